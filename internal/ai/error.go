@@ -14,6 +14,17 @@
 
 package ai
 
-import "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/credit"
+import (
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/budget"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/credit"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/guard"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/quota"
+)
 
-var ErrInsufficientCredit = credit.ErrInsufficientCredit
+var (
+	ErrInsufficientCredit = credit.ErrInsufficientCredit
+	ErrQuotaExceeded      = quota.ErrQuotaExceeded
+	ErrBudgetExceeded     = budget.ErrBudgetExceeded
+	// ErrInputRejected 用户的输入没有通过安全检查
+	ErrInputRejected = guard.ErrInputRejected
+)
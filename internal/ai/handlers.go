@@ -15,54 +15,334 @@
 package ai
 
 import (
+	"errors"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/biz"
+	aibudget "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/budget"
+	aicache "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/cache"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/config"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/credit"
+	aiguard "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/guard"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/log"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/platform"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/platform/openai"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/platform/replay"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/platform/zhipu"
+	aiquota "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/quota"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/record"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/quota"
+	credit2 "github.com/ecodeclub/webook/internal/credit"
+	"github.com/ecodeclub/webook/internal/member"
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
 )
 
-func InitHandlerFacade(common []handler.Builder, zhipu *zhipu.Handler) *biz.FacadeHandler {
-	que := InitQuestionExamineHandler(common, zhipu)
+func InitHandlerFacade(common []handler.Builder,
+	adminCommon AdminHandlers,
+	guard *aiguard.HandlerBuilder,
+	cache *aicache.HandlerBuilder,
+	platform handler.Handler) *biz.FacadeHandler {
+	que := InitQuestionExamineHandler(common, guard, cache, platform)
+	draft := InitQuestionAnswerDraftHandler(adminCommon, platform)
+	ca := InitCaseExamineHandler(common, guard, cache, platform)
 	return biz.NewHandler(map[string]handler.Handler{
-		que.Biz(): que,
+		que.Biz():   que,
+		draft.Biz(): draft,
+		ca.Biz():    ca,
 	})
 }
 
+// InitPlatform 初始化真正的出口
+// 配置了录制回放的时候，回放模式完全不会访问网络，也不需要配置任何平台
+func InitPlatform() handler.Handler {
+	type Config struct {
+		Mode string `yaml:"mode"`
+		Dir  string `yaml:"dir"`
+	}
+	var cfg Config
+	err := econf.UnmarshalKey("ai.replay", &cfg)
+	if err != nil && !errors.Is(err, econf.ErrInvalidKey) {
+		panic(err)
+	}
+	switch cfg.Mode {
+	case replay.ModeReplay:
+		return replay.NewReplayHandler(cfg.Dir)
+	case replay.ModeRecord:
+		return replay.NewRecordHandler(cfg.Dir, InitPlatforms())
+	default:
+		return InitPlatforms()
+	}
+}
+
+// InitPlatforms 初始化所有的平台，BizConfig 决定具体使用哪个平台
+// 每个平台都是可选的，没有配置的平台不会注册
+func InitPlatforms() *platform.Handler {
+	platforms := make(map[string]handler.Handler, 2)
+	// 没有配置默认平台的时候，按照这个顺序选择第一个配置了的平台
+	var names []string
+	if zp := InitZhipu(); zp != nil {
+		platforms[zp.Name()] = zp
+		names = append(names, zp.Name())
+	}
+	if op := InitOpenAI(); op != nil {
+		platforms[op.Name()] = op
+		names = append(names, op.Name())
+	}
+	if len(platforms) == 0 {
+		elog.DefaultLogger.Warn("没有配置任何 AI 平台，调用 AI 都会失败")
+	}
+	defaultPlatform := econf.GetString("ai.defaultPlatform")
+	if defaultPlatform == "" && len(names) > 0 {
+		defaultPlatform = names[0]
+	}
+	return platform.NewHandler(platforms, defaultPlatform)
+}
+
+// InitZhipu 没有配置 apikey 的时候返回 nil
 func InitZhipu() *zhipu.Handler {
 	type Config struct {
-		APIKey string  `yaml:"apikey"`
-		Price  float64 `yaml:"price"`
+		APIKey string `yaml:"apikey"`
+		// 默认模型
+		Model string `yaml:"model"`
+		// 默认模型的价格
+		Price float64 `yaml:"price"`
+		// 其它模型的价格
+		Prices map[string]float64 `yaml:"prices"`
 	}
 	var cfg Config
 	err := econf.UnmarshalKey("zhipu", &cfg)
+	if errors.Is(err, econf.ErrInvalidKey) {
+		return nil
+	}
 	if err != nil {
 		panic(err)
 	}
-	h, err := zhipu.NewHandler(cfg.APIKey, cfg.Price)
+	if cfg.APIKey == "" {
+		return nil
+	}
+	if cfg.Model == "" {
+		cfg.Model = "glm-4-0520"
+	}
+	h, err := zhipu.NewHandler(cfg.APIKey, cfg.Model, initPrices(cfg.Model, cfg.Price, cfg.Prices))
 	if err != nil {
 		panic(err)
 	}
 	return h
 }
 
+// InitOpenAI 没有配置 apikey 的时候返回 nil
+func InitOpenAI() *openai.Handler {
+	type Config struct {
+		BaseURL string             `yaml:"baseURL"`
+		APIKey  string             `yaml:"apikey"`
+		Model   string             `yaml:"model"`
+		Price   float64            `yaml:"price"`
+		Prices  map[string]float64 `yaml:"prices"`
+		// Timeout 等待平台响应的超时时间，默认一分钟
+		Timeout time.Duration `yaml:"timeout"`
+	}
+	var cfg Config
+	err := econf.UnmarshalKey("openai", &cfg)
+	if errors.Is(err, econf.ErrInvalidKey) {
+		return nil
+	}
+	if err != nil {
+		panic(err)
+	}
+	if cfg.APIKey == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Minute
+	}
+	return openai.NewHandler(cfg.BaseURL, cfg.APIKey, cfg.Model,
+		initPrices(cfg.Model, cfg.Price, cfg.Prices), cfg.Timeout)
+}
+
+func initPrices(model string, price float64, prices map[string]float64) map[string]float64 {
+	res := make(map[string]float64, len(prices)+1)
+	for m, p := range prices {
+		res[m] = p
+	}
+	if _, ok := res[model]; !ok {
+		res[model] = price
+	}
+	return res
+}
+
+// InitCreditHandlerBuilder 没有配置 ai.credit 的时候不预扣积分，调用之后再扣
+func InitCreditHandlerBuilder(creSvc credit2.Service, repo repository.LLMCreditLogRepo) *credit.HandlerBuilder {
+	type Config struct {
+		Price     float64 `yaml:"price"`
+		MaxOutput int     `yaml:"maxOutput"`
+	}
+	var cfg Config
+	err := econf.UnmarshalKey("ai.credit", &cfg)
+	if err != nil && !errors.Is(err, econf.ErrInvalidKey) {
+		panic(err)
+	}
+	return credit.NewHandlerBuilder(creSvc, repo, credit.PreAuthConfig{
+		Price:     cfg.Price,
+		MaxOutput: cfg.MaxOutput,
+	})
+}
+
+// InitQuotaService 没有配置 ai.quota 的时候不限制
+func InitQuotaService(repo repository.QuotaRepository, memberSvc member.Service) quota.Service {
+	type Limit struct {
+		CallsPerMinute int64 `yaml:"callsPerMinute"`
+		CallsPerDay    int64 `yaml:"callsPerDay"`
+		TokensPerDay   int64 `yaml:"tokensPerDay"`
+	}
+	type Limits struct {
+		Member Limit `yaml:"member"`
+		Normal Limit `yaml:"normal"`
+	}
+	type Config struct {
+		Default Limits            `yaml:"default"`
+		Bizs    map[string]Limits `yaml:"bizs"`
+	}
+	var cfg Config
+	err := econf.UnmarshalKey("ai.quota", &cfg)
+	if err != nil && !errors.Is(err, econf.ErrInvalidKey) {
+		panic(err)
+	}
+	toLimits := func(l Limits) quota.Limits {
+		return quota.Limits{
+			Member: domain.QuotaLimit(l.Member),
+			Normal: domain.QuotaLimit(l.Normal),
+		}
+	}
+	bizs := make(map[string]quota.Limits, len(cfg.Bizs))
+	for biz, l := range cfg.Bizs {
+		bizs[biz] = toLimits(l)
+	}
+	return quota.NewService(repo, memberSvc, quota.Config{
+		Default: toLimits(cfg.Default),
+		Bizs:    bizs,
+	})
+}
+
+// InitResponseCacheHandlerBuilder 没有配置 ai.cache 的时候不缓存
+func InitResponseCacheHandlerBuilder(repo repository.ResponseRepository) *aicache.HandlerBuilder {
+	type Config struct {
+		TTL       time.Duration `yaml:"ttl"`
+		HitAmount int64         `yaml:"hitAmount"`
+	}
+	var cfg Config
+	err := econf.UnmarshalKey("ai.cache", &cfg)
+	if err != nil && !errors.Is(err, econf.ErrInvalidKey) {
+		panic(err)
+	}
+	return aicache.NewHandlerBuilder(repo, aicache.Config{
+		TTL:       cfg.TTL,
+		HitAmount: cfg.HitAmount,
+	})
+}
+
 func InitQuestionExamineHandler(
 	common []handler.Builder,
+	guard *aiguard.HandlerBuilder,
+	cache *aicache.HandlerBuilder,
 	// platform 就是真正的出口
 	platform handler.Handler) *biz.CompositionHandler {
-	// log -> cfg -> credit -> record -> question_examine -> platform
+	// log -> cfg -> quota -> credit -> record -> guard -> question_examine -> cache -> platform
 	builder := biz.NewQuestionExamineBizHandlerBuilder()
-	common = append(common, builder)
+	common = append(common, guard, builder, cache)
 	res := biz.NewCombinedBizHandler("question_examine", common, platform)
 	return res
 }
 
+// InitCaseExamineHandler 和题目测试一样，要检查用户的输入，也可以缓存
+// guard 只检查 Input[1]，也就是用户的讲述，评分依据是管理员写的，不需要检查
+func InitCaseExamineHandler(
+	common []handler.Builder,
+	guard *aiguard.HandlerBuilder,
+	cache *aicache.HandlerBuilder,
+	platform handler.Handler) *biz.CompositionHandler {
+	// log -> cfg -> quota -> credit -> record -> guard -> case_examine -> cache -> platform
+	builders := make([]handler.Builder, 0, len(common)+3)
+	builders = append(builders, common...)
+	builders = append(builders, guard, biz.NewCaseExamineBizHandlerBuilder(), cache)
+	return biz.NewCombinedBizHandler(domain.BizCaseExamine, builders, platform)
+}
+
+// InitQuestionAnswerDraftHandler 管理后台生成答案草稿
+// 也不缓存，重新生成的时候希望拿到不同的结果
+func InitQuestionAnswerDraftHandler(
+	adminCommon AdminHandlers,
+	platform handler.Handler) *biz.CompositionHandler {
+	// log -> cfg -> budget -> record -> question_answer_draft -> platform
+	builders := make([]handler.Builder, 0, len(adminCommon)+1)
+	builders = append(builders, adminCommon...)
+	builders = append(builders, biz.NewQuestionAnswerDraftBizHandlerBuilder())
+	return biz.NewCombinedBizHandler(domain.BizQuestionAnswerDraft, builders, platform)
+}
+
+// InitGuardHandlerBuilder 读取 ai.guard，所有业务共用的关键字和正则表达式
+// 没有配置的时候只检查内置的规则
+func InitGuardHandlerBuilder() *aiguard.HandlerBuilder {
+	type Config struct {
+		Keywords []string `yaml:"keywords"`
+		Patterns []string `yaml:"patterns"`
+	}
+	var cfg Config
+	err := econf.UnmarshalKey("ai.guard", &cfg)
+	if err != nil && !errors.Is(err, econf.ErrInvalidKey) {
+		panic(err)
+	}
+	res, err := aiguard.NewHandlerBuilder(aiguard.Rules{
+		Keywords: cfg.Keywords,
+		Patterns: cfg.Patterns,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// InitBudgetHandlerBuilder 读取 ai.budget，业务 => 每个月的预算，单位是积分
+// 没有配置的业务不限制，预留花费和预扣积分一样使用 ai.credit 来估算
+func InitBudgetHandlerBuilder(repo repository.BudgetRepository) *aibudget.HandlerBuilder {
+	var limits map[string]int64
+	err := econf.UnmarshalKey("ai.budget", &limits)
+	if err != nil && !errors.Is(err, econf.ErrInvalidKey) {
+		panic(err)
+	}
+	type Config struct {
+		Price     float64 `yaml:"price"`
+		MaxOutput int     `yaml:"maxOutput"`
+	}
+	var cfg Config
+	err = econf.UnmarshalKey("ai.credit", &cfg)
+	if err != nil && !errors.Is(err, econf.ErrInvalidKey) {
+		panic(err)
+	}
+	return aibudget.NewHandlerBuilder(repo, limits, credit.PreAuthConfig{
+		Price:     cfg.Price,
+		MaxOutput: cfg.MaxOutput,
+	})
+}
+
 func InitCommonHandlers(log *log.HandlerBuilder,
 	cfg *config.HandlerBuilder,
+	quota *aiquota.HandlerBuilder,
 	credit *credit.HandlerBuilder,
 	record *record.HandlerBuilder) []handler.Builder {
-	return []handler.Builder{log, cfg, credit, record}
+	return []handler.Builder{log, cfg, quota, credit, record}
+}
+
+// AdminHandlers 管理后台使用的业务共用的 handler
+// 不受用户配额的限制，用预算替换掉积分
+type AdminHandlers []handler.Builder
+
+func InitAdminHandlers(log *log.HandlerBuilder,
+	cfg *config.HandlerBuilder,
+	budget *aibudget.HandlerBuilder,
+	record *record.HandlerBuilder) AdminHandlers {
+	return AdminHandlers{log, cfg, budget, record}
 }
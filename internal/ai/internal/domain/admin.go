@@ -0,0 +1,50 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+// LLMFilter 查询调用记录和扣费记录的条件，零值的字段不参与过滤
+type LLMFilter struct {
+	Uid int64
+	Biz string
+	// 为空表示不过滤状态
+	Status []int
+	// 按照创建时间过滤，毫秒，左闭右开
+	StartTime int64
+	EndTime   int64
+}
+
+type UsageDimension string
+
+const (
+	UsageDimensionBiz  UsageDimension = "biz"
+	UsageDimensionUser UsageDimension = "uid"
+)
+
+func (d UsageDimension) Valid() bool {
+	return d == UsageDimensionBiz || d == UsageDimensionUser
+}
+
+// DailyUsage 某一天某个业务或者某个用户的用量
+type DailyUsage struct {
+	// 2006-01-02 格式
+	Date string
+	// 按照业务统计的时候有值
+	Biz string
+	// 按照用户统计的时候有值
+	Uid    int64
+	Calls  int64
+	Tokens int64
+	Amount int64
+}
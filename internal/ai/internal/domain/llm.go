@@ -1,6 +1,20 @@
 package domain
 
-const BizQuestionExamine = "question_examine"
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	BizQuestionExamine = "question_examine"
+	// BizQuestionAnswerDraft 管理后台根据题目生成答案草稿
+	BizQuestionAnswerDraft = "question_answer_draft"
+	// BizCaseExamine 用户讲述怎么在面试中介绍案例，AI 给出评价
+	BizCaseExamine = "case_examine"
+)
 
 type LLMRequest struct {
 	Biz string
@@ -13,6 +27,9 @@ type LLMRequest struct {
 	Prompt string
 	// 业务相关的配置
 	Config BizConfig
+	// 不读缓存，重新生成回答，新的回答会覆盖缓存
+	// 例如上一次的回答不符合业务的格式要求，需要重试
+	Refresh bool
 }
 
 type LLMResponse struct {
@@ -22,9 +39,26 @@ type LLMResponse struct {
 	Amount int64
 	// llm 的回答
 	Answer string
+	// 是否命中了缓存，命中缓存的时候没有调用平台
+	Cached bool
+	// 提示词模板约定了回答是结构化的 JSON，由业务的 handler 设置
+	// 业务方只在这种时候才需要在解析失败之后重试
+	Structured bool
+}
+
+// StreamEvent 流式响应中的一个事件
+type StreamEvent struct {
+	// 增量的回答
+	Content string
+	// 是否已经结束，结束的时候 Response 是完整的响应
+	Done     bool
+	Response LLMResponse
+	Err      error
 }
 
 type BizConfig struct {
+	Id  int64
+	Biz string
 	// 允许的最长输入
 	// 这里我们不用计算 token，只需要简单约束一下字符串长度就可以
 	MaxInput int
@@ -35,6 +69,95 @@ type BizConfig struct {
 	// 这里一般使用 %s
 	// 后续考虑 key value 的形式
 	PromptTemplate string
+	// 使用的平台，例如 zhipu，openai。为空的时候使用默认平台
+	Platform string
+	// 使用的模型，为空的时候使用平台的默认模型
+	Model string
+	// 主平台调用失败之后，按照顺序尝试的备用平台
+	Fallbacks []PlatformConfig
+	// 响应缓存的过期时间，0 使用默认值
+	CacheTTL time.Duration
+	// 不缓存响应，例如回答需要个性化的业务
+	DisableCache bool
+	// 生效的提示词版本，0 表示使用上面的 PromptTemplate
+	ActiveVersion int64
+	// 提示词实验，不为空的时候按照权重把用户分到不同的版本上
+	Experiments []PromptExperiment
+	// 这一次请求使用的提示词版本，由 PickPromptVersion 选出来
+	PromptVersion int64
+	// 用户输入的安全检查规则，和全局的规则一起使用
+	Guard GuardRules
+	Ctime int64
+	Utime int64
+}
+
+// PickPromptVersion 选择用户使用的提示词版本
+// 有实验的时候按照 uid 的哈希值分流，同一个用户总是落在同一个版本上
+func (c BizConfig) PickPromptVersion(uid int64) int64 {
+	var total int64
+	for _, exp := range c.Experiments {
+		total += max(exp.Weight, 0)
+	}
+	if total == 0 {
+		return c.ActiveVersion
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(strconv.FormatInt(uid, 10)))
+	bucket := int64(hash.Sum32()) % total
+	for _, exp := range c.Experiments {
+		if exp.Weight <= 0 {
+			continue
+		}
+		if bucket < exp.Weight {
+			return exp.Version
+		}
+		bucket -= exp.Weight
+	}
+	return c.ActiveVersion
+}
+
+// PromptVersion 提示词的一个版本，版本一旦创建就不会修改
+type PromptVersion struct {
+	Biz            string
+	Version        int64
+	PromptTemplate string
+	// 版本说明，例如改了什么
+	Description string
+	Ctime       int64
+	Utime       int64
+}
+
+// PromptExperiment 实验中的一个分组
+type PromptExperiment struct {
+	Version int64
+	// 权重，分到这个版本的用户占比是 Weight / 所有权重之和
+	Weight int64
+}
+
+// GuardRules 用户输入的安全检查规则
+type GuardRules struct {
+	// 不检查，例如只有管理员才能使用的业务
+	Disabled bool
+	// 包含这些关键字的输入会被拒绝，忽略大小写
+	Keywords []string
+	// 匹配这些正则表达式的输入会被拒绝
+	Patterns []string
+}
+
+// Validate 检查正则表达式是否合法
+func (r GuardRules) Validate() error {
+	for _, p := range r.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("非法的正则表达式 %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// PlatformConfig 平台和模型的组合
+type PlatformConfig struct {
+	Platform string
+	Model    string
 }
 
 type LLMCredit struct {
@@ -61,8 +184,11 @@ type LLMRecord struct {
 	KnowledgeId    string
 	PromptTemplate string
 	Answer         string
-	Ctime          int64
-	Utime          int64
+	// 使用的提示词版本，0 表示使用的是 BizConfig 里面的提示词
+	PromptVersion int64
+	Cached        bool
+	Ctime         int64
+	Utime         int64
 }
 
 type CreditStatus uint8
@@ -87,4 +213,6 @@ const (
 	RecordStatusProcessing RecordStatus = 0
 	RecordStatusSuccess    RecordStatus = 1
 	RecordStatusFailed     RecordStatus = 2
+	// RecordStatusRejected 用户输入没有通过安全检查，没有调用平台
+	RecordStatusRejected RecordStatus = 3
 )
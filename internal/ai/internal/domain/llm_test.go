@@ -0,0 +1,89 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBizConfig_PickPromptVersion(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  BizConfig
+		uid  int64
+		want int64
+	}{
+		{
+			name: "没有版本",
+			cfg:  BizConfig{},
+			uid:  123,
+			want: 0,
+		},
+		{
+			name: "没有实验",
+			cfg:  BizConfig{ActiveVersion: 2},
+			uid:  123,
+			want: 2,
+		},
+		{
+			name: "权重都是0",
+			cfg: BizConfig{
+				ActiveVersion: 2,
+				Experiments:   []PromptExperiment{{Version: 3}, {Version: 4}},
+			},
+			uid:  123,
+			want: 2,
+		},
+		{
+			name: "只有一个分组有权重",
+			cfg: BizConfig{
+				ActiveVersion: 2,
+				Experiments: []PromptExperiment{
+					{Version: 3, Weight: 0},
+					{Version: 4, Weight: 10},
+				},
+			},
+			uid:  123,
+			want: 4,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.cfg.PickPromptVersion(tc.uid))
+		})
+	}
+}
+
+func TestBizConfig_PickPromptVersion_Distribution(t *testing.T) {
+	cfg := BizConfig{
+		ActiveVersion: 1,
+		Experiments: []PromptExperiment{
+			{Version: 1, Weight: 80},
+			{Version: 2, Weight: 20},
+		},
+	}
+	cnt := map[int64]int{}
+	for uid := int64(1); uid <= 10000; uid++ {
+		version := cfg.PickPromptVersion(uid)
+		// 同一个用户总是落在同一个版本上
+		assert.Equal(t, version, cfg.PickPromptVersion(uid))
+		cnt[version]++
+	}
+	assert.Len(t, cnt, 2)
+	assert.InDelta(t, 8000, cnt[1], 500)
+	assert.InDelta(t, 2000, cnt[2], 500)
+}
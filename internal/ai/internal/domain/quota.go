@@ -0,0 +1,47 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package domain
+
+// QuotaLimit 使用配额，0 表示不限制
+type QuotaLimit struct {
+	CallsPerMinute int64
+	CallsPerDay    int64
+	TokensPerDay   int64
+}
+
+// QuotaUsage 用户在某个业务上的使用情况
+type QuotaUsage struct {
+	Biz   string
+	Limit QuotaLimit
+
+	CallsThisMinute int64
+	CallsToday      int64
+	TokensToday     int64
+}
+
+// CallsLeftToday 今天还能调用的次数，-1 表示不限制
+func (u QuotaUsage) CallsLeftToday() int64 {
+	if u.Limit.CallsPerDay <= 0 {
+		return -1
+	}
+	return max(u.Limit.CallsPerDay-u.CallsToday, 0)
+}
+
+// TokensLeftToday 今天还能使用的 token 数，-1 表示不限制
+func (u QuotaUsage) TokensLeftToday() int64 {
+	if u.Limit.TokensPerDay <= 0 {
+		return -1
+	}
+	return max(u.Limit.TokensPerDay-u.TokensToday, 0)
+}
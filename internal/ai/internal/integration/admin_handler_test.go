@@ -0,0 +1,447 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ekit/sqlx"
+	"github.com/ecodeclub/webook/internal/ai"
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/integration/startup"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/ai/internal/web"
+	"github.com/ecodeclub/webook/internal/credit"
+	"github.com/ecodeclub/webook/internal/member"
+	"github.com/ecodeclub/webook/internal/test"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/ego-component/egorm"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/server/egin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminHandlerTestSuite struct {
+	suite.Suite
+	hdl       *ai.AdminHandler
+	server    *egin.Component
+	db        *egorm.Component
+	configDAO dao.ConfigDAO
+}
+
+func (s *AdminHandlerTestSuite) SetupSuite() {
+	s.db = testioc.InitDB()
+	m, err := startup.InitModule(s.db, nil, &credit.Module{}, &member.Module{})
+	require.NoError(s.T(), err)
+	s.hdl = m.AdminHdl
+
+	econf.Set("server", map[string]any{"contextTimeout": "10s"})
+	server := egin.Load("server").Build()
+	s.hdl.PrivateRoutes(server.Engine)
+	s.server = server
+	s.configDAO = dao.NewGORMConfigDAO(s.db)
+	// 去掉默认的配置，每个测试自己准备数据
+	err = s.db.Exec("TRUNCATE TABLE `ai_biz_configs`").Error
+	require.NoError(s.T(), err)
+}
+
+func (s *AdminHandlerTestSuite) TearDownTest() {
+	err := s.db.Exec("TRUNCATE TABLE `ai_biz_configs`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `ai_prompt_versions`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `llm_records`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `llm_credits`").Error
+	require.NoError(s.T(), err)
+}
+
+func (s *AdminHandlerTestSuite) TestSaveConfig() {
+	testCases := []struct {
+		name   string
+		before func(t *testing.T)
+		after  func(t *testing.T)
+
+		req      web.BizConfig
+		wantCode int
+		wantResp test.Result[int64]
+	}{
+		{
+			name:   "新建",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				cfg, err := s.configDAO.GetConfigById(ctx, 1)
+				require.NoError(t, err)
+				assert.True(t, cfg.Ctime > 0)
+				cfg.Ctime = 0
+				assert.True(t, cfg.Utime > 0)
+				cfg.Utime = 0
+				assert.Equal(t, dao.BizConfig{
+					Id:             1,
+					Biz:            domain.BizQuestionExamine,
+					MaxInput:       100,
+					PromptTemplate: "这是问题 %s，这是 <user_input> 里面的用户输入 %s",
+					KnowledgeId:    knowledgeId,
+					Platform:       "zhipu",
+					Fallbacks: sqlx.JsonColumn[[]dao.PlatformConfig]{
+						Valid: true,
+						Val:   []dao.PlatformConfig{{Platform: "openai", Model: "gpt-4o"}},
+					},
+					CacheTTL: 3600,
+					Guard: sqlx.JsonColumn[dao.GuardRules]{
+						Valid: true,
+						Val: dao.GuardRules{
+							Keywords: []string{"越狱"},
+							Patterns: []string{`满分`},
+						},
+					},
+				}, cfg)
+			},
+			req: web.BizConfig{
+				Biz:            domain.BizQuestionExamine,
+				MaxInput:       100,
+				PromptTemplate: "这是问题 %s，这是 <user_input> 里面的用户输入 %s",
+				KnowledgeId:    knowledgeId,
+				Platform:       "zhipu",
+				Fallbacks:      []web.PlatformConfig{{Platform: "openai", Model: "gpt-4o"}},
+				CacheTTL:       3600,
+				Guard: web.GuardRules{
+					Keywords: []string{"越狱"},
+					Patterns: []string{`满分`},
+				},
+			},
+			wantCode: 200,
+			wantResp: test.Result[int64]{
+				Data: 1,
+			},
+		},
+		{
+			name: "更新",
+			before: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				err := s.db.WithContext(ctx).Create(&dao.BizConfig{
+					Id:             2,
+					Biz:            domain.BizQuestionExamine,
+					MaxInput:       100,
+					PromptTemplate: "老的模板",
+					KnowledgeId:    "old",
+					ActiveVersion:  3,
+					Ctime:          123,
+					Utime:          123,
+				}).Error
+				require.NoError(t, err)
+			},
+			after: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				cfg, err := s.configDAO.GetConfigById(ctx, 2)
+				require.NoError(t, err)
+				assert.True(t, cfg.Utime > 123)
+				cfg.Utime = 0
+				assert.Equal(t, dao.BizConfig{
+					Id:             2,
+					Biz:            domain.BizQuestionExamine,
+					MaxInput:       200,
+					PromptTemplate: "新的模板",
+					KnowledgeId:    knowledgeId,
+					DisableCache:   true,
+					// 不会修改提示词版本
+					ActiveVersion: 3,
+					Guard: sqlx.JsonColumn[dao.GuardRules]{
+						Valid: true,
+						Val:   dao.GuardRules{Disabled: true},
+					},
+					Ctime: 123,
+				}, cfg)
+			},
+			req: web.BizConfig{
+				Id:             2,
+				Biz:            domain.BizQuestionExamine,
+				MaxInput:       200,
+				PromptTemplate: "新的模板",
+				KnowledgeId:    knowledgeId,
+				DisableCache:   true,
+				Guard:          web.GuardRules{Disabled: true},
+			},
+			wantCode: 200,
+			wantResp: test.Result[int64]{
+				Data: 2,
+			},
+		},
+		{
+			name:   "非法的正则表达式",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				_, err := s.configDAO.GetConfigById(ctx, 3)
+				assert.Error(t, err)
+			},
+			req: web.BizConfig{
+				Id:             3,
+				Biz:            domain.BizQuestionExamine,
+				PromptTemplate: "这是问题 %s，这是 <user_input> 里面的用户输入 %s",
+				Guard:          web.GuardRules{Patterns: []string{`(`}},
+			},
+			wantCode: 500,
+			wantResp: test.Result[int64]{
+				Code: 514001,
+				Msg:  "系统错误",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			tc.before(t)
+			req, err := http.NewRequest(http.MethodPost,
+				"/ai/config/save", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[int64]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan())
+			tc.after(t)
+		})
+	}
+}
+
+func (s *AdminHandlerTestSuite) TestListConfigs() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := int64(1); i <= 3; i++ {
+		err := s.db.WithContext(ctx).Create(&dao.BizConfig{
+			Id:          i,
+			Biz:         fmt.Sprintf("biz_%d", i),
+			KnowledgeId: knowledgeId,
+			Utime:       i,
+		}).Error
+		require.NoError(s.T(), err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost,
+		"/ai/config/list", iox.NewJSONReader(web.Page{Offset: 0, Limit: 2}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(s.T(), err)
+	recorder := test.NewJSONResponseRecorder[web.BizConfigList]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(s.T(), 200, recorder.Code)
+	assert.Equal(s.T(), test.Result[web.BizConfigList]{
+		Data: web.BizConfigList{
+			Total: 3,
+			Configs: []web.BizConfig{
+				{Id: 3, Biz: "biz_3", KnowledgeId: knowledgeId, Utime: 3},
+				{Id: 2, Biz: "biz_2", KnowledgeId: knowledgeId, Utime: 2},
+			},
+		},
+	}, recorder.MustScan())
+}
+
+func (s *AdminHandlerTestSuite) TestListRecords() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	records := []dao.LLMRecord{
+		{Id: 1, Tid: "tid-1", Uid: 1, Biz: domain.BizQuestionExamine, Tokens: 10, Amount: 1, Status: domain.RecordStatusSuccess.ToUint8(), Ctime: 100},
+		{Id: 2, Tid: "tid-2", Uid: 2, Biz: domain.BizQuestionExamine, Tokens: 20, Amount: 2, Status: domain.RecordStatusFailed.ToUint8(), Ctime: 200},
+		{Id: 3, Tid: "tid-3", Uid: 1, Biz: "other", Tokens: 30, Amount: 3, Status: domain.RecordStatusSuccess.ToUint8(), Ctime: 300},
+		{Id: 4, Tid: "tid-4", Uid: 1, Biz: domain.BizQuestionExamine, Tokens: 40, Amount: 4, Status: domain.RecordStatusSuccess.ToUint8(), Ctime: 400},
+	}
+	err := s.db.WithContext(ctx).Create(&records).Error
+	require.NoError(s.T(), err)
+
+	testCases := []struct {
+		name     string
+		req      web.ListReq
+		wantResp test.Result[web.LLMRecordList]
+	}{
+		{
+			name: "按照用户和业务过滤",
+			req: web.ListReq{
+				Filter: web.Filter{Uid: 1, Biz: domain.BizQuestionExamine},
+				Page:   web.Page{Limit: 10},
+			},
+			wantResp: test.Result[web.LLMRecordList]{
+				Data: web.LLMRecordList{
+					Total: 2,
+					Records: []web.LLMRecord{
+						{Id: 4, Tid: "tid-4", Uid: 1, Biz: domain.BizQuestionExamine, Tokens: 40, Amount: 4, Status: 1, Ctime: 400},
+						{Id: 1, Tid: "tid-1", Uid: 1, Biz: domain.BizQuestionExamine, Tokens: 10, Amount: 1, Status: 1, Ctime: 100},
+					},
+				},
+			},
+		},
+		{
+			name: "按照状态和时间过滤",
+			req: web.ListReq{
+				Filter: web.Filter{
+					Status:    []int{int(domain.RecordStatusFailed)},
+					StartTime: 100,
+					EndTime:   300,
+				},
+				Page: web.Page{Limit: 10},
+			},
+			wantResp: test.Result[web.LLMRecordList]{
+				Data: web.LLMRecordList{
+					Total: 1,
+					Records: []web.LLMRecord{
+						{Id: 2, Tid: "tid-2", Uid: 2, Biz: domain.BizQuestionExamine, Tokens: 20, Amount: 2, Status: 2, Ctime: 200},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				"/ai/record/list", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.LLMRecordList]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan())
+		})
+	}
+}
+
+func (s *AdminHandlerTestSuite) TestListCredits() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	credits := []dao.LLMCredit{
+		{Id: 1, Tid: "tid-1", Uid: 1, Biz: domain.BizQuestionExamine, Amount: 1, Status: domain.CreditStatusSuccess.ToUint8(), Ctime: 100, Utime: 100},
+		{Id: 2, Tid: "tid-2", Uid: 2, Biz: domain.BizQuestionExamine, Amount: 2, Status: domain.CreditStatusFailed.ToUint8(), Ctime: 200, Utime: 200},
+		{Id: 3, Tid: "tid-3", Uid: 2, Biz: domain.BizQuestionExamine, Amount: 3, Status: domain.CreditStatusSuccess.ToUint8(), Ctime: 300, Utime: 300},
+	}
+	err := s.db.WithContext(ctx).Create(&credits).Error
+	require.NoError(s.T(), err)
+
+	req, err := http.NewRequest(http.MethodPost,
+		"/ai/credit/list", iox.NewJSONReader(web.ListReq{
+			Filter: web.Filter{Uid: 2},
+			Page:   web.Page{Limit: 1},
+		}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(s.T(), err)
+	recorder := test.NewJSONResponseRecorder[web.LLMCreditList]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(s.T(), 200, recorder.Code)
+	assert.Equal(s.T(), test.Result[web.LLMCreditList]{
+		Data: web.LLMCreditList{
+			Total: 2,
+			Credits: []web.LLMCredit{
+				{Id: 3, Tid: "tid-3", Uid: 2, Biz: domain.BizQuestionExamine, Amount: 3, Status: 1, Ctime: 300, Utime: 300},
+			},
+		},
+	}, recorder.MustScan())
+}
+
+func (s *AdminHandlerTestSuite) TestDailyUsage() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// 使用中午的时间，避免时区不同导致日期不同
+	day1 := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC).UnixMilli()
+	day2 := time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC).UnixMilli()
+	records := []dao.LLMRecord{
+		{Tid: "tid-1", Uid: 1, Biz: domain.BizQuestionExamine, Tokens: 10, Amount: 1, Ctime: day1},
+		{Tid: "tid-2", Uid: 2, Biz: domain.BizQuestionExamine, Tokens: 20, Amount: 2, Ctime: day1},
+		{Tid: "tid-3", Uid: 1, Biz: "other", Tokens: 35, Amount: 3, Ctime: day1},
+		{Tid: "tid-4", Uid: 1, Biz: domain.BizQuestionExamine, Tokens: 40, Amount: 4, Ctime: day2},
+	}
+	err := s.db.WithContext(ctx).Create(&records).Error
+	require.NoError(s.T(), err)
+
+	testCases := []struct {
+		name     string
+		req      web.DailyUsageReq
+		wantCode int
+		wantResp test.Result[[]web.DailyUsage]
+	}{
+		{
+			name: "按照业务统计",
+			req: web.DailyUsageReq{
+				Page:      web.Page{Limit: 10},
+				Dimension: "biz",
+			},
+			wantCode: 200,
+			wantResp: test.Result[[]web.DailyUsage]{
+				Data: []web.DailyUsage{
+					{Date: "2024-06-02", Biz: domain.BizQuestionExamine, Calls: 1, Tokens: 40, Amount: 4},
+					{Date: "2024-06-01", Biz: "other", Calls: 1, Tokens: 35, Amount: 3},
+					{Date: "2024-06-01", Biz: domain.BizQuestionExamine, Calls: 2, Tokens: 30, Amount: 3},
+				},
+			},
+		},
+		{
+			name: "按照用户统计",
+			req: web.DailyUsageReq{
+				Filter:    web.Filter{Biz: domain.BizQuestionExamine},
+				Page:      web.Page{Limit: 10},
+				Dimension: "uid",
+			},
+			wantCode: 200,
+			wantResp: test.Result[[]web.DailyUsage]{
+				Data: []web.DailyUsage{
+					{Date: "2024-06-02", Uid: 1, Calls: 1, Tokens: 40, Amount: 4},
+					{Date: "2024-06-01", Uid: 2, Calls: 1, Tokens: 20, Amount: 2},
+					{Date: "2024-06-01", Uid: 1, Calls: 1, Tokens: 10, Amount: 1},
+				},
+			},
+		},
+		{
+			name: "非法的统计维度",
+			req: web.DailyUsageReq{
+				Page:      web.Page{Limit: 10},
+				Dimension: "tid",
+			},
+			wantCode: 200,
+			wantResp: test.Result[[]web.DailyUsage]{
+				Code: 414001,
+				Msg:  "非法的统计维度",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				"/ai/usage/daily", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[[]web.DailyUsage]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan())
+		})
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	suite.Run(t, new(AdminHandlerTestSuite))
+}
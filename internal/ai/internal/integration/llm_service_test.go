@@ -12,12 +12,14 @@ import (
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm"
 	llmHandler "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
 	hdlmocks "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/mocks"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/platform/replay"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
 	"github.com/ecodeclub/webook/internal/ai/internal/integration/startup"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository/dao"
 	"github.com/ecodeclub/webook/internal/credit"
 	creditmocks "github.com/ecodeclub/webook/internal/credit/mocks"
+	"github.com/ecodeclub/webook/internal/member"
 	testioc "github.com/ecodeclub/webook/internal/test/ioc"
 	"github.com/ego-component/egorm"
 	"github.com/stretchr/testify/assert"
@@ -45,13 +47,16 @@ func (s *LLMServiceSuite) SetupSuite() {
 	err := dao.InitTables(db)
 	require.NoError(s.T(), err)
 	s.logDao = dao.NewGORMLLMLogDAO(db)
+	// 去掉默认的配置，使用测试自己的配置
+	err = s.db.Exec("TRUNCATE TABLE `ai_biz_configs`").Error
+	require.NoError(s.T(), err)
 
 	// 先插入 BizConfig
 	now := time.Now().UnixMilli()
 	err = s.db.Create(&dao.BizConfig{
 		Biz:            domain.BizQuestionExamine,
 		MaxInput:       100,
-		PromptTemplate: "这是问题 %s，这是用户输入 %s",
+		PromptTemplate: "这是问题 %s，这是 <user_input> 里面的用户输入 %s",
 		KnowledgeId:    knowledgeId,
 		Ctime:          now,
 		Utime:          now,
@@ -137,7 +142,7 @@ func (s *LLMServiceSuite) TestService() {
 						},
 					},
 					Status:         1,
-					PromptTemplate: sqlx.NewNullString("这是问题 %s，这是用户输入 %s"),
+					PromptTemplate: sqlx.NewNullString("这是问题 %s，这是 <user_input> 里面的用户输入 %s"),
 					Answer:         sqlx.NewNullString("aians"),
 				}, logModel)
 				// 校验credit写入的内容是否正确
@@ -193,7 +198,7 @@ func (s *LLMServiceSuite) TestService() {
 						},
 					},
 					Status:         domain.RecordStatusFailed.ToUint8(),
-					PromptTemplate: sqlx.NewNullString("这是问题 %s，这是用户输入 %s"),
+					PromptTemplate: sqlx.NewNullString("这是问题 %s，这是 <user_input> 里面的用户输入 %s"),
 				}, logModel)
 			},
 			assertFunc: assert.Error,
@@ -242,7 +247,7 @@ func (s *LLMServiceSuite) TestService() {
 						},
 					},
 					Status:         domain.CreditStatusFailed.ToUint8(),
-					PromptTemplate: sqlx.NewNullString("这是问题 %s，这是用户输入 %s"),
+					PromptTemplate: sqlx.NewNullString("这是问题 %s，这是 <user_input> 里面的用户输入 %s"),
 					Answer:         sqlx.NewNullString("aians"),
 				}, logModel)
 				// 校验credit写入的内容是否正确
@@ -331,7 +336,7 @@ func (s *LLMServiceSuite) TestService() {
 						},
 					},
 					Status:         domain.RecordStatusFailed.ToUint8(),
-					PromptTemplate: sqlx.NewNullString("这是问题 %s，这是用户输入 %s"),
+					PromptTemplate: sqlx.NewNullString("这是问题 %s，这是 <user_input> 里面的用户输入 %s"),
 					Answer:         sqlx.NewNullString("aians"),
 				}, logModel)
 				// 校验credit写入的内容是否正确
@@ -356,7 +361,7 @@ func (s *LLMServiceSuite) TestService() {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
 			defer cancel()
 			mockHdl, mockCredit := tc.before(t, ctrl)
-			mou, err := startup.InitModule(s.db, mockHdl, &credit.Module{Svc: mockCredit})
+			mou, err := startup.InitModule(s.db, mockHdl, &credit.Module{Svc: mockCredit}, &member.Module{})
 			require.NoError(t, err)
 			resp, err := mou.Svc.Invoke(ctx, tc.req)
 			tc.assertFunc(t, err)
@@ -368,6 +373,50 @@ func (s *LLMServiceSuite) TestService() {
 	}
 }
 
+// TestServiceWithReplay 使用录制好的响应走完整个调用链，不需要访问网络
+func (s *LLMServiceSuite) TestServiceWithReplay() {
+	t := s.T()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	creditSvc := creditmocks.NewMockService(ctrl)
+	creditSvc.EXPECT().GetCreditsByUID(gomock.Any(), gomock.Any()).Return(credit.Credit{
+		TotalAmount: 1000,
+	}, nil)
+	creditSvc.EXPECT().TryDeductCredits(gomock.Any(), gomock.Any()).Return(12, nil)
+	creditSvc.EXPECT().ConfirmDeductCredits(gomock.Any(), int64(127), int64(12)).Return(nil)
+	mou, err := startup.InitModule(s.db, replay.NewReplayHandler("testdata/llm"), &credit.Module{Svc: creditSvc}, &member.Module{})
+	require.NoError(t, err)
+	resp, err := mou.Svc.Invoke(ctx, domain.LLMRequest{
+		Biz: domain.BizQuestionExamine,
+		Uid: 127,
+		Tid: "12",
+		Input: []string{
+			"问题1",
+			"用户输入1",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.LLMResponse{
+		Tokens: 100,
+		Amount: 100,
+		Answer: "评分：15K",
+	}, resp)
+
+	// 没有录制过的请求
+	_, err = mou.Svc.Invoke(ctx, domain.LLMRequest{
+		Biz: domain.BizQuestionExamine,
+		Uid: 127,
+		Tid: "13",
+		Input: []string{
+			"问题2",
+			"用户输入2",
+		},
+	})
+	assert.ErrorIs(t, err, replay.ErrFixtureNotFound)
+}
+
 func (s *LLMServiceSuite) assertLog(wantLog dao.LLMRecord, actual dao.LLMRecord) {
 	require.True(s.T(), actual.Ctime != 0)
 	require.True(s.T(), actual.Utime != 0)
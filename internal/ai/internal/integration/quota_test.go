@@ -0,0 +1,91 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build e2e
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository/cache"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type QuotaCacheSuite struct {
+	suite.Suite
+	rdb   redis.Cmdable
+	cache cache.QuotaCache
+}
+
+func TestQuotaCacheSuite(t *testing.T) {
+	suite.Run(t, new(QuotaCacheSuite))
+}
+
+func (s *QuotaCacheSuite) SetupSuite() {
+	s.rdb = testioc.InitRedis()
+	s.cache = cache.NewQuotaRedisCache(s.rdb)
+}
+
+func (s *QuotaCacheSuite) TearDownTest() {
+	ctx := context.Background()
+	keys, err := s.rdb.Keys(ctx, "webook:ai:quota:*").Result()
+	require.NoError(s.T(), err)
+	if len(keys) > 0 {
+		require.NoError(s.T(), s.rdb.Del(ctx, keys...).Err())
+	}
+}
+
+func (s *QuotaCacheSuite) TestAcquire() {
+	t := s.T()
+	ctx := context.Background()
+	// 固定时间，避免跨天
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	const biz = "test"
+
+	// 每分钟两次
+	limit := domain.QuotaLimit{CallsPerMinute: 2, CallsPerDay: 3, TokensPerDay: 100}
+	require.NoError(t, s.cache.Acquire(ctx, 1, biz, limit, now))
+	require.NoError(t, s.cache.Acquire(ctx, 1, biz, limit, now))
+	assert.ErrorIs(t, s.cache.Acquire(ctx, 1, biz, limit, now), cache.ErrCallsPerMinuteExceeded)
+	// 其它用户不受影响
+	require.NoError(t, s.cache.Acquire(ctx, 2, biz, limit, now))
+
+	// 下一分钟，每天三次
+	next := now.Add(time.Minute)
+	require.NoError(t, s.cache.Acquire(ctx, 1, biz, limit, next))
+	assert.ErrorIs(t, s.cache.Acquire(ctx, 1, biz, limit, next), cache.ErrCallsPerDayExceeded)
+
+	// 退回一次
+	require.NoError(t, s.cache.Release(ctx, 1, biz, next))
+	usage, err := s.cache.Usage(ctx, 1, biz, next)
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuotaUsage{Biz: biz, CallsThisMinute: 1, CallsToday: 2}, usage)
+
+	// token 用完了
+	require.NoError(t, s.cache.IncrTokens(ctx, 1, biz, 100, next))
+	assert.ErrorIs(t, s.cache.Acquire(ctx, 1, biz, limit, next.Add(time.Minute)), cache.ErrTokensPerDayExceeded)
+
+	// 不存在的计数不会被扣成负数
+	require.NoError(t, s.cache.Release(ctx, 3, biz, now))
+	usage, err = s.cache.Usage(ctx, 3, biz, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuotaUsage{Biz: biz}, usage)
+}
@@ -6,17 +6,24 @@ import (
 	"sync"
 
 	"github.com/ecodeclub/webook/internal/ai"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/admin"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/biz"
+	aicache "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/cache"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/config"
-	aicredit "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/credit"
+	aiguard "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/guard"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/log"
+	aiquota "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/quota"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/record"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository/cache"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/ai/internal/web"
 	"github.com/ecodeclub/webook/internal/credit"
+	"github.com/ecodeclub/webook/internal/member"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
 	"github.com/ego-component/egorm"
 	"github.com/google/wire"
 	"gorm.io/gorm"
@@ -24,12 +31,25 @@ import (
 
 func InitModule(db *egorm.Component,
 	hdl handler.Handler,
-	creditSvc *credit.Module) (*ai.Module, error) {
+	creditSvc *credit.Module,
+	memberSvc *member.Module) (*ai.Module, error) {
 	wire.Build(
 		llm.NewLLMService,
 		repository.NewLLMLogRepo,
 		repository.NewLLMCreditLogRepo,
 		repository.NewCachedConfigRepository,
+		repository.NewQuotaRepository,
+		cache.NewQuotaRedisCache,
+		testioc.InitRedis,
+		testioc.InitCache,
+		repository.NewResponseRepository,
+		cache.NewResponseECache,
+		ai.InitResponseCacheHandlerBuilder,
+		ai.InitBudgetHandlerBuilder,
+		ai.InitGuardHandlerBuilder,
+		repository.NewBudgetRepository,
+		cache.NewBudgetRedisCache,
+		ai.InitQuotaService,
 
 		InitLLMCreditLogDAO,
 		dao.NewGORMLLMLogDAO,
@@ -38,21 +58,33 @@ func InitModule(db *egorm.Component,
 		config.NewBuilder,
 		log.NewHandler,
 		record.NewHandler,
-		aicredit.NewHandlerBuilder,
+		aiquota.NewHandlerBuilder,
+		ai.InitCreditHandlerBuilder,
 
 		ai.InitCommonHandlers,
+		ai.InitAdminHandlers,
 		InitHandlerFacade,
 
+		admin.NewService,
+		web.NewAdminHandler,
+
 		wire.Struct(new(ai.Module), "*"),
 		wire.FieldsOf(new(*credit.Module), "Svc"),
+		wire.FieldsOf(new(*member.Module), "Svc"),
 	)
 	return new(ai.Module), nil
 }
 
-func InitHandlerFacade(common []handler.Builder, llm handler.Handler) *biz.FacadeHandler {
-	que := ai.InitQuestionExamineHandler(common, llm)
+func InitHandlerFacade(common []handler.Builder,
+	adminCommon ai.AdminHandlers,
+	guard *aiguard.HandlerBuilder,
+	cache *aicache.HandlerBuilder,
+	llm handler.Handler) *biz.FacadeHandler {
+	que := ai.InitQuestionExamineHandler(common, guard, cache, llm)
+	draft := ai.InitQuestionAnswerDraftHandler(adminCommon, llm)
 	return biz.NewHandler(map[string]handler.Handler{
-		que.Biz(): que,
+		que.Biz():   que,
+		draft.Biz(): draft,
 	})
 }
 
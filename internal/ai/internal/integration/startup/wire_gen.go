@@ -11,48 +11,80 @@ import (
 
 	"github.com/ecodeclub/webook/internal/ai"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository/cache"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/admin"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/biz"
+	cache2 "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/cache"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/config"
-	credit2 "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/credit"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/guard"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/log"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/quota"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/record"
+	"github.com/ecodeclub/webook/internal/ai/internal/web"
 	"github.com/ecodeclub/webook/internal/credit"
+	"github.com/ecodeclub/webook/internal/member"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
 	"github.com/ego-component/egorm"
 	"gorm.io/gorm"
 )
 
 // Injectors from wire.go:
 
-func InitModule(db *gorm.DB, hdl handler.Handler, creditSvc *credit.Module) (*ai.Module, error) {
+func InitModule(db *gorm.DB, hdl handler.Handler, creditSvc *credit.Module, memberSvc *member.Module) (*ai.Module, error) {
 	handlerBuilder := log.NewHandler()
 	configDAO := dao.NewGORMConfigDAO(db)
 	configRepository := repository.NewCachedConfigRepository(configDAO)
 	configHandlerBuilder := config.NewBuilder(configRepository)
-	service := creditSvc.Svc
+	cmdable := testioc.InitRedis()
+	quotaCache := cache.NewQuotaRedisCache(cmdable)
+	quotaRepository := repository.NewQuotaRepository(quotaCache)
+	service := memberSvc.Svc
+	quotaService := ai.InitQuotaService(quotaRepository, service)
+	quotaHandlerBuilder := quota.NewHandlerBuilder(quotaService)
+	serviceService := creditSvc.Svc
 	llmCreditDAO := InitLLMCreditLogDAO(db)
 	llmCreditLogRepo := repository.NewLLMCreditLogRepo(llmCreditDAO)
-	creditHandlerBuilder := credit2.NewHandlerBuilder(service, llmCreditLogRepo)
+	creditHandlerBuilder := ai.InitCreditHandlerBuilder(serviceService, llmCreditLogRepo)
 	llmRecordDAO := dao.NewGORMLLMLogDAO(db)
 	llmLogRepo := repository.NewLLMLogRepo(llmRecordDAO)
 	recordHandlerBuilder := record.NewHandler(llmLogRepo)
-	v := ai.InitCommonHandlers(handlerBuilder, configHandlerBuilder, creditHandlerBuilder, recordHandlerBuilder)
-	facadeHandler := InitHandlerFacade(v, hdl)
+	v := ai.InitCommonHandlers(handlerBuilder, configHandlerBuilder, quotaHandlerBuilder, creditHandlerBuilder, recordHandlerBuilder)
+	budgetCache := cache.NewBudgetRedisCache(cmdable)
+	budgetRepository := repository.NewBudgetRepository(budgetCache)
+	budgetHandlerBuilder := ai.InitBudgetHandlerBuilder(budgetRepository)
+	guardHandlerBuilder := ai.InitGuardHandlerBuilder()
+	adminHandlers := ai.InitAdminHandlers(handlerBuilder, configHandlerBuilder, budgetHandlerBuilder, recordHandlerBuilder)
+	ecacheCache := testioc.InitCache()
+	responseCache := cache.NewResponseECache(ecacheCache)
+	responseRepository := repository.NewResponseRepository(responseCache)
+	cacheHandlerBuilder := ai.InitResponseCacheHandlerBuilder(responseRepository)
+	facadeHandler := InitHandlerFacade(v, adminHandlers, guardHandlerBuilder, cacheHandlerBuilder, hdl)
 	llmService := llm.NewLLMService(facadeHandler)
+	adminService := admin.NewService(configRepository, llmLogRepo, llmCreditLogRepo)
+	adminHandler := web.NewAdminHandler(adminService)
 	module := &ai.Module{
-		Svc: llmService,
+		Svc:      llmService,
+		QuotaSvc: quotaService,
+		AdminHdl: adminHandler,
 	}
 	return module, nil
 }
 
 // wire.go:
 
-func InitHandlerFacade(common []handler.Builder, llm2 handler.Handler) *biz.FacadeHandler {
-	que := ai.InitQuestionExamineHandler(common, llm2)
+func InitHandlerFacade(common []handler.Builder,
+	adminCommon ai.AdminHandlers,
+	guard2 *guard.HandlerBuilder,
+	cache3 *cache2.HandlerBuilder,
+	llm2 handler.Handler) *biz.FacadeHandler {
+	que := ai.InitQuestionExamineHandler(common, guard2, cache3, llm2)
+	draft := ai.InitQuestionAnswerDraftHandler(adminCommon, llm2)
 	return biz.NewHandler(map[string]handler.Handler{
-		que.Biz(): que,
+		que.Biz():   que,
+		draft.Biz(): draft,
 	})
 }
 
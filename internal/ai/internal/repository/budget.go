@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/repository/cache"
+)
+
+// BudgetRepository 管理后台业务的花费，按照自然月统计
+type BudgetRepository interface {
+	Reserve(ctx context.Context, biz string, amount, limit int64) (bool, error)
+	Incr(ctx context.Context, biz string, amount int64) error
+}
+
+type budgetRepository struct {
+	cache cache.BudgetCache
+}
+
+func NewBudgetRepository(c cache.BudgetCache) BudgetRepository {
+	return &budgetRepository{
+		cache: c,
+	}
+}
+
+func (r *budgetRepository) Reserve(ctx context.Context, biz string, amount, limit int64) (bool, error) {
+	return r.cache.Reserve(ctx, biz, amount, limit, time.Now())
+}
+
+func (r *budgetRepository) Incr(ctx context.Context, biz string, amount int64) error {
+	return r.cache.Incr(ctx, biz, amount, time.Now())
+}
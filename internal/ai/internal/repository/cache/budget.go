@@ -0,0 +1,65 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/budget_reserve.lua
+var luaReserve string
+
+// 多留几天，方便跨月的时候查询
+const budgetExpiration = time.Hour * 24 * 35
+
+// BudgetCache 管理后台的业务按月统计花费，单位是积分
+type BudgetCache interface {
+	// Reserve 检查预算并且预留花费，两步是原子的，预算不足的时候返回 false
+	Reserve(ctx context.Context, biz string, amount, limit int64, now time.Time) (bool, error)
+	// Incr 调整花费，amount 可以是负数
+	Incr(ctx context.Context, biz string, amount int64, now time.Time) error
+}
+
+type BudgetRedisCache struct {
+	client redis.Cmdable
+}
+
+func NewBudgetRedisCache(client redis.Cmdable) BudgetCache {
+	return &BudgetRedisCache{
+		client: client,
+	}
+}
+
+func (c *BudgetRedisCache) Reserve(ctx context.Context, biz string, amount, limit int64, now time.Time) (bool, error) {
+	res, err := c.client.Eval(ctx, luaReserve, []string{c.key(biz, now)},
+		amount, limit, int64(budgetExpiration.Seconds())).Int64()
+	if err != nil {
+		return false, err
+	}
+	return res >= 0, nil
+}
+
+func (c *BudgetRedisCache) Incr(ctx context.Context, biz string, amount int64, now time.Time) error {
+	return c.client.Eval(ctx, luaIncr, []string{c.key(biz, now)}, amount, int64(budgetExpiration.Seconds())).Err()
+}
+
+func (c *BudgetRedisCache) key(biz string, now time.Time) string {
+	return fmt.Sprintf("webook:ai:budget:%s:%s", biz, now.Format("2006-01"))
+}
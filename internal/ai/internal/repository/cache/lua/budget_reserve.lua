@@ -0,0 +1,14 @@
+-- KEYS[1] 本月的花费
+-- ARGV[1] 预留的花费, ARGV[2] 本月的预算, ARGV[3] 过期时间，单位秒
+-- 预算已经用完，或者加上预留的花费之后超出预算，返回 -1，否则返回预留之后的花费
+local amount = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local spent = tonumber(redis.call('get', KEYS[1]) or '0')
+if spent >= limit or spent + amount > limit then
+    return -1
+end
+local val = redis.call('incrby', KEYS[1], amount)
+if redis.call('ttl', KEYS[1]) < 0 then
+    redis.call('expire', KEYS[1], ARGV[3])
+end
+return val
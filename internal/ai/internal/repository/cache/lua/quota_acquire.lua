@@ -0,0 +1,26 @@
+-- KEYS[1] 每分钟调用次数, KEYS[2] 每天调用次数, KEYS[3] 每天 token 数
+-- ARGV[1] 每分钟调用次数上限, ARGV[2] 每天调用次数上限, ARGV[3] 每天 token 上限
+-- ARGV[4] 每分钟计数的过期时间, ARGV[5] 每天计数的过期时间，单位秒
+-- 上限为 0 表示不限制
+-- 返回 0 表示成功，1 每分钟调用次数超限，2 每天调用次数超限，3 每天 token 超限
+local minuteLimit = tonumber(ARGV[1])
+local dayLimit = tonumber(ARGV[2])
+local tokenLimit = tonumber(ARGV[3])
+
+if minuteLimit > 0 and tonumber(redis.call('get', KEYS[1]) or '0') >= minuteLimit then
+    return 1
+end
+if dayLimit > 0 and tonumber(redis.call('get', KEYS[2]) or '0') >= dayLimit then
+    return 2
+end
+if tokenLimit > 0 and tonumber(redis.call('get', KEYS[3]) or '0') >= tokenLimit then
+    return 3
+end
+
+if redis.call('incr', KEYS[1]) == 1 then
+    redis.call('expire', KEYS[1], ARGV[4])
+end
+if redis.call('incr', KEYS[2]) == 1 then
+    redis.call('expire', KEYS[2], ARGV[5])
+end
+return 0
@@ -0,0 +1,12 @@
+-- KEYS[1] 计数的 key
+-- ARGV[1] 增量，可以是负数, ARGV[2] 过期时间，单位秒
+-- key 不存在并且是扣减的时候什么也不做，避免出现没有过期时间的负数
+local delta = tonumber(ARGV[1])
+if delta < 0 and redis.call('exists', KEYS[1]) == 0 then
+    return 0
+end
+local val = redis.call('incrby', KEYS[1], delta)
+if val == delta then
+    redis.call('expire', KEYS[1], ARGV[2])
+end
+return val
@@ -0,0 +1,136 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrCallsPerMinuteExceeded = errors.New("每分钟调用次数超过限制")
+	ErrCallsPerDayExceeded    = errors.New("每天调用次数超过限制")
+	ErrTokensPerDayExceeded   = errors.New("每天 token 数超过限制")
+)
+
+var (
+	//go:embed lua/quota_acquire.lua
+	luaAcquire string
+	//go:embed lua/quota_incr.lua
+	luaIncr string
+)
+
+const (
+	minuteExpiration = time.Minute * 2
+	// 多留一天，方便跨天的时候查询
+	dayExpiration = time.Hour * 48
+)
+
+// QuotaCache 使用配额的计数，按照用户和业务分开计数
+type QuotaCache interface {
+	// Acquire 检查是否超过配额，没有超过就增加调用次数
+	Acquire(ctx context.Context, uid int64, biz string, limit domain.QuotaLimit, now time.Time) error
+	// Release 调用失败，退回今天的调用次数
+	Release(ctx context.Context, uid int64, biz string, now time.Time) error
+	IncrTokens(ctx context.Context, uid int64, biz string, tokens int64, now time.Time) error
+	Usage(ctx context.Context, uid int64, biz string, now time.Time) (domain.QuotaUsage, error)
+}
+
+type QuotaRedisCache struct {
+	client redis.Cmdable
+}
+
+func NewQuotaRedisCache(client redis.Cmdable) QuotaCache {
+	return &QuotaRedisCache{
+		client: client,
+	}
+}
+
+func (c *QuotaRedisCache) Acquire(ctx context.Context, uid int64, biz string, limit domain.QuotaLimit, now time.Time) error {
+	res, err := c.client.Eval(ctx, luaAcquire,
+		[]string{c.minuteKey(uid, biz, now), c.dayKey(uid, biz, now), c.tokenKey(uid, biz, now)},
+		limit.CallsPerMinute, limit.CallsPerDay, limit.TokensPerDay,
+		int64(minuteExpiration.Seconds()), int64(dayExpiration.Seconds())).Int()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%w, 上限 %d", ErrCallsPerMinuteExceeded, limit.CallsPerMinute)
+	case 2:
+		return fmt.Errorf("%w, 上限 %d", ErrCallsPerDayExceeded, limit.CallsPerDay)
+	case 3:
+		return fmt.Errorf("%w, 上限 %d", ErrTokensPerDayExceeded, limit.TokensPerDay)
+	default:
+		return fmt.Errorf("未知的配额检查结果 %d", res)
+	}
+}
+
+func (c *QuotaRedisCache) Release(ctx context.Context, uid int64, biz string, now time.Time) error {
+	return c.incr(ctx, c.dayKey(uid, biz, now), -1)
+}
+
+func (c *QuotaRedisCache) IncrTokens(ctx context.Context, uid int64, biz string, tokens int64, now time.Time) error {
+	return c.incr(ctx, c.tokenKey(uid, biz, now), tokens)
+}
+
+func (c *QuotaRedisCache) Usage(ctx context.Context, uid int64, biz string, now time.Time) (domain.QuotaUsage, error) {
+	vals, err := c.client.MGet(ctx, c.minuteKey(uid, biz, now),
+		c.dayKey(uid, biz, now), c.tokenKey(uid, biz, now)).Result()
+	if err != nil {
+		return domain.QuotaUsage{}, err
+	}
+	counts := make([]int64, len(vals))
+	for i, val := range vals {
+		// key 不存在的时候是 nil
+		str, ok := val.(string)
+		if !ok {
+			continue
+		}
+		_, err = fmt.Sscan(str, &counts[i])
+		if err != nil {
+			return domain.QuotaUsage{}, err
+		}
+	}
+	return domain.QuotaUsage{
+		Biz:             biz,
+		CallsThisMinute: counts[0],
+		CallsToday:      counts[1],
+		TokensToday:     counts[2],
+	}, nil
+}
+
+func (c *QuotaRedisCache) incr(ctx context.Context, key string, delta int64) error {
+	return c.client.Eval(ctx, luaIncr, []string{key}, delta, int64(dayExpiration.Seconds())).Err()
+}
+
+func (c *QuotaRedisCache) minuteKey(uid int64, biz string, now time.Time) string {
+	return fmt.Sprintf("webook:ai:quota:%s:%d:calls:%s", biz, uid, now.Format("200601021504"))
+}
+
+func (c *QuotaRedisCache) dayKey(uid int64, biz string, now time.Time) string {
+	return fmt.Sprintf("webook:ai:quota:%s:%d:calls:%s", biz, uid, now.Format(time.DateOnly))
+}
+
+func (c *QuotaRedisCache) tokenKey(uid int64, biz string, now time.Time) string {
+	return fmt.Sprintf("webook:ai:quota:%s:%d:tokens:%s", biz, uid, now.Format(time.DateOnly))
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ecodeclub/ecache"
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+)
+
+var ErrResponseNotFound = errors.New("没有缓存的响应")
+
+// ResponseCache 缓存 LLM 的响应，相同的 prompt 直接返回缓存的回答
+type ResponseCache interface {
+	Get(ctx context.Context, key string) (domain.LLMResponse, error)
+	Set(ctx context.Context, key string, resp domain.LLMResponse, expiration time.Duration) error
+}
+
+type ResponseECache struct {
+	ec ecache.Cache
+}
+
+func NewResponseECache(ec ecache.Cache) ResponseCache {
+	return &ResponseECache{
+		ec: &ecache.NamespaceCache{
+			Namespace: "ai:response:",
+			C:         ec,
+		},
+	}
+}
+
+func (c *ResponseECache) Get(ctx context.Context, key string) (domain.LLMResponse, error) {
+	val := c.ec.Get(ctx, key)
+	if val.KeyNotFound() {
+		return domain.LLMResponse{}, ErrResponseNotFound
+	}
+	var res response
+	err := val.JSONScan(&res)
+	if err != nil {
+		return domain.LLMResponse{}, err
+	}
+	return domain.LLMResponse{
+		Tokens: res.Tokens,
+		Answer: res.Answer,
+	}, nil
+}
+
+func (c *ResponseECache) Set(ctx context.Context, key string, resp domain.LLMResponse, expiration time.Duration) error {
+	data, err := json.Marshal(response{
+		Tokens: resp.Tokens,
+		Answer: resp.Answer,
+	})
+	if err != nil {
+		return err
+	}
+	return c.ec.Set(ctx, key, data, expiration)
+}
+
+type response struct {
+	// 原始调用花费的 token 数，只用于排查问题
+	Tokens int64  `json:"tokens"`
+	Answer string `json:"answer"`
+}
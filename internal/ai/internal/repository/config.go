@@ -16,13 +16,26 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository/dao"
 )
 
 type ConfigRepository interface {
 	GetConfig(ctx context.Context, biz string) (domain.BizConfig, error)
+	GetConfigById(ctx context.Context, id int64) (domain.BizConfig, error)
+	SaveConfig(ctx context.Context, c domain.BizConfig) (int64, error)
+	ListConfigs(ctx context.Context, offset, limit int) ([]domain.BizConfig, error)
+	CountConfigs(ctx context.Context) (int64, error)
+	DeleteConfig(ctx context.Context, id int64) error
+	GetPromptVersion(ctx context.Context, biz string, version int64) (domain.PromptVersion, error)
+	ListPromptVersions(ctx context.Context, biz string) ([]domain.PromptVersion, error)
+	CreatePromptVersion(ctx context.Context, v domain.PromptVersion) (int64, error)
+	ActivatePromptVersion(ctx context.Context, biz string, version int64) error
+	SetPromptExperiments(ctx context.Context, biz string, exps []domain.PromptExperiment) error
 }
 
 // CachedConfigRepository 这个是一定要搞缓存的
@@ -40,9 +53,150 @@ func (repo *CachedConfigRepository) GetConfig(ctx context.Context, biz string) (
 	if err != nil {
 		return domain.BizConfig{}, err
 	}
+	return repo.toDomain(res), nil
+}
+
+func (repo *CachedConfigRepository) GetConfigById(ctx context.Context, id int64) (domain.BizConfig, error) {
+	res, err := repo.dao.GetConfigById(ctx, id)
+	if err != nil {
+		return domain.BizConfig{}, err
+	}
+	return repo.toDomain(res), nil
+}
+
+func (repo *CachedConfigRepository) SaveConfig(ctx context.Context, c domain.BizConfig) (int64, error) {
+	return repo.dao.SaveConfig(ctx, repo.toEntity(c))
+}
+
+func (repo *CachedConfigRepository) ListConfigs(ctx context.Context, offset, limit int) ([]domain.BizConfig, error) {
+	res, err := repo.dao.ListConfigs(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.BizConfig) domain.BizConfig {
+		return repo.toDomain(src)
+	}), nil
+}
+
+func (repo *CachedConfigRepository) CountConfigs(ctx context.Context) (int64, error) {
+	return repo.dao.CountConfigs(ctx)
+}
+
+func (repo *CachedConfigRepository) DeleteConfig(ctx context.Context, id int64) error {
+	return repo.dao.DeleteConfig(ctx, id)
+}
+
+func (repo *CachedConfigRepository) GetPromptVersion(ctx context.Context, biz string, version int64) (domain.PromptVersion, error) {
+	res, err := repo.dao.GetPromptVersion(ctx, biz, version)
+	if err != nil {
+		return domain.PromptVersion{}, err
+	}
+	return repo.versionToDomain(res), nil
+}
+
+func (repo *CachedConfigRepository) ListPromptVersions(ctx context.Context, biz string) ([]domain.PromptVersion, error) {
+	res, err := repo.dao.ListPromptVersions(ctx, biz)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.PromptVersion) domain.PromptVersion {
+		return repo.versionToDomain(src)
+	}), nil
+}
+
+func (repo *CachedConfigRepository) CreatePromptVersion(ctx context.Context, v domain.PromptVersion) (int64, error) {
+	return repo.dao.CreatePromptVersion(ctx, dao.PromptVersion{
+		Biz:            v.Biz,
+		PromptTemplate: v.PromptTemplate,
+		Description:    v.Description,
+	})
+}
+
+func (repo *CachedConfigRepository) ActivatePromptVersion(ctx context.Context, biz string, version int64) error {
+	return repo.dao.ActivatePromptVersion(ctx, biz, version)
+}
+
+func (repo *CachedConfigRepository) SetPromptExperiments(ctx context.Context, biz string, exps []domain.PromptExperiment) error {
+	return repo.dao.SetPromptExperiments(ctx, biz, slice.Map(exps, func(idx int, src domain.PromptExperiment) dao.PromptExperiment {
+		return dao.PromptExperiment{
+			Version: src.Version,
+			Weight:  src.Weight,
+		}
+	}))
+}
+
+func (repo *CachedConfigRepository) versionToDomain(v dao.PromptVersion) domain.PromptVersion {
+	return domain.PromptVersion{
+		Biz:            v.Biz,
+		Version:        v.Version,
+		PromptTemplate: v.PromptTemplate,
+		Description:    v.Description,
+		Ctime:          v.Ctime,
+		Utime:          v.Utime,
+	}
+}
+
+func (repo *CachedConfigRepository) toDomain(c dao.BizConfig) domain.BizConfig {
 	return domain.BizConfig{
-		MaxInput:       res.MaxInput,
-		PromptTemplate: res.PromptTemplate,
-		KnowledgeId:    res.KnowledgeId,
-	}, nil
+		Id:             c.Id,
+		Biz:            c.Biz,
+		MaxInput:       c.MaxInput,
+		PromptTemplate: c.PromptTemplate,
+		KnowledgeId:    c.KnowledgeId,
+		Platform:       c.Platform,
+		Model:          c.Model,
+		CacheTTL:       time.Duration(c.CacheTTL) * time.Second,
+		DisableCache:   c.DisableCache,
+		ActiveVersion:  c.ActiveVersion,
+		Experiments: slice.Map(c.Experiments.Val, func(idx int, src dao.PromptExperiment) domain.PromptExperiment {
+			return domain.PromptExperiment{
+				Version: src.Version,
+				Weight:  src.Weight,
+			}
+		}),
+		Fallbacks: slice.Map(c.Fallbacks.Val, func(idx int, src dao.PlatformConfig) domain.PlatformConfig {
+			return domain.PlatformConfig{
+				Platform: src.Platform,
+				Model:    src.Model,
+			}
+		}),
+		Guard: domain.GuardRules{
+			Disabled: c.Guard.Val.Disabled,
+			Keywords: c.Guard.Val.Keywords,
+			Patterns: c.Guard.Val.Patterns,
+		},
+		Ctime: c.Ctime,
+		Utime: c.Utime,
+	}
+}
+
+func (repo *CachedConfigRepository) toEntity(c domain.BizConfig) dao.BizConfig {
+	return dao.BizConfig{
+		Id:             c.Id,
+		Biz:            c.Biz,
+		MaxInput:       c.MaxInput,
+		PromptTemplate: c.PromptTemplate,
+		KnowledgeId:    c.KnowledgeId,
+		Platform:       c.Platform,
+		Model:          c.Model,
+		Fallbacks: sqlx.JsonColumn[[]dao.PlatformConfig]{
+			Val: slice.Map(c.Fallbacks, func(idx int, src domain.PlatformConfig) dao.PlatformConfig {
+				return dao.PlatformConfig{
+					Platform: src.Platform,
+					Model:    src.Model,
+				}
+			}),
+			Valid: len(c.Fallbacks) > 0,
+		},
+		CacheTTL:     int64(c.CacheTTL / time.Second),
+		DisableCache: c.DisableCache,
+		Guard: sqlx.JsonColumn[dao.GuardRules]{
+			Val: dao.GuardRules{
+				Disabled: c.Guard.Disabled,
+				Keywords: c.Guard.Keywords,
+				Patterns: c.Guard.Patterns,
+			},
+			Valid: true,
+		},
+	}
 }
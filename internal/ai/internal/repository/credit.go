@@ -3,12 +3,15 @@ package repository
 import (
 	"context"
 
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository/dao"
 )
 
 type LLMCreditLogRepo interface {
 	SaveCredit(ctx context.Context, l domain.LLMCredit) (int64, error)
+	List(ctx context.Context, filter domain.LLMFilter, offset, limit int) ([]domain.LLMCredit, error)
+	Count(ctx context.Context, filter domain.LLMFilter) (int64, error)
 }
 
 type llmCreditLogRepo struct {
@@ -36,3 +39,30 @@ func (g *llmCreditLogRepo) SaveCredit(ctx context.Context, l domain.LLMCredit) (
 	logEntity := g.creditLogToEntity(l)
 	return g.logDao.SaveCredit(ctx, logEntity)
 }
+
+func (g *llmCreditLogRepo) List(ctx context.Context, filter domain.LLMFilter, offset, limit int) ([]domain.LLMCredit, error) {
+	res, err := g.logDao.List(ctx, toFilterEntity(filter), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.LLMCredit) domain.LLMCredit {
+		return g.creditLogToDomain(src)
+	}), nil
+}
+
+func (g *llmCreditLogRepo) Count(ctx context.Context, filter domain.LLMFilter) (int64, error) {
+	return g.logDao.Count(ctx, toFilterEntity(filter))
+}
+
+func (g *llmCreditLogRepo) creditLogToDomain(l dao.LLMCredit) domain.LLMCredit {
+	return domain.LLMCredit{
+		Id:     l.Id,
+		Tid:    l.Tid,
+		Uid:    l.Uid,
+		Biz:    l.Biz,
+		Amount: l.Amount,
+		Status: domain.CreditStatus(l.Status),
+		Ctime:  l.Ctime,
+		Utime:  l.Utime,
+	}
+}
@@ -16,12 +16,31 @@ package dao
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ego-component/egorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ConfigDAO interface {
 	GetConfig(ctx context.Context, biz string) (BizConfig, error)
+	GetConfigById(ctx context.Context, id int64) (BizConfig, error)
+	// SaveConfig 新建或者更新配置，不会修改提示词版本和实验
+	SaveConfig(ctx context.Context, c BizConfig) (int64, error)
+	ListConfigs(ctx context.Context, offset, limit int) ([]BizConfig, error)
+	CountConfigs(ctx context.Context) (int64, error)
+	DeleteConfig(ctx context.Context, id int64) error
+	GetPromptVersion(ctx context.Context, biz string, version int64) (PromptVersion, error)
+	ListPromptVersions(ctx context.Context, biz string) ([]PromptVersion, error)
+	// CreatePromptVersion 创建一个新版本，版本号是当前最大版本号加一
+	CreatePromptVersion(ctx context.Context, v PromptVersion) (int64, error)
+	// ActivatePromptVersion 切换生效的版本，同时结束正在进行的实验
+	// 回滚也是用这个方法
+	ActivatePromptVersion(ctx context.Context, biz string, version int64) error
+	SetPromptExperiments(ctx context.Context, biz string, exps []PromptExperiment) error
 }
 
 type GORMConfigDAO struct {
@@ -38,12 +57,142 @@ func (dao *GORMConfigDAO) GetConfig(ctx context.Context, biz string) (BizConfig,
 	return res, err
 }
 
+func (dao *GORMConfigDAO) GetConfigById(ctx context.Context, id int64) (BizConfig, error) {
+	var res BizConfig
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMConfigDAO) SaveConfig(ctx context.Context, c BizConfig) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"biz", "max_input", "prompt_template", "knowledge_id",
+			"platform", "model", "fallbacks", "cache_ttl", "disable_cache", "guard", "utime",
+		}),
+	}).Create(&c).Error
+	return c.Id, err
+}
+
+func (dao *GORMConfigDAO) ListConfigs(ctx context.Context, offset, limit int) ([]BizConfig, error) {
+	var res []BizConfig
+	err := dao.db.WithContext(ctx).Order("id DESC").
+		Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMConfigDAO) CountConfigs(ctx context.Context) (int64, error) {
+	var res int64
+	err := dao.db.WithContext(ctx).Model(&BizConfig{}).Count(&res).Error
+	return res, err
+}
+
+func (dao *GORMConfigDAO) DeleteConfig(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Where("id = ?", id).Delete(&BizConfig{}).Error
+}
+
+func (dao *GORMConfigDAO) GetPromptVersion(ctx context.Context, biz string, version int64) (PromptVersion, error) {
+	var res PromptVersion
+	err := dao.db.WithContext(ctx).Where("biz = ? AND version = ?", biz, version).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMConfigDAO) ListPromptVersions(ctx context.Context, biz string) ([]PromptVersion, error) {
+	var res []PromptVersion
+	err := dao.db.WithContext(ctx).Where("biz = ?", biz).Order("version DESC").Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMConfigDAO) CreatePromptVersion(ctx context.Context, v PromptVersion) (int64, error) {
+	now := time.Now().UnixMilli()
+	v.Ctime = now
+	v.Utime = now
+	// 并发创建的时候，唯一索引会保证版本号不重复
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int64
+		err := tx.Model(&PromptVersion{}).Where("biz = ?", v.Biz).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+		v.Version = latest + 1
+		return tx.Create(&v).Error
+	})
+	return v.Version, err
+}
+
+func (dao *GORMConfigDAO) ActivatePromptVersion(ctx context.Context, biz string, version int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var cnt int64
+		err := tx.Model(&PromptVersion{}).
+			Where("biz = ? AND version = ?", biz, version).Count(&cnt).Error
+		if err != nil {
+			return err
+		}
+		if cnt == 0 {
+			return fmt.Errorf("%w, biz %s, version %d", gorm.ErrRecordNotFound, biz, version)
+		}
+		return dao.updateConfig(tx, biz, map[string]any{
+			"active_version": version,
+			"experiments":    sqlx.JsonColumn[[]PromptExperiment]{},
+		})
+	})
+}
+
+func (dao *GORMConfigDAO) SetPromptExperiments(ctx context.Context, biz string, exps []PromptExperiment) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		versions := make([]int64, 0, len(exps))
+		for _, exp := range exps {
+			versions = append(versions, exp.Version)
+		}
+		if len(versions) > 0 {
+			var cnt int64
+			err := tx.Model(&PromptVersion{}).
+				Where("biz = ? AND version IN ?", biz, versions).Count(&cnt).Error
+			if err != nil {
+				return err
+			}
+			if int(cnt) != len(exps) {
+				return fmt.Errorf("%w, 实验中有不存在或者重复的版本, biz %s", gorm.ErrRecordNotFound, biz)
+			}
+		}
+		return dao.updateConfig(tx, biz, map[string]any{
+			"experiments": sqlx.JsonColumn[[]PromptExperiment]{Val: exps, Valid: len(exps) > 0},
+		})
+	})
+}
+
+func (dao *GORMConfigDAO) updateConfig(tx *gorm.DB, biz string, updates map[string]any) error {
+	updates["utime"] = time.Now().UnixMilli()
+	res := tx.Model(&BizConfig{}).Where("biz = ?", biz).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w, biz %s", gorm.ErrRecordNotFound, biz)
+	}
+	return nil
+}
+
 type BizConfig struct {
 	Id             int64  `gorm:"primaryKey;autoIncrement;comment:AI biz 配置表ID"`
 	Biz            string `gorm:"type:varchar(256);uniqueIndex;not null;comment:业务类型名"`
 	MaxInput       int    `gorm:"comment:最大输入长度"`
 	PromptTemplate string
 	KnowledgeId    string `gorm:"type:varchar(256);not null;comment:使用的知识库 ID"`
+	Platform       string `gorm:"type:varchar(64);not null;default:'';comment:使用的平台，为空则使用默认平台"`
+	Model          string `gorm:"type:varchar(128);not null;default:'';comment:使用的模型，为空则使用平台默认模型"`
+	// 主平台失败之后按照顺序尝试的备用平台
+	Fallbacks    sqlx.JsonColumn[[]PlatformConfig] `gorm:"type:text;comment:备用平台"`
+	CacheTTL     int64                             `gorm:"not null;default:0;comment:响应缓存的过期时间，单位秒，0 使用默认值"`
+	DisableCache bool                              `gorm:"not null;default:false;comment:是否禁用响应缓存"`
+	// 生效的提示词版本，0 表示直接使用 PromptTemplate
+	ActiveVersion int64                               `gorm:"not null;default:0;comment:生效的提示词版本"`
+	Experiments   sqlx.JsonColumn[[]PromptExperiment] `gorm:"type:text;comment:提示词 A/B 实验"`
+	Guard         sqlx.JsonColumn[GuardRules]         `gorm:"type:text;comment:用户输入的安全检查规则"`
 	// 其它字段按需添加
 	Ctime int64
 	Utime int64
@@ -52,3 +201,34 @@ type BizConfig struct {
 func (c BizConfig) TableName() string {
 	return "ai_biz_configs"
 }
+
+// PromptVersion 提示词版本，创建之后就不再修改
+type PromptVersion struct {
+	Id             int64  `gorm:"primaryKey;autoIncrement"`
+	Biz            string `gorm:"type:varchar(256);not null;uniqueIndex:uniq_biz_version;comment:业务类型名"`
+	Version        int64  `gorm:"not null;uniqueIndex:uniq_biz_version;comment:版本号，从 1 开始"`
+	PromptTemplate string `gorm:"type:text;comment:提示词模板"`
+	Description    string `gorm:"type:varchar(512);not null;default:'';comment:版本说明"`
+	Ctime          int64
+	Utime          int64
+}
+
+func (v PromptVersion) TableName() string {
+	return "ai_prompt_versions"
+}
+
+type GuardRules struct {
+	Disabled bool     `json:"disabled"`
+	Keywords []string `json:"keywords"`
+	Patterns []string `json:"patterns"`
+}
+
+type PromptExperiment struct {
+	Version int64 `json:"version"`
+	Weight  int64 `json:"weight"`
+}
+
+type PlatformConfig struct {
+	Platform string `json:"platform"`
+	Model    string `json:"model"`
+}
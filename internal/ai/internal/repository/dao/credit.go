@@ -26,6 +26,8 @@ func (l LLMCredit) TableName() string {
 
 type LLMCreditDAO interface {
 	SaveCredit(ctx context.Context, l LLMCredit) (int64, error)
+	List(ctx context.Context, filter Filter, offset, limit int) ([]LLMCredit, error)
+	Count(ctx context.Context, filter Filter) (int64, error)
 }
 
 type GORMLLMCreditDAO struct {
@@ -45,7 +47,22 @@ func (g *GORMLLMCreditDAO) SaveCredit(ctx context.Context, l LLMCredit) (int64,
 	err := g.db.WithContext(ctx).Model(&LLMCredit{}).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "id"}},
-			DoUpdates: clause.AssignmentColumns([]string{"status", "utime"}),
+			DoUpdates: clause.AssignmentColumns([]string{"amount", "status", "utime"}),
 		}).Create(&l).Error
 	return l.Id, err
 }
+
+func (g *GORMLLMCreditDAO) List(ctx context.Context, filter Filter, offset, limit int) ([]LLMCredit, error) {
+	var res []LLMCredit
+	err := filter.apply(g.db.WithContext(ctx)).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMLLMCreditDAO) Count(ctx context.Context, filter Filter) (int64, error) {
+	var res int64
+	err := filter.apply(g.db.WithContext(ctx).Model(&LLMCredit{})).Count(&res).Error
+	return res, err
+}
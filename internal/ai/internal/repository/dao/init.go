@@ -3,9 +3,14 @@ package dao
 import "github.com/ego-component/egorm"
 
 func InitTables(db *egorm.Component) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&LLMCredit{},
 		&LLMRecord{},
 		&BizConfig{},
+		&PromptVersion{},
 	)
+	if err != nil {
+		return err
+	}
+	return initBizConfigs(db)
 }
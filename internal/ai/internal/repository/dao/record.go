@@ -21,11 +21,16 @@ import (
 
 	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ego-component/egorm"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type LLMRecordDAO interface {
 	Save(ctx context.Context, r LLMRecord) (int64, error)
+	List(ctx context.Context, filter Filter, offset, limit int) ([]LLMRecord, error)
+	Count(ctx context.Context, filter Filter) (int64, error)
+	// DailyUsage 按天统计用量，dimension 是 biz 或者 uid
+	DailyUsage(ctx context.Context, filter Filter, dimension string, offset, limit int) ([]DailyUsage, error)
 }
 
 // GORMLLMLogDAO => GORM LLM LogDAO
@@ -49,6 +54,34 @@ func (g *GORMLLMLogDAO) Save(ctx context.Context, record LLMRecord) (int64, erro
 	return record.Id, err
 }
 
+func (g *GORMLLMLogDAO) List(ctx context.Context, filter Filter, offset, limit int) ([]LLMRecord, error) {
+	var res []LLMRecord
+	err := filter.apply(g.db.WithContext(ctx)).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMLLMLogDAO) Count(ctx context.Context, filter Filter) (int64, error) {
+	var res int64
+	err := filter.apply(g.db.WithContext(ctx).Model(&LLMRecord{})).Count(&res).Error
+	return res, err
+}
+
+func (g *GORMLLMLogDAO) DailyUsage(ctx context.Context, filter Filter, dimension string, offset, limit int) ([]DailyUsage, error) {
+	var res []DailyUsage
+	// dimension 只能是 biz 或者 uid，由上层保证，不能直接拼接用户输入
+	err := filter.apply(g.db.WithContext(ctx).Model(&LLMRecord{})).
+		Select("FROM_UNIXTIME(ctime DIV 1000, '%Y-%m-%d') AS date, " + dimension +
+			", COUNT(*) AS calls, SUM(tokens) AS tokens, SUM(amount) AS amount").
+		Group("date, " + dimension).
+		Order("date DESC, tokens DESC").
+		Offset(offset).Limit(limit).
+		Scan(&res).Error
+	return res, err
+}
+
 func (g *GORMLLMLogDAO) FirstLog(ctx context.Context, id int64) (*LLMRecord, error) {
 	logModel := &LLMRecord{}
 	err := g.db.WithContext(ctx).Model(&LLMRecord{}).Where("id = ?", id).First(logModel).Error
@@ -67,6 +100,8 @@ type LLMRecord struct {
 	KnowledgeId    string                    `gorm:"type:varchar(256);not null;comment:使用的知识库 ID"`
 	PromptTemplate sql.NullString            `gorm:"type:text;comment:PromptTemplate 模板，加上请求参数构成一个完整的 prompt"`
 	Answer         sql.NullString            `gorm:"type:text;comment:llm的回答"`
+	PromptVersion  int64                     `gorm:"not null;default:0;comment:使用的提示词版本，0 表示使用业务配置里面的提示词"`
+	Cached         bool                      `gorm:"not null;default:false;comment:是否命中了响应缓存"`
 	Ctime          int64
 	Utime          int64
 }
@@ -74,3 +109,40 @@ type LLMRecord struct {
 func (l LLMRecord) TableName() string {
 	return "llm_records"
 }
+
+// Filter 调用记录和扣费记录共用的过滤条件，零值不参与过滤
+type Filter struct {
+	Uid       int64
+	Biz       string
+	Status    []int
+	StartTime int64
+	EndTime   int64
+}
+
+func (f Filter) apply(db *gorm.DB) *gorm.DB {
+	if f.Uid > 0 {
+		db = db.Where("uid = ?", f.Uid)
+	}
+	if f.Biz != "" {
+		db = db.Where("biz = ?", f.Biz)
+	}
+	if len(f.Status) > 0 {
+		db = db.Where("status IN ?", f.Status)
+	}
+	if f.StartTime > 0 {
+		db = db.Where("ctime >= ?", f.StartTime)
+	}
+	if f.EndTime > 0 {
+		db = db.Where("ctime < ?", f.EndTime)
+	}
+	return db
+}
+
+type DailyUsage struct {
+	Date   string
+	Biz    string
+	Uid    int64
+	Calls  int64
+	Tokens int64
+	Amount int64
+}
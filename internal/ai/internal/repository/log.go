@@ -17,6 +17,7 @@ package repository
 import (
 	"context"
 
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository/dao"
@@ -24,6 +25,9 @@ import (
 
 type LLMLogRepo interface {
 	SaveLog(ctx context.Context, l domain.LLMRecord) (int64, error)
+	List(ctx context.Context, filter domain.LLMFilter, offset, limit int) ([]domain.LLMRecord, error)
+	Count(ctx context.Context, filter domain.LLMFilter) (int64, error)
+	DailyUsage(ctx context.Context, filter domain.LLMFilter, dimension domain.UsageDimension, offset, limit int) ([]domain.DailyUsage, error)
 }
 
 // 调用日志
@@ -58,5 +62,69 @@ func (g *llmLogDAO) toEntity(r domain.LLMRecord) dao.LLMRecord {
 		Status:         r.Status.ToUint8(),
 		PromptTemplate: sqlx.NewNullString(r.PromptTemplate),
 		Answer:         sqlx.NewNullString(r.Answer),
+		PromptVersion:  r.PromptVersion,
+		Cached:         r.Cached,
+	}
+}
+
+func (g *llmLogDAO) List(ctx context.Context, filter domain.LLMFilter, offset, limit int) ([]domain.LLMRecord, error) {
+	res, err := g.logDao.List(ctx, toFilterEntity(filter), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.LLMRecord) domain.LLMRecord {
+		return g.toDomain(src)
+	}), nil
+}
+
+func (g *llmLogDAO) Count(ctx context.Context, filter domain.LLMFilter) (int64, error) {
+	return g.logDao.Count(ctx, toFilterEntity(filter))
+}
+
+func (g *llmLogDAO) DailyUsage(ctx context.Context, filter domain.LLMFilter,
+	dimension domain.UsageDimension, offset, limit int) ([]domain.DailyUsage, error) {
+	res, err := g.logDao.DailyUsage(ctx, toFilterEntity(filter), string(dimension), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.DailyUsage) domain.DailyUsage {
+		return domain.DailyUsage{
+			Date:   src.Date,
+			Biz:    src.Biz,
+			Uid:    src.Uid,
+			Calls:  src.Calls,
+			Tokens: src.Tokens,
+			Amount: src.Amount,
+		}
+	}), nil
+}
+
+func (g *llmLogDAO) toDomain(r dao.LLMRecord) domain.LLMRecord {
+	return domain.LLMRecord{
+		Id:             r.Id,
+		Tid:            r.Tid,
+		Uid:            r.Uid,
+		Biz:            r.Biz,
+		Tokens:         r.Tokens,
+		Amount:         r.Amount,
+		Input:          r.Input.Val,
+		Status:         domain.RecordStatus(r.Status),
+		KnowledgeId:    r.KnowledgeId,
+		PromptTemplate: r.PromptTemplate.String,
+		Answer:         r.Answer.String,
+		PromptVersion:  r.PromptVersion,
+		Cached:         r.Cached,
+		Ctime:          r.Ctime,
+		Utime:          r.Utime,
+	}
+}
+
+func toFilterEntity(f domain.LLMFilter) dao.Filter {
+	return dao.Filter{
+		Uid:       f.Uid,
+		Biz:       f.Biz,
+		Status:    f.Status,
+		StartTime: f.StartTime,
+		EndTime:   f.EndTime,
 	}
 }
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository/cache"
+)
+
+var (
+	ErrCallsPerMinuteExceeded = cache.ErrCallsPerMinuteExceeded
+	ErrCallsPerDayExceeded    = cache.ErrCallsPerDayExceeded
+	ErrTokensPerDayExceeded   = cache.ErrTokensPerDayExceeded
+)
+
+// QuotaRepository 使用配额的计数
+// 计数是按照自然分钟和自然日来算的
+type QuotaRepository interface {
+	Acquire(ctx context.Context, uid int64, biz string, limit domain.QuotaLimit) error
+	Release(ctx context.Context, uid int64, biz string) error
+	IncrTokens(ctx context.Context, uid int64, biz string, tokens int64) error
+	Usage(ctx context.Context, uid int64, biz string) (domain.QuotaUsage, error)
+}
+
+type quotaRepository struct {
+	cache cache.QuotaCache
+}
+
+func NewQuotaRepository(c cache.QuotaCache) QuotaRepository {
+	return &quotaRepository{
+		cache: c,
+	}
+}
+
+func (r *quotaRepository) Acquire(ctx context.Context, uid int64, biz string, limit domain.QuotaLimit) error {
+	return r.cache.Acquire(ctx, uid, biz, limit, time.Now())
+}
+
+func (r *quotaRepository) Release(ctx context.Context, uid int64, biz string) error {
+	return r.cache.Release(ctx, uid, biz, time.Now())
+}
+
+func (r *quotaRepository) IncrTokens(ctx context.Context, uid int64, biz string, tokens int64) error {
+	return r.cache.IncrTokens(ctx, uid, biz, tokens, time.Now())
+}
+
+func (r *quotaRepository) Usage(ctx context.Context, uid int64, biz string) (domain.QuotaUsage, error) {
+	return r.cache.Usage(ctx, uid, biz, time.Now())
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository/cache"
+)
+
+var ErrResponseNotFound = cache.ErrResponseNotFound
+
+// ResponseRepository 缓存的 LLM 响应
+type ResponseRepository interface {
+	Get(ctx context.Context, key string) (domain.LLMResponse, error)
+	Save(ctx context.Context, key string, resp domain.LLMResponse, expiration time.Duration) error
+}
+
+type responseRepository struct {
+	cache cache.ResponseCache
+}
+
+func NewResponseRepository(c cache.ResponseCache) ResponseRepository {
+	return &responseRepository{
+		cache: c,
+	}
+}
+
+func (r *responseRepository) Get(ctx context.Context, key string) (domain.LLMResponse, error) {
+	return r.cache.Get(ctx, key)
+}
+
+func (r *responseRepository) Save(ctx context.Context, key string, resp domain.LLMResponse, expiration time.Duration) error {
+	return r.cache.Set(ctx, key, resp, expiration)
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"golang.org/x/sync/errgroup"
+)
+
+// Service 管理后台使用的 AI 服务，包括业务配置管理、调用记录查询和用量统计
+type Service interface {
+	SaveConfig(ctx context.Context, cfg domain.BizConfig) (int64, error)
+	ConfigDetail(ctx context.Context, id int64) (domain.BizConfig, error)
+	ListConfigs(ctx context.Context, offset, limit int) ([]domain.BizConfig, int64, error)
+	DeleteConfig(ctx context.Context, id int64) error
+
+	ListPromptVersions(ctx context.Context, biz string) ([]domain.PromptVersion, error)
+	CreatePromptVersion(ctx context.Context, v domain.PromptVersion) (int64, error)
+	ActivatePromptVersion(ctx context.Context, biz string, version int64) error
+	SetPromptExperiments(ctx context.Context, biz string, exps []domain.PromptExperiment) error
+
+	ListRecords(ctx context.Context, filter domain.LLMFilter, offset, limit int) ([]domain.LLMRecord, int64, error)
+	ListCredits(ctx context.Context, filter domain.LLMFilter, offset, limit int) ([]domain.LLMCredit, int64, error)
+	DailyUsage(ctx context.Context, filter domain.LLMFilter,
+		dimension domain.UsageDimension, offset, limit int) ([]domain.DailyUsage, error)
+}
+
+type service struct {
+	configRepo repository.ConfigRepository
+	recordRepo repository.LLMLogRepo
+	creditRepo repository.LLMCreditLogRepo
+}
+
+func NewService(configRepo repository.ConfigRepository,
+	recordRepo repository.LLMLogRepo,
+	creditRepo repository.LLMCreditLogRepo) Service {
+	return &service{
+		configRepo: configRepo,
+		recordRepo: recordRepo,
+		creditRepo: creditRepo,
+	}
+}
+
+func (s *service) SaveConfig(ctx context.Context, cfg domain.BizConfig) (int64, error) {
+	// 非法的正则表达式在检查的时候会被跳过，所以保存之前就要拒绝
+	if err := cfg.Guard.Validate(); err != nil {
+		return 0, err
+	}
+	return s.configRepo.SaveConfig(ctx, cfg)
+}
+
+func (s *service) ConfigDetail(ctx context.Context, id int64) (domain.BizConfig, error) {
+	return s.configRepo.GetConfigById(ctx, id)
+}
+
+func (s *service) ListConfigs(ctx context.Context, offset, limit int) ([]domain.BizConfig, int64, error) {
+	var (
+		eg   errgroup.Group
+		list []domain.BizConfig
+		cnt  int64
+	)
+	eg.Go(func() error {
+		var err error
+		list, err = s.configRepo.ListConfigs(ctx, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		cnt, err = s.configRepo.CountConfigs(ctx)
+		return err
+	})
+	return list, cnt, eg.Wait()
+}
+
+func (s *service) DeleteConfig(ctx context.Context, id int64) error {
+	return s.configRepo.DeleteConfig(ctx, id)
+}
+
+func (s *service) ListPromptVersions(ctx context.Context, biz string) ([]domain.PromptVersion, error) {
+	return s.configRepo.ListPromptVersions(ctx, biz)
+}
+
+func (s *service) CreatePromptVersion(ctx context.Context, v domain.PromptVersion) (int64, error) {
+	return s.configRepo.CreatePromptVersion(ctx, v)
+}
+
+func (s *service) ActivatePromptVersion(ctx context.Context, biz string, version int64) error {
+	return s.configRepo.ActivatePromptVersion(ctx, biz, version)
+}
+
+func (s *service) SetPromptExperiments(ctx context.Context, biz string, exps []domain.PromptExperiment) error {
+	return s.configRepo.SetPromptExperiments(ctx, biz, exps)
+}
+
+func (s *service) ListRecords(ctx context.Context, filter domain.LLMFilter, offset, limit int) ([]domain.LLMRecord, int64, error) {
+	var (
+		eg   errgroup.Group
+		list []domain.LLMRecord
+		cnt  int64
+	)
+	eg.Go(func() error {
+		var err error
+		list, err = s.recordRepo.List(ctx, filter, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		cnt, err = s.recordRepo.Count(ctx, filter)
+		return err
+	})
+	return list, cnt, eg.Wait()
+}
+
+func (s *service) ListCredits(ctx context.Context, filter domain.LLMFilter, offset, limit int) ([]domain.LLMCredit, int64, error) {
+	var (
+		eg   errgroup.Group
+		list []domain.LLMCredit
+		cnt  int64
+	)
+	eg.Go(func() error {
+		var err error
+		list, err = s.creditRepo.List(ctx, filter, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		cnt, err = s.creditRepo.Count(ctx, filter)
+		return err
+	})
+	return list, cnt, eg.Wait()
+}
+
+func (s *service) DailyUsage(ctx context.Context, filter domain.LLMFilter,
+	dimension domain.UsageDimension, offset, limit int) ([]domain.DailyUsage, error) {
+	if !dimension.Valid() {
+		return nil, fmt.Errorf("非法的统计维度 %s", dimension)
+	}
+	return s.recordRepo.DailyUsage(ctx, filter, dimension, offset, limit)
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package biz
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/guard"
+)
+
+// CaseExamineBizHandlerBuilder 案例测试
+// 输入依次是案例标题、用户的讲述和评分依据（关键字、速记、亮点和引导点）
+type CaseExamineBizHandlerBuilder struct {
+}
+
+func NewCaseExamineBizHandlerBuilder() *CaseExamineBizHandlerBuilder {
+	return &CaseExamineBizHandlerBuilder{}
+}
+
+func (h *CaseExamineBizHandlerBuilder) Next(next handler.Handler) handler.Handler {
+	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+		req, err := h.prompt(req)
+		if err != nil {
+			return domain.LLMResponse{}, err
+		}
+		return next.Handle(ctx, req)
+	})
+}
+
+func (h *CaseExamineBizHandlerBuilder) StreamNext(next handler.StreamHandler) handler.StreamHandler {
+	return handler.StreamHandleFunc(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+		req, err := h.prompt(req)
+		if err != nil {
+			return nil, err
+		}
+		return next.StreamHandle(ctx, req)
+	})
+}
+
+func (h *CaseExamineBizHandlerBuilder) prompt(req domain.LLMRequest) (domain.LLMRequest, error) {
+	if len(req.Input) < 3 {
+		return req, fmt.Errorf("缺少案例标题、用户输入或者评分依据，输入个数 %d", len(req.Input))
+	}
+	title, userInput, rubric := req.Input[0], req.Input[1], req.Input[2]
+	userInputLen := utf8.RuneCountInString(userInput)
+	if req.Config.MaxInput > 0 && userInputLen > req.Config.MaxInput {
+		return req, fmt.Errorf("输入太长，最长不超过 %d，现有长度 %d", req.Config.MaxInput, userInputLen)
+	}
+	req.Prompt = fmt.Sprintf(req.Config.PromptTemplate, title, guard.Delimit(userInput), rubric)
+	return req, nil
+}
+
+var _ handler.Builder = &CaseExamineBizHandlerBuilder{}
+var _ handler.StreamBuilder = &CaseExamineBizHandlerBuilder{}
@@ -16,6 +16,7 @@ package biz
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
@@ -25,13 +26,22 @@ import (
 // 后续该部分应该是动态计算的，通过结合配置来实现动态计算
 type CompositionHandler struct {
 	root handler.Handler
-	name string
+	// 不支持流式调用的时候为 nil
+	streamRoot handler.StreamHandler
+	name       string
 }
 
 func (c *CompositionHandler) Handle(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
 	return c.root.Handle(ctx, req)
 }
 
+func (c *CompositionHandler) StreamHandle(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	if c.streamRoot == nil {
+		return nil, fmt.Errorf("%w biz: %s", ErrStreamNotSupported, c.name)
+	}
+	return c.streamRoot.StreamHandle(ctx, req)
+}
+
 func (c *CompositionHandler) Name() string {
 	return c.name
 }
@@ -49,7 +59,24 @@ func NewCombinedBizHandler(name string,
 		root = current.Next(root)
 	}
 	return &CompositionHandler{
-		root: root,
-		name: name,
+		root:       root,
+		streamRoot: newStreamRoot(common, l),
+		name:       name,
+	}
+}
+
+// newStreamRoot 只有出口和所有的 Builder 都支持流式调用的时候，才能组合出流式调用链
+func newStreamRoot(common []handler.Builder, l handler.Handler) handler.StreamHandler {
+	root, ok := l.(handler.StreamHandler)
+	if !ok {
+		return nil
+	}
+	for i := len(common) - 1; i >= 0; i-- {
+		current, ok := common[i].(handler.StreamBuilder)
+		if !ok {
+			return nil
+		}
+		root = current.StreamNext(root)
 	}
+	return root
 }
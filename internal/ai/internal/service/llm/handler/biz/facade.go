@@ -9,7 +9,10 @@ import (
 	handler2 "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
 )
 
-var ErrUnknownBiz = errors.New("未知的业务")
+var (
+	ErrUnknownBiz         = errors.New("未知的业务")
+	ErrStreamNotSupported = errors.New("不支持流式调用")
+)
 
 // FacadeHandler 用于分发业务Biz
 type FacadeHandler struct {
@@ -24,7 +27,20 @@ func (f *FacadeHandler) Handle(ctx context.Context, req domain.LLMRequest) (doma
 	return h.Handle(ctx, req)
 }
 
+func (f *FacadeHandler) StreamHandle(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	h, ok := f.bizMap[req.Biz]
+	if !ok {
+		return nil, fmt.Errorf("%w biz: %s", ErrUnknownBiz, req.Biz)
+	}
+	sh, ok := h.(handler2.StreamHandler)
+	if !ok {
+		return nil, fmt.Errorf("%w biz: %s", ErrStreamNotSupported, req.Biz)
+	}
+	return sh.StreamHandle(ctx, req)
+}
+
 var _ handler2.Handler = &FacadeHandler{}
+var _ handler2.StreamHandler = &FacadeHandler{}
 
 func NewHandler(bizMap map[string]handler2.Handler) *FacadeHandler {
 	return &FacadeHandler{
@@ -0,0 +1,51 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package biz
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+)
+
+// QuestionAnswerDraftBizHandlerBuilder 根据题目的标题和内容生成答案草稿
+// Input[0] 是标题，Input[1] 是题目内容
+// 提示词模板里面要求大模型按照约定的 JSON 格式返回，解析由调用方负责
+type QuestionAnswerDraftBizHandlerBuilder struct {
+}
+
+func NewQuestionAnswerDraftBizHandlerBuilder() *QuestionAnswerDraftBizHandlerBuilder {
+	return &QuestionAnswerDraftBizHandlerBuilder{}
+}
+
+func (h *QuestionAnswerDraftBizHandlerBuilder) Next(next handler.Handler) handler.Handler {
+	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+		if len(req.Input) < 2 {
+			return domain.LLMResponse{}, fmt.Errorf("缺少题目标题或者内容，输入个数 %d", len(req.Input))
+		}
+		title, content := req.Input[0], req.Input[1]
+		contentLen := utf8.RuneCountInString(content)
+		if req.Config.MaxInput > 0 && contentLen > req.Config.MaxInput {
+			return domain.LLMResponse{}, fmt.Errorf("题目内容太长，最长不超过 %d，现有长度 %d", req.Config.MaxInput, contentLen)
+		}
+		req.Prompt = fmt.Sprintf(req.Config.PromptTemplate, title, content)
+		return next.Handle(ctx, req)
+	})
+}
+
+var _ handler.Builder = &QuestionAnswerDraftBizHandlerBuilder{}
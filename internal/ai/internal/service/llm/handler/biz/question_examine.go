@@ -17,10 +17,12 @@ package biz
 import (
 	"context"
 	"fmt"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/guard"
 )
 
 type QuestionExamineBizHandlerBuilder struct {
@@ -32,16 +34,61 @@ func NewQuestionExamineBizHandlerBuilder() *QuestionExamineBizHandlerBuilder {
 
 func (h *QuestionExamineBizHandlerBuilder) Next(next handler.Handler) handler.Handler {
 	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
-		title := req.Input[0]
-		userInput := req.Input[1]
-		userInputLen := utf8.RuneCount([]byte(userInput))
+		req, err := h.prompt(req)
+		if err != nil {
+			return domain.LLMResponse{}, err
+		}
+		resp, err := next.Handle(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		resp.Structured = h.structured(req)
+		return resp, nil
+	})
+}
 
-		if userInputLen > req.Config.MaxInput {
-			return domain.LLMResponse{}, fmt.Errorf("输入太长，最常不超过 %d，现有长度 %d", req.Config.MaxInput, userInputLen)
+func (h *QuestionExamineBizHandlerBuilder) StreamNext(next handler.StreamHandler) handler.StreamHandler {
+	return handler.StreamHandleFunc(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+		req, err := h.prompt(req)
+		if err != nil {
+			return nil, err
+		}
+		ch, err := next.StreamHandle(ctx, req)
+		if err != nil {
+			return nil, err
 		}
-		// 把 input 和 prompt 结合起来
-		prompt := fmt.Sprintf(req.Config.PromptTemplate, title, userInput)
-		req.Prompt = prompt
-		return next.Handle(ctx, req)
+		structured := h.structured(req)
+		return handler.StreamAfter(ch, func(evt domain.StreamEvent) domain.StreamEvent {
+			evt.Response.Structured = structured
+			return evt
+		}), nil
 	})
 }
+
+func (h *QuestionExamineBizHandlerBuilder) prompt(req domain.LLMRequest) (domain.LLMRequest, error) {
+	title := req.Input[0]
+	userInput := req.Input[1]
+	userInputLen := utf8.RuneCount([]byte(userInput))
+
+	if userInputLen > req.Config.MaxInput {
+		return req, fmt.Errorf("输入太长，最常不超过 %d，现有长度 %d", req.Config.MaxInput, userInputLen)
+	}
+	// 把 input 和 prompt 结合起来
+	args := []any{title, guard.Delimit(userInput)}
+	// 第三个输入是题目的关键字，要求 AI 给出结构化的评价。
+	// 旧版本的提示词模板只有两个 %s，这种时候就不传了
+	if h.structured(req) {
+		args = append(args, req.Input[2])
+	}
+	req.Prompt = fmt.Sprintf(req.Config.PromptTemplate, args...)
+	// 第四个输入是上一次的回答解析失败的原因，让 AI 纠正格式
+	if h.structured(req) && len(req.Input) > 3 && req.Input[3] != "" {
+		req.Prompt += fmt.Sprintf("\n\n上一次的回答不符合约定的 JSON 格式：%s\n请严格按照约定的 JSON 格式重新回答", req.Input[3])
+	}
+	return req, nil
+}
+
+// structured 提示词模板有第三个 %s 的时候，就约定了 AI 返回结构化的 JSON
+func (h *QuestionExamineBizHandlerBuilder) structured(req domain.LLMRequest) bool {
+	return len(req.Input) > 2 && strings.Count(req.Config.PromptTemplate, "%s") > 2
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/credit"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+var ErrBudgetExceeded = errors.New("AI 预算不足")
+
+// HandlerBuilder 管理后台使用的业务不扣用户的积分，而是统一扣预算
+// 预算按照业务和自然月来计算，用来替换 credit
+// 调用之前按照最坏的情况原子地检查并预留花费，调用之后按照实际花费多退少补，
+// 所以并发调用也不会超出预算
+type HandlerBuilder struct {
+	repo repository.BudgetRepository
+	// biz => 每个月的预算，单位是积分，没有配置的业务不限制
+	limits map[string]int64
+	// 和预扣积分使用同样的估算方式
+	cfg    credit.PreAuthConfig
+	logger *elog.Component
+}
+
+func NewHandlerBuilder(repo repository.BudgetRepository,
+	limits map[string]int64, cfg credit.PreAuthConfig) *HandlerBuilder {
+	return &HandlerBuilder{
+		repo:   repo,
+		limits: limits,
+		cfg:    cfg,
+		logger: elog.DefaultLogger,
+	}
+}
+
+func (h *HandlerBuilder) Next(next handler.Handler) handler.Handler {
+	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+		reserved, err := h.reserve(ctx, req)
+		if err != nil {
+			return domain.LLMResponse{}, err
+		}
+		resp, err := next.Handle(ctx, req)
+		if err != nil {
+			h.incr(ctx, req.Biz, -reserved)
+			return resp, err
+		}
+		h.incr(ctx, req.Biz, resp.Amount-reserved)
+		return resp, nil
+	})
+}
+
+// reserve 预留最坏情况下的花费，返回预留了多少
+func (h *HandlerBuilder) reserve(ctx context.Context, req domain.LLMRequest) (int64, error) {
+	limit := h.limits[req.Biz]
+	if limit <= 0 {
+		return 0, nil
+	}
+	amount := h.cfg.Estimate(req)
+	ok, err := h.repo.Reserve(ctx, req.Biz, amount, limit)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("%w, biz %s, 本月预算 %d, 预估花费 %d", ErrBudgetExceeded, req.Biz, limit, amount)
+	}
+	return amount, nil
+}
+
+func (h *HandlerBuilder) incr(ctx context.Context, biz string, amount int64) {
+	if amount == 0 {
+		return
+	}
+	err := h.repo.Incr(ctx, biz, amount)
+	if err != nil {
+		h.logger.Error("记录 AI 预算花费失败", elog.FieldErr(err),
+			elog.String("biz", biz), elog.Int64("amount", amount))
+	}
+}
+
+var _ handler.Builder = &HandlerBuilder{}
@@ -0,0 +1,170 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/credit"
+	hdlmocks "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandlerBuilder_Next(t *testing.T) {
+	testCases := []struct {
+		name   string
+		limits map[string]int64
+		cfg    credit.PreAuthConfig
+		spent  int64
+		mock   func(ctrl *gomock.Controller) *hdlmocks.MockHandler
+
+		wantResp  domain.LLMResponse
+		wantErr   error
+		wantSpent int64
+	}{
+		{
+			name:   "预算充足",
+			limits: map[string]int64{"test": 100},
+			spent:  90,
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{Tokens: 100, Amount: 20, Answer: "草稿"}, nil)
+				return next
+			},
+			wantResp:  domain.LLMResponse{Tokens: 100, Amount: 20, Answer: "草稿"},
+			wantSpent: 110,
+		},
+		{
+			name:   "预算用完",
+			limits: map[string]int64{"test": 100},
+			spent:  100,
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				return hdlmocks.NewMockHandler(ctrl)
+			},
+			wantErr:   ErrBudgetExceeded,
+			wantSpent: 100,
+		},
+		{
+			name:   "没有配置预算",
+			limits: map[string]int64{"other": 100},
+			spent:  1000,
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{Amount: 20}, nil)
+				return next
+			},
+			wantResp:  domain.LLMResponse{Amount: 20},
+			wantSpent: 1020,
+		},
+		{
+			name:   "调用失败不计入花费",
+			limits: map[string]int64{"test": 100},
+			spent:  10,
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{}, errors.New("mock error"))
+				return next
+			},
+			wantErr:   errors.New("mock error"),
+			wantSpent: 10,
+		},
+		{
+			// 预估 (1000 + 2) * 10 / 1000 = 11 积分，只剩下 10
+			name:   "预估花费超出预算",
+			limits: map[string]int64{"test": 100},
+			cfg:    credit.PreAuthConfig{Price: 10, MaxOutput: 1000},
+			spent:  90,
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				return hdlmocks.NewMockHandler(ctrl)
+			},
+			wantErr:   ErrBudgetExceeded,
+			wantSpent: 90,
+		},
+		{
+			name:   "按照实际花费结算",
+			limits: map[string]int64{"test": 100},
+			cfg:    credit.PreAuthConfig{Price: 10, MaxOutput: 1000},
+			spent:  80,
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{Amount: 3}, nil)
+				return next
+			},
+			wantResp:  domain.LLMResponse{Amount: 3},
+			wantSpent: 83,
+		},
+		{
+			name:   "调用失败释放预留的花费",
+			limits: map[string]int64{"test": 100},
+			cfg:    credit.PreAuthConfig{Price: 10, MaxOutput: 1000},
+			spent:  80,
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{}, errors.New("mock error"))
+				return next
+			},
+			wantErr:   errors.New("mock error"),
+			wantSpent: 80,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := &fakeBudgetRepo{spent: tc.spent}
+			h := NewHandlerBuilder(repo, tc.limits, tc.cfg).Next(tc.mock(ctrl))
+			resp, err := h.Handle(context.Background(), domain.LLMRequest{
+				Biz:   "test",
+				Input: []string{"题目"},
+				Config: domain.BizConfig{
+					MaxInput: 100,
+				},
+			})
+			if tc.wantErr != nil && errors.Is(tc.wantErr, ErrBudgetExceeded) {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.Equal(t, tc.wantErr, err)
+			}
+			assert.Equal(t, tc.wantResp, resp)
+			assert.Equal(t, tc.wantSpent, repo.spent)
+		})
+	}
+}
+
+type fakeBudgetRepo struct {
+	spent int64
+}
+
+func (f *fakeBudgetRepo) Reserve(ctx context.Context, biz string, amount, limit int64) (bool, error) {
+	if f.spent >= limit || f.spent+amount > limit {
+		return false, nil
+	}
+	f.spent += amount
+	return true, nil
+}
+
+func (f *fakeBudgetRepo) Incr(ctx context.Context, biz string, amount int64) error {
+	f.spent += amount
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Config 响应缓存的配置
+type Config struct {
+	// 默认的过期时间，BizConfig.CacheTTL 为 0 的时候使用
+	// 两个都为 0 的时候不缓存
+	TTL time.Duration
+	// 命中缓存的时候收取的费用，可以是 0
+	HitAmount int64
+}
+
+// HandlerBuilder 相同的 prompt 直接返回缓存的回答，不再调用平台
+// 它需要完整的 prompt，所以要放在业务的 builder 后面
+type HandlerBuilder struct {
+	repo   repository.ResponseRepository
+	cfg    Config
+	logger *elog.Component
+}
+
+func NewHandlerBuilder(repo repository.ResponseRepository, cfg Config) *HandlerBuilder {
+	return &HandlerBuilder{
+		repo:   repo,
+		cfg:    cfg,
+		logger: elog.DefaultLogger,
+	}
+}
+
+func (h *HandlerBuilder) Name() string {
+	return "cache"
+}
+
+func (h *HandlerBuilder) Next(next handler.Handler) handler.Handler {
+	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+		ttl := h.ttl(req)
+		if ttl <= 0 {
+			return next.Handle(ctx, req)
+		}
+		key := h.key(req)
+		if !req.Refresh {
+			resp, ok := h.get(ctx, key)
+			if ok {
+				return resp, nil
+			}
+		}
+		resp, err := next.Handle(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		h.save(ctx, key, resp, ttl)
+		return resp, nil
+	})
+}
+
+func (h *HandlerBuilder) StreamNext(next handler.StreamHandler) handler.StreamHandler {
+	return handler.StreamHandleFunc(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+		ttl := h.ttl(req)
+		if ttl <= 0 {
+			return next.StreamHandle(ctx, req)
+		}
+		key := h.key(req)
+		if !req.Refresh {
+			resp, ok := h.get(ctx, key)
+			if ok {
+				ch := make(chan domain.StreamEvent, 2)
+				ch <- domain.StreamEvent{Content: resp.Answer}
+				ch <- domain.StreamEvent{Done: true, Response: resp}
+				close(ch)
+				return ch, nil
+			}
+		}
+		ch, err := next.StreamHandle(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return handler.StreamAfter(ch, func(evt domain.StreamEvent) domain.StreamEvent {
+			if evt.Err == nil {
+				h.save(context.WithoutCancel(ctx), key, evt.Response, ttl)
+			}
+			return evt
+		}), nil
+	})
+}
+
+// get 缓存出错的时候当作没有命中处理
+func (h *HandlerBuilder) get(ctx context.Context, key string) (domain.LLMResponse, bool) {
+	resp, err := h.repo.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, repository.ErrResponseNotFound) {
+			h.logger.Error("读取响应缓存失败", elog.FieldErr(err), elog.String("key", key))
+		}
+		return domain.LLMResponse{}, false
+	}
+	// 没有调用平台，不消耗 token
+	return domain.LLMResponse{
+		Amount: h.cfg.HitAmount,
+		Answer: resp.Answer,
+		Cached: true,
+	}, true
+}
+
+func (h *HandlerBuilder) save(ctx context.Context, key string, resp domain.LLMResponse, ttl time.Duration) {
+	if resp.Answer == "" {
+		return
+	}
+	err := h.repo.Save(ctx, key, resp, ttl)
+	if err != nil {
+		h.logger.Error("保存响应缓存失败", elog.FieldErr(err), elog.String("key", key))
+	}
+}
+
+func (h *HandlerBuilder) ttl(req domain.LLMRequest) time.Duration {
+	if req.Config.DisableCache {
+		return 0
+	}
+	if req.Config.CacheTTL > 0 {
+		return req.Config.CacheTTL
+	}
+	return h.cfg.TTL
+}
+
+// key 由 biz，知识库，提示词模板的版本和归一化之后的 prompt 组成
+// 修改了提示词模板之后，之前的缓存自然就失效了
+func (h *HandlerBuilder) key(req domain.LLMRequest) string {
+	return fmt.Sprintf("%s:%s:%s:%s", req.Biz, req.Config.KnowledgeId,
+		h.hash(req.Config.PromptTemplate)[:16], h.hash(normalize(req.Prompt)))
+}
+
+func (h *HandlerBuilder) hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalize 忽略大小写和空白字符的差异
+func normalize(prompt string) string {
+	return strings.ToLower(strings.Join(strings.Fields(prompt), " "))
+}
@@ -0,0 +1,234 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	hdlmocks "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandlerBuilder_Next(t *testing.T) {
+	req := domain.LLMRequest{
+		Biz:    "test",
+		Prompt: "问题 1，用户输入  ABC ",
+		Config: domain.BizConfig{
+			KnowledgeId:    "kid",
+			PromptTemplate: "问题 %s，<user_input> 里面的用户输入 %s",
+		},
+	}
+	testCases := []struct {
+		name   string
+		cfg    Config
+		req    func() domain.LLMRequest
+		before func(repo *fakeResponseRepo)
+		mock   func(ctrl *gomock.Controller) *hdlmocks.MockHandler
+
+		wantResp domain.LLMResponse
+		wantErr  error
+		wantSize int
+	}{
+		{
+			name: "没有命中，调用之后缓存",
+			cfg:  Config{TTL: time.Minute, HitAmount: 1},
+			req:  func() domain.LLMRequest { return req },
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{Tokens: 100, Amount: 10, Answer: "回答"}, nil)
+				return next
+			},
+			wantResp: domain.LLMResponse{Tokens: 100, Amount: 10, Answer: "回答"},
+			wantSize: 1,
+		},
+		{
+			name: "命中，空白和大小写不影响",
+			cfg:  Config{TTL: time.Minute, HitAmount: 1},
+			req: func() domain.LLMRequest {
+				r := req
+				r.Prompt = "问题 1，用户输入 abc"
+				return r
+			},
+			before: func(repo *fakeResponseRepo) {
+				h := NewHandlerBuilder(repo, Config{})
+				repo.data[h.key(req)] = domain.LLMResponse{Tokens: 100, Answer: "缓存的回答"}
+			},
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				return hdlmocks.NewMockHandler(ctrl)
+			},
+			wantResp: domain.LLMResponse{Amount: 1, Answer: "缓存的回答", Cached: true},
+			wantSize: 1,
+		},
+		{
+			name: "修改了提示词模板，不命中",
+			cfg:  Config{TTL: time.Minute},
+			req: func() domain.LLMRequest {
+				r := req
+				r.Config.PromptTemplate = "新的模板 %s %s"
+				return r
+			},
+			before: func(repo *fakeResponseRepo) {
+				h := NewHandlerBuilder(repo, Config{})
+				repo.data[h.key(req)] = domain.LLMResponse{Answer: "缓存的回答"}
+			},
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{Answer: "新的回答"}, nil)
+				return next
+			},
+			wantResp: domain.LLMResponse{Answer: "新的回答"},
+			wantSize: 2,
+		},
+		{
+			name: "强制刷新，覆盖缓存",
+			cfg:  Config{TTL: time.Minute},
+			req: func() domain.LLMRequest {
+				r := req
+				r.Refresh = true
+				return r
+			},
+			before: func(repo *fakeResponseRepo) {
+				h := NewHandlerBuilder(repo, Config{})
+				repo.data[h.key(req)] = domain.LLMResponse{Answer: "缓存的回答"}
+			},
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{Answer: "新的回答"}, nil)
+				return next
+			},
+			wantResp: domain.LLMResponse{Answer: "新的回答"},
+			wantSize: 1,
+		},
+		{
+			name: "禁用了缓存",
+			cfg:  Config{TTL: time.Minute},
+			req: func() domain.LLMRequest {
+				r := req
+				r.Config.DisableCache = true
+				return r
+			},
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{Answer: "回答"}, nil)
+				return next
+			},
+			wantResp: domain.LLMResponse{Answer: "回答"},
+		},
+		{
+			name: "没有配置过期时间，不缓存",
+			req:  func() domain.LLMRequest { return req },
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{Answer: "回答"}, nil)
+				return next
+			},
+			wantResp: domain.LLMResponse{Answer: "回答"},
+		},
+		{
+			name: "调用失败，不缓存",
+			cfg:  Config{TTL: time.Minute},
+			req:  func() domain.LLMRequest { return req },
+			mock: func(ctrl *gomock.Controller) *hdlmocks.MockHandler {
+				next := hdlmocks.NewMockHandler(ctrl)
+				next.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{}, errors.New("mock error"))
+				return next
+			},
+			wantErr: errors.New("mock error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := &fakeResponseRepo{data: map[string]domain.LLMResponse{}}
+			if tc.before != nil {
+				tc.before(repo)
+			}
+			h := NewHandlerBuilder(repo, tc.cfg).Next(tc.mock(ctrl))
+			resp, err := h.Handle(context.Background(), tc.req())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantResp, resp)
+			assert.Len(t, repo.data, tc.wantSize)
+		})
+	}
+}
+
+func TestHandlerBuilder_StreamNext(t *testing.T) {
+	req := domain.LLMRequest{Biz: "test", Prompt: "prompt"}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := &fakeResponseRepo{data: map[string]domain.LLMResponse{}}
+	builder := NewHandlerBuilder(repo, Config{TTL: time.Minute})
+	next := hdlmocks.NewMockStreamHandler(ctrl)
+	next.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+			ch := make(chan domain.StreamEvent, 2)
+			ch <- domain.StreamEvent{Content: "回答"}
+			ch <- domain.StreamEvent{Done: true, Response: domain.LLMResponse{Tokens: 10, Answer: "回答"}}
+			close(ch)
+			return ch, nil
+		})
+	h := builder.StreamNext(next)
+
+	// 第一次调用平台
+	ch, err := h.StreamHandle(context.Background(), req)
+	require.NoError(t, err)
+	var evts []domain.StreamEvent
+	for evt := range ch {
+		evts = append(evts, evt)
+	}
+	assert.Equal(t, domain.LLMResponse{Tokens: 10, Answer: "回答"}, evts[len(evts)-1].Response)
+
+	// 第二次命中缓存
+	ch, err = h.StreamHandle(context.Background(), req)
+	require.NoError(t, err)
+	evts = evts[:0]
+	for evt := range ch {
+		evts = append(evts, evt)
+	}
+	assert.Equal(t, []domain.StreamEvent{
+		{Content: "回答"},
+		{Done: true, Response: domain.LLMResponse{Answer: "回答", Cached: true}},
+	}, evts)
+}
+
+type fakeResponseRepo struct {
+	data map[string]domain.LLMResponse
+}
+
+func (f *fakeResponseRepo) Get(ctx context.Context, key string) (domain.LLMResponse, error) {
+	resp, ok := f.data[key]
+	if !ok {
+		return domain.LLMResponse{}, repository.ErrResponseNotFound
+	}
+	return resp, nil
+}
+
+func (f *fakeResponseRepo) Save(ctx context.Context, key string, resp domain.LLMResponse, expiration time.Duration) error {
+	f.data[key] = resp
+	return nil
+}
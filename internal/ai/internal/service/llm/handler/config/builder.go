@@ -16,6 +16,7 @@ package config
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository"
@@ -36,7 +37,7 @@ func NewBuilder(repo repository.ConfigRepository) *HandlerBuilder {
 func (b *HandlerBuilder) Next(next handler.Handler) handler.Handler {
 	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
 		// 读取配置
-		cfg, err := b.repo.GetConfig(ctx, req.Biz)
+		cfg, err := b.getConfig(ctx, req)
 		if err != nil {
 			return domain.LLMResponse{}, err
 		}
@@ -45,4 +46,35 @@ func (b *HandlerBuilder) Next(next handler.Handler) handler.Handler {
 	})
 }
 
+func (b *HandlerBuilder) StreamNext(next handler.StreamHandler) handler.StreamHandler {
+	return handler.StreamHandleFunc(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+		cfg, err := b.getConfig(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		req.Config = cfg
+		return next.StreamHandle(ctx, req)
+	})
+}
+
+// getConfig 读取配置，并且替换成这个用户应该使用的提示词版本
+func (b *HandlerBuilder) getConfig(ctx context.Context, req domain.LLMRequest) (domain.BizConfig, error) {
+	cfg, err := b.repo.GetConfig(ctx, req.Biz)
+	if err != nil {
+		return domain.BizConfig{}, err
+	}
+	version := cfg.PickPromptVersion(req.Uid)
+	if version <= 0 {
+		return cfg, nil
+	}
+	pv, err := b.repo.GetPromptVersion(ctx, req.Biz, version)
+	if err != nil {
+		return domain.BizConfig{}, fmt.Errorf("读取提示词版本失败 biz %s, version %d, %w", req.Biz, version, err)
+	}
+	cfg.PromptTemplate = pv.PromptTemplate
+	cfg.PromptVersion = pv.Version
+	return cfg, nil
+}
+
 var _ handler.Builder = &HandlerBuilder{}
+var _ handler.StreamBuilder = &HandlerBuilder{}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"unicode/utf8"
 
 	"github.com/gotomicro/ego/core/elog"
 
@@ -17,9 +19,20 @@ import (
 type HandlerBuilder struct {
 	creditSvc credit.Service
 	logRepo   repository.LLMCreditLogRepo
+	cfg       PreAuthConfig
 	logger    *elog.Component
 }
 
+// PreAuthConfig 预扣积分的配置
+// 调用之前按照最坏的情况预扣积分，调用之后按照实际花费结算，多退少补
+type PreAuthConfig struct {
+	// 估算使用的价格，N/1k token，一般配置为最贵的模型的价格
+	// 为 0 的时候不预扣，调用之后再扣
+	Price float64
+	// 最大输出 token 数
+	MaxOutput int
+}
+
 func (h *HandlerBuilder) Name() string {
 	return "credit"
 }
@@ -28,66 +41,222 @@ var (
 	ErrInsufficientCredit = errors.New("积分不足")
 )
 
-func NewHandlerBuilder(creSvc credit.Service, repo repository.LLMCreditLogRepo) *HandlerBuilder {
+func NewHandlerBuilder(creSvc credit.Service,
+	repo repository.LLMCreditLogRepo,
+	cfg PreAuthConfig) *HandlerBuilder {
 	return &HandlerBuilder{
 		creditSvc: creSvc,
 		logRepo:   repo,
+		cfg:       cfg,
 		logger:    elog.DefaultLogger,
 	}
 }
 
 func (h *HandlerBuilder) Next(next handler.Handler) handler.Handler {
 	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
-		cre, err := h.creditSvc.GetCreditsByUID(ctx, req.Uid)
+		lock, err := h.lock(ctx, req)
 		if err != nil {
 			return domain.LLMResponse{}, err
 		}
-		// 如果剩余的积分不足就返回积分不足
-		ok := h.checkCredit(cre)
-		if !ok {
-			return domain.LLMResponse{}, fmt.Errorf("%w, 余额非正数，无法继续调用，用户 %d",
-				ErrInsufficientCredit, req.Uid)
-		}
 
 		// 调用下层服务
 		resp, err := next.Handle(ctx, req)
 		if err != nil {
+			h.release(ctx, lock)
 			return resp, err
 		}
-
-		// 扣款
-		id, err := h.logRepo.SaveCredit(ctx, h.newLog(req, resp))
+		err = h.settle(ctx, lock, resp)
 		if err != nil {
 			return domain.LLMResponse{}, err
 		}
-		err = h.deductCredit(ctx, credit.Credit{
-			Uid: req.Uid,
-			Logs: []credit.CreditLog{
-				{
-					Key:          uuid.New(),
-					ChangeAmount: resp.Amount,
-					Uid:          req.Uid,
-					Biz:          "ai-llm",
-					BizId:        id,
-					Desc:         "ai-llm服务",
-				},
-			},
+		return resp, nil
+	})
+}
+
+func (h *HandlerBuilder) StreamNext(next handler.StreamHandler) handler.StreamHandler {
+	return handler.StreamHandleFunc(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+		lock, err := h.lock(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		ch, err := next.StreamHandle(ctx, req)
+		if err != nil {
+			h.release(ctx, lock)
+			return nil, err
+		}
+		return handler.StreamAfter(ch, func(evt domain.StreamEvent) domain.StreamEvent {
+			// 用户可能已经断开了连接，但是钱已经花出去了，所以还是要扣
+			ctx := context.WithoutCancel(ctx)
+			if evt.Err != nil {
+				h.release(ctx, lock)
+				return evt
+			}
+			err1 := h.settle(ctx, lock, evt.Response)
+			if err1 != nil {
+				return domain.StreamEvent{Err: err1}
+			}
+			return evt
+		}), nil
+	})
+}
+
+// creditLock 一次调用预扣的积分
+type creditLock struct {
+	req domain.LLMRequest
+	// LLMCredit 的 ID
+	id int64
+	// 预扣积分流水的 ID，为 0 说明没有预扣
+	tid int64
+	// 预扣的积分
+	amount int64
+}
+
+// lock 调用之前检查积分，并且按照最坏的情况预扣积分
+func (h *HandlerBuilder) lock(ctx context.Context, req domain.LLMRequest) (creditLock, error) {
+	res := creditLock{req: req}
+	cre, err := h.creditSvc.GetCreditsByUID(ctx, req.Uid)
+	if err != nil {
+		return res, err
+	}
+	// 如果剩余的积分不足就返回积分不足
+	ok := h.checkCredit(cre)
+	if !ok {
+		return res, fmt.Errorf("%w, 余额非正数，无法继续调用，用户 %d",
+			ErrInsufficientCredit, req.Uid)
+	}
+	res.amount = h.cfg.Estimate(req)
+	if res.amount == 0 {
+		return res, nil
+	}
+	if uint64(res.amount) > cre.TotalAmount {
+		return res, fmt.Errorf("%w, 余额不足以支付预估费用，用户 %d，余额 %d，预估 %d",
+			ErrInsufficientCredit, req.Uid, cre.TotalAmount, res.amount)
+	}
+	res.id, err = h.logRepo.SaveCredit(ctx, domain.LLMCredit{
+		Tid:    req.Tid,
+		Uid:    req.Uid,
+		Biz:    req.Biz,
+		Amount: res.amount,
+		Status: domain.CreditStatusProcessing,
+	})
+	if err != nil {
+		return res, err
+	}
+	res.tid, err = h.creditSvc.TryDeductCredits(ctx, h.newCredit(req.Uid, res.id, res.amount))
+	if err != nil {
+		_, _ = h.logRepo.SaveCredit(ctx, domain.LLMCredit{
+			Id:     res.id,
+			Amount: res.amount,
+			Status: domain.CreditStatusFailed,
 		})
+		if errors.Is(err, credit.ErrCreditNotEnough) {
+			return res, fmt.Errorf("%w, 预扣积分失败，用户 %d", ErrInsufficientCredit, req.Uid)
+		}
+		return res, err
+	}
+	return res, nil
+}
+
+// Estimate 估算最坏情况下的花费
+// 一个字符最多算一个 token，用户输入最长是 MaxInput
+func (c PreAuthConfig) Estimate(req domain.LLMRequest) int64 {
+	if c.Price <= 0 {
+		return 0
+	}
+	tokens := utf8.RuneCountInString(req.Config.PromptTemplate) + c.MaxOutput
+	for _, input := range req.Input {
+		tokens += min(utf8.RuneCountInString(input), req.Config.MaxInput)
+	}
+	return int64(math.Ceil(float64(tokens) * c.Price / 1000))
+}
+
+// release 调用失败，释放预扣的积分
+func (h *HandlerBuilder) release(ctx context.Context, lock creditLock) {
+	if lock.tid == 0 {
+		return
+	}
+	err := h.creditSvc.CancelDeductCredits(ctx, lock.req.Uid, lock.tid)
+	if err != nil {
+		// 超时未处理的预扣积分会被定时任务释放
+		h.logger.Error("释放预扣积分失败", elog.FieldErr(err),
+			elog.Int64("uid", lock.req.Uid),
+			elog.Int64("tid", lock.tid))
+	}
+	_, err = h.logRepo.SaveCredit(ctx, domain.LLMCredit{
+		Id:     lock.id,
+		Amount: lock.amount,
+		Status: domain.CreditStatusFailed,
+	})
+	if err != nil {
+		h.logger.Error("更新 LLM 扣费记录失败", elog.FieldErr(err))
+	}
+}
+
+// settle 调用成功，按照实际花费结算
+// 实际花费少于预扣的积分，多出来的部分返还给用户；
+// 实际花费超过了预扣的积分，超出的部分再扣一次
+func (h *HandlerBuilder) settle(ctx context.Context, lock creditLock, resp domain.LLMResponse) error {
+	var err error
+	id := lock.id
+	if id == 0 {
+		id, err = h.logRepo.SaveCredit(ctx, h.newLog(lock.req, resp))
+		if err != nil {
+			return err
+		}
+	}
+	extra := resp.Amount
+	if lock.tid != 0 {
+		confirmed := min(resp.Amount, lock.amount)
+		err = h.creditSvc.PartialConfirmDeductCredits(ctx, lock.req.Uid, lock.tid, confirmed)
+		if err != nil {
+			h.logger.Error("确认预扣积分失败", elog.FieldErr(err),
+				elog.Int64("uid", lock.req.Uid),
+				elog.Int64("tid", lock.tid))
+			h.release(ctx, lock)
+			return fmt.Errorf("确认预扣积分失败 %w", err)
+		}
+		extra = resp.Amount - confirmed
+		if extra > 0 {
+			h.logger.Warn("实际花费超过了预估费用",
+				elog.Int64("uid", lock.req.Uid),
+				elog.Int64("estimate", lock.amount),
+				elog.Int64("amount", resp.Amount))
+		}
+	}
+	if extra > 0 {
+		err = h.deductCredit(ctx, h.newCredit(lock.req.Uid, id, extra))
 		if err != nil {
 			_, _ = h.logRepo.SaveCredit(ctx, domain.LLMCredit{
 				Id:     id,
+				Amount: resp.Amount,
 				Status: domain.CreditStatusFailed,
 			})
-			return domain.LLMResponse{}, err
+			return err
 		}
+	}
 
-		_, err = h.logRepo.SaveCredit(ctx, domain.LLMCredit{
-			Id:     id,
-			Status: domain.CreditStatusSuccess,
-		})
-
-		return resp, err
+	_, err = h.logRepo.SaveCredit(ctx, domain.LLMCredit{
+		Id:     id,
+		Amount: resp.Amount,
+		Status: domain.CreditStatusSuccess,
 	})
+	return err
+}
+
+func (h *HandlerBuilder) newCredit(uid, bizId, amount int64) credit.Credit {
+	return credit.Credit{
+		Uid: uid,
+		Logs: []credit.CreditLog{
+			{
+				Key:          uuid.New(),
+				ChangeAmount: amount,
+				Uid:          uid,
+				Biz:          "ai-llm",
+				BizId:        bizId,
+				Desc:         "ai-llm服务",
+			},
+		},
+	}
 }
 
 // TODO deductCredit 后面要求 credit 那边提供一个一次性接口，绕开 try-confirm 流程
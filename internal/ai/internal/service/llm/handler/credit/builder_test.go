@@ -0,0 +1,372 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	hdlmocks "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/mocks"
+	"github.com/ecodeclub/webook/internal/credit"
+	creditmocks "github.com/ecodeclub/webook/internal/credit/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandlerBuilder_Next(t *testing.T) {
+	// 预估的 token 数是 3 + 5 + 1992 = 2000，按照 5/1k token 预扣 10
+	preAuth := PreAuthConfig{Price: 5, MaxOutput: 1992}
+	testCases := []struct {
+		name string
+		cfg  PreAuthConfig
+		mock func(ctrl *gomock.Controller) (credit.Service, handler.Handler)
+
+		wantResp   domain.LLMResponse
+		wantErr    error
+		wantStatus domain.CreditStatus
+		wantAmount int64
+	}{
+		{
+			name: "不预扣，调用之后扣费",
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.Handler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(7)).Return(int64(11), nil)
+				svc.EXPECT().ConfirmDeductCredits(gomock.Any(), int64(1), int64(11)).Return(nil)
+				return svc, newNext(ctrl, domain.LLMResponse{Amount: 7}, nil)
+			},
+			wantResp:   domain.LLMResponse{Amount: 7},
+			wantStatus: domain.CreditStatusSuccess,
+			wantAmount: 7,
+		},
+		{
+			name: "预扣，实际花费少于预扣",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.Handler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(10)).Return(int64(11), nil)
+				svc.EXPECT().PartialConfirmDeductCredits(gomock.Any(), int64(1), int64(11), int64(7)).Return(nil)
+				return svc, newNext(ctrl, domain.LLMResponse{Amount: 7}, nil)
+			},
+			wantResp:   domain.LLMResponse{Amount: 7},
+			wantStatus: domain.CreditStatusSuccess,
+			wantAmount: 7,
+		},
+		{
+			name: "预扣，实际花费超过预扣",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.Handler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(10)).Return(int64(11), nil)
+				svc.EXPECT().PartialConfirmDeductCredits(gomock.Any(), int64(1), int64(11), int64(10)).Return(nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(3)).Return(int64(12), nil)
+				svc.EXPECT().ConfirmDeductCredits(gomock.Any(), int64(1), int64(12)).Return(nil)
+				return svc, newNext(ctrl, domain.LLMResponse{Amount: 13}, nil)
+			},
+			wantResp:   domain.LLMResponse{Amount: 13},
+			wantStatus: domain.CreditStatusSuccess,
+			wantAmount: 13,
+		},
+		{
+			name: "预扣，调用失败释放积分",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.Handler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(10)).Return(int64(11), nil)
+				svc.EXPECT().CancelDeductCredits(gomock.Any(), int64(1), int64(11)).Return(nil)
+				return svc, newNext(ctrl, domain.LLMResponse{}, errors.New("mock error"))
+			},
+			wantErr:    errors.New("mock error"),
+			wantStatus: domain.CreditStatusFailed,
+			wantAmount: 10,
+		},
+		{
+			name: "余额不足以支付预估费用",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.Handler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 9}, nil)
+				return svc, hdlmocks.NewMockHandler(ctrl)
+			},
+			wantErr: ErrInsufficientCredit,
+		},
+		{
+			name: "预扣积分的时候积分不足",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.Handler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(10)).
+					Return(int64(0), credit.ErrCreditNotEnough)
+				return svc, hdlmocks.NewMockHandler(ctrl)
+			},
+			wantErr:    ErrInsufficientCredit,
+			wantStatus: domain.CreditStatusFailed,
+			wantAmount: 10,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, next := tc.mock(ctrl)
+			repo := &fakeCreditLogRepo{}
+			h := NewHandlerBuilder(svc, repo, tc.cfg).Next(next)
+			resp, err := h.Handle(context.Background(), domain.LLMRequest{
+				Uid:   1,
+				Biz:   "test",
+				Input: []string{"hello"},
+				Config: domain.BizConfig{
+					MaxInput:       100,
+					PromptTemplate: "abc",
+				},
+			})
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				if errors.Is(tc.wantErr, ErrInsufficientCredit) {
+					assert.ErrorIs(t, err, ErrInsufficientCredit)
+				} else {
+					assert.Equal(t, tc.wantErr.Error(), err.Error())
+				}
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.wantResp, resp)
+			}
+			if tc.wantStatus == 0 {
+				assert.Empty(t, repo.logs)
+				return
+			}
+			last := repo.logs[len(repo.logs)-1]
+			assert.Equal(t, tc.wantStatus, last.Status)
+			assert.Equal(t, tc.wantAmount, last.Amount)
+		})
+	}
+}
+
+func TestHandlerBuilder_StreamNext(t *testing.T) {
+	preAuth := PreAuthConfig{Price: 5, MaxOutput: 1992}
+	testCases := []struct {
+		name string
+		cfg  PreAuthConfig
+		mock func(ctrl *gomock.Controller) (credit.Service, handler.StreamHandler)
+
+		wantEvts   []domain.StreamEvent
+		wantErr    error
+		wantStatus domain.CreditStatus
+		wantAmount int64
+	}{
+		{
+			name: "不预扣，结束之后扣费",
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.StreamHandler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(7)).Return(int64(11), nil)
+				svc.EXPECT().ConfirmDeductCredits(gomock.Any(), int64(1), int64(11)).Return(nil)
+				return svc, newStreamNext(ctrl,
+					domain.StreamEvent{Content: "回答"},
+					domain.StreamEvent{Done: true, Response: domain.LLMResponse{Amount: 7}})
+			},
+			wantEvts: []domain.StreamEvent{
+				{Content: "回答"},
+				{Done: true, Response: domain.LLMResponse{Amount: 7}},
+			},
+			wantStatus: domain.CreditStatusSuccess,
+			wantAmount: 7,
+		},
+		{
+			name: "预扣，结束之后按照实际花费结算",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.StreamHandler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(10)).Return(int64(11), nil)
+				svc.EXPECT().PartialConfirmDeductCredits(gomock.Any(), int64(1), int64(11), int64(7)).Return(nil)
+				return svc, newStreamNext(ctrl,
+					domain.StreamEvent{Done: true, Response: domain.LLMResponse{Amount: 7}})
+			},
+			wantEvts: []domain.StreamEvent{
+				{Done: true, Response: domain.LLMResponse{Amount: 7}},
+			},
+			wantStatus: domain.CreditStatusSuccess,
+			wantAmount: 7,
+		},
+		{
+			name: "预扣，流式响应失败释放积分",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.StreamHandler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(10)).Return(int64(11), nil)
+				svc.EXPECT().CancelDeductCredits(gomock.Any(), int64(1), int64(11)).Return(nil)
+				return svc, newStreamNext(ctrl,
+					domain.StreamEvent{Content: "回"},
+					domain.StreamEvent{Err: errors.New("mock error")})
+			},
+			wantEvts: []domain.StreamEvent{
+				{Content: "回"},
+				{Err: errors.New("mock error")},
+			},
+			wantStatus: domain.CreditStatusFailed,
+			wantAmount: 10,
+		},
+		{
+			name: "预扣，确认扣费失败的时候最后一个事件改为失败",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.StreamHandler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(10)).Return(int64(11), nil)
+				svc.EXPECT().PartialConfirmDeductCredits(gomock.Any(), int64(1), int64(11), int64(7)).
+					Return(errors.New("mock error"))
+				svc.EXPECT().CancelDeductCredits(gomock.Any(), int64(1), int64(11)).Return(nil)
+				return svc, newStreamNext(ctrl,
+					domain.StreamEvent{Done: true, Response: domain.LLMResponse{Amount: 7}})
+			},
+			wantEvts: []domain.StreamEvent{
+				{Err: fmt.Errorf("确认预扣积分失败 %w", errors.New("mock error"))},
+			},
+			wantStatus: domain.CreditStatusFailed,
+			wantAmount: 10,
+		},
+		{
+			name: "预扣，调用失败释放积分",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.StreamHandler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 100}, nil)
+				svc.EXPECT().TryDeductCredits(gomock.Any(), amountMatcher(10)).Return(int64(11), nil)
+				svc.EXPECT().CancelDeductCredits(gomock.Any(), int64(1), int64(11)).Return(nil)
+				next := hdlmocks.NewMockStreamHandler(ctrl)
+				next.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).Return(nil, errors.New("mock error"))
+				return svc, next
+			},
+			wantErr:    errors.New("mock error"),
+			wantStatus: domain.CreditStatusFailed,
+			wantAmount: 10,
+		},
+		{
+			name: "余额不足以支付预估费用",
+			cfg:  preAuth,
+			mock: func(ctrl *gomock.Controller) (credit.Service, handler.StreamHandler) {
+				svc := creditmocks.NewMockService(ctrl)
+				svc.EXPECT().GetCreditsByUID(gomock.Any(), int64(1)).
+					Return(credit.Credit{TotalAmount: 9}, nil)
+				return svc, hdlmocks.NewMockStreamHandler(ctrl)
+			},
+			wantErr: ErrInsufficientCredit,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, next := tc.mock(ctrl)
+			repo := &fakeCreditLogRepo{}
+			h := NewHandlerBuilder(svc, repo, tc.cfg).StreamNext(next)
+			ch, err := h.StreamHandle(context.Background(), domain.LLMRequest{
+				Uid:   1,
+				Biz:   "test",
+				Input: []string{"hello"},
+				Config: domain.BizConfig{
+					MaxInput:       100,
+					PromptTemplate: "abc",
+				},
+			})
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				if errors.Is(tc.wantErr, ErrInsufficientCredit) {
+					assert.ErrorIs(t, err, ErrInsufficientCredit)
+				} else {
+					assert.Equal(t, tc.wantErr.Error(), err.Error())
+				}
+			} else {
+				require.NoError(t, err)
+				var evts []domain.StreamEvent
+				for evt := range ch {
+					evts = append(evts, evt)
+				}
+				assert.Equal(t, tc.wantEvts, evts)
+			}
+			if tc.wantStatus == 0 {
+				assert.Empty(t, repo.logs)
+				return
+			}
+			last := repo.logs[len(repo.logs)-1]
+			assert.Equal(t, tc.wantStatus, last.Status)
+			assert.Equal(t, tc.wantAmount, last.Amount)
+		})
+	}
+}
+
+func newStreamNext(ctrl *gomock.Controller, evts ...domain.StreamEvent) handler.StreamHandler {
+	next := hdlmocks.NewMockStreamHandler(ctrl)
+	next.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+			ch := make(chan domain.StreamEvent, len(evts))
+			for _, evt := range evts {
+				ch <- evt
+			}
+			close(ch)
+			return ch, nil
+		})
+	return next
+}
+
+func newNext(ctrl *gomock.Controller, resp domain.LLMResponse, err error) handler.Handler {
+	next := hdlmocks.NewMockHandler(ctrl)
+	next.EXPECT().Handle(gomock.Any(), gomock.Any()).Return(resp, err)
+	return next
+}
+
+func amountMatcher(amount int64) gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		c, ok := x.(credit.Credit)
+		return ok && len(c.Logs) == 1 && c.Logs[0].ChangeAmount == amount
+	})
+}
+
+type fakeCreditLogRepo struct {
+	// 只用到了 SaveCredit
+	repository.LLMCreditLogRepo
+	logs []domain.LLMCredit
+}
+
+func (f *fakeCreditLogRepo) SaveCredit(ctx context.Context, l domain.LLMCredit) (int64, error) {
+	if l.Id == 0 {
+		l.Id = int64(len(f.logs) + 1)
+	}
+	f.logs = append(f.logs, l)
+	return l.Id, nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package guard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+var ErrInputRejected = errors.New("输入没有通过安全检查")
+
+// builtinPatterns 常见的角色覆盖和指令注入，总是会检查
+var builtinPatterns = []string{
+	`(?i)(ignore|disregard|forget)\s+(all\s+)?(the\s+)?(previous|above|prior)`,
+	`(?i)you\s+are\s+now\b`,
+	`(?im)^\s*(system|assistant)\s*[:：]`,
+	`(忽略|无视|忘记)(掉)?(以上|上面|上述|之前|前面)`,
+	`你(现在)?的新(身份|角色)`,
+	// 伪造的评分结果，比如单独一行的"评分：35K"
+	`(?im)^\s*(评分|评级)\s*[:：]\s*[123]5K`,
+	// 要求直接给出评分，比如"请给我评级：35K"，正常提到薪资的"这个岗位给 25K"不算
+	`(?i)(给我|请|直接)(打分|评分|评级|评为|打|评)\s*[:：为成]?\s*[123]5K`,
+}
+
+// userInputIdx 用户的回答在 Input 里面的下标。
+// 第一个一般是题目，后面是关键字、评分标准这些系统自己的数据，不需要检查
+const userInputIdx = 1
+
+// 包裹用户输入的分隔符，提示词模板可以告诉大模型只把里面的内容当成回答
+const (
+	openDelimiter  = "<user_input>"
+	closeDelimiter = "</user_input>"
+)
+
+var delimiterRegexp = regexp.MustCompile(`(?i)<\s*/?\s*user_input\s*>`)
+
+// Rules 全局的规则，所有业务共用，业务自己的规则在 BizConfig.Guard 里面
+type Rules struct {
+	Keywords []string
+	Patterns []string
+}
+
+// HandlerBuilder 检查用户的输入，拒绝关键字命中或者疑似提示词注入的输入
+// 它需要 BizConfig，所以要放在 config 后面；要记录被拒绝的请求，所以要放在 record 后面
+// 通过检查之后会去掉输入里面伪造的分隔符，业务的 builder 再用 Delimit 包裹起来
+type HandlerBuilder struct {
+	keywords []string
+	patterns []*regexp.Regexp
+	// 业务配置里面的正则表达式，pattern => *regexp.Regexp
+	compiled sync.Map
+	logger   *elog.Component
+}
+
+func NewHandlerBuilder(rules Rules) (*HandlerBuilder, error) {
+	patterns := make([]*regexp.Regexp, 0, len(builtinPatterns)+len(rules.Patterns))
+	for _, p := range append(slices.Clone(builtinPatterns), rules.Patterns...) {
+		reg, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("非法的正则表达式 %s: %w", p, err)
+		}
+		patterns = append(patterns, reg)
+	}
+	return &HandlerBuilder{
+		keywords: toLower(rules.Keywords),
+		patterns: patterns,
+		logger:   elog.DefaultLogger,
+	}, nil
+}
+
+func (h *HandlerBuilder) Name() string {
+	return "guard"
+}
+
+func (h *HandlerBuilder) Next(next handler.Handler) handler.Handler {
+	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+		req, err := h.check(req)
+		if err != nil {
+			return domain.LLMResponse{}, err
+		}
+		return next.Handle(ctx, req)
+	})
+}
+
+func (h *HandlerBuilder) StreamNext(next handler.StreamHandler) handler.StreamHandler {
+	return handler.StreamHandleFunc(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+		req, err := h.check(req)
+		if err != nil {
+			return nil, err
+		}
+		return next.StreamHandle(ctx, req)
+	})
+}
+
+// check 只检查用户的回答，通过之后返回去掉了分隔符的输入
+func (h *HandlerBuilder) check(req domain.LLMRequest) (domain.LLMRequest, error) {
+	rules := req.Config.Guard
+	if rules.Disabled || len(req.Input) <= userInputIdx {
+		return req, nil
+	}
+	keywords := append(slices.Clone(h.keywords), toLower(rules.Keywords)...)
+	patterns := append(slices.Clone(h.patterns), h.bizPatterns(rules.Patterns)...)
+	in := req.Input[userInputIdx]
+	err := h.inspect(in, keywords, patterns)
+	if err != nil {
+		h.logger.Warn("用户输入没有通过安全检查",
+			elog.FieldErr(err),
+			elog.String("biz", req.Biz),
+			elog.Int64("uid", req.Uid),
+			elog.String("tid", req.Tid))
+		return req, err
+	}
+	// 不能修改调用者的 Input
+	input := slices.Clone(req.Input)
+	input[userInputIdx] = Escape(in)
+	req.Input = input
+	return req, nil
+}
+
+func (h *HandlerBuilder) inspect(input string, keywords []string, patterns []*regexp.Regexp) error {
+	lower := strings.ToLower(input)
+	for _, kw := range keywords {
+		if kw != "" && strings.Contains(lower, kw) {
+			return fmt.Errorf("%w, 命中关键字 %s", ErrInputRejected, kw)
+		}
+	}
+	for _, reg := range patterns {
+		if reg.MatchString(input) {
+			return fmt.Errorf("%w, 命中规则 %s", ErrInputRejected, reg.String())
+		}
+	}
+	return nil
+}
+
+// bizPatterns 非法的正则表达式直接跳过，保存配置的时候已经校验过了
+func (h *HandlerBuilder) bizPatterns(patterns []string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		if val, ok := h.compiled.Load(p); ok {
+			res = append(res, val.(*regexp.Regexp))
+			continue
+		}
+		reg, err := regexp.Compile(p)
+		if err != nil {
+			h.logger.Error("非法的正则表达式", elog.FieldErr(err), elog.String("pattern", p))
+			continue
+		}
+		h.compiled.Store(p, reg)
+		res = append(res, reg)
+	}
+	return res
+}
+
+// Escape 去掉用户伪造的分隔符，避免用户的输入"逃出"分隔符
+func Escape(input string) string {
+	return delimiterRegexp.ReplaceAllString(input, "")
+}
+
+// Delimit 用分隔符把用户的输入包裹起来
+func Delimit(input string) string {
+	return openDelimiter + "\n" + input + "\n" + closeDelimiter
+}
+
+func toLower(keywords []string) []string {
+	res := make([]string, 0, len(keywords))
+	for _, kw := range keywords {
+		res = append(res, strings.ToLower(kw))
+	}
+	return res
+}
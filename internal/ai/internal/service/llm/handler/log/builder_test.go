@@ -0,0 +1,77 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	hdlmocks "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandlerBuilder_StreamNext(t *testing.T) {
+	testCases := []struct {
+		name string
+		evts []domain.StreamEvent
+		err  error
+
+		wantErr error
+	}{
+		{
+			name: "成功",
+			evts: []domain.StreamEvent{
+				{Content: "回答"},
+				{Done: true, Response: domain.LLMResponse{Tokens: 10, Answer: "回答"}},
+			},
+		},
+		{
+			name: "流式响应失败",
+			evts: []domain.StreamEvent{
+				{Content: "回"},
+				{Err: errors.New("mock error")},
+			},
+		},
+		{
+			name:    "调用失败",
+			err:     errors.New("mock error"),
+			wantErr: errors.New("mock error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			next := hdlmocks.NewMockStreamHandler(ctrl)
+			next.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+					if tc.err != nil {
+						return nil, tc.err
+					}
+					ch := make(chan domain.StreamEvent, len(tc.evts))
+					for _, evt := range tc.evts {
+						ch <- evt
+					}
+					close(ch)
+					return ch, nil
+				})
+			ch, err := NewHandler().StreamNext(next).StreamHandle(context.Background(), domain.LLMRequest{
+				Tid: "tid",
+				Biz: "test",
+				Uid: 1,
+			})
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			// 只记录日志，事件原样转发
+			var evts []domain.StreamEvent
+			for evt := range ch {
+				evts = append(evts, evt)
+			}
+			require.Equal(t, tc.evts, evts)
+		})
+	}
+}
@@ -139,3 +139,126 @@ func (c *BuilderNextCall) DoAndReturn(f func(handler.Handler) handler.Handler) *
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// MockStreamHandler is a mock of StreamHandler interface.
+type MockStreamHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamHandlerMockRecorder
+}
+
+// MockStreamHandlerMockRecorder is the mock recorder for MockStreamHandler.
+type MockStreamHandlerMockRecorder struct {
+	mock *MockStreamHandler
+}
+
+// NewMockStreamHandler creates a new mock instance.
+func NewMockStreamHandler(ctrl *gomock.Controller) *MockStreamHandler {
+	mock := &MockStreamHandler{ctrl: ctrl}
+	mock.recorder = &MockStreamHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStreamHandler) EXPECT() *MockStreamHandlerMockRecorder {
+	return m.recorder
+}
+
+// StreamHandle mocks base method.
+func (m *MockStreamHandler) StreamHandle(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamHandle", ctx, req)
+	ret0, _ := ret[0].(<-chan domain.StreamEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamHandle indicates an expected call of StreamHandle.
+func (mr *MockStreamHandlerMockRecorder) StreamHandle(ctx, req any) *StreamHandlerStreamHandleCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamHandle", reflect.TypeOf((*MockStreamHandler)(nil).StreamHandle), ctx, req)
+	return &StreamHandlerStreamHandleCall{Call: call}
+}
+
+// StreamHandlerStreamHandleCall wrap *gomock.Call
+type StreamHandlerStreamHandleCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamHandlerStreamHandleCall) Return(arg0 <-chan domain.StreamEvent, arg1 error) *StreamHandlerStreamHandleCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamHandlerStreamHandleCall) Do(f func(context.Context, domain.LLMRequest) (<-chan domain.StreamEvent, error)) *StreamHandlerStreamHandleCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamHandlerStreamHandleCall) DoAndReturn(f func(context.Context, domain.LLMRequest) (<-chan domain.StreamEvent, error)) *StreamHandlerStreamHandleCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockStreamBuilder is a mock of StreamBuilder interface.
+type MockStreamBuilder struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamBuilderMockRecorder
+}
+
+// MockStreamBuilderMockRecorder is the mock recorder for MockStreamBuilder.
+type MockStreamBuilderMockRecorder struct {
+	mock *MockStreamBuilder
+}
+
+// NewMockStreamBuilder creates a new mock instance.
+func NewMockStreamBuilder(ctrl *gomock.Controller) *MockStreamBuilder {
+	mock := &MockStreamBuilder{ctrl: ctrl}
+	mock.recorder = &MockStreamBuilderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStreamBuilder) EXPECT() *MockStreamBuilderMockRecorder {
+	return m.recorder
+}
+
+// StreamNext mocks base method.
+func (m *MockStreamBuilder) StreamNext(next handler.StreamHandler) handler.StreamHandler {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamNext", next)
+	ret0, _ := ret[0].(handler.StreamHandler)
+	return ret0
+}
+
+// StreamNext indicates an expected call of StreamNext.
+func (mr *MockStreamBuilderMockRecorder) StreamNext(next any) *StreamBuilderStreamNextCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamNext", reflect.TypeOf((*MockStreamBuilder)(nil).StreamNext), next)
+	return &StreamBuilderStreamNextCall{Call: call}
+}
+
+// StreamBuilderStreamNextCall wrap *gomock.Call
+type StreamBuilderStreamNextCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamBuilderStreamNextCall) Return(arg0 handler.StreamHandler) *StreamBuilderStreamNextCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamBuilderStreamNextCall) Do(f func(handler.StreamHandler) handler.StreamHandler) *StreamBuilderStreamNextCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamBuilderStreamNextCall) DoAndReturn(f func(handler.StreamHandler) handler.StreamHandler) *StreamBuilderStreamNextCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
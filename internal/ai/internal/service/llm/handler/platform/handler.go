@@ -0,0 +1,153 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+var (
+	ErrUnknownPlatform    = errors.New("未知的平台")
+	ErrStreamNotSupported = errors.New("平台不支持流式调用")
+)
+
+// Handler 是真正的出口，根据 BizConfig 选择平台和模型
+// 主平台失败之后，会按照 BizConfig.Fallbacks 的顺序尝试备用平台
+type Handler struct {
+	platforms map[string]handler.Handler
+	// BizConfig 里面没有指定平台的时候使用的平台
+	defaultPlatform string
+	logger          *elog.Component
+}
+
+var _ handler.Handler = &Handler{}
+var _ handler.StreamHandler = &Handler{}
+
+func NewHandler(platforms map[string]handler.Handler, defaultPlatform string) *Handler {
+	return &Handler{
+		platforms:       platforms,
+		defaultPlatform: defaultPlatform,
+		logger:          elog.DefaultLogger,
+	}
+}
+
+func (h *Handler) Handle(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+	var err error
+	for _, c := range h.candidates(req) {
+		p, ok := h.platforms[c.Platform]
+		if !ok {
+			err = fmt.Errorf("%w %s", ErrUnknownPlatform, c.Platform)
+			h.logger.Error("平台未注册", elog.String("platform", c.Platform), elog.String("biz", req.Biz))
+			continue
+		}
+		req.Config.Platform = c.Platform
+		req.Config.Model = c.Model
+		var resp domain.LLMResponse
+		resp, err = p.Handle(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			// 已经超时或者被取消了，没必要再尝试备用平台
+			return domain.LLMResponse{}, err
+		}
+		h.logFailover(req, c, err)
+	}
+	return domain.LLMResponse{}, err
+}
+
+func (h *Handler) StreamHandle(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	var err error
+	for _, c := range h.candidates(req) {
+		p, ok := h.platforms[c.Platform]
+		if !ok {
+			err = fmt.Errorf("%w %s", ErrUnknownPlatform, c.Platform)
+			h.logger.Error("平台未注册", elog.String("platform", c.Platform), elog.String("biz", req.Biz))
+			continue
+		}
+		sp, ok := p.(handler.StreamHandler)
+		if !ok {
+			err = fmt.Errorf("%w %s", ErrStreamNotSupported, c.Platform)
+			continue
+		}
+		req.Config.Platform = c.Platform
+		req.Config.Model = c.Model
+		var ch <-chan domain.StreamEvent
+		ch, err = sp.StreamHandle(ctx, req)
+		if err == nil {
+			// 只有在还没有返回任何内容的时候才能切换平台，
+			// 所以要等到第一个事件
+			first, ok := <-ch
+			switch {
+			case !ok:
+				err = handler.ErrStreamInterrupted
+			case first.Err != nil:
+				err = first.Err
+				// 把剩下的读完
+				for range ch {
+				}
+			default:
+				return prepend(first, ch), nil
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		h.logFailover(req, c, err)
+	}
+	return nil, err
+}
+
+// candidates 主平台和备用平台，平台为空的时候使用默认平台
+func (h *Handler) candidates(req domain.LLMRequest) []domain.PlatformConfig {
+	res := make([]domain.PlatformConfig, 0, len(req.Config.Fallbacks)+1)
+	res = append(res, domain.PlatformConfig{
+		Platform: req.Config.Platform,
+		Model:    req.Config.Model,
+	})
+	res = append(res, req.Config.Fallbacks...)
+	for i := range res {
+		if res[i].Platform == "" {
+			res[i].Platform = h.defaultPlatform
+		}
+	}
+	return res
+}
+
+func (h *Handler) logFailover(req domain.LLMRequest, c domain.PlatformConfig, err error) {
+	h.logger.Warn("平台调用失败，尝试下一个平台",
+		elog.String("platform", c.Platform),
+		elog.String("model", c.Model),
+		elog.String("tid", req.Tid),
+		elog.FieldErr(err))
+}
+
+func prepend(first domain.StreamEvent, src <-chan domain.StreamEvent) <-chan domain.StreamEvent {
+	dst := make(chan domain.StreamEvent, 1)
+	go func() {
+		defer close(dst)
+		dst <- first
+		for evt := range src {
+			dst <- evt
+		}
+	}()
+	return dst
+}
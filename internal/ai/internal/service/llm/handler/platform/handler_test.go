@@ -0,0 +1,284 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	hdlmocks "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_Handle(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) map[string]handler.Handler
+		cfg     domain.BizConfig
+		wantRes domain.LLMResponse
+		wantErr error
+	}{
+		{
+			name: "使用默认平台",
+			mock: func(ctrl *gomock.Controller) map[string]handler.Handler {
+				zp := hdlmocks.NewMockHandler(ctrl)
+				zp.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+						assert.Equal(t, "zhipu", req.Config.Platform)
+						return domain.LLMResponse{Tokens: 10, Amount: 1, Answer: "zhipu"}, nil
+					})
+				return map[string]handler.Handler{"zhipu": zp}
+			},
+			wantRes: domain.LLMResponse{Tokens: 10, Amount: 1, Answer: "zhipu"},
+		},
+		{
+			name: "使用指定平台和模型",
+			cfg:  domain.BizConfig{Platform: "openai", Model: "gpt-4o"},
+			mock: func(ctrl *gomock.Controller) map[string]handler.Handler {
+				zp := hdlmocks.NewMockHandler(ctrl)
+				op := hdlmocks.NewMockHandler(ctrl)
+				op.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+						assert.Equal(t, "gpt-4o", req.Config.Model)
+						return domain.LLMResponse{Tokens: 10, Amount: 2, Answer: "openai"}, nil
+					})
+				return map[string]handler.Handler{"zhipu": zp, "openai": op}
+			},
+			wantRes: domain.LLMResponse{Tokens: 10, Amount: 2, Answer: "openai"},
+		},
+		{
+			name: "主平台失败，使用备用平台",
+			cfg: domain.BizConfig{
+				Platform: "zhipu",
+				Fallbacks: []domain.PlatformConfig{
+					{Platform: "unknown"},
+					{Platform: "openai", Model: "gpt-4o-mini"},
+				},
+			},
+			mock: func(ctrl *gomock.Controller) map[string]handler.Handler {
+				zp := hdlmocks.NewMockHandler(ctrl)
+				zp.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{}, errors.New("mock error"))
+				op := hdlmocks.NewMockHandler(ctrl)
+				op.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+						assert.Equal(t, "gpt-4o-mini", req.Config.Model)
+						return domain.LLMResponse{Tokens: 10, Amount: 3, Answer: "openai"}, nil
+					})
+				return map[string]handler.Handler{"zhipu": zp, "openai": op}
+			},
+			wantRes: domain.LLMResponse{Tokens: 10, Amount: 3, Answer: "openai"},
+		},
+		{
+			name: "全部失败",
+			cfg: domain.BizConfig{
+				Fallbacks: []domain.PlatformConfig{
+					{Platform: "unknown"},
+				},
+			},
+			mock: func(ctrl *gomock.Controller) map[string]handler.Handler {
+				zp := hdlmocks.NewMockHandler(ctrl)
+				zp.EXPECT().Handle(gomock.Any(), gomock.Any()).
+					Return(domain.LLMResponse{}, errors.New("mock error"))
+				return map[string]handler.Handler{"zhipu": zp}
+			},
+			wantErr: ErrUnknownPlatform,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := NewHandler(tc.mock(ctrl), "zhipu")
+			res, err := h.Handle(context.Background(), domain.LLMRequest{
+				Biz:    domain.BizQuestionExamine,
+				Config: tc.cfg,
+			})
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+func TestHandler_StreamHandle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) map[string]handler.Handler
+		cfg      domain.BizConfig
+		wantEvts []domain.StreamEvent
+		wantErr  error
+	}{
+		{
+			name: "使用默认平台",
+			mock: func(ctrl *gomock.Controller) map[string]handler.Handler {
+				zp := newStreamPlatform(ctrl)
+				zp.MockStreamHandler.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+						assert.Equal(t, "zhipu", req.Config.Platform)
+						return newStream(
+							domain.StreamEvent{Content: "zhipu"},
+							domain.StreamEvent{Done: true, Response: domain.LLMResponse{Answer: "zhipu"}},
+						), nil
+					})
+				return map[string]handler.Handler{"zhipu": zp}
+			},
+			wantEvts: []domain.StreamEvent{
+				{Content: "zhipu"},
+				{Done: true, Response: domain.LLMResponse{Answer: "zhipu"}},
+			},
+		},
+		{
+			name: "主平台第一个事件就失败了，切换到备用平台",
+			cfg: domain.BizConfig{
+				Platform: "zhipu",
+				Fallbacks: []domain.PlatformConfig{
+					{Platform: "openai", Model: "gpt-4o-mini"},
+				},
+			},
+			mock: func(ctrl *gomock.Controller) map[string]handler.Handler {
+				zp := newStreamPlatform(ctrl)
+				zp.MockStreamHandler.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+					Return(newStream(domain.StreamEvent{Err: errors.New("mock error")}), nil)
+				op := newStreamPlatform(ctrl)
+				op.MockStreamHandler.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+						assert.Equal(t, "gpt-4o-mini", req.Config.Model)
+						return newStream(
+							domain.StreamEvent{Content: "openai"},
+							domain.StreamEvent{Done: true, Response: domain.LLMResponse{Answer: "openai"}},
+						), nil
+					})
+				return map[string]handler.Handler{"zhipu": zp, "openai": op}
+			},
+			wantEvts: []domain.StreamEvent{
+				{Content: "openai"},
+				{Done: true, Response: domain.LLMResponse{Answer: "openai"}},
+			},
+		},
+		{
+			name: "主平台返回错误或者不支持流式调用，切换到备用平台",
+			cfg: domain.BizConfig{
+				Platform: "zhipu",
+				Fallbacks: []domain.PlatformConfig{
+					{Platform: "unknown"},
+					{Platform: "nostream"},
+					{Platform: "openai"},
+				},
+			},
+			mock: func(ctrl *gomock.Controller) map[string]handler.Handler {
+				zp := newStreamPlatform(ctrl)
+				zp.MockStreamHandler.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("mock error"))
+				op := newStreamPlatform(ctrl)
+				op.MockStreamHandler.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+					Return(newStream(domain.StreamEvent{Done: true, Response: domain.LLMResponse{Answer: "openai"}}), nil)
+				return map[string]handler.Handler{
+					"zhipu":    zp,
+					"nostream": hdlmocks.NewMockHandler(ctrl),
+					"openai":   op,
+				}
+			},
+			wantEvts: []domain.StreamEvent{
+				{Done: true, Response: domain.LLMResponse{Answer: "openai"}},
+			},
+		},
+		{
+			name: "已经返回了内容之后失败，不再切换平台",
+			cfg: domain.BizConfig{
+				Platform: "zhipu",
+				Fallbacks: []domain.PlatformConfig{
+					{Platform: "openai"},
+				},
+			},
+			mock: func(ctrl *gomock.Controller) map[string]handler.Handler {
+				zp := newStreamPlatform(ctrl)
+				zp.MockStreamHandler.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+					Return(newStream(
+						domain.StreamEvent{Content: "zhipu"},
+						domain.StreamEvent{Err: errors.New("mock error")},
+					), nil)
+				return map[string]handler.Handler{"zhipu": zp, "openai": newStreamPlatform(ctrl)}
+			},
+			wantEvts: []domain.StreamEvent{
+				{Content: "zhipu"},
+				{Err: errors.New("mock error")},
+			},
+		},
+		{
+			name: "全部失败",
+			cfg: domain.BizConfig{
+				Fallbacks: []domain.PlatformConfig{
+					{Platform: "nostream"},
+				},
+			},
+			mock: func(ctrl *gomock.Controller) map[string]handler.Handler {
+				zp := newStreamPlatform(ctrl)
+				// 没有发送最后一个事件就关闭了
+				zp.MockStreamHandler.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+					Return(newStream(), nil)
+				return map[string]handler.Handler{"zhipu": zp, "nostream": hdlmocks.NewMockHandler(ctrl)}
+			},
+			wantErr: ErrStreamNotSupported,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := NewHandler(tc.mock(ctrl), "zhipu")
+			ch, err := h.StreamHandle(context.Background(), domain.LLMRequest{
+				Biz:    domain.BizQuestionExamine,
+				Config: tc.cfg,
+			})
+			assert.ErrorIs(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+			var evts []domain.StreamEvent
+			for evt := range ch {
+				evts = append(evts, evt)
+			}
+			assert.Equal(t, tc.wantEvts, evts)
+		})
+	}
+}
+
+// streamPlatform 同时支持普通调用和流式调用的平台
+type streamPlatform struct {
+	*hdlmocks.MockHandler
+	*hdlmocks.MockStreamHandler
+}
+
+func newStreamPlatform(ctrl *gomock.Controller) streamPlatform {
+	return streamPlatform{
+		MockHandler:       hdlmocks.NewMockHandler(ctrl),
+		MockStreamHandler: hdlmocks.NewMockStreamHandler(ctrl),
+	}
+}
+
+func newStream(evts ...domain.StreamEvent) <-chan domain.StreamEvent {
+	ch := make(chan domain.StreamEvent, len(evts))
+	for _, evt := range evts {
+		ch <- evt
+	}
+	close(ch)
+	return ch
+}
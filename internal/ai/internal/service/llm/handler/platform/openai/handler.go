@@ -0,0 +1,233 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+)
+
+// Handler 兼容 OpenAI chat completions 接口的平台
+// 大部分国内外的平台都提供了兼容接口，所以只需要配置不同的 baseURL 就可以接入
+type Handler struct {
+	client  *http.Client
+	timeout time.Duration
+	baseURL string
+	apikey  string
+	// 没有指定模型的时候使用的模型
+	model string
+	// 价格和 model 进行绑定的
+	prices map[string]float64
+}
+
+// NewHandler timeout 是等待平台响应的超时时间
+// 流式响应的 Body 会持续比较久，所以 client 只限制等待响应头的时间，非流式调用在 Handle 里面限制整体时间
+func NewHandler(baseURL string,
+	apikey string,
+	model string,
+	prices map[string]float64,
+	timeout time.Duration) *Handler {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	return &Handler{
+		client:  &http.Client{Transport: transport},
+		timeout: timeout,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apikey:  apikey,
+		model:   model,
+		prices:  prices,
+	}
+}
+
+func (h *Handler) Name() string {
+	return "openai"
+}
+
+func (h *Handler) Handle(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+	body, price, err := h.newReq(req)
+	if err != nil {
+		return domain.LLMResponse{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	httpResp, err := h.do(ctx, body)
+	if err != nil {
+		return domain.LLMResponse{}, err
+	}
+	defer httpResp.Body.Close()
+	var completion chatCompletionResp
+	err = json.NewDecoder(httpResp.Body).Decode(&completion)
+	if err != nil {
+		return domain.LLMResponse{}, err
+	}
+	resp := h.newResponse(completion.Usage.TotalTokens, price)
+	if len(completion.Choices) > 0 {
+		resp.Answer = completion.Choices[0].Message.Content
+	}
+	return resp, nil
+}
+
+func (h *Handler) StreamHandle(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	body, price, err := h.newReq(req)
+	if err != nil {
+		return nil, err
+	}
+	body.Stream = true
+	body.StreamOptions = &streamOptions{IncludeUsage: true}
+	ch := make(chan domain.StreamEvent, 10)
+	go func() {
+		defer close(ch)
+		httpResp, err := h.do(ctx, body)
+		if err != nil {
+			ch <- domain.StreamEvent{Err: err}
+			return
+		}
+		defer httpResp.Body.Close()
+		var (
+			answer strings.Builder
+			tokens int64
+		)
+		scanner := bufio.NewScanner(httpResp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			data, ok := strings.CutPrefix(line, "data:")
+			if !ok {
+				continue
+			}
+			data = strings.TrimSpace(data)
+			if data == "[DONE]" {
+				break
+			}
+			var chunk chatCompletionChunk
+			err = json.Unmarshal([]byte(data), &chunk)
+			if err != nil {
+				ch <- domain.StreamEvent{Err: err}
+				return
+			}
+			if chunk.Usage != nil {
+				tokens = chunk.Usage.TotalTokens
+			}
+			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+				answer.WriteString(chunk.Choices[0].Delta.Content)
+				ch <- domain.StreamEvent{Content: chunk.Choices[0].Delta.Content}
+			}
+		}
+		if err = scanner.Err(); err != nil {
+			ch <- domain.StreamEvent{Err: err}
+			return
+		}
+		resp := h.newResponse(tokens, price)
+		resp.Answer = answer.String()
+		ch <- domain.StreamEvent{Done: true, Response: resp}
+	}()
+	return ch, nil
+}
+
+func (h *Handler) newReq(req domain.LLMRequest) (chatCompletionReq, float64, error) {
+	model := req.Config.Model
+	if model == "" {
+		model = h.model
+	}
+	price, ok := h.prices[model]
+	if !ok {
+		return chatCompletionReq{}, 0, fmt.Errorf("未配置模型价格 %s, model: %s", h.Name(), model)
+	}
+	return chatCompletionReq{
+		Model: model,
+		Messages: []message{
+			{Role: "user", Content: req.Prompt},
+		},
+	}, price, nil
+}
+
+func (h *Handler) newResponse(tokens int64, price float64) domain.LLMResponse {
+	// 报价都是 N/1k token，向上取整
+	amt := math.Ceil(float64(tokens) * price / 1000)
+	return domain.LLMResponse{
+		Tokens: tokens,
+		Amount: int64(amt),
+	}
+}
+
+// do 发送请求，调用者负责关闭 Body
+func (h *Handler) do(ctx context.Context, body chatCompletionReq) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		h.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+h.apikey)
+	httpResp, err := h.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1024))
+		return nil, fmt.Errorf("调用 %s 失败，状态码 %d，响应 %s", h.Name(), httpResp.StatusCode, msg)
+	}
+	return httpResp, nil
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatCompletionReq struct {
+	Model         string         `json:"model"`
+	Messages      []message      `json:"messages"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+type usage struct {
+	TotalTokens int64 `json:"total_tokens"`
+}
+
+type chatCompletionResp struct {
+	Choices []struct {
+		Message message `json:"message"`
+	} `json:"choices"`
+	Usage usage `json:"usage"`
+}
+
+type chatCompletionChunk struct {
+	Choices []struct {
+		Delta message `json:"delta"`
+	} `json:"choices"`
+	// 只有最后一个 chunk 才有
+	Usage *usage `json:"usage"`
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Handle(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler http.HandlerFunc
+		model   string
+		timeout time.Duration
+
+		wantResp domain.LLMResponse
+		wantErr  bool
+	}{
+		{
+			name: "成功",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/chat/completions", r.URL.Path)
+				assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+				var req chatCompletionReq
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, "gpt-4o-mini", req.Model)
+				assert.Equal(t, []message{{Role: "user", Content: "问题"}}, req.Messages)
+				_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"回答"}}],` +
+					`"usage":{"total_tokens":1500}}`))
+			},
+			timeout: time.Second,
+			// 1500 token，每 1k token 3 个积分，向上取整
+			wantResp: domain.LLMResponse{Tokens: 1500, Amount: 5, Answer: "回答"},
+		},
+		{
+			name: "状态码不对",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"error":"rate limit"}`))
+			},
+			timeout: time.Second,
+			wantErr: true,
+		},
+		{
+			name: "超时",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(time.Millisecond * 200)
+				_, _ = w.Write([]byte(`{}`))
+			},
+			timeout: time.Millisecond * 50,
+			wantErr: true,
+		},
+		{
+			name:    "没有配置价格",
+			model:   "gpt-4o",
+			timeout: time.Second,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			h := NewHandler(server.URL+"/", "key", "gpt-4o-mini",
+				map[string]float64{"gpt-4o-mini": 3}, tc.timeout)
+			resp, err := h.Handle(context.Background(), domain.LLMRequest{
+				Prompt: "问题",
+				Config: domain.BizConfig{Model: tc.model},
+			})
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantResp, resp)
+		})
+	}
+}
+
+func TestHandler_StreamHandle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatCompletionReq
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"choices":[{"delta":{"content":"回"}}]}`,
+			`{"choices":[{"delta":{"content":"答"}}]}`,
+			`{"choices":[],"usage":{"total_tokens":1000}}`,
+		} {
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+		}
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+	h := NewHandler(server.URL, "key", "gpt-4o-mini",
+		map[string]float64{"gpt-4o-mini": 3}, time.Second)
+	ch, err := h.StreamHandle(context.Background(), domain.LLMRequest{Prompt: "问题"})
+	require.NoError(t, err)
+	var events []domain.StreamEvent
+	for evt := range ch {
+		events = append(events, evt)
+	}
+	assert.Equal(t, []domain.StreamEvent{
+		{Content: "回"},
+		{Content: "答"},
+		{Done: true, Response: domain.LLMResponse{Tokens: 1000, Amount: 3, Answer: "回答"}},
+	}, events)
+}
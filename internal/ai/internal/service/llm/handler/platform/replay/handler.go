@@ -0,0 +1,179 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+)
+
+const (
+	ModeRecord = "record"
+	ModeReplay = "replay"
+)
+
+var ErrFixtureNotFound = errors.New("没有录制的响应")
+
+// Handler 录制回放平台，主要用于本地开发和测试
+// 录制模式下，它会调用真实的平台，并且把请求和响应保存到 dir 下；
+// 回放模式下，它只会从 dir 下读取响应，不会访问网络
+// 文件按照 biz 分目录，文件名是 prompt 的哈希值
+type Handler struct {
+	dir string
+	// 录制模式下的真实平台，回放模式下为 nil
+	platform handler.Handler
+}
+
+var _ handler.Handler = &Handler{}
+var _ handler.StreamHandler = &Handler{}
+
+func NewRecordHandler(dir string, platform handler.Handler) *Handler {
+	return &Handler{
+		dir:      dir,
+		platform: platform,
+	}
+}
+
+func NewReplayHandler(dir string) *Handler {
+	return &Handler{
+		dir: dir,
+	}
+}
+
+func (h *Handler) Name() string {
+	return "replay"
+}
+
+func (h *Handler) Handle(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+	if h.platform == nil {
+		f, err := h.load(req)
+		if err != nil {
+			return domain.LLMResponse{}, err
+		}
+		return f.toResponse(), nil
+	}
+	resp, err := h.platform.Handle(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	err = h.save(req, resp)
+	return resp, err
+}
+
+func (h *Handler) StreamHandle(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	if h.platform == nil {
+		f, err := h.load(req)
+		if err != nil {
+			return nil, err
+		}
+		ch := make(chan domain.StreamEvent, 2)
+		ch <- domain.StreamEvent{Content: f.Answer}
+		ch <- domain.StreamEvent{Done: true, Response: f.toResponse()}
+		close(ch)
+		return ch, nil
+	}
+	sp, ok := h.platform.(handler.StreamHandler)
+	if !ok {
+		return nil, fmt.Errorf("录制的平台不支持流式调用")
+	}
+	ch, err := sp.StreamHandle(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return handler.StreamAfter(ch, func(evt domain.StreamEvent) domain.StreamEvent {
+		if evt.Err != nil {
+			return evt
+		}
+		if err1 := h.save(req, evt.Response); err1 != nil {
+			return domain.StreamEvent{Err: err1}
+		}
+		return evt
+	}), nil
+}
+
+func (h *Handler) load(req domain.LLMRequest) (fixture, error) {
+	var f fixture
+	path := h.path(req)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, fmt.Errorf("%w biz: %s, 文件: %s", ErrFixtureNotFound, req.Biz, path)
+	}
+	if err != nil {
+		return f, err
+	}
+	err = json.Unmarshal(data, &f)
+	return f, err
+}
+
+func (h *Handler) save(req domain.LLMRequest, resp domain.LLMResponse) error {
+	path := h.path(req)
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(fixture{
+		Biz:    req.Biz,
+		Prompt: req.Prompt,
+		Answer: resp.Answer,
+		Tokens: resp.Tokens,
+		Amount: resp.Amount,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免并发录制的时候读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	err1 := tmp.Close()
+	if err != nil || err1 != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Join(err, err1)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (h *Handler) path(req domain.LLMRequest) string {
+	sum := sha256.Sum256([]byte(req.Prompt))
+	return filepath.Join(h.dir, req.Biz, hex.EncodeToString(sum[:])+".json")
+}
+
+type fixture struct {
+	Biz string `json:"biz"`
+	// 原始的 prompt，方便人工查看
+	Prompt string `json:"prompt"`
+	Answer string `json:"answer"`
+	Tokens int64  `json:"tokens"`
+	Amount int64  `json:"amount"`
+}
+
+func (f fixture) toResponse() domain.LLMResponse {
+	return domain.LLMResponse{
+		Tokens: f.Tokens,
+		Amount: f.Amount,
+		Answer: f.Answer,
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	hdlmocks "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_RecordAndReplay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dir := t.TempDir()
+	want := domain.LLMResponse{Tokens: 100, Amount: 10, Answer: "评分：25K"}
+	req := domain.LLMRequest{
+		Biz:    domain.BizQuestionExamine,
+		Prompt: "这是问题 问题1，这是用户输入 用户输入1",
+	}
+
+	platform := hdlmocks.NewMockHandler(ctrl)
+	platform.EXPECT().Handle(gomock.Any(), gomock.Any()).Return(want, nil)
+	resp, err := NewRecordHandler(dir, platform).Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, want, resp)
+
+	h := NewReplayHandler(dir)
+	resp, err = h.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, want, resp)
+
+	ch, err := h.StreamHandle(context.Background(), req)
+	require.NoError(t, err)
+	var events []domain.StreamEvent
+	for evt := range ch {
+		events = append(events, evt)
+	}
+	assert.Equal(t, []domain.StreamEvent{
+		{Content: want.Answer},
+		{Done: true, Response: want},
+	}, events)
+
+	req.Prompt = "没有录制过的"
+	_, err = h.Handle(context.Background(), req)
+	assert.ErrorIs(t, err, ErrFixtureNotFound)
+}
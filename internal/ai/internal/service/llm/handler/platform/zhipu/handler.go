@@ -2,6 +2,7 @@ package zhipu
 
 import (
 	"context"
+	"fmt"
 	"math"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
@@ -11,24 +12,23 @@ import (
 // Handler 如果后续有不同的实现，就提供不同的实现
 type Handler struct {
 	client *zhipu.Client
-	svc    *zhipu.ChatCompletionService
+	// 没有指定模型的时候使用的模型
+	model string
 	// 价格和 model 进行绑定的
-	price float64
+	prices map[string]float64
 }
 
 func NewHandler(apikey string,
-	price float64) (*Handler, error) {
+	model string,
+	prices map[string]float64) (*Handler, error) {
 	client, err := zhipu.NewClient(zhipu.WithAPIKey(apikey))
 	if err != nil {
 		return nil, err
 	}
-	const model = "glm-4-0520"
-	svc := client.ChatCompletion(model)
 	return &Handler{
 		client: client,
-		// 后续可以做成可配置的
-		svc:   svc,
-		price: price,
+		model:  model,
+		prices: prices,
 	}, err
 }
 
@@ -37,20 +37,65 @@ func (h *Handler) Name() string {
 }
 
 func (h *Handler) Handle(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+	svc, price, err := h.newService(req)
+	if err != nil {
+		return domain.LLMResponse{}, err
+	}
 	// 这边它不会调用 next，因为它是最终的出口
-	completion, err := h.svc.AddTool(zhipu.ChatCompletionToolRetrieval{
+	completion, err := svc.Do(ctx)
+	if err != nil {
+		return domain.LLMResponse{}, err
+	}
+	return h.newResponse(completion, price), nil
+}
+
+func (h *Handler) StreamHandle(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	svc, price, err := h.newService(req)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan domain.StreamEvent, 10)
+	go func() {
+		defer close(ch)
+		completion, err := svc.SetStreamHandler(func(chunk zhipu.ChatCompletionResponse) error {
+			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+				ch <- domain.StreamEvent{Content: chunk.Choices[0].Delta.Content}
+			}
+			return nil
+		}).Do(ctx)
+		if err != nil {
+			ch <- domain.StreamEvent{Err: err}
+			return
+		}
+		ch <- domain.StreamEvent{Done: true, Response: h.newResponse(completion, price)}
+	}()
+	return ch, nil
+}
+
+func (h *Handler) newService(req domain.LLMRequest) (*zhipu.ChatCompletionService, float64, error) {
+	model := req.Config.Model
+	if model == "" {
+		model = h.model
+	}
+	price, ok := h.prices[model]
+	if !ok {
+		return nil, 0, fmt.Errorf("未配置模型价格 %s, model: %s", h.Name(), model)
+	}
+	// ChatCompletionService 是有状态的，所以每次调用都要创建一个新的
+	svc := h.client.ChatCompletion(model).AddTool(zhipu.ChatCompletionToolRetrieval{
 		KnowledgeID: req.Config.KnowledgeId,
 	}).AddMessage(zhipu.ChatCompletionMessage{
 		Role:    "user",
 		Content: req.Prompt,
-	}).Do(ctx)
-	if err != nil {
-		return domain.LLMResponse{}, err
-	}
+	})
+	return svc, price, nil
+}
+
+func (h *Handler) newResponse(completion zhipu.ChatCompletionResponse, price float64) domain.LLMResponse {
 	tokens := completion.Usage.TotalTokens
 	// 现在的报价都是 N/1k token
 	// 而后向上取整
-	amt := math.Ceil(float64(tokens) * h.price / 1000)
+	amt := math.Ceil(float64(tokens) * price / 1000)
 	// 金额只有具体的模型才知道怎么算
 	resp := domain.LLMResponse{
 		Tokens: tokens,
@@ -60,5 +105,5 @@ func (h *Handler) Handle(ctx context.Context, req domain.LLMRequest) (domain.LLM
 	if len(completion.Choices) > 0 {
 		resp.Answer = completion.Choices[0].Message.Content
 	}
-	return resp, nil
+	return resp
 }
@@ -0,0 +1,77 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package quota
+
+import (
+	"context"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/quota"
+)
+
+// HandlerBuilder 按照用户和业务限制调用次数和 token 数
+// 要放在 credit 前面，超过配额的请求不需要预扣积分
+type HandlerBuilder struct {
+	svc quota.Service
+}
+
+func NewHandlerBuilder(svc quota.Service) *HandlerBuilder {
+	return &HandlerBuilder{
+		svc: svc,
+	}
+}
+
+func (h *HandlerBuilder) Name() string {
+	return "quota"
+}
+
+func (h *HandlerBuilder) Next(next handler.Handler) handler.Handler {
+	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
+		err := h.svc.Acquire(ctx, req.Uid, req.Biz)
+		if err != nil {
+			return domain.LLMResponse{}, err
+		}
+		resp, err := next.Handle(ctx, req)
+		if err != nil {
+			h.svc.Release(ctx, req.Uid, req.Biz)
+			return resp, err
+		}
+		h.svc.Consume(ctx, req.Uid, req.Biz, resp.Tokens)
+		return resp, nil
+	})
+}
+
+func (h *HandlerBuilder) StreamNext(next handler.StreamHandler) handler.StreamHandler {
+	return handler.StreamHandleFunc(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+		err := h.svc.Acquire(ctx, req.Uid, req.Biz)
+		if err != nil {
+			return nil, err
+		}
+		ch, err := next.StreamHandle(ctx, req)
+		if err != nil {
+			h.svc.Release(ctx, req.Uid, req.Biz)
+			return nil, err
+		}
+		return handler.StreamAfter(ch, func(evt domain.StreamEvent) domain.StreamEvent {
+			ctx := context.WithoutCancel(ctx)
+			if evt.Err != nil {
+				h.svc.Release(ctx, req.Uid, req.Biz)
+				return evt
+			}
+			h.svc.Consume(ctx, req.Uid, req.Biz, evt.Response.Tokens)
+			return evt
+		}), nil
+	})
+}
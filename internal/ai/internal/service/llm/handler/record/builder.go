@@ -2,8 +2,10 @@ package record
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/guard"
 	"github.com/gotomicro/ego/core/elog"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
@@ -27,29 +29,72 @@ func (h *HandlerBuilder) Name() string {
 
 func (h *HandlerBuilder) Next(next handler.Handler) handler.Handler {
 	return handler.HandleFunc(func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
-		log := domain.LLMRecord{
-			Tid:            req.Tid,
-			Biz:            req.Biz,
-			Uid:            req.Uid,
-			Input:          req.Input,
-			KnowledgeId:    req.Config.KnowledgeId,
-			PromptTemplate: req.Config.PromptTemplate,
-		}
+		log := h.newRecord(req)
 		defer func() {
-			_, err1 := h.repo.SaveLog(ctx, log)
-			if err1 != nil {
-				h.logger.Error("保存 LLM 访问记录失败", elog.FieldErr(err1))
-			}
+			h.save(ctx, log)
 		}()
 		resp, err := next.Handle(ctx, req)
 		if err != nil {
-			log.Status = domain.RecordStatusFailed
+			log.Status = h.failedStatus(err)
 			return domain.LLMResponse{}, err
 		}
 		log.Tokens = resp.Tokens
 		log.Amount = resp.Amount
 		log.Status = domain.RecordStatusProcessing
 		log.Answer = resp.Answer
+		log.Cached = resp.Cached
 		return resp, err
 	})
 }
+
+func (h *HandlerBuilder) StreamNext(next handler.StreamHandler) handler.StreamHandler {
+	return handler.StreamHandleFunc(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+		log := h.newRecord(req)
+		ch, err := next.StreamHandle(ctx, req)
+		if err != nil {
+			log.Status = h.failedStatus(err)
+			h.save(ctx, log)
+			return nil, err
+		}
+		return handler.StreamAfter(ch, func(evt domain.StreamEvent) domain.StreamEvent {
+			if evt.Err != nil {
+				log.Status = domain.RecordStatusFailed
+			} else {
+				log.Tokens = evt.Response.Tokens
+				log.Amount = evt.Response.Amount
+				log.Status = domain.RecordStatusProcessing
+				log.Answer = evt.Response.Answer
+				log.Cached = evt.Response.Cached
+			}
+			h.save(context.WithoutCancel(ctx), log)
+			return evt
+		}), nil
+	})
+}
+
+func (h *HandlerBuilder) newRecord(req domain.LLMRequest) domain.LLMRecord {
+	return domain.LLMRecord{
+		Tid:            req.Tid,
+		Biz:            req.Biz,
+		Uid:            req.Uid,
+		Input:          req.Input,
+		KnowledgeId:    req.Config.KnowledgeId,
+		PromptTemplate: req.Config.PromptTemplate,
+		PromptVersion:  req.Config.PromptVersion,
+	}
+}
+
+// failedStatus 没有通过安全检查的请求单独标记出来，方便排查
+func (h *HandlerBuilder) failedStatus(err error) domain.RecordStatus {
+	if errors.Is(err, guard.ErrInputRejected) {
+		return domain.RecordStatusRejected
+	}
+	return domain.RecordStatusFailed
+}
+
+func (h *HandlerBuilder) save(ctx context.Context, log domain.LLMRecord) {
+	_, err := h.repo.SaveLog(ctx, log)
+	if err != nil {
+		h.logger.Error("保存 LLM 访问记录失败", elog.FieldErr(err))
+	}
+}
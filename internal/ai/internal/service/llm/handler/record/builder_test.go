@@ -0,0 +1,129 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/guard"
+	hdlmocks "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandlerBuilder_StreamNext(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) handler.StreamHandler
+
+		wantErr    bool
+		wantRecord domain.LLMRecord
+	}{
+		{
+			name: "成功",
+			mock: func(ctrl *gomock.Controller) handler.StreamHandler {
+				return newStreamNext(ctrl,
+					domain.StreamEvent{Content: "回答"},
+					domain.StreamEvent{Done: true, Response: domain.LLMResponse{
+						Tokens: 10, Amount: 2, Answer: "回答",
+					}})
+			},
+			wantRecord: domain.LLMRecord{
+				Tid:    "tid",
+				Biz:    "test",
+				Uid:    1,
+				Input:  []string{"hello"},
+				Tokens: 10,
+				Amount: 2,
+				Answer: "回答",
+				Status: domain.RecordStatusProcessing,
+			},
+		},
+		{
+			name: "流式响应失败",
+			mock: func(ctrl *gomock.Controller) handler.StreamHandler {
+				return newStreamNext(ctrl,
+					domain.StreamEvent{Content: "回"},
+					domain.StreamEvent{Err: errors.New("mock error")})
+			},
+			wantRecord: domain.LLMRecord{
+				Tid:    "tid",
+				Biz:    "test",
+				Uid:    1,
+				Input:  []string{"hello"},
+				Status: domain.RecordStatusFailed,
+			},
+		},
+		{
+			name: "没有通过安全检查",
+			mock: func(ctrl *gomock.Controller) handler.StreamHandler {
+				next := hdlmocks.NewMockStreamHandler(ctrl)
+				next.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+					Return(nil, fmt.Errorf("%w, 命中关键字 abc", guard.ErrInputRejected))
+				return next
+			},
+			wantErr: true,
+			wantRecord: domain.LLMRecord{
+				Tid:    "tid",
+				Biz:    "test",
+				Uid:    1,
+				Input:  []string{"hello"},
+				Status: domain.RecordStatusRejected,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := &fakeLogRepo{}
+			h := NewHandler(repo).StreamNext(tc.mock(ctrl))
+			ch, err := h.StreamHandle(context.Background(), domain.LLMRequest{
+				Tid:   "tid",
+				Biz:   "test",
+				Uid:   1,
+				Input: []string{"hello"},
+			})
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				for range ch {
+				}
+			}
+			// 读完之后才会保存记录
+			require.Len(t, repo.logs, 1)
+			assert.Equal(t, tc.wantRecord, repo.logs[0])
+		})
+	}
+}
+
+func newStreamNext(ctrl *gomock.Controller, evts ...domain.StreamEvent) handler.StreamHandler {
+	next := hdlmocks.NewMockStreamHandler(ctrl)
+	next.EXPECT().StreamHandle(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+			ch := make(chan domain.StreamEvent, len(evts))
+			for _, evt := range evts {
+				ch <- evt
+			}
+			close(ch)
+			return ch, nil
+		})
+	return next
+}
+
+type fakeLogRepo struct {
+	// 只用到了 SaveLog
+	repository.LLMLogRepo
+	logs []domain.LLMRecord
+}
+
+func (f *fakeLogRepo) SaveLog(ctx context.Context, l domain.LLMRecord) (int64, error) {
+	f.logs = append(f.logs, l)
+	return int64(len(f.logs)), nil
+}
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
 )
 
+var ErrStreamInterrupted = errors.New("流式响应意外中断")
+
 type HandleFunc func(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error)
 
 func (f HandleFunc) Handle(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
@@ -20,3 +23,44 @@ type Handler interface {
 type Builder interface {
 	Next(next Handler) Handler
 }
+
+type StreamHandleFunc func(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error)
+
+func (f StreamHandleFunc) StreamHandle(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	return f(ctx, req)
+}
+
+// StreamHandler 流式调用
+// 返回的 channel 在调用结束之后会被关闭，最后一个事件要么 Done 为 true，要么 Err 不为 nil
+// 调用者必须把 channel 读完，否则中间的 Builder 没有机会完成扣费、记录等收尾工作
+type StreamHandler interface {
+	StreamHandle(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error)
+}
+
+// StreamBuilder 支持流式调用的 Builder
+type StreamBuilder interface {
+	StreamNext(next StreamHandler) StreamHandler
+}
+
+// StreamAfter 转发 src 中的事件，并且在最后一个事件发出去之前调用 after
+// after 可以修改最后一个事件，例如在扣费失败的时候将其改为失败事件
+func StreamAfter(src <-chan domain.StreamEvent,
+	after func(evt domain.StreamEvent) domain.StreamEvent) <-chan domain.StreamEvent {
+	dst := make(chan domain.StreamEvent, 1)
+	go func() {
+		defer close(dst)
+		finished := false
+		for evt := range src {
+			if evt.Done || evt.Err != nil {
+				finished = true
+				evt = after(evt)
+			}
+			dst <- evt
+		}
+		if !finished {
+			// 下游没有按照约定发送最后一个事件
+			dst <- after(domain.StreamEvent{Err: ErrStreamInterrupted})
+		}
+	}()
+	return dst
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamAfter(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  []domain.StreamEvent
+		// after 修改最后一个事件
+		after func(evt domain.StreamEvent) domain.StreamEvent
+
+		wantEvts  []domain.StreamEvent
+		wantCalls int
+	}{
+		{
+			name: "正常结束",
+			src: []domain.StreamEvent{
+				{Content: "回"},
+				{Content: "答"},
+				{Done: true, Response: domain.LLMResponse{Tokens: 10, Answer: "回答"}},
+			},
+			after: func(evt domain.StreamEvent) domain.StreamEvent {
+				evt.Response.Amount = 2
+				return evt
+			},
+			wantEvts: []domain.StreamEvent{
+				{Content: "回"},
+				{Content: "答"},
+				{Done: true, Response: domain.LLMResponse{Tokens: 10, Amount: 2, Answer: "回答"}},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "失败事件",
+			src: []domain.StreamEvent{
+				{Content: "回"},
+				{Err: errors.New("mock error")},
+			},
+			after: func(evt domain.StreamEvent) domain.StreamEvent {
+				return evt
+			},
+			wantEvts: []domain.StreamEvent{
+				{Content: "回"},
+				{Err: errors.New("mock error")},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "after 把成功改为失败",
+			src: []domain.StreamEvent{
+				{Done: true, Response: domain.LLMResponse{Tokens: 10}},
+			},
+			after: func(evt domain.StreamEvent) domain.StreamEvent {
+				return domain.StreamEvent{Err: errors.New("扣费失败")}
+			},
+			wantEvts: []domain.StreamEvent{
+				{Err: errors.New("扣费失败")},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "下游没有发送最后一个事件",
+			src: []domain.StreamEvent{
+				{Content: "回"},
+			},
+			after: func(evt domain.StreamEvent) domain.StreamEvent {
+				return evt
+			},
+			wantEvts: []domain.StreamEvent{
+				{Content: "回"},
+				{Err: ErrStreamInterrupted},
+			},
+			wantCalls: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := make(chan domain.StreamEvent, len(tc.src))
+			for _, evt := range tc.src {
+				src <- evt
+			}
+			close(src)
+			calls := 0
+			dst := StreamAfter(src, func(evt domain.StreamEvent) domain.StreamEvent {
+				calls++
+				return tc.after(evt)
+			})
+			var evts []domain.StreamEvent
+			for evt := range dst {
+				evts = append(evts, evt)
+			}
+			assert.Equal(t, tc.wantEvts, evts)
+			assert.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
@@ -10,6 +10,9 @@ import (
 //go:generate mockgen -source=./llm.go -destination=../../../mocks/llm.mock.go -package=aimocks -typed=true Service
 type Service interface {
 	Invoke(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error)
+	// StreamInvoke 流式调用，最后一个事件里面有完整的响应
+	// 调用者必须把返回的 channel 读完
+	StreamInvoke(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error)
 }
 
 type llmService struct {
@@ -26,3 +29,7 @@ func NewLLMService(facade *biz.FacadeHandler) Service {
 func (g *llmService) Invoke(ctx context.Context, req domain.LLMRequest) (domain.LLMResponse, error) {
 	return g.handler.Handle(ctx, req)
 }
+
+func (g *llmService) StreamInvoke(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	return g.handler.StreamHandle(ctx, req)
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/member"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// ErrQuotaExceeded 超过了使用配额，具体是哪一种可以用 errors.Is 判断
+var ErrQuotaExceeded = errors.New("超过使用配额")
+
+var (
+	ErrCallsPerMinuteExceeded = repository.ErrCallsPerMinuteExceeded
+	ErrCallsPerDayExceeded    = repository.ErrCallsPerDayExceeded
+	ErrTokensPerDayExceeded   = repository.ErrTokensPerDayExceeded
+)
+
+//go:generate mockgen -source=./service.go -destination=../../../mocks/quota.mock.go -package=aimocks -mock_names=Service=MockQuotaService -typed=true Service
+type Service interface {
+	// Acquire 调用之前检查配额，超过了返回 ErrQuotaExceeded
+	Acquire(ctx context.Context, uid int64, biz string) error
+	// Release 调用失败，退回调用次数
+	Release(ctx context.Context, uid int64, biz string)
+	// Consume 调用成功，记录使用的 token 数
+	Consume(ctx context.Context, uid int64, biz string, tokens int64)
+	// Usage 用户在 biz 上的使用情况，用于前端展示剩余次数
+	Usage(ctx context.Context, uid int64, biz string) (domain.QuotaUsage, error)
+}
+
+// Limits 会员和非会员的配额
+type Limits struct {
+	Member domain.QuotaLimit
+	Normal domain.QuotaLimit
+}
+
+// Config 配额配置，Bizs 里面没有配置的业务使用 Default
+type Config struct {
+	Default Limits
+	Bizs    map[string]Limits
+}
+
+type service struct {
+	repo      repository.QuotaRepository
+	memberSvc member.Service
+	cfg       Config
+	logger    *elog.Component
+}
+
+func NewService(repo repository.QuotaRepository, memberSvc member.Service, cfg Config) Service {
+	return &service{
+		repo:      repo,
+		memberSvc: memberSvc,
+		cfg:       cfg,
+		logger:    elog.DefaultLogger,
+	}
+}
+
+func (s *service) Acquire(ctx context.Context, uid int64, biz string) error {
+	limit := s.limit(ctx, uid, biz)
+	if limit == (domain.QuotaLimit{}) {
+		return nil
+	}
+	err := s.repo.Acquire(ctx, uid, biz, limit)
+	switch {
+	case errors.Is(err, ErrCallsPerMinuteExceeded),
+		errors.Is(err, ErrCallsPerDayExceeded),
+		errors.Is(err, ErrTokensPerDayExceeded):
+		return fmt.Errorf("%w, 用户 %d, biz %s: %w", ErrQuotaExceeded, uid, biz, err)
+	default:
+		return err
+	}
+}
+
+func (s *service) Release(ctx context.Context, uid int64, biz string) {
+	if s.limit(ctx, uid, biz) == (domain.QuotaLimit{}) {
+		return
+	}
+	err := s.repo.Release(ctx, uid, biz)
+	if err != nil {
+		s.logger.Error("退回调用次数失败", elog.FieldErr(err),
+			elog.Int64("uid", uid), elog.String("biz", biz))
+	}
+}
+
+func (s *service) Consume(ctx context.Context, uid int64, biz string, tokens int64) {
+	if tokens <= 0 || s.limit(ctx, uid, biz).TokensPerDay <= 0 {
+		return
+	}
+	err := s.repo.IncrTokens(ctx, uid, biz, tokens)
+	if err != nil {
+		// 少记了一点 token 问题不大，不影响这一次的调用
+		s.logger.Error("记录 token 使用量失败", elog.FieldErr(err),
+			elog.Int64("uid", uid), elog.String("biz", biz))
+	}
+}
+
+func (s *service) Usage(ctx context.Context, uid int64, biz string) (domain.QuotaUsage, error) {
+	usage, err := s.repo.Usage(ctx, uid, biz)
+	if err != nil {
+		return domain.QuotaUsage{}, err
+	}
+	usage.Limit = s.limit(ctx, uid, biz)
+	return usage, nil
+}
+
+// limit 根据是否是会员选择配额
+func (s *service) limit(ctx context.Context, uid int64, biz string) domain.QuotaLimit {
+	limits, ok := s.cfg.Bizs[biz]
+	if !ok {
+		limits = s.cfg.Default
+	}
+	if limits.Member == limits.Normal {
+		// 一样的配额就没必要查询会员了
+		return limits.Normal
+	}
+	info, err := s.memberSvc.GetMembershipInfo(ctx, uid)
+	if err != nil {
+		// 查询失败就按照非会员处理
+		s.logger.Warn("查询会员信息失败", elog.FieldErr(err), elog.Int64("uid", uid))
+		return limits.Normal
+	}
+	if info.EndAt > time.Now().UnixMilli() {
+		return limits.Member
+	}
+	return limits.Normal
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/member"
+	membermocks "github.com/ecodeclub/webook/internal/member/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Acquire(t *testing.T) {
+	memberLimit := domain.QuotaLimit{CallsPerMinute: 10, CallsPerDay: 100}
+	normalLimit := domain.QuotaLimit{CallsPerMinute: 1, CallsPerDay: 10}
+	cfg := Config{
+		Default: Limits{Member: memberLimit, Normal: normalLimit},
+		Bizs: map[string]Limits{
+			"same": {Member: normalLimit, Normal: normalLimit},
+			"free": {},
+		},
+	}
+	testCases := []struct {
+		name    string
+		biz     string
+		mock    func(ctrl *gomock.Controller) member.Service
+		repoErr error
+
+		wantLimit domain.QuotaLimit
+		wantErr   error
+	}{
+		{
+			name: "会员",
+			biz:  "test",
+			mock: func(ctrl *gomock.Controller) member.Service {
+				svc := membermocks.NewMockService(ctrl)
+				svc.EXPECT().GetMembershipInfo(gomock.Any(), int64(1)).
+					Return(member.Member{EndAt: time.Now().Add(time.Hour).UnixMilli()}, nil)
+				return svc
+			},
+			wantLimit: memberLimit,
+		},
+		{
+			name: "会员已过期",
+			biz:  "test",
+			mock: func(ctrl *gomock.Controller) member.Service {
+				svc := membermocks.NewMockService(ctrl)
+				svc.EXPECT().GetMembershipInfo(gomock.Any(), int64(1)).
+					Return(member.Member{EndAt: time.Now().Add(-time.Hour).UnixMilli()}, nil)
+				return svc
+			},
+			wantLimit: normalLimit,
+		},
+		{
+			name: "查询会员失败，按照非会员处理",
+			biz:  "test",
+			mock: func(ctrl *gomock.Controller) member.Service {
+				svc := membermocks.NewMockService(ctrl)
+				svc.EXPECT().GetMembershipInfo(gomock.Any(), int64(1)).
+					Return(member.Member{}, errors.New("mock error"))
+				return svc
+			},
+			wantLimit: normalLimit,
+		},
+		{
+			name: "配额一样，不查询会员",
+			biz:  "same",
+			mock: func(ctrl *gomock.Controller) member.Service {
+				return membermocks.NewMockService(ctrl)
+			},
+			wantLimit: normalLimit,
+		},
+		{
+			name: "不限制",
+			biz:  "free",
+			mock: func(ctrl *gomock.Controller) member.Service {
+				return membermocks.NewMockService(ctrl)
+			},
+		},
+		{
+			name: "超过配额",
+			biz:  "same",
+			mock: func(ctrl *gomock.Controller) member.Service {
+				return membermocks.NewMockService(ctrl)
+			},
+			repoErr:   fmt.Errorf("%w, 上限 10", repository.ErrCallsPerDayExceeded),
+			wantLimit: normalLimit,
+			wantErr:   ErrQuotaExceeded,
+		},
+		{
+			name: "其它错误",
+			biz:  "same",
+			mock: func(ctrl *gomock.Controller) member.Service {
+				return membermocks.NewMockService(ctrl)
+			},
+			repoErr:   errors.New("mock error"),
+			wantLimit: normalLimit,
+			wantErr:   errors.New("mock error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := &fakeQuotaRepo{err: tc.repoErr}
+			svc := NewService(repo, tc.mock(ctrl), cfg)
+			err := svc.Acquire(context.Background(), 1, tc.biz)
+			switch {
+			case tc.wantErr == nil:
+				assert.NoError(t, err)
+			case errors.Is(tc.wantErr, ErrQuotaExceeded):
+				assert.ErrorIs(t, err, ErrQuotaExceeded)
+				assert.ErrorIs(t, err, repository.ErrCallsPerDayExceeded)
+			default:
+				assert.Equal(t, tc.wantErr.Error(), err.Error())
+			}
+			assert.Equal(t, tc.wantLimit, repo.limit)
+		})
+	}
+}
+
+type fakeQuotaRepo struct {
+	repository.QuotaRepository
+	limit domain.QuotaLimit
+	err   error
+}
+
+func (f *fakeQuotaRepo) Acquire(ctx context.Context, uid int64, biz string, limit domain.QuotaLimit) error {
+	f.limit = limit
+	return f.err
+}
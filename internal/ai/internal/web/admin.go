@@ -0,0 +1,194 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ginx"
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/admin"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	svc admin.Service
+}
+
+func NewAdminHandler(svc admin.Service) *AdminHandler {
+	return &AdminHandler{svc: svc}
+}
+
+func (h *AdminHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/ai")
+	g.POST("/config/save", ginx.B[BizConfig](h.SaveConfig))
+	g.POST("/config/detail", ginx.B[IdReq](h.ConfigDetail))
+	g.POST("/config/list", ginx.B[Page](h.ListConfigs))
+	g.POST("/config/delete", ginx.B[IdReq](h.DeleteConfig))
+
+	g.POST("/prompt/list", ginx.B[BizReq](h.ListPromptVersions))
+	g.POST("/prompt/save", ginx.B[PromptVersion](h.CreatePromptVersion))
+	// 切换版本，回滚也是用这个接口
+	g.POST("/prompt/activate", ginx.B[ActivatePromptVersionReq](h.ActivatePromptVersion))
+	g.POST("/prompt/experiment", ginx.B[SetPromptExperimentsReq](h.SetPromptExperiments))
+
+	g.POST("/record/list", ginx.B[ListReq](h.ListRecords))
+	g.POST("/credit/list", ginx.B[ListReq](h.ListCredits))
+	g.POST("/usage/daily", ginx.B[DailyUsageReq](h.DailyUsage))
+}
+
+func (h *AdminHandler) SaveConfig(ctx *ginx.Context, req BizConfig) (ginx.Result, error) {
+	id, err := h.svc.SaveConfig(ctx, req.toDomain())
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: id,
+	}, nil
+}
+
+func (h *AdminHandler) ConfigDetail(ctx *ginx.Context, req IdReq) (ginx.Result, error) {
+	cfg, err := h.svc.ConfigDetail(ctx, req.Id)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: newBizConfig(cfg),
+	}, nil
+}
+
+func (h *AdminHandler) ListConfigs(ctx *ginx.Context, req Page) (ginx.Result, error) {
+	list, cnt, err := h.svc.ListConfigs(ctx, req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: BizConfigList{
+			Total: cnt,
+			Configs: slice.Map(list, func(idx int, src domain.BizConfig) BizConfig {
+				return newBizConfig(src)
+			}),
+		},
+	}, nil
+}
+
+func (h *AdminHandler) DeleteConfig(ctx *ginx.Context, req IdReq) (ginx.Result, error) {
+	err := h.svc.DeleteConfig(ctx, req.Id)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *AdminHandler) ListPromptVersions(ctx *ginx.Context, req BizReq) (ginx.Result, error) {
+	list, err := h.svc.ListPromptVersions(ctx, req.Biz)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: slice.Map(list, func(idx int, src domain.PromptVersion) PromptVersion {
+			return newPromptVersion(src)
+		}),
+	}, nil
+}
+
+func (h *AdminHandler) CreatePromptVersion(ctx *ginx.Context, req PromptVersion) (ginx.Result, error) {
+	version, err := h.svc.CreatePromptVersion(ctx, domain.PromptVersion{
+		Biz:            req.Biz,
+		PromptTemplate: req.PromptTemplate,
+		Description:    req.Description,
+	})
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		// 返回新的版本号
+		Data: version,
+	}, nil
+}
+
+func (h *AdminHandler) ActivatePromptVersion(ctx *ginx.Context, req ActivatePromptVersionReq) (ginx.Result, error) {
+	err := h.svc.ActivatePromptVersion(ctx, req.Biz, req.Version)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *AdminHandler) SetPromptExperiments(ctx *ginx.Context, req SetPromptExperimentsReq) (ginx.Result, error) {
+	err := h.svc.SetPromptExperiments(ctx, req.Biz,
+		slice.Map(req.Experiments, func(idx int, src PromptExperiment) domain.PromptExperiment {
+			return domain.PromptExperiment{
+				Version: src.Version,
+				Weight:  src.Weight,
+			}
+		}))
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *AdminHandler) ListRecords(ctx *ginx.Context, req ListReq) (ginx.Result, error) {
+	list, cnt, err := h.svc.ListRecords(ctx, req.Filter.toDomain(), req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: LLMRecordList{
+			Total: cnt,
+			Records: slice.Map(list, func(idx int, src domain.LLMRecord) LLMRecord {
+				return newLLMRecord(src)
+			}),
+		},
+	}, nil
+}
+
+func (h *AdminHandler) ListCredits(ctx *ginx.Context, req ListReq) (ginx.Result, error) {
+	list, cnt, err := h.svc.ListCredits(ctx, req.Filter.toDomain(), req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: LLMCreditList{
+			Total: cnt,
+			Credits: slice.Map(list, func(idx int, src domain.LLMCredit) LLMCredit {
+				return newLLMCredit(src)
+			}),
+		},
+	}, nil
+}
+
+func (h *AdminHandler) DailyUsage(ctx *ginx.Context, req DailyUsageReq) (ginx.Result, error) {
+	dimension := domain.UsageDimension(req.Dimension)
+	if !dimension.Valid() {
+		return invalidDimensionResult, nil
+	}
+	list, err := h.svc.DailyUsage(ctx, req.Filter.toDomain(), dimension, req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: slice.Map(list, func(idx int, src domain.DailyUsage) DailyUsage {
+			return DailyUsage{
+				Date:   src.Date,
+				Biz:    src.Biz,
+				Uid:    src.Uid,
+				Calls:  src.Calls,
+				Tokens: src.Tokens,
+				Amount: src.Amount,
+			}
+		}),
+	}, nil
+}
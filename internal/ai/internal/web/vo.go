@@ -0,0 +1,276 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/ai/internal/domain"
+)
+
+type Page struct {
+	Offset int `json:"offset,omitempty"`
+	Limit  int `json:"limit,omitempty"`
+}
+
+type IdReq struct {
+	Id int64 `json:"id"`
+}
+
+type BizReq struct {
+	Biz string `json:"biz"`
+}
+
+type BizConfig struct {
+	Id             int64            `json:"id,omitempty"`
+	Biz            string           `json:"biz,omitempty"`
+	MaxInput       int              `json:"maxInput,omitempty"`
+	PromptTemplate string           `json:"promptTemplate,omitempty"`
+	KnowledgeId    string           `json:"knowledgeId,omitempty"`
+	Platform       string           `json:"platform,omitempty"`
+	Model          string           `json:"model,omitempty"`
+	Fallbacks      []PlatformConfig `json:"fallbacks,omitempty"`
+	// 响应缓存的过期时间，单位秒
+	CacheTTL     int64 `json:"cacheTTL,omitempty"`
+	DisableCache bool  `json:"disableCache,omitempty"`
+	// 提示词版本和实验只能通过专门的接口修改，这里只用来展示
+	ActiveVersion int64              `json:"activeVersion,omitempty"`
+	Experiments   []PromptExperiment `json:"experiments,omitempty"`
+	// 用户输入的安全检查规则，和全局的规则一起使用
+	Guard GuardRules `json:"guard"`
+	Ctime int64      `json:"ctime,omitempty"`
+	Utime int64      `json:"utime,omitempty"`
+}
+
+func (c BizConfig) toDomain() domain.BizConfig {
+	return domain.BizConfig{
+		Id:             c.Id,
+		Biz:            c.Biz,
+		MaxInput:       c.MaxInput,
+		PromptTemplate: c.PromptTemplate,
+		KnowledgeId:    c.KnowledgeId,
+		Platform:       c.Platform,
+		Model:          c.Model,
+		Fallbacks: slice.Map(c.Fallbacks, func(idx int, src PlatformConfig) domain.PlatformConfig {
+			return domain.PlatformConfig{
+				Platform: src.Platform,
+				Model:    src.Model,
+			}
+		}),
+		CacheTTL:     time.Duration(c.CacheTTL) * time.Second,
+		DisableCache: c.DisableCache,
+		Guard: domain.GuardRules{
+			Disabled: c.Guard.Disabled,
+			Keywords: c.Guard.Keywords,
+			Patterns: c.Guard.Patterns,
+		},
+	}
+}
+
+func newBizConfig(c domain.BizConfig) BizConfig {
+	return BizConfig{
+		Id:             c.Id,
+		Biz:            c.Biz,
+		MaxInput:       c.MaxInput,
+		PromptTemplate: c.PromptTemplate,
+		KnowledgeId:    c.KnowledgeId,
+		Platform:       c.Platform,
+		Model:          c.Model,
+		Fallbacks: slice.Map(c.Fallbacks, func(idx int, src domain.PlatformConfig) PlatformConfig {
+			return PlatformConfig{
+				Platform: src.Platform,
+				Model:    src.Model,
+			}
+		}),
+		CacheTTL:      int64(c.CacheTTL / time.Second),
+		DisableCache:  c.DisableCache,
+		ActiveVersion: c.ActiveVersion,
+		Experiments: slice.Map(c.Experiments, func(idx int, src domain.PromptExperiment) PromptExperiment {
+			return PromptExperiment{
+				Version: src.Version,
+				Weight:  src.Weight,
+			}
+		}),
+		Guard: GuardRules{
+			Disabled: c.Guard.Disabled,
+			Keywords: c.Guard.Keywords,
+			Patterns: c.Guard.Patterns,
+		},
+		Ctime: c.Ctime,
+		Utime: c.Utime,
+	}
+}
+
+type PlatformConfig struct {
+	Platform string `json:"platform"`
+	Model    string `json:"model"`
+}
+
+type GuardRules struct {
+	Disabled bool     `json:"disabled,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+type BizConfigList struct {
+	Total   int64       `json:"total"`
+	Configs []BizConfig `json:"configs"`
+}
+
+type PromptVersion struct {
+	Biz            string `json:"biz,omitempty"`
+	Version        int64  `json:"version,omitempty"`
+	PromptTemplate string `json:"promptTemplate,omitempty"`
+	Description    string `json:"description,omitempty"`
+	Ctime          int64  `json:"ctime,omitempty"`
+}
+
+func newPromptVersion(v domain.PromptVersion) PromptVersion {
+	return PromptVersion{
+		Biz:            v.Biz,
+		Version:        v.Version,
+		PromptTemplate: v.PromptTemplate,
+		Description:    v.Description,
+		Ctime:          v.Ctime,
+	}
+}
+
+type PromptExperiment struct {
+	Version int64 `json:"version"`
+	Weight  int64 `json:"weight"`
+}
+
+type ActivatePromptVersionReq struct {
+	Biz     string `json:"biz"`
+	Version int64  `json:"version"`
+}
+
+type SetPromptExperimentsReq struct {
+	Biz string `json:"biz"`
+	// 为空表示结束实验
+	Experiments []PromptExperiment `json:"experiments"`
+}
+
+// Filter 调用记录和扣费记录的过滤条件，零值表示不过滤
+type Filter struct {
+	Uid    int64  `json:"uid,omitempty"`
+	Biz    string `json:"biz,omitempty"`
+	Status []int  `json:"status,omitempty"`
+	// 毫秒，左闭右开
+	StartTime int64 `json:"startTime,omitempty"`
+	EndTime   int64 `json:"endTime,omitempty"`
+}
+
+func (f Filter) toDomain() domain.LLMFilter {
+	return domain.LLMFilter{
+		Uid:       f.Uid,
+		Biz:       f.Biz,
+		Status:    f.Status,
+		StartTime: f.StartTime,
+		EndTime:   f.EndTime,
+	}
+}
+
+type ListReq struct {
+	Filter
+	Page
+}
+
+type LLMRecord struct {
+	Id             int64    `json:"id"`
+	Tid            string   `json:"tid"`
+	Uid            int64    `json:"uid"`
+	Biz            string   `json:"biz"`
+	Tokens         int64    `json:"tokens"`
+	Amount         int64    `json:"amount"`
+	Input          []string `json:"input"`
+	Status         uint8    `json:"status"`
+	KnowledgeId    string   `json:"knowledgeId"`
+	PromptTemplate string   `json:"promptTemplate"`
+	PromptVersion  int64    `json:"promptVersion"`
+	Answer         string   `json:"answer"`
+	Cached         bool     `json:"cached"`
+	Ctime          int64    `json:"ctime"`
+}
+
+func newLLMRecord(r domain.LLMRecord) LLMRecord {
+	return LLMRecord{
+		Id:             r.Id,
+		Tid:            r.Tid,
+		Uid:            r.Uid,
+		Biz:            r.Biz,
+		Tokens:         r.Tokens,
+		Amount:         r.Amount,
+		Input:          r.Input,
+		Status:         r.Status.ToUint8(),
+		KnowledgeId:    r.KnowledgeId,
+		PromptTemplate: r.PromptTemplate,
+		PromptVersion:  r.PromptVersion,
+		Answer:         r.Answer,
+		Cached:         r.Cached,
+		Ctime:          r.Ctime,
+	}
+}
+
+type LLMRecordList struct {
+	Total   int64       `json:"total"`
+	Records []LLMRecord `json:"records"`
+}
+
+type LLMCredit struct {
+	Id     int64  `json:"id"`
+	Tid    string `json:"tid"`
+	Uid    int64  `json:"uid"`
+	Biz    string `json:"biz"`
+	Amount int64  `json:"amount"`
+	Status uint8  `json:"status"`
+	Ctime  int64  `json:"ctime"`
+	Utime  int64  `json:"utime"`
+}
+
+func newLLMCredit(c domain.LLMCredit) LLMCredit {
+	return LLMCredit{
+		Id:     c.Id,
+		Tid:    c.Tid,
+		Uid:    c.Uid,
+		Biz:    c.Biz,
+		Amount: c.Amount,
+		Status: c.Status.ToUint8(),
+		Ctime:  c.Ctime,
+		Utime:  c.Utime,
+	}
+}
+
+type LLMCreditList struct {
+	Total   int64       `json:"total"`
+	Credits []LLMCredit `json:"credits"`
+}
+
+type DailyUsageReq struct {
+	Filter
+	Page
+	// biz 按照业务统计，uid 按照用户统计
+	Dimension string `json:"dimension"`
+}
+
+type DailyUsage struct {
+	Date   string `json:"date"`
+	Biz    string `json:"biz,omitempty"`
+	Uid    int64  `json:"uid,omitempty"`
+	Calls  int64  `json:"calls"`
+	Tokens int64  `json:"tokens"`
+	Amount int64  `json:"amount"`
+}
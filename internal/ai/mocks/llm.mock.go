@@ -77,3 +77,42 @@ func (c *ServiceInvokeCall) DoAndReturn(f func(context.Context, domain.LLMReques
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// StreamInvoke mocks base method.
+func (m *MockService) StreamInvoke(ctx context.Context, req domain.LLMRequest) (<-chan domain.StreamEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamInvoke", ctx, req)
+	ret0, _ := ret[0].(<-chan domain.StreamEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamInvoke indicates an expected call of StreamInvoke.
+func (mr *MockServiceMockRecorder) StreamInvoke(ctx, req any) *ServiceStreamInvokeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamInvoke", reflect.TypeOf((*MockService)(nil).StreamInvoke), ctx, req)
+	return &ServiceStreamInvokeCall{Call: call}
+}
+
+// ServiceStreamInvokeCall wrap *gomock.Call
+type ServiceStreamInvokeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceStreamInvokeCall) Return(arg0 <-chan domain.StreamEvent, arg1 error) *ServiceStreamInvokeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceStreamInvokeCall) Do(f func(context.Context, domain.LLMRequest) (<-chan domain.StreamEvent, error)) *ServiceStreamInvokeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceStreamInvokeCall) DoAndReturn(f func(context.Context, domain.LLMRequest) (<-chan domain.StreamEvent, error)) *ServiceStreamInvokeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -0,0 +1,189 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./service.go -destination=../../../mocks/quota.mock.go -package=aimocks -mock_names=Service=MockQuotaService -typed=true Service
+//
+// Package aimocks is a generated GoMock package.
+package aimocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/ecodeclub/webook/internal/ai/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockQuotaService is a mock of Service interface.
+type MockQuotaService struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaServiceMockRecorder
+}
+
+// MockQuotaServiceMockRecorder is the mock recorder for MockQuotaService.
+type MockQuotaServiceMockRecorder struct {
+	mock *MockQuotaService
+}
+
+// NewMockQuotaService creates a new mock instance.
+func NewMockQuotaService(ctrl *gomock.Controller) *MockQuotaService {
+	mock := &MockQuotaService{ctrl: ctrl}
+	mock.recorder = &MockQuotaServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotaService) EXPECT() *MockQuotaServiceMockRecorder {
+	return m.recorder
+}
+
+// Acquire mocks base method.
+func (m *MockQuotaService) Acquire(ctx context.Context, uid int64, biz string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acquire", ctx, uid, biz)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Acquire indicates an expected call of Acquire.
+func (mr *MockQuotaServiceMockRecorder) Acquire(ctx, uid, biz any) *ServiceAcquireCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acquire", reflect.TypeOf((*MockQuotaService)(nil).Acquire), ctx, uid, biz)
+	return &ServiceAcquireCall{Call: call}
+}
+
+// ServiceAcquireCall wrap *gomock.Call
+type ServiceAcquireCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceAcquireCall) Return(arg0 error) *ServiceAcquireCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceAcquireCall) Do(f func(context.Context, int64, string) error) *ServiceAcquireCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceAcquireCall) DoAndReturn(f func(context.Context, int64, string) error) *ServiceAcquireCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Consume mocks base method.
+func (m *MockQuotaService) Consume(ctx context.Context, uid int64, biz string, tokens int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Consume", ctx, uid, biz, tokens)
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockQuotaServiceMockRecorder) Consume(ctx, uid, biz, tokens any) *ServiceConsumeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockQuotaService)(nil).Consume), ctx, uid, biz, tokens)
+	return &ServiceConsumeCall{Call: call}
+}
+
+// ServiceConsumeCall wrap *gomock.Call
+type ServiceConsumeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceConsumeCall) Return() *ServiceConsumeCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceConsumeCall) Do(f func(context.Context, int64, string, int64)) *ServiceConsumeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceConsumeCall) DoAndReturn(f func(context.Context, int64, string, int64)) *ServiceConsumeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Release mocks base method.
+func (m *MockQuotaService) Release(ctx context.Context, uid int64, biz string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Release", ctx, uid, biz)
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockQuotaServiceMockRecorder) Release(ctx, uid, biz any) *ServiceReleaseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockQuotaService)(nil).Release), ctx, uid, biz)
+	return &ServiceReleaseCall{Call: call}
+}
+
+// ServiceReleaseCall wrap *gomock.Call
+type ServiceReleaseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceReleaseCall) Return() *ServiceReleaseCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceReleaseCall) Do(f func(context.Context, int64, string)) *ServiceReleaseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceReleaseCall) DoAndReturn(f func(context.Context, int64, string)) *ServiceReleaseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Usage mocks base method.
+func (m *MockQuotaService) Usage(ctx context.Context, uid int64, biz string) (domain.QuotaUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", ctx, uid, biz)
+	ret0, _ := ret[0].(domain.QuotaUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage.
+func (mr *MockQuotaServiceMockRecorder) Usage(ctx, uid, biz any) *ServiceUsageCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockQuotaService)(nil).Usage), ctx, uid, biz)
+	return &ServiceUsageCall{Call: call}
+}
+
+// ServiceUsageCall wrap *gomock.Call
+type ServiceUsageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceUsageCall) Return(arg0 domain.QuotaUsage, arg1 error) *ServiceUsageCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceUsageCall) Do(f func(context.Context, int64, string) (domain.QuotaUsage, error)) *ServiceUsageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceUsageCall) DoAndReturn(f func(context.Context, int64, string) (domain.QuotaUsage, error)) *ServiceUsageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
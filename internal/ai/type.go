@@ -3,8 +3,15 @@ package ai
 import (
 	"github.com/ecodeclub/webook/internal/ai/internal/domain"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/quota"
+	"github.com/ecodeclub/webook/internal/ai/internal/web"
 )
 
 type LLMRequest = domain.LLMRequest
 type LLMResponse = domain.LLMResponse
+type StreamEvent = domain.StreamEvent
 type LLMService = llm.Service
+type QuotaService = quota.Service
+type QuotaLimit = domain.QuotaLimit
+type QuotaUsage = domain.QuotaUsage
+type AdminHandler = web.AdminHandler
@@ -5,26 +5,42 @@ package ai
 import (
 	"sync"
 
+	"github.com/ecodeclub/ecache"
+
+	"github.com/ecodeclub/webook/internal/ai/internal/service/admin"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/config"
-	aicredit "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/credit"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/log"
+	aiquota "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/quota"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/record"
 
 	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository/cache"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/ai/internal/web"
 	"github.com/ecodeclub/webook/internal/credit"
+	"github.com/ecodeclub/webook/internal/member"
 	"github.com/ego-component/egorm"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
-func InitModule(db *egorm.Component, creditSvc *credit.Module) (*Module, error) {
+func InitModule(db *egorm.Component,
+	cmd redis.Cmdable,
+	ec ecache.Cache,
+	creditSvc *credit.Module,
+	memberSvc *member.Module) (*Module, error) {
 	wire.Build(
 		llm.NewLLMService,
 		repository.NewLLMLogRepo,
 		repository.NewLLMCreditLogRepo,
 		repository.NewCachedConfigRepository,
+		repository.NewQuotaRepository,
+		cache.NewQuotaRedisCache,
+		InitQuotaService,
+		repository.NewResponseRepository,
+		cache.NewResponseECache,
 
 		InitLLMCreditLogDAO,
 		dao.NewGORMLLMLogDAO,
@@ -33,14 +49,25 @@ func InitModule(db *egorm.Component, creditSvc *credit.Module) (*Module, error)
 		config.NewBuilder,
 		log.NewHandler,
 		record.NewHandler,
-		aicredit.NewHandlerBuilder,
+		aiquota.NewHandlerBuilder,
+		InitResponseCacheHandlerBuilder,
+		InitBudgetHandlerBuilder,
+		InitGuardHandlerBuilder,
+		repository.NewBudgetRepository,
+		cache.NewBudgetRedisCache,
+		InitCreditHandlerBuilder,
 
 		InitHandlerFacade,
 		InitCommonHandlers,
-		InitZhipu,
+		InitAdminHandlers,
+		InitPlatform,
+
+		admin.NewService,
+		web.NewAdminHandler,
 
 		wire.Struct(new(Module), "*"),
 		wire.FieldsOf(new(*credit.Module), "Svc"),
+		wire.FieldsOf(new(*member.Module), "Svc"),
 	)
 	return new(Module), nil
 }
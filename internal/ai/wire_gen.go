@@ -9,38 +9,61 @@ package ai
 import (
 	"sync"
 
+	"github.com/ecodeclub/ecache"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository"
+	"github.com/ecodeclub/webook/internal/ai/internal/repository/cache"
 	"github.com/ecodeclub/webook/internal/ai/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/admin"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/config"
-	credit2 "github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/credit"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/log"
+	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/quota"
 	"github.com/ecodeclub/webook/internal/ai/internal/service/llm/handler/record"
+	"github.com/ecodeclub/webook/internal/ai/internal/web"
 	"github.com/ecodeclub/webook/internal/credit"
+	"github.com/ecodeclub/webook/internal/member"
 	"github.com/ego-component/egorm"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
 // Injectors from wire.go:
 
-func InitModule(db *gorm.DB, creditSvc *credit.Module) (*Module, error) {
+func InitModule(db *gorm.DB, cmd redis.Cmdable, ec ecache.Cache, creditSvc *credit.Module, memberSvc *member.Module) (*Module, error) {
 	handlerBuilder := log.NewHandler()
 	configDAO := dao.NewGORMConfigDAO(db)
 	configRepository := repository.NewCachedConfigRepository(configDAO)
 	configHandlerBuilder := config.NewBuilder(configRepository)
-	service := creditSvc.Svc
+	quotaCache := cache.NewQuotaRedisCache(cmd)
+	quotaRepository := repository.NewQuotaRepository(quotaCache)
+	service := memberSvc.Svc
+	quotaService := InitQuotaService(quotaRepository, service)
+	quotaHandlerBuilder := quota.NewHandlerBuilder(quotaService)
+	serviceService := creditSvc.Svc
 	llmCreditDAO := InitLLMCreditLogDAO(db)
 	llmCreditLogRepo := repository.NewLLMCreditLogRepo(llmCreditDAO)
-	creditHandlerBuilder := credit2.NewHandlerBuilder(service, llmCreditLogRepo)
+	creditHandlerBuilder := InitCreditHandlerBuilder(serviceService, llmCreditLogRepo)
 	llmRecordDAO := dao.NewGORMLLMLogDAO(db)
 	llmLogRepo := repository.NewLLMLogRepo(llmRecordDAO)
 	recordHandlerBuilder := record.NewHandler(llmLogRepo)
-	v := InitCommonHandlers(handlerBuilder, configHandlerBuilder, creditHandlerBuilder, recordHandlerBuilder)
-	handler := InitZhipu()
-	facadeHandler := InitHandlerFacade(v, handler)
+	v := InitCommonHandlers(handlerBuilder, configHandlerBuilder, quotaHandlerBuilder, creditHandlerBuilder, recordHandlerBuilder)
+	budgetCache := cache.NewBudgetRedisCache(cmd)
+	budgetRepository := repository.NewBudgetRepository(budgetCache)
+	budgetHandlerBuilder := InitBudgetHandlerBuilder(budgetRepository)
+	guardHandlerBuilder := InitGuardHandlerBuilder()
+	adminHandlers := InitAdminHandlers(handlerBuilder, configHandlerBuilder, budgetHandlerBuilder, recordHandlerBuilder)
+	responseCache := cache.NewResponseECache(ec)
+	responseRepository := repository.NewResponseRepository(responseCache)
+	cacheHandlerBuilder := InitResponseCacheHandlerBuilder(responseRepository)
+	handler := InitPlatform()
+	facadeHandler := InitHandlerFacade(v, adminHandlers, guardHandlerBuilder, cacheHandlerBuilder, handler)
 	llmService := llm.NewLLMService(facadeHandler)
+	adminService := admin.NewService(configRepository, llmLogRepo, llmCreditLogRepo)
+	adminHandler := web.NewAdminHandler(adminService)
 	module := &Module{
-		Svc: llmService,
+		Svc:      llmService,
+		QuotaSvc: quotaService,
+		AdminHdl: adminHandler,
 	}
 	return module, nil
 }
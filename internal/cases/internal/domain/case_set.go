@@ -0,0 +1,46 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import "time"
+
+const BizCaseSet = "caseSet"
+
+// CaseSet 案例集，和 Case 是多对多的关系
+type CaseSet struct {
+	Id  int64
+	Uid int64
+	// 标题
+	Title string
+	// 描述
+	Description string
+
+	// 案例集可以绑定到别的业务上，例如面试项目
+	Biz   string
+	BizId int64
+
+	// 案例集中引用的案例，顺序就是展示的顺序
+	Cases []Case
+
+	Utime time.Time
+}
+
+func (set CaseSet) Cids() []int64 {
+	ids := make([]int64, 0, len(set.Cases))
+	for _, ca := range set.Cases {
+		ids = append(ids, ca.Id)
+	}
+	return ids
+}
@@ -41,4 +41,6 @@ const (
 	UnPublishedStatus CaseStatus = 1
 	// PublishedStatus 发布
 	PublishedStatus CaseStatus = 2
+	// DeletedStatus 已删除，在回收站里面，可以恢复
+	DeletedStatus CaseStatus = 3
 )
@@ -2,6 +2,14 @@ package errs
 
 var (
 	SystemError = ErrorCode{Code: 505001, Msg: "系统错误"}
+	// InsufficientCredit 和题目的测试一样，积分不够了
+	InsufficientCredit = ErrorCode{Code: 505002, Msg: "积分不足"}
+	// QuotaExceeded 超过了使用次数限制
+	QuotaExceeded = ErrorCode{Code: 505003, Msg: "使用次数超过限制"}
+	// InputRejected 用户的输入没有通过 AI 的安全检查
+	InputRejected = ErrorCode{Code: 505004, Msg: "输入包含不允许的内容"}
+	// InvalidExamineResult AI 返回的测试结果无法解析，用户可以重新测试
+	InvalidExamineResult = ErrorCode{Code: 505005, Msg: "AI 评价失败，请重新测试"}
 )
 
 type ErrorCode struct {
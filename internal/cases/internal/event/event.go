@@ -20,10 +20,15 @@ import (
 	"github.com/ecodeclub/webook/internal/cases/internal/domain"
 )
 
+// syncOpDelete 让搜索删除对应的文档
+const syncOpDelete = "delete"
+
 type CaseEvent struct {
 	Biz   string `json:"biz"`
 	BizID int    `json:"bizID"`
 	Data  string `json:"data"`
+	// Op 为空的时候写入或者更新文档
+	Op string `json:"op,omitempty"`
 }
 type Case struct {
 	Id           int64    `json:"id"`
@@ -51,6 +56,15 @@ func NewCaseEvent(ca domain.Case) CaseEvent {
 	}
 }
 
+// NewSyncDeleteEvent 内容下线或者删除之后，从搜索里面移除
+func NewSyncDeleteEvent(biz string, id int64) CaseEvent {
+	return CaseEvent{
+		Biz:   biz,
+		BizID: int(id),
+		Op:    syncOpDelete,
+	}
+}
+
 func newCase(ca domain.Case) Case {
 	return Case{
 		Id:           ca.Id,
@@ -69,3 +83,32 @@ func newCase(ca domain.Case) Case {
 		Utime:        ca.Utime.UnixMilli(),
 	}
 }
+
+type CaseSet struct {
+	Id          int64   `json:"id"`
+	Uid         int64   `json:"uid"`
+	Title       string  `json:"title"`
+	Biz         string  `json:"biz"`
+	BizId       int64   `json:"bizId"`
+	Description string  `json:"description"`
+	Cases       []int64 `json:"cases"`
+	Utime       int64   `json:"utime"`
+}
+
+func NewCaseSetEvent(set domain.CaseSet) CaseEvent {
+	data, _ := json.Marshal(CaseSet{
+		Id:          set.Id,
+		Uid:         set.Uid,
+		Title:       set.Title,
+		Biz:         set.Biz,
+		BizId:       set.BizId,
+		Description: set.Description,
+		Cases:       set.Cids(),
+		Utime:       set.Utime.UnixMilli(),
+	})
+	return CaseEvent{
+		Biz:   domain.BizCaseSet,
+		BizID: int(set.Id),
+		Data:  string(data),
+	}
+}
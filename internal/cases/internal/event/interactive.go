@@ -31,7 +31,7 @@ type InteractiveEvent struct {
 	Biz   string `json:"biz,omitempty"`
 	BizId int64  `json:"bizId,omitempty"`
 	// 取值是
-	// like, collect, view 三个
+	// like, collect, view, delete 四个
 	Action string `json:"action,omitempty"`
 	Uid    int64  `json:"uid,omitempty"`
 }
@@ -43,3 +43,12 @@ func NewViewCntEvent(id int64, biz string) InteractiveEvent {
 		Action: "view",
 	}
 }
+
+// NewInteractiveDeleteEvent 内容被彻底删除之后，清理计数以及点赞、收藏明细
+func NewInteractiveDeleteEvent(id int64, biz string) InteractiveEvent {
+	return InteractiveEvent{
+		Biz:    biz,
+		BizId:  id,
+		Action: "delete",
+	}
+}
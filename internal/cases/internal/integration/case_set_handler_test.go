@@ -0,0 +1,435 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/ai"
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+	"github.com/ecodeclub/webook/internal/cases/internal/event"
+	eveMocks "github.com/ecodeclub/webook/internal/cases/internal/event/mocks"
+	"github.com/ecodeclub/webook/internal/cases/internal/integration/startup"
+	"github.com/ecodeclub/webook/internal/cases/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/cases/internal/web"
+	"github.com/ecodeclub/webook/internal/interactive"
+	intrmocks "github.com/ecodeclub/webook/internal/interactive/mocks"
+	"github.com/ecodeclub/webook/internal/test"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/ego-component/egorm"
+	"github.com/gin-gonic/gin"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/server/egin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type CaseSetHandlerTestSuite struct {
+	suite.Suite
+	server *egin.Component
+	// pubServer 对应 C 端，和后台的路由是分开部署的
+	pubServer *egin.Component
+	db        *egorm.Component
+	dao       dao.CaseSetDAO
+	caseDAO   dao.CaseDAO
+	producer  *eveMocks.MockSyncEventProducer
+}
+
+func (s *CaseSetHandlerTestSuite) SetupSuite() {
+	ctrl := gomock.NewController(s.T())
+	s.producer = eveMocks.NewMockSyncEventProducer(ctrl)
+	intrSvc := intrmocks.NewMockService(ctrl)
+	intrSvc.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, biz string, id int64, uid int64) (interactive.Interactive, error) {
+			return interactive.Interactive{
+				Biz:        biz,
+				BizId:      id,
+				ViewCnt:    int(id + 1),
+				LikeCnt:    int(id + 2),
+				CollectCnt: int(id + 3),
+				Liked:      id%2 == 1,
+				Collected:  id%2 == 0,
+			}, nil
+		})
+	module, err := startup.InitModule(s.producer, &interactive.Module{Svc: intrSvc}, &ai.Module{})
+	require.NoError(s.T(), err)
+
+	econf.Set("server", map[string]any{"contextTimeout": "1s"})
+	s.server = s.newServer()
+	module.AdminSetHdl.PrivateRoutes(s.server.Engine)
+	s.pubServer = s.newServer()
+	module.SetHdl.PrivateRoutes(s.pubServer.Engine)
+
+	s.db = testioc.InitDB()
+	err = dao.InitTables(s.db)
+	require.NoError(s.T(), err)
+	s.dao = dao.NewGORMCaseSetDAO(s.db)
+	s.caseDAO = dao.NewCaseDao(s.db)
+}
+
+func (s *CaseSetHandlerTestSuite) newServer() *egin.Component {
+	server := egin.Load("server").Build()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set("_session", session.NewMemorySession(session.Claims{
+			Uid: uid,
+			Data: map[string]string{
+				"creator":   "true",
+				"memberDDL": strconv.FormatInt(time.Now().Add(time.Hour).UnixMilli(), 10),
+			},
+		}))
+	})
+	return server
+}
+
+func (s *CaseSetHandlerTestSuite) TearDownTest() {
+	err := s.db.Exec("TRUNCATE TABLE `case_sets`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `case_set_cases`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `cases`").Error
+	require.NoError(s.T(), err)
+}
+
+func (s *CaseSetHandlerTestSuite) TestSave() {
+	testCases := []struct {
+		name   string
+		before func(t *testing.T)
+		after  func(t *testing.T)
+		req    web.CaseSet
+
+		wantCode int
+		wantResp test.Result[int64]
+	}{
+		{
+			name: "新建",
+			before: func(t *testing.T) {
+				s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, evt event.CaseEvent) error {
+						assert.Equal(t, domain.BizCaseSet, evt.Biz)
+						assert.Equal(t, 1, evt.BizID)
+						return nil
+					})
+			},
+			after: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				cs, err := s.dao.GetByID(ctx, 1)
+				require.NoError(t, err)
+				s.assertCaseSet(t, dao.CaseSet{
+					Uid:         uid,
+					Title:       "高并发案例",
+					Description: "高并发相关的案例",
+					Biz:         "skill",
+					BizId:       3,
+				}, cs)
+			},
+			req: web.CaseSet{
+				Title:       "高并发案例",
+				Description: "高并发相关的案例",
+				Biz:         "skill",
+				BizId:       3,
+			},
+			wantCode: 200,
+			wantResp: test.Result[int64]{
+				Data: 1,
+			},
+		},
+		{
+			name: "更新",
+			before: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				_, err := s.dao.Create(ctx, dao.CaseSet{
+					Id:          2,
+					Uid:         uid,
+					Title:       "老的标题",
+					Description: "老的描述",
+				})
+				require.NoError(t, err)
+				s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			after: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				cs, err := s.dao.GetByID(ctx, 2)
+				require.NoError(t, err)
+				s.assertCaseSet(t, dao.CaseSet{
+					Uid:         uid,
+					Title:       "新的标题",
+					Description: "新的描述",
+					Biz:         "project",
+					BizId:       1,
+				}, cs)
+			},
+			req: web.CaseSet{
+				Id:          2,
+				Title:       "新的标题",
+				Description: "新的描述",
+				Biz:         "project",
+				BizId:       1,
+			},
+			wantCode: 200,
+			wantResp: test.Result[int64]{
+				Data: 2,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			tc.before(t)
+			req, err := http.NewRequest(http.MethodPost,
+				"/case-sets/save", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[int64]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan())
+			tc.after(t)
+		})
+	}
+}
+
+func (s *CaseSetHandlerTestSuite) TestUpdateCases() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.initCases(ctx, 4)
+	csid, err := s.dao.Create(ctx, dao.CaseSet{
+		Uid:   uid,
+		Title: "案例集",
+	})
+	require.NoError(s.T(), err)
+	err = s.dao.UpdateCasesByID(ctx, csid, []int64{1, 2})
+	require.NoError(s.T(), err)
+
+	testCases := []struct {
+		name     string
+		req      web.UpdateCases
+		wantCids []int64
+	}{
+		{
+			name: "调整顺序并增加案例",
+			// 重复的案例只保留第一次出现的位置
+			req:      web.UpdateCases{CSID: csid, CIDs: []int64{3, 1, 4, 3}},
+			wantCids: []int64{3, 1, 4},
+		},
+		{
+			name:     "清空案例",
+			req:      web.UpdateCases{CSID: csid},
+			wantCids: []int64{},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil)
+			req, err := http.NewRequest(http.MethodPost,
+				"/case-sets/cases/save", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[any]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+
+			cas, err := s.dao.GetCasesByID(ctx, csid)
+			require.NoError(t, err)
+			cids := make([]int64, 0, len(cas))
+			for _, ca := range cas {
+				cids = append(cids, ca.Id)
+			}
+			assert.Equal(t, tc.wantCids, cids)
+		})
+	}
+}
+
+func (s *CaseSetHandlerTestSuite) TestListAndDetail() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.initCases(ctx, 3)
+	for i := 1; i <= 3; i++ {
+		_, err := s.dao.Create(ctx, dao.CaseSet{
+			Uid:         uid,
+			Title:       fmt.Sprintf("案例集%d", i),
+			Description: fmt.Sprintf("案例集%d描述", i),
+			Biz:         "skill",
+			BizId:       int64(i),
+		})
+		require.NoError(s.T(), err)
+	}
+	err := s.dao.UpdateCasesByID(ctx, 2, []int64{3, 1})
+	require.NoError(s.T(), err)
+	// C 端只能看到发布了的案例 1，并且内容来自线上库
+	_, err = s.caseDAO.Sync(ctx, dao.Case{
+		Id:           1,
+		Uid:          uid,
+		Title:        "发布的案例1",
+		Introduction: "发布的案例1介绍",
+		Status:       domain.PublishedStatus.ToUint8(),
+	})
+	require.NoError(s.T(), err)
+	_, err = s.caseDAO.Save(ctx, dao.Case{
+		Id:           1,
+		Uid:          uid,
+		Title:        "案例1",
+		Introduction: "案例1介绍",
+		Content:      "案例1内容",
+		Status:       domain.PublishedStatus.ToUint8(),
+	})
+	require.NoError(s.T(), err)
+
+	s.T().Run("后台列表", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost,
+			"/case-sets/list", iox.NewJSONReader(web.Page{Offset: 0, Limit: 2}))
+		req.Header.Set("content-type", "application/json")
+		require.NoError(t, err)
+		recorder := test.NewJSONResponseRecorder[web.CaseSetList]()
+		s.server.ServeHTTP(recorder, req)
+		require.Equal(t, 200, recorder.Code)
+		data := recorder.MustScan().Data
+		assert.Equal(t, int64(3), data.Total)
+		require.Len(t, data.CaseSets, 2)
+		for _, cs := range data.CaseSets {
+			assert.Empty(t, cs.Cases)
+			assert.True(t, cs.Utime > 0)
+		}
+	})
+
+	testCases := []struct {
+		name    string
+		server  *egin.Component
+		path    string
+		req     any
+		wantRes web.CaseSet
+	}{
+		{
+			name:   "后台详情",
+			server: s.server,
+			path:   "/case-sets/detail",
+			req:    web.CaseSetID{CSID: 2},
+			wantRes: web.CaseSet{
+				Id:          2,
+				Title:       "案例集2",
+				Description: "案例集2描述",
+				Biz:         "skill",
+				BizId:       2,
+				Cases: []web.Case{
+					{Id: 3, Title: "案例3", Introduction: "案例3介绍", Status: domain.PublishedStatus.ToUint8()},
+					{Id: 1, Title: "案例1", Introduction: "案例1介绍", Status: domain.PublishedStatus.ToUint8()},
+				},
+			},
+		},
+		{
+			name:   "C端详情",
+			server: s.pubServer,
+			path:   "/case-sets/detail",
+			req:    web.CaseSetID{CSID: 2},
+			wantRes: web.CaseSet{
+				Id:          2,
+				Title:       "案例集2",
+				Description: "案例集2描述",
+				Biz:         "skill",
+				BizId:       2,
+				Cases: []web.Case{
+					{Id: 1, Title: "发布的案例1", Introduction: "发布的案例1介绍", Status: domain.PublishedStatus.ToUint8()},
+				},
+				Interactive: web.Interactive{
+					ViewCnt:    3,
+					LikeCnt:    4,
+					CollectCnt: 5,
+					Collected:  true,
+				},
+			},
+		},
+		{
+			name:   "C端按照业务查询",
+			server: s.pubServer,
+			path:   "/case-sets/detail/biz",
+			req:    web.BizReq{Biz: "skill", BizId: 1},
+			wantRes: web.CaseSet{
+				Id:          1,
+				Title:       "案例集1",
+				Description: "案例集1描述",
+				Biz:         "skill",
+				BizId:       1,
+				Interactive: web.Interactive{
+					ViewCnt:    2,
+					LikeCnt:    3,
+					CollectCnt: 4,
+					Liked:      true,
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				tc.path, iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.CaseSet]()
+			tc.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			data := recorder.MustScan().Data
+			assert.True(t, data.Utime > 0)
+			data.Utime = 0
+			for i := range data.Cases {
+				assert.True(t, data.Cases[i].Utime > 0)
+				data.Cases[i].Utime = 0
+			}
+			assert.Equal(t, tc.wantRes, data)
+		})
+	}
+}
+
+func (s *CaseSetHandlerTestSuite) initCases(ctx context.Context, n int) {
+	for i := 1; i <= n; i++ {
+		_, err := s.caseDAO.Save(ctx, dao.Case{
+			Id:           int64(i),
+			Uid:          uid,
+			Title:        fmt.Sprintf("案例%d", i),
+			Introduction: fmt.Sprintf("案例%d介绍", i),
+			Content:      fmt.Sprintf("案例%d内容", i),
+			Status:       domain.PublishedStatus.ToUint8(),
+		})
+		require.NoError(s.T(), err)
+	}
+}
+
+func (s *CaseSetHandlerTestSuite) assertCaseSet(t *testing.T, expect dao.CaseSet, actual dao.CaseSet) {
+	assert.True(t, actual.Id > 0)
+	assert.True(t, actual.Ctime > 0)
+	assert.True(t, actual.Utime > 0)
+	actual.Id = 0
+	actual.Ctime = 0
+	actual.Utime = 0
+	assert.Equal(t, expect, actual)
+}
+
+func TestCaseSetHandler(t *testing.T) {
+	suite.Run(t, new(CaseSetHandlerTestSuite))
+}
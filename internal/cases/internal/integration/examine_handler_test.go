@@ -0,0 +1,310 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/ai"
+	aimocks "github.com/ecodeclub/webook/internal/ai/mocks"
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+	"github.com/ecodeclub/webook/internal/cases/internal/errs"
+	"github.com/ecodeclub/webook/internal/cases/internal/integration/startup"
+	"github.com/ecodeclub/webook/internal/cases/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/cases/internal/web"
+	"github.com/ecodeclub/webook/internal/interactive"
+	intrmocks "github.com/ecodeclub/webook/internal/interactive/mocks"
+	"github.com/ecodeclub/webook/internal/test"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/ego-component/egorm"
+	"github.com/gin-gonic/gin"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/server/egin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type ExamineHandlerTestSuite struct {
+	suite.Suite
+	server *egin.Component
+	db     *egorm.Component
+}
+
+func (s *ExamineHandlerTestSuite) SetupSuite() {
+	ctrl := gomock.NewController(s.T())
+	aiSvc := aimocks.NewMockService(ctrl)
+	aiSvc.EXPECT().Invoke(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req ai.LLMRequest) (ai.LLMResponse, error) {
+		// 案例标题、用户输入和评分依据
+		require.Len(s.T(), req.Input, 3)
+		assert.Equal(s.T(), "case_examine", req.Biz)
+		answer := `{"level":"15K","comment":"还可以"}`
+		switch req.Input[1] {
+		case "积分不足":
+			return ai.LLMResponse{}, ai.ErrInsufficientCredit
+		case "讲得很好":
+			answer = "```json\n{\"level\":\"35k\",\"comment\":\"亮点讲得很清楚\"}\n```"
+		case "格式错误":
+			answer = "评分：35K"
+		}
+		return ai.LLMResponse{
+			Tokens: req.Uid,
+			Amount: req.Uid,
+			Answer: answer,
+		}, nil
+	}).AnyTimes()
+	intrSvc := intrmocks.NewMockService(ctrl)
+	intrSvc.EXPECT().GetByIds(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(map[int64]interactive.Interactive{}, nil).AnyTimes()
+
+	module, err := startup.InitModule(nil, &interactive.Module{Svc: intrSvc}, &ai.Module{Svc: aiSvc})
+	require.NoError(s.T(), err)
+	econf.Set("server", map[string]any{"contextTimeout": "1s"})
+	server := egin.Load("server").Build()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set(session.CtxSessionKey,
+			session.NewMemorySession(session.Claims{
+				Uid: uid,
+			}))
+	})
+	// 登录了的用户访问公开的列表，能看到自己的测试结果
+	module.Hdl.PublicRoutes(server.Engine)
+	module.ExamineHdl.MemberRoutes(server.Engine)
+	s.server = server
+	s.db = testioc.InitDB()
+	err = dao.InitTables(s.db)
+	require.NoError(s.T(), err)
+}
+
+func (s *ExamineHandlerTestSuite) SetupTest() {
+	now := time.Now().UnixMilli()
+	for _, ca := range []dao.PublishCase{
+		{Id: 1, Title: "案例1", Keywords: "关键字1", Highlight: "亮点1", Status: domain.PublishedStatus.ToUint8(), Utime: now},
+		{Id: 2, Title: "案例2", Keywords: "关键字2", Highlight: "亮点2", Status: domain.PublishedStatus.ToUint8(), Utime: now},
+	} {
+		err := s.db.Create(&ca).Error
+		require.NoError(s.T(), err)
+	}
+}
+
+func (s *ExamineHandlerTestSuite) TearDownTest() {
+	err := s.db.Exec("TRUNCATE TABLE `publish_cases`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `case_examine_records`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `case_results`").Error
+	require.NoError(s.T(), err)
+}
+
+func (s *ExamineHandlerTestSuite) TestExamine() {
+	testCases := []struct {
+		name   string
+		before func(t *testing.T)
+		after  func(t *testing.T)
+
+		req web.ExamineReq
+
+		wantCode int
+		wantResp test.Result[web.ExamineResult]
+	}{
+		{
+			name:   "第一次测试",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				var record dao.CaseExamineRecord
+				err := s.db.Where("uid = ? AND cid = ?", uid, 1).First(&record).Error
+				require.NoError(t, err)
+				assert.True(t, record.Id > 0)
+				record.Id = 0
+				assert.True(t, record.Ctime > 0)
+				record.Ctime = 0
+				assert.True(t, record.Utime > 0)
+				record.Utime = 0
+				assert.True(t, len(record.Tid) > 0)
+				record.Tid = ""
+				assert.Equal(t, dao.CaseExamineRecord{
+					Uid:       uid,
+					Cid:       1,
+					Result:    domain.ResultBasic.ToUint8(),
+					RawResult: `{"level":"15K","comment":"还可以"}`,
+					Tokens:    uid,
+					Amount:    uid,
+					Input:     "测试一下",
+				}, record)
+				s.assertCaseResult(t, 1, domain.ResultBasic)
+			},
+			req: web.ExamineReq{
+				Cid:   1,
+				Input: "测试一下",
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ExamineResult]{
+				Data: web.ExamineResult{
+					Result:    domain.ResultBasic.ToUint8(),
+					RawResult: `{"level":"15K","comment":"还可以"}`,
+					Tokens:    uid,
+					Amount:    uid,
+				},
+			},
+		},
+		{
+			name: "重复测试，覆盖之前的结果",
+			before: func(t *testing.T) {
+				err := s.db.Create(&dao.CaseResult{
+					Uid:    uid,
+					Cid:    2,
+					Result: domain.ResultBasic.ToUint8(),
+					Ctime:  123,
+					Utime:  123,
+				}).Error
+				require.NoError(t, err)
+			},
+			after: func(t *testing.T) {
+				var cnt int64
+				err := s.db.Model(&dao.CaseExamineRecord{}).
+					Where("uid = ? AND cid = ?", uid, 2).Count(&cnt).Error
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), cnt)
+				s.assertCaseResult(t, 2, domain.ResultAdvanced)
+			},
+			req: web.ExamineReq{
+				Cid:   2,
+				Input: "讲得很好",
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ExamineResult]{
+				Data: web.ExamineResult{
+					Result:    domain.ResultAdvanced.ToUint8(),
+					RawResult: "```json\n{\"level\":\"35k\",\"comment\":\"亮点讲得很清楚\"}\n```",
+					Tokens:    uid,
+					Amount:    uid,
+				},
+			},
+		},
+		{
+			name:   "AI 返回的格式不对",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				var cnt int64
+				err := s.db.Model(&dao.CaseExamineRecord{}).
+					Where("uid = ? AND cid = ? AND raw_result = ?", uid, 1, "评分：35K").Count(&cnt).Error
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), cnt)
+				// 第一次测试的结果没有被覆盖
+				s.assertCaseResult(t, 1, domain.ResultBasic)
+			},
+			req: web.ExamineReq{
+				Cid:   1,
+				Input: "格式错误",
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ExamineResult]{
+				Code: errs.InvalidExamineResult.Code,
+				Msg:  errs.InvalidExamineResult.Msg,
+			},
+		},
+		{
+			name:   "积分不足",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				var cnt int64
+				err := s.db.Model(&dao.CaseExamineRecord{}).
+					Where("uid = ? AND cid = ?", uid, 1).Count(&cnt).Error
+				require.NoError(t, err)
+				assert.Equal(t, int64(0), cnt)
+			},
+			req: web.ExamineReq{
+				Cid:   1,
+				Input: "积分不足",
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ExamineResult]{
+				Code: errs.InsufficientCredit.Code,
+				Msg:  errs.InsufficientCredit.Msg,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			tc.before(t)
+			req, err := http.NewRequest(http.MethodPost,
+				"/case/examine", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.ExamineResult]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan())
+			tc.after(t)
+		})
+	}
+}
+
+func (s *ExamineHandlerTestSuite) TestPubListWithResults() {
+	t := s.T()
+	err := s.db.Create(&dao.CaseResult{
+		Uid:    uid,
+		Cid:    2,
+		Result: domain.ResultIntermediate.ToUint8(),
+		Ctime:  123,
+		Utime:  123,
+	}).Error
+	require.NoError(t, err)
+	// 别人的测试结果不会出现在列表里面
+	err = s.db.Create(&dao.CaseResult{
+		Uid:    uid + 1,
+		Cid:    1,
+		Result: domain.ResultAdvanced.ToUint8(),
+		Ctime:  123,
+		Utime:  123,
+	}).Error
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost,
+		"/case/pub/list", iox.NewJSONReader(web.PubListReq{Offset: 0, Limit: 10}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.CasesList]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	results := make(map[int64]uint8, 2)
+	for _, ca := range recorder.MustScan().Data.Cases {
+		results[ca.Id] = ca.ExamineResult
+	}
+	assert.Equal(t, map[int64]uint8{
+		1: domain.ResultFailed.ToUint8(),
+		2: domain.ResultIntermediate.ToUint8(),
+	}, results)
+}
+
+func (s *ExamineHandlerTestSuite) assertCaseResult(t *testing.T, cid int64, want domain.Result) {
+	var res dao.CaseResult
+	err := s.db.Where("uid = ? AND cid = ?", uid, cid).First(&res).Error
+	require.NoError(t, err)
+	assert.Equal(t, want.ToUint8(), res.Result)
+}
+
+func TestExamineHandler(t *testing.T) {
+	suite.Run(t, new(ExamineHandlerTestSuite))
+}
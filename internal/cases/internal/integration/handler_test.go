@@ -26,9 +26,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ecodeclub/webook/internal/ai"
 	"github.com/ecodeclub/webook/internal/interactive"
 	intrmocks "github.com/ecodeclub/webook/internal/interactive/mocks"
+	"github.com/ecodeclub/webook/internal/label"
 
+	"github.com/ecodeclub/webook/internal/cases"
 	"github.com/ecodeclub/webook/internal/cases/internal/domain"
 	"github.com/ecodeclub/webook/internal/cases/internal/event"
 	eveMocks "github.com/ecodeclub/webook/internal/cases/internal/event/mocks"
@@ -36,6 +39,7 @@ import (
 
 	"github.com/ecodeclub/ecache"
 	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ecodeclub/ginx/session"
 	"github.com/ecodeclub/webook/internal/cases/internal/integration/startup"
@@ -48,21 +52,26 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gotomicro/ego/core/econf"
 	"github.com/gotomicro/ego/server/egin"
+	"github.com/gotomicro/ego/task/ejob"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 const uid = 2051
 
 type HandlerTestSuite struct {
 	suite.Suite
-	server   *egin.Component
-	db       *egorm.Component
-	rdb      ecache.Cache
-	dao      dao.CaseDAO
-	ctrl     *gomock.Controller
-	producer *eveMocks.MockSyncEventProducer
+	server    *egin.Component
+	db        *egorm.Component
+	rdb       ecache.Cache
+	dao       dao.CaseDAO
+	ctrl      *gomock.Controller
+	producer  *eveMocks.MockSyncEventProducer
+	svc       cases.Service
+	labelsJob *cases.SyncLabelsJob
+	purgeJob  *cases.PurgeTrashJob
 }
 
 func (s *HandlerTestSuite) TearDownSuite() {
@@ -70,6 +79,8 @@ func (s *HandlerTestSuite) TearDownSuite() {
 	require.NoError(s.T(), err)
 	err = s.db.Exec("DROP TABLE `publish_cases`").Error
 	require.NoError(s.T(), err)
+	err = s.db.Exec("DROP TABLE `publish_case_labels`").Error
+	require.NoError(s.T(), err)
 }
 
 func (s *HandlerTestSuite) TearDownTest() {
@@ -77,6 +88,10 @@ func (s *HandlerTestSuite) TearDownTest() {
 	require.NoError(s.T(), err)
 	err = s.db.Exec("TRUNCATE TABLE `publish_cases`").Error
 	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `publish_case_labels`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `labels`").Error
+	require.NoError(s.T(), err)
 }
 
 func (s *HandlerTestSuite) SetupSuite() {
@@ -107,7 +122,7 @@ func (s *HandlerTestSuite) SetupSuite() {
 		}
 		return res, nil
 	}).AnyTimes()
-	module, err := startup.InitModule(s.producer, intrModule)
+	module, err := startup.InitModule(s.producer, intrModule, &ai.Module{})
 	require.NoError(s.T(), err)
 	handler := module.Hdl
 	econf.Set("server", map[string]any{"contextTimeout": "1s"})
@@ -127,6 +142,9 @@ func (s *HandlerTestSuite) SetupSuite() {
 	handler.MemberRoutes(server.Engine)
 
 	s.server = server
+	s.svc = module.Svc
+	s.labelsJob = module.SyncLabelsJob
+	s.purgeJob = module.PurgeTrashJob
 	s.db = testioc.InitDB()
 	err = dao.InitTables(s.db)
 	require.NoError(s.T(), err)
@@ -478,6 +496,16 @@ func (s *HandlerTestSuite) TestPublish() {
 				publishCase, err := s.dao.GetPublishCase(ctx, 1)
 				require.NoError(t, err)
 				s.assertCase(t, wantCase, dao.Case(publishCase))
+				// 标签关联到 label 模块
+				var rels []dao.PublishCaseLabel
+				err = s.db.WithContext(ctx).Where("cid = ?", 1).Find(&rels).Error
+				require.NoError(t, err)
+				require.Len(t, rels, 1)
+				var name string
+				err = s.db.WithContext(ctx).Table("labels").Select("name").
+					Where("id = ?", rels[0].LabelId).Scan(&name).Error
+				require.NoError(t, err)
+				assert.Equal(t, "MySQL", name)
 			},
 			req: web.SaveReq{
 				Case: web.Case{
@@ -776,6 +804,129 @@ func (s *HandlerTestSuite) TestPubList() {
 	}
 }
 
+func (s *HandlerTestSuite) TestPubListByLabels() {
+	data := make([]dao.PublishCase, 0, 4)
+	for idx := 0; idx < 4; idx++ {
+		data = append(data, dao.PublishCase{
+			Id:    int64(idx + 1),
+			Uid:   uid,
+			Title: fmt.Sprintf("这是发布的案例标题 %d", idx),
+			Utime: 123,
+		})
+	}
+	err := s.db.Create(&data).Error
+	require.NoError(s.T(), err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	labels := map[int64][]string{
+		1: {"Redis"},
+		2: {"Redis", "MySQL"},
+		3: {"MySQL", "Kafka"},
+		4: {},
+	}
+	for cid := int64(1); cid <= 4; cid++ {
+		err = s.svc.SyncLabels(ctx, cid, labels[cid])
+		require.NoError(s.T(), err)
+	}
+	// 按照创建顺序，Redis 是 1，MySQL 是 2，Kafka 是 3
+	facets := []web.LabelFacet{
+		{LabelId: 1, Name: "Redis", Cnt: 2},
+		{LabelId: 2, Name: "MySQL", Cnt: 2},
+		{LabelId: 3, Name: "Kafka", Cnt: 1},
+	}
+
+	testCases := []struct {
+		name string
+		req  web.PubListReq
+
+		wantIds []int64
+	}{
+		{
+			name: "命中任意一个",
+			req: web.PubListReq{
+				Limit:    10,
+				LabelIds: []int64{1, 3},
+			},
+			wantIds: []int64{3, 2, 1},
+		},
+		{
+			name: "命中全部",
+			req: web.PubListReq{
+				Limit:     10,
+				LabelIds:  []int64{2, 3},
+				LabelMode: label.MatchAll.ToUint8(),
+			},
+			wantIds: []int64{3},
+		},
+		{
+			name: "翻页",
+			req: web.PubListReq{
+				Offset:   1,
+				Limit:    10,
+				LabelIds: []int64{1},
+			},
+			wantIds: []int64{1},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				"/case/pub/list", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.CasesList]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			res := recorder.MustScan().Data
+			assert.Equal(t, tc.wantIds, slice.Map(res.Cases, func(idx int, src web.Case) int64 {
+				return src.Id
+			}))
+		})
+	}
+
+	// 标签统计和筛选条件无关
+	req, err := http.NewRequest(http.MethodPost, "/case/pub/facets", nil)
+	require.NoError(s.T(), err)
+	recorder := test.NewJSONResponseRecorder[[]web.LabelFacet]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(s.T(), 200, recorder.Code)
+	assert.Equal(s.T(), facets, recorder.MustScan().Data)
+}
+
+func (s *HandlerTestSuite) TestSyncLabelsJob() {
+	err := s.db.Create(&[]dao.PublishCase{
+		{
+			Id:     1,
+			Labels: sqlx.JsonColumn[[]string]{Val: []string{"Redis", "MySQL"}, Valid: true},
+		},
+		{
+			Id:     2,
+			Labels: sqlx.JsonColumn[[]string]{Val: []string{"MySQL"}, Valid: true},
+		},
+		{
+			Id: 3,
+		},
+	}).Error
+	require.NoError(s.T(), err)
+	// 已经不存在的标签会被删掉
+	err = s.db.Create(&dao.PublishCaseLabel{Cid: 3, LabelId: 100}).Error
+	require.NoError(s.T(), err)
+
+	err = s.labelsJob.Run(ejob.Context{Ctx: context.Background()})
+	require.NoError(s.T(), err)
+
+	var rels []dao.PublishCaseLabel
+	err = s.db.Order("cid ASC, label_id ASC").Find(&rels).Error
+	require.NoError(s.T(), err)
+	// 按照 id 升序处理，Redis 是 1，MySQL 是 2
+	assert.Equal(s.T(), [][2]int64{{1, 1}, {1, 2}, {2, 2}},
+		slice.Map(rels, func(idx int, src dao.PublishCaseLabel) [2]int64 {
+			return [2]int64{src.Cid, src.LabelId}
+		}))
+}
+
 func (s *HandlerTestSuite) TestPubDetail() {
 	err := s.db.Create(&dao.PublishCase{
 		Id:           3,
@@ -906,6 +1057,174 @@ func (s *HandlerTestSuite) TestEvent() {
 }
 
 // assertCase 不比较 id
+func (s *HandlerTestSuite) TestTakeDown() {
+	testCases := []struct {
+		name       string
+		path       string
+		wantStatus domain.CaseStatus
+	}{
+		{
+			name:       "下线",
+			path:       "/case/unpublish",
+			wantStatus: domain.UnPublishedStatus,
+		},
+		{
+			name:       "删除",
+			path:       "/case/delete",
+			wantStatus: domain.DeletedStatus,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			defer cancel()
+			id, err := s.dao.Sync(ctx, dao.Case{
+				Uid:    uid,
+				Title:  "案例标题",
+				Status: domain.PublishedStatus.ToUint8(),
+			})
+			require.NoError(t, err)
+			err = s.dao.SyncLabels(ctx, id, []int64{1, 2})
+			require.NoError(t, err)
+			s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, evt event.CaseEvent) error {
+					assert.Equal(t, event.NewSyncDeleteEvent(domain.BizCase, id), evt)
+					return nil
+				}).MaxTimes(1)
+
+			req, err := http.NewRequest(http.MethodPost,
+				tc.path, iox.NewJSONReader(web.CaseId{Cid: id}))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[any]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+
+			ca, err := s.dao.GetCaseByID(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantStatus.ToUint8(), ca.Status)
+			_, err = s.dao.GetPublishCase(ctx, id)
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			var cnt int64
+			err = s.db.WithContext(ctx).Model(&dao.PublishCaseLabel{}).
+				Where("cid = ?", id).Count(&cnt).Error
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), cnt)
+		})
+	}
+}
+
+func (s *HandlerTestSuite) TestRestore() {
+	t := s.T()
+	err := s.db.Create(&[]dao.Case{
+		{Id: 1, Uid: uid, Title: "回收站里面的案例", Status: domain.DeletedStatus.ToUint8()},
+		{Id: 2, Uid: uid, Title: "已发表的案例", Status: domain.PublishedStatus.ToUint8()},
+	}).Error
+	require.NoError(t, err)
+	for _, id := range []int64{1, 2} {
+		req, err := http.NewRequest(http.MethodPost,
+			"/case/restore", iox.NewJSONReader(web.CaseId{Cid: id}))
+		req.Header.Set("content-type", "application/json")
+		require.NoError(t, err)
+		recorder := test.NewJSONResponseRecorder[any]()
+		s.server.ServeHTTP(recorder, req)
+		require.Equal(t, 200, recorder.Code)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ca, err := s.dao.GetCaseByID(ctx, 1)
+	require.NoError(t, err)
+	// 恢复之后需要重新发布
+	assert.Equal(t, domain.UnPublishedStatus.ToUint8(), ca.Status)
+	// 不在回收站里面的不受影响
+	ca, err = s.dao.GetCaseByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PublishedStatus.ToUint8(), ca.Status)
+}
+
+func (s *HandlerTestSuite) TestTrashList() {
+	t := s.T()
+	err := s.db.Create(&[]dao.Case{
+		{Id: 1, Uid: uid, Title: "案例1", Status: domain.UnPublishedStatus.ToUint8(), Utime: 1},
+		{Id: 2, Uid: uid, Title: "案例2", Status: domain.DeletedStatus.ToUint8(), Utime: 2},
+		{Id: 3, Uid: uid, Title: "案例3", Status: domain.DeletedStatus.ToUint8(), Utime: 3},
+	}).Error
+	require.NoError(t, err)
+	testCases := []struct {
+		name     string
+		path     string
+		wantResp web.CasesList
+	}{
+		{
+			name: "回收站按照删除时间倒序",
+			path: "/case/trash/list",
+			wantResp: web.CasesList{
+				Total: 2,
+				Cases: []web.Case{
+					{Id: 3, Title: "案例3", Status: domain.DeletedStatus.ToUint8(), Utime: 3},
+					{Id: 2, Title: "案例2", Status: domain.DeletedStatus.ToUint8(), Utime: 2},
+				},
+			},
+		},
+		{
+			name: "制作库列表不包含回收站",
+			path: "/case/list",
+			wantResp: web.CasesList{
+				Total: 1,
+				Cases: []web.Case{
+					{Id: 1, Title: "案例1", Status: domain.UnPublishedStatus.ToUint8(), Utime: 1},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				tc.path, iox.NewJSONReader(web.Page{Limit: 10}))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.CasesList]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan().Data)
+		})
+	}
+}
+
+func (s *HandlerTestSuite) TestPurgeTrashJob() {
+	t := s.T()
+	expired := time.Now().Add(-31 * 24 * time.Hour).UnixMilli()
+	err := s.db.Create(&[]dao.Case{
+		{Id: 1, Uid: uid, Title: "过期的案例", Status: domain.DeletedStatus.ToUint8(), Utime: expired},
+		{Id: 2, Uid: uid, Title: "刚删除的案例", Status: domain.DeletedStatus.ToUint8(), Utime: time.Now().UnixMilli()},
+		{Id: 3, Uid: uid, Title: "很久没有更新的案例", Status: domain.UnPublishedStatus.ToUint8(), Utime: expired},
+	}).Error
+	require.NoError(t, err)
+	err = s.db.Create(&dao.CaseSetCase{CSID: 1, CID: 1}).Error
+	require.NoError(t, err)
+	s.producer.EXPECT().Produce(gomock.Any(), event.NewSyncDeleteEvent(domain.BizCase, 1)).Return(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	err = s.purgeJob.Run(ctx)
+	require.NoError(t, err)
+
+	_, err = s.dao.GetCaseByID(ctx, 1)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	var cnt int64
+	err = s.db.WithContext(ctx).Model(&dao.CaseSetCase{}).Where("cid = ?", 1).Count(&cnt).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), cnt)
+	for _, id := range []int64{2, 3} {
+		_, err = s.dao.GetCaseByID(ctx, id)
+		assert.NoError(t, err)
+	}
+	err = s.db.Exec("TRUNCATE TABLE `case_set_cases`").Error
+	require.NoError(t, err)
+}
+
 func (s *HandlerTestSuite) assertCase(t *testing.T, expect dao.Case, ca dao.Case) {
 	assert.True(t, ca.Id > 0)
 	assert.True(t, ca.Ctime > 0)
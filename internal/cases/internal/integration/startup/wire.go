@@ -17,25 +17,43 @@
 package startup
 
 import (
+	"github.com/ecodeclub/webook/internal/ai"
 	"github.com/ecodeclub/webook/internal/cases"
 	"github.com/ecodeclub/webook/internal/cases/internal/event"
+	"github.com/ecodeclub/webook/internal/cases/internal/job"
 	"github.com/ecodeclub/webook/internal/cases/internal/repository"
 	"github.com/ecodeclub/webook/internal/cases/internal/service"
 	"github.com/ecodeclub/webook/internal/cases/internal/web"
 	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/label"
 	testioc "github.com/ecodeclub/webook/internal/test/ioc"
 	"github.com/google/wire"
 )
 
 func InitModule(
 	syncProducer event.SyncEventProducer,
-	intrModule *interactive.Module) (*cases.Module, error) {
+	intrModule *interactive.Module,
+	aiModule *ai.Module) (*cases.Module, error) {
 	wire.Build(cases.InitCaseDAO,
 		testioc.BaseSet,
 		repository.NewCaseRepo,
 		event.NewInteractiveEventProducer,
 		service.NewService,
 		web.NewHandler,
+		job.NewSyncLabelsJob,
+		cases.InitPurgeTrashJob,
+		cases.InitCaseSetDAO,
+		repository.NewCaseSetRepository,
+		service.NewCaseSetService,
+		web.NewCaseSetHandler,
+		web.NewAdminCaseSetHandler,
+		cases.InitExamineDAO,
+		repository.NewExamineRepository,
+		service.NewLLMExamineService,
+		web.NewExamineHandler,
+		wire.FieldsOf(new(*ai.Module), "Svc"),
+		label.InitModule,
+		wire.FieldsOf(new(*label.Module), "Svc"),
 		wire.FieldsOf(new(*interactive.Module), "Svc"),
 		wire.Struct(new(cases.Module), "*"),
 	)
@@ -7,18 +7,21 @@
 package startup
 
 import (
+	"github.com/ecodeclub/webook/internal/ai"
 	"github.com/ecodeclub/webook/internal/cases"
 	"github.com/ecodeclub/webook/internal/cases/internal/event"
+	"github.com/ecodeclub/webook/internal/cases/internal/job"
 	"github.com/ecodeclub/webook/internal/cases/internal/repository"
 	"github.com/ecodeclub/webook/internal/cases/internal/service"
 	"github.com/ecodeclub/webook/internal/cases/internal/web"
 	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/label"
 	testioc "github.com/ecodeclub/webook/internal/test/ioc"
 )
 
 // Injectors from wire.go:
 
-func InitModule(syncProducer event.SyncEventProducer, intrModule *interactive.Module) (*cases.Module, error) {
+func InitModule(syncProducer event.SyncEventProducer, intrModule *interactive.Module, aiModule *ai.Module) (*cases.Module, error) {
 	db := testioc.InitDB()
 	caseDAO := cases.InitCaseDAO(db)
 	caseRepo := repository.NewCaseRepo(caseDAO)
@@ -27,12 +30,33 @@ func InitModule(syncProducer event.SyncEventProducer, intrModule *interactive.Mo
 	if err != nil {
 		return nil, err
 	}
-	serviceService := service.NewService(caseRepo, interactiveEventProducer, syncProducer)
+	labelModule := label.InitModule(db)
+	labelService := labelModule.Svc
+	serviceService := service.NewService(caseRepo, labelService, interactiveEventProducer, syncProducer)
+	examineDAO := cases.InitExamineDAO(db)
+	examineRepository := repository.NewExamineRepository(examineDAO)
+	llmService := aiModule.Svc
+	examineService := service.NewLLMExamineService(caseRepo, examineRepository, llmService)
 	service2 := intrModule.Svc
-	handler := web.NewHandler(serviceService, service2)
+	handler := web.NewHandler(serviceService, examineService, service2)
+	syncLabelsJob := job.NewSyncLabelsJob(serviceService)
+	purgeTrashJob := cases.InitPurgeTrashJob(serviceService)
+	caseSetDAO := cases.InitCaseSetDAO(db)
+	caseSetRepository := repository.NewCaseSetRepository(caseSetDAO)
+	caseSetService := service.NewCaseSetService(caseSetRepository, interactiveEventProducer, syncProducer)
+	caseSetHandler := web.NewCaseSetHandler(caseSetService, service2)
+	adminCaseSetHandler := web.NewAdminCaseSetHandler(caseSetService)
+	examineHandler := web.NewExamineHandler(examineService)
 	module := &cases.Module{
-		Svc: serviceService,
-		Hdl: handler,
+		Svc:           serviceService,
+		Hdl:           handler,
+		SyncLabelsJob: syncLabelsJob,
+		PurgeTrashJob: purgeTrashJob,
+		SetSvc:        caseSetService,
+		SetHdl:        caseSetHandler,
+		AdminSetHdl:   adminCaseSetHandler,
+		ExamineSvc:    examineService,
+		ExamineHdl:    examineHandler,
 	}
 	return module, nil
 }
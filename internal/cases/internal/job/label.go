@@ -0,0 +1,81 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+	"github.com/ecodeclub/webook/internal/cases/internal/service"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/task/ejob"
+)
+
+// SyncLabelsJob 将线上库案例的字符串标签关联到 label 模块的标签上
+// 主要用于迁移历史数据，发布的时候会自动关联，所以重复执行也没有问题
+type SyncLabelsJob struct {
+	batchSize    int
+	batchTimeout time.Duration
+	svc          service.Service
+	logger       *elog.Component
+}
+
+func NewSyncLabelsJob(svc service.Service) *SyncLabelsJob {
+	return &SyncLabelsJob{
+		svc:          svc,
+		batchSize:    100,
+		batchTimeout: time.Second * 10,
+		logger:       elog.DefaultLogger,
+	}
+}
+
+func (j *SyncLabelsJob) Run(ctx ejob.Context) error {
+	var (
+		cnt int
+		id  int64
+	)
+	for {
+		cases, err := j.batch(ctx.Ctx, id)
+		if err != nil {
+			return err
+		}
+		cnt += len(cases)
+		if len(cases) < j.batchSize {
+			break
+		}
+		id = cases[len(cases)-1].Id
+	}
+	j.logger.Info("关联案例标签完成", elog.Int("cnt", cnt))
+	return nil
+}
+
+// batch 按照 id 翻页，执行期间新发布的案例不会让数据挪动，返回的是这一批处理过的案例
+func (j *SyncLabelsJob) batch(ctx context.Context, id int64) ([]domain.Case, error) {
+	ctx, cancel := context.WithTimeout(ctx, j.batchTimeout)
+	defer cancel()
+	cases, err := j.svc.PubListAfter(ctx, id, j.batchSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, ca := range cases {
+		err = j.svc.SyncLabels(ctx, ca.Id, ca.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("关联案例 %d 的标签失败 %w", ca.Id, err)
+		}
+	}
+	return cases, nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+	"github.com/ecodeclub/webook/internal/cases/internal/repository/dao"
+)
+
+type CaseSetRepository interface {
+	Create(ctx context.Context, set domain.CaseSet) (int64, error)
+	UpdateNonZero(ctx context.Context, set domain.CaseSet) error
+	// UpdateCases 覆盖案例集里面的案例，重复的案例只保留第一次出现的位置
+	UpdateCases(ctx context.Context, set domain.CaseSet) error
+	// GetByID 包括案例集里面的案例
+	GetByID(ctx context.Context, id int64) (domain.CaseSet, error)
+	// GetPubByID 案例集里面的案例来自线上库
+	GetPubByID(ctx context.Context, id int64) (domain.CaseSet, error)
+	// GetPubByBiz 案例集里面的案例来自线上库
+	GetPubByBiz(ctx context.Context, biz string, bizId int64) (domain.CaseSet, error)
+	// GetByIDs 只有基本信息
+	GetByIDs(ctx context.Context, ids []int64) ([]domain.CaseSet, error)
+	Total(ctx context.Context) (int64, error)
+	List(ctx context.Context, offset int, limit int) ([]domain.CaseSet, error)
+}
+
+var _ CaseSetRepository = &caseSetRepository{}
+
+type caseSetRepository struct {
+	dao dao.CaseSetDAO
+}
+
+func (c *caseSetRepository) Create(ctx context.Context, set domain.CaseSet) (int64, error) {
+	return c.dao.Create(ctx, c.toEntity(set))
+}
+
+func (c *caseSetRepository) UpdateNonZero(ctx context.Context, set domain.CaseSet) error {
+	return c.dao.UpdateNonZero(ctx, c.toEntity(set))
+}
+
+func (c *caseSetRepository) UpdateCases(ctx context.Context, set domain.CaseSet) error {
+	cids := make([]int64, 0, len(set.Cases))
+	seen := make(map[int64]struct{}, len(set.Cases))
+	for _, ca := range set.Cases {
+		if _, ok := seen[ca.Id]; ok {
+			continue
+		}
+		seen[ca.Id] = struct{}{}
+		cids = append(cids, ca.Id)
+	}
+	return c.dao.UpdateCasesByID(ctx, set.Id, cids)
+}
+
+func (c *caseSetRepository) GetByID(ctx context.Context, id int64) (domain.CaseSet, error) {
+	set, err := c.dao.GetByID(ctx, id)
+	if err != nil {
+		return domain.CaseSet{}, err
+	}
+	return c.withCases(ctx, set)
+}
+
+func (c *caseSetRepository) withCases(ctx context.Context, set dao.CaseSet) (domain.CaseSet, error) {
+	cases, err := c.dao.GetCasesByID(ctx, set.Id)
+	if err != nil {
+		return domain.CaseSet{}, err
+	}
+	res := c.toDomain(set)
+	res.Cases = slice.Map(cases, func(idx int, src dao.Case) domain.Case {
+		return c.toDomainCase(src)
+	})
+	return res, nil
+}
+
+func (c *caseSetRepository) GetPubByID(ctx context.Context, id int64) (domain.CaseSet, error) {
+	set, err := c.dao.GetByID(ctx, id)
+	if err != nil {
+		return domain.CaseSet{}, err
+	}
+	return c.withPubCases(ctx, set)
+}
+
+func (c *caseSetRepository) GetPubByBiz(ctx context.Context, biz string, bizId int64) (domain.CaseSet, error) {
+	set, err := c.dao.GetByBiz(ctx, biz, bizId)
+	if err != nil {
+		return domain.CaseSet{}, err
+	}
+	return c.withPubCases(ctx, set)
+}
+
+func (c *caseSetRepository) withPubCases(ctx context.Context, set dao.CaseSet) (domain.CaseSet, error) {
+	cases, err := c.dao.GetPubCasesByID(ctx, set.Id)
+	if err != nil {
+		return domain.CaseSet{}, err
+	}
+	res := c.toDomain(set)
+	res.Cases = slice.Map(cases, func(idx int, src dao.PublishCase) domain.Case {
+		return c.toDomainCase(dao.Case(src))
+	})
+	return res, nil
+}
+
+func (c *caseSetRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.CaseSet, error) {
+	sets, err := c.dao.GetByIDs(ctx, ids)
+	return slice.Map(sets, func(idx int, src dao.CaseSet) domain.CaseSet {
+		return c.toDomain(src)
+	}), err
+}
+
+func (c *caseSetRepository) Total(ctx context.Context) (int64, error) {
+	return c.dao.Count(ctx)
+}
+
+func (c *caseSetRepository) List(ctx context.Context, offset int, limit int) ([]domain.CaseSet, error) {
+	sets, err := c.dao.List(ctx, offset, limit)
+	return slice.Map(sets, func(idx int, src dao.CaseSet) domain.CaseSet {
+		return c.toDomain(src)
+	}), err
+}
+
+func (c *caseSetRepository) toEntity(set domain.CaseSet) dao.CaseSet {
+	return dao.CaseSet{
+		Id:          set.Id,
+		Uid:         set.Uid,
+		Title:       set.Title,
+		Description: set.Description,
+		Biz:         set.Biz,
+		BizId:       set.BizId,
+	}
+}
+
+func (c *caseSetRepository) toDomain(set dao.CaseSet) domain.CaseSet {
+	return domain.CaseSet{
+		Id:          set.Id,
+		Uid:         set.Uid,
+		Title:       set.Title,
+		Description: set.Description,
+		Biz:         set.Biz,
+		BizId:       set.BizId,
+		Utime:       time.UnixMilli(set.Utime),
+	}
+}
+
+// toDomainCase 案例集里面只展示案例的基本信息
+func (c *caseSetRepository) toDomainCase(ca dao.Case) domain.Case {
+	return domain.Case{
+		Id:           ca.Id,
+		Uid:          ca.Uid,
+		Introduction: ca.Introduction,
+		Labels:       ca.Labels.Val,
+		Title:        ca.Title,
+		Status:       domain.CaseStatus(ca.Status),
+		Utime:        time.UnixMilli(ca.Utime),
+	}
+}
+
+func NewCaseSetRepository(d dao.CaseSetDAO) CaseSetRepository {
+	return &caseSetRepository{
+		dao: d,
+	}
+}
@@ -9,15 +9,34 @@ import (
 	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ecodeclub/webook/internal/cases/internal/domain"
 	"github.com/ecodeclub/webook/internal/cases/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/label"
 )
 
 type CaseRepo interface {
 	// c端接口
-	PubList(ctx context.Context, offset int, limit int) ([]domain.Case, error)
+	// PubList filter 为空的时候不按照标签筛选
+	PubList(ctx context.Context, offset int, limit int, filter label.Filter) ([]domain.Case, error)
+	// PubListAfter 按照 id 升序翻页，返回 id 之后的案例
+	PubListAfter(ctx context.Context, id int64, limit int) ([]domain.Case, error)
+	// PubLabelCounts 返回的 LabelFacet 里面没有标签名字
+	PubLabelCounts(ctx context.Context) ([]label.Facet, error)
+	// SyncLabels 覆盖线上库案例关联的标签 ID
+	SyncLabels(ctx context.Context, cid int64, labelIds []int64) error
 	GetPubByID(ctx context.Context, caseId int64) (domain.Case, error)
 	GetPubByIDs(ctx context.Context, ids []int64) ([]domain.Case, error)
 	// Sync 保存到制作库，而后同步到线上库
 	Sync(ctx context.Context, ca domain.Case) (int64, error)
+	// Unpublish 从线上库删除，制作库变回未发表
+	Unpublish(ctx context.Context, id int64) error
+	// Delete 从线上库删除，制作库放入回收站
+	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	TrashList(ctx context.Context, offset int, limit int) ([]domain.Case, error)
+	TrashTotal(ctx context.Context) (int64, error)
+	// ListDeletedBefore 在 t 之前放入回收站的案例
+	ListDeletedBefore(ctx context.Context, t time.Time, limit int) ([]domain.Case, error)
+	// Purge 彻底删除回收站里面的案例
+	Purge(ctx context.Context, id int64) error
 	// 管理端接口
 	List(ctx context.Context, offset int, limit int) ([]domain.Case, error)
 	Total(ctx context.Context) (int64, error)
@@ -29,8 +48,16 @@ type caseRepo struct {
 	caseDao dao.CaseDAO
 }
 
-func (c *caseRepo) PubList(ctx context.Context, offset int, limit int) ([]domain.Case, error) {
-	caseList, err := c.caseDao.PublishCaseList(ctx, offset, limit)
+func (c *caseRepo) PubList(ctx context.Context, offset int, limit int, filter label.Filter) ([]domain.Case, error) {
+	var (
+		caseList []dao.PublishCase
+		err      error
+	)
+	if filter.IsEmpty() {
+		caseList, err = c.caseDao.PublishCaseList(ctx, offset, limit)
+	} else {
+		caseList, err = c.caseDao.PublishCaseListByLabels(ctx, offset, limit, filter.DistinctLabelIds(), filter.MatchAll())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +68,24 @@ func (c *caseRepo) PubList(ctx context.Context, offset int, limit int) ([]domain
 	return domainCases, nil
 }
 
+func (c *caseRepo) PubListAfter(ctx context.Context, id int64, limit int) ([]domain.Case, error) {
+	caseList, err := c.caseDao.PublishCaseListAfter(ctx, id, limit)
+	return slice.Map(caseList, func(idx int, src dao.PublishCase) domain.Case {
+		return c.toDomain(dao.Case(src))
+	}), err
+}
+
+func (c *caseRepo) PubLabelCounts(ctx context.Context) ([]label.Facet, error) {
+	cnts, err := c.caseDao.PubLabelCounts(ctx)
+	return slice.Map(cnts, func(idx int, src dao.LabelCount) label.Facet {
+		return label.Facet{LabelId: src.LabelId, Cnt: src.Cnt}
+	}), err
+}
+
+func (c *caseRepo) SyncLabels(ctx context.Context, cid int64, labelIds []int64) error {
+	return c.caseDao.SyncLabels(ctx, cid, labelIds)
+}
+
 func (c *caseRepo) GetPubByID(ctx context.Context, caseId int64) (domain.Case, error) {
 	caseInfo, err := c.caseDao.GetPublishCase(ctx, caseId)
 	if err != nil {
@@ -61,6 +106,40 @@ func (c *caseRepo) Sync(ctx context.Context, ca domain.Case) (int64, error) {
 	return c.caseDao.Sync(ctx, caseModel)
 }
 
+func (c *caseRepo) Unpublish(ctx context.Context, id int64) error {
+	return c.caseDao.Unpublish(ctx, id)
+}
+
+func (c *caseRepo) Delete(ctx context.Context, id int64) error {
+	return c.caseDao.Delete(ctx, id)
+}
+
+func (c *caseRepo) Restore(ctx context.Context, id int64) error {
+	return c.caseDao.Restore(ctx, id)
+}
+
+func (c *caseRepo) TrashList(ctx context.Context, offset int, limit int) ([]domain.Case, error) {
+	caseList, err := c.caseDao.TrashList(ctx, offset, limit)
+	return slice.Map(caseList, func(idx int, src dao.Case) domain.Case {
+		return c.toDomain(src)
+	}), err
+}
+
+func (c *caseRepo) TrashTotal(ctx context.Context) (int64, error) {
+	return c.caseDao.TrashCount(ctx)
+}
+
+func (c *caseRepo) ListDeletedBefore(ctx context.Context, t time.Time, limit int) ([]domain.Case, error) {
+	caseList, err := c.caseDao.ListDeletedBefore(ctx, t.UnixMilli(), limit)
+	return slice.Map(caseList, func(idx int, src dao.Case) domain.Case {
+		return c.toDomain(src)
+	}), err
+}
+
+func (c *caseRepo) Purge(ctx context.Context, id int64) error {
+	return c.caseDao.Purge(ctx, id)
+}
+
 func (c *caseRepo) List(ctx context.Context, offset int, limit int) ([]domain.Case, error) {
 	caseList, err := c.caseDao.List(ctx, offset, limit)
 	if err != nil {
@@ -0,0 +1,161 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/ego-component/egorm"
+	"gorm.io/gorm"
+)
+
+type CaseSetDAO interface {
+	Create(ctx context.Context, cs CaseSet) (int64, error)
+	UpdateNonZero(ctx context.Context, cs CaseSet) error
+	GetByID(ctx context.Context, id int64) (CaseSet, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]CaseSet, error)
+	// GetByBiz 同一个业务绑定了多个案例集的时候，返回最近更新的
+	GetByBiz(ctx context.Context, biz string, bizId int64) (CaseSet, error)
+	// GetCasesByID 按照加入案例集的顺序返回
+	GetCasesByID(ctx context.Context, id int64) ([]Case, error)
+	// GetPubCasesByID 按照加入案例集的顺序返回线上库的案例，没有发布的案例会被跳过
+	GetPubCasesByID(ctx context.Context, id int64) ([]PublishCase, error)
+	// UpdateCasesByID 覆盖案例集里面的案例，cids 的顺序就是展示的顺序
+	UpdateCasesByID(ctx context.Context, id int64, cids []int64) error
+
+	Count(ctx context.Context) (int64, error)
+	List(ctx context.Context, offset, limit int) ([]CaseSet, error)
+}
+
+type GORMCaseSetDAO struct {
+	db *egorm.Component
+}
+
+func (g *GORMCaseSetDAO) Create(ctx context.Context, cs CaseSet) (int64, error) {
+	now := time.Now().UnixMilli()
+	cs.Ctime = now
+	cs.Utime = now
+	err := g.db.WithContext(ctx).Create(&cs).Error
+	return cs.Id, err
+}
+
+func (g *GORMCaseSetDAO) UpdateNonZero(ctx context.Context, cs CaseSet) error {
+	cs.Utime = time.Now().UnixMilli()
+	return g.db.WithContext(ctx).Where("id = ?", cs.Id).Updates(cs).Error
+}
+
+func (g *GORMCaseSetDAO) GetByID(ctx context.Context, id int64) (CaseSet, error) {
+	var cs CaseSet
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&cs).Error
+	return cs, err
+}
+
+func (g *GORMCaseSetDAO) GetByIDs(ctx context.Context, ids []int64) ([]CaseSet, error) {
+	var res []CaseSet
+	err := g.db.WithContext(ctx).Where("id IN ?", ids).Find(&res).Error
+	return res, err
+}
+
+func (g *GORMCaseSetDAO) GetByBiz(ctx context.Context, biz string, bizId int64) (CaseSet, error) {
+	var cs CaseSet
+	err := g.db.WithContext(ctx).Where("biz = ? AND biz_id = ?", biz, bizId).
+		Order("utime DESC").
+		First(&cs).Error
+	return cs, err
+}
+
+func (g *GORMCaseSetDAO) GetCasesByID(ctx context.Context, id int64) ([]Case, error) {
+	var cscs []CaseSetCase
+	db := g.db.WithContext(ctx)
+	err := db.Where("cs_id = ?", id).Order("id ASC").Find(&cscs).Error
+	if err != nil || len(cscs) == 0 {
+		return nil, err
+	}
+	cids := make([]int64, 0, len(cscs))
+	for _, csc := range cscs {
+		cids = append(cids, csc.CID)
+	}
+	var cases []Case
+	err = db.Where("id IN ?", cids).Find(&cases).Error
+	if err != nil {
+		return nil, err
+	}
+	caseMap := make(map[int64]Case, len(cases))
+	for _, ca := range cases {
+		caseMap[ca.Id] = ca
+	}
+	res := make([]Case, 0, len(cases))
+	for _, cid := range cids {
+		// 案例可能已经被删除了
+		if ca, ok := caseMap[cid]; ok {
+			res = append(res, ca)
+		}
+	}
+	return res, nil
+}
+
+func (g *GORMCaseSetDAO) GetPubCasesByID(ctx context.Context, id int64) ([]PublishCase, error) {
+	var res []PublishCase
+	err := g.db.WithContext(ctx).
+		Joins("JOIN case_set_cases ON case_set_cases.cid = publish_cases.id").
+		Where("case_set_cases.cs_id = ?", id).
+		Order("case_set_cases.id ASC").
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMCaseSetDAO) UpdateCasesByID(ctx context.Context, id int64, cids []int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var cs CaseSet
+		if err := tx.Where("id = ?", id).First(&cs).Error; err != nil {
+			return err
+		}
+		// 全部删除，而后按照新的顺序重新创建
+		if err := tx.Where("cs_id = ?", id).Delete(&CaseSetCase{}).Error; err != nil {
+			return err
+		}
+		if len(cids) == 0 {
+			return nil
+		}
+		now := time.Now().UnixMilli()
+		cscs := make([]CaseSetCase, 0, len(cids))
+		for _, cid := range cids {
+			cscs = append(cscs, CaseSetCase{
+				CSID:  id,
+				CID:   cid,
+				Ctime: now,
+				Utime: now,
+			})
+		}
+		return tx.Create(&cscs).Error
+	})
+}
+
+func (g *GORMCaseSetDAO) Count(ctx context.Context) (int64, error) {
+	var res int64
+	err := g.db.WithContext(ctx).Model(&CaseSet{}).Select("COUNT(id)").Count(&res).Error
+	return res, err
+}
+
+func (g *GORMCaseSetDAO) List(ctx context.Context, offset, limit int) ([]CaseSet, error) {
+	var res []CaseSet
+	err := g.db.WithContext(ctx).Offset(offset).Limit(limit).Order("id DESC").Find(&res).Error
+	return res, err
+}
+
+func NewGORMCaseSetDAO(db *egorm.Component) CaseSetDAO {
+	return &GORMCaseSetDAO{db: db}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+
 	"gorm.io/gorm/clause"
 
 	"github.com/ego-component/egorm"
@@ -18,12 +21,32 @@ type CaseDAO interface {
 	Count(ctx context.Context) (int64, error)
 
 	Sync(ctx context.Context, c Case) (int64, error)
+	// Unpublish 从线上库删除，制作库的数据变回未发表
+	Unpublish(ctx context.Context, id int64) error
+	// Delete 从线上库删除，制作库的数据放入回收站
+	Delete(ctx context.Context, id int64) error
+	// Restore 将回收站里面的数据恢复成未发表
+	Restore(ctx context.Context, id int64) error
+	TrashList(ctx context.Context, offset, limit int) ([]Case, error)
+	TrashCount(ctx context.Context) (int64, error)
+	// ListDeletedBefore 回收站里面在 utime 之前删除的数据
+	ListDeletedBefore(ctx context.Context, utime int64, limit int) ([]Case, error)
+	// Purge 彻底删除回收站里面的数据
+	Purge(ctx context.Context, id int64) error
 
 	// 线上库
 	PublishCaseList(ctx context.Context, offset, limit int) ([]PublishCase, error)
+	// PublishCaseListAfter 按照 id 升序翻页，返回 id 之后的数据
+	PublishCaseListAfter(ctx context.Context, id int64, limit int) ([]PublishCase, error)
 	PublishCaseCount(ctx context.Context) (int64, error)
 	GetPublishCase(ctx context.Context, caseId int64) (PublishCase, error)
 	GetPubByIDs(ctx context.Context, ids []int64) ([]PublishCase, error)
+	// PublishCaseListByLabels 线上库里面命中标签的案例，all 为 true 的时候要求同时命中全部标签，labelIds 需要去重
+	PublishCaseListByLabels(ctx context.Context, offset, limit int, labelIds []int64, all bool) ([]PublishCase, error)
+	// PubLabelCounts 线上库里面每个标签关联的案例数量
+	PubLabelCounts(ctx context.Context) ([]LabelCount, error)
+	// SyncLabels 用 labelIds 覆盖线上库案例关联的标签
+	SyncLabels(ctx context.Context, cid int64, labelIds []int64) error
 }
 
 type caseDAO struct {
@@ -34,7 +57,9 @@ type caseDAO struct {
 
 func (ca *caseDAO) Count(ctx context.Context) (int64, error) {
 	var res int64
-	err := ca.db.WithContext(ctx).Model(&Case{}).Select("COUNT(id)").Count(&res).Error
+	err := ca.db.WithContext(ctx).Model(&Case{}).
+		Where("status <> ?", domain.DeletedStatus.ToUint8()).
+		Select("COUNT(id)").Count(&res).Error
 	return res, err
 }
 
@@ -62,6 +87,7 @@ func (ca *caseDAO) List(ctx context.Context, offset, limit int) ([]Case, error)
 	var caseList []Case
 	err := ca.db.WithContext(ctx).
 		Select(ca.listColumns).
+		Where("status <> ?", domain.DeletedStatus.ToUint8()).
 		Order("id desc").
 		Offset(offset).
 		Limit(limit).
@@ -85,6 +111,85 @@ func (ca *caseDAO) Sync(ctx context.Context, c Case) (int64, error) {
 	return id, err
 }
 
+func (ca *caseDAO) Unpublish(ctx context.Context, id int64) error {
+	return ca.takeDown(ctx, id, domain.UnPublishedStatus)
+}
+
+func (ca *caseDAO) Delete(ctx context.Context, id int64) error {
+	return ca.takeDown(ctx, id, domain.DeletedStatus)
+}
+
+// takeDown 删除线上库的案例以及关联的标签，并且更新制作库的状态
+// 已经在回收站里面的案例不会被改回未发表
+func (ca *caseDAO) takeDown(ctx context.Context, id int64, status domain.CaseStatus) error {
+	return ca.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ?", id).Delete(&PublishCase{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("cid = ?", id).Delete(&PublishCaseLabel{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&Case{}).
+			Where("id = ? AND status <> ?", id, domain.DeletedStatus.ToUint8()).
+			Updates(map[string]any{
+				"status": status.ToUint8(),
+				"utime":  time.Now().UnixMilli(),
+			}).Error
+	})
+}
+
+func (ca *caseDAO) Restore(ctx context.Context, id int64) error {
+	return ca.db.WithContext(ctx).Model(&Case{}).
+		Where("id = ? AND status = ?", id, domain.DeletedStatus.ToUint8()).
+		Updates(map[string]any{
+			"status": domain.UnPublishedStatus.ToUint8(),
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (ca *caseDAO) TrashList(ctx context.Context, offset, limit int) ([]Case, error) {
+	var caseList []Case
+	err := ca.db.WithContext(ctx).
+		Select(ca.listColumns).
+		Where("status = ?", domain.DeletedStatus.ToUint8()).
+		Order("utime desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&caseList).Error
+	return caseList, err
+}
+
+func (ca *caseDAO) TrashCount(ctx context.Context) (int64, error) {
+	var res int64
+	err := ca.db.WithContext(ctx).Model(&Case{}).
+		Where("status = ?", domain.DeletedStatus.ToUint8()).
+		Select("COUNT(id)").Count(&res).Error
+	return res, err
+}
+
+func (ca *caseDAO) ListDeletedBefore(ctx context.Context, utime int64, limit int) ([]Case, error) {
+	var caseList []Case
+	err := ca.db.WithContext(ctx).
+		Select(ca.listColumns).
+		Where("status = ? AND utime < ?", domain.DeletedStatus.ToUint8(), utime).
+		Order("utime asc").
+		Limit(limit).
+		Find(&caseList).Error
+	return caseList, err
+}
+
+func (ca *caseDAO) Purge(ctx context.Context, id int64) error {
+	return ca.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND status = ?", id, domain.DeletedStatus.ToUint8()).Delete(&Case{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Where("cid = ?", id).Delete(&CaseSetCase{}).Error
+	})
+}
+
 func (ca *caseDAO) PublishCaseList(ctx context.Context, offset, limit int) ([]PublishCase, error) {
 	publishCaseList := make([]PublishCase, 0, limit)
 	err := ca.db.WithContext(ctx).
@@ -96,6 +201,61 @@ func (ca *caseDAO) PublishCaseList(ctx context.Context, offset, limit int) ([]Pu
 	return publishCaseList, err
 }
 
+func (ca *caseDAO) PublishCaseListAfter(ctx context.Context, id int64, limit int) ([]PublishCase, error) {
+	publishCaseList := make([]PublishCase, 0, limit)
+	err := ca.db.WithContext(ctx).
+		Where("id > ?", id).
+		Order("id asc").
+		Select(ca.listColumns).
+		Limit(limit).
+		Find(&publishCaseList).Error
+	return publishCaseList, err
+}
+
+func (ca *caseDAO) PublishCaseListByLabels(ctx context.Context, offset, limit int, labelIds []int64, all bool) ([]PublishCase, error) {
+	publishCaseList := make([]PublishCase, 0, limit)
+	db := ca.db.WithContext(ctx)
+	sub := db.Model(&PublishCaseLabel{}).Select("cid").Where("label_id IN ?", labelIds)
+	if all {
+		sub = sub.Group("cid").Having("COUNT(DISTINCT label_id) = ?", len(labelIds))
+	}
+	err := db.Where("id IN (?)", sub).
+		Order("id desc").
+		Select(ca.listColumns).
+		Offset(offset).
+		Limit(limit).
+		Find(&publishCaseList).Error
+	return publishCaseList, err
+}
+
+func (ca *caseDAO) PubLabelCounts(ctx context.Context) ([]LabelCount, error) {
+	var res []LabelCount
+	err := ca.db.WithContext(ctx).Model(&PublishCaseLabel{}).
+		Select("label_id, COUNT(*) AS cnt").
+		Group("label_id").
+		Order("cnt DESC, label_id ASC").
+		Scan(&res).Error
+	return res, err
+}
+
+func (ca *caseDAO) SyncLabels(ctx context.Context, cid int64, labelIds []int64) error {
+	return ca.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		del := tx.Where("cid = ?", cid)
+		if len(labelIds) > 0 {
+			del = del.Where("label_id NOT IN ?", labelIds)
+		}
+		err := del.Delete(&PublishCaseLabel{}).Error
+		if err != nil || len(labelIds) == 0 {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		rels := slice.Map(labelIds, func(idx int, src int64) PublishCaseLabel {
+			return PublishCaseLabel{Cid: cid, LabelId: src, Ctime: now}
+		})
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rels).Error
+	})
+}
+
 func (ca *caseDAO) PublishCaseCount(ctx context.Context) (int64, error) {
 	var res int64
 	err := ca.db.WithContext(ctx).Model(&PublishCase{}).Select("COUNT(id)").Count(&res).Error
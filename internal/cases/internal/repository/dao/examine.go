@@ -0,0 +1,87 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/ego-component/egorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrRecordNotFound = gorm.ErrRecordNotFound
+
+type ExamineDAO interface {
+	// SaveResult 保存测试记录，同时更新 CaseResult
+	SaveResult(ctx context.Context, record CaseExamineRecord) error
+	// CreateRecord 只保存测试记录，不会修改 CaseResult
+	CreateRecord(ctx context.Context, record CaseExamineRecord) error
+	GetResultByUidAndCid(ctx context.Context, uid int64, cid int64) (CaseResult, error)
+	GetResultsByUidAndCids(ctx context.Context, uid int64, cids []int64) ([]CaseResult, error)
+}
+
+var _ ExamineDAO = &GORMExamineDAO{}
+
+type GORMExamineDAO struct {
+	db *egorm.Component
+}
+
+func (dao *GORMExamineDAO) SaveResult(ctx context.Context, record CaseExamineRecord) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		record.Ctime = now
+		record.Utime = now
+		err := tx.Create(&record).Error
+		if err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			// 如果有记录了，就更新结果和更新时间
+			DoUpdates: clause.AssignmentColumns([]string{
+				"result", "utime",
+			}),
+		}).Create(&CaseResult{
+			Uid:    record.Uid,
+			Cid:    record.Cid,
+			Result: record.Result,
+			Ctime:  now,
+			Utime:  now,
+		}).Error
+	})
+}
+
+func (dao *GORMExamineDAO) CreateRecord(ctx context.Context, record CaseExamineRecord) error {
+	now := time.Now().UnixMilli()
+	record.Ctime = now
+	record.Utime = now
+	return dao.db.WithContext(ctx).Create(&record).Error
+}
+
+func (dao *GORMExamineDAO) GetResultByUidAndCid(ctx context.Context, uid int64, cid int64) (CaseResult, error) {
+	var res CaseResult
+	err := dao.db.WithContext(ctx).Where("uid = ? AND cid = ?", uid, cid).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMExamineDAO) GetResultsByUidAndCids(ctx context.Context, uid int64, cids []int64) ([]CaseResult, error) {
+	var res []CaseResult
+	err := dao.db.WithContext(ctx).Where("uid = ? AND cid IN ?", uid, cids).Find(&res).Error
+	return res, err
+}
+
+func NewGORMExamineDAO(db *egorm.Component) ExamineDAO {
+	return &GORMExamineDAO{db: db}
+}
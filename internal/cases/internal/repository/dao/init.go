@@ -6,5 +6,10 @@ func InitTables(db *egorm.Component) error {
 	return db.AutoMigrate(
 		&Case{},
 		&PublishCase{},
+		&PublishCaseLabel{},
+		&CaseSet{},
+		&CaseSetCase{},
+		&CaseExamineRecord{},
+		&CaseResult{},
 	)
 }
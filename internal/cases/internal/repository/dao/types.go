@@ -22,7 +22,7 @@ type Case struct {
 	Highlight string
 	// 引导点
 	Guidance string
-	Status   uint8 `gorm:"type:tinyint(3);comment:0-未知 1-未发表 2-已发表"`
+	Status   uint8 `gorm:"type:tinyint(3);comment:0-未知 1-未发表 2-已发表 3-已删除"`
 	Ctime    int64
 	Utime    int64 `gorm:"index"`
 }
@@ -36,3 +36,74 @@ type PublishCase Case
 func (PublishCase) TableName() string {
 	return "publish_cases"
 }
+
+// PublishCaseLabel 线上库案例和 label 模块里面的标签的关联关系
+type PublishCaseLabel struct {
+	Id      int64 `gorm:"primaryKey,autoIncrement"`
+	Cid     int64 `gorm:"uniqueIndex:cid_label_id"`
+	LabelId int64 `gorm:"uniqueIndex:cid_label_id;index"`
+	Ctime   int64
+}
+
+// LabelCount 某个标签关联的案例数量
+type LabelCount struct {
+	LabelId int64
+	Cnt     int64
+}
+
+// CaseSet 案例集
+type CaseSet struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 所有者
+	Uid int64 `gorm:"index"`
+	// 案例集标题
+	Title string
+	// 案例集描述
+	Description string
+
+	// 和题集一样，案例集的 Biz 和 BizId 可以和内部的 Case 无关
+	Biz   string `gorm:"type:varchar(256);index:biz;not null;default:''"`
+	BizId int64  `gorm:"index:biz;not null;default:0"`
+
+	Ctime int64
+	Utime int64 `gorm:"index"`
+}
+
+// CaseSetCase 案例集与案例的关联关系，按照 Id 的顺序展示
+type CaseSetCase struct {
+	Id    int64 `gorm:"primaryKey,autoIncrement"`
+	CSID  int64 `gorm:"column:cs_id;uniqueIndex:csid_cid"`
+	CID   int64 `gorm:"column:cid;uniqueIndex:csid_cid"`
+	Ctime int64
+	Utime int64 `gorm:"index"`
+}
+
+// CaseExamineRecord 每一次案例测试的记录
+type CaseExamineRecord struct {
+	Id  int64
+	Uid int64 `gorm:"index:uid_cid"`
+	Cid int64 `gorm:"index:uid_cid"`
+	// 代表这一次测试的 ID，和 AI 打交道的唯一凭证
+	Tid    string `gorm:"type:varchar(64);index"`
+	Result uint8
+	// 原始的 AI 回答
+	RawResult string
+	// 冗余字段，使用的 tokens 数量
+	Tokens int64
+	// 冗余字段，花费的金额
+	Amount int64
+	// 用户的回答
+	Input string `gorm:"type:text"`
+	Ctime int64
+	Utime int64
+}
+
+// CaseResult 某人最近一次测试某个案例的结果
+type CaseResult struct {
+	Id     int64
+	Uid    int64 `gorm:"uniqueIndex:uid_cid"`
+	Cid    int64 `gorm:"uniqueIndex:uid_cid"`
+	Result uint8
+	Ctime  int64
+	Utime  int64
+}
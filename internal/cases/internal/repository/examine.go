@@ -0,0 +1,81 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+	"github.com/ecodeclub/webook/internal/cases/internal/repository/dao"
+)
+
+type ExamineRepository interface {
+	SaveResult(ctx context.Context, uid int64, input string, result domain.ExamineResult) error
+	// SaveRecord 只保存测试记录，用户的测试结果保持不变
+	SaveRecord(ctx context.Context, uid int64, input string, result domain.ExamineResult) error
+	// GetResultByUidAndCid 没有测试过的时候返回 ResultFailed
+	GetResultByUidAndCid(ctx context.Context, uid int64, cid int64) (domain.Result, error)
+	GetResultsByIds(ctx context.Context, uid int64, cids []int64) ([]domain.ExamineResult, error)
+}
+
+var _ ExamineRepository = &examineRepository{}
+
+type examineRepository struct {
+	dao dao.ExamineDAO
+}
+
+func (repo *examineRepository) SaveResult(ctx context.Context, uid int64, input string, result domain.ExamineResult) error {
+	return repo.dao.SaveResult(ctx, repo.toRecordEntity(uid, input, result))
+}
+
+func (repo *examineRepository) SaveRecord(ctx context.Context, uid int64, input string, result domain.ExamineResult) error {
+	return repo.dao.CreateRecord(ctx, repo.toRecordEntity(uid, input, result))
+}
+
+func (repo *examineRepository) GetResultByUidAndCid(ctx context.Context, uid int64, cid int64) (domain.Result, error) {
+	res, err := repo.dao.GetResultByUidAndCid(ctx, uid, cid)
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		return domain.ResultFailed, nil
+	}
+	return domain.Result(res.Result), err
+}
+
+func (repo *examineRepository) GetResultsByIds(ctx context.Context, uid int64, cids []int64) ([]domain.ExamineResult, error) {
+	res, err := repo.dao.GetResultsByUidAndCids(ctx, uid, cids)
+	return slice.Map(res, func(idx int, src dao.CaseResult) domain.ExamineResult {
+		return domain.ExamineResult{
+			Cid:    src.Cid,
+			Result: domain.Result(src.Result),
+		}
+	}), err
+}
+
+func (repo *examineRepository) toRecordEntity(uid int64, input string, result domain.ExamineResult) dao.CaseExamineRecord {
+	return dao.CaseExamineRecord{
+		Uid:       uid,
+		Cid:       result.Cid,
+		Tid:       result.Tid,
+		Result:    result.Result.ToUint8(),
+		RawResult: result.RawResult,
+		Tokens:    result.Tokens,
+		Amount:    result.Amount,
+		Input:     input,
+	}
+}
+
+func NewExamineRepository(dao dao.ExamineDAO) ExamineRepository {
+	return &examineRepository{dao: dao}
+}
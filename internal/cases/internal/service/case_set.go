@@ -0,0 +1,154 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+	"github.com/ecodeclub/webook/internal/cases/internal/event"
+	"github.com/ecodeclub/webook/internal/cases/internal/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"golang.org/x/sync/errgroup"
+)
+
+// CaseSetService 和题集一样，还没有分离制作库和线上库
+//
+//go:generate mockgen -source=./case_set.go -destination=../../mocks/case_set.mock.go -package=casemocks -typed CaseSetService
+type CaseSetService interface {
+	Save(ctx context.Context, set domain.CaseSet) (int64, error)
+	// UpdateCases 覆盖式更新，set.Cases 就是案例集最终的案例以及顺序
+	UpdateCases(ctx context.Context, set domain.CaseSet) error
+	List(ctx context.Context, offset, limit int) ([]domain.CaseSet, int64, error)
+	Detail(ctx context.Context, id int64) (domain.CaseSet, error)
+	// PubDetail 案例集里面只有已经发布的案例，内容来自线上库
+	PubDetail(ctx context.Context, id int64) (domain.CaseSet, error)
+	// PubDetailByBiz 和 PubDetail 一样，只有已经发布的案例
+	PubDetailByBiz(ctx context.Context, biz string, bizId int64) (domain.CaseSet, error)
+	// GetByIds 只有基本信息，不包括案例
+	GetByIds(ctx context.Context, ids []int64) ([]domain.CaseSet, error)
+}
+
+type caseSetService struct {
+	repo         repository.CaseSetRepository
+	producer     event.SyncEventProducer
+	intrProducer event.InteractiveEventProducer
+	logger       *elog.Component
+	syncTimeout  time.Duration
+}
+
+func (c *caseSetService) Save(ctx context.Context, set domain.CaseSet) (int64, error) {
+	id := set.Id
+	var err error
+	if id > 0 {
+		err = c.repo.UpdateNonZero(ctx, set)
+	} else {
+		id, err = c.repo.Create(ctx, set)
+	}
+	if err != nil {
+		return 0, err
+	}
+	c.syncCaseSet(id)
+	return id, nil
+}
+
+func (c *caseSetService) UpdateCases(ctx context.Context, set domain.CaseSet) error {
+	err := c.repo.UpdateCases(ctx, set)
+	if err != nil {
+		return err
+	}
+	c.syncCaseSet(set.Id)
+	return nil
+}
+
+func (c *caseSetService) List(ctx context.Context, offset, limit int) ([]domain.CaseSet, int64, error) {
+	var (
+		eg    errgroup.Group
+		sets  []domain.CaseSet
+		total int64
+	)
+	eg.Go(func() error {
+		var err error
+		sets, err = c.repo.List(ctx, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		total, err = c.repo.Total(ctx)
+		return err
+	})
+	return sets, total, eg.Wait()
+}
+
+func (c *caseSetService) Detail(ctx context.Context, id int64) (domain.CaseSet, error) {
+	return c.repo.GetByID(ctx, id)
+}
+
+func (c *caseSetService) PubDetail(ctx context.Context, id int64) (domain.CaseSet, error) {
+	set, err := c.repo.GetPubByID(ctx, id)
+	if err == nil {
+		go func() {
+			newCtx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			defer cancel()
+			err1 := c.intrProducer.Produce(newCtx, event.NewViewCntEvent(id, domain.BizCaseSet))
+			if err1 != nil {
+				c.logger.Error("发送案例集阅读计数消息到消息队列失败",
+					elog.FieldErr(err1), elog.Int64("csid", id))
+			}
+		}()
+	}
+	return set, err
+}
+
+func (c *caseSetService) PubDetailByBiz(ctx context.Context, biz string, bizId int64) (domain.CaseSet, error) {
+	return c.repo.GetPubByBiz(ctx, biz, bizId)
+}
+
+func (c *caseSetService) GetByIds(ctx context.Context, ids []int64) ([]domain.CaseSet, error) {
+	return c.repo.GetByIDs(ctx, ids)
+}
+
+func (c *caseSetService) syncCaseSet(id int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.syncTimeout)
+	defer cancel()
+	// 搜索是给 C 端用的，所以只同步已经发布的案例
+	set, err := c.repo.GetPubByID(ctx, id)
+	if err != nil {
+		c.logger.Error("查询案例集详情失败",
+			elog.FieldErr(err),
+			elog.Int64("csid", id))
+		return
+	}
+	evt := event.NewCaseSetEvent(set)
+	err = c.producer.Produce(ctx, evt)
+	if err != nil {
+		c.logger.Error("发送案例集内容到搜索失败",
+			elog.FieldErr(err),
+			elog.Any("event", evt))
+	}
+}
+
+func NewCaseSetService(repo repository.CaseSetRepository,
+	intrProducer event.InteractiveEventProducer,
+	producer event.SyncEventProducer) CaseSetService {
+	return &caseSetService{
+		repo:         repo,
+		producer:     producer,
+		intrProducer: intrProducer,
+		logger:       elog.DefaultLogger,
+		syncTimeout:  10 * time.Second,
+	}
+}
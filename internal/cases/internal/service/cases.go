@@ -2,13 +2,16 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/webook/internal/cases/internal/event"
 	"github.com/gotomicro/ego/core/elog"
 
 	"github.com/ecodeclub/webook/internal/cases/internal/domain"
 	"github.com/ecodeclub/webook/internal/cases/internal/repository"
+	"github.com/ecodeclub/webook/internal/label"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -19,14 +22,32 @@ type Service interface {
 	Publish(ctx context.Context, ca domain.Case) (int64, error)
 	List(ctx context.Context, offset int, limit int) ([]domain.Case, int64, error)
 
-	PubList(ctx context.Context, offset int, limit int) ([]domain.Case, error)
+	// PubList filter 为空的时候不按照标签筛选
+	PubList(ctx context.Context, offset int, limit int, filter label.Filter) ([]domain.Case, error)
+	// PubListAfter 按照 id 升序翻页，返回 id 之后的案例，只有基础信息
+	PubListAfter(ctx context.Context, id int64, limit int) ([]domain.Case, error)
+	// PubLabelFacets 每个标签下的案例数量，和筛选条件无关
+	PubLabelFacets(ctx context.Context) ([]label.Facet, error)
+	// SyncLabels 将案例的字符串标签关联到 label 模块的标签上，不存在的标签会被创建
+	SyncLabels(ctx context.Context, cid int64, labels []string) error
 	GetPubByIDs(ctx context.Context, ids []int64) ([]domain.Case, error)
 	Detail(ctx context.Context, caseId int64) (domain.Case, error)
 	PubDetail(ctx context.Context, caseId int64) (domain.Case, error)
+
+	// Unpublish 下线案例，制作库的数据会保留
+	Unpublish(ctx context.Context, caseId int64) error
+	// Delete 下线案例并且放入回收站
+	Delete(ctx context.Context, caseId int64) error
+	// Restore 从回收站恢复，恢复之后是未发表的状态，需要重新发布
+	Restore(ctx context.Context, caseId int64) error
+	TrashList(ctx context.Context, offset int, limit int) ([]domain.Case, int64, error)
+	// PurgeTrash 彻底删除在 before 之前放入回收站的案例，最多删除 limit 个，返回删除的数量
+	PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error)
 }
 
 type service struct {
 	repo         repository.CaseRepo
+	labelSvc     label.Service
 	producer     event.SyncEventProducer
 	intrProducer event.InteractiveEventProducer
 	logger       *elog.Component
@@ -46,6 +67,13 @@ func (s *service) Publish(ctx context.Context, ca domain.Case) (int64, error) {
 	ca.Status = domain.PublishedStatus
 	id, err := s.repo.Sync(ctx, ca)
 	if err == nil {
+		// 案例已经发布成功了，标签关联失败不影响发布，可以通过 sync-case-labels 任务修复
+		err1 := s.SyncLabels(ctx, id, ca.Labels)
+		if err1 != nil {
+			s.logger.Error("关联案例标签失败",
+				elog.FieldErr(err1),
+				elog.Int64("cid", id))
+		}
 		go func() {
 			s.syncCase(id)
 		}()
@@ -53,6 +81,76 @@ func (s *service) Publish(ctx context.Context, ca domain.Case) (int64, error) {
 	return id, nil
 }
 
+func (s *service) Unpublish(ctx context.Context, caseId int64) error {
+	err := s.repo.Unpublish(ctx, caseId)
+	if err == nil {
+		go func() {
+			s.removeFromSearch(caseId)
+		}()
+	}
+	return err
+}
+
+func (s *service) Delete(ctx context.Context, caseId int64) error {
+	err := s.repo.Delete(ctx, caseId)
+	if err == nil {
+		go func() {
+			s.removeFromSearch(caseId)
+		}()
+	}
+	return err
+}
+
+func (s *service) Restore(ctx context.Context, caseId int64) error {
+	return s.repo.Restore(ctx, caseId)
+}
+
+func (s *service) TrashList(ctx context.Context, offset int, limit int) ([]domain.Case, int64, error) {
+	var (
+		total    int64
+		caseList []domain.Case
+		eg       errgroup.Group
+	)
+	eg.Go(func() error {
+		var err error
+		caseList, err = s.repo.TrashList(ctx, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		total, err = s.repo.TrashTotal(ctx)
+		return err
+	})
+	return caseList, total, eg.Wait()
+}
+
+func (s *service) PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error) {
+	caseList, err := s.repo.ListDeletedBefore(ctx, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, ca := range caseList {
+		err = s.repo.Purge(ctx, ca.Id)
+		if err != nil {
+			return 0, fmt.Errorf("彻底删除案例 %d 失败 %w", ca.Id, err)
+		}
+		// 数据已经删除了，通知失败只影响其它模块的残留数据
+		err1 := s.intrProducer.Produce(ctx, event.NewInteractiveDeleteEvent(ca.Id, domain.BizCase))
+		if err1 != nil {
+			s.logger.Error("发送案例删除消息到互动失败",
+				elog.FieldErr(err1),
+				elog.Int64("cid", ca.Id))
+		}
+		err1 = s.producer.Produce(ctx, event.NewSyncDeleteEvent(domain.BizCase, ca.Id))
+		if err1 != nil {
+			s.logger.Error("发送案例删除消息到搜索失败",
+				elog.FieldErr(err1),
+				elog.Int64("cid", ca.Id))
+		}
+	}
+	return len(caseList), nil
+}
+
 func (s *service) List(ctx context.Context, offset int, limit int) ([]domain.Case, int64, error) {
 	var (
 		total    int64
@@ -75,8 +173,30 @@ func (s *service) List(ctx context.Context, offset int, limit int) ([]domain.Cas
 	return caseList, total, nil
 }
 
-func (s *service) PubList(ctx context.Context, offset int, limit int) ([]domain.Case, error) {
-	return s.repo.PubList(ctx, offset, limit)
+func (s *service) PubList(ctx context.Context, offset int, limit int, filter label.Filter) ([]domain.Case, error) {
+	return s.repo.PubList(ctx, offset, limit, filter)
+}
+
+func (s *service) PubListAfter(ctx context.Context, id int64, limit int) ([]domain.Case, error) {
+	return s.repo.PubListAfter(ctx, id, limit)
+}
+
+func (s *service) PubLabelFacets(ctx context.Context) ([]label.Facet, error) {
+	facets, err := s.repo.PubLabelCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.labelSvc.FillFacetNames(ctx, facets)
+}
+
+func (s *service) SyncLabels(ctx context.Context, cid int64, labels []string) error {
+	ls, err := s.labelSvc.GetOrCreateSystemLabels(ctx, labels)
+	if err != nil {
+		return err
+	}
+	return s.repo.SyncLabels(ctx, cid, slice.Map(ls, func(idx int, src label.Label) int64 {
+		return src.Id
+	}))
 }
 
 func (s *service) Detail(ctx context.Context, caseId int64) (domain.Case, error) {
@@ -104,10 +224,12 @@ func (s *service) PubDetail(ctx context.Context, caseId int64) (domain.Case, err
 }
 
 func NewService(repo repository.CaseRepo,
+	labelSvc label.Service,
 	intrProducer event.InteractiveEventProducer,
 	producer event.SyncEventProducer) Service {
 	return &service{
 		repo:         repo,
+		labelSvc:     labelSvc,
 		producer:     producer,
 		intrProducer: intrProducer,
 		logger:       elog.DefaultLogger,
@@ -134,3 +256,16 @@ func (s *service) syncCase(id int64) {
 		)
 	}
 }
+
+func (s *service) removeFromSearch(id int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.syncTimeout)
+	defer cancel()
+	evt := event.NewSyncDeleteEvent(domain.BizCase, id)
+	err := s.producer.Produce(ctx, evt)
+	if err != nil {
+		s.logger.Error("发送案例下线消息到搜索失败",
+			elog.FieldErr(err),
+			elog.Any("event", evt),
+		)
+	}
+}
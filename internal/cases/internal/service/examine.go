@@ -0,0 +1,164 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/ai"
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+	"github.com/ecodeclub/webook/internal/cases/internal/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/lithammer/shortuuid/v4"
+)
+
+var (
+	ErrInsufficientCredit = ai.ErrInsufficientCredit
+	ErrQuotaExceeded      = ai.ErrQuotaExceeded
+	ErrInputRejected      = ai.ErrInputRejected
+	// ErrInvalidExamineResult AI 返回的测试结果不符合约定的格式
+	ErrInvalidExamineResult = errors.New("无法解析 AI 返回的测试结果")
+)
+
+const examineBiz = "case_examine"
+
+// ExamineService 案例测试，用户讲述自己在面试中会怎么介绍这个案例，由 AI 评价
+type ExamineService interface {
+	// Examine input 是用户输入的内容
+	// AI 返回的结果无法解析的时候返回 ErrInvalidExamineResult，之前的测试结果保持不变
+	Examine(ctx context.Context, uid, cid int64, input string) (domain.ExamineResult, error)
+	CaseResult(ctx context.Context, uid, cid int64) (domain.Result, error)
+	GetResults(ctx context.Context, uid int64, ids []int64) (map[int64]domain.ExamineResult, error)
+}
+
+var _ ExamineService = &LLMExamineService{}
+
+// LLMExamineService 使用 LLM 进行评价的测试服务
+type LLMExamineService struct {
+	caseRepo repository.CaseRepo
+	repo     repository.ExamineRepository
+	aiSvc    ai.LLMService
+	logger   *elog.Component
+}
+
+func (svc *LLMExamineService) Examine(ctx context.Context,
+	uid int64,
+	cid int64, input string) (domain.ExamineResult, error) {
+	ca, err := svc.caseRepo.GetPubByID(ctx, cid)
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	tid := shortuuid.New()
+	aiReq := ai.LLMRequest{
+		Uid:   uid,
+		Tid:   tid,
+		Biz:   examineBiz,
+		Input: []string{ca.Title, input, svc.rubric(ca)},
+	}
+	aiResp, err := svc.aiSvc.Invoke(ctx, aiReq)
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	result := domain.ExamineResult{
+		Cid:       cid,
+		RawResult: aiResp.Answer,
+		Tokens:    aiResp.Tokens,
+		Amount:    aiResp.Amount,
+		Tid:       tid,
+	}
+	result.Result, err = svc.parseExamineResult(aiResp.Answer)
+	if err != nil {
+		// AI 的回答已经扣费了，所以依旧记录下来，但是不能覆盖之前的测试结果，用户可以重新测试
+		svc.logger.Error("解析案例测试结果失败", elog.FieldErr(err),
+			elog.String("tid", tid))
+		err1 := svc.repo.SaveRecord(ctx, uid, input, result)
+		if err1 != nil {
+			svc.logger.Error("保存案例测试记录失败", elog.FieldErr(err1),
+				elog.String("tid", tid))
+		}
+		return domain.ExamineResult{}, err
+	}
+	err = svc.repo.SaveResult(ctx, uid, input, result)
+	return result, err
+}
+
+func (svc *LLMExamineService) CaseResult(ctx context.Context, uid, cid int64) (domain.Result, error) {
+	return svc.repo.GetResultByUidAndCid(ctx, uid, cid)
+}
+
+func (svc *LLMExamineService) GetResults(ctx context.Context, uid int64, ids []int64) (map[int64]domain.ExamineResult, error) {
+	results, err := svc.repo.GetResultsByIds(ctx, uid, ids)
+	return slice.ToMap[domain.ExamineResult, int64](results, func(ele domain.ExamineResult) int64 {
+		return ele.Cid
+	}), err
+}
+
+// rubric 评分依据，也就是案例的关键字、速记、亮点和引导点
+func (svc *LLMExamineService) rubric(ca domain.Case) string {
+	return fmt.Sprintf("关键字：%s\n速记：%s\n亮点：%s\n引导点：%s",
+		ca.Keywords, ca.Shorthand, ca.Highlight, ca.Guidance)
+}
+
+// parseExamineResult 提示词要求大模型只返回 JSON，但是大模型经常会包在 markdown 的代码块里面
+func (svc *LLMExamineService) parseExamineResult(answer string) (domain.Result, error) {
+	start := strings.Index(answer, "{")
+	end := strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return domain.ResultFailed, fmt.Errorf("%w, 没有找到 JSON", ErrInvalidExamineResult)
+	}
+	var res examineAnswer
+	err := json.Unmarshal([]byte(answer[start:end+1]), &res)
+	if err != nil {
+		return domain.ResultFailed, fmt.Errorf("%w, %w", ErrInvalidExamineResult, err)
+	}
+	// 等级忽略大小写，例如 15k 也可以
+	result, ok := examineLevels[strings.ToUpper(strings.TrimSpace(res.Level))]
+	if !ok {
+		return domain.ResultFailed, fmt.Errorf("%w, 未知的等级 %s", ErrInvalidExamineResult, res.Level)
+	}
+	return result, nil
+}
+
+// examineAnswer 和提示词模板里面约定的 JSON 格式
+type examineAnswer struct {
+	// FAILED，15K，25K 或者 35K
+	Level string `json:"level"`
+	// 评语只是给用户看的，保存在原始回答里面
+	Comment string `json:"comment"`
+}
+
+var examineLevels = map[string]domain.Result{
+	"FAILED": domain.ResultFailed,
+	"15K":    domain.ResultBasic,
+	"25K":    domain.ResultIntermediate,
+	"35K":    domain.ResultAdvanced,
+}
+
+func NewLLMExamineService(
+	caseRepo repository.CaseRepo,
+	repo repository.ExamineRepository,
+	aiSvc ai.LLMService,
+) ExamineService {
+	return &LLMExamineService{
+		caseRepo: caseRepo,
+		repo:     repo,
+		aiSvc:    aiSvc,
+		logger:   elog.DefaultLogger,
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ginx"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+	"github.com/ecodeclub/webook/internal/cases/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminCaseSetHandler struct {
+	svc service.CaseSetService
+}
+
+func NewAdminCaseSetHandler(svc service.CaseSetService) *AdminCaseSetHandler {
+	return &AdminCaseSetHandler{svc: svc}
+}
+
+func (h *AdminCaseSetHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/case-sets")
+	g.POST("/save", ginx.BS[CaseSet](h.Save))
+	g.POST("/cases/save", ginx.BS[UpdateCases](h.UpdateCases))
+	g.POST("/list", ginx.B[Page](h.List))
+	g.POST("/detail", ginx.B[CaseSetID](h.Detail))
+}
+
+func (h *AdminCaseSetHandler) Save(ctx *ginx.Context, req CaseSet, sess session.Session) (ginx.Result, error) {
+	id, err := h.svc.Save(ctx.Request.Context(), domain.CaseSet{
+		Id:          req.Id,
+		Uid:         sess.Claims().Uid,
+		Title:       req.Title,
+		Description: req.Description,
+		Biz:         req.Biz,
+		BizId:       req.BizId,
+	})
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: id,
+	}, nil
+}
+
+// UpdateCases 整体更新案例集中的所有案例，前端传递过来的就是最终的案例以及顺序
+func (h *AdminCaseSetHandler) UpdateCases(ctx *ginx.Context, req UpdateCases, sess session.Session) (ginx.Result, error) {
+	err := h.svc.UpdateCases(ctx.Request.Context(), domain.CaseSet{
+		Id:  req.CSID,
+		Uid: sess.Claims().Uid,
+		Cases: slice.Map(req.CIDs, func(idx int, src int64) domain.Case {
+			return domain.Case{Id: src}
+		}),
+	})
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *AdminCaseSetHandler) List(ctx *ginx.Context, req Page) (ginx.Result, error) {
+	data, total, err := h.svc.List(ctx.Request.Context(), req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: CaseSetList{
+			Total: total,
+			CaseSets: slice.Map(data, func(idx int, src domain.CaseSet) CaseSet {
+				return newCaseSet(src)
+			}),
+		},
+	}, nil
+}
+
+func (h *AdminCaseSetHandler) Detail(ctx *ginx.Context, req CaseSetID) (ginx.Result, error) {
+	data, err := h.svc.Detail(ctx.Request.Context(), req.CSID)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: newCaseSetDetail(data),
+	}, nil
+}
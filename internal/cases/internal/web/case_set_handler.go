@@ -0,0 +1,73 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+
+	"github.com/ecodeclub/ginx"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/cases/internal/domain"
+	"github.com/ecodeclub/webook/internal/cases/internal/service"
+	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/gin-gonic/gin"
+)
+
+type CaseSetHandler struct {
+	svc     service.CaseSetService
+	intrSvc interactive.Service
+}
+
+func NewCaseSetHandler(svc service.CaseSetService, intrSvc interactive.Service) *CaseSetHandler {
+	return &CaseSetHandler{
+		svc:     svc,
+		intrSvc: intrSvc,
+	}
+}
+
+func (h *CaseSetHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/case-sets")
+	g.POST("/detail", ginx.BS(h.Detail))
+	g.POST("/detail/biz", ginx.BS(h.DetailByBiz))
+}
+
+func (h *CaseSetHandler) Detail(ctx *ginx.Context, req CaseSetID, sess session.Session) (ginx.Result, error) {
+	data, err := h.svc.PubDetail(ctx.Request.Context(), req.CSID)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return h.getDetail(ctx, sess.Claims().Uid, data)
+}
+
+// DetailByBiz 例如面试项目可以通过 biz 和 bizId 找到它的案例集
+func (h *CaseSetHandler) DetailByBiz(ctx *ginx.Context, req BizReq, sess session.Session) (ginx.Result, error) {
+	data, err := h.svc.PubDetailByBiz(ctx.Request.Context(), req.Biz, req.BizId)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return h.getDetail(ctx, sess.Claims().Uid, data)
+}
+
+func (h *CaseSetHandler) getDetail(ctx context.Context, uid int64, set domain.CaseSet) (ginx.Result, error) {
+	intr, err := h.intrSvc.Get(ctx, domain.BizCaseSet, set.Id, uid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	res := newCaseSetDetail(set)
+	res.Interactive = newInteractive(intr)
+	return ginx.Result{
+		Data: res,
+	}, nil
+}
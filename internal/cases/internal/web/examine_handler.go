@@ -0,0 +1,74 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package web
+
+import (
+	"errors"
+
+	"github.com/ecodeclub/ginx"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/cases/internal/errs"
+	"github.com/ecodeclub/webook/internal/cases/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ExamineHandler struct {
+	svc service.ExamineService
+}
+
+func NewExamineHandler(svc service.ExamineService) *ExamineHandler {
+	return &ExamineHandler{
+		svc: svc,
+	}
+}
+
+func (h *ExamineHandler) MemberRoutes(server *gin.Engine) {
+	server.POST("/case/examine", ginx.BS(h.Examine))
+}
+
+func (h *ExamineHandler) Examine(ctx *ginx.Context, req ExamineReq, sess session.Session) (ginx.Result, error) {
+	res, err := h.svc.Examine(ctx, sess.Claims().Uid, req.Cid, req.Input)
+	if err != nil {
+		return examineErrResult(err)
+	}
+	return ginx.Result{
+		Data: newExamineResult(res),
+	}, nil
+}
+
+func examineErrResult(err error) (ginx.Result, error) {
+	switch {
+	case errors.Is(err, service.ErrInsufficientCredit):
+		return ginx.Result{
+			Code: errs.InsufficientCredit.Code,
+			Msg:  errs.InsufficientCredit.Msg,
+		}, nil
+	case errors.Is(err, service.ErrQuotaExceeded):
+		return ginx.Result{
+			Code: errs.QuotaExceeded.Code,
+			Msg:  errs.QuotaExceeded.Msg,
+		}, nil
+	case errors.Is(err, service.ErrInputRejected):
+		return ginx.Result{
+			Code: errs.InputRejected.Code,
+			Msg:  errs.InputRejected.Msg,
+		}, nil
+	case errors.Is(err, service.ErrInvalidExamineResult):
+		return ginx.Result{
+			Code: errs.InvalidExamineResult.Code,
+			Msg:  errs.InvalidExamineResult.Msg,
+		}, nil
+	}
+	return systemErrorResult, err
+}
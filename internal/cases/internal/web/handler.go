@@ -31,21 +31,26 @@ import (
 )
 
 type Handler struct {
-	svc     service.Service
-	intrSvc interactive.Service
-	logger  *elog.Component
+	svc        service.Service
+	examineSvc service.ExamineService
+	intrSvc    interactive.Service
+	logger     *elog.Component
 }
 
-func NewHandler(svc service.Service, intrSvc interactive.Service) *Handler {
+func NewHandler(svc service.Service,
+	examineSvc service.ExamineService,
+	intrSvc interactive.Service) *Handler {
 	return &Handler{
-		svc:     svc,
-		intrSvc: intrSvc,
-		logger:  elog.DefaultLogger,
+		svc:        svc,
+		examineSvc: examineSvc,
+		intrSvc:    intrSvc,
+		logger:     elog.DefaultLogger,
 	}
 }
 
 func (h *Handler) PublicRoutes(server *gin.Engine) {
-	server.POST("/case/pub/list", ginx.B[Page](h.PubList))
+	server.POST("/case/pub/list", ginx.B[PubListReq](h.PubList))
+	server.POST("/case/pub/facets", ginx.W(h.PubLabelFacets))
 }
 
 func (h *Handler) PrivateRoutes(server *gin.Engine) {
@@ -53,6 +58,10 @@ func (h *Handler) PrivateRoutes(server *gin.Engine) {
 	server.POST("/case/list", ginx.S(h.Permission), ginx.B[Page](h.List))
 	server.POST("/case/detail", ginx.S(h.Permission), ginx.B[CaseId](h.Detail))
 	server.POST("/case/publish", ginx.S(h.Permission), ginx.BS[SaveReq](h.Publish))
+	server.POST("/case/unpublish", ginx.S(h.Permission), ginx.B[CaseId](h.Unpublish))
+	server.POST("/case/delete", ginx.S(h.Permission), ginx.B[CaseId](h.Delete))
+	server.POST("/case/restore", ginx.S(h.Permission), ginx.B[CaseId](h.Restore))
+	server.POST("/case/trash/list", ginx.S(h.Permission), ginx.B[Page](h.TrashList))
 }
 
 func (h *Handler) MemberRoutes(server *gin.Engine) {
@@ -94,13 +103,14 @@ func (h *Handler) Detail(ctx *ginx.Context, req CaseId) (ginx.Result, error) {
 	}, err
 }
 
-func (h *Handler) PubList(ctx *ginx.Context, req Page) (ginx.Result, error) {
-	data, err := h.svc.PubList(ctx, req.Offset, req.Limit)
+func (h *Handler) PubList(ctx *ginx.Context, req PubListReq) (ginx.Result, error) {
+	data, err := h.svc.PubList(ctx, req.Offset, req.Limit, req.labelFilter())
 	if err != nil {
 		return systemErrorResult, err
 	}
 
 	intrs := map[int64]interactive.Interactive{}
+	results := map[int64]domain.ExamineResult{}
 	if len(data) > 0 {
 		ids := slice.Map(data, func(idx int, src domain.Case) int64 {
 			return src.Id
@@ -113,28 +123,58 @@ func (h *Handler) PubList(ctx *ginx.Context, req Page) (ginx.Result, error) {
 				elog.Any("ids", ids),
 				elog.FieldErr(err))
 		}
+		results = h.examineResults(ctx, ids)
 	}
 	return ginx.Result{
 		Data: CasesList{
 			Cases: slice.Map(data, func(idx int, ca domain.Case) Case {
 				return Case{
-					Id:           ca.Id,
-					Title:        ca.Title,
-					Introduction: ca.Introduction,
-					Labels:       ca.Labels,
-					Utime:        ca.Utime.UnixMilli(),
-					Interactive:  newInteractive(intrs[ca.Id]),
+					Id:            ca.Id,
+					Title:         ca.Title,
+					Introduction:  ca.Introduction,
+					Labels:        ca.Labels,
+					Utime:         ca.Utime.UnixMilli(),
+					Interactive:   newInteractive(intrs[ca.Id]),
+					ExamineResult: results[ca.Id].Result.ToUint8(),
 				}
 			}),
 		},
 	}, nil
 }
 
+// PubLabelFacets 每个标签下的案例数量，前端用来展示标签筛选
+func (h *Handler) PubLabelFacets(ctx *ginx.Context) (ginx.Result, error) {
+	facets, err := h.svc.PubLabelFacets(ctx)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: slice.Map(facets, newLabelFacet),
+	}, nil
+}
+
+// examineResults 列表是公开的，没有登录的时候就没有测试结果
+func (h *Handler) examineResults(ctx *ginx.Context, ids []int64) map[int64]domain.ExamineResult {
+	sess, err := session.Get(ctx)
+	if err != nil {
+		return nil
+	}
+	res, err := h.examineSvc.GetResults(ctx, sess.Claims().Uid, ids)
+	if err != nil {
+		// 查询不到也不影响列表
+		h.logger.Error("查询案例测试结果失败",
+			elog.Any("ids", ids),
+			elog.FieldErr(err))
+	}
+	return res
+}
+
 func (h *Handler) PubDetail(ctx *ginx.Context, req CaseId, sess session.Session) (ginx.Result, error) {
 	var (
-		eg     errgroup.Group
-		detail domain.Case
-		intr   interactive.Interactive
+		eg      errgroup.Group
+		detail  domain.Case
+		intr    interactive.Interactive
+		examine domain.Result
 	)
 	eg.Go(func() error {
 		var err error
@@ -148,12 +188,25 @@ func (h *Handler) PubDetail(ctx *ginx.Context, req CaseId, sess session.Session)
 		return err
 	})
 
+	eg.Go(func() error {
+		var err error
+		examine, err = h.examineSvc.CaseResult(ctx, sess.Claims().Uid, req.Cid)
+		// 查询不到测试结果也不影响详情
+		if err != nil {
+			h.logger.Error("查询案例测试结果失败",
+				elog.Int64("cid", req.Cid),
+				elog.FieldErr(err))
+		}
+		return nil
+	})
+
 	err := eg.Wait()
 	if err != nil {
 		return systemErrorResult, err
 	}
 	res := newCase(detail)
 	res.Interactive = newInteractive(intr)
+	res.ExamineResult = examine.ToUint8()
 	return ginx.Result{
 		Data: res,
 	}, err
@@ -171,6 +224,41 @@ func (h *Handler) Publish(ctx *ginx.Context, req SaveReq, sess session.Session)
 	}, nil
 }
 
+func (h *Handler) Unpublish(ctx *ginx.Context, req CaseId) (ginx.Result, error) {
+	err := h.svc.Unpublish(ctx, req.Cid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+// Delete 放入回收站，超过保留期限之后会被彻底删除
+func (h *Handler) Delete(ctx *ginx.Context, req CaseId) (ginx.Result, error) {
+	err := h.svc.Delete(ctx, req.Cid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *Handler) Restore(ctx *ginx.Context, req CaseId) (ginx.Result, error) {
+	err := h.svc.Restore(ctx, req.Cid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *Handler) TrashList(ctx *ginx.Context, req Page) (ginx.Result, error) {
+	data, cnt, err := h.svc.TrashList(ctx, req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: h.toCaseList(data, cnt),
+	}, nil
+}
+
 func (h *Handler) toCaseList(data []domain.Case, cnt int64) CasesList {
 	return CasesList{
 		Total: cnt,
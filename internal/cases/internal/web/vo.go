@@ -1,14 +1,48 @@
 package web
 
 import (
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/webook/internal/cases/internal/domain"
 	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/label"
 )
 
 type Page struct {
 	Offset int `json:"offset,omitempty"`
 	Limit  int `json:"limit,omitempty"`
 }
+
+// PubListReq C 端的列表，可以按照标签筛选
+type PubListReq struct {
+	Offset int `json:"offset,omitempty"`
+	Limit  int `json:"limit,omitempty"`
+	// label 模块里面的标签 ID
+	LabelIds []int64 `json:"labelIds,omitempty"`
+	// 0-命中任意一个标签 1-同时命中全部标签
+	LabelMode uint8 `json:"labelMode,omitempty"`
+}
+
+func (r PubListReq) labelFilter() label.Filter {
+	return label.Filter{
+		LabelIds: r.LabelIds,
+		Mode:     label.MatchMode(r.LabelMode),
+	}
+}
+
+type LabelFacet struct {
+	LabelId int64  `json:"labelId"`
+	Name    string `json:"name"`
+	Cnt     int64  `json:"cnt"`
+}
+
+func newLabelFacet(idx int, src label.Facet) LabelFacet {
+	return LabelFacet{
+		LabelId: src.LabelId,
+		Name:    src.Name,
+		Cnt:     src.Cnt,
+	}
+}
+
 type CasesList struct {
 	Cases []Case `json:"cases,omitempty"`
 	Total int64  `json:"total,omitempty"`
@@ -37,6 +71,8 @@ type Case struct {
 	Utime    int64  `json:"utime,omitempty"`
 
 	Interactive Interactive `json:"interactive,omitempty"`
+	// 当前用户的测试结果，没有登录或者没有测试过都是 0
+	ExamineResult uint8 `json:"examineResult"`
 }
 
 type CaseId struct {
@@ -78,3 +114,86 @@ func newInteractive(intr interactive.Interactive) Interactive {
 		Collected:  intr.Collected,
 	}
 }
+
+type CaseSetID struct {
+	CSID int64 `json:"csid"`
+}
+
+type BizReq struct {
+	Biz   string `json:"biz"`
+	BizId int64  `json:"bizId"`
+}
+
+type UpdateCases struct {
+	CSID int64 `json:"csid"`
+	// 顺序就是案例集里面展示的顺序
+	CIDs []int64 `json:"cids,omitempty"`
+}
+
+type CaseSet struct {
+	Id          int64       `json:"id,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Biz         string      `json:"biz"`
+	BizId       int64       `json:"bizId"`
+	Cases       []Case      `json:"cases,omitempty"`
+	Utime       int64       `json:"utime,omitempty"`
+	Interactive Interactive `json:"interactive,omitempty"`
+}
+
+// newCaseSet 只包含基础信息
+func newCaseSet(set domain.CaseSet) CaseSet {
+	return CaseSet{
+		Id:          set.Id,
+		Title:       set.Title,
+		Description: set.Description,
+		Biz:         set.Biz,
+		BizId:       set.BizId,
+		Utime:       set.Utime.UnixMilli(),
+	}
+}
+
+// newCaseSetDetail 包含案例集里面的案例
+func newCaseSetDetail(set domain.CaseSet) CaseSet {
+	res := newCaseSet(set)
+	res.Cases = slice.Map(set.Cases, func(idx int, src domain.Case) Case {
+		return Case{
+			Id:           src.Id,
+			Title:        src.Title,
+			Introduction: src.Introduction,
+			Labels:       src.Labels,
+			Status:       src.Status.ToUint8(),
+			Utime:        src.Utime.UnixMilli(),
+		}
+	})
+	return res
+}
+
+type CaseSetList struct {
+	Total    int64     `json:"total,omitempty"`
+	CaseSets []CaseSet `json:"caseSets,omitempty"`
+}
+
+type ExamineReq struct {
+	Cid   int64  `json:"cid"`
+	Input string `json:"input"`
+}
+
+type ExamineResult struct {
+	Result uint8 `json:"result"`
+	// 原始回答，源自 AI
+	RawResult string `json:"rawResult"`
+	// 使用的 token 数量
+	Tokens int64 `json:"tokens"`
+	// 花费的金额
+	Amount int64 `json:"amount"`
+}
+
+func newExamineResult(r domain.ExamineResult) ExamineResult {
+	return ExamineResult{
+		Result:    r.Result.ToUint8(),
+		RawResult: r.RawResult,
+		Tokens:    r.Tokens,
+		Amount:    r.Amount,
+	}
+}
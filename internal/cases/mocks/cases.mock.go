@@ -11,8 +11,10 @@ package casemocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/ecodeclub/webook/internal/cases/internal/domain"
+	label "github.com/ecodeclub/webook/internal/label"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,6 +41,44 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockService) Delete(ctx context.Context, caseId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, caseId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockServiceMockRecorder) Delete(ctx, caseId any) *ServiceDeleteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, caseId)
+	return &ServiceDeleteCall{Call: call}
+}
+
+// ServiceDeleteCall wrap *gomock.Call
+type ServiceDeleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceDeleteCall) Return(arg0 error) *ServiceDeleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceDeleteCall) Do(f func(context.Context, int64) error) *ServiceDeleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceDeleteCall) DoAndReturn(f func(context.Context, int64) error) *ServiceDeleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Detail mocks base method.
 func (m *MockService) Detail(ctx context.Context, caseId int64) (domain.Case, error) {
 	m.ctrl.T.Helper()
@@ -196,19 +236,58 @@ func (c *ServicePubDetailCall) DoAndReturn(f func(context.Context, int64) (domai
 	return c
 }
 
+// PubLabelFacets mocks base method.
+func (m *MockService) PubLabelFacets(ctx context.Context) ([]label.Facet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubLabelFacets", ctx)
+	ret0, _ := ret[0].([]label.Facet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PubLabelFacets indicates an expected call of PubLabelFacets.
+func (mr *MockServiceMockRecorder) PubLabelFacets(ctx any) *ServicePubLabelFacetsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubLabelFacets", reflect.TypeOf((*MockService)(nil).PubLabelFacets), ctx)
+	return &ServicePubLabelFacetsCall{Call: call}
+}
+
+// ServicePubLabelFacetsCall wrap *gomock.Call
+type ServicePubLabelFacetsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServicePubLabelFacetsCall) Return(arg0 []label.Facet, arg1 error) *ServicePubLabelFacetsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServicePubLabelFacetsCall) Do(f func(context.Context) ([]label.Facet, error)) *ServicePubLabelFacetsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServicePubLabelFacetsCall) DoAndReturn(f func(context.Context) ([]label.Facet, error)) *ServicePubLabelFacetsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // PubList mocks base method.
-func (m *MockService) PubList(ctx context.Context, offset, limit int) ([]domain.Case, error) {
+func (m *MockService) PubList(ctx context.Context, offset, limit int, filter label.Filter) ([]domain.Case, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PubList", ctx, offset, limit)
+	ret := m.ctrl.Call(m, "PubList", ctx, offset, limit, filter)
 	ret0, _ := ret[0].([]domain.Case)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PubList indicates an expected call of PubList.
-func (mr *MockServiceMockRecorder) PubList(ctx, offset, limit any) *ServicePubListCall {
+func (mr *MockServiceMockRecorder) PubList(ctx, offset, limit, filter any) *ServicePubListCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubList", reflect.TypeOf((*MockService)(nil).PubList), ctx, offset, limit)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubList", reflect.TypeOf((*MockService)(nil).PubList), ctx, offset, limit, filter)
 	return &ServicePubListCall{Call: call}
 }
 
@@ -224,13 +303,52 @@ func (c *ServicePubListCall) Return(arg0 []domain.Case, arg1 error) *ServicePubL
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *ServicePubListCall) Do(f func(context.Context, int, int) ([]domain.Case, error)) *ServicePubListCall {
+func (c *ServicePubListCall) Do(f func(context.Context, int, int, label.Filter) ([]domain.Case, error)) *ServicePubListCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServicePubListCall) DoAndReturn(f func(context.Context, int, int, label.Filter) ([]domain.Case, error)) *ServicePubListCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PubListAfter mocks base method.
+func (m *MockService) PubListAfter(ctx context.Context, id int64, limit int) ([]domain.Case, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubListAfter", ctx, id, limit)
+	ret0, _ := ret[0].([]domain.Case)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PubListAfter indicates an expected call of PubListAfter.
+func (mr *MockServiceMockRecorder) PubListAfter(ctx, id, limit any) *ServicePubListAfterCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubListAfter", reflect.TypeOf((*MockService)(nil).PubListAfter), ctx, id, limit)
+	return &ServicePubListAfterCall{Call: call}
+}
+
+// ServicePubListAfterCall wrap *gomock.Call
+type ServicePubListAfterCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServicePubListAfterCall) Return(arg0 []domain.Case, arg1 error) *ServicePubListAfterCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServicePubListAfterCall) Do(f func(context.Context, int64, int) ([]domain.Case, error)) *ServicePubListAfterCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *ServicePubListCall) DoAndReturn(f func(context.Context, int, int) ([]domain.Case, error)) *ServicePubListCall {
+func (c *ServicePubListAfterCall) DoAndReturn(f func(context.Context, int64, int) ([]domain.Case, error)) *ServicePubListAfterCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -274,6 +392,83 @@ func (c *ServicePublishCall) DoAndReturn(f func(context.Context, domain.Case) (i
 	return c
 }
 
+// PurgeTrash mocks base method.
+func (m *MockService) PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, before, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockServiceMockRecorder) PurgeTrash(ctx, before, limit any) *ServicePurgeTrashCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockService)(nil).PurgeTrash), ctx, before, limit)
+	return &ServicePurgeTrashCall{Call: call}
+}
+
+// ServicePurgeTrashCall wrap *gomock.Call
+type ServicePurgeTrashCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServicePurgeTrashCall) Return(arg0 int, arg1 error) *ServicePurgeTrashCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServicePurgeTrashCall) Do(f func(context.Context, time.Time, int) (int, error)) *ServicePurgeTrashCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServicePurgeTrashCall) DoAndReturn(f func(context.Context, time.Time, int) (int, error)) *ServicePurgeTrashCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Restore mocks base method.
+func (m *MockService) Restore(ctx context.Context, caseId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, caseId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockServiceMockRecorder) Restore(ctx, caseId any) *ServiceRestoreCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockService)(nil).Restore), ctx, caseId)
+	return &ServiceRestoreCall{Call: call}
+}
+
+// ServiceRestoreCall wrap *gomock.Call
+type ServiceRestoreCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceRestoreCall) Return(arg0 error) *ServiceRestoreCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceRestoreCall) Do(f func(context.Context, int64) error) *ServiceRestoreCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceRestoreCall) DoAndReturn(f func(context.Context, int64) error) *ServiceRestoreCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Save mocks base method.
 func (m *MockService) Save(ctx context.Context, ca domain.Case) (int64, error) {
 	m.ctrl.T.Helper()
@@ -312,3 +507,119 @@ func (c *ServiceSaveCall) DoAndReturn(f func(context.Context, domain.Case) (int6
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// SyncLabels mocks base method.
+func (m *MockService) SyncLabels(ctx context.Context, cid int64, labels []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncLabels", ctx, cid, labels)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncLabels indicates an expected call of SyncLabels.
+func (mr *MockServiceMockRecorder) SyncLabels(ctx, cid, labels any) *ServiceSyncLabelsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncLabels", reflect.TypeOf((*MockService)(nil).SyncLabels), ctx, cid, labels)
+	return &ServiceSyncLabelsCall{Call: call}
+}
+
+// ServiceSyncLabelsCall wrap *gomock.Call
+type ServiceSyncLabelsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceSyncLabelsCall) Return(arg0 error) *ServiceSyncLabelsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceSyncLabelsCall) Do(f func(context.Context, int64, []string) error) *ServiceSyncLabelsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceSyncLabelsCall) DoAndReturn(f func(context.Context, int64, []string) error) *ServiceSyncLabelsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// TrashList mocks base method.
+func (m *MockService) TrashList(ctx context.Context, offset, limit int) ([]domain.Case, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrashList", ctx, offset, limit)
+	ret0, _ := ret[0].([]domain.Case)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TrashList indicates an expected call of TrashList.
+func (mr *MockServiceMockRecorder) TrashList(ctx, offset, limit any) *ServiceTrashListCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrashList", reflect.TypeOf((*MockService)(nil).TrashList), ctx, offset, limit)
+	return &ServiceTrashListCall{Call: call}
+}
+
+// ServiceTrashListCall wrap *gomock.Call
+type ServiceTrashListCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceTrashListCall) Return(arg0 []domain.Case, arg1 int64, arg2 error) *ServiceTrashListCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceTrashListCall) Do(f func(context.Context, int, int) ([]domain.Case, int64, error)) *ServiceTrashListCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceTrashListCall) DoAndReturn(f func(context.Context, int, int) ([]domain.Case, int64, error)) *ServiceTrashListCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Unpublish mocks base method.
+func (m *MockService) Unpublish(ctx context.Context, caseId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unpublish", ctx, caseId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unpublish indicates an expected call of Unpublish.
+func (mr *MockServiceMockRecorder) Unpublish(ctx, caseId any) *ServiceUnpublishCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unpublish", reflect.TypeOf((*MockService)(nil).Unpublish), ctx, caseId)
+	return &ServiceUnpublishCall{Call: call}
+}
+
+// ServiceUnpublishCall wrap *gomock.Call
+type ServiceUnpublishCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceUnpublishCall) Return(arg0 error) *ServiceUnpublishCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceUnpublishCall) Do(f func(context.Context, int64) error) *ServiceUnpublishCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceUnpublishCall) DoAndReturn(f func(context.Context, int64) error) *ServiceUnpublishCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -15,6 +15,15 @@
 package cases
 
 type Module struct {
-	Svc Service
-	Hdl *Handler
+	Svc           Service
+	Hdl           *Handler
+	SyncLabelsJob *SyncLabelsJob
+	PurgeTrashJob *PurgeTrashJob
+
+	SetSvc      CaseSetService
+	SetHdl      *CaseSetHandler
+	AdminSetHdl *AdminCaseSetHandler
+
+	ExamineSvc ExamineService
+	ExamineHdl *ExamineHandler
 }
@@ -4,11 +4,15 @@ package cases
 
 import (
 	"sync"
+	"time"
 
 	"github.com/ecodeclub/webook/internal/interactive"
 
 	"github.com/ecodeclub/mq-api"
+	"github.com/ecodeclub/webook/internal/ai"
 	"github.com/ecodeclub/webook/internal/cases/internal/event"
+	"github.com/ecodeclub/webook/internal/cases/internal/job"
+	"github.com/ecodeclub/webook/internal/label"
 
 	"github.com/ecodeclub/webook/internal/cases/internal/domain"
 
@@ -23,6 +27,8 @@ import (
 
 func InitModule(db *egorm.Component,
 	intrModule *interactive.Module,
+	labelModule *label.Module,
+	aiModule *ai.Module,
 	q mq.MQ) (*Module, error) {
 	wire.Build(InitCaseDAO,
 		repository.NewCaseRepo,
@@ -30,7 +36,20 @@ func InitModule(db *egorm.Component,
 		event.NewInteractiveEventProducer,
 		service.NewService,
 		web.NewHandler,
+		job.NewSyncLabelsJob,
+		InitPurgeTrashJob,
+		InitCaseSetDAO,
+		repository.NewCaseSetRepository,
+		service.NewCaseSetService,
+		web.NewCaseSetHandler,
+		web.NewAdminCaseSetHandler,
+		InitExamineDAO,
+		repository.NewExamineRepository,
+		service.NewLLMExamineService,
+		web.NewExamineHandler,
 		wire.FieldsOf(new(*interactive.Module), "Svc"),
+		wire.FieldsOf(new(*label.Module), "Svc"),
+		wire.FieldsOf(new(*ai.Module), "Svc"),
 		wire.Struct(new(Module), "*"),
 	)
 	return new(Module), nil
@@ -52,6 +71,30 @@ func InitCaseDAO(db *egorm.Component) dao.CaseDAO {
 	return dao.NewCaseDao(db)
 }
 
+func InitCaseSetDAO(db *egorm.Component) dao.CaseSetDAO {
+	InitTableOnce(db)
+	return dao.NewGORMCaseSetDAO(db)
+}
+
+func InitExamineDAO(db *egorm.Component) dao.ExamineDAO {
+	InitTableOnce(db)
+	return dao.NewGORMExamineDAO(db)
+}
+
+// InitPurgeTrashJob 回收站里面的案例保留 30 天
+func InitPurgeTrashJob(svc service.Service) *PurgeTrashJob {
+	return job.NewPurgeTrashJob(svc, 30*24*time.Hour, 100)
+}
+
 type Handler = web.Handler
 type Service = service.Service
 type Case = domain.Case
+type SyncLabelsJob = job.SyncLabelsJob
+type PurgeTrashJob = job.PurgeTrashJob
+type CaseSetService = service.CaseSetService
+type CaseSet = domain.CaseSet
+type CaseSetHandler = web.CaseSetHandler
+type AdminCaseSetHandler = web.AdminCaseSetHandler
+type ExamineService = service.ExamineService
+type ExamineHandler = web.ExamineHandler
+type ExamineResult = domain.ExamineResult
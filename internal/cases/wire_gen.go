@@ -8,22 +8,26 @@ package cases
 
 import (
 	"sync"
+	"time"
 
 	"github.com/ecodeclub/mq-api"
+	"github.com/ecodeclub/webook/internal/ai"
 	"github.com/ecodeclub/webook/internal/cases/internal/domain"
 	"github.com/ecodeclub/webook/internal/cases/internal/event"
+	"github.com/ecodeclub/webook/internal/cases/internal/job"
 	"github.com/ecodeclub/webook/internal/cases/internal/repository"
 	"github.com/ecodeclub/webook/internal/cases/internal/repository/dao"
 	"github.com/ecodeclub/webook/internal/cases/internal/service"
 	"github.com/ecodeclub/webook/internal/cases/internal/web"
 	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ego-component/egorm"
 	"gorm.io/gorm"
 )
 
 // Injectors from wire.go:
 
-func InitModule(db *gorm.DB, intrModule *interactive.Module, q mq.MQ) (*Module, error) {
+func InitModule(db *gorm.DB, intrModule *interactive.Module, labelModule *label.Module, aiModule *ai.Module, q mq.MQ) (*Module, error) {
 	caseDAO := InitCaseDAO(db)
 	caseRepo := repository.NewCaseRepo(caseDAO)
 	interactiveEventProducer, err := event.NewInteractiveEventProducer(q)
@@ -34,12 +38,32 @@ func InitModule(db *gorm.DB, intrModule *interactive.Module, q mq.MQ) (*Module,
 	if err != nil {
 		return nil, err
 	}
-	serviceService := service.NewService(caseRepo, interactiveEventProducer, syncEventProducer)
+	labelService := labelModule.Svc
+	serviceService := service.NewService(caseRepo, labelService, interactiveEventProducer, syncEventProducer)
+	examineDAO := InitExamineDAO(db)
+	examineRepository := repository.NewExamineRepository(examineDAO)
+	llmService := aiModule.Svc
+	examineService := service.NewLLMExamineService(caseRepo, examineRepository, llmService)
 	service2 := intrModule.Svc
-	handler := web.NewHandler(serviceService, service2)
+	handler := web.NewHandler(serviceService, examineService, service2)
+	syncLabelsJob := job.NewSyncLabelsJob(serviceService)
+	purgeTrashJob := InitPurgeTrashJob(serviceService)
+	caseSetDAO := InitCaseSetDAO(db)
+	caseSetRepository := repository.NewCaseSetRepository(caseSetDAO)
+	caseSetService := service.NewCaseSetService(caseSetRepository, interactiveEventProducer, syncEventProducer)
+	caseSetHandler := web.NewCaseSetHandler(caseSetService, service2)
+	adminCaseSetHandler := web.NewAdminCaseSetHandler(caseSetService)
+	examineHandler := web.NewExamineHandler(examineService)
 	module := &Module{
-		Svc: serviceService,
-		Hdl: handler,
+		Svc:           serviceService,
+		Hdl:           handler,
+		SyncLabelsJob: syncLabelsJob,
+		PurgeTrashJob: purgeTrashJob,
+		SetSvc:        caseSetService,
+		SetHdl:        caseSetHandler,
+		AdminSetHdl:   adminCaseSetHandler,
+		ExamineSvc:    examineService,
+		ExamineHdl:    examineHandler,
 	}
 	return module, nil
 }
@@ -62,8 +86,41 @@ func InitCaseDAO(db *egorm.Component) dao.CaseDAO {
 	return dao.NewCaseDao(db)
 }
 
+func InitCaseSetDAO(db *egorm.Component) dao.CaseSetDAO {
+	InitTableOnce(db)
+	return dao.NewGORMCaseSetDAO(db)
+}
+
+func InitExamineDAO(db *egorm.Component) dao.ExamineDAO {
+	InitTableOnce(db)
+	return dao.NewGORMExamineDAO(db)
+}
+
+// InitPurgeTrashJob 回收站里面的案例保留 30 天
+func InitPurgeTrashJob(svc service.Service) *PurgeTrashJob {
+	return job.NewPurgeTrashJob(svc, 30*24*time.Hour, 100)
+}
+
 type Handler = web.Handler
 
 type Service = service.Service
 
 type Case = domain.Case
+
+type SyncLabelsJob = job.SyncLabelsJob
+
+type PurgeTrashJob = job.PurgeTrashJob
+
+type CaseSetService = service.CaseSetService
+
+type CaseSet = domain.CaseSet
+
+type CaseSetHandler = web.CaseSetHandler
+
+type AdminCaseSetHandler = web.AdminCaseSetHandler
+
+type ExamineService = service.ExamineService
+
+type ExamineHandler = web.ExamineHandler
+
+type ExamineResult = domain.ExamineResult
@@ -1181,6 +1181,131 @@ func (s *ModuleTestSuite) TestService_ConfirmDeductCredits() {
 	}
 }
 
+func (s *ModuleTestSuite) TestService_PartialConfirmDeductCredits() {
+	t := s.T()
+
+	lock := func(t *testing.T, uid int64) int64 {
+		t.Helper()
+		err := s.svc.AddCredits(context.Background(), domain.Credit{
+			Uid: uid,
+			Logs: []domain.CreditLog{
+				{
+					Key:          fmt.Sprintf("key-%d-1", uid),
+					ChangeAmount: 100,
+					Biz:          "user",
+					BizId:        1,
+					Desc:         "注册",
+				},
+			},
+		})
+		require.NoError(t, err)
+		// 预扣
+		id, err := s.svc.TryDeductCredits(context.Background(), domain.Credit{
+			Uid: uid,
+			Logs: []domain.CreditLog{
+				{
+					Key:          fmt.Sprintf("key-%d-2", uid),
+					ChangeAmount: 50,
+					Biz:          "ai-llm",
+					BizId:        9,
+					Desc:         "ai-llm服务",
+				},
+			},
+		})
+		require.NoError(t, err)
+		return id
+	}
+
+	testCases := []struct {
+		name           string
+		uid            int64
+		amount         int64
+		wantTotal      uint64
+		wantLocked     uint64
+		wantChange     int64
+		errRequireFunc require.ErrorAssertionFunc
+	}{
+		{
+			name:           "部分确认成功_返还剩余积分",
+			uid:            8101,
+			amount:         20,
+			wantTotal:      80,
+			wantChange:     -20,
+			errRequireFunc: require.NoError,
+		},
+		{
+			name:           "部分确认成功_全部确认",
+			uid:            8102,
+			amount:         50,
+			wantTotal:      50,
+			wantChange:     -50,
+			errRequireFunc: require.NoError,
+		},
+		{
+			name:           "部分确认成功_确认0积分",
+			uid:            8103,
+			amount:         0,
+			wantTotal:      100,
+			wantChange:     0,
+			errRequireFunc: require.NoError,
+		},
+		{
+			name:       "部分确认失败_确认积分超过预扣积分",
+			uid:        8104,
+			amount:     51,
+			wantTotal:  50,
+			wantLocked: 50,
+			wantChange: -50,
+			errRequireFunc: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, service.ErrInvalidConfirmAmount)
+			},
+		},
+		{
+			name:       "部分确认失败_确认积分为负数",
+			uid:        8105,
+			amount:     -1,
+			wantTotal:  50,
+			wantLocked: 50,
+			wantChange: -50,
+			errRequireFunc: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, service.ErrInvalidConfirmAmount)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tid := lock(t, tc.uid)
+			err := s.svc.PartialConfirmDeductCredits(context.Background(), tc.uid, tid, tc.amount)
+			tc.errRequireFunc(t, err)
+
+			c, err := s.svc.GetCreditsByUID(context.Background(), tc.uid)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantTotal, c.TotalAmount)
+			require.Equal(t, tc.wantLocked, c.LockedTotalAmount)
+			s.requireCreditLogs(t, []domain.CreditLog{
+				{
+					Key:          fmt.Sprintf("key-%d-2", tc.uid),
+					Uid:          tc.uid,
+					ChangeAmount: tc.wantChange,
+					Biz:          "ai-llm",
+					BizId:        9,
+					Desc:         "ai-llm服务",
+				},
+				{
+					Key:          fmt.Sprintf("key-%d-1", tc.uid),
+					Uid:          tc.uid,
+					ChangeAmount: 100,
+					Biz:          "user",
+					BizId:        1,
+					Desc:         "注册",
+				},
+			}, c.Logs)
+		})
+	}
+}
+
 func (s *ModuleTestSuite) requireCreditLogs(t *testing.T, expected []domain.CreditLog, actual []domain.CreditLog) {
 	for i := 0; i < len(actual); i++ {
 		require.NotZero(t, actual[i].ID)
@@ -32,6 +32,7 @@ var (
 	ErrCreditNotEnough              = errors.New("积分不足")
 	ErrRecordNotFound               = egorm.ErrRecordNotFound
 	ErrInvalidLockedCreditLogStatus = errors.New("锁定的积分流水初始状态非法")
+	ErrInvalidConfirmAmount         = errors.New("确认扣减的积分超过了锁定的积分")
 )
 
 type CreditDAO interface {
@@ -40,6 +41,7 @@ type CreditDAO interface {
 	FindCreditLogsByUID(ctx context.Context, uid int64) ([]CreditLog, error)
 	CreateCreditLockLog(ctx context.Context, l CreditLog) (int64, error)
 	ConfirmCreditLockLog(ctx context.Context, uid, tid int64) error
+	PartialConfirmCreditLockLog(ctx context.Context, uid, tid int64, amount uint64) error
 	CancelCreditLockLog(ctx context.Context, uid, tid int64) error
 	FindExpiredLockedCreditLogs(ctx context.Context, offset int, limit int, ctime int64) ([]CreditLog, error)
 	TotalExpiredLockedCreditLogs(ctx context.Context, ctime int64) (int64, error)
@@ -234,6 +236,82 @@ func (g *creditDAO) ConfirmCreditLockLog(ctx context.Context, uid, tid int64) er
 	}
 }
 
+// PartialConfirmCreditLockLog 只确认部分预扣积分，剩余的部分返还给用户
+func (g *creditDAO) PartialConfirmCreditLockLog(ctx context.Context, uid, tid int64, amount uint64) error {
+	for {
+		err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			err := g.shrinkCreditLockLog(tx, uid, tid, amount)
+			if err != nil {
+				return err
+			}
+			totalCreditsIncreaseAmountFunc := func(cl CreditLog) uint64 { return uint64(0) }
+			return g.updateCreditLockLog(tx, uid, tid, CreditLogStatusLocked, CreditLogStatusActive, totalCreditsIncreaseAmountFunc)
+		})
+		if errors.Is(err, ErrUpdateCreditConflict) {
+			continue
+		}
+		return err
+	}
+}
+
+// shrinkCreditLockLog 将预扣积分减少到 amount，多出来的部分返还给用户
+func (g *creditDAO) shrinkCreditLockLog(tx *gorm.DB, uid, tid int64, amount uint64) error {
+	now := time.Now().UnixMilli()
+
+	var c Credit
+	if err := tx.First(&c, "uid = ?", uid).Error; err != nil {
+		return err
+	}
+
+	var cl CreditLog
+	if err := tx.Where("uid = ? AND id = ?", uid, tid).First(&cl).Error; err != nil {
+		return err
+	}
+	if cl.Status != CreditLogStatusLocked {
+		// 已经确认过或者已经取消了，交给后面的确认步骤处理
+		return nil
+	}
+	locked := uint64(0 - cl.CreditChange)
+	if amount > locked {
+		return fmt.Errorf("%w: 锁定 %d, 确认 %d", ErrInvalidConfirmAmount, locked, amount)
+	}
+	released := locked - amount
+	if released == 0 {
+		return nil
+	}
+
+	res := tx.Model(&CreditLog{}).
+		Where("uid = ? AND id = ? AND status = ?", uid, tid, CreditLogStatusLocked).
+		Updates(map[string]any{
+			"CreditChange":  0 - int64(amount),
+			"CreditBalance": cl.CreditBalance + released,
+			"Utime":         now,
+		})
+	if err := res.Error; err != nil {
+		return fmt.Errorf("更新积分流水记录失败: %w", err)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w", ErrUpdateCreditConflict)
+	}
+
+	version := c.Version
+	res = tx.Model(&Credit{}).
+		Where("uid = ? AND Version = ?", uid, version).
+		Updates(map[string]any{
+			"TotalCredits":       c.TotalCredits + released,
+			"LockedTotalCredits": c.LockedTotalCredits - released,
+			"Utime":              now,
+			"Version":            version + 1,
+		})
+	if err := res.Error; err != nil {
+		return fmt.Errorf("更新积分主记录失败: %w", err)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w", ErrUpdateCreditConflict)
+	}
+	return nil
+}
+
 // CancelCreditLockLog 取消积分预扣
 func (g *creditDAO) CancelCreditLockLog(ctx context.Context, uid, tid int64) error {
 	for {
@@ -23,9 +23,10 @@ import (
 )
 
 var (
-	ErrDuplicatedCreditLog = dao.ErrDuplicatedCreditLog
-	ErrCreditNotEnough     = dao.ErrCreditNotEnough
-	ErrRecordNotFound      = dao.ErrRecordNotFound
+	ErrDuplicatedCreditLog  = dao.ErrDuplicatedCreditLog
+	ErrCreditNotEnough      = dao.ErrCreditNotEnough
+	ErrRecordNotFound       = dao.ErrRecordNotFound
+	ErrInvalidConfirmAmount = dao.ErrInvalidConfirmAmount
 )
 
 type CreditRepository interface {
@@ -33,6 +34,7 @@ type CreditRepository interface {
 	GetCreditByUID(ctx context.Context, uid int64) (domain.Credit, error)
 	TryDeductCredits(ctx context.Context, credit domain.Credit) (int64, error)
 	ConfirmDeductCredits(ctx context.Context, uid, tid int64) error
+	PartialConfirmDeductCredits(ctx context.Context, uid, tid int64, amount uint64) error
 	CancelDeductCredits(ctx context.Context, uid, tid int64) error
 	FindExpiredLockedCreditLogs(ctx context.Context, offset int, limit int, ctime int64) ([]domain.CreditLog, error)
 	TotalExpiredLockedCreditLogs(ctx context.Context, ctime int64) (int64, error)
@@ -107,6 +109,10 @@ func (r *creditRepository) ConfirmDeductCredits(ctx context.Context, uid, tid in
 	return r.dao.ConfirmCreditLockLog(ctx, uid, tid)
 }
 
+func (r *creditRepository) PartialConfirmDeductCredits(ctx context.Context, uid, tid int64, amount uint64) error {
+	return r.dao.PartialConfirmCreditLockLog(ctx, uid, tid, amount)
+}
+
 func (r *creditRepository) CancelDeductCredits(ctx context.Context, uid, tid int64) error {
 	return r.dao.CancelCreditLockLog(ctx, uid, tid)
 }
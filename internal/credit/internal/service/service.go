@@ -25,10 +25,11 @@ import (
 )
 
 var (
-	ErrCreditNotEnough     = repository.ErrCreditNotEnough
-	ErrDuplicatedCreditLog = repository.ErrDuplicatedCreditLog
-	ErrInvalidCreditLog    = errors.New("积分流水信息非法")
-	ErrRecordNotFound      = repository.ErrRecordNotFound
+	ErrCreditNotEnough      = repository.ErrCreditNotEnough
+	ErrDuplicatedCreditLog  = repository.ErrDuplicatedCreditLog
+	ErrInvalidCreditLog     = errors.New("积分流水信息非法")
+	ErrRecordNotFound       = repository.ErrRecordNotFound
+	ErrInvalidConfirmAmount = repository.ErrInvalidConfirmAmount
 )
 
 //go:generate mockgen -source=./service.go -destination=../../mocks/credit.mock.go -package=creditmocks -typed Service
@@ -37,6 +38,9 @@ type Service interface {
 	GetCreditsByUID(ctx context.Context, uid int64) (domain.Credit, error)
 	TryDeductCredits(ctx context.Context, credit domain.Credit) (id int64, err error)
 	ConfirmDeductCredits(ctx context.Context, uid, tid int64) error
+	// PartialConfirmDeductCredits 只确认 amount 这么多的预扣积分，剩余的部分返还给用户
+	// amount 不能超过预扣的积分
+	PartialConfirmDeductCredits(ctx context.Context, uid, tid int64, amount int64) error
 	CancelDeductCredits(ctx context.Context, uid, tid int64) error
 	FindExpiredLockedCreditLogs(ctx context.Context, offset int, limit int, ctime int64) ([]domain.CreditLog, int64, error)
 }
@@ -79,6 +83,13 @@ func (s *service) ConfirmDeductCredits(ctx context.Context, uid, tid int64) erro
 	return s.repo.ConfirmDeductCredits(ctx, uid, tid)
 }
 
+func (s *service) PartialConfirmDeductCredits(ctx context.Context, uid, tid int64, amount int64) error {
+	if amount < 0 {
+		return fmt.Errorf("%w: 确认扣减的积分不能为负数 %d", ErrInvalidConfirmAmount, amount)
+	}
+	return s.repo.PartialConfirmDeductCredits(ctx, uid, tid, uint64(amount))
+}
+
 func (s *service) CancelDeductCredits(ctx context.Context, uid, tid int64) error {
 	return s.repo.CancelDeductCredits(ctx, uid, tid)
 }
@@ -232,6 +232,44 @@ func (c *ServiceGetCreditsByUIDCall) DoAndReturn(f func(context.Context, int64)
 	return c
 }
 
+// PartialConfirmDeductCredits mocks base method.
+func (m *MockService) PartialConfirmDeductCredits(ctx context.Context, uid, tid, amount int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PartialConfirmDeductCredits", ctx, uid, tid, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PartialConfirmDeductCredits indicates an expected call of PartialConfirmDeductCredits.
+func (mr *MockServiceMockRecorder) PartialConfirmDeductCredits(ctx, uid, tid, amount any) *ServicePartialConfirmDeductCreditsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PartialConfirmDeductCredits", reflect.TypeOf((*MockService)(nil).PartialConfirmDeductCredits), ctx, uid, tid, amount)
+	return &ServicePartialConfirmDeductCreditsCall{Call: call}
+}
+
+// ServicePartialConfirmDeductCreditsCall wrap *gomock.Call
+type ServicePartialConfirmDeductCreditsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServicePartialConfirmDeductCreditsCall) Return(arg0 error) *ServicePartialConfirmDeductCreditsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServicePartialConfirmDeductCreditsCall) Do(f func(context.Context, int64, int64, int64) error) *ServicePartialConfirmDeductCreditsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServicePartialConfirmDeductCreditsCall) DoAndReturn(f func(context.Context, int64, int64, int64) error) *ServicePartialConfirmDeductCreditsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // TryDeductCredits mocks base method.
 func (m *MockService) TryDeductCredits(ctx context.Context, credit domain.Credit) (int64, error) {
 	m.ctrl.T.Helper()
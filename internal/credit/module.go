@@ -16,9 +16,12 @@ package credit
 
 import (
 	"github.com/ecodeclub/webook/internal/credit/internal/event"
+	"github.com/ecodeclub/webook/internal/credit/internal/service"
 	"github.com/ecodeclub/webook/internal/credit/internal/web"
 )
 
+var ErrCreditNotEnough = service.ErrCreditNotEnough
+
 type Module struct {
 	Hdl                          *web.Handler
 	Svc                          Service
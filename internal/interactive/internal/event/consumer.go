@@ -49,6 +49,7 @@ func NewSyncConsumer(svc service.Service, q mq.MQ) (*Consumer, error) {
 		"like":    c.likeHandle,
 		"collect": c.collectHandle,
 		"view":    c.viewHandle,
+		"delete":  c.deleteHandle,
 	}
 	c.handlerMap = handlerMap
 	return c, nil
@@ -63,6 +64,10 @@ func (c *Consumer) viewHandle(ctx context.Context, svc service.Service, evt Even
 	return svc.IncrReadCnt(ctx, evt.Biz, evt.BizId)
 }
 
+func (c *Consumer) deleteHandle(ctx context.Context, svc service.Service, evt Event) error {
+	return svc.Delete(ctx, evt.Biz, evt.BizId)
+}
+
 func (c *Consumer) Consume(ctx context.Context) error {
 	msg, err := c.consumer.Consume(ctx)
 	if err != nil {
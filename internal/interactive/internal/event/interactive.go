@@ -24,7 +24,7 @@ type Event struct {
 	Biz   string `json:"biz,omitempty"`
 	BizId int64  `json:"bizId,omitempty"`
 	// 取值是
-	// like, collect, view 三个
+	// like, collect, view, delete 四个
 	Action string `json:"action,omitempty"`
 	Uid    int64  `json:"uid,omitempty"`
 }
@@ -476,6 +476,40 @@ func (i *InteractiveTestSuite) Test_Event() {
 	}
 }
 
+func (i *InteractiveTestSuite) Test_DeleteEvent() {
+	t := i.T()
+	i.initInteractiveBizData("case", 1, 2, 2, 2)
+	// 其它资源的数据不受影响
+	i.initInteractiveBizData("case", 2, 1, 1, 1)
+	v, err := json.Marshal(event.Event{
+		Biz:    "case",
+		BizId:  1,
+		Action: "delete",
+	})
+	require.NoError(t, err)
+	_, err = i.producer.Produce(context.Background(), &mq.Message{
+		Value: v,
+	})
+	require.NoError(t, err)
+	time.Sleep(10 * time.Second)
+
+	_, err = i.intrDAO.Get(context.Background(), "case", 1)
+	assert.Equal(t, dao.ErrRecordNotFound, err)
+	_, err = i.intrDAO.GetLikeInfo(context.Background(), "case", 1, 3)
+	assert.Equal(t, dao.ErrRecordNotFound, err)
+	_, err = i.intrDAO.GetCollectInfo(context.Background(), "case", 1, 4)
+	assert.Equal(t, dao.ErrRecordNotFound, err)
+	intr, err := i.intrDAO.Get(context.Background(), "case", 2)
+	require.NoError(t, err)
+	i.assertInteractive(dao.Interactive{
+		Biz:        "case",
+		BizId:      2,
+		ViewCnt:    1,
+		LikeCnt:    1,
+		CollectCnt: 1,
+	}, intr)
+}
+
 func (i *InteractiveTestSuite) assertLikeBiz(want dao.UserLikeBiz, actual dao.UserLikeBiz) {
 	t := i.T()
 	require.True(t, actual.Id != 0)
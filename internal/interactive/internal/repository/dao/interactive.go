@@ -34,6 +34,8 @@ type InteractiveDAO interface {
 		biz string, id int64, uid int64) (UserCollectionBiz, error)
 	Get(ctx context.Context, biz string, id int64) (Interactive, error)
 	GetByIds(ctx context.Context, biz string, ids []int64) ([]Interactive, error)
+	// Delete 删除资源的计数以及点赞、收藏明细
+	Delete(ctx context.Context, biz string, id int64) error
 }
 
 type GORMInteractiveDAO struct {
@@ -217,3 +219,17 @@ func (g *GORMInteractiveDAO) GetByIds(ctx context.Context, biz string, ids []int
 		Find(&res).Error
 	return res, err
 }
+
+func (g *GORMInteractiveDAO) Delete(ctx context.Context, biz string, id int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("biz = ? AND biz_id = ?", biz, id).Delete(&UserLikeBiz{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("biz = ? AND biz_id = ?", biz, id).Delete(&UserCollectionBiz{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("biz = ? AND biz_id = ?", biz, id).Delete(&Interactive{}).Error
+	})
+}
@@ -32,6 +32,7 @@ type InteractiveRepository interface {
 	GetByIds(ctx context.Context, biz string, ids []int64) ([]domain.Interactive, error)
 	Liked(ctx context.Context, biz string, id int64, uid int64) (bool, error)
 	Collected(ctx context.Context, biz string, id int64, uid int64) (bool, error)
+	Delete(ctx context.Context, biz string, id int64) error
 }
 
 type interactiveRepository struct {
@@ -101,6 +102,10 @@ func (i *interactiveRepository) GetByIds(ctx context.Context, biz string, ids []
 	return list, nil
 }
 
+func (i *interactiveRepository) Delete(ctx context.Context, biz string, id int64) error {
+	return i.interactiveDao.Delete(ctx, biz, id)
+}
+
 func NewCachedInteractiveRepository(interactiveDao dao.InteractiveDAO) InteractiveRepository {
 	return &interactiveRepository{interactiveDao: interactiveDao}
 }
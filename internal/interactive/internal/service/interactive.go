@@ -32,6 +32,8 @@ type Service interface {
 	CollectToggle(ctx context.Context, biz string, bizId, uid int64) error
 	Get(ctx context.Context, biz string, id int64, uid int64) (domain.Interactive, error)
 	GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error)
+	// Delete 资源被彻底删除之后，清理它的计数以及点赞、收藏明细
+	Delete(ctx context.Context, biz string, id int64) error
 }
 
 type interactiveService struct {
@@ -52,6 +54,10 @@ func (i *interactiveService) LikeToggle(c context.Context, biz string, id int64,
 	return i.repo.LikeToggle(c, biz, id, uid)
 }
 
+func (i *interactiveService) Delete(ctx context.Context, biz string, id int64) error {
+	return i.repo.Delete(ctx, biz, id)
+}
+
 func (i *interactiveService) CollectToggle(ctx context.Context, biz string, bizId, uid int64) error {
 	return i.repo.CollectToggle(ctx, biz, bizId, uid)
 }
@@ -0,0 +1,63 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+type MatchMode uint8
+
+const (
+	// MatchAny 命中任意一个标签即可
+	MatchAny MatchMode = 0
+	// MatchAll 要求同时命中全部标签
+	MatchAll MatchMode = 1
+)
+
+func (m MatchMode) ToUint8() uint8 {
+	return uint8(m)
+}
+
+// Filter 题目、案例等内容按照标签筛选，标签是 label 模块里面的标签 ID
+type Filter struct {
+	LabelIds []int64
+	Mode     MatchMode
+}
+
+func (f Filter) IsEmpty() bool {
+	return len(f.LabelIds) == 0
+}
+
+func (f Filter) MatchAll() bool {
+	return f.Mode == MatchAll
+}
+
+// DistinctLabelIds 去重之后的标签 ID，按照全部命中筛选的时候依赖去重之后的数量
+func (f Filter) DistinctLabelIds() []int64 {
+	res := make([]int64, 0, len(f.LabelIds))
+	seen := make(map[int64]struct{}, len(f.LabelIds))
+	for _, id := range f.LabelIds {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		res = append(res, id)
+	}
+	return res
+}
+
+// Facet 某个标签下面有多少内容
+type Facet struct {
+	LabelId int64
+	Name    string
+	Cnt     int64
+}
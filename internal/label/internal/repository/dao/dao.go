@@ -19,12 +19,17 @@ import (
 	"time"
 
 	"github.com/ego-component/egorm"
+	"gorm.io/gorm/clause"
 )
 
 type LabelDAO interface {
 	UidLabels(ctx context.Context, uid int64) ([]Label, error)
 	CreateLabel(ctx context.Context, label Label) (int64, error)
 	GetByID(ctx context.Context, id int64) (Label, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]Label, error)
+	GetByNames(ctx context.Context, names []string) ([]Label, error)
+	// CreateLabels 批量创建标签，名字已经存在的会被忽略
+	CreateLabels(ctx context.Context, labels []Label) error
 }
 
 type LabelGORMDAO struct {
@@ -37,6 +42,29 @@ func (dao *LabelGORMDAO) GetByID(ctx context.Context, id int64) (Label, error) {
 	return res, err
 }
 
+func (dao *LabelGORMDAO) GetByIDs(ctx context.Context, ids []int64) ([]Label, error) {
+	var res []Label
+	err := dao.db.WithContext(ctx).Where("id IN ?", ids).Find(&res).Error
+	return res, err
+}
+
+func (dao *LabelGORMDAO) GetByNames(ctx context.Context, names []string) ([]Label, error) {
+	var res []Label
+	err := dao.db.WithContext(ctx).Where("name IN ?", names).Find(&res).Error
+	return res, err
+}
+
+func (dao *LabelGORMDAO) CreateLabels(ctx context.Context, labels []Label) error {
+	now := time.Now().UnixMilli()
+	for i := range labels {
+		labels[i].Ctime = now
+		labels[i].Utime = now
+	}
+	// 并发创建同名标签的时候，依赖唯一索引忽略掉重复的
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&labels).Error
+}
+
 func (dao *LabelGORMDAO) CreateLabel(ctx context.Context, label Label) (int64, error) {
 	now := time.Now().UnixMilli()
 	label.Ctime = now
@@ -25,6 +25,9 @@ import (
 type LabelRepository interface {
 	UidLabels(ctx context.Context, uid int64) ([]domain.Label, error)
 	CreateLabel(ctx context.Context, uid int64, name string) (int64, error)
+	CreateLabels(ctx context.Context, uid int64, names []string) error
+	GetByIDs(ctx context.Context, ids []int64) ([]domain.Label, error)
+	GetByNames(ctx context.Context, names []string) ([]domain.Label, error)
 }
 
 type CachedLabelRepository struct {
@@ -38,15 +41,36 @@ func (repo *CachedLabelRepository) CreateLabel(ctx context.Context, uid int64, n
 	})
 }
 
+func (repo *CachedLabelRepository) CreateLabels(ctx context.Context, uid int64, names []string) error {
+	return repo.dao.CreateLabels(ctx, slice.Map(names, func(idx int, src string) dao.Label {
+		return dao.Label{
+			Uid:  uid,
+			Name: src,
+		}
+	}))
+}
+
+func (repo *CachedLabelRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.Label, error) {
+	labels, err := repo.dao.GetByIDs(ctx, ids)
+	return slice.Map(labels, repo.toDomain), err
+}
+
+func (repo *CachedLabelRepository) GetByNames(ctx context.Context, names []string) ([]domain.Label, error) {
+	labels, err := repo.dao.GetByNames(ctx, names)
+	return slice.Map(labels, repo.toDomain), err
+}
+
 func (repo *CachedLabelRepository) UidLabels(ctx context.Context, uid int64) ([]domain.Label, error) {
 	labels, err := repo.dao.UidLabels(ctx, uid)
-	return slice.Map(labels, func(idx int, src dao.Label) domain.Label {
-		return domain.Label{
-			Id:   src.Id,
-			Uid:  src.Uid,
-			Name: src.Name,
-		}
-	}), err
+	return slice.Map(labels, repo.toDomain), err
+}
+
+func (repo *CachedLabelRepository) toDomain(idx int, src dao.Label) domain.Label {
+	return domain.Label{
+		Id:   src.Id,
+		Uid:  src.Uid,
+		Name: src.Name,
+	}
 }
 
 func NewCachedLabelRepository(dao dao.LabelDAO) LabelRepository {
@@ -16,6 +16,10 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ecodeclub/ekit/slice"
 
 	"github.com/ecodeclub/webook/internal/label/internal/domain"
 	"github.com/ecodeclub/webook/internal/label/internal/repository"
@@ -26,6 +30,12 @@ const systemUid int64 = -1
 type Service interface {
 	SystemLabels(ctx context.Context) ([]domain.Label, error)
 	CreateSystemLabel(ctx context.Context, name string) (int64, error)
+	// GetOrCreateSystemLabels 按照名字查找标签，不存在的会被创建为系统标签。
+	// 返回结果和去重之后的 names 顺序一致，空白的名字会被忽略
+	GetOrCreateSystemLabels(ctx context.Context, names []string) ([]domain.Label, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]domain.Label, error)
+	// FillFacetNames 补全标签的名字，找不到的标签会被忽略
+	FillFacetNames(ctx context.Context, facets []domain.Facet) ([]domain.Facet, error)
 }
 
 type service struct {
@@ -36,6 +46,95 @@ func (s *service) CreateSystemLabel(ctx context.Context, name string) (int64, er
 	return s.repo.CreateLabel(ctx, systemUid, name)
 }
 
+func (s *service) GetOrCreateSystemLabels(ctx context.Context, names []string) ([]domain.Label, error) {
+	names = s.normalize(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	labels, err := s.repo.GetByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) < len(names) {
+		err = s.repo.CreateLabels(ctx, systemUid, s.missing(names, labels))
+		if err != nil {
+			return nil, err
+		}
+		labels, err = s.repo.GetByNames(ctx, names)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// 数据库的排序规则一般是大小写不敏感的，所以这里也忽略大小写
+	m := make(map[string]domain.Label, len(labels))
+	for _, l := range labels {
+		m[strings.ToLower(l.Name)] = l
+	}
+	res := make([]domain.Label, 0, len(names))
+	for _, name := range names {
+		l, ok := m[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("标签 %s 创建失败", name)
+		}
+		res = append(res, l)
+	}
+	return res, nil
+}
+
+func (s *service) GetByIDs(ctx context.Context, ids []int64) ([]domain.Label, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return s.repo.GetByIDs(ctx, ids)
+}
+
+func (s *service) FillFacetNames(ctx context.Context, facets []domain.Facet) ([]domain.Facet, error) {
+	if len(facets) == 0 {
+		return facets, nil
+	}
+	labels, err := s.GetByIDs(ctx, slice.Map(facets, func(idx int, src domain.Facet) int64 {
+		return src.LabelId
+	}))
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(labels))
+	for _, l := range labels {
+		names[l.Id] = l.Name
+	}
+	return slice.FilterMap(facets, func(idx int, src domain.Facet) (domain.Facet, bool) {
+		name, ok := names[src.LabelId]
+		src.Name = name
+		return src, ok
+	}), nil
+}
+
+func (s *service) normalize(names []string) []string {
+	res := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok || name == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		res = append(res, name)
+	}
+	return res
+}
+
+func (s *service) missing(names []string, labels []domain.Label) []string {
+	exists := make(map[string]struct{}, len(labels))
+	for _, l := range labels {
+		exists[strings.ToLower(l.Name)] = struct{}{}
+	}
+	return slice.FilterMap(names, func(idx int, src string) (string, bool) {
+		_, ok := exists[strings.ToLower(src)]
+		return src, !ok
+	})
+}
+
 func (s *service) SystemLabels(ctx context.Context) ([]domain.Label, error) {
 	return s.repo.UidLabels(ctx, systemUid)
 }
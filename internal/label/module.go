@@ -0,0 +1,40 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package label
+
+import (
+	"github.com/ecodeclub/webook/internal/label/internal/domain"
+	"github.com/ecodeclub/webook/internal/label/internal/service"
+)
+
+type Module struct {
+	Svc Service
+	Hdl *Handler
+}
+
+type Service = service.Service
+
+type Label = domain.Label
+
+type Filter = domain.Filter
+
+type MatchMode = domain.MatchMode
+
+const (
+	MatchAny = domain.MatchAny
+	MatchAll = domain.MatchAll
+)
+
+type Facet = domain.Facet
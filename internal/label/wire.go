@@ -38,6 +38,11 @@ func InitHandler(db *egorm.Component) *Handler {
 	return new(Handler)
 }
 
+func InitModule(db *egorm.Component) *Module {
+	wire.Build(HandlerSet, wire.Struct(new(Module), "*"))
+	return new(Module)
+}
+
 var once = &sync.Once{}
 
 func InitTablesOnce(db *egorm.Component) dao.LabelDAO {
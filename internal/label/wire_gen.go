@@ -28,6 +28,18 @@ func InitHandler(db *gorm.DB) *web.Handler {
 	return handler
 }
 
+func InitModule(db *gorm.DB) *Module {
+	labelDAO := InitTablesOnce(db)
+	labelRepository := repository.NewCachedLabelRepository(labelDAO)
+	serviceService := service.NewService(labelRepository)
+	handler := web.NewHandler(serviceService)
+	module := &Module{
+		Svc: serviceService,
+		Hdl: handler,
+	}
+	return module
+}
+
 // wire.go:
 
 var HandlerSet = wire.NewSet(repository.NewCachedLabelRepository, InitTablesOnce, service.NewService, web.NewHandler)
@@ -0,0 +1,129 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Exam 基于题集的模拟考试
+type Exam struct {
+	Id        int64
+	Uid       int64
+	Qsid      int64
+	Questions []ExamQuestion
+	Status    ExamStatus
+	// 截止时间，超过了之后自动交卷
+	Deadline int64
+	// 交卷的时间
+	FinishTime int64
+	// 整体的评级，交卷之后才有
+	Level Result
+	// 薄弱的标签，出现次数多的在前面，交卷之后才有
+	WeakLabels []string
+	Ctime      int64
+}
+
+// ExamQuestion 考试中的一道题目，题目的标题和标签是开始考试时候的快照
+type ExamQuestion struct {
+	Qid    int64
+	Title  string
+	Labels []string
+	// 用户的回答，每道题目只能回答一次
+	Input    string
+	Answered bool
+	// AI 的评价，对应的测试记录是 Tid
+	Result Result
+	Tid    string
+	// 开始回答的时间，正在调用 AI 评价的时候才有意义
+	ClaimTime int64
+}
+
+type ExamStatus uint8
+
+func (s ExamStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	ExamStatusUnknown ExamStatus = iota
+	ExamStatusInProgress
+	// ExamStatusFinished 用户主动交卷
+	ExamStatusFinished
+	// ExamStatusTimeout 超过截止时间自动交卷
+	ExamStatusTimeout
+)
+
+// Expired 进行中的考试已经超过了截止时间
+func (e Exam) Expired(now time.Time) bool {
+	return e.Status == ExamStatusInProgress && now.UnixMilli() >= e.Deadline
+}
+
+// Answering 有截止之前开始回答、还在等待 AI 评价的题目，占用时间早于 staleBefore 的不算
+func (e Exam) Answering(staleBefore time.Time) bool {
+	for _, q := range e.Questions {
+		if !q.Answered && q.ClaimTime > 0 && q.ClaimTime < e.Deadline &&
+			q.ClaimTime >= staleBefore.UnixMilli() {
+			return true
+		}
+	}
+	return false
+}
+
+// Question 找到考试中的题目
+func (e Exam) Question(qid int64) (ExamQuestion, bool) {
+	for _, q := range e.Questions {
+		if q.Qid == qid {
+			return q, true
+		}
+	}
+	return ExamQuestion{}, false
+}
+
+// Finish 交卷，没有回答的题目当作没有通过
+// 整体的评级是所有题目评级的平均值，向下取整
+// 没有达到 25K 的题目的标签都算作薄弱的标签
+func (e Exam) Finish(status ExamStatus, now time.Time) Exam {
+	e.Status = status
+	e.FinishTime = now.UnixMilli()
+	if len(e.Questions) == 0 {
+		return e
+	}
+	var sum int
+	cnts := make(map[string]int)
+	for _, q := range e.Questions {
+		sum += int(q.Result)
+		if q.Result >= ResultIntermediate {
+			continue
+		}
+		for _, label := range q.Labels {
+			cnts[label]++
+		}
+	}
+	e.Level = Result(sum / len(e.Questions))
+	labels := make([]string, 0, len(cnts))
+	for label := range cnts {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if cnts[labels[i]] != cnts[labels[j]] {
+			return cnts[labels[i]] > cnts[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+	e.WeakLabels = labels
+	return e
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExam_Finish(t *testing.T) {
+	now := time.UnixMilli(123)
+	testCases := []struct {
+		name string
+		exam Exam
+
+		wantLevel      Result
+		wantWeakLabels []string
+	}{
+		{
+			name: "没有题目",
+			exam: Exam{},
+		},
+		{
+			name: "全部通过",
+			exam: Exam{Questions: []ExamQuestion{
+				{Result: ResultAdvanced, Labels: []string{"Redis"}},
+				{Result: ResultIntermediate, Labels: []string{"MySQL"}},
+			}},
+			wantLevel:      ResultIntermediate,
+			wantWeakLabels: []string{},
+		},
+		{
+			name: "没有回答的当作没有通过",
+			exam: Exam{Questions: []ExamQuestion{
+				{Result: ResultAdvanced, Labels: []string{"Redis"}, Answered: true},
+				{Result: ResultBasic, Labels: []string{"MySQL", "索引"}, Answered: true},
+				{Labels: []string{"MySQL"}},
+				{Labels: []string{"Go"}},
+			}},
+			wantLevel:      ResultBasic,
+			wantWeakLabels: []string{"MySQL", "Go", "索引"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exam := tc.exam.Finish(ExamStatusFinished, now)
+			assert.Equal(t, ExamStatusFinished, exam.Status)
+			assert.Equal(t, now.UnixMilli(), exam.FinishTime)
+			assert.Equal(t, tc.wantLevel, exam.Level)
+			assert.Equal(t, tc.wantWeakLabels, exam.WeakLabels)
+		})
+	}
+}
+
+func TestExam_Expired(t *testing.T) {
+	now := time.UnixMilli(1000)
+	assert.True(t, Exam{Status: ExamStatusInProgress, Deadline: 1000}.Expired(now))
+	assert.False(t, Exam{Status: ExamStatusInProgress, Deadline: 1001}.Expired(now))
+	assert.False(t, Exam{Status: ExamStatusFinished, Deadline: 1000}.Expired(now))
+}
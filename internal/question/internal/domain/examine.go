@@ -14,6 +14,14 @@
 
 package domain
 
+import (
+	"slices"
+	"time"
+)
+
+// ExamineLocation 按天统计测试次数使用的时区，和服务器、数据库的时区无关
+var ExamineLocation = time.FixedZone("UTC+8", 8*60*60)
+
 type ExamineResult struct {
 	Qid    int64
 	Result Result
@@ -25,6 +33,130 @@ type ExamineResult struct {
 	// 花费的金额
 	Amount int64
 	Tid    string
+
+	// 各个维度的得分，AI 没有返回结构化结果的时候都是 0
+	Scores ExamineScores
+	// 改进建议
+	Suggestions []ExamineSuggestion
+
+	// 用户的回答
+	Input  string
+	Status ExamineStatus
+	// 失败的原因，给用户看的
+	FailReason string
+	// 测试的时间
+	Ctime int64
+	// 最后一次更新的时间，异步测试用来判断是不是等待太久了
+	Utime int64
+}
+
+// ExamineStatus 测试记录的状态，同步测试的记录直接就是 ExamineStatusSucceeded
+type ExamineStatus uint8
+
+func (s ExamineStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	ExamineStatusUnknown ExamineStatus = iota
+	// ExamineStatusPending 异步测试已经提交，等待 AI 评价
+	ExamineStatusPending
+	ExamineStatusSucceeded
+	// ExamineStatusFailed 重试之后还是失败了，用户可以重新提交
+	ExamineStatusFailed
+	// ExamineStatusRunning 消费者已经抢到了这条记录，正在调用 AI
+	ExamineStatusRunning
+)
+
+// InProgress 异步测试还没有结果
+func (s ExamineStatus) InProgress() bool {
+	return s == ExamineStatusPending || s == ExamineStatusRunning
+}
+
+// ExamineScores 各个维度的得分，范围是 0-100
+type ExamineScores struct {
+	// 正确性
+	Correctness int
+	// 深度
+	Depth int
+	// 命中的亮点
+	Highlights int
+	// 覆盖的关键字
+	Keywords int
+}
+
+// ExamineSuggestion 改进建议
+type ExamineSuggestion struct {
+	// 关联的关键字，来自题目答案的 AnswerElement.Keywords
+	Keyword string
+	Content string
+}
+
+// ExamineEvent 流式测试中的一个事件
+type ExamineEvent struct {
+	// 增量的 AI 回答
+	Content string
+	// 是否已经结束，结束的时候 Result 是完整的测试结果
+	Done   bool
+	Result ExamineResult
+	Err    error
+}
+
+// ExamineQuota 测试的配额，0 表示不限制
+type ExamineQuota struct {
+	CallsPerDay  int64
+	CallsToday   int64
+	CallsLeft    int64
+	TokensPerDay int64
+	TokensToday  int64
+}
+
+// ExamineProgress 用户所有题目的测试进度
+type ExamineProgress struct {
+	// 最近每天的测试次数，没有测试的日子不返回
+	Days []ExamineDailyAttempts
+	// 每个评级的测试次数
+	Distribution []ExamineResultCount
+	// 每道题目的最好成绩，最近测试过的在前面
+	Best []ExamineBestResult
+}
+
+type ExamineDailyAttempts struct {
+	// 格式是 2006-01-02
+	Date     string
+	Attempts int64
+}
+
+// CountDailyAttempts 按照 ExamineLocation 的日期统计每天的测试次数，日期早的在前面
+// ctimes 是测试记录的创建时间，毫秒
+func CountDailyAttempts(ctimes []int64) []ExamineDailyAttempts {
+	res := make([]ExamineDailyAttempts, 0, 8)
+	idx := make(map[string]int, 8)
+	slices.Sort(ctimes)
+	for _, ctime := range ctimes {
+		date := time.UnixMilli(ctime).In(ExamineLocation).Format(time.DateOnly)
+		i, ok := idx[date]
+		if !ok {
+			i = len(res)
+			idx[date] = i
+			res = append(res, ExamineDailyAttempts{Date: date})
+		}
+		res[i].Attempts++
+	}
+	return res
+}
+
+type ExamineResultCount struct {
+	Result Result
+	Count  int64
+}
+
+type ExamineBestResult struct {
+	Qid      int64
+	Result   Result
+	Attempts int64
+	// 最后一次测试的时间
+	LastTime int64
 }
 
 type Result uint8
@@ -0,0 +1,66 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountDailyAttempts(t *testing.T) {
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, ExamineLocation)
+	testCases := []struct {
+		name   string
+		ctimes []int64
+		want   []ExamineDailyAttempts
+	}{
+		{
+			name: "没有测试",
+			want: []ExamineDailyAttempts{},
+		},
+		{
+			name: "乱序的多天",
+			ctimes: []int64{
+				day.AddDate(0, 0, 2).UnixMilli(),
+				day.Add(time.Hour).UnixMilli(),
+				day.UnixMilli(),
+				day.AddDate(0, 0, 2).Add(time.Minute).UnixMilli(),
+			},
+			want: []ExamineDailyAttempts{
+				{Date: "2024-06-01", Attempts: 2},
+				{Date: "2024-06-03", Attempts: 2},
+			},
+		},
+		{
+			// UTC 还是前一天，按照 ExamineLocation 已经是第二天了
+			name: "按照统计的时区分天",
+			ctimes: []int64{
+				time.Date(2024, 5, 31, 15, 59, 59, 0, time.UTC).UnixMilli(),
+				time.Date(2024, 5, 31, 16, 0, 0, 0, time.UTC).UnixMilli(),
+			},
+			want: []ExamineDailyAttempts{
+				{Date: "2024-05-31", Attempts: 1},
+				{Date: "2024-06-01", Attempts: 1},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, CountDailyAttempts(tc.ctimes))
+		})
+	}
+}
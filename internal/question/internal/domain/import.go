@@ -0,0 +1,70 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+// ImportFile 批量导入的文件，根据后缀区分格式
+// .csv 和 KnowledgeJobStarter 导出的格式一样，一行一道题目
+// .md 是带 front matter 的 Markdown，一个文件一道题目
+type ImportFile struct {
+	Name    string
+	Content string
+}
+
+type ImportAction uint8
+
+func (a ImportAction) ToUint8() uint8 {
+	return uint8(a)
+}
+
+const (
+	ImportActionUnknown ImportAction = iota
+	// ImportActionCreate 没有同名的题目，新建
+	ImportActionCreate
+	// ImportActionUpdate 制作库里面已经有同名的八股文题目，覆盖
+	ImportActionUpdate
+)
+
+// ImportItem 一道待导入的题目
+type ImportItem struct {
+	// 来源，文件名，CSV 还包括行号
+	Source   string
+	Question Question
+	Action   ImportAction
+	// 校验失败的原因，为空代表校验通过
+	Errors []string
+}
+
+// ImportReport 批量导入的报告
+type ImportReport struct {
+	DryRun bool
+	// 是否真的保存了
+	Imported bool
+	Items    []ImportItem
+	// 和具体题目无关的错误，例如题集不存在
+	Errors []string
+}
+
+// Valid 所有的题目都通过了校验
+func (r ImportReport) Valid() bool {
+	if len(r.Errors) > 0 {
+		return false
+	}
+	for _, item := range r.Items {
+		if len(item.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import "time"
+
+// KnowledgeExportRun 一次导出知识库的记录，下游可以根据成功的记录自动刷新知识库
+type KnowledgeExportRun struct {
+	Id     int64
+	Format string
+	// 增量导出只导出 Since 之后更新过的题目，全量导出的 Since 是零值
+	Incremental bool
+	Status      KnowledgeExportStatus
+	// 导出的是更新时间在 Since 之后的题目，Watermark 是导出的题目里面最大的更新时间
+	// 下一次增量导出从 Watermark 往前一段时间开始，避免漏掉更新时间早于水位但是提交得比较晚的题目
+	Since     time.Time
+	Watermark time.Time
+	// 导出的文件
+	File   string
+	Count  int64
+	ErrMsg string
+
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+type KnowledgeExportStatus uint8
+
+func (s KnowledgeExportStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	KnowledgeExportStatusUnknown KnowledgeExportStatus = iota
+	KnowledgeExportStatusRunning
+	KnowledgeExportStatusSucceeded
+	KnowledgeExportStatusFailed
+)
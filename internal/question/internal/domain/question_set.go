@@ -34,6 +34,7 @@ type QuestionSet struct {
 
 	// 题集中引用的题目,
 	Questions []Question
+	Status    QuestionSetStatus
 
 	Utime time.Time
 }
@@ -43,3 +44,20 @@ func (set QuestionSet) Qids() []int64 {
 		return src.Id
 	})
 }
+
+// QuestionSetStatus 题集没有区分制作库和线上库，用状态控制是否对外展示
+type QuestionSetStatus uint8
+
+func (s QuestionSetStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	QuestionSetStatusUnknown QuestionSetStatus = iota
+	// QuestionSetStatusUnPublished 已下线
+	QuestionSetStatusUnPublished
+	// QuestionSetStatusPublished 对外展示，新建的题集默认就是这个状态
+	QuestionSetStatusPublished
+	// QuestionSetStatusDeleted 已删除，在回收站里面，可以恢复
+	QuestionSetStatusDeleted
+)
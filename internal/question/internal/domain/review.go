@@ -0,0 +1,103 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// DefaultEaseFactor SM-2 的初始难度系数 2.5，放大了 1000 倍
+	DefaultEaseFactor = 2500
+	// MinEaseFactor SM-2 的难度系数下限 1.3，放大了 1000 倍
+	MinEaseFactor = 1300
+)
+
+// Review 用户某道题目的复习计划，使用 SM-2 算法根据测试结果安排下一次复习的时间
+type Review struct {
+	Uid int64
+	Qid int64
+	// 连续通过的次数，没有通过就从 0 开始
+	Repetitions int
+	// 距离下一次复习的天数
+	Interval int
+	// 难度系数，越大复习间隔增长得越快，放大了 1000 倍
+	EaseFactor int
+	LastResult Result
+	// 最后一次测试的时间
+	LastReview int64
+	// 最后一次按照计划复习的时间，用来统计每天复习了多少道题目
+	ReviewedAt int64
+	NextReview int64
+}
+
+// Next 根据 at 时刻的测试结果计算下一次复习的时间
+func (r Review) Next(result Result, at time.Time) Review {
+	// 到期了的才算是按照计划复习
+	if r.NextReview > 0 && r.NextReview < EndOfDay(at).UnixMilli() {
+		r.ReviewedAt = at.UnixMilli()
+	}
+	ef := r.EaseFactor
+	if ef == 0 {
+		ef = DefaultEaseFactor
+	}
+	q := result.quality()
+	if q < 3 {
+		r.Repetitions = 0
+		r.Interval = 1
+	} else {
+		r.Repetitions++
+		switch r.Repetitions {
+		case 1:
+			r.Interval = 1
+		case 2:
+			r.Interval = 6
+		default:
+			r.Interval = int(math.Round(float64(r.Interval) * float64(ef) / 1000))
+		}
+	}
+	// EF' = EF + (0.1 - (5 - q) * (0.08 + (5 - q) * 0.02))
+	d := 5 - q
+	r.EaseFactor = max(ef+100-d*(80+d*20), MinEaseFactor)
+	r.LastResult = result
+	r.LastReview = at.UnixMilli()
+	r.NextReview = at.AddDate(0, 0, r.Interval).UnixMilli()
+	return r
+}
+
+// quality 把测试结果映射为 SM-2 的回答质量 0-5，没有通过的当作记错了
+func (r Result) quality() int {
+	switch r {
+	case ResultAdvanced:
+		return 5
+	case ResultIntermediate:
+		return 4
+	case ResultBasic:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// StartOfDay t 当天的零点
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay t 第二天的零点
+func EndOfDay(t time.Time) time.Time {
+	return StartOfDay(t).AddDate(0, 0, 1)
+}
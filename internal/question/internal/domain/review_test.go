@@ -0,0 +1,92 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReview_Next(t *testing.T) {
+	at := time.Date(2024, 6, 1, 10, 0, 0, 0, time.Local)
+	testCases := []struct {
+		name   string
+		review Review
+		result Result
+
+		wantReview Review
+	}{
+		{
+			name:   "第一次通过",
+			result: ResultAdvanced,
+			wantReview: Review{
+				Repetitions: 1,
+				Interval:    1,
+				EaseFactor:  2600,
+				LastResult:  ResultAdvanced,
+				LastReview:  at.UnixMilli(),
+				NextReview:  at.AddDate(0, 0, 1).UnixMilli(),
+			},
+		},
+		{
+			name:   "第二次通过",
+			review: Review{Repetitions: 1, Interval: 1, EaseFactor: 2500},
+			result: ResultIntermediate,
+			wantReview: Review{
+				Repetitions: 2,
+				Interval:    6,
+				EaseFactor:  2500,
+				LastResult:  ResultIntermediate,
+				LastReview:  at.UnixMilli(),
+				NextReview:  at.AddDate(0, 0, 6).UnixMilli(),
+			},
+		},
+		{
+			name: "到期复习，间隔乘以难度系数",
+			review: Review{Repetitions: 2, Interval: 6, EaseFactor: 2500,
+				NextReview: at.Add(-time.Hour).UnixMilli()},
+			result: ResultBasic,
+			wantReview: Review{
+				Repetitions: 3,
+				Interval:    15,
+				EaseFactor:  2360,
+				LastResult:  ResultBasic,
+				LastReview:  at.UnixMilli(),
+				ReviewedAt:  at.UnixMilli(),
+				NextReview:  at.AddDate(0, 0, 15).UnixMilli(),
+			},
+		},
+		{
+			name:   "没有通过，重新开始",
+			review: Review{Repetitions: 3, Interval: 15, EaseFactor: 1400},
+			result: ResultFailed,
+			wantReview: Review{
+				Repetitions: 0,
+				Interval:    1,
+				EaseFactor:  MinEaseFactor,
+				LastResult:  ResultFailed,
+				LastReview:  at.UnixMilli(),
+				NextReview:  at.AddDate(0, 0, 1).UnixMilli(),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantReview, tc.review.Next(tc.result, at))
+		})
+	}
+}
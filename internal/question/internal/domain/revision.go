@@ -0,0 +1,70 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuestionRevision 每一次保存或者发布时候的题目快照
+// Question.Id 是题目的 id，Question.Uid 是这一次修改的人，Question.Status 区分保存和发布
+type QuestionRevision struct {
+	Id       int64
+	Question Question
+	Ctime    time.Time
+}
+
+// FieldDiff 两个版本之间某个字段的变化，答案元素的字段形如 basic.content
+type FieldDiff struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Diff 从 r 到 other 有变化的字段，顺序是固定的
+func (r QuestionRevision) Diff(other QuestionRevision) []FieldDiff {
+	var res []FieldDiff
+	add := func(field, old, new string) {
+		if old != new {
+			res = append(res, FieldDiff{Field: field, Old: old, New: new})
+		}
+	}
+	oldQue, newQue := r.Question, other.Question
+	add("title", oldQue.Title, newQue.Title)
+	add("content", oldQue.Content, newQue.Content)
+	add("labels", strings.Join(oldQue.Labels, ","), strings.Join(newQue.Labels, ","))
+	add("biz", oldQue.Biz, newQue.Biz)
+	add("bizId", strconv.FormatInt(oldQue.BizId, 10), strconv.FormatInt(newQue.BizId, 10))
+	add("status", strconv.Itoa(int(oldQue.Status)), strconv.Itoa(int(newQue.Status)))
+	elements := []struct {
+		name     string
+		old, new AnswerElement
+	}{
+		{name: "analysis", old: oldQue.Answer.Analysis, new: newQue.Answer.Analysis},
+		{name: "basic", old: oldQue.Answer.Basic, new: newQue.Answer.Basic},
+		{name: "intermediate", old: oldQue.Answer.Intermediate, new: newQue.Answer.Intermediate},
+		{name: "advanced", old: oldQue.Answer.Advanced, new: newQue.Answer.Advanced},
+	}
+	for _, ele := range elements {
+		add(ele.name+".content", ele.old.Content, ele.new.Content)
+		add(ele.name+".keywords", ele.old.Keywords, ele.new.Keywords)
+		add(ele.name+".shorthand", ele.old.Shorthand, ele.new.Shorthand)
+		add(ele.name+".highlight", ele.old.Highlight, ele.new.Highlight)
+		add(ele.name+".guidance", ele.old.Guidance, ele.new.Guidance)
+	}
+	return res
+}
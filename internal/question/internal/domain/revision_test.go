@@ -0,0 +1,78 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuestionRevision_Diff(t *testing.T) {
+	old := QuestionRevision{
+		Id: 1,
+		Question: Question{
+			Id:      1,
+			Title:   "老的标题",
+			Content: "内容",
+			Labels:  []string{"MySQL"},
+			Status:  UnPublishedStatus,
+			Answer: Answer{
+				Analysis: AnswerElement{Content: "分析", Keywords: "老的关键字"},
+				Advanced: AnswerElement{Guidance: "引导点"},
+			},
+		},
+	}
+	testCases := []struct {
+		name     string
+		other    QuestionRevision
+		wantDiff []FieldDiff
+	}{
+		{
+			name:  "没有变化",
+			other: old,
+		},
+		{
+			name: "基础信息和答案都有变化",
+			other: QuestionRevision{
+				Id: 2,
+				Question: Question{
+					Id:      1,
+					Title:   "新的标题",
+					Content: "内容",
+					Labels:  []string{"MySQL", "事务"},
+					Status:  PublishedStatus,
+					Answer: Answer{
+						Analysis: AnswerElement{Content: "分析", Keywords: "新的关键字"},
+						Basic:    AnswerElement{Content: "基本回答"},
+					},
+				},
+			},
+			wantDiff: []FieldDiff{
+				{Field: "title", Old: "老的标题", New: "新的标题"},
+				{Field: "labels", Old: "MySQL", New: "MySQL,事务"},
+				{Field: "status", Old: "1", New: "2"},
+				{Field: "analysis.keywords", Old: "老的关键字", New: "新的关键字"},
+				{Field: "basic.content", Old: "", New: "基本回答"},
+				{Field: "advanced.guidance", Old: "引导点", New: ""},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantDiff, old.Diff(tc.other))
+		})
+	}
+}
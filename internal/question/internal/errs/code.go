@@ -4,6 +4,27 @@ var (
 	SystemError = ErrorCode{Code: 502001, Msg: "系统错误"}
 	// InsufficientCredit 这个不管说是客户端错误还是服务端错误，都有点勉强，所以随便用一个 5
 	InsufficientCredit = ErrorCode{Code: 502002, Msg: "积分不足"}
+	// QuotaExceeded 超过了使用次数限制，前端可以提示用户稍后再试或者明天再来
+	QuotaExceeded = ErrorCode{Code: 502003, Msg: "使用次数超过限制"}
+	// AIBudgetExceeded 管理后台的 AI 预算用完了，需要调整配置或者等下个月
+	AIBudgetExceeded = ErrorCode{Code: 502004, Msg: "AI 预算不足"}
+	// InputRejected 用户的输入没有通过 AI 的安全检查，例如试图让 AI 直接给高分
+	InputRejected = ErrorCode{Code: 502005, Msg: "输入包含不允许的内容"}
+	// ExamFinished 模拟考试已经交卷或者超时了
+	ExamFinished = ErrorCode{Code: 502006, Msg: "考试已经结束"}
+	// ExamAnswered 模拟考试中的题目只能回答一次
+	ExamAnswered = ErrorCode{Code: 502007, Msg: "题目已经回答过了"}
+	// ImportInvalid 批量导入的题目没有通过校验，Data 里面是校验报告
+	ImportInvalid = ErrorCode{Code: 502008, Msg: "导入的题目没有通过校验"}
+
+	// ExamineNotFound 测试记录不存在，例如前端传了错误的 Tid
+	ExamineNotFound = ErrorCode{Code: 402001, Msg: "测试记录不存在"}
+	// ExamineNotRetryable 只有失败了的，或者等待太久的异步测试才能重试
+	ExamineNotRetryable = ErrorCode{Code: 402002, Msg: "测试不能重试"}
+	// RevisionNotFound 历史版本不存在，例如前端传了别的题目的版本
+	RevisionNotFound = ErrorCode{Code: 402003, Msg: "历史版本不存在"}
+	// QuestionSetNotFound 题集不存在，或者已经下线、删除了
+	QuestionSetNotFound = ErrorCode{Code: 402004, Msg: "题集不存在"}
 )
 
 type ErrorCode struct {
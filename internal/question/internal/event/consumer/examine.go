@@ -0,0 +1,79 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ecodeclub/mq-api"
+	"github.com/ecodeclub/webook/internal/question/internal/event"
+	"github.com/ecodeclub/webook/internal/question/internal/service"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// ExamineConsumer 消费异步测试的消息，调用 AI 完成评价
+type ExamineConsumer struct {
+	svc      service.ExamineService
+	consumer mq.Consumer
+	logger   *elog.Component
+}
+
+func NewExamineConsumer(svc service.ExamineService, q mq.MQ) (*ExamineConsumer, error) {
+	const groupID = "question_examine"
+	consumer, err := q.Consumer(event.ExamineTopic, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return &ExamineConsumer{
+		svc:      svc,
+		consumer: consumer,
+		logger:   elog.DefaultLogger,
+	}, nil
+}
+
+func (c *ExamineConsumer) Start(ctx context.Context) {
+	go func() {
+		for {
+			err := c.Consume(ctx)
+			if err != nil {
+				c.logger.Error("消费异步测试事件失败", elog.FieldErr(err))
+			}
+		}
+	}()
+}
+
+func (c *ExamineConsumer) Consume(ctx context.Context) error {
+	msg, err := c.consumer.Consume(ctx)
+	if err != nil {
+		return fmt.Errorf("获取消息失败: %w", err)
+	}
+	var evt event.ExamineEvent
+	err = json.Unmarshal(msg.Value, &evt)
+	if err != nil {
+		return fmt.Errorf("解析消息失败: %w", err)
+	}
+	// 调用 AI 失败的时候测试记录会被标记为失败，用户可以自己重试
+	err = c.svc.HandleAsync(ctx, evt.Uid, evt.Tid)
+	if err != nil {
+		return fmt.Errorf("处理异步测试失败 tid %s: %w", evt.Tid, err)
+	}
+	return nil
+}
+
+func (c *ExamineConsumer) Stop(_ context.Context) error {
+	return c.consumer.Close()
+}
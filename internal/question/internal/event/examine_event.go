@@ -0,0 +1,51 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"github.com/ecodeclub/mq-api"
+	"github.com/ecodeclub/webook/internal/pkg/mqx"
+)
+
+const (
+	ExamineTopic       = "question_examine_events"
+	ExamineResultTopic = "question_examine_results"
+)
+
+type ExamineEventProducer mqx.Producer[ExamineEvent]
+
+func NewExamineEventProducer(p mq.MQ) (ExamineEventProducer, error) {
+	return mqx.NewGeneralProducer[ExamineEvent](p, ExamineTopic)
+}
+
+// ExamineEvent 异步测试，具体的输入在测试记录里面
+type ExamineEvent struct {
+	Uid int64  `json:"uid"`
+	Qid int64  `json:"qid"`
+	Tid string `json:"tid"`
+}
+
+type ExamineResultEventProducer mqx.Producer[ExamineResultEvent]
+
+func NewExamineResultEventProducer(p mq.MQ) (ExamineResultEventProducer, error) {
+	return mqx.NewGeneralProducer[ExamineResultEvent](p, ExamineResultTopic)
+}
+
+// ExamineResultEvent 测试结果已经记录下来了，依赖测试结果的模块可以据此更新自己的数据
+type ExamineResultEvent struct {
+	Uid    int64 `json:"uid"`
+	Qid    int64 `json:"qid"`
+	Result uint8 `json:"result"`
+}
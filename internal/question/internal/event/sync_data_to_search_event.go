@@ -20,10 +20,15 @@ import (
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
 )
 
+// syncOpDelete 让搜索删除对应的文档
+const syncOpDelete = "delete"
+
 type QuestionEvent struct {
 	Biz   string `json:"biz"`
 	BizID int    `json:"bizID"`
 	Data  string `json:"data"`
+	// Op 为空的时候写入或者更新文档
+	Op string `json:"op,omitempty"`
 }
 type Question struct {
 	ID      int64    `json:"id"`
@@ -85,6 +90,16 @@ func NewQuestionSetEvent(q domain.QuestionSet) QuestionEvent {
 		Data:  string(qByte),
 	}
 }
+
+// NewSyncDeleteEvent 内容下线或者删除之后，从搜索里面移除
+func NewSyncDeleteEvent(biz string, id int64) QuestionEvent {
+	return QuestionEvent{
+		Biz:   biz,
+		BizID: int(id),
+		Op:    syncOpDelete,
+	}
+}
+
 func newQuestionSet(q domain.QuestionSet) QuestionSet {
 	qids := make([]int64, 0, len(q.Questions))
 	for _, que := range q.Questions {
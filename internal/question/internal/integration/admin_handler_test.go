@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/ecodeclub/webook/internal/ai"
+	aimocks "github.com/ecodeclub/webook/internal/ai/mocks"
 
 	"github.com/ecodeclub/webook/internal/permission"
 
@@ -38,6 +39,7 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/errs"
 
 	"gorm.io/gorm"
 
@@ -65,6 +67,7 @@ type AdminHandlerTestSuite struct {
 	rdb      ecache.Cache
 	dao      dao.QuestionDAO
 	producer *eveMocks.MockSyncEventProducer
+	aiSvc    *aimocks.MockService
 }
 
 func (s *AdminHandlerTestSuite) SetupSuite() {
@@ -101,7 +104,8 @@ func (s *AdminHandlerTestSuite) SetupSuite() {
 		return res, nil
 	}).AnyTimes()
 
-	module, err := startup.InitModule(s.producer, intrModule, &permission.Module{}, &ai.Module{})
+	s.aiSvc = aimocks.NewMockService(ctrl)
+	module, err := startup.InitModule(s.producer, intrModule, &permission.Module{}, &ai.Module{Svc: s.aiSvc})
 	require.NoError(s.T(), err)
 	econf.Set("server", map[string]any{"contextTimeout": "1s"})
 	server := egin.Load("server").Build()
@@ -311,6 +315,109 @@ func (s *AdminHandlerTestSuite) TestSave() {
 	}
 }
 
+func (s *AdminHandlerTestSuite) TestGenerateDraft() {
+	que := web.Question{
+		Id:      12,
+		Title:   "Redis 为什么快",
+		Content: "说说 Redis 为什么快",
+		Labels:  []string{"Redis"},
+		Basic:   web.AnswerElement{Id: 34, Content: "老的基本回答"},
+	}
+	draft := `这是生成的草稿：
+` + "```json" + `
+{
+  "analysis": {"content": "分析", "keywords": "分析关键字", "shorthand": "分析速记", "highlight": "分析亮点", "guidance": "分析引导点"},
+  "basic": {"content": "基本回答", "keywords": "基本关键字"},
+  "intermediate": {"content": "进阶回答", "shorthand": "进阶速记"},
+  "advanced": {"content": "高阶回答", "highlight": "高阶亮点"}
+}
+` + "```"
+	testCases := []struct {
+		name   string
+		before func(t *testing.T)
+
+		req      web.SaveReq
+		wantCode int
+		wantResp test.Result[web.SaveReq]
+	}{
+		{
+			name: "生成成功",
+			before: func(t *testing.T) {
+				s.aiSvc.EXPECT().Invoke(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, req ai.LLMRequest) (ai.LLMResponse, error) {
+						assert.Equal(t, int64(uid), req.Uid)
+						assert.Equal(t, "question_answer_draft", req.Biz)
+						assert.Equal(t, []string{que.Title, que.Content}, req.Input)
+						return ai.LLMResponse{Tokens: 100, Amount: 10, Answer: draft}, nil
+					})
+			},
+			req:      web.SaveReq{Question: que},
+			wantCode: 200,
+			wantResp: test.Result[web.SaveReq]{
+				Data: web.SaveReq{
+					Question: web.Question{
+						Id:      12,
+						Title:   "Redis 为什么快",
+						Content: "说说 Redis 为什么快",
+						Labels:  []string{"Redis"},
+						Analysis: web.AnswerElement{
+							Content:   "分析",
+							Keywords:  "分析关键字",
+							Shorthand: "分析速记",
+							Highlight: "分析亮点",
+							Guidance:  "分析引导点",
+						},
+						// 保留原本的 id
+						Basic:        web.AnswerElement{Id: 34, Content: "基本回答", Keywords: "基本关键字"},
+						Intermediate: web.AnswerElement{Content: "进阶回答", Shorthand: "进阶速记"},
+						Advanced:     web.AnswerElement{Content: "高阶回答", Highlight: "高阶亮点"},
+					},
+				},
+			},
+		},
+		{
+			name: "预算不足",
+			before: func(t *testing.T) {
+				s.aiSvc.EXPECT().Invoke(gomock.Any(), gomock.Any()).
+					Return(ai.LLMResponse{}, fmt.Errorf("%w, 本月预算 100", ai.ErrBudgetExceeded))
+			},
+			req:      web.SaveReq{Question: que},
+			wantCode: 200,
+			wantResp: test.Result[web.SaveReq]{
+				Code: 502004,
+				Msg:  "AI 预算不足",
+			},
+		},
+		{
+			name: "无法解析",
+			before: func(t *testing.T) {
+				s.aiSvc.EXPECT().Invoke(gomock.Any(), gomock.Any()).
+					Return(ai.LLMResponse{Answer: "我不知道"}, nil)
+			},
+			req:      web.SaveReq{Question: que},
+			wantCode: 500,
+			wantResp: test.Result[web.SaveReq]{
+				Code: 502001,
+				Msg:  "系统错误",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			tc.before(t)
+			req, err := http.NewRequest(http.MethodPost,
+				"/question/draft", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.SaveReq]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan())
+		})
+	}
+}
+
 func (s *AdminHandlerTestSuite) TestSync() {
 	testCases := []struct {
 		name   string
@@ -598,6 +705,314 @@ func (s *AdminHandlerTestSuite) assertAnswerElement(
 	assert.Equal(t, expect, ele)
 }
 
+func (s *AdminHandlerTestSuite) TestImport() {
+	csvFile := web.ImportFile{
+		Name: "genknow.csv",
+		Content: "问题,标签,问题描述,问题分析,15K 答案,25K 答案,35K 答案\n" +
+			"老的题目,MySQL,新的内容,\"新的分析\n关键字：新的关键字\",新的 15K,新的 25K,新的 35K\n",
+	}
+	mdFile := web.ImportFile{
+		Name:    "redis.md",
+		Content: "---\ntitle: 新的题目\nlabels: Redis\n---\n新的题目内容\n## 15K 答案\n15K 答案\n",
+	}
+	testCases := []struct {
+		name   string
+		before func(t *testing.T)
+		after  func(t *testing.T)
+		req    web.ImportReq
+
+		wantCode int
+		wantResp test.Result[web.ImportReport]
+	}{
+		{
+			name:   "只校验，不保存",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				var cnt int64
+				err := s.db.Model(&dao.Question{}).Count(&cnt).Error
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), cnt)
+			},
+			req: web.ImportReq{
+				Files:  []web.ImportFile{csvFile, mdFile},
+				DryRun: true,
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ImportReport]{
+				Data: web.ImportReport{
+					DryRun: true,
+					Items: []web.ImportItem{
+						{Source: "genknow.csv:2", Title: "老的题目", Labels: []string{"MySQL"}, Qid: 1, Action: domain.ImportActionUpdate.ToUint8()},
+						{Source: "redis.md", Title: "新的题目", Labels: []string{"Redis"}, Action: domain.ImportActionCreate.ToUint8()},
+					},
+				},
+			},
+		},
+		{
+			name:   "校验失败，不保存",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				var cnt int64
+				err := s.db.Model(&dao.Question{}).Count(&cnt).Error
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), cnt)
+			},
+			req: web.ImportReq{
+				Files: []web.ImportFile{
+					mdFile,
+					{Name: "copy.md", Content: "---\ntitle: 新的题目\n---\n"},
+					{Name: "empty.md", Content: "---\nlabels: Go\n---\n"},
+				},
+				Publish: true,
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ImportReport]{
+				Code: 502008,
+				Msg:  "导入的题目没有通过校验",
+				Data: web.ImportReport{
+					Items: []web.ImportItem{
+						{Source: "redis.md", Title: "新的题目", Labels: []string{"Redis"}, Action: domain.ImportActionCreate.ToUint8()},
+						{Source: "copy.md", Title: "新的题目", Action: domain.ImportActionCreate.ToUint8(), Errors: []string{"和 redis.md 的标题重复"}},
+						{Source: "empty.md", Labels: []string{"Go"}, Action: domain.ImportActionCreate.ToUint8(), Errors: []string{"缺少标题"}},
+					},
+				},
+			},
+		},
+		{
+			name:   "题集不存在",
+			before: func(t *testing.T) {},
+			after:  func(t *testing.T) {},
+			req: web.ImportReq{
+				Files: []web.ImportFile{mdFile},
+				Qsid:  100,
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ImportReport]{
+				Code: 502008,
+				Msg:  "导入的题目没有通过校验",
+				Data: web.ImportReport{
+					Items: []web.ImportItem{
+						{Source: "redis.md", Title: "新的题目", Labels: []string{"Redis"}, Action: domain.ImportActionCreate.ToUint8()},
+					},
+					Errors: []string{"题集 100 不存在"},
+				},
+			},
+		},
+		{
+			name: "发布并且加入题集",
+			before: func(t *testing.T) {
+				// 两道题目和一个题集
+				s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+			},
+			after: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				q, eles, err := s.dao.GetPubByID(ctx, 1)
+				require.NoError(t, err)
+				s.assertQuestion(t, dao.Question{
+					Uid:     uid,
+					Title:   "老的题目",
+					Content: "新的内容",
+					Biz:     domain.DefaultBiz,
+					Status:  domain.PublishedStatus.ToUint8(),
+					Labels:  sqlx.JsonColumn[[]string]{Valid: true, Val: []string{"MySQL"}},
+				}, dao.Question(q))
+				assert.Equal(t, 4, len(eles))
+				assert.Equal(t, "新的分析", eles[0].Content)
+				assert.Equal(t, "新的关键字", eles[0].Keywords)
+
+				q, _, err = s.dao.GetPubByID(ctx, 2)
+				require.NoError(t, err)
+				s.assertQuestion(t, dao.Question{
+					Uid:     uid,
+					Title:   "新的题目",
+					Content: "新的题目内容",
+					Biz:     domain.DefaultBiz,
+					Status:  domain.PublishedStatus.ToUint8(),
+					Labels:  sqlx.JsonColumn[[]string]{Valid: true, Val: []string{"Redis"}},
+				}, dao.Question(q))
+
+				var qids []int64
+				err = s.db.Model(&dao.QuestionSetQuestion{}).
+					Where("qs_id = ?", 1).Order("id ASC").Pluck("qid", &qids).Error
+				require.NoError(t, err)
+				assert.Equal(t, []int64{1, 2}, qids)
+			},
+			req: web.ImportReq{
+				Files:   []web.ImportFile{csvFile, mdFile},
+				Publish: true,
+				Qsid:    1,
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ImportReport]{
+				Data: web.ImportReport{
+					Imported: true,
+					Items: []web.ImportItem{
+						{Source: "genknow.csv:2", Title: "老的题目", Labels: []string{"MySQL"}, Qid: 1, Action: domain.ImportActionUpdate.ToUint8()},
+						{Source: "redis.md", Title: "新的题目", Labels: []string{"Redis"}, Qid: 2, Action: domain.ImportActionCreate.ToUint8()},
+					},
+				},
+			},
+		},
+		{
+			name: "保存为草稿",
+			before: func(t *testing.T) {
+				s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			after: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				q, eles, err := s.dao.GetByID(ctx, 2)
+				require.NoError(t, err)
+				s.assertQuestion(t, dao.Question{
+					Uid:     uid,
+					Title:   "新的题目",
+					Content: "新的题目内容",
+					Biz:     domain.DefaultBiz,
+					Status:  domain.UnPublishedStatus.ToUint8(),
+					Labels:  sqlx.JsonColumn[[]string]{Valid: true, Val: []string{"Redis"}},
+				}, q)
+				assert.Equal(t, 4, len(eles))
+				assert.Equal(t, "15K 答案", eles[1].Content)
+				_, _, err = s.dao.GetPubByID(ctx, 2)
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			},
+			req: web.ImportReq{
+				Files: []web.ImportFile{mdFile},
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ImportReport]{
+				Data: web.ImportReport{
+					Imported: true,
+					Items: []web.ImportItem{
+						{Source: "redis.md", Title: "新的题目", Labels: []string{"Redis"}, Qid: 2, Action: domain.ImportActionCreate.ToUint8()},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			err := s.db.Create(&dao.Question{Id: 1, Uid: uid, Title: "老的题目", Content: "老的内容"}).Error
+			require.NoError(t, err)
+			err = s.db.Create(&dao.QuestionSet{Id: 1, Uid: uid, Title: "题集"}).Error
+			require.NoError(t, err)
+			err = s.db.Create(&dao.QuestionSetQuestion{QSID: 1, QID: 1}).Error
+			require.NoError(t, err)
+			tc.before(t)
+			req, err := http.NewRequest(http.MethodPost,
+				"/question/import", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.ImportReport]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan())
+			tc.after(t)
+			s.TearDownTest()
+		})
+	}
+}
+
+func (s *AdminHandlerTestSuite) TestRevision() {
+	t := s.T()
+	// 保存一次，发布一次，一共两个版本
+	s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil).Times(5)
+	que := web.Question{
+		Title:        "老的标题",
+		Content:      "老的内容",
+		Labels:       []string{"MySQL"},
+		Analysis:     s.buildAnswerEle(0),
+		Basic:        s.buildAnswerEle(1),
+		Intermediate: s.buildAnswerEle(2),
+		Advanced:     s.buildAnswerEle(3),
+	}
+	qid := post[int64](t, s.server, "/question/save", web.SaveReq{Question: que}).Data
+	require.True(t, qid > 0)
+	que.Id = qid
+	que.Title = "新的标题"
+	que.Basic.Content = "新的 15K 答案"
+	post[int64](t, s.server, "/question/publish", web.SaveReq{Question: que})
+
+	list := post[web.RevisionList](t, s.server, "/question/revision/list",
+		web.RevisionListReq{Qid: qid, Limit: 10}).Data
+	require.Equal(t, int64(2), list.Total)
+	require.Len(t, list.Revisions, 2)
+	newRev, oldRev := list.Revisions[0], list.Revisions[1]
+	assert.Equal(t, "新的标题", newRev.Title)
+	assert.Equal(t, domain.PublishedStatus.ToUint8(), newRev.Status)
+	assert.Equal(t, uid, newRev.Uid)
+	assert.Equal(t, "老的标题", oldRev.Title)
+	assert.Equal(t, domain.UnPublishedStatus.ToUint8(), oldRev.Status)
+
+	detail := post[web.Revision](t, s.server, "/question/revision/detail",
+		web.RevisionReq{Qid: qid, Id: oldRev.Id}).Data
+	require.NotNil(t, detail.Question)
+	assert.Equal(t, "老的内容", detail.Question.Content)
+	assert.Equal(t, s.buildAnswerEle(1).Content, detail.Question.Basic.Content)
+
+	diff := post[web.RevisionDiff](t, s.server, "/question/revision/diff",
+		web.RevisionDiffReq{Qid: qid, From: oldRev.Id, To: newRev.Id}).Data
+	assert.Equal(t, []web.FieldDiff{
+		{Field: "title", Old: "老的标题", New: "新的标题"},
+		{Field: "status", Old: "1", New: "2"},
+		{Field: "basic.content", Old: s.buildAnswerEle(1).Content, New: "新的 15K 答案"},
+	}, diff.Diffs)
+
+	// 恢复为草稿，线上库不变
+	res := post[int64](t, s.server, "/question/revision/restore",
+		web.RevisionRestoreReq{Qid: qid, Id: oldRev.Id})
+	assert.Equal(t, qid, res.Data)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	q, eles, err := s.dao.GetByID(ctx, qid)
+	require.NoError(t, err)
+	assert.Equal(t, "老的标题", q.Title)
+	assert.Equal(t, domain.UnPublishedStatus.ToUint8(), q.Status)
+	assert.Equal(t, s.buildAnswerEle(1).Content, eles[1].Content)
+	pub, _, err := s.dao.GetPubByID(ctx, qid)
+	require.NoError(t, err)
+	assert.Equal(t, "新的标题", pub.Title)
+
+	// 恢复并且发布
+	post[int64](t, s.server, "/question/revision/restore",
+		web.RevisionRestoreReq{Qid: qid, Id: oldRev.Id, Publish: true})
+	pub, pubEles, err := s.dao.GetPubByID(ctx, qid)
+	require.NoError(t, err)
+	assert.Equal(t, "老的标题", pub.Title)
+	assert.Equal(t, s.buildAnswerEle(1).Content, pubEles[1].Content)
+
+	// 恢复也会产生新的版本
+	list = post[web.RevisionList](t, s.server, "/question/revision/list",
+		web.RevisionListReq{Qid: qid, Limit: 10}).Data
+	assert.Equal(t, int64(4), list.Total)
+
+	// 不是这道题目的版本
+	req, err := http.NewRequest(http.MethodPost,
+		"/question/revision/detail", iox.NewJSONReader(web.RevisionReq{Qid: qid + 1, Id: oldRev.Id}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.Revision]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	assert.Equal(t, errs.RevisionNotFound.Code, recorder.MustScan().Code)
+
+	// 删除题目之后历史版本还在，并且可以恢复
+	post[any](t, s.server, "/question/delete", web.Qid{Qid: qid})
+	list = post[web.RevisionList](t, s.server, "/question/revision/list",
+		web.RevisionListReq{Qid: qid}).Data
+	assert.Equal(t, int64(4), list.Total)
+	res = post[int64](t, s.server, "/question/revision/restore",
+		web.RevisionRestoreReq{Qid: qid, Id: oldRev.Id})
+	assert.Equal(t, qid, res.Data)
+	q, eles, err = s.dao.GetByID(ctx, qid)
+	require.NoError(t, err)
+	assert.Equal(t, "老的标题", q.Title)
+	assert.Equal(t, s.buildAnswerEle(1).Content, eles[1].Content)
+}
+
 func (s *AdminHandlerTestSuite) TestQuestionEvent() {
 	t := s.T()
 	ans := make([]event.Question, 0, 16)
@@ -34,6 +34,7 @@ import (
 	"github.com/ecodeclub/ginx/session"
 	"github.com/ecodeclub/webook/internal/interactive"
 	"github.com/ecodeclub/webook/internal/pkg/middleware"
+	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
 	"github.com/ecodeclub/webook/internal/question/internal/event"
 	eveMocks "github.com/ecodeclub/webook/internal/question/internal/event/mocks"
@@ -49,6 +50,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
 )
 
 type AdminSetHandlerTestSuite struct {
@@ -58,6 +60,7 @@ type AdminSetHandlerTestSuite struct {
 	dao            dao.QuestionDAO
 	questionSetDAO dao.QuestionSetDAO
 	producer       *eveMocks.MockSyncEventProducer
+	purgeJob       *baguwen.PurgeSetTrashJob
 }
 
 func (s *AdminSetHandlerTestSuite) SetupSuite() {
@@ -84,6 +87,7 @@ func (s *AdminSetHandlerTestSuite) SetupSuite() {
 	server.Use(middleware.NewCheckMembershipMiddlewareBuilder(nil).Build())
 
 	s.server = server
+	s.purgeJob = module.PurgeSetTrashJob
 	s.db = testioc.InitDB()
 	err = dao.InitTables(s.db)
 	require.NoError(s.T(), err)
@@ -120,6 +124,7 @@ func (s *AdminSetHandlerTestSuite) TestQuestionSet_Save() {
 					Biz:         "project",
 					BizId:       1,
 					Description: "mysql相关面试题",
+					Status:      domain.QuestionSetStatusPublished.ToUint8(),
 				}, qs)
 			},
 			req: web.QuestionSet{
@@ -163,6 +168,7 @@ func (s *AdminSetHandlerTestSuite) TestQuestionSet_Save() {
 					Biz:         "roadmap",
 					BizId:       2,
 					Description: "mq相关面试题",
+					Status:      domain.QuestionSetStatusPublished.ToUint8(),
 				}, qs)
 			},
 			req: web.QuestionSet{
@@ -905,6 +911,183 @@ func (s *AdminSetHandlerTestSuite) TestQuestionSet_Detail() {
 	}
 }
 
+func (s *AdminSetHandlerTestSuite) TestQuestionSet_StatusChange() {
+	testCases := []struct {
+		name   string
+		before func(t *testing.T)
+		path   string
+
+		wantStatus domain.QuestionSetStatus
+	}{
+		{
+			name: "下线",
+			before: func(t *testing.T) {
+				s.producer.EXPECT().Produce(gomock.Any(), event.NewSyncDeleteEvent(domain.QuestionSetBiz, 1)).Return(nil)
+			},
+			path:       "/question-sets/unpublish",
+			wantStatus: domain.QuestionSetStatusUnPublished,
+		},
+		{
+			name: "删除",
+			before: func(t *testing.T) {
+				s.producer.EXPECT().Produce(gomock.Any(), event.NewSyncDeleteEvent(domain.QuestionSetBiz, 1)).Return(nil)
+			},
+			path:       "/question-sets/delete",
+			wantStatus: domain.QuestionSetStatusDeleted,
+		},
+		{
+			name: "回收站里面的题集不能直接发表",
+			before: func(t *testing.T) {
+				err := s.questionSetDAO.Delete(context.Background(), 1)
+				require.NoError(t, err)
+			},
+			path:       "/question-sets/publish",
+			wantStatus: domain.QuestionSetStatusDeleted,
+		},
+		{
+			name: "恢复之后是下线状态",
+			before: func(t *testing.T) {
+				err := s.questionSetDAO.Delete(context.Background(), 1)
+				require.NoError(t, err)
+			},
+			path:       "/question-sets/restore",
+			wantStatus: domain.QuestionSetStatusUnPublished,
+		},
+		{
+			name: "重新发表",
+			before: func(t *testing.T) {
+				err := s.questionSetDAO.UpdateStatus(context.Background(), 1,
+					domain.QuestionSetStatusUnPublished.ToUint8())
+				require.NoError(t, err)
+				s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, evt event.QuestionEvent) error {
+						assert.Equal(t, domain.QuestionSetBiz, evt.Biz)
+						assert.Equal(t, 1, evt.BizID)
+						assert.Empty(t, evt.Op)
+						return nil
+					})
+			},
+			path:       "/question-sets/publish",
+			wantStatus: domain.QuestionSetStatusPublished,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			defer cancel()
+			id, err := s.questionSetDAO.Create(ctx, dao.QuestionSet{
+				Uid:   uid,
+				Title: "题集",
+				Biz:   "project",
+				BizId: 1,
+			})
+			require.NoError(t, err)
+			require.Equal(t, int64(1), id)
+			tc.before(t)
+
+			req, err := http.NewRequest(http.MethodPost,
+				tc.path, iox.NewJSONReader(web.QuestionSetID{QSID: id}))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[any]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+
+			qs, err := s.questionSetDAO.GetByID(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantStatus.ToUint8(), qs.Status)
+			// 只有已发表的题集会被 C 端看到
+			sets, err := s.questionSetDAO.GetByIDs(ctx, []int64{id})
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantStatus == domain.QuestionSetStatusPublished, len(sets) == 1)
+
+			err = s.db.Exec("TRUNCATE TABLE `question_sets`").Error
+			require.NoError(t, err)
+		})
+	}
+}
+
+func (s *AdminSetHandlerTestSuite) TestQuestionSet_TrashList() {
+	t := s.T()
+	err := s.db.Create(&[]dao.QuestionSet{
+		{Id: 1, Uid: uid, Title: "题集1", Status: domain.QuestionSetStatusUnPublished.ToUint8(), Utime: 1},
+		{Id: 2, Uid: uid, Title: "题集2", Status: domain.QuestionSetStatusDeleted.ToUint8(), Utime: 2},
+		{Id: 3, Uid: uid, Title: "题集3", Status: domain.QuestionSetStatusDeleted.ToUint8(), Utime: 3},
+	}).Error
+	require.NoError(t, err)
+	testCases := []struct {
+		name     string
+		path     string
+		wantResp web.QuestionSetList
+	}{
+		{
+			name: "回收站按照删除时间倒序",
+			path: "/question-sets/trash/list",
+			wantResp: web.QuestionSetList{
+				Total: 2,
+				QuestionSets: []web.QuestionSet{
+					{Id: 3, Title: "题集3", Biz: "baguwen", Status: domain.QuestionSetStatusDeleted.ToUint8(), Utime: 3},
+					{Id: 2, Title: "题集2", Biz: "baguwen", Status: domain.QuestionSetStatusDeleted.ToUint8(), Utime: 2},
+				},
+			},
+		},
+		{
+			name: "管理端列表不包含回收站",
+			path: "/question-sets/list",
+			wantResp: web.QuestionSetList{
+				Total: 1,
+				QuestionSets: []web.QuestionSet{
+					{Id: 1, Title: "题集1", Biz: "baguwen", Status: domain.QuestionSetStatusUnPublished.ToUint8(), Utime: 1},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				tc.path, iox.NewJSONReader(web.Page{Limit: 10}))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.QuestionSetList]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan().Data)
+		})
+	}
+}
+
+func (s *AdminSetHandlerTestSuite) TestPurgeSetTrashJob() {
+	t := s.T()
+	expired := time.Now().Add(-31 * 24 * time.Hour).UnixMilli()
+	err := s.db.Create(&[]dao.QuestionSet{
+		{Id: 1, Uid: uid, Title: "过期的题集", Status: domain.QuestionSetStatusDeleted.ToUint8(), Utime: expired},
+		{Id: 2, Uid: uid, Title: "刚删除的题集", Status: domain.QuestionSetStatusDeleted.ToUint8(), Utime: time.Now().UnixMilli()},
+		{Id: 3, Uid: uid, Title: "很久没有更新的题集", Status: domain.QuestionSetStatusPublished.ToUint8(), Utime: expired},
+	}).Error
+	require.NoError(t, err)
+	err = s.questionSetDAO.UpdateQuestionsByID(context.Background(), 1, []int64{1, 2})
+	require.NoError(t, err)
+	s.producer.EXPECT().Produce(gomock.Any(), event.NewSyncDeleteEvent(domain.QuestionSetBiz, 1)).Return(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	err = s.purgeJob.Run(ctx)
+	require.NoError(t, err)
+
+	_, err = s.questionSetDAO.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	var cnt int64
+	err = s.db.WithContext(ctx).Model(&dao.QuestionSetQuestion{}).Where("qs_id = ?", 1).Count(&cnt).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), cnt)
+	for _, id := range []int64{2, 3} {
+		_, err = s.questionSetDAO.GetByID(ctx, id)
+		assert.NoError(t, err)
+	}
+}
+
 func (s *AdminSetHandlerTestSuite) TestQuestionSetEvent() {
 	t := s.T()
 	ans := make([]event.QuestionSet, 0, 16)
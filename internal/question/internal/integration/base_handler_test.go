@@ -17,13 +17,16 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/ecodeclub/webook/internal/question/internal/web"
 
 	"github.com/ecodeclub/webook/internal/interactive"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
 	"github.com/ego-component/egorm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -54,6 +57,28 @@ func (s *BaseTestSuite) TearDownTest() {
 
 	err = s.db.Exec("TRUNCATE TABLE `question_results`").Error
 	require.NoError(s.T(), err)
+
+	err = s.db.Exec("TRUNCATE TABLE `question_revisions`").Error
+	require.NoError(s.T(), err)
+
+	err = s.db.Exec("TRUNCATE TABLE `publish_question_labels`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `labels`").Error
+	require.NoError(s.T(), err)
+	clearQuestionCache(s.T())
+}
+
+// clearQuestionCache 用例之间会复用题目和题集的 id，所以要清空详情缓存
+func clearQuestionCache(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	rdb := testioc.InitRedis()
+	keys, err := rdb.Keys(ctx, "webook:question:*").Result()
+	require.NoError(t, err)
+	if len(keys) > 0 {
+		err = rdb.Del(ctx, keys...).Err()
+		require.NoError(t, err)
+	}
 }
 
 // assertQuestionSetEqual 不比较 id
@@ -0,0 +1,334 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ekit/sqlx"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/ai"
+	aimocks "github.com/ecodeclub/webook/internal/ai/mocks"
+	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/permission"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/errs"
+	"github.com/ecodeclub/webook/internal/question/internal/integration/startup"
+	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/question/internal/web"
+	"github.com/ecodeclub/webook/internal/test"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/ego-component/egorm"
+	"github.com/gin-gonic/gin"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/server/egin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+// examSlowAI 模拟调用 AI 比较慢
+const examSlowAI = 300 * time.Millisecond
+
+type ExamHandlerTestSuite struct {
+	suite.Suite
+	server *egin.Component
+	db     *egorm.Component
+}
+
+func (s *ExamHandlerTestSuite) SetupSuite() {
+	ctrl := gomock.NewController(s.T())
+	aiSvc := aimocks.NewMockService(ctrl)
+	aiSvc.EXPECT().Invoke(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req ai.LLMRequest) (ai.LLMResponse, error) {
+		switch req.Input[1] {
+		case "积分不足":
+			return ai.LLMResponse{}, ai.ErrInsufficientCredit
+		case "截止":
+			// 调用 AI 比较慢，评价完成的时候已经超过了截止时间
+			time.Sleep(examSlowAI)
+		}
+		return ai.LLMResponse{Tokens: 10, Amount: 10, Answer: examineJSON, Structured: true}, nil
+	}).AnyTimes()
+	module, err := startup.InitModule(nil, &interactive.Module{}, &permission.Module{},
+		&ai.Module{Svc: aiSvc})
+	require.NoError(s.T(), err)
+	s.db = testioc.InitDB()
+	econf.Set("server", map[string]any{"contextTimeout": "1s"})
+	server := egin.Load("server").Build()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set(session.CtxSessionKey,
+			session.NewMemorySession(session.Claims{
+				Uid: uid,
+			}))
+	})
+	module.ExamHdl.MemberRoutes(server.Engine)
+	s.server = server
+
+	for i := int64(1); i <= 3; i++ {
+		title := fmt.Sprintf("测试题目%d", i)
+		labels := sqlx.JsonColumn[[]string]{Val: []string{fmt.Sprintf("标签%d", i)}, Valid: true}
+		err = s.db.Create(&dao.Question{Id: i, Title: title, Labels: labels}).Error
+		require.NoError(s.T(), err)
+		err = s.db.Create(&dao.PublishQuestion{Id: i, Title: title, Labels: labels}).Error
+		require.NoError(s.T(), err)
+	}
+	// 只在制作库里面的题目，不会出现在考试里面
+	err = s.db.Create(&dao.Question{Id: 4, Title: "测试题目4"}).Error
+	require.NoError(s.T(), err)
+	err = s.db.Create(&dao.QuestionSet{Id: 1, Title: "题集"}).Error
+	require.NoError(s.T(), err)
+	err = s.db.Create(&[]dao.QuestionSetQuestion{
+		{QSID: 1, QID: 1},
+		{QSID: 1, QID: 4},
+		{QSID: 1, QID: 2},
+		{QSID: 1, QID: 3},
+	}).Error
+	require.NoError(s.T(), err)
+}
+
+func (s *ExamHandlerTestSuite) TearDownTest() {
+	for _, table := range []string{"exams", "exam_questions", "examine_records",
+		"question_results", "question_reviews"} {
+		err := s.db.Exec("TRUNCATE TABLE `" + table + "`").Error
+		require.NoError(s.T(), err)
+	}
+}
+
+func (s *ExamHandlerTestSuite) TearDownSuite() {
+	for _, table := range []string{"questions", "publish_questions",
+		"question_sets", "question_set_questions"} {
+		err := s.db.Exec("TRUNCATE TABLE `" + table + "`").Error
+		require.NoError(s.T(), err)
+	}
+	clearQuestionCache(s.T())
+}
+
+func (s *ExamHandlerTestSuite) TestExam() {
+	t := s.T()
+	start := time.Now()
+	exam := post[web.Exam](t, s.server, "/question/exam/start", web.ExamStartReq{Qsid: 1, Count: 2}).Data
+	assert.True(t, exam.Id > 0)
+	assert.Equal(t, domain.ExamStatusInProgress.ToUint8(), exam.Status)
+	// 每道题目 5 分钟
+	assert.True(t, exam.Deadline >= start.Add(10*time.Minute).UnixMilli())
+	assert.Equal(t, []web.ExamQuestion{
+		{Qid: 1, Title: "测试题目1", Labels: []string{"标签1"}},
+		{Qid: 2, Title: "测试题目2", Labels: []string{"标签2"}},
+	}, exam.Questions)
+
+	// 积分不足，可以再次回答
+	res := post[any](t, s.server, "/question/exam/submit", web.ExamSubmitReq{Id: exam.Id, Qid: 1, Input: "积分不足"})
+	assert.Equal(t, errs.InsufficientCredit.Code, res.Code)
+	res = post[any](t, s.server, "/question/exam/submit", web.ExamSubmitReq{Id: exam.Id, Qid: 1, Input: "回答"})
+	assert.Equal(t, 0, res.Code)
+	// 每道题目只能回答一次
+	res = post[any](t, s.server, "/question/exam/submit", web.ExamSubmitReq{Id: exam.Id, Qid: 1, Input: "回答"})
+	assert.Equal(t, errs.ExamAnswered.Code, res.Code)
+
+	// 交卷之前看不到评价
+	detail := post[web.Exam](t, s.server, "/question/exam/detail", web.ExamIdReq{Id: exam.Id}).Data
+	assert.Equal(t, web.ExamQuestion{Qid: 1, Title: "测试题目1", Labels: []string{"标签1"},
+		Input: "回答", Answered: true}, detail.Questions[0])
+
+	report := post[web.Exam](t, s.server, "/question/exam/finish", web.ExamIdReq{Id: exam.Id}).Data
+	assert.Equal(t, domain.ExamStatusFinished.ToUint8(), report.Status)
+	assert.True(t, report.FinishTime > 0)
+	// (35K + 没有回答) / 2
+	assert.Equal(t, domain.ResultBasic.ToUint8(), report.Level)
+	assert.Equal(t, []string{"标签2"}, report.WeakLabels)
+	assert.Equal(t, domain.ResultAdvanced.ToUint8(), report.Questions[0].Result)
+	assert.True(t, len(report.Questions[0].Tid) > 0)
+	assert.Equal(t, domain.ResultFailed.ToUint8(), report.Questions[1].Result)
+
+	// 交卷之后不能回答，重复交卷返回同样的报告
+	res = post[any](t, s.server, "/question/exam/submit", web.ExamSubmitReq{Id: exam.Id, Qid: 2, Input: "回答"})
+	assert.Equal(t, errs.ExamFinished.Code, res.Code)
+	again := post[web.Exam](t, s.server, "/question/exam/finish", web.ExamIdReq{Id: exam.Id}).Data
+	assert.Equal(t, report, again)
+
+	// 同时也是一次普通的测试
+	var record dao.ExamineRecord
+	err := s.db.Where("uid = ? AND qid = ?", uid, 1).First(&record).Error
+	require.NoError(t, err)
+	assert.Equal(t, report.Questions[0].Tid, record.Tid)
+}
+
+func (s *ExamHandlerTestSuite) TestStartAll() {
+	t := s.T()
+	exam := post[web.Exam](t, s.server, "/question/exam/start", web.ExamStartReq{Qsid: 1}).Data
+	assert.Equal(t, []int64{1, 2, 3}, slice.Map(exam.Questions, func(idx int, src web.ExamQuestion) int64 {
+		return src.Qid
+	}))
+}
+
+func (s *ExamHandlerTestSuite) TestStartNotPublished() {
+	t := s.T()
+	err := s.db.Create(&[]dao.QuestionSet{
+		{Id: 2, Title: "未发表", Status: domain.QuestionSetStatusUnPublished.ToUint8()},
+		{Id: 3, Title: "回收站", Status: domain.QuestionSetStatusDeleted.ToUint8()},
+	}).Error
+	require.NoError(t, err)
+	err = s.db.Create(&[]dao.QuestionSetQuestion{
+		{QSID: 2, QID: 1},
+		{QSID: 3, QID: 1},
+	}).Error
+	require.NoError(t, err)
+	defer func() {
+		err = s.db.Where("id IN ?", []int64{2, 3}).Delete(&dao.QuestionSet{}).Error
+		require.NoError(t, err)
+		err = s.db.Where("qs_id IN ?", []int64{2, 3}).Delete(&dao.QuestionSetQuestion{}).Error
+		require.NoError(t, err)
+	}()
+
+	for _, qsid := range []int64{2, 3, 100} {
+		res := post[web.Exam](t, s.server, "/question/exam/start", web.ExamStartReq{Qsid: qsid})
+		assert.Equal(t, errs.QuestionSetNotFound.Code, res.Code)
+	}
+}
+
+func (s *ExamHandlerTestSuite) TestStartRandom() {
+	t := s.T()
+	exam := post[web.Exam](t, s.server, "/question/exam/start",
+		web.ExamStartReq{Qsid: 1, Count: 2, Random: true, Duration: 30}).Data
+	assert.Len(t, exam.Questions, 2)
+	assert.NotEqual(t, exam.Questions[0].Qid, exam.Questions[1].Qid)
+	assert.True(t, exam.Deadline-exam.Ctime >= (30*time.Minute).Milliseconds())
+}
+
+func (s *ExamHandlerTestSuite) TestTimeout() {
+	t := s.T()
+	deadline := time.Now().Add(-time.Minute).UnixMilli()
+	err := s.db.Create(&dao.Exam{Id: 10, Uid: uid, Qsid: 1,
+		Status: domain.ExamStatusInProgress.ToUint8(), Deadline: deadline}).Error
+	require.NoError(t, err)
+	err = s.db.Create(&dao.ExamQuestion{ExamId: 10, Qid: 1, Title: "测试题目1",
+		Labels: sqlx.JsonColumn[[]string]{Val: []string{"标签1"}, Valid: true}}).Error
+	require.NoError(t, err)
+
+	list := post[web.ExamList](t, s.server, "/question/exam/list", web.Page{Limit: 10}).Data
+	assert.Equal(t, web.ExamList{
+		Total: 1,
+		Exams: []web.Exam{
+			{
+				Id:         10,
+				Qsid:       1,
+				Status:     domain.ExamStatusTimeout.ToUint8(),
+				Deadline:   deadline,
+				FinishTime: deadline,
+				Level:      domain.ResultFailed.ToUint8(),
+				WeakLabels: []string{"标签1"},
+			},
+		},
+	}, list)
+
+	var exam dao.Exam
+	err = s.db.Where("id = ?", 10).First(&exam).Error
+	require.NoError(t, err)
+	assert.Equal(t, domain.ExamStatusTimeout.ToUint8(), exam.Status)
+}
+
+func (s *ExamHandlerTestSuite) TestSubmitClaimed() {
+	t := s.T()
+	exam := post[web.Exam](t, s.server, "/question/exam/start", web.ExamStartReq{Qsid: 1, Count: 2}).Data
+
+	// 别的请求正在回答
+	err := s.db.Model(&dao.ExamQuestion{}).Where("exam_id = ? AND qid = ?", exam.Id, 1).
+		Update("claim_time", time.Now().UnixMilli()).Error
+	require.NoError(t, err)
+	res := post[any](t, s.server, "/question/exam/submit", web.ExamSubmitReq{Id: exam.Id, Qid: 1, Input: "回答"})
+	assert.Equal(t, errs.ExamAnswered.Code, res.Code)
+
+	// 占用超时了，可以重新回答
+	err = s.db.Model(&dao.ExamQuestion{}).Where("exam_id = ? AND qid = ?", exam.Id, 1).
+		Update("claim_time", time.Now().Add(-time.Hour).UnixMilli()).Error
+	require.NoError(t, err)
+	res = post[any](t, s.server, "/question/exam/submit", web.ExamSubmitReq{Id: exam.Id, Qid: 1, Input: "回答"})
+	assert.Equal(t, 0, res.Code)
+
+}
+
+func (s *ExamHandlerTestSuite) TestSubmitPastDeadline() {
+	t := s.T()
+	exam := post[web.Exam](t, s.server, "/question/exam/start", web.ExamStartReq{Qsid: 1, Count: 2}).Data
+	err := s.db.Model(&dao.Exam{}).Where("id = ?", exam.Id).
+		Update("deadline", time.Now().Add(examSlowAI/2).UnixMilli()).Error
+	require.NoError(t, err)
+
+	// 截止之前开始回答，调用 AI 的时候超过了截止时间，积分已经扣了，回答要保存下来
+	res := post[any](t, s.server, "/question/exam/submit", web.ExamSubmitReq{Id: exam.Id, Qid: 1, Input: "截止"})
+	assert.Equal(t, 0, res.Code)
+	var que dao.ExamQuestion
+	err = s.db.Where("exam_id = ? AND qid = ?", exam.Id, 1).First(&que).Error
+	require.NoError(t, err)
+	assert.True(t, que.Answered)
+	assert.Equal(t, "截止", que.Input)
+
+	// 截止之后不能再回答
+	res = post[any](t, s.server, "/question/exam/submit", web.ExamSubmitReq{Id: exam.Id, Qid: 2, Input: "回答"})
+	assert.Equal(t, errs.ExamFinished.Code, res.Code)
+
+	// 自动交卷，评级包含截止之后才评价完的回答
+	report := post[web.Exam](t, s.server, "/question/exam/detail", web.ExamIdReq{Id: exam.Id}).Data
+	assert.Equal(t, domain.ExamStatusTimeout.ToUint8(), report.Status)
+	assert.Equal(t, domain.ResultAdvanced.ToUint8(), report.Questions[0].Result)
+	assert.Equal(t, domain.ResultBasic.ToUint8(), report.Level)
+}
+
+func (s *ExamHandlerTestSuite) TestDetailWaitAnswering() {
+	t := s.T()
+	deadline := time.Now().Add(-time.Minute).UnixMilli()
+	err := s.db.Create(&dao.Exam{Id: 11, Uid: uid, Qsid: 1,
+		Status: domain.ExamStatusInProgress.ToUint8(), Deadline: deadline}).Error
+	require.NoError(t, err)
+	// 截止之前开始回答，还在等待 AI 评价
+	err = s.db.Create(&dao.ExamQuestion{ExamId: 11, Qid: 1, Title: "测试题目1",
+		ClaimTime: deadline - 1}).Error
+	require.NoError(t, err)
+
+	detail := post[web.Exam](t, s.server, "/question/exam/detail", web.ExamIdReq{Id: 11}).Data
+	assert.Equal(t, domain.ExamStatusInProgress.ToUint8(), detail.Status)
+	res := post[any](t, s.server, "/question/exam/submit", web.ExamSubmitReq{Id: 11, Qid: 1, Input: "回答"})
+	assert.Equal(t, errs.ExamFinished.Code, res.Code)
+
+	// 占用超时了，说明回答的请求已经失败了，直接交卷
+	err = s.db.Model(&dao.ExamQuestion{}).Where("exam_id = ? AND qid = ?", 11, 1).
+		Update("claim_time", deadline-time.Hour.Milliseconds()).Error
+	require.NoError(t, err)
+	detail = post[web.Exam](t, s.server, "/question/exam/detail", web.ExamIdReq{Id: 11}).Data
+	assert.Equal(t, domain.ExamStatusTimeout.ToUint8(), detail.Status)
+}
+
+func post[T any](t *testing.T, server *egin.Component, path string, body any) test.Result[T] {
+	req, err := http.NewRequest(http.MethodPost, path, iox.NewJSONReader(body))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[T]()
+	server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	return recorder.MustScan()
+}
+
+func TestExamHandler(t *testing.T) {
+	suite.Run(t, new(ExamHandlerTestSuite))
+}
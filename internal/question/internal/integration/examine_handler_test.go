@@ -18,7 +18,10 @@ package integration
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -29,14 +32,21 @@ import (
 	"github.com/ecodeclub/webook/internal/permission"
 
 	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/mq-api"
 	"github.com/ecodeclub/webook/internal/interactive"
+	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/errs"
+	"github.com/ecodeclub/webook/internal/question/internal/event"
+	"github.com/ecodeclub/webook/internal/question/internal/event/consumer"
 	"github.com/ecodeclub/webook/internal/question/internal/integration/startup"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
 	"github.com/ecodeclub/webook/internal/question/internal/web"
 	"github.com/ecodeclub/webook/internal/test"
 	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/ecodeclub/webook/internal/test/mocks"
 	"github.com/ego-component/egorm"
 	"github.com/gin-gonic/gin"
 	"github.com/gotomicro/ego/core/econf"
@@ -46,26 +56,76 @@ import (
 	"github.com/stretchr/testify/suite"
 )
 
+const examineJSON = `{"level":"35K","scores":{"correctness":90,"depth":80,"highlights":70,"keywords":60},` +
+	`"suggestions":[{"keyword":"GC","content":"补充 GC 的过程"}]}`
+
 type ExamineHandlerTest struct {
 	suite.Suite
 	server *egin.Component
 	db     *egorm.Component
 	dao    dao.ExamineDAO
+	svc    baguwen.ExamineService
 }
 
 func (s *ExamineHandlerTest) SetupSuite() {
 	ctrl := gomock.NewController(s.T())
 	aiSvc := aimocks.NewMockService(ctrl)
 	aiSvc.EXPECT().Invoke(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req ai.LLMRequest) (ai.LLMResponse, error) {
+		// 第三个输入是题目的关键字
+		require.GreaterOrEqual(s.T(), len(req.Input), 3)
+		answer := "评分：15K"
+		// 只有约定了 JSON 格式的提示词模板，解析失败的时候才会重试
+		structured := false
+		switch {
+		case req.Input[1] == "积分不足":
+			return ai.LLMResponse{}, ai.ErrInsufficientCredit
+		case req.Input[1] == "结构化":
+			answer = "```json\n" + examineJSON + "\n```"
+			structured = true
+		case req.Input[1] == "重试" && req.Refresh:
+			// 第四个输入是上一次解析失败的原因
+			require.Len(s.T(), req.Input, 4)
+			assert.Contains(s.T(), req.Input[3], "没有找到 JSON")
+			answer = examineJSON
+			structured = true
+		case req.Input[1] == "重试":
+			structured = true
+		}
 		return ai.LLMResponse{
-			Tokens: req.Uid,
-			Amount: req.Uid,
-			Answer: "评分：15K",
+			Tokens:     req.Uid,
+			Amount:     req.Uid,
+			Answer:     answer,
+			Structured: structured,
 		}, nil
 	}).AnyTimes()
-	module, err := startup.InitModule(nil, &interactive.Module{}, &permission.Module{}, &ai.Module{Svc: aiSvc})
+	aiSvc.EXPECT().StreamInvoke(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req ai.LLMRequest) (<-chan ai.StreamEvent, error) {
+		ch := make(chan ai.StreamEvent, 3)
+		ch <- ai.StreamEvent{Content: `{"level":"25K",`}
+		ch <- ai.StreamEvent{Content: `"scores":{"correctness":60}}`}
+		ch <- ai.StreamEvent{Done: true, Response: ai.LLMResponse{
+			Tokens:     req.Uid,
+			Amount:     req.Uid,
+			Answer:     `{"level":"25K","scores":{"correctness":60}}`,
+			Structured: true,
+		}}
+		close(ch)
+		return ch, nil
+	}).AnyTimes()
+	quotaSvc := aimocks.NewMockQuotaService(ctrl)
+	quotaSvc.EXPECT().Usage(gomock.Any(), int64(uid), "question_examine").Return(ai.QuotaUsage{
+		Biz: "question_examine",
+		Limit: ai.QuotaLimit{
+			CallsPerDay:  10,
+			TokensPerDay: 1000,
+		},
+		CallsToday:  3,
+		TokensToday: 100,
+	}, nil).AnyTimes()
+	module, err := startup.InitModule(nil, &interactive.Module{}, &permission.Module{},
+		&ai.Module{Svc: aiSvc, QuotaSvc: quotaSvc})
 	require.NoError(s.T(), err)
 	hdl := module.ExamineHdl
+	s.svc = module.ExamineSvc
 	s.db = testioc.InitDB()
 	econf.Set("server", map[string]any{"contextTimeout": "1s"})
 	server := egin.Load("server").Build()
@@ -97,11 +157,14 @@ func (s *ExamineHandlerTest) TearDownTest() {
 	require.NoError(s.T(), err)
 	err = s.db.Exec("TRUNCATE TABLE `examine_records`").Error
 	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `question_reviews`").Error
+	require.NoError(s.T(), err)
 }
 
 func (s *ExamineHandlerTest) TearDownSuite() {
 	err := s.db.Exec("TRUNCATE TABLE `publish_questions`").Error
 	require.NoError(s.T(), err)
+	clearQuestionCache(s.T())
 }
 
 func (s *ExamineHandlerTest) TestExamine() {
@@ -141,6 +204,8 @@ func (s *ExamineHandlerTest) TestExamine() {
 					RawResult: "评分：15K",
 					Tokens:    uid,
 					Amount:    uid,
+					Input:     "测试一下",
+					Status:    domain.ExamineStatusSucceeded.ToUint8(),
 				}, record)
 
 				var queRes dao.QuestionResult
@@ -159,6 +224,16 @@ func (s *ExamineHandlerTest) TestExamine() {
 					Qid:    1,
 					Uid:    uid,
 				}, queRes)
+
+				// 安排了复习
+				var review dao.QuestionReview
+				err = s.db.WithContext(ctx).
+					Where("qid = ? AND uid = ?", 1, uid).
+					First(&review).Error
+				require.NoError(t, err)
+				assert.Equal(t, domain.ResultBasic.ToUint8(), review.LastResult)
+				assert.Equal(t, 1, review.Repetitions)
+				assert.Equal(t, review.LastReview+int64(24*time.Hour/time.Millisecond), review.NextReview)
 			},
 			req: web.ExamineReq{
 				Qid:   1,
@@ -167,9 +242,13 @@ func (s *ExamineHandlerTest) TestExamine() {
 			wantCode: 200,
 			wantResp: test.Result[web.ExamineResult]{
 				Data: web.ExamineResult{
+					Qid:       1,
+					Input:     "测试一下",
 					Result:    domain.ResultBasic.ToUint8(),
 					RawResult: "评分：15K",
+					Tokens:    uid,
 					Amount:    uid,
+					Status:    domain.ExamineStatusSucceeded.ToUint8(),
 				},
 			},
 		},
@@ -209,6 +288,8 @@ func (s *ExamineHandlerTest) TestExamine() {
 					RawResult: "评分：15K",
 					Tokens:    uid,
 					Amount:    uid,
+					Input:     "测试一下",
+					Status:    domain.ExamineStatusSucceeded.ToUint8(),
 				}, record)
 
 				var queRes dao.QuestionResult
@@ -235,9 +316,116 @@ func (s *ExamineHandlerTest) TestExamine() {
 			},
 			wantResp: test.Result[web.ExamineResult]{
 				Data: web.ExamineResult{
+					Qid:       2,
+					Input:     "测试一下",
 					Result:    domain.ResultBasic.ToUint8(),
 					RawResult: "评分：15K",
+					Tokens:    uid,
 					Amount:    uid,
+					Status:    domain.ExamineStatusSucceeded.ToUint8(),
+				},
+			},
+		},
+		{
+			name:   "结构化的结果",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				var record dao.ExamineRecord
+				err := s.db.Where("uid = ? ", uid).Order("id DESC").First(&record).Error
+				require.NoError(t, err)
+				assert.Equal(t, domain.ResultAdvanced.ToUint8(), record.Result)
+				assert.Equal(t, int64(uid), record.Tokens)
+				assert.Equal(t, 90, record.CorrectnessScore)
+				assert.Equal(t, 80, record.DepthScore)
+				assert.Equal(t, 70, record.HighlightScore)
+				assert.Equal(t, 60, record.KeywordScore)
+				assert.Equal(t, sqlx.JsonColumn[[]dao.ExamineSuggestion]{
+					Val:   []dao.ExamineSuggestion{{Keyword: "GC", Content: "补充 GC 的过程"}},
+					Valid: true,
+				}, record.Suggestions)
+
+				var queRes dao.QuestionResult
+				err = s.db.Where("qid = ? AND uid = ?", 1, uid).First(&queRes).Error
+				require.NoError(t, err)
+				assert.Equal(t, domain.ResultAdvanced.ToUint8(), queRes.Result)
+
+				// 最近一次的得分和改进建议
+				res, err := s.svc.QuestionResult(context.Background(), uid, 1)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ExamineScores{
+					Correctness: 90,
+					Depth:       80,
+					Highlights:  70,
+					Keywords:    60,
+				}, res.Scores)
+				assert.Equal(t, []domain.ExamineSuggestion{
+					{Keyword: "GC", Content: "补充 GC 的过程"},
+				}, res.Suggestions)
+				results, err := s.svc.GetResults(context.Background(), uid, []int64{1})
+				require.NoError(t, err)
+				assert.Equal(t, res, results[1])
+			},
+			req: web.ExamineReq{
+				Qid:   1,
+				Input: "结构化",
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ExamineResult]{
+				Data: web.ExamineResult{
+					Qid:       1,
+					Input:     "结构化",
+					Result:    domain.ResultAdvanced.ToUint8(),
+					RawResult: "```json\n" + examineJSON + "\n```",
+					Tokens:    uid,
+					Amount:    uid,
+					Scores: web.ExamineScores{
+						Correctness: 90,
+						Depth:       80,
+						Highlights:  70,
+						Keywords:    60,
+					},
+					Suggestions: []web.ExamineSuggestion{
+						{Keyword: "GC", Content: "补充 GC 的过程"},
+					},
+					Status: domain.ExamineStatusSucceeded.ToUint8(),
+				},
+			},
+		},
+		{
+			name:   "格式不对，重试成功",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				var record dao.ExamineRecord
+				err := s.db.Where("uid = ? ", uid).Order("id DESC").First(&record).Error
+				require.NoError(t, err)
+				assert.Equal(t, domain.ResultAdvanced.ToUint8(), record.Result)
+				assert.Equal(t, examineJSON, record.RawResult)
+				assert.Equal(t, int64(uid*2), record.Tokens)
+				assert.Equal(t, 90, record.CorrectnessScore)
+			},
+			req: web.ExamineReq{
+				Qid:   2,
+				Input: "重试",
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.ExamineResult]{
+				Data: web.ExamineResult{
+					Qid:       2,
+					Input:     "重试",
+					Result:    domain.ResultAdvanced.ToUint8(),
+					RawResult: examineJSON,
+					Tokens:    uid * 2,
+					Amount:    uid * 2,
+					Scores: web.ExamineScores{
+						Correctness: 90,
+						Depth:       80,
+						Highlights:  70,
+						Keywords:    60,
+					},
+					Suggestions: []web.ExamineSuggestion{
+						{Keyword: "GC", Content: "补充 GC 的过程"},
+					},
+					Status: domain.ExamineStatusSucceeded.ToUint8(),
 				},
 			},
 		},
@@ -253,12 +441,346 @@ func (s *ExamineHandlerTest) TestExamine() {
 			recorder := test.NewJSONResponseRecorder[web.ExamineResult]()
 			s.server.ServeHTTP(recorder, req)
 			require.Equal(t, tc.wantCode, recorder.Code)
-			assert.Equal(t, tc.wantResp, recorder.MustScan())
+			res := recorder.MustScan()
+			assert.True(t, len(res.Data.Tid) > 0)
+			res.Data.Tid = ""
+			assert.Equal(t, tc.wantResp, res)
 			tc.after(t)
 		})
 	}
 }
 
+func (s *ExamineHandlerTest) TestStreamExamine() {
+	t := s.T()
+	req, err := http.NewRequest(http.MethodPost,
+		"/question/examine/stream", iox.NewJSONReader(web.ExamineReq{
+			Qid:   1,
+			Input: "测试一下",
+		}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, "event:message")
+	assert.Contains(t, body, `{"content":"{\"level\":\"25K\","}`)
+	assert.Contains(t, body, "event:result")
+	assert.Contains(t, body, `"result":2`)
+	assert.Contains(t, body, fmt.Sprintf(`"tokens":%d`, uid))
+
+	var record dao.ExamineRecord
+	err = s.db.Where("uid = ? AND qid = ?", uid, 1).First(&record).Error
+	require.NoError(t, err)
+	assert.Equal(t, domain.ResultIntermediate.ToUint8(), record.Result)
+	assert.Equal(t, `{"level":"25K","scores":{"correctness":60}}`, record.RawResult)
+	assert.Equal(t, 60, record.CorrectnessScore)
+}
+
+func (s *ExamineHandlerTest) TestExamineAsync() {
+	testCases := []struct {
+		name  string
+		input string
+
+		wantResult     domain.Result
+		wantStatus     domain.ExamineStatus
+		wantFailReason string
+	}{
+		{
+			name:       "成功",
+			input:      "结构化",
+			wantResult: domain.ResultAdvanced,
+			wantStatus: domain.ExamineStatusSucceeded,
+		},
+		{
+			name:           "积分不足，不重试",
+			input:          "积分不足",
+			wantStatus:     domain.ExamineStatusFailed,
+			wantFailReason: "积分不足",
+		},
+	}
+	for _, tc := range testCases {
+		s.T().Run(tc.name, func(t *testing.T) {
+			// 提交
+			res := s.postTid(t, "/question/examine/async", web.ExamineReq{Qid: 1, Input: tc.input})
+			require.True(t, len(res.Tid) > 0)
+			assert.Equal(t, domain.ExamineStatusPending.ToUint8(), res.Status)
+			record, err := s.dao.GetRecordByTid(context.Background(), uid, res.Tid)
+			require.NoError(t, err)
+			assert.Equal(t, tc.input, record.Input)
+			assert.Equal(t, domain.ExamineStatusPending.ToUint8(), record.Status)
+
+			// 消费
+			s.consume(t, res.Tid)
+			res = s.postTid(t, "/question/examine/async/result", web.ExamineTidReq{Tid: res.Tid})
+			assert.Equal(t, tc.wantStatus.ToUint8(), res.Status)
+			assert.Equal(t, tc.wantResult.ToUint8(), res.Result)
+			assert.Equal(t, tc.wantFailReason, res.FailReason)
+
+			// 重复消费什么也不做
+			s.consume(t, res.Tid)
+			again := s.postTid(t, "/question/examine/async/result", web.ExamineTidReq{Tid: res.Tid})
+			assert.Equal(t, res, again)
+		})
+	}
+}
+
+func (s *ExamineHandlerTest) TestRetryAsync() {
+	t := s.T()
+	res := s.postTid(t, "/question/examine/async", web.ExamineReq{Qid: 2, Input: "积分不足"})
+	s.consume(t, res.Tid)
+	res = s.postTid(t, "/question/examine/async/result", web.ExamineTidReq{Tid: res.Tid})
+	require.Equal(t, domain.ExamineStatusFailed.ToUint8(), res.Status)
+
+	res = s.postTid(t, "/question/examine/async/retry", web.ExamineTidReq{Tid: res.Tid})
+	assert.Equal(t, domain.ExamineStatusPending.ToUint8(), res.Status)
+	record, err := s.dao.GetRecordByTid(context.Background(), uid, res.Tid)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ExamineStatusPending.ToUint8(), record.Status)
+	assert.Equal(t, "", record.FailReason)
+
+	// 等待中的不能重试
+	req, err := http.NewRequest(http.MethodPost,
+		"/question/examine/async/retry", iox.NewJSONReader(web.ExamineTidReq{Tid: res.Tid}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.ExamineResult]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	assert.Equal(t, errs.ExamineNotRetryable.Code, recorder.MustScan().Code)
+
+	// 等待太久的可以重试
+	err = s.db.Model(&dao.ExamineRecord{}).Where("tid = ?", res.Tid).
+		Update("utime", time.Now().Add(-time.Hour).UnixMilli()).Error
+	require.NoError(t, err)
+	res = s.postTid(t, "/question/examine/async/retry", web.ExamineTidReq{Tid: res.Tid})
+	assert.Equal(t, domain.ExamineStatusPending.ToUint8(), res.Status)
+	record, err = s.dao.GetRecordByTid(context.Background(), uid, res.Tid)
+	require.NoError(t, err)
+	assert.True(t, record.Utime > time.Now().Add(-time.Minute).UnixMilli())
+
+	// 执行太久的也可以重试，消费者可能已经挂了
+	err = s.db.Model(&dao.ExamineRecord{}).Where("tid = ?", res.Tid).
+		Updates(map[string]any{
+			"status": domain.ExamineStatusRunning.ToUint8(),
+			"utime":  time.Now().Add(-time.Hour).UnixMilli(),
+		}).Error
+	require.NoError(t, err)
+	res = s.postTid(t, "/question/examine/async/retry", web.ExamineTidReq{Tid: res.Tid})
+	assert.Equal(t, domain.ExamineStatusPending.ToUint8(), res.Status)
+	record, err = s.dao.GetRecordByTid(context.Background(), uid, res.Tid)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ExamineStatusPending.ToUint8(), record.Status)
+	assert.True(t, record.Utime > time.Now().Add(-time.Minute).UnixMilli())
+
+	// 不存在的测试记录
+	req, err = http.NewRequest(http.MethodPost,
+		"/question/examine/async/retry", iox.NewJSONReader(web.ExamineTidReq{Tid: "not-exist"}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder = test.NewJSONResponseRecorder[web.ExamineResult]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	assert.Equal(t, errs.ExamineNotFound.Code, recorder.MustScan().Code)
+}
+
+func (s *ExamineHandlerTest) TestHandleAsyncClaimed() {
+	t := s.T()
+	res := s.postTid(t, "/question/examine/async", web.ExamineReq{Qid: 1, Input: "结构化"})
+	// 模拟另外一个消费者已经抢到了这条记录
+	err := s.dao.UpdateStatus(context.Background(), uid, res.Tid,
+		domain.ExamineStatusPending.ToUint8(), domain.ExamineStatusRunning.ToUint8(), "")
+	require.NoError(t, err)
+
+	// 不会再调用 AI
+	s.consume(t, res.Tid)
+	record, err := s.dao.GetRecordByTid(context.Background(), uid, res.Tid)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ExamineStatusRunning.ToUint8(), record.Status)
+	assert.Equal(t, int64(0), record.Tokens)
+	// 前端看到的还是等待中
+	res = s.postTid(t, "/question/examine/async/result", web.ExamineTidReq{Tid: res.Tid})
+	assert.Equal(t, domain.ExamineStatusPending.ToUint8(), res.Status)
+}
+
+func (s *ExamineHandlerTest) TestSubscribeAsync() {
+	t := s.T()
+	res := s.postTid(t, "/question/examine/async", web.ExamineReq{Qid: 1, Input: "结构化"})
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		s.consume(t, res.Tid)
+	}()
+	req, err := http.NewRequest(http.MethodPost,
+		"/question/examine/async/subscribe", iox.NewJSONReader(web.ExamineTidReq{Tid: res.Tid}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, "event:result")
+	assert.Contains(t, body, fmt.Sprintf(`"status":%d`, domain.ExamineStatusSucceeded))
+	assert.Contains(t, body, fmt.Sprintf(`"tid":"%s"`, res.Tid))
+}
+
+// consume 使用 mock 的消息队列，消费一条异步测试的消息
+func (s *ExamineHandlerTest) consume(t *testing.T, tid string) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	val, err := json.Marshal(event.ExamineEvent{Uid: uid, Tid: tid})
+	require.NoError(t, err)
+	mockConsumer := mocks.NewMockConsumer(ctrl)
+	mockConsumer.EXPECT().Consume(gomock.Any()).Return(&mq.Message{Value: val}, nil)
+	mockMQ := mocks.NewMockMQ(ctrl)
+	mockMQ.EXPECT().Consumer(event.ExamineTopic, gomock.Any()).Return(mockConsumer, nil)
+	c, err := consumer.NewExamineConsumer(s.svc, mockMQ)
+	require.NoError(t, err)
+	err = c.Consume(context.Background())
+	require.NoError(t, err)
+}
+
+func (s *ExamineHandlerTest) postTid(t *testing.T, path string, body any) web.ExamineResult {
+	req, err := http.NewRequest(http.MethodPost, path, iox.NewJSONReader(body))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.ExamineResult]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	return recorder.MustScan().Data
+}
+
+func (s *ExamineHandlerTest) TestHistory() {
+	t := s.T()
+	records := []dao.ExamineRecord{
+		{Id: 1, Uid: uid, Qid: 1, Tid: "tid1", Result: domain.ResultBasic.ToUint8(), RawResult: "评分：15K",
+			Input: "第一次", Status: domain.ExamineStatusSucceeded.ToUint8(), Ctime: 1, Utime: 1},
+		{Id: 2, Uid: uid, Qid: 1, Tid: "tid2", Input: "第二次",
+			Status: domain.ExamineStatusFailed.ToUint8(), FailReason: "积分不足", Ctime: 2, Utime: 2},
+		{Id: 3, Uid: uid, Qid: 1, Tid: "tid3", Result: domain.ResultAdvanced.ToUint8(), RawResult: examineJSON,
+			Tokens: 10, Amount: 10, CorrectnessScore: 90,
+			Input: "第三次", Status: domain.ExamineStatusSucceeded.ToUint8(), Ctime: 3, Utime: 3},
+		// 其它题目和其它用户的
+		{Id: 4, Uid: uid, Qid: 2, Tid: "tid4", Status: domain.ExamineStatusSucceeded.ToUint8(), Ctime: 4, Utime: 4},
+		{Id: 5, Uid: uid + 1, Qid: 1, Tid: "tid5", Status: domain.ExamineStatusSucceeded.ToUint8(), Ctime: 5, Utime: 5},
+	}
+	err := s.db.Create(&records).Error
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost,
+		"/question/examine/history", iox.NewJSONReader(web.ExamineHistoryReq{Qid: 1, Offset: 0, Limit: 2}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.ExamineHistory]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	assert.Equal(t, web.ExamineHistory{
+		Total: 3,
+		Records: []web.ExamineResult{
+			{
+				Qid:       1,
+				Tid:       "tid3",
+				Result:    domain.ResultAdvanced.ToUint8(),
+				RawResult: examineJSON,
+				Tokens:    10,
+				Amount:    10,
+				Scores:    web.ExamineScores{Correctness: 90},
+				Status:    domain.ExamineStatusSucceeded.ToUint8(),
+				Input:     "第三次",
+				Ctime:     3,
+			},
+			{
+				Qid:        1,
+				Tid:        "tid2",
+				Status:     domain.ExamineStatusFailed.ToUint8(),
+				FailReason: "积分不足",
+				Input:      "第二次",
+				Ctime:      2,
+			},
+		},
+	}, recorder.MustScan().Data)
+
+	// 没有传 limit 的时候使用默认值
+	req, err = http.NewRequest(http.MethodPost,
+		"/question/examine/history", iox.NewJSONReader(web.ExamineHistoryReq{Qid: 1}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder = test.NewJSONResponseRecorder[web.ExamineHistory]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	res := recorder.MustScan().Data
+	assert.Equal(t, int64(3), res.Total)
+	assert.Len(t, res.Records, 3)
+}
+
+func (s *ExamineHandlerTest) TestProgress() {
+	t := s.T()
+	// 按天统计用的是固定的时区
+	now := time.Now().In(domain.ExamineLocation)
+	twoDaysAgo := now.AddDate(0, 0, -2)
+	longAgo := now.AddDate(0, 0, -40)
+	records := []dao.ExamineRecord{
+		{Uid: uid, Qid: 1, Tid: "tid1", Result: domain.ResultBasic.ToUint8(),
+			Status: domain.ExamineStatusSucceeded.ToUint8(), Ctime: now.UnixMilli() - 1000},
+		{Uid: uid, Qid: 1, Tid: "tid2", Result: domain.ResultAdvanced.ToUint8(),
+			Status: domain.ExamineStatusSucceeded.ToUint8(), Ctime: now.UnixMilli()},
+		// 等待中和失败的不统计
+		{Uid: uid, Qid: 1, Tid: "tid3", Status: domain.ExamineStatusPending.ToUint8(), Ctime: now.UnixMilli()},
+		{Uid: uid, Qid: 2, Tid: "tid4", Status: domain.ExamineStatusFailed.ToUint8(), Ctime: now.UnixMilli()},
+		// 早期的同步测试没有状态
+		{Uid: uid, Qid: 2, Tid: "tid5", Result: domain.ResultIntermediate.ToUint8(), Ctime: twoDaysAgo.UnixMilli()},
+		// 超过了统计每天测试次数的范围
+		{Uid: uid, Qid: 3, Tid: "tid6", Result: domain.ResultBasic.ToUint8(),
+			Status: domain.ExamineStatusSucceeded.ToUint8(), Ctime: longAgo.UnixMilli()},
+		// 其它用户的
+		{Uid: uid + 1, Qid: 1, Tid: "tid7", Result: domain.ResultAdvanced.ToUint8(),
+			Status: domain.ExamineStatusSucceeded.ToUint8(), Ctime: now.UnixMilli()},
+	}
+	err := s.db.Create(&records).Error
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost,
+		"/question/examine/progress", iox.NewJSONReader(web.ExamineProgressReq{}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.ExamineProgress]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	assert.Equal(t, web.ExamineProgress{
+		Days: []web.ExamineDailyAttempts{
+			{Date: twoDaysAgo.Format(time.DateOnly), Attempts: 1},
+			{Date: now.Format(time.DateOnly), Attempts: 2},
+		},
+		Distribution: []web.ExamineResultCount{
+			{Result: domain.ResultBasic.ToUint8(), Count: 2},
+			{Result: domain.ResultIntermediate.ToUint8(), Count: 1},
+			{Result: domain.ResultAdvanced.ToUint8(), Count: 1},
+		},
+		Best: []web.ExamineBestResult{
+			{Qid: 1, Result: domain.ResultAdvanced.ToUint8(), Attempts: 2, LastTime: now.UnixMilli()},
+			{Qid: 2, Result: domain.ResultIntermediate.ToUint8(), Attempts: 1, LastTime: twoDaysAgo.UnixMilli()},
+			{Qid: 3, Result: domain.ResultBasic.ToUint8(), Attempts: 1, LastTime: longAgo.UnixMilli()},
+		},
+	}, recorder.MustScan().Data)
+}
+
+func (s *ExamineHandlerTest) TestQuota() {
+	t := s.T()
+	req, err := http.NewRequest(http.MethodPost,
+		"/question/examine/quota", iox.NewJSONReader(nil))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.ExamineQuota]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	assert.Equal(t, web.ExamineQuota{
+		CallsPerDay:  10,
+		CallsToday:   3,
+		CallsLeft:    7,
+		TokensPerDay: 1000,
+		TokensToday:  100,
+	}, recorder.MustScan().Data)
+}
+
 func TestExamineHandler(t *testing.T) {
 	suite.Run(t, new(ExamineHandlerTest))
 }
@@ -0,0 +1,131 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/credit"
+	creditmocks "github.com/ecodeclub/webook/internal/credit/mocks"
+	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/permission"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/integration/startup"
+	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/question/internal/web"
+	"github.com/ecodeclub/webook/internal/test"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/ego-component/egorm"
+	"github.com/gin-gonic/gin"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/server/egin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+// ExamineReplayTestSuite 使用真实的 AI 模块，响应来自 testdata/llm 下录制好的文件
+// 提示词模板是 AI 模块默认的 question_examine 配置，修改了模板就需要重新录制
+type ExamineReplayTestSuite struct {
+	suite.Suite
+	server *egin.Component
+	db     *egorm.Component
+}
+
+func (s *ExamineReplayTestSuite) SetupSuite() {
+	ctrl := gomock.NewController(s.T())
+	creditSvc := creditmocks.NewMockService(ctrl)
+	creditSvc.EXPECT().GetCreditsByUID(gomock.Any(), gomock.Any()).Return(credit.Credit{
+		TotalAmount: 1000,
+	}, nil).AnyTimes()
+	creditSvc.EXPECT().TryDeductCredits(gomock.Any(), gomock.Any()).Return(int64(1), nil).AnyTimes()
+	creditSvc.EXPECT().ConfirmDeductCredits(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	aiModule, err := startup.InitReplayAIModule("testdata/llm", &credit.Module{Svc: creditSvc})
+	require.NoError(s.T(), err)
+	module, err := startup.InitModule(nil, &interactive.Module{}, &permission.Module{}, aiModule)
+	require.NoError(s.T(), err)
+	s.db = testioc.InitDB()
+	econf.Set("server", map[string]any{"contextTimeout": "3s"})
+	server := egin.Load("server").Build()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set(session.CtxSessionKey,
+			session.NewMemorySession(session.Claims{
+				Uid: uid,
+			}))
+	})
+	module.ExamineHdl.MemberRoutes(server.Engine)
+	s.server = server
+
+	err = s.db.Create(&dao.PublishQuestion{
+		Id:    1,
+		Title: "回放测试题目",
+	}).Error
+	require.NoError(s.T(), err)
+}
+
+func (s *ExamineReplayTestSuite) TearDownSuite() {
+	for _, table := range []string{"publish_questions", "question_results",
+		"examine_records", "question_reviews", "llm_records", "llm_credits"} {
+		err := s.db.Exec("TRUNCATE TABLE `" + table + "`").Error
+		require.NoError(s.T(), err)
+	}
+	clearQuestionCache(s.T())
+}
+
+func (s *ExamineReplayTestSuite) TestExamine() {
+	t := s.T()
+	req, err := http.NewRequest(http.MethodPost,
+		"/question/examine", iox.NewJSONReader(web.ExamineReq{
+			Qid:   1,
+			Input: "Redis 是单线程的，用了 IO 多路复用",
+		}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.ExamineResult]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	res := recorder.MustScan()
+	assert.True(t, len(res.Data.Tid) > 0)
+	res.Data.Tid = ""
+	assert.Equal(t, web.ExamineResult{
+		Qid:    1,
+		Result: domain.ResultIntermediate.ToUint8(),
+		RawResult: `{"level":"25K","scores":{"correctness":80,"depth":60,"highlights":40,"keywords":50},` +
+			`"suggestions":[{"keyword":"IO 多路复用","content":"补充 epoll 的原理"}]}`,
+		Tokens: 200,
+		Amount: 20,
+		Scores: web.ExamineScores{
+			Correctness: 80,
+			Depth:       60,
+			Highlights:  40,
+			Keywords:    50,
+		},
+		Suggestions: []web.ExamineSuggestion{
+			{Keyword: "IO 多路复用", Content: "补充 epoll 的原理"},
+		},
+		Status: domain.ExamineStatusSucceeded.ToUint8(),
+		Input:  "Redis 是单线程的，用了 IO 多路复用",
+	}, res.Data)
+}
+
+func TestExamineReplay(t *testing.T) {
+	suite.Run(t, new(ExamineReplayTestSuite))
+}
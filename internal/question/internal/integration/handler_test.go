@@ -26,8 +26,10 @@ import (
 
 	"github.com/ecodeclub/webook/internal/ai"
 
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/permission"
 	permissionmocks "github.com/ecodeclub/webook/internal/permission/mocks"
+	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/question/internal/errs"
 
 	"github.com/ecodeclub/webook/internal/interactive"
@@ -42,6 +44,8 @@ import (
 
 	"github.com/ecodeclub/ecache"
 	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ecodeclub/ginx/session"
 	"github.com/ecodeclub/webook/internal/question/internal/integration/startup"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
@@ -51,6 +55,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gotomicro/ego/core/econf"
 	"github.com/gotomicro/ego/server/egin"
+	"github.com/gotomicro/ego/task/ejob"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -60,13 +65,17 @@ const uid = 123
 
 type HandlerTestSuite struct {
 	BaseTestSuite
-	server *egin.Component
-	rdb    ecache.Cache
+	server    *egin.Component
+	rdb       ecache.Cache
+	svc       baguwen.Service
+	labelsJob *baguwen.SyncLabelsJob
 }
 
 func (s *HandlerTestSuite) SetupSuite() {
 	ctrl := gomock.NewController(s.T())
 	producer := eveMocks.NewMockSyncEventProducer(ctrl)
+	// 只有 TestPubDetailCache 会发布题目
+	producer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	intrSvc := intrmocks.NewMockService(ctrl)
 	intrModule := &interactive.Module{
@@ -125,6 +134,8 @@ func (s *HandlerTestSuite) SetupSuite() {
 	module.Hdl.MemberRoutes(server.Engine)
 
 	s.server = server
+	s.svc = module.Svc
+	s.labelsJob = module.SyncLabelsJob
 	s.db = testioc.InitDB()
 	err = dao.InitTables(s.db)
 	require.NoError(s.T(), err)
@@ -262,6 +273,159 @@ func (s *HandlerTestSuite) TestPubList() {
 	require.NoError(s.T(), err)
 }
 
+func (s *HandlerTestSuite) TestPubListByLabels() {
+	data := make([]dao.PublishQuestion, 0, 5)
+	for idx := 0; idx < 4; idx++ {
+		id := int64(idx + 1)
+		data = append(data, dao.PublishQuestion{
+			Id:      id,
+			Uid:     uid,
+			Biz:     domain.DefaultBiz,
+			BizId:   id,
+			Status:  domain.PublishedStatus.ToUint8(),
+			Title:   fmt.Sprintf("这是标题 %d", idx),
+			Content: fmt.Sprintf("这是解析 %d", idx),
+			Utime:   123,
+		})
+	}
+	// project 的不会被筛选到，也不会被统计
+	data = append(data, dao.PublishQuestion{
+		Id:    5,
+		Uid:   uid,
+		Biz:   "project",
+		BizId: 5,
+		Title: "这是标题 5",
+		Utime: 123,
+	})
+	err := s.db.Create(&data).Error
+	require.NoError(s.T(), err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	labels := map[int64][]string{
+		1: {"Redis"},
+		2: {"Redis", "MySQL"},
+		3: {"MySQL", "Kafka"},
+		4: {},
+		5: {"Redis"},
+	}
+	for qid := int64(1); qid <= 5; qid++ {
+		err = s.svc.SyncLabels(ctx, qid, labels[qid])
+		require.NoError(s.T(), err)
+	}
+	// 按照创建顺序，Redis 是 1，MySQL 是 2，Kafka 是 3
+	facets := []web.LabelFacet{
+		{LabelId: 1, Name: "Redis", Cnt: 2},
+		{LabelId: 2, Name: "MySQL", Cnt: 2},
+		{LabelId: 3, Name: "Kafka", Cnt: 1},
+	}
+
+	testCases := []struct {
+		name string
+		req  web.PubListReq
+
+		wantIds []int64
+	}{
+		{
+			name: "不筛选",
+			req: web.PubListReq{
+				Limit: 10,
+			},
+			wantIds: []int64{4, 3, 2, 1},
+		},
+		{
+			name: "命中任意一个",
+			req: web.PubListReq{
+				Limit:    10,
+				LabelIds: []int64{1, 3},
+			},
+			wantIds: []int64{3, 2, 1},
+		},
+		{
+			name: "命中全部",
+			req: web.PubListReq{
+				Limit:     10,
+				LabelIds:  []int64{1, 2, 2},
+				LabelMode: label.MatchAll.ToUint8(),
+			},
+			wantIds: []int64{2},
+		},
+		{
+			name: "翻页",
+			req: web.PubListReq{
+				Offset:   1,
+				Limit:    10,
+				LabelIds: []int64{2},
+			},
+			wantIds: []int64{2},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				"/question/pub/list", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[[]web.Question]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			assert.Equal(t, tc.wantIds, slice.Map(recorder.MustScan().Data, func(idx int, src web.Question) int64 {
+				return src.Id
+			}))
+		})
+	}
+
+	// 标签统计和筛选条件无关
+	req, err := http.NewRequest(http.MethodPost, "/question/facets", nil)
+	require.NoError(s.T(), err)
+	recorder := test.NewJSONResponseRecorder[[]web.LabelFacet]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(s.T(), 200, recorder.Code)
+	assert.Equal(s.T(), facets, recorder.MustScan().Data)
+}
+
+func (s *HandlerTestSuite) TestSyncLabelsJob() {
+	data := []dao.PublishQuestion{
+		{
+			Id:     1,
+			Biz:    domain.DefaultBiz,
+			Labels: sqlx.JsonColumn[[]string]{Val: []string{"Redis", " MySQL ", "redis"}, Valid: true},
+			Utime:  123,
+		},
+		{
+			Id:     2,
+			Biz:    domain.DefaultBiz,
+			Labels: sqlx.JsonColumn[[]string]{Val: []string{"MySQL"}, Valid: true},
+			Utime:  124,
+		},
+		{
+			Id:    3,
+			Biz:   domain.DefaultBiz,
+			Utime: 125,
+		},
+	}
+	err := s.db.Create(&data).Error
+	require.NoError(s.T(), err)
+	// 已经不存在的标签会被删掉
+	err = s.db.Create(&dao.PublishQuestionLabel{Qid: 3, LabelId: 100}).Error
+	require.NoError(s.T(), err)
+
+	err = s.labelsJob.Run(ejob.Context{Ctx: context.Background()})
+	require.NoError(s.T(), err)
+	// 重复执行也没有问题
+	err = s.labelsJob.Run(ejob.Context{Ctx: context.Background()})
+	require.NoError(s.T(), err)
+
+	var rels []dao.PublishQuestionLabel
+	err = s.db.Order("qid ASC, label_id ASC").Find(&rels).Error
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), [][2]int64{{1, 1}, {1, 2}, {2, 2}},
+		slice.Map(rels, func(idx int, src dao.PublishQuestionLabel) [2]int64 {
+			return [2]int64{src.Qid, src.LabelId}
+		}))
+}
+
 func (s *HandlerTestSuite) TestPubDetail() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
 	defer cancel()
@@ -349,6 +513,66 @@ func (s *HandlerTestSuite) TestPubDetail() {
 	}
 }
 
+func (s *HandlerTestSuite) TestPubDetailCache() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	que := &domain.Question{
+		Uid:     uid,
+		Title:   "发布的标题",
+		Content: "发布的内容",
+		Biz:     "project",
+		BizId:   2,
+		Answer: domain.Answer{
+			Basic: domain.AnswerElement{Content: "基本回答"},
+		},
+	}
+	qid, err := s.svc.Publish(ctx, que)
+	require.NoError(s.T(), err)
+
+	pubDetail := func(t *testing.T) web.Question {
+		req, err := http.NewRequest(http.MethodPost,
+			"/question/detail", iox.NewJSONReader(web.Qid{Qid: qid}))
+		req.Header.Set("content-type", "application/json")
+		require.NoError(t, err)
+		recorder := test.NewJSONResponseRecorder[web.Question]()
+		s.server.ServeHTTP(recorder, req)
+		require.Equal(t, 200, recorder.Code)
+		return recorder.MustScan().Data
+	}
+
+	assert.Equal(s.T(), "发布的标题", pubDetail(s.T()).Title)
+	assert.False(s.T(), s.rdb.Get(ctx, fmt.Sprintf("question:pub:detail:%d", qid)).KeyNotFound())
+
+	// 绕过 repository 修改线上库，读到的还是缓存
+	err = s.db.WithContext(ctx).Model(&dao.PublishQuestion{}).
+		Where("id = ?", qid).Update("title", "数据库里的标题").Error
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "发布的标题", pubDetail(s.T()).Title)
+
+	// 重新发布会删除缓存
+	que.Id = qid
+	que.Title = "重新发布的标题"
+	_, err = s.svc.Publish(ctx, que)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "重新发布的标题", pubDetail(s.T()).Title)
+
+	// 批量查询，重复的和不存在的 id 都会被跳过，也不返回答案
+	qs, err := s.svc.GetPubByIDs(ctx, []int64{qid, 10000, qid})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), qs, 1)
+	assert.Equal(s.T(), "重新发布的标题", qs[0].Title)
+	assert.Equal(s.T(), domain.Answer{}, qs[0].Answer)
+	qs, err = s.svc.PubDetailByIDs(ctx, []int64{qid})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), qs, 1)
+	assert.Equal(s.T(), "基本回答", qs[0].Answer.Basic.Content)
+
+	// 删除之后缓存也没了
+	err = s.svc.Delete(ctx, qid)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), s.rdb.Get(ctx, fmt.Sprintf("question:pub:detail:%d", qid)).KeyNotFound())
+}
+
 func TestHandler(t *testing.T) {
 	suite.Run(t, new(HandlerTestSuite))
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"testing"
 	"time"
 
 	"github.com/ecodeclub/webook/internal/ai"
@@ -63,7 +64,8 @@ func (s *KnowledgeJobStarterTestSuite) TestExport() {
 		file.Close()
 	}()
 
-	err = s.starter.Export(ejob.Context{Ctx: context.Background()}, file)
+	_, _, err = s.starter.Export(ejob.Context{Ctx: context.Background()},
+		job.KnowledgeFormatCSV, time.Time{}, time.Now(), file)
 	assert.NoError(s.T(), err)
 	// 重置到文件开始位置
 	_, err = file.Seek(0, 0)
@@ -73,6 +75,49 @@ func (s *KnowledgeJobStarterTestSuite) TestExport() {
 	assert.Equal(s.T(), ``, string(all))
 }
 
+func (s *KnowledgeJobStarterTestSuite) TestStartIncremental() {
+	t := s.T()
+	ctx := ejob.Context{Ctx: context.Background()}
+	err := s.db.Exec("TRUNCATE TABLE `knowledge_export_runs`").Error
+	require.NoError(t, err)
+	_, err = s.dao.Sync(context.Background(), dao.Question{Title: "标题1", Biz: domain.DefaultBiz}, nil)
+	require.NoError(t, err)
+
+	// 第一次增量导出等同于全量导出
+	err = s.starter.StartIncremental(ctx)
+	require.NoError(t, err)
+	first := s.lastRun(t)
+	assert.Equal(t, int64(1), first.Cnt)
+	assert.Equal(t, int64(0), first.Since)
+	// 水位是导出的题目的更新时间，而不是开始导出的时间
+	var que1 dao.PublishQuestion
+	err = s.db.Where("title = ?", "标题1").First(&que1).Error
+	require.NoError(t, err)
+	assert.Equal(t, que1.Utime, first.Watermark)
+
+	time.Sleep(time.Millisecond * 10)
+	_, err = s.dao.Sync(context.Background(), dao.Question{Title: "标题2", Biz: domain.DefaultBiz}, nil)
+	require.NoError(t, err)
+	err = s.starter.StartIncremental(ctx)
+	require.NoError(t, err)
+	second := s.lastRun(t)
+	// 往前重叠了一段时间，标题1 也会再导出一次
+	assert.Equal(t, int64(2), second.Cnt)
+	assert.True(t, second.Since < first.Watermark)
+	assert.True(t, second.Watermark > first.Watermark)
+	assert.Equal(t, domain.KnowledgeExportStatusSucceeded.ToUint8(), second.Status)
+	assert.True(t, second.Incremental)
+	_, err = os.Stat(second.File)
+	assert.NoError(t, err)
+}
+
+func (s *KnowledgeJobStarterTestSuite) lastRun(t *testing.T) dao.KnowledgeExportRun {
+	var run dao.KnowledgeExportRun
+	err := s.db.Order("id DESC").First(&run).Error
+	require.NoError(t, err)
+	return run
+}
+
 func (s *KnowledgeJobStarterTestSuite) initWholeQuestion(id int64) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
 	defer cancel()
@@ -0,0 +1,164 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/ai"
+	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/permission"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/integration/startup"
+	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/question/internal/web"
+	"github.com/ecodeclub/webook/internal/test"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/ego-component/egorm"
+	"github.com/gin-gonic/gin"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/server/egin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReviewHandlerTestSuite struct {
+	suite.Suite
+	server *egin.Component
+	db     *egorm.Component
+}
+
+func (s *ReviewHandlerTestSuite) SetupSuite() {
+	econf.Set("question.review.dailyLimit", 3)
+	module, err := startup.InitModule(nil, &interactive.Module{}, &permission.Module{}, &ai.Module{})
+	require.NoError(s.T(), err)
+	s.db = testioc.InitDB()
+	econf.Set("server", map[string]any{"contextTimeout": "1s"})
+	server := egin.Load("server").Build()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set(session.CtxSessionKey,
+			session.NewMemorySession(session.Claims{
+				Uid: uid,
+			}))
+	})
+	module.ReviewHdl.PrivateRoutes(server.Engine)
+	s.server = server
+
+	for i := int64(1); i <= 4; i++ {
+		title := fmt.Sprintf("测试题目%d", i)
+		err = s.db.Create(&dao.Question{Id: i, Title: title}).Error
+		require.NoError(s.T(), err)
+		err = s.db.Create(&dao.PublishQuestion{Id: i, Title: title}).Error
+		require.NoError(s.T(), err)
+	}
+	err = s.db.Create(&dao.QuestionSet{Id: 1, Title: "题集"}).Error
+	require.NoError(s.T(), err)
+	err = s.db.Create(&[]dao.QuestionSetQuestion{
+		{QSID: 1, QID: 1},
+		{QSID: 1, QID: 2},
+	}).Error
+	require.NoError(s.T(), err)
+}
+
+func (s *ReviewHandlerTestSuite) TearDownSuite() {
+	for _, table := range []string{"questions", "publish_questions", "question_sets",
+		"question_set_questions", "question_results", "question_reviews"} {
+		err := s.db.Exec("TRUNCATE TABLE `" + table + "`").Error
+		require.NoError(s.T(), err)
+	}
+}
+
+func (s *ReviewHandlerTestSuite) TestToday() {
+	t := s.T()
+	now := time.Now()
+	twoDaysAgo := now.AddDate(0, 0, -2).UnixMilli()
+	reviews := []dao.QuestionReview{
+		// 早就到期了
+		{Uid: uid, Qid: 1, Repetitions: 1, Interval: 1, EaseFactor: 2500,
+			LastResult: domain.ResultBasic.ToUint8(), LastReview: twoDaysAgo - 1000, NextReview: twoDaysAgo},
+		// 今天到期
+		{Uid: uid, Qid: 2, Repetitions: 2, Interval: 6, EaseFactor: 2500,
+			LastResult: domain.ResultAdvanced.ToUint8(), NextReview: domain.EndOfDay(now).UnixMilli() - 1},
+		// 明天才到期，今天已经复习过了
+		{Uid: uid, Qid: 3, Repetitions: 1, Interval: 1, EaseFactor: 2500,
+			LastResult: domain.ResultAdvanced.ToUint8(), ReviewedAt: now.UnixMilli(),
+			NextReview: domain.EndOfDay(now).UnixMilli() + 1},
+		// 其它用户的
+		{Uid: uid + 1, Qid: 1, NextReview: twoDaysAgo},
+	}
+	err := s.db.Create(&reviews).Error
+	require.NoError(t, err)
+	// 还没有复习计划的测试结果
+	err = s.db.Create(&dao.QuestionResult{Uid: uid, Qid: 4,
+		Result: domain.ResultBasic.ToUint8(), Ctime: twoDaysAgo, Utime: twoDaysAgo}).Error
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		req      web.ReviewTodayReq
+		wantResp web.ReviewList
+	}{
+		{
+			// 每天最多 3 道，已经复习了 1 道
+			name: "所有题目",
+			req:  web.ReviewTodayReq{},
+			wantResp: web.ReviewList{
+				Reviews: []web.Review{
+					{Qid: 1, Title: "测试题目1", LastResult: domain.ResultBasic.ToUint8(),
+						Repetitions: 1, Interval: 1, LastReview: twoDaysAgo - 1000, NextReview: twoDaysAgo},
+					{Qid: 4, Title: "测试题目4", LastResult: domain.ResultBasic.ToUint8(),
+						Repetitions: 1, Interval: 1, LastReview: twoDaysAgo,
+						NextReview: time.UnixMilli(twoDaysAgo).AddDate(0, 0, 1).UnixMilli()},
+				},
+			},
+		},
+		{
+			name: "按照题集过滤",
+			req:  web.ReviewTodayReq{Qsid: 1},
+			wantResp: web.ReviewList{
+				Reviews: []web.Review{
+					{Qid: 1, Title: "测试题目1", LastResult: domain.ResultBasic.ToUint8(),
+						Repetitions: 1, Interval: 1, LastReview: twoDaysAgo - 1000, NextReview: twoDaysAgo},
+					{Qid: 2, Title: "测试题目2", LastResult: domain.ResultAdvanced.ToUint8(),
+						Repetitions: 2, Interval: 6, NextReview: domain.EndOfDay(now).UnixMilli() - 1},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				"/question/review/today", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.ReviewList]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan().Data)
+		})
+	}
+}
+
+func TestReviewHandler(t *testing.T) {
+	suite.Run(t, new(ReviewHandlerTestSuite))
+}
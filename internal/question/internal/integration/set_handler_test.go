@@ -26,15 +26,18 @@ import (
 
 	"github.com/ecodeclub/webook/internal/ai"
 
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/permission"
 
 	"github.com/ecodeclub/ecache"
 	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/ginx/session"
 	"github.com/ecodeclub/webook/internal/interactive"
 	intrmocks "github.com/ecodeclub/webook/internal/interactive/mocks"
 	"github.com/ecodeclub/webook/internal/pkg/middleware"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/errs"
 	eveMocks "github.com/ecodeclub/webook/internal/question/internal/event/mocks"
 	"github.com/ecodeclub/webook/internal/question/internal/integration/startup"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
@@ -542,6 +545,33 @@ func (s *SetHandlerTestSuite) TestQuestionSet_RetrieveQuestionSetDetail_Failed()
 			wantCode: 500,
 			wantResp: test.Result[int64]{Code: 502001, Msg: "系统错误"},
 		},
+		{
+			name: "题集已下线",
+			before: func(t *testing.T) {
+				t.Helper()
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				_, err := s.questionSetDAO.Create(ctx, dao.QuestionSet{
+					Id:     10001,
+					Uid:    uid,
+					Title:  "已下线的题集",
+					Status: domain.QuestionSetStatusUnPublished.ToUint8(),
+					Utime:  123,
+				})
+				require.NoError(t, err)
+			},
+			after: func(t *testing.T) {
+				t.Helper()
+			},
+			req: web.QuestionSetID{
+				QSID: 10001,
+			},
+			wantCode: 200,
+			wantResp: test.Result[int64]{
+				Code: errs.QuestionSetNotFound.Code,
+				Msg:  errs.QuestionSetNotFound.Msg,
+			},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -680,6 +710,95 @@ func (s *SetHandlerTestSuite) TestQuestionSet_ListAllQuestionSets() {
 	}
 }
 
+func (s *SetHandlerTestSuite) TestQuestionSet_ListByLabels() {
+	sets := []dao.QuestionSet{
+		{Id: 1, Uid: uid, Title: "题集标题 1", Biz: domain.DefaultBiz, Utime: 123},
+		{Id: 2, Uid: uid, Title: "题集标题 2", Biz: domain.DefaultBiz, Utime: 123},
+		// project 的不会被筛选到，也不会被统计
+		{Id: 3, Uid: uid, Title: "题集标题 3", Biz: "project", Utime: 123},
+	}
+	err := s.db.Create(&sets).Error
+	require.NoError(s.T(), err)
+	err = s.db.Create(&[]dao.QuestionSetQuestion{
+		{QSID: 1, QID: 1}, {QSID: 1, QID: 2},
+		{QSID: 2, QID: 2}, {QSID: 2, QID: 3},
+		{QSID: 3, QID: 1},
+	}).Error
+	require.NoError(s.T(), err)
+	err = s.db.Create(&[]dao.PublishQuestionLabel{
+		{Qid: 1, LabelId: 1},
+		{Qid: 2, LabelId: 2},
+		{Qid: 3, LabelId: 1}, {Qid: 3, LabelId: 3},
+	}).Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("INSERT INTO `labels`(`id`, `name`, `uid`, `ctime`, `utime`) VALUES " +
+		"(1, 'Redis', -1, 0, 0), (2, 'MySQL', -1, 0, 0), (3, 'Kafka', -1, 0, 0)").Error
+	require.NoError(s.T(), err)
+
+	facets := []web.LabelFacet{
+		{LabelId: 1, Name: "Redis", Cnt: 2},
+		{LabelId: 2, Name: "MySQL", Cnt: 2},
+		{LabelId: 3, Name: "Kafka", Cnt: 1},
+	}
+	testCases := []struct {
+		name string
+		req  web.PubListReq
+
+		wantIds []int64
+	}{
+		{
+			name: "命中任意一个",
+			req: web.PubListReq{
+				Limit:    10,
+				LabelIds: []int64{3},
+			},
+			wantIds: []int64{2},
+		},
+		{
+			name: "题集内的题目合起来命中全部",
+			req: web.PubListReq{
+				Limit:     10,
+				LabelIds:  []int64{1, 2},
+				LabelMode: label.MatchAll.ToUint8(),
+			},
+			wantIds: []int64{2, 1},
+		},
+		{
+			name: "没有命中全部",
+			req: web.PubListReq{
+				Limit:     10,
+				LabelIds:  []int64{2, 3},
+				LabelMode: label.MatchAll.ToUint8(),
+			},
+			wantIds: []int64{2},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				"/question-sets/list", iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.QuestionSetList]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			res := recorder.MustScan().Data
+			assert.Equal(t, tc.wantIds, slice.Map(res.QuestionSets, func(idx int, src web.QuestionSet) int64 {
+				return src.Id
+			}))
+		})
+	}
+
+	// 标签统计和筛选条件无关
+	req, err := http.NewRequest(http.MethodPost, "/question-sets/facets", nil)
+	require.NoError(s.T(), err)
+	recorder := test.NewJSONResponseRecorder[[]web.LabelFacet]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(s.T(), 200, recorder.Code)
+	assert.Equal(s.T(), facets, recorder.MustScan().Data)
+}
+
 func TestSetHandler(t *testing.T) {
 	suite.Run(t, new(SetHandlerTestSuite))
 }
@@ -0,0 +1,33 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package startup
+
+import (
+	"github.com/ecodeclub/webook/internal/ai"
+	"github.com/ecodeclub/webook/internal/credit"
+	"github.com/ecodeclub/webook/internal/member"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/gotomicro/ego/core/econf"
+)
+
+// InitReplayAIModule 真实的 AI 模块，只是出口换成了回放 dir 下录制好的响应，不会访问网络
+func InitReplayAIModule(dir string, creditModule *credit.Module) (*ai.Module, error) {
+	econf.Set("ai.replay", map[string]any{
+		"mode": "replay",
+		"dir":  dir,
+	})
+	return ai.InitModule(testioc.InitDB(), testioc.InitRedis(), testioc.InitCache(),
+		creditModule, &member.Module{})
+}
@@ -19,12 +19,15 @@ package startup
 import (
 	"os"
 
+	"github.com/ecodeclub/mq-api"
 	"github.com/ecodeclub/webook/internal/ai"
 
 	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/permission"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/question/internal/event"
+	"github.com/ecodeclub/webook/internal/question/internal/event/consumer"
 	"github.com/ecodeclub/webook/internal/question/internal/job"
 	"github.com/ecodeclub/webook/internal/question/internal/repository"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/cache"
@@ -41,11 +44,14 @@ func InitModule(p event.SyncDataToSearchEventProducer,
 ) (*baguwen.Module, error) {
 	wire.Build(
 		testioc.BaseSet,
+		testioc.InitRedis,
 		moduleSet,
 		event.NewInteractiveEventProducer,
+		label.InitModule,
+		wire.FieldsOf(new(*label.Module), "Svc"),
 		wire.FieldsOf(new(*interactive.Module), "Svc"),
 		wire.FieldsOf(new(*permission.Module), "Svc"),
-		wire.FieldsOf(new(*ai.Module), "Svc"),
+		wire.FieldsOf(new(*ai.Module), "Svc", "QuotaSvc"),
 	)
 	return new(baguwen.Module), nil
 }
@@ -56,9 +62,18 @@ var moduleSet = wire.NewSet(baguwen.InitQuestionDAO,
 	service.NewService,
 	web.NewHandler,
 	web.NewAdminHandler,
+	service.NewLLMAnswerDraftService,
+	service.NewImportService,
+	baguwen.RevisionServiceSet,
 	initKnowledgeJobStarter,
+	job.NewSyncLabelsJob,
+	baguwen.InitPurgeSetTrashJob,
+	initExamineConsumer,
 	web.NewAdminQuestionSetHandler,
 	baguwen.ExamineHandlerSet,
+	baguwen.ReviewHandlerSet,
+	baguwen.ExamHandlerSet,
+	baguwen.KnowledgeExportSet,
 	baguwen.InitQuestionSetDAO,
 	repository.NewQuestionSetRepository,
 	service.NewQuestionSetService,
@@ -66,6 +81,11 @@ var moduleSet = wire.NewSet(baguwen.InitQuestionDAO,
 	wire.Struct(new(baguwen.Module), "*"),
 )
 
-func initKnowledgeJobStarter(svc service.Service) *job.KnowledgeJobStarter {
-	return job.NewKnowledgeJobStarter(svc, os.TempDir())
+func initKnowledgeJobStarter(svc service.Service, runSvc service.KnowledgeExportService) *job.KnowledgeJobStarter {
+	return job.NewKnowledgeJobStarter(svc, runSvc, os.TempDir(), job.KnowledgeFormatCSV)
+}
+
+// initExamineConsumer 测试里面不启动，需要的时候手动构造消费者调用 Consume
+func initExamineConsumer(svc service.ExamineService, q mq.MQ) (*consumer.ExamineConsumer, error) {
+	return consumer.NewExamineConsumer(svc, q)
 }
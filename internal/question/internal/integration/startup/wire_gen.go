@@ -9,11 +9,14 @@ package startup
 import (
 	"os"
 
+	"github.com/ecodeclub/mq-api"
 	"github.com/ecodeclub/webook/internal/ai"
 	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/permission"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/question/internal/event"
+	"github.com/ecodeclub/webook/internal/question/internal/event/consumer"
 	"github.com/ecodeclub/webook/internal/question/internal/job"
 	"github.com/ecodeclub/webook/internal/question/internal/repository"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/cache"
@@ -30,29 +33,63 @@ func InitModule(p event.SyncDataToSearchEventProducer, intrModule *interactive.M
 	db := testioc.InitDB()
 	questionDAO := baguwen.InitQuestionDAO(db)
 	ecacheCache := testioc.InitCache()
-	questionCache := cache.NewQuestionECache(ecacheCache)
+	cmdable := testioc.InitRedis()
+	questionCache := cache.NewQuestionECache(ecacheCache, cmdable)
 	repositoryRepository := repository.NewCacheRepository(questionDAO, questionCache)
 	mq := testioc.InitMQ()
 	interactiveEventProducer, err := event.NewInteractiveEventProducer(mq)
 	if err != nil {
 		return nil, err
 	}
-	serviceService := service.NewService(repositoryRepository, p, interactiveEventProducer)
+	labelModule := label.InitModule(db)
+	labelService := labelModule.Svc
+	serviceService := service.NewService(repositoryRepository, labelService, p, interactiveEventProducer)
 	questionSetDAO := baguwen.InitQuestionSetDAO(db)
-	questionSetRepository := repository.NewQuestionSetRepository(questionSetDAO)
-	questionSetService := service.NewQuestionSetService(questionSetRepository, interactiveEventProducer, p)
-	adminHandler := web.NewAdminHandler(serviceService)
+	questionSetRepository := repository.NewQuestionSetRepository(questionSetDAO, questionCache)
+	questionSetService := service.NewQuestionSetService(questionSetRepository, labelService, interactiveEventProducer, p)
+	gptService := aiModule.Svc
+	answerDraftService := service.NewLLMAnswerDraftService(gptService)
+	importService := service.NewImportService(serviceService, repositoryRepository, questionSetRepository, questionSetService)
+	revisionDAO := dao.NewGORMRevisionDAO(db)
+	revisionRepository := repository.NewRevisionRepository(revisionDAO)
+	revisionService := service.NewRevisionService(serviceService, revisionRepository)
+	adminHandler := web.NewAdminHandler(serviceService, answerDraftService, importService, revisionService)
 	adminQuestionSetHandler := web.NewAdminQuestionSetHandler(questionSetService)
 	service2 := intrModule.Svc
 	examineDAO := dao.NewGORMExamineDAO(db)
-	examineRepository := repository.NewCachedExamineRepository(examineDAO)
-	gptService := aiModule.Svc
-	examineService := service.NewLLMExamineService(repositoryRepository, examineRepository, gptService)
+	examineRepository := repository.NewExamineRepository(examineDAO)
+	quotaService := aiModule.QuotaSvc
+	examineEventProducer, err := event.NewExamineEventProducer(mq)
+	if err != nil {
+		return nil, err
+	}
+	examineResultEventProducer, err := event.NewExamineResultEventProducer(mq)
+	if err != nil {
+		return nil, err
+	}
+	reviewDAO := dao.NewGORMReviewDAO(db)
+	reviewRepository := repository.NewReviewRepository(reviewDAO)
+	reviewService := baguwen.InitReviewService(reviewRepository, questionSetRepository)
+	examineService := service.NewLLMExamineService(repositoryRepository, examineRepository, gptService, quotaService, examineEventProducer, examineResultEventProducer, reviewService)
 	service3 := permModule.Svc
 	handler := web.NewHandler(service2, examineService, service3, serviceService)
 	questionSetHandler := web.NewQuestionSetHandler(questionSetService, examineService, service2)
 	examineHandler := web.NewExamineHandler(examineService)
-	knowledgeJobStarter := initKnowledgeJobStarter(serviceService)
+	reviewHandler := web.NewReviewHandler(reviewService, serviceService)
+	examDAO := dao.NewGORMExamDAO(db)
+	examRepository := repository.NewExamRepository(examDAO)
+	examService := service.NewExamService(examRepository, questionSetRepository, repositoryRepository, examineService)
+	examHandler := web.NewExamHandler(examService)
+	knowledgeExportDAO := dao.NewGORMKnowledgeExportDAO(db)
+	knowledgeExportRepository := repository.NewKnowledgeExportRepository(knowledgeExportDAO)
+	knowledgeExportService := service.NewKnowledgeExportService(knowledgeExportRepository)
+	knowledgeJobStarter := initKnowledgeJobStarter(serviceService, knowledgeExportService)
+	syncLabelsJob := job.NewSyncLabelsJob(serviceService)
+	purgeSetTrashJob := baguwen.InitPurgeSetTrashJob(questionSetService)
+	examineConsumer, err := initExamineConsumer(examineService, mq)
+	if err != nil {
+		return nil, err
+	}
 	module := &baguwen.Module{
 		Svc:                 serviceService,
 		SetSvc:              questionSetService,
@@ -61,15 +98,26 @@ func InitModule(p event.SyncDataToSearchEventProducer, intrModule *interactive.M
 		Hdl:                 handler,
 		QsHdl:               questionSetHandler,
 		ExamineHdl:          examineHandler,
+		ExamineSvc:          examineService,
+		ReviewHdl:           reviewHandler,
+		ExamHdl:             examHandler,
 		KnowledgeJobStarter: knowledgeJobStarter,
+		SyncLabelsJob:       syncLabelsJob,
+		PurgeSetTrashJob:    purgeSetTrashJob,
+		ExamineConsumer:     examineConsumer,
 	}
 	return module, nil
 }
 
 // wire.go:
 
-var moduleSet = wire.NewSet(baguwen.InitQuestionDAO, cache.NewQuestionECache, repository.NewCacheRepository, service.NewService, web.NewHandler, web.NewAdminHandler, initKnowledgeJobStarter, web.NewAdminQuestionSetHandler, baguwen.ExamineHandlerSet, baguwen.InitQuestionSetDAO, repository.NewQuestionSetRepository, service.NewQuestionSetService, web.NewQuestionSetHandler, wire.Struct(new(baguwen.Module), "*"))
+var moduleSet = wire.NewSet(baguwen.InitQuestionDAO, cache.NewQuestionECache, repository.NewCacheRepository, service.NewService, web.NewHandler, web.NewAdminHandler, service.NewLLMAnswerDraftService, service.NewImportService, baguwen.RevisionServiceSet, initKnowledgeJobStarter, job.NewSyncLabelsJob, baguwen.InitPurgeSetTrashJob, initExamineConsumer, web.NewAdminQuestionSetHandler, baguwen.ExamineHandlerSet, baguwen.ReviewHandlerSet, baguwen.ExamHandlerSet, baguwen.KnowledgeExportSet, baguwen.InitQuestionSetDAO, repository.NewQuestionSetRepository, service.NewQuestionSetService, web.NewQuestionSetHandler, wire.Struct(new(baguwen.Module), "*"))
+
+func initKnowledgeJobStarter(svc service.Service, runSvc service.KnowledgeExportService) *job.KnowledgeJobStarter {
+	return job.NewKnowledgeJobStarter(svc, runSvc, os.TempDir(), job.KnowledgeFormatCSV)
+}
 
-func initKnowledgeJobStarter(svc service.Service) *job.KnowledgeJobStarter {
-	return job.NewKnowledgeJobStarter(svc, os.TempDir())
+// initExamineConsumer 测试里面不启动，需要的时候手动构造消费者调用 Consume
+func initExamineConsumer(svc service.ExamineService, q mq.MQ) (*consumer.ExamineConsumer, error) {
+	return consumer.NewExamineConsumer(svc, q)
 }
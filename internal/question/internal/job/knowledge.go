@@ -16,112 +16,145 @@ package job
 
 import (
 	"context"
-	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
-	"strings"
+	"path/filepath"
 	"time"
 
 	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
 	"github.com/ecodeclub/webook/internal/question/internal/service"
+	"github.com/gotomicro/ego/core/elog"
 	"github.com/gotomicro/ego/task/ejob"
 )
 
 // KnowledgeJobStarter 生成供 AI 平台使用的知识库数据
 type KnowledgeJobStarter struct {
-	batchSize int
-	svc       service.Service
-	baseDir   string
+	batchSize    int
+	batchTimeout time.Duration
+	svc          service.Service
+	runSvc       service.KnowledgeExportService
+	baseDir      string
+	format       string
+	logger       *elog.Component
 }
 
-func NewKnowledgeJobStarter(svc service.Service, baseDir string) *KnowledgeJobStarter {
-	// 默认十条一批
+func NewKnowledgeJobStarter(svc service.Service,
+	runSvc service.KnowledgeExportService,
+	baseDir string, format string) *KnowledgeJobStarter {
+	// 默认一百条一批，一批只需要查询两次
 	return &KnowledgeJobStarter{
-		svc:       svc,
-		batchSize: 10,
-		baseDir:   baseDir,
+		svc:          svc,
+		runSvc:       runSvc,
+		batchSize:    100,
+		batchTimeout: time.Second * 3,
+		baseDir:      baseDir,
+		format:       format,
+		logger:       elog.DefaultLogger,
 	}
 }
 
-// Start 不是好的写法，因为直接绕开了 service。
+// Start 全量导出
 func (s *KnowledgeJobStarter) Start(ctx ejob.Context) error {
-	// 准备一个文件
-	writer, err := os.Create(fmt.Sprintf("%s/genknow_%d.csv", s.baseDir, time.Now().UnixMilli()))
+	return s.run(ctx, false)
+}
+
+// StartIncremental 只导出上一次成功导出之后更新过的题目，删除了的题目不会体现在增量数据里面
+func (s *KnowledgeJobStarter) StartIncremental(ctx ejob.Context) error {
+	return s.run(ctx, true)
+}
+
+func (s *KnowledgeJobStarter) run(ctx ejob.Context, incremental bool) error {
+	run, err := s.runSvc.Start(ctx.Ctx, s.format, incremental)
 	if err != nil {
 		return err
 	}
-	defer writer.Close()
-	return s.Export(ctx, writer)
+	run.File = filepath.Join(s.baseDir, fmt.Sprintf("genknow_%d.%s", run.StartTime.UnixMilli(), s.format))
+	var watermark time.Time
+	run.Count, watermark, err = s.exportFile(ctx, run)
+	if watermark.After(run.Watermark) {
+		run.Watermark = watermark
+	}
+	// 导出本身成功了就不要因为记录失败而返回 error，只是下一次增量导出会多导出一些数据
+	_, err1 := s.runSvc.Finish(context.WithoutCancel(ctx.Ctx), run, err)
+	if err1 != nil {
+		s.logger.Error("记录导出知识库的结果失败", elog.FieldErr(err1), elog.Any("run", run))
+	}
+	return err
 }
 
-// Export 返回导出的文件名字
-func (s *KnowledgeJobStarter) Export(ctx ejob.Context, writer io.Writer) error {
-	offset := 0
-	limit := s.batchSize
+func (s *KnowledgeJobStarter) exportFile(ctx ejob.Context, run domain.KnowledgeExportRun) (int64, time.Time, error) {
+	writer, err := os.Create(run.File)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer writer.Close()
+	return s.Export(ctx, run.Format, run.Since, run.StartTime, writer)
+}
 
-	csvWriter := csv.NewWriter(writer)
-	defer csvWriter.Flush()
-	// 写入标题
-	_ = csvWriter.Write([]string{"问题", "标签", "问题描述", "问题分析",
-		"15K 答案", "25K 答案", "35K 答案"})
+// Export 导出更新时间在 (since, end] 之间的题目，返回导出的数量和导出的题目里面最大的更新时间
+func (s *KnowledgeJobStarter) Export(ctx ejob.Context, format string,
+	since, end time.Time, writer io.Writer) (int64, time.Time, error) {
+	w, err := newKnowledgeWriter(format, writer)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	var (
+		cnt   int64
+		utime = since
+		id    int64
+		// 按照更新时间升序翻页，最后一条就是最大的
+		maxUtime time.Time
+	)
 	for {
-		cnt, err := s.Batch(ctx, offset, limit, csvWriter)
+		ques, err := s.Batch(ctx, utime, id, end, w)
 		if err != nil {
-			return err
+			return cnt, maxUtime, err
+		}
+		cnt += int64(len(ques))
+		if len(ques) > 0 {
+			maxUtime = ques[len(ques)-1].Utime
 		}
-		if cnt < limit {
+		if len(ques) < s.batchSize {
 			// 全部搞完了
 			break
 		}
-		offset += cnt
+		last := ques[len(ques)-1]
+		utime, id = last.Utime, last.Id
 	}
-	return nil
+	return cnt, maxUtime, w.Flush()
 }
 
-// Batch 实现很不优雅，性能很差，但是我能少些很多代码。等后面性能瓶颈了再说
-func (s *KnowledgeJobStarter) Batch(ctx ejob.Context, offset, limit int, writer *csv.Writer) (int, error) {
-	batchCtx, cancel := context.WithTimeout(ctx.Ctx, time.Second*3)
+// Batch 先按照更新时间翻页，再批量加载答案，返回的是翻页得到的题目
+func (s *KnowledgeJobStarter) Batch(ctx ejob.Context, utime time.Time, id int64,
+	end time.Time, w knowledgeWriter) ([]domain.Question, error) {
+	batchCtx, cancel := context.WithTimeout(ctx.Ctx, s.batchTimeout)
 	defer cancel()
-	ques, err := s.svc.PubList(batchCtx, offset, limit)
-	if err != nil {
-		return 0, err
+	ques, err := s.svc.PubListByUtime(batchCtx, utime, id, end, s.batchSize)
+	if err != nil || len(ques) == 0 {
+		return ques, err
 	}
-	ids := slice.Map(ques, func(idx int, src domain.Question) int64 {
+	details, err := s.svc.PubDetailByIDs(batchCtx, slice.Map(ques, func(idx int, src domain.Question) int64 {
 		return src.Id
-	})
-	// 优化性能
-	for _, qid := range ids {
-		detail, err := s.svc.PubDetail(batchCtx, qid)
-		if err != nil {
-			return 0, err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	detailMap := make(map[int64]domain.Question, len(details))
+	for _, detail := range details {
+		detailMap[detail.Id] = detail
+	}
+	// 保持翻页的顺序，中间被删除了的题目直接跳过
+	for _, que := range ques {
+		detail, ok := detailMap[que.Id]
+		if !ok {
+			continue
 		}
-		// 开始写入
-		err = writer.Write([]string{
-			detail.Title, strings.Join(detail.Labels, ";"), detail.Content,
-			s.formatAnswer(detail.Answer.Analysis),
-			s.formatAnswer(detail.Answer.Basic),
-			s.formatAnswer(detail.Answer.Intermediate),
-			s.formatAnswer(detail.Answer.Advanced),
-		})
+		err = w.Write(detail)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 	}
-	return len(ques), nil
-}
-
-func (s *KnowledgeJobStarter) formatAnswer(ans domain.AnswerElement) string {
-	sb := strings.Builder{}
-	sb.WriteString(ans.Content)
-	sb.WriteByte('\n')
-	sb.WriteString("关键字：" + ans.Keywords)
-	sb.WriteByte('\n')
-	sb.WriteString("引导点：" + ans.Guidance)
-	sb.WriteByte('\n')
-	sb.WriteString("亮点：" + ans.Highlight)
-	sb.WriteByte('\n')
-	sb.WriteString("速记口诀：" + ans.Shorthand)
-	return sb.String()
+	return ques, nil
 }
@@ -0,0 +1,84 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/service"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/task/ejob"
+)
+
+// SyncLabelsJob 将线上库八股文的字符串标签关联到 label 模块的标签上
+// 主要用于迁移历史数据，发布的时候会自动关联，所以重复执行也没有问题
+type SyncLabelsJob struct {
+	batchSize    int
+	batchTimeout time.Duration
+	svc          service.Service
+	logger       *elog.Component
+}
+
+func NewSyncLabelsJob(svc service.Service) *SyncLabelsJob {
+	return &SyncLabelsJob{
+		svc:          svc,
+		batchSize:    100,
+		batchTimeout: time.Second * 10,
+		logger:       elog.DefaultLogger,
+	}
+}
+
+func (j *SyncLabelsJob) Run(ctx ejob.Context) error {
+	var (
+		cnt   int
+		utime = time.UnixMilli(0)
+		id    int64
+		end   = time.Now()
+	)
+	for {
+		ques, err := j.batch(ctx.Ctx, utime, id, end)
+		if err != nil {
+			return err
+		}
+		cnt += len(ques)
+		if len(ques) < j.batchSize {
+			break
+		}
+		last := ques[len(ques)-1]
+		utime, id = last.Utime, last.Id
+	}
+	j.logger.Info("关联题目标签完成", elog.Int("cnt", cnt))
+	return nil
+}
+
+// batch 按照更新时间翻页，返回的是这一批处理过的题目
+func (j *SyncLabelsJob) batch(ctx context.Context, utime time.Time, id int64, end time.Time) ([]domain.Question, error) {
+	ctx, cancel := context.WithTimeout(ctx, j.batchTimeout)
+	defer cancel()
+	ques, err := j.svc.PubListByUtime(ctx, utime, id, end, j.batchSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, que := range ques {
+		err = j.svc.SyncLabels(ctx, que.Id, que.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("关联题目 %d 的标签失败 %w", que.Id, err)
+		}
+	}
+	return ques, nil
+}
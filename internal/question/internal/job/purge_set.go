@@ -0,0 +1,58 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/question/internal/service"
+	"github.com/gotomicro/ego/task/ecron"
+)
+
+var _ ecron.NamedJob = (*PurgeSetTrashJob)(nil)
+
+// PurgeSetTrashJob 彻底删除回收站里面超过保留期限的题集
+type PurgeSetTrashJob struct {
+	svc       service.QuestionSetService
+	retention time.Duration
+	limit     int
+}
+
+func NewPurgeSetTrashJob(svc service.QuestionSetService, retention time.Duration, limit int) *PurgeSetTrashJob {
+	return &PurgeSetTrashJob{
+		svc:       svc,
+		retention: retention,
+		limit:     limit,
+	}
+}
+
+func (j *PurgeSetTrashJob) Name() string {
+	return "PurgeQuestionSetTrashJob"
+}
+
+func (j *PurgeSetTrashJob) Run(ctx context.Context) error {
+	before := time.Now().Add(-j.retention)
+	for {
+		cnt, err := j.svc.PurgeTrash(ctx, before, j.limit)
+		if err != nil {
+			return fmt.Errorf("清理题集回收站失败: %w", err)
+		}
+		if cnt < j.limit {
+			return nil
+		}
+	}
+}
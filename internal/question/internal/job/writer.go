@@ -0,0 +1,187 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+)
+
+// 知识库支持的格式，也是导出文件的后缀
+const (
+	KnowledgeFormatCSV      = "csv"
+	KnowledgeFormatJSONL    = "jsonl"
+	KnowledgeFormatMarkdown = "md"
+)
+
+// knowledgeWriter 将题目写成某一种格式的知识库
+type knowledgeWriter interface {
+	Write(que domain.Question) error
+	// Flush 全部写完之后调用
+	Flush() error
+}
+
+func newKnowledgeWriter(format string, writer io.Writer) (knowledgeWriter, error) {
+	switch format {
+	case KnowledgeFormatCSV:
+		return newCSVKnowledgeWriter(writer)
+	case KnowledgeFormatJSONL:
+		enc := json.NewEncoder(writer)
+		enc.SetEscapeHTML(false)
+		return &jsonlKnowledgeWriter{enc: enc}, nil
+	case KnowledgeFormatMarkdown:
+		return &markdownKnowledgeWriter{w: bufio.NewWriter(writer)}, nil
+	default:
+		return nil, fmt.Errorf("不支持的知识库格式 %s", format)
+	}
+}
+
+// csvKnowledgeWriter 一行一道题目，也是后台批量导入使用的格式
+type csvKnowledgeWriter struct {
+	w *csv.Writer
+}
+
+func newCSVKnowledgeWriter(writer io.Writer) (*csvKnowledgeWriter, error) {
+	w := csv.NewWriter(writer)
+	// 写入标题
+	err := w.Write([]string{"问题", "标签", "问题描述", "问题分析",
+		"15K 答案", "25K 答案", "35K 答案"})
+	return &csvKnowledgeWriter{w: w}, err
+}
+
+func (c *csvKnowledgeWriter) Write(que domain.Question) error {
+	return c.w.Write([]string{
+		que.Title, strings.Join(que.Labels, ";"), que.Content,
+		formatAnswer(que.Answer.Analysis),
+		formatAnswer(que.Answer.Basic),
+		formatAnswer(que.Answer.Intermediate),
+		formatAnswer(que.Answer.Advanced),
+	})
+}
+
+func (c *csvKnowledgeWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlKnowledgeWriter 一行一个 JSON 对象，答案元素保留各个字段
+type jsonlKnowledgeWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlKnowledgeWriter) Write(que domain.Question) error {
+	return j.enc.Encode(knowledgeQuestion{
+		Id:           que.Id,
+		Title:        que.Title,
+		Labels:       que.Labels,
+		Content:      que.Content,
+		Analysis:     newKnowledgeAnswer(que.Answer.Analysis),
+		Basic:        newKnowledgeAnswer(que.Answer.Basic),
+		Intermediate: newKnowledgeAnswer(que.Answer.Intermediate),
+		Advanced:     newKnowledgeAnswer(que.Answer.Advanced),
+		Utime:        que.Utime.UnixMilli(),
+	})
+}
+
+func (j *jsonlKnowledgeWriter) Flush() error {
+	return nil
+}
+
+type knowledgeQuestion struct {
+	Id           int64           `json:"id"`
+	Title        string          `json:"title"`
+	Labels       []string        `json:"labels,omitempty"`
+	Content      string          `json:"content"`
+	Analysis     knowledgeAnswer `json:"analysis"`
+	Basic        knowledgeAnswer `json:"basic"`
+	Intermediate knowledgeAnswer `json:"intermediate"`
+	Advanced     knowledgeAnswer `json:"advanced"`
+	Utime        int64           `json:"utime"`
+}
+
+type knowledgeAnswer struct {
+	Content   string `json:"content"`
+	Keywords  string `json:"keywords,omitempty"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Highlight string `json:"highlight,omitempty"`
+	Guidance  string `json:"guidance,omitempty"`
+}
+
+func newKnowledgeAnswer(ans domain.AnswerElement) knowledgeAnswer {
+	return knowledgeAnswer{
+		Content:   ans.Content,
+		Keywords:  ans.Keywords,
+		Shorthand: ans.Shorthand,
+		Highlight: ans.Highlight,
+		Guidance:  ans.Guidance,
+	}
+}
+
+// markdownKnowledgeWriter 所有题目写在一个文件里面，也是后台批量导入使用的格式
+// 每道题目以 front matter 开头，二级标题和 CSV 的列名一样
+type markdownKnowledgeWriter struct {
+	w *bufio.Writer
+}
+
+func (m *markdownKnowledgeWriter) Write(que domain.Question) error {
+	sb := strings.Builder{}
+	sb.WriteString("---\ntitle: " + que.Title + "\n")
+	if len(que.Labels) > 0 {
+		sb.WriteString("labels: " + strings.Join(que.Labels, ";") + "\n")
+	}
+	sb.WriteString("---\n\n")
+	if que.Content != "" {
+		sb.WriteString(que.Content + "\n\n")
+	}
+	sections := []struct {
+		title string
+		ans   domain.AnswerElement
+	}{
+		{title: "问题分析", ans: que.Answer.Analysis},
+		{title: "15K 答案", ans: que.Answer.Basic},
+		{title: "25K 答案", ans: que.Answer.Intermediate},
+		{title: "35K 答案", ans: que.Answer.Advanced},
+	}
+	for _, section := range sections {
+		sb.WriteString("## " + section.title + "\n\n")
+		sb.WriteString(formatAnswer(section.ans) + "\n\n")
+	}
+	_, err := m.w.WriteString(sb.String())
+	return err
+}
+
+func (m *markdownKnowledgeWriter) Flush() error {
+	return m.w.Flush()
+}
+
+func formatAnswer(ans domain.AnswerElement) string {
+	sb := strings.Builder{}
+	sb.WriteString(ans.Content)
+	sb.WriteByte('\n')
+	sb.WriteString("关键字：" + ans.Keywords)
+	sb.WriteByte('\n')
+	sb.WriteString("引导点：" + ans.Guidance)
+	sb.WriteByte('\n')
+	sb.WriteString("亮点：" + ans.Highlight)
+	sb.WriteByte('\n')
+	sb.WriteString("速记口诀：" + ans.Shorthand)
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKnowledgeWriter(t *testing.T) {
+	que := domain.Question{
+		Id:      1,
+		Title:   "什么是 MVCC",
+		Labels:  []string{"MySQL", "事务"},
+		Content: "问题描述",
+		Answer: domain.Answer{
+			Analysis: domain.AnswerElement{Content: "分析", Keywords: "关键字"},
+			Basic:    domain.AnswerElement{Content: "<基本回答>"},
+		},
+		Utime: time.UnixMilli(123),
+	}
+	emptyAnswer := "\n关键字：\n引导点：\n亮点：\n速记口诀："
+	testCases := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "CSV",
+			format: KnowledgeFormatCSV,
+			want: "问题,标签,问题描述,问题分析,15K 答案,25K 答案,35K 答案\n" +
+				"什么是 MVCC,MySQL;事务,问题描述," +
+				"\"分析\n关键字：关键字\n引导点：\n亮点：\n速记口诀：\"," +
+				"\"<基本回答>" + emptyAnswer + "\"," +
+				"\"" + emptyAnswer + "\",\"" + emptyAnswer + "\"\n",
+		},
+		{
+			name:   "JSONL",
+			format: KnowledgeFormatJSONL,
+			want: `{"id":1,"title":"什么是 MVCC","labels":["MySQL","事务"],"content":"问题描述",` +
+				`"analysis":{"content":"分析","keywords":"关键字"},"basic":{"content":"<基本回答>"},` +
+				`"intermediate":{"content":""},"advanced":{"content":""},"utime":123}` + "\n",
+		},
+		{
+			name:   "Markdown",
+			format: KnowledgeFormatMarkdown,
+			want: "---\ntitle: 什么是 MVCC\nlabels: MySQL;事务\n---\n\n问题描述\n\n" +
+				"## 问题分析\n\n分析\n关键字：关键字\n引导点：\n亮点：\n速记口诀：\n\n" +
+				"## 15K 答案\n\n<基本回答>" + emptyAnswer + "\n\n" +
+				"## 25K 答案\n\n" + emptyAnswer + "\n\n" +
+				"## 35K 答案\n\n" + emptyAnswer + "\n\n",
+		},
+		{
+			name:    "不支持的格式",
+			format:  "xlsx",
+			wantErr: "不支持的知识库格式 xlsx",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w, err := newKnowledgeWriter(tc.format, buf)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, w.Write(que))
+			require.NoError(t, w.Flush())
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
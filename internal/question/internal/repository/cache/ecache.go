@@ -16,21 +16,59 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/ecodeclub/ecache"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 题目和题集都很少更新，而且更新的时候会主动删除缓存
+	detailExpiration = time.Hour
+	// 本地缓存没有办法通知到别的实例，所以过期时间要短一些
+	defaultLocalExpiration = time.Minute
+	// redisKeyPrefix cmd 没有 Namespace，要和 ec 的 Namespace 保持一致
+	redisKeyPrefix = "webook:question:"
 )
 
 type QuestionECache struct {
 	ec ecache.Cache
+	// cmd 用来批量读取，ecache 没有批量读取的接口
+	cmd redis.Cmdable
+	// local 是可选的本地缓存，为 nil 的时候只使用 ec
+	local           ecache.Cache
+	localExpiration time.Duration
+}
+
+func NewQuestionECache(ec ecache.Cache, cmd redis.Cmdable) QuestionCache {
+	return &QuestionECache{
+		ec: &ecache.NamespaceCache{
+			Namespace: "question:",
+			C:         ec,
+		},
+		cmd: cmd,
+	}
 }
 
-func NewQuestionECache(ec ecache.Cache) QuestionCache {
+// NewQuestionECacheWithLocal 在 ec 前面加一层本地缓存，
+// 本地缓存只在当前实例内失效，别的实例要等 localExpiration 过期
+func NewQuestionECacheWithLocal(ec ecache.Cache, cmd redis.Cmdable,
+	local ecache.Cache, localExpiration time.Duration) QuestionCache {
+	if localExpiration <= 0 {
+		localExpiration = defaultLocalExpiration
+	}
 	return &QuestionECache{
 		ec: &ecache.NamespaceCache{
 			Namespace: "question:",
 			C:         ec,
 		},
+		cmd:             cmd,
+		local:           local,
+		localExpiration: localExpiration,
 	}
 }
 
@@ -43,7 +81,149 @@ func (q *QuestionECache) SetTotal(ctx context.Context, total int64) error {
 	return q.ec.Set(ctx, q.totalKey(), total, time.Minute*30)
 }
 
+func (q *QuestionECache) GetPubQuestion(ctx context.Context, qid int64) (domain.Question, error) {
+	var que domain.Question
+	err := q.get(ctx, q.pubQuestionKey(qid), &que)
+	return que, err
+}
+
+// GetPubQuestions 本地缓存没有命中的题目用一次 MGET 从 redis 里面读取
+func (q *QuestionECache) GetPubQuestions(ctx context.Context, qids []int64) (map[int64]domain.Question, error) {
+	res := make(map[int64]domain.Question, len(qids))
+	misses := make([]int64, 0, len(qids))
+	for _, qid := range qids {
+		var que domain.Question
+		if q.getLocal(ctx, q.pubQuestionKey(qid), &que) {
+			res[qid] = que
+			continue
+		}
+		misses = append(misses, qid)
+	}
+	if len(misses) == 0 {
+		return res, nil
+	}
+	vals, err := q.cmd.MGet(ctx, slice.Map(misses, func(idx int, src int64) string {
+		return redisKeyPrefix + q.pubQuestionKey(src)
+	})...).Result()
+	if err != nil {
+		return res, err
+	}
+	for i, val := range vals {
+		// key 不存在的时候是 nil
+		str, ok := val.(string)
+		if !ok {
+			continue
+		}
+		var que domain.Question
+		data := []byte(str)
+		if json.Unmarshal(data, &que) != nil {
+			continue
+		}
+		res[misses[i]] = que
+		q.setLocal(ctx, q.pubQuestionKey(misses[i]), data)
+	}
+	return res, nil
+}
+
+func (q *QuestionECache) SetPubQuestions(ctx context.Context, ques ...domain.Question) error {
+	for _, que := range ques {
+		err := q.set(ctx, q.pubQuestionKey(que.Id), que)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *QuestionECache) DelPubQuestion(ctx context.Context, qid int64) error {
+	return q.del(ctx, q.pubQuestionKey(qid))
+}
+
+func (q *QuestionECache) GetQuestionSet(ctx context.Context, id int64) (domain.QuestionSet, error) {
+	var set domain.QuestionSet
+	err := q.get(ctx, q.questionSetKey(id), &set)
+	return set, err
+}
+
+func (q *QuestionECache) SetQuestionSet(ctx context.Context, set domain.QuestionSet) error {
+	return q.set(ctx, q.questionSetKey(set.Id), set)
+}
+
+func (q *QuestionECache) DelQuestionSets(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, q.questionSetKey(id))
+	}
+	return q.del(ctx, keys...)
+}
+
+// get 先读本地缓存，没有命中再读 ec，并且回填本地缓存
+func (q *QuestionECache) get(ctx context.Context, key string, val any) error {
+	if q.getLocal(ctx, key, val) {
+		return nil
+	}
+	data, err := q.ec.Get(ctx, key).AsBytes()
+	if err != nil {
+		return err
+	}
+	q.setLocal(ctx, key, data)
+	return json.Unmarshal(data, val)
+}
+
+// getLocal 返回本地缓存有没有命中
+func (q *QuestionECache) getLocal(ctx context.Context, key string, val any) bool {
+	if q.local == nil {
+		return false
+	}
+	data, err := q.local.Get(ctx, key).AsBytes()
+	return err == nil && json.Unmarshal(data, val) == nil
+}
+
+// setLocal 回填本地缓存，失败了也没关系
+func (q *QuestionECache) setLocal(ctx context.Context, key string, data []byte) {
+	if q.local != nil {
+		_ = q.local.Set(ctx, key, data, q.localExpiration)
+	}
+}
+
+func (q *QuestionECache) set(ctx context.Context, key string, val any) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	err = q.ec.Set(ctx, key, data, detailExpiration)
+	if err != nil {
+		return err
+	}
+	if q.local != nil {
+		return q.local.Set(ctx, key, data, q.localExpiration)
+	}
+	return nil
+}
+
+func (q *QuestionECache) del(ctx context.Context, keys ...string) error {
+	if q.local != nil {
+		_, err := q.local.Delete(ctx, keys...)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := q.ec.Delete(ctx, keys...)
+	return err
+}
+
 // 注意 Namespace 设置
 func (q *QuestionECache) totalKey() string {
 	return "total"
 }
+
+func (q *QuestionECache) pubQuestionKey(qid int64) string {
+	return fmt.Sprintf("pub:detail:%d", qid)
+}
+
+func (q *QuestionECache) questionSetKey(id int64) string {
+	return fmt.Sprintf("set:detail:%d", id)
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ecache/memory/lru"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionECache_Local(t *testing.T) {
+	ctx := context.Background()
+	remote := lru.NewCache(100)
+	local := lru.NewCache(100)
+	// 批量读取的时候只有本地缓存没有命中才会用到 redis，所以这里不需要
+	c := NewQuestionECacheWithLocal(remote, nil, local, time.Minute)
+
+	que := domain.Question{Id: 1, Title: "标题", Labels: []string{"Go"}}
+	err := c.SetPubQuestions(ctx, que)
+	require.NoError(t, err)
+	assert.False(t, local.Get(ctx, "pub:detail:1").KeyNotFound())
+
+	// 本地缓存没有的时候从远程读取，并且回填本地缓存
+	_, err = local.Delete(ctx, "pub:detail:1")
+	require.NoError(t, err)
+	got, err := c.GetPubQuestion(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, que, got)
+	assert.False(t, local.Get(ctx, "pub:detail:1").KeyNotFound())
+
+	hits, err := c.GetPubQuestions(ctx, []int64{1, 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]domain.Question{1: que}, hits)
+
+	// 删除的时候两层都要删除
+	err = c.DelPubQuestion(ctx, 1)
+	require.NoError(t, err)
+	_, err = c.GetPubQuestion(ctx, 1)
+	assert.Error(t, err)
+	assert.True(t, local.Get(ctx, "pub:detail:1").KeyNotFound())
+	assert.True(t, remote.Get(ctx, "question:pub:detail:1").KeyNotFound())
+
+	set := domain.QuestionSet{Id: 2, Title: "题集", Questions: []domain.Question{que}}
+	err = c.SetQuestionSet(ctx, set)
+	require.NoError(t, err)
+	gotSet, err := c.GetQuestionSet(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, set, gotSet)
+	err = c.DelQuestionSets(ctx, 2, 3)
+	require.NoError(t, err)
+	_, err = c.GetQuestionSet(ctx, 2)
+	assert.Error(t, err)
+}
@@ -14,9 +14,25 @@
 
 package cache
 
-import "context"
+import (
+	"context"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+)
 
 type QuestionCache interface {
 	GetTotal(ctx context.Context) (int64, error)
 	SetTotal(ctx context.Context, total int64) error
+
+	// GetPubQuestion 线上库的题目，包括答案
+	GetPubQuestion(ctx context.Context, qid int64) (domain.Question, error)
+	// GetPubQuestions 只返回命中了缓存的题目
+	GetPubQuestions(ctx context.Context, qids []int64) (map[int64]domain.Question, error)
+	SetPubQuestions(ctx context.Context, ques ...domain.Question) error
+	DelPubQuestion(ctx context.Context, qid int64) error
+
+	// GetQuestionSet 题集详情，包括题集里面的题目
+	GetQuestionSet(ctx context.Context, id int64) (domain.QuestionSet, error)
+	SetQuestionSet(ctx context.Context, set domain.QuestionSet) error
+	DelQuestionSets(ctx context.Context, ids ...int64) error
 }
@@ -0,0 +1,167 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ego-component/egorm"
+	"gorm.io/gorm"
+)
+
+type ExamDAO interface {
+	// CreateExam 同时创建考试和考试中的题目
+	CreateExam(ctx context.Context, exam Exam, questions []ExamQuestion) (int64, error)
+	GetExam(ctx context.Context, uid, id int64) (Exam, error)
+	// GetQuestions 考试中的题目，按照开始考试时候的顺序
+	GetQuestions(ctx context.Context, examId int64) ([]ExamQuestion, error)
+	ListExams(ctx context.Context, uid int64, offset, limit int) ([]Exam, error)
+	CountExams(ctx context.Context, uid int64) (int64, error)
+	// ClaimAnswer 占住还没有回答的题目，staleBefore 之前的占用认为请求已经失败了，可以重新占用
+	// 没有占住返回 ErrRecordNotFound
+	ClaimAnswer(ctx context.Context, examId, qid, claimTime, staleBefore int64) error
+	// ReleaseAnswer 回答失败之后释放 claimTime 的占用
+	ReleaseAnswer(ctx context.Context, examId, qid, claimTime int64) error
+	// SaveAnswer 只有 claimTime 占住的题目，并且考试的状态是 inProgress、claimTime 没有超过截止时间的时候才会保存，
+	// 否则返回 ErrRecordNotFound
+	SaveAnswer(ctx context.Context, question ExamQuestion, claimTime int64, inProgress uint8) error
+	// Finish 保存交卷的结果，只有状态是 from 的时候才会更新，否则返回 ErrRecordNotFound
+	Finish(ctx context.Context, exam Exam, from uint8) error
+}
+
+var _ ExamDAO = &GORMExamDAO{}
+
+type GORMExamDAO struct {
+	db *egorm.Component
+}
+
+func NewGORMExamDAO(db *egorm.Component) ExamDAO {
+	return &GORMExamDAO{db: db}
+}
+
+func (dao *GORMExamDAO) CreateExam(ctx context.Context, exam Exam, questions []ExamQuestion) (int64, error) {
+	now := time.Now().UnixMilli()
+	exam.Ctime = now
+	exam.Utime = now
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&exam).Error
+		if err != nil {
+			return err
+		}
+		for i := range questions {
+			questions[i].ExamId = exam.Id
+			questions[i].Ctime = now
+			questions[i].Utime = now
+		}
+		return tx.Create(&questions).Error
+	})
+	return exam.Id, err
+}
+
+func (dao *GORMExamDAO) GetExam(ctx context.Context, uid, id int64) (Exam, error) {
+	var res Exam
+	err := dao.db.WithContext(ctx).Where("id = ? AND uid = ?", id, uid).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMExamDAO) GetQuestions(ctx context.Context, examId int64) ([]ExamQuestion, error) {
+	var res []ExamQuestion
+	err := dao.db.WithContext(ctx).Where("exam_id = ?", examId).Order("id ASC").Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMExamDAO) ListExams(ctx context.Context, uid int64, offset, limit int) ([]Exam, error) {
+	var res []Exam
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMExamDAO) CountExams(ctx context.Context, uid int64) (int64, error) {
+	var res int64
+	err := dao.db.WithContext(ctx).Model(&Exam{}).Where("uid = ?", uid).Count(&res).Error
+	return res, err
+}
+
+func (dao *GORMExamDAO) ClaimAnswer(ctx context.Context, examId, qid, claimTime, staleBefore int64) error {
+	res := dao.db.WithContext(ctx).Model(&ExamQuestion{}).
+		Where("exam_id = ? AND qid = ? AND answered = ? AND claim_time < ?", examId, qid, false, staleBefore).
+		Updates(map[string]any{
+			"claim_time": claimTime,
+			"utime":      time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w, 题目已经回答过了或者正在回答 exam %d qid %d", ErrRecordNotFound, examId, qid)
+	}
+	return nil
+}
+
+func (dao *GORMExamDAO) ReleaseAnswer(ctx context.Context, examId, qid, claimTime int64) error {
+	return dao.db.WithContext(ctx).Model(&ExamQuestion{}).
+		Where("exam_id = ? AND qid = ? AND answered = ? AND claim_time = ?", examId, qid, false, claimTime).
+		Updates(map[string]any{
+			"claim_time": 0,
+			"utime":      time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMExamDAO) SaveAnswer(ctx context.Context, question ExamQuestion, claimTime int64, inProgress uint8) error {
+	db := dao.db.WithContext(ctx)
+	res := db.Model(&ExamQuestion{}).
+		Where("exam_id = ? AND qid = ? AND answered = ? AND claim_time = ?",
+			question.ExamId, question.Qid, false, claimTime).
+		Where("EXISTS (?)", db.Model(&Exam{}).Select("1").
+			Where("id = ? AND status = ? AND deadline > ?", question.ExamId, inProgress, claimTime)).
+		Updates(map[string]any{
+			"input":    question.Input,
+			"answered": true,
+			"result":   question.Result,
+			"tid":      question.Tid,
+			"utime":    time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w, 考试已经结束或者题目已经回答过了 exam %d qid %d", ErrRecordNotFound, question.ExamId, question.Qid)
+	}
+	return nil
+}
+
+func (dao *GORMExamDAO) Finish(ctx context.Context, exam Exam, from uint8) error {
+	res := dao.db.WithContext(ctx).Model(&Exam{}).
+		Where("id = ? AND uid = ? AND status = ?", exam.Id, exam.Uid, from).
+		Updates(map[string]any{
+			"status":      exam.Status,
+			"finish_time": exam.FinishTime,
+			"level":       exam.Level,
+			"weak_labels": exam.WeakLabels,
+			"utime":       time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w, 考试的状态不是 %d id %d", ErrRecordNotFound, from, exam.Id)
+	}
+	return nil
+}
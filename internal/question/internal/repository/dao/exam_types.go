@@ -0,0 +1,52 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import "github.com/ecodeclub/ekit/sqlx"
+
+// Exam 基于题集的模拟考试
+type Exam struct {
+	Id     int64
+	Uid    int64 `gorm:"index"`
+	Qsid   int64
+	Status uint8
+	// 截止时间，超过了之后自动交卷
+	Deadline   int64
+	FinishTime int64
+	// 整体的评级
+	Level      uint8
+	WeakLabels sqlx.JsonColumn[[]string] `gorm:"type:text"`
+	Ctime      int64
+	Utime      int64
+}
+
+// ExamQuestion 考试中的题目，标题和标签是开始考试时候的快照
+type ExamQuestion struct {
+	Id     int64
+	ExamId int64 `gorm:"uniqueIndex:exam_qid"`
+	Qid    int64 `gorm:"uniqueIndex:exam_qid"`
+	Title  string
+	Labels sqlx.JsonColumn[[]string] `gorm:"type:text"`
+	// 用户的回答
+	Input    string `gorm:"type:text"`
+	Answered bool
+	Result   uint8
+	// 开始回答的时间，避免同一道题目同时回答多次，回答失败之后清零
+	ClaimTime int64
+	// 对应的测试记录
+	Tid   string `gorm:"type:varchar(64)"`
+	Ctime int64
+	Utime int64
+}
@@ -16,6 +16,7 @@ package dao
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/ego-component/egorm"
@@ -29,6 +30,29 @@ type ExamineDAO interface {
 	SaveResult(ctx context.Context, record ExamineRecord) error
 	GetResultByUidAndQid(ctx context.Context, uid int64, qid int64) (QuestionResult, error)
 	GetResultByUidAndQids(ctx context.Context, uid int64, ids []int64) ([]QuestionResult, error)
+
+	// CreateRecord 异步测试，先记录下来，结果由 UpdateResult 补上
+	CreateRecord(ctx context.Context, record ExamineRecord) error
+	GetRecordByTid(ctx context.Context, uid int64, tid string) (ExamineRecord, error)
+	// UpdateResult 更新异步测试的结果，同时更新 QuestionResult
+	// 只有状态是 from 的时候才会更新，否则返回 ErrRecordNotFound
+	UpdateResult(ctx context.Context, record ExamineRecord, from uint8) error
+	// UpdateStatus 只有状态是 from 的时候才会更新，否则返回 ErrRecordNotFound
+	UpdateStatus(ctx context.Context, uid int64, tid string, from, to uint8, failReason string) error
+	// RenewPending 把状态改成 to 并且更新 utime，只有状态是 from 并且 utime 没有变过的时候才会更新，否则返回 ErrRecordNotFound
+	// 并发的时候只有一个能够成功
+	RenewPending(ctx context.Context, uid int64, tid string, from, to uint8, utime int64) error
+
+	// ListRecords 按照时间倒序分页查询某道题目的所有测试记录
+	ListRecords(ctx context.Context, uid, qid int64, offset, limit int) ([]ExamineRecord, error)
+	CountRecords(ctx context.Context, uid, qid int64) (int64, error)
+	// AttemptCtimes 从 start 开始的测试记录的创建时间，只统计状态在 statuses 里面的记录
+	// 按天分组交给业务，数据库的时区可能和业务的不一样
+	AttemptCtimes(ctx context.Context, uid, start int64, statuses []uint8) ([]int64, error)
+	// ResultDistribution 每个评级的测试次数，只统计状态在 statuses 里面的记录
+	ResultDistribution(ctx context.Context, uid int64, statuses []uint8) ([]ResultCount, error)
+	// BestResults 每道题目的最好成绩，只统计状态在 statuses 里面的记录
+	BestResults(ctx context.Context, uid int64, statuses []uint8) ([]BestResult, error)
 }
 
 var _ ExamineDAO = &GORMExamineDAO{}
@@ -58,21 +82,154 @@ func (dao *GORMExamineDAO) SaveResult(ctx context.Context, record ExamineRecord)
 		if err != nil {
 			return err
 		}
-		return tx.Clauses(clause.OnConflict{
-			// 如果有记录了，就更新结果和更新时间
-			DoUpdates: clause.AssignmentColumns([]string{
-				"result", "utime",
-			}),
-		}).Create(&QuestionResult{
-			Uid:    record.Uid,
-			Qid:    record.Qid,
-			Result: record.Result,
-			Ctime:  now,
-			Utime:  now,
-		}).Error
+		return dao.saveQuestionResult(tx, record, now)
+	})
+}
+
+func (dao *GORMExamineDAO) saveQuestionResult(tx *gorm.DB, record ExamineRecord, now int64) error {
+	return tx.Clauses(clause.OnConflict{
+		// 如果有记录了，就更新结果和更新时间
+		DoUpdates: clause.AssignmentColumns([]string{
+			"result", "correctness_score", "depth_score",
+			"highlight_score", "keyword_score", "suggestions", "utime",
+		}),
+	}).Create(&QuestionResult{
+		Uid:              record.Uid,
+		Qid:              record.Qid,
+		Result:           record.Result,
+		CorrectnessScore: record.CorrectnessScore,
+		DepthScore:       record.DepthScore,
+		HighlightScore:   record.HighlightScore,
+		KeywordScore:     record.KeywordScore,
+		Suggestions:      record.Suggestions,
+		Ctime:            now,
+		Utime:            now,
+	}).Error
+}
+
+func (dao *GORMExamineDAO) CreateRecord(ctx context.Context, record ExamineRecord) error {
+	now := time.Now().UnixMilli()
+	record.Ctime = now
+	record.Utime = now
+	return dao.db.WithContext(ctx).Create(&record).Error
+}
+
+func (dao *GORMExamineDAO) GetRecordByTid(ctx context.Context, uid int64, tid string) (ExamineRecord, error) {
+	var res ExamineRecord
+	err := dao.db.WithContext(ctx).Where("uid = ? AND tid = ?", uid, tid).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMExamineDAO) UpdateResult(ctx context.Context, record ExamineRecord, from uint8) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&ExamineRecord{}).
+			Where("uid = ? AND tid = ? AND status = ?", record.Uid, record.Tid, from).
+			Updates(map[string]any{
+				"result":            record.Result,
+				"raw_result":        record.RawResult,
+				"tokens":            record.Tokens,
+				"amount":            record.Amount,
+				"correctness_score": record.CorrectnessScore,
+				"depth_score":       record.DepthScore,
+				"highlight_score":   record.HighlightScore,
+				"keyword_score":     record.KeywordScore,
+				"suggestions":       record.Suggestions,
+				"status":            record.Status,
+				"fail_reason":       record.FailReason,
+				"utime":             now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%w, 状态不是 %d tid %s", ErrRecordNotFound, from, record.Tid)
+		}
+		return dao.saveQuestionResult(tx, record, now)
 	})
 }
 
+func (dao *GORMExamineDAO) UpdateStatus(ctx context.Context, uid int64, tid string, from, to uint8, failReason string) error {
+	res := dao.db.WithContext(ctx).Model(&ExamineRecord{}).
+		Where("uid = ? AND tid = ? AND status = ?", uid, tid, from).
+		Updates(map[string]any{
+			"status":      to,
+			"fail_reason": failReason,
+			"utime":       time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w, 状态不是 %d tid %s", ErrRecordNotFound, from, tid)
+	}
+	return nil
+}
+
+func (dao *GORMExamineDAO) RenewPending(ctx context.Context, uid int64, tid string, from, to uint8, utime int64) error {
+	res := dao.db.WithContext(ctx).Model(&ExamineRecord{}).
+		Where("uid = ? AND tid = ? AND status = ? AND utime = ?", uid, tid, from, utime).
+		Updates(map[string]any{
+			"status": to,
+			"utime":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w, 状态不是 %d 或者已经被更新过了 tid %s", ErrRecordNotFound, from, tid)
+	}
+	return nil
+}
+
+func (dao *GORMExamineDAO) ListRecords(ctx context.Context, uid, qid int64, offset, limit int) ([]ExamineRecord, error) {
+	var res []ExamineRecord
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND qid = ?", uid, qid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMExamineDAO) CountRecords(ctx context.Context, uid, qid int64) (int64, error) {
+	var res int64
+	err := dao.db.WithContext(ctx).Model(&ExamineRecord{}).
+		Where("uid = ? AND qid = ?", uid, qid).
+		Count(&res).Error
+	return res, err
+}
+
+func (dao *GORMExamineDAO) AttemptCtimes(ctx context.Context, uid, start int64, statuses []uint8) ([]int64, error) {
+	var res []int64
+	err := dao.db.WithContext(ctx).Model(&ExamineRecord{}).
+		Where("uid = ? AND ctime >= ? AND status IN ?", uid, start, statuses).
+		Pluck("ctime", &res).Error
+	return res, err
+}
+
+func (dao *GORMExamineDAO) ResultDistribution(ctx context.Context, uid int64, statuses []uint8) ([]ResultCount, error) {
+	var res []ResultCount
+	err := dao.db.WithContext(ctx).Model(&ExamineRecord{}).
+		Select("result, COUNT(*) AS cnt").
+		Where("uid = ? AND status IN ?", uid, statuses).
+		Group("result").
+		Order("result ASC").
+		Scan(&res).Error
+	return res, err
+}
+
+func (dao *GORMExamineDAO) BestResults(ctx context.Context, uid int64, statuses []uint8) ([]BestResult, error) {
+	var res []BestResult
+	err := dao.db.WithContext(ctx).Model(&ExamineRecord{}).
+		Select("qid, MAX(result) AS result, COUNT(*) AS attempts, MAX(ctime) AS last_time").
+		Where("uid = ? AND status IN ?", uid, statuses).
+		Group("qid").
+		Order("last_time DESC").
+		Scan(&res).Error
+	return res, err
+}
+
 func NewGORMExamineDAO(db *egorm.Component) ExamineDAO {
 	return &GORMExamineDAO{db: db}
 }
@@ -14,14 +14,18 @@
 
 package dao
 
+import "github.com/ecodeclub/ekit/sqlx"
+
 // ExamineRecord 业务层面上记录
 type ExamineRecord struct {
-	Id  int64
-	Uid int64
-	Qid int64
+	Id int64
+	// 查询历史记录总是带着 uid，按照题目查询的时候还会带上 qid
+	Uid int64 `gorm:"index:uid_qid"`
+	Qid int64 `gorm:"index:uid_qid"`
 	// 代表这一次测试的 ID
 	// 这个主要是为了和 AI 打交道，有一个唯一凭证
-	Tid    string
+	// 异步测试的时候，前端也是用它来查询结果
+	Tid    string `gorm:"type:varchar(64);index"`
 	Result uint8
 	// 原始的 AI 回答
 	RawResult string
@@ -30,6 +34,20 @@ type ExamineRecord struct {
 	// 冗余字段，花费的金额
 	Amount int64
 
+	// 各个维度的得分，AI 没有返回结构化结果的时候都是 0
+	CorrectnessScore int
+	DepthScore       int
+	HighlightScore   int
+	KeywordScore     int
+	// 改进建议
+	Suggestions sqlx.JsonColumn[[]ExamineSuggestion] `gorm:"type:text"`
+
+	// 用户的回答，异步测试的时候消费者要用
+	Input  string `gorm:"type:text"`
+	Status uint8
+	// 失败的原因，给用户看的
+	FailReason string
+
 	Ctime int64
 	Utime int64
 }
@@ -41,6 +59,34 @@ type QuestionResult struct {
 	Uid    int64 `gorm:"uniqueIndex:uid_qid"`
 	Qid    int64 `gorm:"uniqueIndex:uid_qid"`
 	Result uint8
-	Ctime  int64
-	Utime  int64
+
+	// 最近一次测试的得分和改进建议，和 ExamineRecord 一样
+	CorrectnessScore int
+	DepthScore       int
+	HighlightScore   int
+	KeywordScore     int
+	Suggestions      sqlx.JsonColumn[[]ExamineSuggestion] `gorm:"type:text"`
+
+	Ctime int64
+	Utime int64
+}
+
+type ExamineSuggestion struct {
+	Keyword string `json:"keyword"`
+	Content string `json:"content"`
+}
+
+// ResultCount 某个评级的测试次数
+type ResultCount struct {
+	Result uint8
+	Cnt    int64
+}
+
+// BestResult 某道题目的最好成绩
+type BestResult struct {
+	Qid      int64
+	Result   uint8
+	Attempts int64
+	// 最后一次测试的时间
+	LastTime int64
 }
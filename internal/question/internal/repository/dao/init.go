@@ -26,5 +26,11 @@ func InitTables(db *egorm.Component) error {
 		&QuestionSetQuestion{},
 		&QuestionResult{},
 		&ExamineRecord{},
+		&QuestionReview{},
+		&Exam{},
+		&ExamQuestion{},
+		&KnowledgeExportRun{},
+		&QuestionRevision{},
+		&PublishQuestionLabel{},
 	)
 }
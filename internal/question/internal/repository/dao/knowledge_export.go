@@ -0,0 +1,69 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/ego-component/egorm"
+)
+
+type KnowledgeExportDAO interface {
+	Create(ctx context.Context, run KnowledgeExportRun) (int64, error)
+	// Finish 更新状态、文件、数量和结束时间
+	Finish(ctx context.Context, run KnowledgeExportRun) error
+	// LatestByStatus 某个格式某个状态下水位最高的那一次导出
+	LatestByStatus(ctx context.Context, format string, status uint8) (KnowledgeExportRun, error)
+}
+
+var _ KnowledgeExportDAO = &GORMKnowledgeExportDAO{}
+
+type GORMKnowledgeExportDAO struct {
+	db *egorm.Component
+}
+
+func NewGORMKnowledgeExportDAO(db *egorm.Component) KnowledgeExportDAO {
+	return &GORMKnowledgeExportDAO{db: db}
+}
+
+func (dao *GORMKnowledgeExportDAO) Create(ctx context.Context, run KnowledgeExportRun) (int64, error) {
+	now := time.Now().UnixMilli()
+	run.Ctime = now
+	run.Utime = now
+	err := dao.db.WithContext(ctx).Create(&run).Error
+	return run.Id, err
+}
+
+func (dao *GORMKnowledgeExportDAO) Finish(ctx context.Context, run KnowledgeExportRun) error {
+	return dao.db.WithContext(ctx).Model(&KnowledgeExportRun{}).
+		Where("id = ?", run.Id).Updates(map[string]any{
+		"status":   run.Status,
+		"file":     run.File,
+		"cnt":      run.Cnt,
+		"err_msg":  run.ErrMsg,
+		"end_time": run.EndTime,
+		"utime":    time.Now().UnixMilli(),
+	}).Error
+}
+
+func (dao *GORMKnowledgeExportDAO) LatestByStatus(ctx context.Context, format string, status uint8) (KnowledgeExportRun, error) {
+	var res KnowledgeExportRun
+	err := dao.db.WithContext(ctx).
+		Where("format = ? AND status = ?", format, status).
+		Order("watermark DESC, id DESC").
+		First(&res).Error
+	return res, err
+}
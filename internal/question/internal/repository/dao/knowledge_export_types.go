@@ -0,0 +1,33 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+// KnowledgeExportRun 导出知识库的记录
+type KnowledgeExportRun struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	Format      string `gorm:"type:varchar(32);index:format_status"`
+	Incremental bool
+	Status      uint8 `gorm:"type:tinyint(3);index:format_status;comment:0-未知 1-运行中 2-成功 3-失败"`
+	// 导出的题目的更新时间范围是 (since, watermark]
+	Since     int64
+	Watermark int64
+	File      string `gorm:"type:varchar(512)"`
+	Cnt       int64
+	ErrMsg    string `gorm:"type:varchar(1024)"`
+	StartTime int64
+	EndTime   int64
+	Ctime     int64
+	Utime     int64
+}
@@ -0,0 +1,29 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+// PublishQuestionLabel 线上库题目和 label 模块里面的标签的关联关系
+type PublishQuestionLabel struct {
+	Id      int64 `gorm:"primaryKey,autoIncrement"`
+	Qid     int64 `gorm:"uniqueIndex:qid_label_id"`
+	LabelId int64 `gorm:"uniqueIndex:qid_label_id;index"`
+	Ctime   int64
+}
+
+// LabelCount 某个标签关联的数据数量
+type LabelCount struct {
+	LabelId int64
+	Cnt     int64
+}
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ego-component/egorm"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -28,9 +29,11 @@ type QuestionDAO interface {
 	Update(ctx context.Context, q Question, eles []AnswerElement) error
 	Create(ctx context.Context, q Question, eles []AnswerElement) (int64, error)
 	GetByID(ctx context.Context, id int64) (Question, []AnswerElement, error)
+	// GetByTitles 制作库里面指定业务下标题在 titles 中的题目
+	GetByTitles(ctx context.Context, biz string, titles []string) ([]Question, error)
 	List(ctx context.Context, offset int, limit int) ([]Question, error)
 	Count(ctx context.Context) (int64, error)
-	// Delete 会直接删除制作库和线上库的数据
+	// Delete 会直接删除制作库和线上库的数据，历史版本会保留
 	Delete(ctx context.Context, qid int64) error
 
 	Sync(ctx context.Context, que Question, eles []AnswerElement) (int64, error)
@@ -40,6 +43,17 @@ type QuestionDAO interface {
 	PubCount(ctx context.Context) (int64, error)
 	GetPubByID(ctx context.Context, qid int64) (PublishQuestion, []PublishAnswerElement, error)
 	GetPubByIDs(ctx context.Context, qids []int64) ([]PublishQuestion, error)
+	// PubListByUtime 按照 utime 和 id 升序翻页，返回 (utime, id) 之后，utime 不晚于 end 的数据
+	PubListByUtime(ctx context.Context, biz string, utime, id, end int64, limit int) ([]PublishQuestion, error)
+	GetPubAnswerElementsByQids(ctx context.Context, qids []int64) ([]PublishAnswerElement, error)
+	// SyncLabels 用 labelIds 覆盖线上库题目关联的标签
+	SyncLabels(ctx context.Context, qid int64, labelIds []int64) error
+	// PubListByLabels 线上库里面命中标签的题目，all 为 true 的时候要求同时命中全部标签，labelIds 需要去重
+	PubListByLabels(ctx context.Context, offset int, limit int, biz string, labelIds []int64, all bool) ([]PublishQuestion, error)
+	// PubLabelCounts 线上库里面每个标签关联的题目数量
+	PubLabelCounts(ctx context.Context, biz string) ([]LabelCount, error)
+	// QuestionSetIDsByQid 引用了该题目的题集
+	QuestionSetIDsByQid(ctx context.Context, qid int64) ([]int64, error)
 }
 
 type GORMQuestionDAO struct {
@@ -53,6 +67,32 @@ func (g *GORMQuestionDAO) GetPubByIDs(ctx context.Context, qids []int64) ([]Publ
 	return qs, err
 }
 
+func (g *GORMQuestionDAO) QuestionSetIDsByQid(ctx context.Context, qid int64) ([]int64, error) {
+	var ids []int64
+	err := g.db.WithContext(ctx).Model(&QuestionSetQuestion{}).
+		Where("qid = ?", qid).Pluck("qs_id", &ids).Error
+	return ids, err
+}
+
+func (g *GORMQuestionDAO) PubListByUtime(ctx context.Context, biz string, utime, id, end int64, limit int) ([]PublishQuestion, error) {
+	var res []PublishQuestion
+	// 不用 offset，是因为导出的过程中有题目被修改的话，offset 会跳过一些数据
+	err := g.db.WithContext(ctx).
+		Where("biz = ? AND utime <= ?", biz, end).
+		Where("utime > ? OR (utime = ? AND id > ?)", utime, utime, id).
+		Order("utime ASC, id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMQuestionDAO) GetPubAnswerElementsByQids(ctx context.Context, qids []int64) ([]PublishAnswerElement, error) {
+	var res []PublishAnswerElement
+	err := g.db.WithContext(ctx).Where("qid IN ?", qids).
+		Order("qid ASC, type ASC").Find(&res).Error
+	return res, err
+}
+
 func (g *GORMQuestionDAO) GetPubByID(ctx context.Context, qid int64) (PublishQuestion, []PublishAnswerElement, error) {
 	var q PublishQuestion
 	db := g.db.WithContext(ctx)
@@ -66,7 +106,7 @@ func (g *GORMQuestionDAO) GetPubByID(ctx context.Context, qid int64) (PublishQue
 	return q, eles, err
 }
 
-// Delete 会直接删除制作库和线上库的数据
+// Delete 会直接删除制作库和线上库的数据，历史版本不删除，删除之后还可以从历史版本恢复
 func (g *GORMQuestionDAO) Delete(ctx context.Context, qid int64) error {
 	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		err := tx.Where("id = ?", qid).Delete(&Question{}).Error
@@ -88,13 +128,22 @@ func (g *GORMQuestionDAO) Delete(ctx context.Context, qid int64) error {
 		if err != nil {
 			return err
 		}
+
+		err = tx.Where("qid = ?", qid).Delete(&PublishQuestionLabel{}).Error
+		if err != nil {
+			return err
+		}
 		return tx.Where("qid = ?", qid).Delete(&QuestionSetQuestion{}).Error
 	})
 }
 
 func (g *GORMQuestionDAO) Update(ctx context.Context, q Question, eles []AnswerElement) error {
 	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return g.update(tx, q, eles)
+		err := g.update(tx, q, eles)
+		if err != nil {
+			return err
+		}
+		return g.createRevision(tx, q.Id, q, eles)
 	})
 }
 
@@ -112,15 +161,36 @@ func (g *GORMQuestionDAO) update(tx *gorm.DB, q Question, eles []AnswerElement)
 	if res.Error != nil {
 		return res.Error
 	}
+	if res.RowsAffected == 0 {
+		err := g.recreate(tx, q, now)
+		if err != nil {
+			return err
+		}
+	}
 	return g.saveEles(tx, eles)
 }
 
+// recreate 题目已经删除了的时候，例如从历史版本恢复，用原来的 id 重新创建
+func (g *GORMQuestionDAO) recreate(tx *gorm.DB, q Question, now int64) error {
+	var cnt int64
+	err := tx.Model(&Question{}).Where("id = ?", q.Id).Count(&cnt).Error
+	if err != nil || cnt > 0 {
+		return err
+	}
+	q.Ctime = now
+	q.Utime = now
+	return tx.Create(&q).Error
+}
+
 func (g *GORMQuestionDAO) Create(ctx context.Context, q Question, eles []AnswerElement) (int64, error) {
 	var qid int64
 	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var err error
 		qid, err = g.create(tx, q, eles)
-		return err
+		if err != nil {
+			return err
+		}
+		return g.createRevision(tx, qid, q, eles)
 	})
 	return qid, err
 }
@@ -161,6 +231,49 @@ func (g *GORMQuestionDAO) PubList(ctx context.Context, offset int, limit int, bi
 	return res, err
 }
 
+func (g *GORMQuestionDAO) PubListByLabels(ctx context.Context, offset int, limit int, biz string, labelIds []int64, all bool) ([]PublishQuestion, error) {
+	var res []PublishQuestion
+	db := g.db.WithContext(ctx)
+	sub := db.Model(&PublishQuestionLabel{}).Select("qid").Where("label_id IN ?", labelIds)
+	if all {
+		sub = sub.Group("qid").Having("COUNT(DISTINCT label_id) = ?", len(labelIds))
+	}
+	err := db.Where("biz = ? AND id IN (?)", biz, sub).
+		Offset(offset).Limit(limit).Order("id DESC").
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMQuestionDAO) PubLabelCounts(ctx context.Context, biz string) ([]LabelCount, error) {
+	var res []LabelCount
+	err := g.db.WithContext(ctx).Model(&PublishQuestionLabel{}).
+		Select("publish_question_labels.label_id AS label_id, COUNT(*) AS cnt").
+		Joins("JOIN publish_questions ON publish_questions.id = publish_question_labels.qid").
+		Where("publish_questions.biz = ?", biz).
+		Group("publish_question_labels.label_id").
+		Order("cnt DESC, label_id ASC").
+		Scan(&res).Error
+	return res, err
+}
+
+func (g *GORMQuestionDAO) SyncLabels(ctx context.Context, qid int64, labelIds []int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		del := tx.Where("qid = ?", qid)
+		if len(labelIds) > 0 {
+			del = del.Where("label_id NOT IN ?", labelIds)
+		}
+		err := del.Delete(&PublishQuestionLabel{}).Error
+		if err != nil || len(labelIds) == 0 {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		rels := slice.Map(labelIds, func(idx int, src int64) PublishQuestionLabel {
+			return PublishQuestionLabel{Qid: qid, LabelId: src, Ctime: now}
+		})
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rels).Error
+	})
+}
+
 func (g *GORMQuestionDAO) PubCount(ctx context.Context) (int64, error) {
 	var res int64
 	err := g.db.WithContext(ctx).Model(&PublishQuestion{}).Select("COUNT(id)").Count(&res).Error
@@ -180,6 +293,15 @@ func (g *GORMQuestionDAO) GetByID(ctx context.Context, id int64) (Question, []An
 	return q, eles, err
 }
 
+func (g *GORMQuestionDAO) GetByTitles(ctx context.Context, biz string, titles []string) ([]Question, error) {
+	var res []Question
+	err := g.db.WithContext(ctx).
+		Where("biz = ? AND title IN ?", biz, titles).
+		Order("id ASC").
+		Find(&res).Error
+	return res, err
+}
+
 func (g *GORMQuestionDAO) saveEles(tx *gorm.DB, eles []AnswerElement) error {
 	return tx.Clauses(clause.OnConflict{
 		DoUpdates: clause.AssignmentColumns([]string{
@@ -221,11 +343,41 @@ func (g *GORMQuestionDAO) Sync(ctx context.Context, que Question, eles []AnswerE
 			src.Qid = qid
 			return PublishAnswerElement(src)
 		})
-		return g.saveLive(tx, PublishQuestion(que), pubEles)
+		err = g.saveLive(tx, PublishQuestion(que), pubEles)
+		if err != nil {
+			return err
+		}
+		return g.createRevision(tx, qid, que, eles)
 	})
 	return qid, err
 }
 
+// createRevision 保存和发布都会在同一个事务里面记录一个版本
+func (g *GORMQuestionDAO) createRevision(tx *gorm.DB, qid int64, q Question, eles []AnswerElement) error {
+	answer := slice.Map(eles, func(idx int, src AnswerElement) RevisionAnswerElement {
+		return RevisionAnswerElement{
+			Type:      src.Type,
+			Content:   src.Content,
+			Keywords:  src.Keywords,
+			Shorthand: src.Shorthand,
+			Highlight: src.Highlight,
+			Guidance:  src.Guidance,
+		}
+	})
+	return tx.Create(&QuestionRevision{
+		Qid:     qid,
+		Uid:     q.Uid,
+		Labels:  q.Labels,
+		Title:   q.Title,
+		Content: q.Content,
+		Biz:     q.Biz,
+		BizId:   q.BizId,
+		Status:  q.Status,
+		Answer:  sqlx.JsonColumn[[]RevisionAnswerElement]{Val: answer, Valid: true},
+		Ctime:   time.Now().UnixMilli(),
+	}).Error
+}
+
 func NewGORMQuestionDAO(db *egorm.Component) QuestionDAO {
 	return &GORMQuestionDAO{db: db}
 }
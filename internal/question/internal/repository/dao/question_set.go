@@ -18,6 +18,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
 	"github.com/ego-component/egorm"
 	"gorm.io/gorm"
 )
@@ -35,8 +36,30 @@ type QuestionSetDAO interface {
 	GetByIDs(ctx context.Context, ids []int64) ([]QuestionSet, error)
 	ListByBiz(ctx context.Context, offset int, limit int, biz string) ([]QuestionSet, error)
 	GetByBiz(ctx context.Context, biz string, bizId int64) (QuestionSet, error)
+	// ListByLabels 题集的标签就是题集内线上库题目的标签，all 为 true 的时候要求同时命中全部标签，labelIds 需要去重
+	ListByLabels(ctx context.Context, offset int, limit int, biz string, labelIds []int64, all bool) ([]QuestionSet, error)
+	// LabelCounts 每个标签关联的题集数量
+	LabelCounts(ctx context.Context, biz string) ([]LabelCount, error)
+
+	// UpdateStatus 回收站里面的题集只能通过 Restore 恢复，不会被修改
+	UpdateStatus(ctx context.Context, id int64, status uint8) error
+	// Delete 放入回收站
+	Delete(ctx context.Context, id int64) error
+	// Restore 将回收站里面的题集恢复成已下线
+	Restore(ctx context.Context, id int64) error
+	TrashList(ctx context.Context, offset, limit int) ([]QuestionSet, error)
+	TrashCount(ctx context.Context) (int64, error)
+	// ListDeletedBefore 回收站里面在 utime 之前删除的题集
+	ListDeletedBefore(ctx context.Context, utime int64, limit int) ([]QuestionSet, error)
+	// Purge 彻底删除回收站里面的题集
+	Purge(ctx context.Context, id int64) error
 }
 
+var (
+	publishedSetStatus = domain.QuestionSetStatusPublished.ToUint8()
+	deletedSetStatus   = domain.QuestionSetStatusDeleted.ToUint8()
+)
+
 type GORMQuestionSetDAO struct {
 	db *egorm.Component
 }
@@ -44,7 +67,7 @@ type GORMQuestionSetDAO struct {
 func (g *GORMQuestionSetDAO) GetByBiz(ctx context.Context, biz string, bizId int64) (QuestionSet, error) {
 	var res QuestionSet
 	db := g.db.WithContext(ctx)
-	err := db.Where("biz = ? AND biz_id = ?", biz, bizId).
+	err := db.Where("biz = ? AND biz_id = ? AND status = ?", biz, bizId, publishedSetStatus).
 		Order("utime DESC").
 		First(&res).Error
 	return res, err
@@ -53,14 +76,44 @@ func (g *GORMQuestionSetDAO) GetByBiz(ctx context.Context, biz string, bizId int
 func (g *GORMQuestionSetDAO) ListByBiz(ctx context.Context, offset int, limit int, biz string) ([]QuestionSet, error) {
 	var res []QuestionSet
 	db := g.db.WithContext(ctx)
-	err := db.Where("biz = ?", biz).
+	err := db.Where("biz = ? AND status = ?", biz, publishedSetStatus).
 		Offset(offset).Limit(limit).Order("id DESC").Find(&res).Error
 	return res, err
 }
 
+func (g *GORMQuestionSetDAO) ListByLabels(ctx context.Context, offset int, limit int, biz string, labelIds []int64, all bool) ([]QuestionSet, error) {
+	var res []QuestionSet
+	db := g.db.WithContext(ctx)
+	sub := db.Model(&QuestionSetQuestion{}).
+		Select("question_set_questions.qs_id").
+		Joins("JOIN publish_question_labels ON publish_question_labels.qid = question_set_questions.qid").
+		Where("publish_question_labels.label_id IN ?", labelIds)
+	if all {
+		sub = sub.Group("question_set_questions.qs_id").
+			Having("COUNT(DISTINCT publish_question_labels.label_id) = ?", len(labelIds))
+	}
+	err := db.Where("biz = ? AND status = ? AND id IN (?)", biz, publishedSetStatus, sub).
+		Offset(offset).Limit(limit).Order("id DESC").
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMQuestionSetDAO) LabelCounts(ctx context.Context, biz string) ([]LabelCount, error) {
+	var res []LabelCount
+	err := g.db.WithContext(ctx).Model(&QuestionSetQuestion{}).
+		Select("publish_question_labels.label_id AS label_id, COUNT(DISTINCT question_set_questions.qs_id) AS cnt").
+		Joins("JOIN publish_question_labels ON publish_question_labels.qid = question_set_questions.qid").
+		Joins("JOIN question_sets ON question_sets.id = question_set_questions.qs_id").
+		Where("question_sets.biz = ? AND question_sets.status = ?", biz, publishedSetStatus).
+		Group("publish_question_labels.label_id").
+		Order("cnt DESC, label_id ASC").
+		Scan(&res).Error
+	return res, err
+}
+
 func (g *GORMQuestionSetDAO) GetByIDs(ctx context.Context, ids []int64) ([]QuestionSet, error) {
 	var res []QuestionSet
-	err := g.db.WithContext(ctx).Where("id IN ?", ids).Find(&res).Error
+	err := g.db.WithContext(ctx).Where("id IN ? AND status = ?", ids, publishedSetStatus).Find(&res).Error
 	return res, err
 }
 
@@ -133,7 +186,7 @@ func (g *GORMQuestionSetDAO) UpdateQuestionsByID(ctx context.Context, id int64,
 
 func (g *GORMQuestionSetDAO) Count(ctx context.Context) (int64, error) {
 	var res int64
-	db := g.db.WithContext(ctx).Model(&QuestionSet{})
+	db := g.db.WithContext(ctx).Model(&QuestionSet{}).Where("status <> ?", deletedSetStatus)
 	err := db.Select("COUNT(id)").Count(&res).Error
 	return res, err
 }
@@ -141,10 +194,71 @@ func (g *GORMQuestionSetDAO) Count(ctx context.Context) (int64, error) {
 func (g *GORMQuestionSetDAO) List(ctx context.Context, offset, limit int) ([]QuestionSet, error) {
 	var res []QuestionSet
 	db := g.db.WithContext(ctx)
-	err := db.Offset(offset).Limit(limit).Order("id DESC").Find(&res).Error
+	err := db.Where("status <> ?", deletedSetStatus).
+		Offset(offset).Limit(limit).Order("id DESC").Find(&res).Error
+	return res, err
+}
+
+func (g *GORMQuestionSetDAO) UpdateStatus(ctx context.Context, id int64, status uint8) error {
+	return g.db.WithContext(ctx).Model(&QuestionSet{}).
+		Where("id = ? AND status <> ?", id, deletedSetStatus).
+		Updates(map[string]any{
+			"status": status,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (g *GORMQuestionSetDAO) Delete(ctx context.Context, id int64) error {
+	return g.db.WithContext(ctx).Model(&QuestionSet{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status": deletedSetStatus,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (g *GORMQuestionSetDAO) Restore(ctx context.Context, id int64) error {
+	return g.db.WithContext(ctx).Model(&QuestionSet{}).
+		Where("id = ? AND status = ?", id, deletedSetStatus).
+		Updates(map[string]any{
+			"status": domain.QuestionSetStatusUnPublished.ToUint8(),
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (g *GORMQuestionSetDAO) TrashList(ctx context.Context, offset, limit int) ([]QuestionSet, error) {
+	var res []QuestionSet
+	err := g.db.WithContext(ctx).Where("status = ?", deletedSetStatus).
+		Offset(offset).Limit(limit).Order("utime DESC").Find(&res).Error
+	return res, err
+}
+
+func (g *GORMQuestionSetDAO) TrashCount(ctx context.Context) (int64, error) {
+	var res int64
+	err := g.db.WithContext(ctx).Model(&QuestionSet{}).
+		Where("status = ?", deletedSetStatus).
+		Select("COUNT(id)").Count(&res).Error
 	return res, err
 }
 
+func (g *GORMQuestionSetDAO) ListDeletedBefore(ctx context.Context, utime int64, limit int) ([]QuestionSet, error) {
+	var res []QuestionSet
+	err := g.db.WithContext(ctx).
+		Where("status = ? AND utime < ?", deletedSetStatus, utime).
+		Order("utime ASC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (g *GORMQuestionSetDAO) Purge(ctx context.Context, id int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND status = ?", id, deletedSetStatus).Delete(&QuestionSet{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Where("qs_id = ?", id).Delete(&QuestionSetQuestion{}).Error
+	})
+}
+
 func NewGORMQuestionSetDAO(db *egorm.Component) QuestionSetDAO {
 	return &GORMQuestionSetDAO{db: db}
 }
@@ -0,0 +1,107 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/ego-component/egorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewDAO interface {
+	GetReview(ctx context.Context, uid, qid int64) (QuestionReview, error)
+	// SaveReview 没有就创建，有就更新
+	SaveReview(ctx context.Context, review QuestionReview) error
+	// BatchCreateReviews 已经有了的直接忽略
+	BatchCreateReviews(ctx context.Context, reviews []QuestionReview) error
+	// UnscheduledResults 有测试结果但是还没有复习计划的题目
+	UnscheduledResults(ctx context.Context, uid int64) ([]QuestionResult, error)
+	// CountReviewed 从 start 开始按照计划复习了的题目数量
+	CountReviewed(ctx context.Context, uid, start int64) (int64, error)
+	// ListDue 在 end 之前需要复习的题目，最早到期的在前面，qids 不为空的时候只查询这些题目
+	ListDue(ctx context.Context, uid, end int64, qids []int64, limit int) ([]QuestionReview, error)
+}
+
+var _ ReviewDAO = &GORMReviewDAO{}
+
+type GORMReviewDAO struct {
+	db *egorm.Component
+}
+
+func NewGORMReviewDAO(db *egorm.Component) ReviewDAO {
+	return &GORMReviewDAO{db: db}
+}
+
+func (dao *GORMReviewDAO) GetReview(ctx context.Context, uid, qid int64) (QuestionReview, error) {
+	var res QuestionReview
+	err := dao.db.WithContext(ctx).Where("uid = ? AND qid = ?", uid, qid).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMReviewDAO) SaveReview(ctx context.Context, review QuestionReview) error {
+	now := time.Now().UnixMilli()
+	review.Ctime = now
+	review.Utime = now
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{
+			"repetitions", "interval_days", "ease_factor", "last_result",
+			"last_review", "reviewed_at", "next_review", "utime",
+		}),
+	}).Create(&review).Error
+}
+
+func (dao *GORMReviewDAO) BatchCreateReviews(ctx context.Context, reviews []QuestionReview) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+	for i := range reviews {
+		reviews[i].Ctime = now
+		reviews[i].Utime = now
+	}
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&reviews).Error
+}
+
+func (dao *GORMReviewDAO) UnscheduledResults(ctx context.Context, uid int64) ([]QuestionResult, error) {
+	var res []QuestionResult
+	err := dao.db.WithContext(ctx).
+		Table("question_results AS r").
+		Select("r.*").
+		Joins("LEFT JOIN question_reviews AS v ON v.uid = r.uid AND v.qid = r.qid").
+		Where("r.uid = ? AND v.id IS NULL", uid).
+		Scan(&res).Error
+	return res, err
+}
+
+func (dao *GORMReviewDAO) CountReviewed(ctx context.Context, uid, start int64) (int64, error) {
+	var res int64
+	err := dao.db.WithContext(ctx).Model(&QuestionReview{}).
+		Where("uid = ? AND reviewed_at >= ?", uid, start).
+		Count(&res).Error
+	return res, err
+}
+
+func (dao *GORMReviewDAO) ListDue(ctx context.Context, uid, end int64, qids []int64, limit int) ([]QuestionReview, error) {
+	var res []QuestionReview
+	db := dao.db.WithContext(ctx).Where("uid = ? AND next_review < ?", uid, end)
+	if len(qids) > 0 {
+		db = db.Where("qid IN ?", qids)
+	}
+	err := db.Order("next_review ASC").Limit(limit).Find(&res).Error
+	return res, err
+}
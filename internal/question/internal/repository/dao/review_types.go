@@ -0,0 +1,35 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+// QuestionReview 用户某道题目的复习计划
+type QuestionReview struct {
+	Id  int64
+	Uid int64 `gorm:"uniqueIndex:uid_qid;index:uid_next_review"`
+	Qid int64 `gorm:"uniqueIndex:uid_qid"`
+	// 连续通过的次数
+	Repetitions int
+	// 距离下一次复习的天数，interval 是 MySQL 的关键字
+	Interval int `gorm:"column:interval_days"`
+	// 难度系数，放大了 1000 倍
+	EaseFactor int
+	LastResult uint8
+	LastReview int64
+	// 最后一次按照计划复习的时间
+	ReviewedAt int64
+	NextReview int64 `gorm:"index:uid_next_review"`
+	Ctime      int64
+	Utime      int64
+}
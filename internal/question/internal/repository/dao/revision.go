@@ -0,0 +1,61 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/ego-component/egorm"
+)
+
+// RevisionDAO 只读，版本是在 QuestionDAO 保存题目的事务里面创建的
+type RevisionDAO interface {
+	// ListRevisions 最新的版本在前面
+	ListRevisions(ctx context.Context, qid int64, offset, limit int) ([]QuestionRevision, error)
+	CountRevisions(ctx context.Context, qid int64) (int64, error)
+	GetRevision(ctx context.Context, qid, id int64) (QuestionRevision, error)
+}
+
+var _ RevisionDAO = &GORMRevisionDAO{}
+
+type GORMRevisionDAO struct {
+	db *egorm.Component
+}
+
+func NewGORMRevisionDAO(db *egorm.Component) RevisionDAO {
+	return &GORMRevisionDAO{db: db}
+}
+
+func (dao *GORMRevisionDAO) ListRevisions(ctx context.Context, qid int64, offset, limit int) ([]QuestionRevision, error) {
+	var res []QuestionRevision
+	err := dao.db.WithContext(ctx).Where("qid = ?", qid).
+		Offset(offset).Limit(limit).
+		Order("id DESC").
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMRevisionDAO) CountRevisions(ctx context.Context, qid int64) (int64, error) {
+	var res int64
+	err := dao.db.WithContext(ctx).Model(&QuestionRevision{}).
+		Where("qid = ?", qid).Count(&res).Error
+	return res, err
+}
+
+func (dao *GORMRevisionDAO) GetRevision(ctx context.Context, qid, id int64) (QuestionRevision, error) {
+	var res QuestionRevision
+	err := dao.db.WithContext(ctx).Where("id = ? AND qid = ?", id, qid).First(&res).Error
+	return res, err
+}
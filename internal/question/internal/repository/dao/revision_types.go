@@ -0,0 +1,44 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import "github.com/ecodeclub/ekit/sqlx"
+
+// QuestionRevision 每一次保存或者发布时候题目和答案的快照，创建之后不会修改
+type QuestionRevision struct {
+	Id  int64 `gorm:"primaryKey,autoIncrement"`
+	Qid int64 `gorm:"index"`
+	// 这一次修改的人，不一定是题目的作者
+	Uid     int64
+	Labels  sqlx.JsonColumn[[]string] `gorm:"type:varchar(512)"`
+	Title   string                    `gorm:"type=varchar(512)"`
+	Content string
+	Biz     string `gorm:"type=varchar(256)"`
+	BizId   int64
+	// 1-保存草稿 2-发布
+	Status uint8 `gorm:"type:tinyint(3);comment:1-保存草稿 2-发布"`
+	// 固定是四个答案元素，没有必要再建一个表
+	Answer sqlx.JsonColumn[[]RevisionAnswerElement] `gorm:"type:text"`
+	Ctime  int64
+}
+
+type RevisionAnswerElement struct {
+	Type      uint8  `json:"type"`
+	Content   string `json:"content"`
+	Keywords  string `json:"keywords"`
+	Shorthand string `json:"shorthand"`
+	Highlight string `json:"highlight"`
+	Guidance  string `json:"guidance"`
+}
@@ -85,6 +85,8 @@ type QuestionSet struct {
 	// 举个例子来说，一个面试项目的模拟面试题，一部分是面试项目本身的题目，一部分是八股文
 	Biz   string `gorm:"type=varchar(256);index:biz;not null;default:'baguwen';"`
 	BizId int64  `gorm:"index:biz;not null;default:0;"`
+	// 历史数据都是对外展示的，所以默认是已发表
+	Status uint8 `gorm:"type:tinyint(3);not null;default:2;comment:1-已下线 2-已发表 3-已删除"`
 
 	Ctime int64
 	Utime int64 `gorm:"index"`
@@ -0,0 +1,163 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ekit/sqlx"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
+)
+
+type ExamRepository interface {
+	CreateExam(ctx context.Context, exam domain.Exam) (int64, error)
+	// GetExam 包括考试中的题目
+	GetExam(ctx context.Context, uid, id int64) (domain.Exam, error)
+	// ListExams 不包括考试中的题目
+	ListExams(ctx context.Context, uid int64, offset, limit int) ([]domain.Exam, error)
+	CountExams(ctx context.Context, uid int64) (int64, error)
+	// ClaimAnswer 回答之前先占住题目，每道题目只能回答一次
+	// 占用的时间早于 staleBefore 的认为之前的请求已经失败了，可以重新占用
+	ClaimAnswer(ctx context.Context, examId, qid int64, claimTime, staleBefore time.Time) error
+	ReleaseAnswer(ctx context.Context, examId, qid int64, claimTime time.Time) error
+	// SaveAnswer 只能保存自己占住的题目，并且考试还在进行中、占住题目的时候没有超过截止时间
+	SaveAnswer(ctx context.Context, examId int64, question domain.ExamQuestion, claimTime time.Time) error
+	// Finish 只有进行中的考试才能交卷
+	Finish(ctx context.Context, exam domain.Exam) error
+}
+
+var _ ExamRepository = &examRepository{}
+
+type examRepository struct {
+	dao dao.ExamDAO
+}
+
+func NewExamRepository(dao dao.ExamDAO) ExamRepository {
+	return &examRepository{dao: dao}
+}
+
+func (repo *examRepository) CreateExam(ctx context.Context, exam domain.Exam) (int64, error) {
+	return repo.dao.CreateExam(ctx, repo.toEntity(exam),
+		slice.Map(exam.Questions, func(idx int, src domain.ExamQuestion) dao.ExamQuestion {
+			return repo.toQuestionEntity(exam.Id, src)
+		}))
+}
+
+func (repo *examRepository) GetExam(ctx context.Context, uid, id int64) (domain.Exam, error) {
+	exam, err := repo.dao.GetExam(ctx, uid, id)
+	if err != nil {
+		return domain.Exam{}, err
+	}
+	questions, err := repo.dao.GetQuestions(ctx, id)
+	if err != nil {
+		return domain.Exam{}, err
+	}
+	res := repo.toDomain(exam)
+	res.Questions = slice.Map(questions, func(idx int, src dao.ExamQuestion) domain.ExamQuestion {
+		return repo.toQuestionDomain(src)
+	})
+	return res, nil
+}
+
+func (repo *examRepository) ListExams(ctx context.Context, uid int64, offset, limit int) ([]domain.Exam, error) {
+	res, err := repo.dao.ListExams(ctx, uid, offset, limit)
+	return slice.Map(res, func(idx int, src dao.Exam) domain.Exam {
+		return repo.toDomain(src)
+	}), err
+}
+
+func (repo *examRepository) CountExams(ctx context.Context, uid int64) (int64, error) {
+	return repo.dao.CountExams(ctx, uid)
+}
+
+func (repo *examRepository) ClaimAnswer(ctx context.Context, examId, qid int64, claimTime, staleBefore time.Time) error {
+	return repo.dao.ClaimAnswer(ctx, examId, qid, claimTime.UnixMilli(), staleBefore.UnixMilli())
+}
+
+func (repo *examRepository) ReleaseAnswer(ctx context.Context, examId, qid int64, claimTime time.Time) error {
+	return repo.dao.ReleaseAnswer(ctx, examId, qid, claimTime.UnixMilli())
+}
+
+func (repo *examRepository) SaveAnswer(ctx context.Context, examId int64, question domain.ExamQuestion, claimTime time.Time) error {
+	return repo.dao.SaveAnswer(ctx, repo.toQuestionEntity(examId, question), claimTime.UnixMilli(),
+		domain.ExamStatusInProgress.ToUint8())
+}
+
+func (repo *examRepository) Finish(ctx context.Context, exam domain.Exam) error {
+	return repo.dao.Finish(ctx, repo.toEntity(exam), domain.ExamStatusInProgress.ToUint8())
+}
+
+func (repo *examRepository) toEntity(e domain.Exam) dao.Exam {
+	return dao.Exam{
+		Id:         e.Id,
+		Uid:        e.Uid,
+		Qsid:       e.Qsid,
+		Status:     e.Status.ToUint8(),
+		Deadline:   e.Deadline,
+		FinishTime: e.FinishTime,
+		Level:      e.Level.ToUint8(),
+		WeakLabels: sqlx.JsonColumn[[]string]{
+			Val:   e.WeakLabels,
+			Valid: len(e.WeakLabels) > 0,
+		},
+	}
+}
+
+func (repo *examRepository) toDomain(e dao.Exam) domain.Exam {
+	return domain.Exam{
+		Id:         e.Id,
+		Uid:        e.Uid,
+		Qsid:       e.Qsid,
+		Status:     domain.ExamStatus(e.Status),
+		Deadline:   e.Deadline,
+		FinishTime: e.FinishTime,
+		Level:      domain.Result(e.Level),
+		WeakLabels: e.WeakLabels.Val,
+		Ctime:      e.Ctime,
+	}
+}
+
+func (repo *examRepository) toQuestionEntity(examId int64, q domain.ExamQuestion) dao.ExamQuestion {
+	return dao.ExamQuestion{
+		ExamId: examId,
+		Qid:    q.Qid,
+		Title:  q.Title,
+		Labels: sqlx.JsonColumn[[]string]{
+			Val:   q.Labels,
+			Valid: len(q.Labels) > 0,
+		},
+		Input:     q.Input,
+		Answered:  q.Answered,
+		Result:    q.Result.ToUint8(),
+		Tid:       q.Tid,
+		ClaimTime: q.ClaimTime,
+	}
+}
+
+func (repo *examRepository) toQuestionDomain(q dao.ExamQuestion) domain.ExamQuestion {
+	return domain.ExamQuestion{
+		Qid:       q.Qid,
+		Title:     q.Title,
+		Labels:    q.Labels.Val,
+		Input:     q.Input,
+		Answered:  q.Answered,
+		Result:    domain.Result(q.Result),
+		Tid:       q.Tid,
+		ClaimTime: q.ClaimTime,
+	}
+}
@@ -19,43 +19,142 @@ import (
 	"errors"
 
 	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
 )
 
+var ErrRecordNotFound = dao.ErrRecordNotFound
+
 type ExamineRepository interface {
 	SaveResult(ctx context.Context, uid, qid int64, result domain.ExamineResult) error
-	GetResultByUidAndQid(ctx context.Context, uid int64, qid int64) (domain.Result, error)
+	// GetResultByUidAndQid 没有测试过的时候返回 ResultFailed
+	GetResultByUidAndQid(ctx context.Context, uid int64, qid int64) (domain.ExamineResult, error)
 	GetResultsByIds(ctx context.Context, uid int64, ids []int64) ([]domain.ExamineResult, error)
+
+	// CreateRecord 记录一次异步测试
+	CreateRecord(ctx context.Context, uid, qid int64, result domain.ExamineResult) error
+	GetRecordByTid(ctx context.Context, uid int64, tid string) (domain.ExamineResult, error)
+	// UpdateResult 只有状态是 from 的时候才会更新
+	UpdateResult(ctx context.Context, uid, qid int64, result domain.ExamineResult, from domain.ExamineStatus) error
+	// UpdateStatus 只有状态是 from 的时候才会更新
+	UpdateStatus(ctx context.Context, uid int64, tid string, from, to domain.ExamineStatus, failReason string) error
+	// RenewPending 重新开始等待，只有状态是 from 并且更新时间还是 utime 的时候才会更新
+	RenewPending(ctx context.Context, uid int64, tid string, from domain.ExamineStatus, utime int64) error
+
+	// ListRecords 某道题目的测试记录，最新的在前面
+	ListRecords(ctx context.Context, uid, qid int64, offset, limit int) ([]domain.ExamineResult, error)
+	CountRecords(ctx context.Context, uid, qid int64) (int64, error)
+	// DailyAttempts 从 start 开始每天有结果的测试次数
+	DailyAttempts(ctx context.Context, uid, start int64) ([]domain.ExamineDailyAttempts, error)
+	// ResultDistribution 有结果的测试在每个评级上的次数
+	ResultDistribution(ctx context.Context, uid int64) ([]domain.ExamineResultCount, error)
+	// BestResults 每道题目的最好成绩
+	BestResults(ctx context.Context, uid int64) ([]domain.ExamineBestResult, error)
 }
 
-var _ ExamineRepository = &CachedExamineRepository{}
+// gradedStatuses 有结果的测试记录，早期的同步测试没有记录状态
+var gradedStatuses = []uint8{
+	domain.ExamineStatusUnknown.ToUint8(),
+	domain.ExamineStatusSucceeded.ToUint8(),
+}
 
-type CachedExamineRepository struct {
+var _ ExamineRepository = &examineRepository{}
+
+type examineRepository struct {
 	dao dao.ExamineDAO
 }
 
-func (repo *CachedExamineRepository) GetResultsByIds(ctx context.Context, uid int64, ids []int64) ([]domain.ExamineResult, error) {
+func (repo *examineRepository) GetResultsByIds(ctx context.Context, uid int64, ids []int64) ([]domain.ExamineResult, error) {
 	res, err := repo.dao.GetResultByUidAndQids(ctx, uid, ids)
 	return slice.Map(res, func(idx int, src dao.QuestionResult) domain.ExamineResult {
-		return domain.ExamineResult{
-			Qid:    src.Qid,
-			Result: domain.Result(src.Result),
-		}
+		return repo.resultToDomain(src)
 	}), err
 }
 
-func (repo *CachedExamineRepository) GetResultByUidAndQid(ctx context.Context, uid int64, qid int64) (domain.Result, error) {
+func (repo *examineRepository) GetResultByUidAndQid(ctx context.Context, uid int64, qid int64) (domain.ExamineResult, error) {
 	res, err := repo.dao.GetResultByUidAndQid(ctx, uid, qid)
 	if errors.Is(err, dao.ErrRecordNotFound) {
-		return domain.ResultFailed, nil
+		return domain.ExamineResult{Qid: qid, Result: domain.ResultFailed}, nil
 	}
-	return domain.Result(res.Result), err
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	return repo.resultToDomain(res), nil
 }
 
-func (repo *CachedExamineRepository) SaveResult(ctx context.Context, uid, qid int64, result domain.ExamineResult) error {
+func (repo *examineRepository) SaveResult(ctx context.Context, uid, qid int64, result domain.ExamineResult) error {
 	// 开始记录
-	err := repo.dao.SaveResult(ctx, dao.ExamineRecord{
+	return repo.dao.SaveResult(ctx, repo.toEntity(uid, qid, result))
+}
+
+func (repo *examineRepository) CreateRecord(ctx context.Context, uid, qid int64, result domain.ExamineResult) error {
+	return repo.dao.CreateRecord(ctx, repo.toEntity(uid, qid, result))
+}
+
+func (repo *examineRepository) GetRecordByTid(ctx context.Context, uid int64, tid string) (domain.ExamineResult, error) {
+	res, err := repo.dao.GetRecordByTid(ctx, uid, tid)
+	return repo.toDomain(res), err
+}
+
+func (repo *examineRepository) UpdateResult(ctx context.Context, uid, qid int64,
+	result domain.ExamineResult, from domain.ExamineStatus) error {
+	return repo.dao.UpdateResult(ctx, repo.toEntity(uid, qid, result), from.ToUint8())
+}
+
+func (repo *examineRepository) UpdateStatus(ctx context.Context, uid int64, tid string,
+	from, to domain.ExamineStatus, failReason string) error {
+	return repo.dao.UpdateStatus(ctx, uid, tid, from.ToUint8(), to.ToUint8(), failReason)
+}
+
+func (repo *examineRepository) RenewPending(ctx context.Context, uid int64, tid string,
+	from domain.ExamineStatus, utime int64) error {
+	return repo.dao.RenewPending(ctx, uid, tid, from.ToUint8(), domain.ExamineStatusPending.ToUint8(), utime)
+}
+
+func (repo *examineRepository) ListRecords(ctx context.Context, uid, qid int64, offset, limit int) ([]domain.ExamineResult, error) {
+	res, err := repo.dao.ListRecords(ctx, uid, qid, offset, limit)
+	return slice.Map(res, func(idx int, src dao.ExamineRecord) domain.ExamineResult {
+		return repo.toDomain(src)
+	}), err
+}
+
+func (repo *examineRepository) CountRecords(ctx context.Context, uid, qid int64) (int64, error) {
+	return repo.dao.CountRecords(ctx, uid, qid)
+}
+
+func (repo *examineRepository) DailyAttempts(ctx context.Context, uid, start int64) ([]domain.ExamineDailyAttempts, error) {
+	ctimes, err := repo.dao.AttemptCtimes(ctx, uid, start, gradedStatuses)
+	if err != nil {
+		return nil, err
+	}
+	return domain.CountDailyAttempts(ctimes), nil
+}
+
+func (repo *examineRepository) ResultDistribution(ctx context.Context, uid int64) ([]domain.ExamineResultCount, error) {
+	res, err := repo.dao.ResultDistribution(ctx, uid, gradedStatuses)
+	return slice.Map(res, func(idx int, src dao.ResultCount) domain.ExamineResultCount {
+		return domain.ExamineResultCount{
+			Result: domain.Result(src.Result),
+			Count:  src.Cnt,
+		}
+	}), err
+}
+
+func (repo *examineRepository) BestResults(ctx context.Context, uid int64) ([]domain.ExamineBestResult, error) {
+	res, err := repo.dao.BestResults(ctx, uid, gradedStatuses)
+	return slice.Map(res, func(idx int, src dao.BestResult) domain.ExamineBestResult {
+		return domain.ExamineBestResult{
+			Qid:      src.Qid,
+			Result:   domain.Result(src.Result),
+			Attempts: src.Attempts,
+			LastTime: src.LastTime,
+		}
+	}), err
+}
+
+func (repo *examineRepository) toEntity(uid, qid int64, result domain.ExamineResult) dao.ExamineRecord {
+	return dao.ExamineRecord{
 		Uid:       uid,
 		Qid:       qid,
 		Tid:       result.Tid,
@@ -63,10 +162,73 @@ func (repo *CachedExamineRepository) SaveResult(ctx context.Context, uid, qid in
 		RawResult: result.RawResult,
 		Tokens:    result.Tokens,
 		Amount:    result.Amount,
-	})
-	return err
+
+		CorrectnessScore: result.Scores.Correctness,
+		DepthScore:       result.Scores.Depth,
+		HighlightScore:   result.Scores.Highlights,
+		KeywordScore:     result.Scores.Keywords,
+		Suggestions: sqlx.JsonColumn[[]dao.ExamineSuggestion]{
+			Val: slice.Map(result.Suggestions, func(idx int, src domain.ExamineSuggestion) dao.ExamineSuggestion {
+				return dao.ExamineSuggestion{
+					Keyword: src.Keyword,
+					Content: src.Content,
+				}
+			}),
+			Valid: len(result.Suggestions) > 0,
+		},
+
+		Input:      result.Input,
+		Status:     result.Status.ToUint8(),
+		FailReason: result.FailReason,
+	}
+}
+
+func (repo *examineRepository) toDomain(record dao.ExamineRecord) domain.ExamineResult {
+	return domain.ExamineResult{
+		Qid:       record.Qid,
+		Result:    domain.Result(record.Result),
+		RawResult: record.RawResult,
+		Tokens:    record.Tokens,
+		Amount:    record.Amount,
+		Tid:       record.Tid,
+		Scores: domain.ExamineScores{
+			Correctness: record.CorrectnessScore,
+			Depth:       record.DepthScore,
+			Highlights:  record.HighlightScore,
+			Keywords:    record.KeywordScore,
+		},
+		Suggestions: repo.suggestionsToDomain(record.Suggestions.Val),
+		Input:       record.Input,
+		Status:      domain.ExamineStatus(record.Status),
+		FailReason:  record.FailReason,
+		Ctime:       record.Ctime,
+		Utime:       record.Utime,
+	}
+}
+
+func NewExamineRepository(dao dao.ExamineDAO) ExamineRepository {
+	return &examineRepository{dao: dao}
+}
+
+func (repo *examineRepository) resultToDomain(res dao.QuestionResult) domain.ExamineResult {
+	return domain.ExamineResult{
+		Qid:    res.Qid,
+		Result: domain.Result(res.Result),
+		Scores: domain.ExamineScores{
+			Correctness: res.CorrectnessScore,
+			Depth:       res.DepthScore,
+			Highlights:  res.HighlightScore,
+			Keywords:    res.KeywordScore,
+		},
+		Suggestions: repo.suggestionsToDomain(res.Suggestions.Val),
+	}
 }
 
-func NewCachedExamineRepository(dao dao.ExamineDAO) ExamineRepository {
-	return &CachedExamineRepository{dao: dao}
+func (repo *examineRepository) suggestionsToDomain(sugs []dao.ExamineSuggestion) []domain.ExamineSuggestion {
+	return slice.Map(sugs, func(idx int, src dao.ExamineSuggestion) domain.ExamineSuggestion {
+		return domain.ExamineSuggestion{
+			Keyword: src.Keyword,
+			Content: src.Content,
+		}
+	})
 }
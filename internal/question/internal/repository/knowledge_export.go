@@ -0,0 +1,97 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
+)
+
+type KnowledgeExportRepository interface {
+	Create(ctx context.Context, run domain.KnowledgeExportRun) (int64, error)
+	Finish(ctx context.Context, run domain.KnowledgeExportRun) error
+	// LatestSucceeded 没有成功导出过的时候返回一个空的记录
+	LatestSucceeded(ctx context.Context, format string) (domain.KnowledgeExportRun, error)
+}
+
+var _ KnowledgeExportRepository = &knowledgeExportRepository{}
+
+type knowledgeExportRepository struct {
+	dao dao.KnowledgeExportDAO
+}
+
+func NewKnowledgeExportRepository(dao dao.KnowledgeExportDAO) KnowledgeExportRepository {
+	return &knowledgeExportRepository{dao: dao}
+}
+
+func (repo *knowledgeExportRepository) Create(ctx context.Context, run domain.KnowledgeExportRun) (int64, error) {
+	return repo.dao.Create(ctx, repo.toEntity(run))
+}
+
+func (repo *knowledgeExportRepository) Finish(ctx context.Context, run domain.KnowledgeExportRun) error {
+	return repo.dao.Finish(ctx, repo.toEntity(run))
+}
+
+func (repo *knowledgeExportRepository) LatestSucceeded(ctx context.Context, format string) (domain.KnowledgeExportRun, error) {
+	res, err := repo.dao.LatestByStatus(ctx, format, domain.KnowledgeExportStatusSucceeded.ToUint8())
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		return domain.KnowledgeExportRun{Format: format}, nil
+	}
+	return repo.toDomain(res), err
+}
+
+func (repo *knowledgeExportRepository) toEntity(run domain.KnowledgeExportRun) dao.KnowledgeExportRun {
+	return dao.KnowledgeExportRun{
+		Id:          run.Id,
+		Format:      run.Format,
+		Incremental: run.Incremental,
+		Status:      run.Status.ToUint8(),
+		Since:       repo.toMilli(run.Since),
+		Watermark:   repo.toMilli(run.Watermark),
+		File:        run.File,
+		Cnt:         run.Count,
+		ErrMsg:      run.ErrMsg,
+		StartTime:   repo.toMilli(run.StartTime),
+		EndTime:     repo.toMilli(run.EndTime),
+	}
+}
+
+func (repo *knowledgeExportRepository) toDomain(run dao.KnowledgeExportRun) domain.KnowledgeExportRun {
+	return domain.KnowledgeExportRun{
+		Id:          run.Id,
+		Format:      run.Format,
+		Incremental: run.Incremental,
+		Status:      domain.KnowledgeExportStatus(run.Status),
+		Since:       time.UnixMilli(run.Since),
+		Watermark:   time.UnixMilli(run.Watermark),
+		File:        run.File,
+		Count:       run.Cnt,
+		ErrMsg:      run.ErrMsg,
+		StartTime:   time.UnixMilli(run.StartTime),
+		EndTime:     time.UnixMilli(run.EndTime),
+	}
+}
+
+// toMilli 零值的 time.Time 转换成 0，而不是一个负数
+func (repo *knowledgeExportRepository) toMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
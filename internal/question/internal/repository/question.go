@@ -16,20 +16,28 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/ecodeclub/ekit/sqlx"
 
 	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/cache"
 	"github.com/gotomicro/ego/core/elog"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
 )
 
 type Repository interface {
-	PubList(ctx context.Context, offset int, limit int, biz string) ([]domain.Question, error)
+	// PubList filter 为空的时候不按照标签筛选
+	PubList(ctx context.Context, offset int, limit int, biz string, filter label.Filter) ([]domain.Question, error)
+	// PubLabelCounts 返回的 LabelFacet 里面没有标签名字
+	PubLabelCounts(ctx context.Context, biz string) ([]label.Facet, error)
+	// SyncLabels 覆盖线上库题目关联的标签 ID
+	SyncLabels(ctx context.Context, qid int64, labelIds []int64) error
 	// Sync 保存到制作库，而后同步到线上库
 	Sync(ctx context.Context, que *domain.Question) (int64, error)
 	List(ctx context.Context, offset int, limit int) ([]domain.Question, error)
@@ -40,35 +48,138 @@ type Repository interface {
 	Delete(ctx context.Context, qid int64) error
 
 	GetById(ctx context.Context, qid int64) (domain.Question, error)
+	// GetByTitles 只返回基础信息，不包括答案
+	GetByTitles(ctx context.Context, biz string, titles []string) ([]domain.Question, error)
 	GetPubByID(ctx context.Context, qid int64) (domain.Question, error)
+	// GetPubByIDs 不包括答案，顺序和 ids 一致，不存在的题目会被跳过
 	GetPubByIDs(ctx context.Context, ids []int64) ([]domain.Question, error)
+	// GetPubDetailByIDs 包括答案在内，顺序和 ids 一致，不存在的题目会被跳过
+	GetPubDetailByIDs(ctx context.Context, ids []int64) ([]domain.Question, error)
+	PubListByUtime(ctx context.Context, biz string, utime time.Time, id int64, end time.Time, limit int) ([]domain.Question, error)
 }
 
+const (
+	// loadTimeout 缓存没有命中的时候，回源查询数据库和回写缓存的超时时间
+	loadTimeout = time.Second * 3
+	// delayedDelTimeout 正在回源的请求最多持续 loadTimeout，
+	// 在这之后再删除一次缓存，避免它把删除缓存之前读到的旧数据写回去
+	delayedDelTimeout = loadTimeout + time.Second
+)
+
 // CachedRepository 支持缓存的 repository 实现
-// 虽然在 Save 的时候，理论上也需要更新缓存，但是没有必要
+// 线上库的题目详情会被缓存，Sync、Update 和 Delete 之后会删除对应的缓存
 type CachedRepository struct {
-	dao    dao.QuestionDAO
-	cache  cache.QuestionCache
+	dao   dao.QuestionDAO
+	cache cache.QuestionCache
+	// sf 避免缓存失效的时候大量请求同时查询数据库
+	sf     singleflight.Group
 	logger *elog.Component
 }
 
 func (c *CachedRepository) GetPubByIDs(ctx context.Context, qids []int64) ([]domain.Question, error) {
-	data, err := c.dao.GetPubByIDs(ctx, qids)
-	return slice.Map(data, func(idx int, src dao.PublishQuestion) domain.Question {
+	qs, err := c.getPubDetails(ctx, qids)
+	for i := range qs {
+		qs[i].Answer = domain.Answer{}
+	}
+	return qs, err
+}
+
+func (c *CachedRepository) GetPubDetailByIDs(ctx context.Context, ids []int64) ([]domain.Question, error) {
+	return c.getPubDetails(ctx, ids)
+}
+
+// getPubDetails 先查缓存，没有命中的题目一次性从数据库里面查出来，再回写缓存
+func (c *CachedRepository) getPubDetails(ctx context.Context, ids []int64) ([]domain.Question, error) {
+	hits, err := c.cache.GetPubQuestions(ctx, ids)
+	if err != nil {
+		c.logger.Error("批量读取题目缓存失败", elog.FieldErr(err))
+		hits = make(map[int64]domain.Question, len(ids))
+	}
+	misses := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := hits[id]; !ok {
+			misses = append(misses, id)
+		}
+	}
+	if len(misses) > 0 {
+		qs, err := c.loadPubDetails(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		for _, que := range qs {
+			hits[que.Id] = que
+		}
+		err = c.cache.SetPubQuestions(ctx, qs...)
+		if err != nil {
+			c.logger.Error("回写题目缓存失败", elog.FieldErr(err))
+		}
+	}
+	res := make([]domain.Question, 0, len(ids))
+	for _, id := range ids {
+		que, ok := hits[id]
+		if !ok {
+			continue
+		}
+		res = append(res, que)
+		// ids 里面有重复的时候只返回一次
+		delete(hits, id)
+	}
+	return res, nil
+}
+
+func (c *CachedRepository) loadPubDetails(ctx context.Context, ids []int64) ([]domain.Question, error) {
+	qs, err := c.dao.GetPubByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	pubEles, err := c.dao.GetPubAnswerElementsByQids(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	eleMap := make(map[int64][]dao.AnswerElement, len(qs))
+	for _, ele := range pubEles {
+		eleMap[ele.Qid] = append(eleMap[ele.Qid], dao.AnswerElement(ele))
+	}
+	return slice.Map(qs, func(idx int, src dao.PublishQuestion) domain.Question {
+		return c.toDomainWithAnswer(dao.Question(src), eleMap[src.Id])
+	}), nil
+}
+
+func (c *CachedRepository) PubListByUtime(ctx context.Context, biz string,
+	utime time.Time, id int64, end time.Time, limit int) ([]domain.Question, error) {
+	qs, err := c.dao.PubListByUtime(ctx, biz, utime.UnixMilli(), id, end.UnixMilli(), limit)
+	return slice.Map(qs, func(idx int, src dao.PublishQuestion) domain.Question {
 		return c.toDomain(dao.Question(src))
 	}), err
 }
 
 func (c *CachedRepository) GetPubByID(ctx context.Context, qid int64) (domain.Question, error) {
-	// 可以缓存
-	data, pubEles, err := c.dao.GetPubByID(ctx, qid)
+	que, err := c.cache.GetPubQuestion(ctx, qid)
+	if err == nil {
+		return que, nil
+	}
+	val, err, _ := c.sf.Do(c.pubKey(qid), func() (any, error) {
+		// 结果是共享的，所以不能因为第一个请求取消了就失败
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+		data, pubEles, err := c.dao.GetPubByID(ctx, qid)
+		if err != nil {
+			return domain.Question{}, err
+		}
+		eles := slice.Map(pubEles, func(idx int, src dao.PublishAnswerElement) dao.AnswerElement {
+			return dao.AnswerElement(src)
+		})
+		res := c.toDomainWithAnswer(dao.Question(data), eles)
+		err = c.cache.SetPubQuestions(ctx, res)
+		if err != nil {
+			c.logger.Error("回写题目缓存失败", elog.FieldErr(err), elog.Int64("qid", qid))
+		}
+		return res, nil
+	})
 	if err != nil {
 		return domain.Question{}, err
 	}
-	eles := slice.Map(pubEles, func(idx int, src dao.PublishAnswerElement) dao.AnswerElement {
-		return dao.AnswerElement(src)
-	})
-	return c.toDomainWithAnswer(dao.Question(data), eles), nil
+	return val.(domain.Question), nil
 }
 
 func (c *CachedRepository) GetById(ctx context.Context, qid int64) (domain.Question, error) {
@@ -79,13 +190,36 @@ func (c *CachedRepository) GetById(ctx context.Context, qid int64) (domain.Quest
 	return c.toDomainWithAnswer(data, eles), nil
 }
 
+func (c *CachedRepository) GetByTitles(ctx context.Context, biz string, titles []string) ([]domain.Question, error) {
+	qs, err := c.dao.GetByTitles(ctx, biz, titles)
+	return slice.Map(qs, func(idx int, src dao.Question) domain.Question {
+		return c.toDomain(src)
+	}), err
+}
+
 func (c *CachedRepository) Delete(ctx context.Context, qid int64) error {
-	return c.dao.Delete(ctx, qid)
+	// 删除之后关联关系也没了，所以要提前查询
+	setIds, err := c.dao.QuestionSetIDsByQid(ctx, qid)
+	if err != nil {
+		return err
+	}
+	err = c.dao.Delete(ctx, qid)
+	if err != nil {
+		return err
+	}
+	c.delCache(ctx, qid, setIds)
+	return nil
 }
 
 func (c *CachedRepository) Update(ctx context.Context, question *domain.Question) error {
 	q, eles := c.toEntity(question)
-	return c.dao.Update(ctx, q, eles)
+	err := c.dao.Update(ctx, q, eles)
+	if err != nil {
+		return err
+	}
+	// 题集详情里面的题目来自制作库
+	c.invalidate(ctx, question.Id)
+	return nil
 }
 
 func (c *CachedRepository) Create(ctx context.Context, question *domain.Question) (int64, error) {
@@ -94,9 +228,50 @@ func (c *CachedRepository) Create(ctx context.Context, question *domain.Question
 }
 
 func (c *CachedRepository) Sync(ctx context.Context, que *domain.Question) (int64, error) {
-	// 理论上来说要更新缓存，但是我懒得写了
 	q, eles := c.toEntity(que)
-	return c.dao.Sync(ctx, q, eles)
+	id, err := c.dao.Sync(ctx, q, eles)
+	if err != nil {
+		return 0, err
+	}
+	c.invalidate(ctx, id)
+	return id, nil
+}
+
+// invalidate 删除题目以及引用了它的题集的缓存
+func (c *CachedRepository) invalidate(ctx context.Context, qid int64) {
+	setIds, err := c.dao.QuestionSetIDsByQid(ctx, qid)
+	if err != nil {
+		c.logger.Error("查询题目关联的题集失败", elog.FieldErr(err), elog.Int64("qid", qid))
+	}
+	c.delCache(ctx, qid, setIds)
+}
+
+// delCache 删除缓存失败只能等缓存过期
+func (c *CachedRepository) delCache(ctx context.Context, qid int64, setIds []int64) {
+	// Forget 只是让后来的请求重新查询数据库，不再等待正在进行中的查询，
+	// 正在进行中的查询可能读到的是旧数据，并且依旧会回写缓存，所以还要延迟删除一次
+	c.sf.Forget(c.pubKey(qid))
+	c.doDelCache(ctx, qid, setIds)
+	time.AfterFunc(delayedDelTimeout, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		c.doDelCache(ctx, qid, setIds)
+	})
+}
+
+func (c *CachedRepository) doDelCache(ctx context.Context, qid int64, setIds []int64) {
+	err := c.cache.DelPubQuestion(ctx, qid)
+	if err != nil {
+		c.logger.Error("删除题目缓存失败", elog.FieldErr(err), elog.Int64("qid", qid))
+	}
+	err = c.cache.DelQuestionSets(ctx, setIds...)
+	if err != nil {
+		c.logger.Error("删除题集缓存失败", elog.FieldErr(err), elog.Int64("qid", qid))
+	}
+}
+
+func (c *CachedRepository) pubKey(qid int64) string {
+	return fmt.Sprintf("pub:%d", qid)
 }
 
 func (c *CachedRepository) List(ctx context.Context, offset int, limit int) ([]domain.Question, error) {
@@ -110,14 +285,33 @@ func (c *CachedRepository) Total(ctx context.Context) (int64, error) {
 	return c.dao.Count(ctx)
 }
 
-func (c *CachedRepository) PubList(ctx context.Context, offset int, limit int, biz string) ([]domain.Question, error) {
-	// TODO 缓存第一页
-	qs, err := c.dao.PubList(ctx, offset, limit, biz)
+func (c *CachedRepository) PubList(ctx context.Context, offset int, limit int, biz string, filter label.Filter) ([]domain.Question, error) {
+	var (
+		qs  []dao.PublishQuestion
+		err error
+	)
+	if filter.IsEmpty() {
+		// TODO 缓存第一页
+		qs, err = c.dao.PubList(ctx, offset, limit, biz)
+	} else {
+		qs, err = c.dao.PubListByLabels(ctx, offset, limit, biz, filter.DistinctLabelIds(), filter.MatchAll())
+	}
 	return slice.Map(qs, func(idx int, src dao.PublishQuestion) domain.Question {
 		return c.toDomain(dao.Question(src))
 	}), err
 }
 
+func (c *CachedRepository) PubLabelCounts(ctx context.Context, biz string) ([]label.Facet, error) {
+	cnts, err := c.dao.PubLabelCounts(ctx, biz)
+	return slice.Map(cnts, func(idx int, src dao.LabelCount) label.Facet {
+		return label.Facet{LabelId: src.LabelId, Cnt: src.Cnt}
+	}), err
+}
+
+func (c *CachedRepository) SyncLabels(ctx context.Context, qid int64, labelIds []int64) error {
+	return c.dao.SyncLabels(ctx, qid, labelIds)
+}
+
 func (c *CachedRepository) toDomainWithAnswer(que dao.Question, eles []dao.AnswerElement) domain.Question {
 	res := c.toDomain(que)
 	for _, ele := range eles {
@@ -16,30 +16,53 @@ package repository
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository/cache"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
 	"github.com/gotomicro/ego/core/elog"
+	"golang.org/x/sync/singleflight"
 )
 
 type QuestionSetRepository interface {
 	Create(ctx context.Context, set domain.QuestionSet) (int64, error)
 	UpdateQuestions(ctx context.Context, set domain.QuestionSet) error
+	// GetByID 会缓存题集详情，UpdateQuestions 和 UpdateNonZero 之后删除缓存
 	GetByID(ctx context.Context, id int64) (domain.QuestionSet, error)
 	Total(ctx context.Context) (int64, error)
 	List(ctx context.Context, offset int, limit int) ([]domain.QuestionSet, error)
 	UpdateNonZero(ctx context.Context, set domain.QuestionSet) error
 	GetByIDs(ctx context.Context, ids []int64) ([]domain.QuestionSet, error)
-	ListByBiz(ctx context.Context, offset, limit int, biz string) ([]domain.QuestionSet, error)
+	// ListByBiz filter 为空的时候不按照标签筛选
+	ListByBiz(ctx context.Context, offset, limit int, biz string, filter label.Filter) ([]domain.QuestionSet, error)
+	// LabelCounts 返回的 LabelFacet 里面没有标签名字
+	LabelCounts(ctx context.Context, biz string) ([]label.Facet, error)
 	GetByBiz(ctx context.Context, biz string, bizId int64) (domain.QuestionSet, error)
+
+	// UpdateStatus 回收站里面的题集不会被修改
+	UpdateStatus(ctx context.Context, id int64, status domain.QuestionSetStatus) error
+	// Delete 放入回收站
+	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	TrashList(ctx context.Context, offset int, limit int) ([]domain.QuestionSet, error)
+	TrashTotal(ctx context.Context) (int64, error)
+	// ListDeletedBefore 在 t 之前放入回收站的题集
+	ListDeletedBefore(ctx context.Context, t time.Time, limit int) ([]domain.QuestionSet, error)
+	// Purge 彻底删除回收站里面的题集
+	Purge(ctx context.Context, id int64) error
 }
 
 var _ QuestionSetRepository = &questionSetRepository{}
 
 type questionSetRepository struct {
-	dao    dao.QuestionSetDAO
+	dao   dao.QuestionSetDAO
+	cache cache.QuestionCache
+	// sf 避免缓存失效的时候大量请求同时查询数据库
+	sf     singleflight.Group
 	logger *elog.Component
 }
 
@@ -60,12 +83,21 @@ func (q *questionSetRepository) GetByBiz(ctx context.Context, biz string, bizId
 		BizId:       set.BizId,
 		Description: set.Description,
 		Questions:   questions,
+		Status:      domain.QuestionSetStatus(set.Status),
 		Utime:       time.UnixMilli(set.Utime),
 	}, nil
 }
 
-func (q *questionSetRepository) ListByBiz(ctx context.Context, offset, limit int, biz string) ([]domain.QuestionSet, error) {
-	qs, err := q.dao.ListByBiz(ctx, offset, limit, biz)
+func (q *questionSetRepository) ListByBiz(ctx context.Context, offset, limit int, biz string, filter label.Filter) ([]domain.QuestionSet, error) {
+	var (
+		qs  []dao.QuestionSet
+		err error
+	)
+	if filter.IsEmpty() {
+		qs, err = q.dao.ListByBiz(ctx, offset, limit, biz)
+	} else {
+		qs, err = q.dao.ListByLabels(ctx, offset, limit, biz, filter.DistinctLabelIds(), filter.MatchAll())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +106,13 @@ func (q *questionSetRepository) ListByBiz(ctx context.Context, offset, limit int
 	}), err
 }
 
+func (q *questionSetRepository) LabelCounts(ctx context.Context, biz string) ([]label.Facet, error) {
+	cnts, err := q.dao.LabelCounts(ctx, biz)
+	return slice.Map(cnts, func(idx int, src dao.LabelCount) label.Facet {
+		return label.Facet{LabelId: src.LabelId, Cnt: src.Cnt}
+	}), err
+}
+
 func (q *questionSetRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.QuestionSet, error) {
 	qs, err := q.dao.GetByIDs(ctx, ids)
 	return slice.Map(qs, func(idx int, src dao.QuestionSet) domain.QuestionSet {
@@ -82,7 +121,66 @@ func (q *questionSetRepository) GetByIDs(ctx context.Context, ids []int64) ([]do
 }
 
 func (q *questionSetRepository) UpdateNonZero(ctx context.Context, set domain.QuestionSet) error {
-	return q.dao.UpdateNonZero(ctx, q.toEntityQuestionSet(set))
+	err := q.dao.UpdateNonZero(ctx, q.toEntityQuestionSet(set))
+	if err != nil {
+		return err
+	}
+	q.delCache(ctx, set.Id)
+	return nil
+}
+
+func (q *questionSetRepository) UpdateStatus(ctx context.Context, id int64, status domain.QuestionSetStatus) error {
+	err := q.dao.UpdateStatus(ctx, id, status.ToUint8())
+	if err != nil {
+		return err
+	}
+	q.delCache(ctx, id)
+	return nil
+}
+
+func (q *questionSetRepository) Delete(ctx context.Context, id int64) error {
+	err := q.dao.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	q.delCache(ctx, id)
+	return nil
+}
+
+func (q *questionSetRepository) Restore(ctx context.Context, id int64) error {
+	err := q.dao.Restore(ctx, id)
+	if err != nil {
+		return err
+	}
+	q.delCache(ctx, id)
+	return nil
+}
+
+func (q *questionSetRepository) TrashList(ctx context.Context, offset int, limit int) ([]domain.QuestionSet, error) {
+	qs, err := q.dao.TrashList(ctx, offset, limit)
+	return slice.Map(qs, func(idx int, src dao.QuestionSet) domain.QuestionSet {
+		return q.toDomainQuestionSet(src)
+	}), err
+}
+
+func (q *questionSetRepository) TrashTotal(ctx context.Context) (int64, error) {
+	return q.dao.TrashCount(ctx)
+}
+
+func (q *questionSetRepository) ListDeletedBefore(ctx context.Context, t time.Time, limit int) ([]domain.QuestionSet, error) {
+	qs, err := q.dao.ListDeletedBefore(ctx, t.UnixMilli(), limit)
+	return slice.Map(qs, func(idx int, src dao.QuestionSet) domain.QuestionSet {
+		return q.toDomainQuestionSet(src)
+	}), err
+}
+
+func (q *questionSetRepository) Purge(ctx context.Context, id int64) error {
+	err := q.dao.Purge(ctx, id)
+	if err != nil {
+		return err
+	}
+	q.delCache(ctx, id)
+	return nil
 }
 
 func (q *questionSetRepository) Create(ctx context.Context, set domain.QuestionSet) (int64, error) {
@@ -97,6 +195,7 @@ func (q *questionSetRepository) toEntityQuestionSet(d domain.QuestionSet) dao.Qu
 		Biz:         d.Biz,
 		BizId:       d.BizId,
 		Description: d.Description,
+		Status:      d.Status.ToUint8(),
 		Utime:       d.Utime.UnixMilli(),
 	}
 }
@@ -106,7 +205,31 @@ func (q *questionSetRepository) UpdateQuestions(ctx context.Context, set domain.
 	for i := range set.Questions {
 		qids[i] = set.Questions[i].Id
 	}
-	return q.dao.UpdateQuestionsByID(ctx, set.Id, qids)
+	err := q.dao.UpdateQuestionsByID(ctx, set.Id, qids)
+	if err != nil {
+		return err
+	}
+	q.delCache(ctx, set.Id)
+	return nil
+}
+
+// delCache 删除缓存失败只能等缓存过期，
+// 和 CachedRepository.delCache 一样，正在进行中的查询依旧会回写缓存，所以要延迟删除一次
+func (q *questionSetRepository) delCache(ctx context.Context, id int64) {
+	q.sf.Forget(strconv.FormatInt(id, 10))
+	q.doDelCache(ctx, id)
+	time.AfterFunc(delayedDelTimeout, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		q.doDelCache(ctx, id)
+	})
+}
+
+func (q *questionSetRepository) doDelCache(ctx context.Context, id int64) {
+	err := q.cache.DelQuestionSets(ctx, id)
+	if err != nil {
+		q.logger.Error("删除题集缓存失败", elog.FieldErr(err), elog.Int64("qsid", id))
+	}
 }
 
 func (q *questionSetRepository) getDomainQuestions(ctx context.Context, id int64) ([]domain.Question, error) {
@@ -124,6 +247,7 @@ func (q *questionSetRepository) toDomainQuestion(que dao.Question) domain.Questi
 		Id:      que.Id,
 		Uid:     que.Uid,
 		Title:   que.Title,
+		Labels:  que.Labels.Val,
 		Content: que.Content,
 		Biz:     que.Biz,
 		BizId:   que.BizId,
@@ -133,6 +257,31 @@ func (q *questionSetRepository) toDomainQuestion(que dao.Question) domain.Questi
 }
 
 func (q *questionSetRepository) GetByID(ctx context.Context, id int64) (domain.QuestionSet, error) {
+	set, err := q.cache.GetQuestionSet(ctx, id)
+	if err == nil {
+		return set, nil
+	}
+	val, err, _ := q.sf.Do(strconv.FormatInt(id, 10), func() (any, error) {
+		// 结果是共享的，所以不能因为第一个请求取消了就失败
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+		res, err := q.getByID(ctx, id)
+		if err != nil {
+			return domain.QuestionSet{}, err
+		}
+		err = q.cache.SetQuestionSet(ctx, res)
+		if err != nil {
+			q.logger.Error("回写题集缓存失败", elog.FieldErr(err), elog.Int64("qsid", id))
+		}
+		return res, nil
+	})
+	if err != nil {
+		return domain.QuestionSet{}, err
+	}
+	return val.(domain.QuestionSet), nil
+}
+
+func (q *questionSetRepository) getByID(ctx context.Context, id int64) (domain.QuestionSet, error) {
 	set, err := q.dao.GetByID(ctx, id)
 	if err != nil {
 		return domain.QuestionSet{}, err
@@ -150,6 +299,7 @@ func (q *questionSetRepository) GetByID(ctx context.Context, id int64) (domain.Q
 		BizId:       set.BizId,
 		Description: set.Description,
 		Questions:   questions,
+		Status:      domain.QuestionSetStatus(set.Status),
 		Utime:       time.UnixMilli(set.Utime),
 	}, nil
 }
@@ -176,13 +326,15 @@ func (q *questionSetRepository) toDomainQuestionSet(qs dao.QuestionSet) domain.Q
 		Biz:         qs.Biz,
 		BizId:       qs.BizId,
 		Description: qs.Description,
+		Status:      domain.QuestionSetStatus(qs.Status),
 		// Questions:   q.getDomainQuestions(),
 		Utime: time.UnixMilli(qs.Utime),
 	}
 }
 
-func NewQuestionSetRepository(d dao.QuestionSetDAO) QuestionSetRepository {
+func NewQuestionSetRepository(d dao.QuestionSetDAO, c cache.QuestionCache) QuestionSetRepository {
 	return &questionSetRepository{
 		dao:    d,
+		cache:  c,
 		logger: elog.DefaultLogger}
 }
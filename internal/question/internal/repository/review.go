@@ -0,0 +1,115 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
+)
+
+type ReviewRepository interface {
+	// GetReview 没有复习计划的时候返回一个空的计划
+	GetReview(ctx context.Context, uid, qid int64) (domain.Review, error)
+	SaveReview(ctx context.Context, review domain.Review) error
+	// BatchCreateReviews 已经有复习计划的题目会被忽略
+	BatchCreateReviews(ctx context.Context, reviews []domain.Review) error
+	// UnscheduledResults 有测试结果但是还没有复习计划的题目，ExamineResult 里面只有 Qid、Result 和 Ctime
+	UnscheduledResults(ctx context.Context, uid int64) ([]domain.ExamineResult, error)
+	CountReviewed(ctx context.Context, uid, start int64) (int64, error)
+	ListDue(ctx context.Context, uid, end int64, qids []int64, limit int) ([]domain.Review, error)
+}
+
+var _ ReviewRepository = &reviewRepository{}
+
+type reviewRepository struct {
+	dao dao.ReviewDAO
+}
+
+func NewReviewRepository(dao dao.ReviewDAO) ReviewRepository {
+	return &reviewRepository{dao: dao}
+}
+
+func (repo *reviewRepository) GetReview(ctx context.Context, uid, qid int64) (domain.Review, error) {
+	res, err := repo.dao.GetReview(ctx, uid, qid)
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		return domain.Review{Uid: uid, Qid: qid}, nil
+	}
+	return repo.toDomain(res), err
+}
+
+func (repo *reviewRepository) SaveReview(ctx context.Context, review domain.Review) error {
+	return repo.dao.SaveReview(ctx, repo.toEntity(review))
+}
+
+func (repo *reviewRepository) BatchCreateReviews(ctx context.Context, reviews []domain.Review) error {
+	return repo.dao.BatchCreateReviews(ctx, slice.Map(reviews, func(idx int, src domain.Review) dao.QuestionReview {
+		return repo.toEntity(src)
+	}))
+}
+
+func (repo *reviewRepository) UnscheduledResults(ctx context.Context, uid int64) ([]domain.ExamineResult, error) {
+	res, err := repo.dao.UnscheduledResults(ctx, uid)
+	return slice.Map(res, func(idx int, src dao.QuestionResult) domain.ExamineResult {
+		return domain.ExamineResult{
+			Qid:    src.Qid,
+			Result: domain.Result(src.Result),
+			// 最后一次测试的时间
+			Ctime: src.Utime,
+		}
+	}), err
+}
+
+func (repo *reviewRepository) CountReviewed(ctx context.Context, uid, start int64) (int64, error) {
+	return repo.dao.CountReviewed(ctx, uid, start)
+}
+
+func (repo *reviewRepository) ListDue(ctx context.Context, uid, end int64, qids []int64, limit int) ([]domain.Review, error) {
+	res, err := repo.dao.ListDue(ctx, uid, end, qids, limit)
+	return slice.Map(res, func(idx int, src dao.QuestionReview) domain.Review {
+		return repo.toDomain(src)
+	}), err
+}
+
+func (repo *reviewRepository) toEntity(r domain.Review) dao.QuestionReview {
+	return dao.QuestionReview{
+		Uid:         r.Uid,
+		Qid:         r.Qid,
+		Repetitions: r.Repetitions,
+		Interval:    r.Interval,
+		EaseFactor:  r.EaseFactor,
+		LastResult:  r.LastResult.ToUint8(),
+		LastReview:  r.LastReview,
+		ReviewedAt:  r.ReviewedAt,
+		NextReview:  r.NextReview,
+	}
+}
+
+func (repo *reviewRepository) toDomain(r dao.QuestionReview) domain.Review {
+	return domain.Review{
+		Uid:         r.Uid,
+		Qid:         r.Qid,
+		Repetitions: r.Repetitions,
+		Interval:    r.Interval,
+		EaseFactor:  r.EaseFactor,
+		LastResult:  domain.Result(r.LastResult),
+		LastReview:  r.LastReview,
+		ReviewedAt:  r.ReviewedAt,
+		NextReview:  r.NextReview,
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository/dao"
+)
+
+// RevisionRepository 版本是在保存题目的时候创建的，所以这里只有查询
+type RevisionRepository interface {
+	ListRevisions(ctx context.Context, qid int64, offset, limit int) ([]domain.QuestionRevision, error)
+	CountRevisions(ctx context.Context, qid int64) (int64, error)
+	GetRevision(ctx context.Context, qid, id int64) (domain.QuestionRevision, error)
+}
+
+var _ RevisionRepository = &revisionRepository{}
+
+type revisionRepository struct {
+	dao dao.RevisionDAO
+}
+
+func NewRevisionRepository(dao dao.RevisionDAO) RevisionRepository {
+	return &revisionRepository{dao: dao}
+}
+
+func (repo *revisionRepository) ListRevisions(ctx context.Context, qid int64, offset, limit int) ([]domain.QuestionRevision, error) {
+	res, err := repo.dao.ListRevisions(ctx, qid, offset, limit)
+	return slice.Map(res, func(idx int, src dao.QuestionRevision) domain.QuestionRevision {
+		return repo.toDomain(src)
+	}), err
+}
+
+func (repo *revisionRepository) CountRevisions(ctx context.Context, qid int64) (int64, error) {
+	return repo.dao.CountRevisions(ctx, qid)
+}
+
+func (repo *revisionRepository) GetRevision(ctx context.Context, qid, id int64) (domain.QuestionRevision, error) {
+	res, err := repo.dao.GetRevision(ctx, qid, id)
+	return repo.toDomain(res), err
+}
+
+func (repo *revisionRepository) toDomain(r dao.QuestionRevision) domain.QuestionRevision {
+	que := domain.Question{
+		Id:      r.Qid,
+		Uid:     r.Uid,
+		Title:   r.Title,
+		Content: r.Content,
+		Labels:  r.Labels.Val,
+		Biz:     r.Biz,
+		BizId:   r.BizId,
+		Status:  domain.QuestionStatus(r.Status),
+		Utime:   time.UnixMilli(r.Ctime),
+	}
+	for _, ele := range r.Answer.Val {
+		domainEle := domain.AnswerElement{
+			Content:   ele.Content,
+			Keywords:  ele.Keywords,
+			Shorthand: ele.Shorthand,
+			Highlight: ele.Highlight,
+			Guidance:  ele.Guidance,
+		}
+		switch ele.Type {
+		case dao.AnswerElementTypeAnalysis:
+			que.Answer.Analysis = domainEle
+		case dao.AnswerElementTypeBasic:
+			que.Answer.Basic = domainEle
+		case dao.AnswerElementTypeIntermedia:
+			que.Answer.Intermediate = domainEle
+		case dao.AnswerElementTypeAdvanced:
+			que.Answer.Advanced = domainEle
+		}
+	}
+	return domain.QuestionRevision{
+		Id:       r.Id,
+		Question: que,
+		Ctime:    time.UnixMilli(r.Ctime),
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ecodeclub/webook/internal/ai"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/lithammer/shortuuid/v4"
+)
+
+var (
+	ErrBudgetExceeded     = ai.ErrBudgetExceeded
+	ErrInvalidAnswerDraft = errors.New("无法解析 AI 生成的答案草稿")
+)
+
+const answerDraftBiz = "question_answer_draft"
+
+// AnswerDraftService 使用 AI 生成答案草稿，只生成不保存，由管理员修改之后再保存
+type AnswerDraftService interface {
+	// Generate 根据标题和内容生成四个答案元素，其余字段原样返回
+	Generate(ctx context.Context, uid int64, que domain.Question) (domain.Question, error)
+}
+
+type LLMAnswerDraftService struct {
+	aiSvc ai.LLMService
+}
+
+func NewLLMAnswerDraftService(aiSvc ai.LLMService) AnswerDraftService {
+	return &LLMAnswerDraftService{
+		aiSvc: aiSvc,
+	}
+}
+
+func (svc *LLMAnswerDraftService) Generate(ctx context.Context, uid int64, que domain.Question) (domain.Question, error) {
+	resp, err := svc.aiSvc.Invoke(ctx, ai.LLMRequest{
+		Uid:   uid,
+		Tid:   shortuuid.New(),
+		Biz:   answerDraftBiz,
+		Input: []string{que.Title, que.Content},
+	})
+	if err != nil {
+		return domain.Question{}, err
+	}
+	draft, err := svc.parse(resp.Answer)
+	if err != nil {
+		return domain.Question{}, err
+	}
+	que.Answer.Analysis = draft.Analysis.merge(que.Answer.Analysis)
+	que.Answer.Basic = draft.Basic.merge(que.Answer.Basic)
+	que.Answer.Intermediate = draft.Intermediate.merge(que.Answer.Intermediate)
+	que.Answer.Advanced = draft.Advanced.merge(que.Answer.Advanced)
+	return que, nil
+}
+
+// parse 提示词要求大模型只返回 JSON，但是大模型经常会包在 markdown 的代码块里面
+func (svc *LLMAnswerDraftService) parse(answer string) (answerDraft, error) {
+	start := strings.Index(answer, "{")
+	end := strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return answerDraft{}, fmt.Errorf("%w, 没有找到 JSON", ErrInvalidAnswerDraft)
+	}
+	var res answerDraft
+	err := json.Unmarshal([]byte(answer[start:end+1]), &res)
+	if err != nil {
+		return answerDraft{}, fmt.Errorf("%w, %w", ErrInvalidAnswerDraft, err)
+	}
+	return res, nil
+}
+
+// answerDraft 和提示词模板里面约定的 JSON 格式
+type answerDraft struct {
+	Analysis     answerElementDraft `json:"analysis"`
+	Basic        answerElementDraft `json:"basic"`
+	Intermediate answerElementDraft `json:"intermediate"`
+	Advanced     answerElementDraft `json:"advanced"`
+}
+
+type answerElementDraft struct {
+	Content   string `json:"content"`
+	Keywords  string `json:"keywords"`
+	Shorthand string `json:"shorthand"`
+	Highlight string `json:"highlight"`
+	Guidance  string `json:"guidance"`
+}
+
+// merge 保留原本的 Id，这样保存的时候是更新而不是新建
+func (d answerElementDraft) merge(ele domain.AnswerElement) domain.AnswerElement {
+	return domain.AnswerElement{
+		Id:        ele.Id,
+		Content:   d.Content,
+		Keywords:  d.Keywords,
+		Shorthand: d.Shorthand,
+		Highlight: d.Highlight,
+		Guidance:  d.Guidance,
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	// ErrExamFinished 已经交卷或者超时了
+	ErrExamFinished = errors.New("考试已经结束")
+	// ErrExamAnswered 每道题目只能回答一次，正在回答的题目也不能再次回答
+	ErrExamAnswered = errors.New("题目已经回答过了")
+)
+
+const (
+	// 没有指定考试时长的时候，每道题目 5 分钟，最长 3 个小时
+	defaultExamTimePerQuestion = 5 * time.Minute
+	maxExamDuration            = 3 * time.Hour
+	// 占住题目超过这个时间还没有保存回答，认为请求已经失败了，可以重新回答
+	examAnswerClaimTimeout = 3 * time.Minute
+)
+
+// ExamService 基于题集的模拟考试
+type ExamService interface {
+	// Start 开始考试，题集没有发表的时候返回 ErrQuestionSetNotPublished
+	// count 不大于 0 的时候使用题集里面的所有题目
+	// random 为 true 的时候随机选题，否则按照题集里面的顺序选
+	// duration 不大于 0 的时候按照题目数量计算
+	Start(ctx context.Context, uid, qsid int64, count int, random bool, duration time.Duration) (domain.Exam, error)
+	// Submit 回答一道题目，马上调用 AI 评价并且扣除积分，评价的结果交卷之后才能看
+	Submit(ctx context.Context, uid, id, qid int64, input string) error
+	// Finish 交卷，已经结束了的考试直接返回
+	Finish(ctx context.Context, uid, id int64) (domain.Exam, error)
+	// Detail 考试的详情，超过截止时间的考试会自动交卷，截止之前开始回答的题目会等到保存了回答再交卷
+	Detail(ctx context.Context, uid, id int64) (domain.Exam, error)
+	// List 考试记录，最新的在前面，不包括考试中的题目
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Exam, int64, error)
+}
+
+var _ ExamService = &examService{}
+
+type examService struct {
+	repo       repository.ExamRepository
+	setRepo    repository.QuestionSetRepository
+	queRepo    repository.Repository
+	examineSvc ExamineService
+	logger     *elog.Component
+}
+
+func NewExamService(repo repository.ExamRepository,
+	setRepo repository.QuestionSetRepository,
+	queRepo repository.Repository,
+	examineSvc ExamineService) ExamService {
+	return &examService{
+		repo:       repo,
+		setRepo:    setRepo,
+		queRepo:    queRepo,
+		examineSvc: examineSvc,
+		logger:     elog.DefaultLogger,
+	}
+}
+
+func (s *examService) Start(ctx context.Context, uid, qsid int64,
+	count int, random bool, duration time.Duration) (domain.Exam, error) {
+	set, err := s.setRepo.GetByID(ctx, qsid)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		return domain.Exam{}, fmt.Errorf("%w, %w", ErrQuestionSetNotPublished, err)
+	}
+	if err != nil {
+		return domain.Exam{}, err
+	}
+	// 制作库里面的题集不管状态，下线或者删除了的题集不能考试
+	if set.Status != domain.QuestionSetStatusPublished {
+		return domain.Exam{}, fmt.Errorf("%w, qsid %d", ErrQuestionSetNotPublished, qsid)
+	}
+	// 回答的时候只能测试线上库的题目，所以只使用已经发表的题目
+	questions, err := s.queRepo.GetPubByIDs(ctx, set.Qids())
+	if err != nil {
+		return domain.Exam{}, err
+	}
+	if len(questions) == 0 {
+		return domain.Exam{}, fmt.Errorf("题集 %d 没有发表了的题目", qsid)
+	}
+	if random {
+		rand.Shuffle(len(questions), func(i, j int) {
+			questions[i], questions[j] = questions[j], questions[i]
+		})
+	}
+	if count > 0 && count < len(questions) {
+		questions = questions[:count]
+	}
+	if duration <= 0 {
+		duration = time.Duration(len(questions)) * defaultExamTimePerQuestion
+	}
+	now := time.Now()
+	exam := domain.Exam{
+		Uid:    uid,
+		Qsid:   qsid,
+		Status: domain.ExamStatusInProgress,
+		Questions: slice.Map(questions, func(idx int, src domain.Question) domain.ExamQuestion {
+			return domain.ExamQuestion{
+				Qid:    src.Id,
+				Title:  src.Title,
+				Labels: src.Labels,
+			}
+		}),
+		Deadline: now.Add(min(duration, maxExamDuration)).UnixMilli(),
+		Ctime:    now.UnixMilli(),
+	}
+	exam.Id, err = s.repo.CreateExam(ctx, exam)
+	return exam, err
+}
+
+func (s *examService) Submit(ctx context.Context, uid, id, qid int64, input string) error {
+	exam, err := s.Detail(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	if exam.Status != domain.ExamStatusInProgress {
+		return ErrExamFinished
+	}
+	que, ok := exam.Question(qid)
+	if !ok {
+		return fmt.Errorf("考试 %d 里面没有题目 %d", id, qid)
+	}
+	if que.Answered {
+		return ErrExamAnswered
+	}
+	// 先占住题目再调用 AI，避免并发的请求重复调用 AI、重复扣积分
+	// 截止之前占住的题目，AI 评价完成的时候即使超过了截止时间也会保存回答
+	claimTime := time.Now()
+	if exam.Expired(claimTime) {
+		return ErrExamFinished
+	}
+	err = s.repo.ClaimAnswer(ctx, id, qid, claimTime, claimTime.Add(-examAnswerClaimTimeout))
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		return ErrExamAnswered
+	}
+	if err != nil {
+		return err
+	}
+	result, err := s.examineSvc.Examine(ctx, uid, qid, input)
+	if err != nil {
+		s.release(ctx, id, qid, claimTime)
+		return err
+	}
+	que.Input = input
+	que.Answered = true
+	que.Result = result.Result
+	que.Tid = result.Tid
+	err = s.repo.SaveAnswer(ctx, id, que, claimTime)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		// 别的请求交卷了，或者占用超时之后被别的请求抢走了
+		latest, err1 := s.repo.GetExam(ctx, uid, id)
+		if err1 == nil && latest.Status != domain.ExamStatusInProgress {
+			return ErrExamFinished
+		}
+		return ErrExamAnswered
+	}
+	return err
+}
+
+// release 回答失败之后释放题目，用户可以重新回答
+func (s *examService) release(ctx context.Context, id, qid int64, claimTime time.Time) {
+	err := s.repo.ReleaseAnswer(ctx, id, qid, claimTime)
+	if err != nil {
+		// 释放失败也没关系，占用超时之后可以重新回答
+		s.logger.Error("释放考试题目失败", elog.FieldErr(err),
+			elog.Int64("exam", id), elog.Int64("qid", qid))
+	}
+}
+
+func (s *examService) Finish(ctx context.Context, uid, id int64) (domain.Exam, error) {
+	exam, err := s.Detail(ctx, uid, id)
+	if err != nil || exam.Status != domain.ExamStatusInProgress {
+		return exam, err
+	}
+	now := time.Now()
+	if exam.Expired(now) {
+		// 还有题目在等待 AI 评价，保存之后会自动交卷
+		return exam, nil
+	}
+	return s.finish(ctx, exam, domain.ExamStatusFinished, now)
+}
+
+func (s *examService) Detail(ctx context.Context, uid, id int64) (domain.Exam, error) {
+	exam, err := s.repo.GetExam(ctx, uid, id)
+	if err != nil {
+		return domain.Exam{}, err
+	}
+	now := time.Now()
+	if exam.Expired(now) && !exam.Answering(now.Add(-examAnswerClaimTimeout)) {
+		// 没有定时任务，查询的时候发现超时了就自动交卷
+		// 截止之前开始回答的题目还在等待 AI 评价的话，等保存了回答再交卷
+		return s.finish(ctx, exam, domain.ExamStatusTimeout, time.UnixMilli(exam.Deadline))
+	}
+	return exam, nil
+}
+
+func (s *examService) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Exam, int64, error) {
+	var (
+		eg    errgroup.Group
+		exams []domain.Exam
+		total int64
+	)
+	eg.Go(func() error {
+		var err error
+		exams, err = s.repo.ListExams(ctx, uid, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		total, err = s.repo.CountExams(ctx, uid)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	for i, exam := range exams {
+		if !exam.Expired(now) {
+			continue
+		}
+		finished, err := s.Detail(ctx, uid, exam.Id)
+		if err != nil {
+			return nil, 0, err
+		}
+		finished.Questions = nil
+		exams[i] = finished
+	}
+	return exams, total, nil
+}
+
+func (s *examService) finish(ctx context.Context, exam domain.Exam,
+	status domain.ExamStatus, at time.Time) (domain.Exam, error) {
+	exam = exam.Finish(status, at)
+	err := s.repo.Finish(ctx, exam)
+	if err != nil {
+		// 可能别的请求已经交卷了，以数据库里面的为准
+		latest, err1 := s.repo.GetExam(ctx, exam.Uid, exam.Id)
+		if err1 != nil || latest.Status == domain.ExamStatusInProgress {
+			return domain.Exam{}, err
+		}
+		return latest, nil
+	}
+	return exam, nil
+}
@@ -16,33 +16,95 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/ecodeclub/ekit/retry"
 	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/webook/internal/ai"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/event"
 	"github.com/ecodeclub/webook/internal/question/internal/repository"
+	"github.com/gotomicro/ego/core/elog"
 	"github.com/lithammer/shortuuid/v4"
+	"golang.org/x/sync/errgroup"
 )
 
-var ErrInsufficientCredit = ai.ErrInsufficientCredit
+var (
+	ErrInsufficientCredit = ai.ErrInsufficientCredit
+	ErrQuotaExceeded      = ai.ErrQuotaExceeded
+	ErrInputRejected      = ai.ErrInputRejected
+	// ErrInvalidExamineResult AI 返回的测试结果不符合约定的格式
+	ErrInvalidExamineResult = errors.New("无法解析 AI 返回的测试结果")
+	// ErrExamineNotFound 测试记录不存在，或者不是这个用户的
+	ErrExamineNotFound = repository.ErrRecordNotFound
+	// ErrExamineNotRetryable 只有失败了的，或者等待、执行太久的异步测试才能重试
+	ErrExamineNotRetryable = errors.New("测试不能重试")
+)
+
+const (
+	examineBiz = "question_examine"
+	// 异步测试调用 AI 失败之后的重试次数和初始间隔
+	asyncExamineRetries       = 2
+	asyncExamineRetryInterval = time.Second
+	// 异步测试等待或者执行了这么久还没有结果，说明消息丢了或者消费者出问题了，用户可以重试
+	asyncExaminePendingTimeout = time.Minute * 10
+	// 测试进度默认统计最近 30 天，最多 90 天
+	defaultProgressDays = 30
+	maxProgressDays     = 90
+	// 测试记录默认一页 10 条，最多 100 条
+	defaultHistoryLimit = 10
+	maxHistoryLimit     = 100
+)
 
 // ExamineService 测试服务
+//
+//go:generate mockgen -source=./examine.go -destination=../../mocks/examine.mock.go -package=quemocks -typed=true ExamineService
 type ExamineService interface {
 	// Examine 测试服务
 	// input 是用户输入的内容
 	Examine(ctx context.Context, uid, qid int64, input string) (domain.ExamineResult, error)
-	QuestionResult(ctx context.Context, uid, qid int64) (domain.Result, error)
+	// StreamExamine 流式测试，最后一个事件里面是完整的测试结果
+	// 调用者必须把返回的 channel 读完
+	StreamExamine(ctx context.Context, uid, qid int64, input string) (<-chan domain.ExamineEvent, error)
+	// QuestionResult 最近一次的测试结果，包括得分和改进建议
+	QuestionResult(ctx context.Context, uid, qid int64) (domain.ExamineResult, error)
 	GetResults(ctx context.Context, uid int64, ids []int64) (map[int64]domain.ExamineResult, error)
+	// Quota 用户今天的测试配额
+	Quota(ctx context.Context, uid int64) (domain.ExamineQuota, error)
+
+	// ExamineAsync 异步测试，只是记录下来并且发送消息，AI 的评价由消费者完成
+	// 前端使用返回的 Tid 查询最终的结果
+	ExamineAsync(ctx context.Context, uid, qid int64, input string) (domain.ExamineResult, error)
+	// AsyncResult 根据 Tid 查询异步测试的结果
+	AsyncResult(ctx context.Context, uid int64, tid string) (domain.ExamineResult, error)
+	// RetryAsync 重新提交失败了的，或者等待太久的异步测试
+	RetryAsync(ctx context.Context, uid int64, tid string) (domain.ExamineResult, error)
+	// HandleAsync 消费者调用，完成一次异步测试，重复的消息会被忽略
+	HandleAsync(ctx context.Context, uid int64, tid string) error
+
+	// History 某道题目的所有测试记录，最新的在前面
+	History(ctx context.Context, uid, qid int64, offset, limit int) ([]domain.ExamineResult, int64, error)
+	// Progress 所有题目的测试进度，days 是统计每天测试次数的天数，包括今天
+	Progress(ctx context.Context, uid int64, days int) (domain.ExamineProgress, error)
 }
 
 var _ ExamineService = &LLMExamineService{}
 
 // LLMExamineService 使用 LLM 进行评价的测试服务
 type LLMExamineService struct {
-	queRepo repository.Repository
-	repo    repository.ExamineRepository
-	aiSvc   ai.LLMService
+	queRepo        repository.Repository
+	repo           repository.ExamineRepository
+	aiSvc          ai.LLMService
+	quota          ai.QuotaService
+	producer       event.ExamineEventProducer
+	resultProducer event.ExamineResultEventProducer
+	review         ReviewService
+	logger         *elog.Component
 }
 
 func (svc *LLMExamineService) GetResults(ctx context.Context, uid int64, ids []int64) (map[int64]domain.ExamineResult, error) {
@@ -52,44 +114,434 @@ func (svc *LLMExamineService) GetResults(ctx context.Context, uid int64, ids []i
 	}), err
 }
 
-func (svc *LLMExamineService) QuestionResult(ctx context.Context, uid, qid int64) (domain.Result, error) {
+func (svc *LLMExamineService) Quota(ctx context.Context, uid int64) (domain.ExamineQuota, error) {
+	usage, err := svc.quota.Usage(ctx, uid, examineBiz)
+	if err != nil {
+		return domain.ExamineQuota{}, err
+	}
+	return domain.ExamineQuota{
+		CallsPerDay:  usage.Limit.CallsPerDay,
+		CallsToday:   usage.CallsToday,
+		CallsLeft:    usage.CallsLeftToday(),
+		TokensPerDay: usage.Limit.TokensPerDay,
+		TokensToday:  usage.TokensToday,
+	}, nil
+}
+
+func (svc *LLMExamineService) QuestionResult(ctx context.Context, uid, qid int64) (domain.ExamineResult, error) {
 	return svc.repo.GetResultByUidAndQid(ctx, uid, qid)
 }
 
+func (svc *LLMExamineService) History(ctx context.Context, uid, qid int64, offset, limit int) ([]domain.ExamineResult, int64, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	limit = min(limit, maxHistoryLimit)
+	var (
+		eg      errgroup.Group
+		records []domain.ExamineResult
+		total   int64
+	)
+	eg.Go(func() error {
+		var err error
+		records, err = svc.repo.ListRecords(ctx, uid, qid, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		total, err = svc.repo.CountRecords(ctx, uid, qid)
+		return err
+	})
+	return records, total, eg.Wait()
+}
+
+func (svc *LLMExamineService) Progress(ctx context.Context, uid int64, days int) (domain.ExamineProgress, error) {
+	if days <= 0 {
+		days = defaultProgressDays
+	}
+	days = min(days, maxProgressDays)
+	// 和按天分组使用同一个时区
+	start := domain.StartOfDay(time.Now().In(domain.ExamineLocation)).AddDate(0, 0, 1-days).UnixMilli()
+	var (
+		eg  errgroup.Group
+		res domain.ExamineProgress
+	)
+	eg.Go(func() error {
+		var err error
+		res.Days, err = svc.repo.DailyAttempts(ctx, uid, start)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		res.Distribution, err = svc.repo.ResultDistribution(ctx, uid)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		res.Best, err = svc.repo.BestResults(ctx, uid)
+		return err
+	})
+	return res, eg.Wait()
+}
+
 func (svc *LLMExamineService) Examine(ctx context.Context,
 	uid int64,
 	qid int64, input string) (domain.ExamineResult, error) {
-	const biz = "question_examine"
-	// 实际上我们只需要 title，但是懒得写一个新的接口了
-	que, err := svc.queRepo.GetPubByID(ctx, qid)
+	aiReq, err := svc.newLLMRequest(ctx, uid, qid, input)
 	if err != nil {
 		return domain.ExamineResult{}, err
 	}
-	tid := shortuuid.New()
-	aiReq := ai.LLMRequest{
-		Uid:   uid,
-		Tid:   tid,
-		Biz:   biz,
-		Input: []string{que.Title, input},
+	aiResp, err := svc.aiSvc.Invoke(ctx, aiReq)
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	result := svc.newResult(ctx, qid, aiReq, aiResp)
+	result.Input = input
+	// 开始记录结果
+	err = svc.repo.SaveResult(ctx, uid, qid, result)
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	svc.onResult(ctx, uid, qid, result.Result)
+	return result, nil
+}
+
+func (svc *LLMExamineService) StreamExamine(ctx context.Context,
+	uid int64,
+	qid int64, input string) (<-chan domain.ExamineEvent, error) {
+	aiReq, err := svc.newLLMRequest(ctx, uid, qid, input)
+	if err != nil {
+		return nil, err
+	}
+	aiCh, err := svc.aiSvc.StreamInvoke(ctx, aiReq)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan domain.ExamineEvent, 1)
+	go func() {
+		defer close(ch)
+		for evt := range aiCh {
+			switch {
+			case evt.Err != nil:
+				ch <- domain.ExamineEvent{Err: evt.Err}
+			case evt.Done:
+				// 用户可能已经断开了，但是结果还是要记录下来
+				ctx := context.WithoutCancel(ctx)
+				result := svc.newResult(ctx, qid, aiReq, evt.Response)
+				result.Input = input
+				err1 := svc.repo.SaveResult(ctx, uid, qid, result)
+				if err1 != nil {
+					ch <- domain.ExamineEvent{Err: err1}
+					continue
+				}
+				svc.onResult(ctx, uid, qid, result.Result)
+				ch <- domain.ExamineEvent{Done: true, Result: result}
+			default:
+				ch <- domain.ExamineEvent{Content: evt.Content}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (svc *LLMExamineService) ExamineAsync(ctx context.Context,
+	uid, qid int64, input string) (domain.ExamineResult, error) {
+	// 提前检查，免得用户等了半天才知道次数已经用完了
+	usage, err := svc.quota.Usage(ctx, uid, examineBiz)
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	if usage.CallsLeftToday() == 0 {
+		return domain.ExamineResult{}, ErrQuotaExceeded
+	}
+	_, err = svc.queRepo.GetPubByID(ctx, qid)
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	result := domain.ExamineResult{
+		Qid:    qid,
+		Tid:    shortuuid.New(),
+		Input:  input,
+		Status: domain.ExamineStatusPending,
+	}
+	err = svc.repo.CreateRecord(ctx, uid, qid, result)
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	return svc.publish(ctx, uid, result), nil
+}
+
+func (svc *LLMExamineService) AsyncResult(ctx context.Context, uid int64, tid string) (domain.ExamineResult, error) {
+	return svc.repo.GetRecordByTid(ctx, uid, tid)
+}
+
+func (svc *LLMExamineService) RetryAsync(ctx context.Context, uid int64, tid string) (domain.ExamineResult, error) {
+	result, err := svc.repo.GetRecordByTid(ctx, uid, tid)
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	switch {
+	case result.Status == domain.ExamineStatusFailed:
+		err = svc.repo.UpdateStatus(ctx, uid, tid,
+			domain.ExamineStatusFailed, domain.ExamineStatusPending, "")
+	case result.Status.InProgress() &&
+		time.Since(time.UnixMilli(result.Utime)) > asyncExaminePendingTimeout:
+		err = svc.repo.RenewPending(ctx, uid, tid, result.Status, result.Utime)
+	default:
+		return domain.ExamineResult{}, fmt.Errorf("%w, tid %s 状态 %d",
+			ErrExamineNotRetryable, tid, result.Status)
+	}
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		// 并发重试的时候只有一个能够成功
+		return domain.ExamineResult{}, fmt.Errorf("%w, %w", ErrExamineNotRetryable, err)
+	}
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	result.Status = domain.ExamineStatusPending
+	result.FailReason = ""
+	return svc.publish(ctx, uid, result), nil
+}
+
+// publish 发送失败的时候把记录标记为失败，用户可以重试，而不是直接返回错误
+func (svc *LLMExamineService) publish(ctx context.Context, uid int64, result domain.ExamineResult) domain.ExamineResult {
+	err := svc.producer.Produce(ctx, event.ExamineEvent{
+		Uid: uid,
+		Qid: result.Qid,
+		Tid: result.Tid,
+	})
+	if err == nil {
+		return result
+	}
+	svc.logger.Error("发送异步测试消息失败", elog.FieldErr(err), elog.String("tid", result.Tid))
+	result.Status = domain.ExamineStatusFailed
+	result.FailReason = svc.failReason(err)
+	err = svc.repo.UpdateStatus(ctx, uid, result.Tid,
+		domain.ExamineStatusPending, result.Status, result.FailReason)
+	if err != nil {
+		svc.logger.Error("标记异步测试失败出错", elog.FieldErr(err), elog.String("tid", result.Tid))
+	}
+	return result
+}
+
+func (svc *LLMExamineService) HandleAsync(ctx context.Context, uid int64, tid string) error {
+	record, err := svc.repo.GetRecordByTid(ctx, uid, tid)
+	if err != nil {
+		return err
+	}
+	if record.Status != domain.ExamineStatusPending {
+		// 重复消费
+		return nil
+	}
+	// 调用 AI 要扣积分，先抢占这条记录，重复投递或者多个消费者的时候只有一个能够抢到
+	err = svc.repo.UpdateStatus(ctx, uid, tid,
+		domain.ExamineStatusPending, domain.ExamineStatusRunning, "")
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	result, err := svc.examineWithRetry(ctx, uid, record)
+	if err != nil {
+		svc.logger.Error("异步测试失败", elog.FieldErr(err), elog.String("tid", tid))
+		return svc.repo.UpdateStatus(ctx, uid, tid,
+			domain.ExamineStatusRunning, domain.ExamineStatusFailed, svc.failReason(err))
+	}
+	// 前端始终使用提交时候的 Tid 来查询
+	result.Tid = record.Tid
+	result.Input = record.Input
+	err = svc.repo.UpdateResult(ctx, uid, record.Qid, result, domain.ExamineStatusRunning)
+	if err != nil {
+		return err
+	}
+	svc.onResult(ctx, uid, record.Qid, result.Result)
+	return nil
+}
+
+// onResult 测试结果记录下来之后安排复习，并且通知其它模块
+func (svc *LLMExamineService) onResult(ctx context.Context, uid, qid int64, result domain.Result) {
+	svc.schedule(ctx, uid, qid, result)
+	// 通知失败了只会让其它模块的缓存晚一点更新，不影响测试结果
+	err := svc.resultProducer.Produce(ctx, event.ExamineResultEvent{
+		Uid:    uid,
+		Qid:    qid,
+		Result: result.ToUint8(),
+	})
+	if err != nil {
+		svc.logger.Error("发送测试结果消息失败", elog.FieldErr(err),
+			elog.Int64("uid", uid), elog.Int64("qid", qid))
+	}
+}
+
+// schedule 安排复习失败了不影响测试结果，下一次测试的时候会重新安排
+func (svc *LLMExamineService) schedule(ctx context.Context, uid, qid int64, result domain.Result) {
+	err := svc.review.Schedule(ctx, uid, qid, result)
+	if err != nil {
+		svc.logger.Error("安排复习失败", elog.FieldErr(err),
+			elog.Int64("uid", uid), elog.Int64("qid", qid))
+	}
+}
+
+// examineWithRetry 调用 AI 失败的时候按照指数退避重试，积分不足之类的错误重试也没用
+func (svc *LLMExamineService) examineWithRetry(ctx context.Context,
+	uid int64, record domain.ExamineResult) (domain.ExamineResult, error) {
+	strategy, err := retry.NewExponentialBackoffRetryStrategy(asyncExamineRetryInterval,
+		asyncExamineRetryInterval*4, asyncExamineRetries)
+	if err != nil {
+		return domain.ExamineResult{}, err
+	}
+	for {
+		var result domain.ExamineResult
+		result, err = svc.examineOnce(ctx, uid, record)
+		if err == nil {
+			return result, nil
+		}
+		if errors.Is(err, ErrInsufficientCredit) ||
+			errors.Is(err, ErrQuotaExceeded) ||
+			errors.Is(err, ErrInputRejected) {
+			return domain.ExamineResult{}, err
+		}
+		next, ok := strategy.Next()
+		if !ok {
+			return domain.ExamineResult{}, err
+		}
+		svc.logger.Warn("异步测试失败，准备重试", elog.FieldErr(err),
+			elog.String("tid", record.Tid))
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return domain.ExamineResult{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (svc *LLMExamineService) examineOnce(ctx context.Context,
+	uid int64, record domain.ExamineResult) (domain.ExamineResult, error) {
+	// 每一次调用 AI 都使用新的 Tid
+	aiReq, err := svc.newLLMRequest(ctx, uid, record.Qid, record.Input)
+	if err != nil {
+		return domain.ExamineResult{}, err
 	}
 	aiResp, err := svc.aiSvc.Invoke(ctx, aiReq)
 	if err != nil {
 		return domain.ExamineResult{}, err
 	}
-	// 解析结果
-	parsedRes := svc.parseExamineResult(aiResp.Answer)
+	return svc.newResult(ctx, record.Qid, aiReq, aiResp), nil
+}
+
+// failReason 给用户看的失败原因
+func (svc *LLMExamineService) failReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInsufficientCredit):
+		return "积分不足"
+	case errors.Is(err, ErrQuotaExceeded):
+		return "使用次数超过限制"
+	case errors.Is(err, ErrInputRejected):
+		return "输入包含不允许的内容"
+	default:
+		return "AI 评价失败，请稍后重试"
+	}
+}
+
+func (svc *LLMExamineService) newLLMRequest(ctx context.Context,
+	uid, qid int64, input string) (ai.LLMRequest, error) {
+	que, err := svc.queRepo.GetPubByID(ctx, qid)
+	if err != nil {
+		return ai.LLMRequest{}, err
+	}
+	return ai.LLMRequest{
+		Uid:   uid,
+		Tid:   shortuuid.New(),
+		Biz:   examineBiz,
+		Input: []string{que.Title, input, svc.keywords(que)},
+	}, nil
+}
+
+// keywords 题目答案的关键字，AI 的改进建议要引用这些关键字
+func (svc *LLMExamineService) keywords(que domain.Question) string {
+	return fmt.Sprintf("15K：%s\n25K：%s\n35K：%s",
+		que.Answer.Basic.Keywords,
+		que.Answer.Intermediate.Keywords,
+		que.Answer.Advanced.Keywords)
+}
+
+// newResult 解析 AI 返回的测试结果
+// 提示词模板约定了 JSON 格式的时候，解析失败就带上失败的原因，不读缓存重试一次；
+// 没有约定或者重试还是失败，就按照第一行的 15K，25K，35K 来判定
+func (svc *LLMExamineService) newResult(ctx context.Context, qid int64,
+	aiReq ai.LLMRequest, aiResp ai.LLMResponse) domain.ExamineResult {
 	result := domain.ExamineResult{
-		Result:    parsedRes,
+		Qid:       qid,
 		RawResult: aiResp.Answer,
 		Tokens:    aiResp.Tokens,
 		Amount:    aiResp.Amount,
-		Tid:       tid,
+		Tid:       aiReq.Tid,
+		Status:    domain.ExamineStatusSucceeded,
 	}
-	// 开始记录结果
-	err = svc.repo.SaveResult(ctx, uid, qid, result)
-	return result, err
+	if !aiResp.Structured {
+		result.Result = svc.parseExamineResult(result.RawResult)
+		return result
+	}
+	answer, err := svc.parseExamineAnswer(aiResp.Answer)
+	if err != nil {
+		svc.logger.Warn("解析测试结果失败，准备重试", elog.FieldErr(err),
+			elog.String("tid", aiReq.Tid))
+		answer, err = svc.retry(ctx, aiReq, err, &result)
+	}
+	if err != nil {
+		svc.logger.Error("解析测试结果失败，使用旧的方式解析", elog.FieldErr(err),
+			elog.String("tid", result.Tid))
+		result.Result = svc.parseExamineResult(result.RawResult)
+		return result
+	}
+	result.Result, _ = answer.result()
+	result.Scores = answer.Scores.toDomain()
+	result.Suggestions = slice.Map(answer.Suggestions, func(idx int, src examineSuggestion) domain.ExamineSuggestion {
+		return domain.ExamineSuggestion{
+			Keyword: src.Keyword,
+			Content: src.Content,
+		}
+	})
+	return result
+}
+
+// retry 带上解析失败的原因重新调用一次 AI，重试的花费也算在这一次测试里面
+func (svc *LLMExamineService) retry(ctx context.Context,
+	aiReq ai.LLMRequest, parseErr error, result *domain.ExamineResult) (examineAnswer, error) {
+	aiReq.Tid = shortuuid.New()
+	// 上一次的回答可能已经被缓存了
+	aiReq.Refresh = true
+	aiReq.Input = append(slices.Clone(aiReq.Input), parseErr.Error())
+	aiResp, err := svc.aiSvc.Invoke(ctx, aiReq)
+	if err != nil {
+		return examineAnswer{}, err
+	}
+	result.Tid = aiReq.Tid
+	result.RawResult = aiResp.Answer
+	result.Tokens += aiResp.Tokens
+	result.Amount += aiResp.Amount
+	return svc.parseExamineAnswer(aiResp.Answer)
+}
+
+// parseExamineAnswer 提示词要求大模型只返回 JSON，但是大模型经常会包在 markdown 的代码块里面
+func (svc *LLMExamineService) parseExamineAnswer(answer string) (examineAnswer, error) {
+	start := strings.Index(answer, "{")
+	end := strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return examineAnswer{}, fmt.Errorf("%w, 没有找到 JSON", ErrInvalidExamineResult)
+	}
+	var res examineAnswer
+	err := json.Unmarshal([]byte(answer[start:end+1]), &res)
+	if err != nil {
+		return examineAnswer{}, fmt.Errorf("%w, %w", ErrInvalidExamineResult, err)
+	}
+	return res, res.validate()
 }
 
+// parseExamineResult 旧的约定，第一行里面包含 15K，25K 或者 35K
 func (svc *LLMExamineService) parseExamineResult(answer string) domain.Result {
 	answer = strings.TrimSpace(answer)
 	// 获取第一行
@@ -110,14 +562,83 @@ func (svc *LLMExamineService) parseExamineResult(answer string) domain.Result {
 	}
 }
 
+// examineAnswer 和提示词模板里面约定的 JSON 格式
+type examineAnswer struct {
+	// failed，15K，25K 或者 35K
+	Level       string              `json:"level"`
+	Scores      examineScores       `json:"scores"`
+	Suggestions []examineSuggestion `json:"suggestions"`
+}
+
+var examineLevels = map[string]domain.Result{
+	"FAILED": domain.ResultFailed,
+	"15K":    domain.ResultBasic,
+	"25K":    domain.ResultIntermediate,
+	"35K":    domain.ResultAdvanced,
+}
+
+// result 等级忽略大小写，例如 15k 也可以
+func (a examineAnswer) result() (domain.Result, bool) {
+	res, ok := examineLevels[strings.ToUpper(strings.TrimSpace(a.Level))]
+	return res, ok
+}
+
+func (a examineAnswer) validate() error {
+	if _, ok := a.result(); !ok {
+		return fmt.Errorf("%w, 未知的等级 %s", ErrInvalidExamineResult, a.Level)
+	}
+	for _, score := range []int{a.Scores.Correctness, a.Scores.Depth,
+		a.Scores.Highlights, a.Scores.Keywords} {
+		if score < 0 || score > 100 {
+			return fmt.Errorf("%w, 得分超出范围 %d", ErrInvalidExamineResult, score)
+		}
+	}
+	for _, sug := range a.Suggestions {
+		if sug.Content == "" {
+			return fmt.Errorf("%w, 改进建议为空", ErrInvalidExamineResult)
+		}
+	}
+	return nil
+}
+
+type examineScores struct {
+	Correctness int `json:"correctness"`
+	Depth       int `json:"depth"`
+	Highlights  int `json:"highlights"`
+	Keywords    int `json:"keywords"`
+}
+
+func (s examineScores) toDomain() domain.ExamineScores {
+	return domain.ExamineScores{
+		Correctness: s.Correctness,
+		Depth:       s.Depth,
+		Highlights:  s.Highlights,
+		Keywords:    s.Keywords,
+	}
+}
+
+type examineSuggestion struct {
+	Keyword string `json:"keyword"`
+	Content string `json:"content"`
+}
+
 func NewLLMExamineService(
 	queRepo repository.Repository,
 	repo repository.ExamineRepository,
 	aiSvc ai.LLMService,
+	quota ai.QuotaService,
+	producer event.ExamineEventProducer,
+	resultProducer event.ExamineResultEventProducer,
+	review ReviewService,
 ) ExamineService {
 	return &LLMExamineService{
-		queRepo: queRepo,
-		repo:    repo,
-		aiSvc:   aiSvc,
+		queRepo:        queRepo,
+		repo:           repo,
+		aiSvc:          aiSvc,
+		quota:          quota,
+		producer:       producer,
+		resultProducer: resultProducer,
+		review:         review,
+		logger:         elog.DefaultLogger,
 	}
 }
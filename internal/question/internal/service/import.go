@@ -0,0 +1,193 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository"
+)
+
+const (
+	// 一次最多导入的题目数量，每道题目都要单独保存，太多了请求会超时
+	maxImportQuestions = 200
+	// 和 question 表的 title 字段保持一致
+	maxImportTitleLen = 512
+)
+
+// ImportService 批量导入八股文题目到制作库
+type ImportService interface {
+	// Import 先解析和校验所有的题目，只要有一道题目没有通过校验，就都不会保存
+	// dryRun 为 true 的时候只返回校验报告；publish 为 true 的时候直接发布，否则保存为草稿
+	// 制作库里面有同名的题目就覆盖，qsid 大于 0 的时候会把导入的题目加入到题集
+	Import(ctx context.Context, uid int64, files []domain.ImportFile,
+		dryRun, publish bool, qsid int64) (domain.ImportReport, error)
+}
+
+var _ ImportService = &importService{}
+
+type importService struct {
+	svc     Service
+	repo    repository.Repository
+	setRepo repository.QuestionSetRepository
+	setSvc  QuestionSetService
+}
+
+func NewImportService(svc Service,
+	repo repository.Repository,
+	setRepo repository.QuestionSetRepository,
+	setSvc QuestionSetService) ImportService {
+	return &importService{
+		svc:     svc,
+		repo:    repo,
+		setRepo: setRepo,
+		setSvc:  setSvc,
+	}
+}
+
+func (s *importService) Import(ctx context.Context, uid int64, files []domain.ImportFile,
+	dryRun, publish bool, qsid int64) (domain.ImportReport, error) {
+	report := domain.ImportReport{DryRun: dryRun}
+	for _, file := range files {
+		report.Items = append(report.Items, parseImportFile(file)...)
+	}
+	switch {
+	case len(report.Items) == 0:
+		report.Errors = append(report.Errors, "没有需要导入的题目")
+	case len(report.Items) > maxImportQuestions:
+		report.Errors = append(report.Errors, fmt.Sprintf("一次最多导入 %d 道题目", maxImportQuestions))
+	}
+	s.validate(report.Items)
+
+	if qsid > 0 {
+		sets, err := s.setRepo.GetByIDs(ctx, []int64{qsid})
+		if err != nil {
+			return report, err
+		}
+		if len(sets) == 0 {
+			report.Errors = append(report.Errors, fmt.Sprintf("题集 %d 不存在", qsid))
+		}
+	}
+
+	err := s.resolve(ctx, report.Items)
+	if err != nil {
+		return report, err
+	}
+	if dryRun || !report.Valid() {
+		return report, nil
+	}
+
+	// 没有事务，中间失败的话前面的题目已经保存了，再导入一次会覆盖这些题目
+	for i := range report.Items {
+		item := &report.Items[i]
+		que := item.Question
+		que.Uid = uid
+		var id int64
+		if publish {
+			id, err = s.svc.Publish(ctx, &que)
+		} else {
+			id, err = s.svc.Save(ctx, &que)
+		}
+		if err != nil {
+			return report, fmt.Errorf("导入 %s 失败 %w", item.Source, err)
+		}
+		item.Question.Id = id
+	}
+	report.Imported = true
+	if qsid > 0 {
+		return report, s.attach(ctx, qsid, report.Items)
+	}
+	return report, nil
+}
+
+func (s *importService) validate(items []domain.ImportItem) {
+	titles := make(map[string]string, len(items))
+	for i := range items {
+		item := &items[i]
+		// 解析失败的题目没有必要继续校验了
+		if len(item.Errors) > 0 {
+			continue
+		}
+		title := item.Question.Title
+		switch {
+		case title == "":
+			item.Errors = append(item.Errors, "缺少标题")
+		case utf8.RuneCountInString(title) > maxImportTitleLen:
+			item.Errors = append(item.Errors, fmt.Sprintf("标题超过 %d 个字符", maxImportTitleLen))
+		default:
+			if src, ok := titles[title]; ok {
+				item.Errors = append(item.Errors, fmt.Sprintf("和 %s 的标题重复", src))
+			} else {
+				titles[title] = item.Source
+			}
+		}
+	}
+}
+
+// resolve 按照标题找到制作库里面的同名题目，有多道同名题目的时候覆盖最早的那一道
+func (s *importService) resolve(ctx context.Context, items []domain.ImportItem) error {
+	titles := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Question.Title != "" {
+			titles = append(titles, item.Question.Title)
+		}
+	}
+	if len(titles) == 0 {
+		return nil
+	}
+	existing, err := s.repo.GetByTitles(ctx, domain.DefaultBiz, titles)
+	if err != nil {
+		return err
+	}
+	ids := make(map[string]int64, len(existing))
+	for _, que := range existing {
+		if _, ok := ids[que.Title]; !ok {
+			ids[que.Title] = que.Id
+		}
+	}
+	for i := range items {
+		item := &items[i]
+		item.Question.Biz = domain.DefaultBiz
+		if id, ok := ids[item.Question.Title]; ok {
+			item.Question.Id = id
+			item.Action = domain.ImportActionUpdate
+		} else {
+			item.Action = domain.ImportActionCreate
+		}
+	}
+	return nil
+}
+
+// attach 追加到题集的末尾，已经在题集里面的题目保持原来的位置
+func (s *importService) attach(ctx context.Context, qsid int64, items []domain.ImportItem) error {
+	set, err := s.setRepo.GetByID(ctx, qsid)
+	if err != nil {
+		return err
+	}
+	qids := set.Qids()
+	for _, item := range items {
+		if !slice.Contains(qids, item.Question.Id) {
+			qids = append(qids, item.Question.Id)
+		}
+	}
+	set.Questions = slice.Map(qids, func(idx int, src int64) domain.Question {
+		return domain.Question{Id: src}
+	})
+	return s.setSvc.UpdateQuestions(ctx, set)
+}
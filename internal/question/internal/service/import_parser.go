@@ -0,0 +1,356 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+)
+
+// 和 KnowledgeJobStarter 导出的 CSV 表头保持一致
+const (
+	importColTitle        = "问题"
+	importColLabels       = "标签"
+	importColContent      = "问题描述"
+	importColAnalysis     = "问题分析"
+	importColBasic        = "15K 答案"
+	importColIntermediate = "25K 答案"
+	importColAdvanced     = "35K 答案"
+)
+
+// 和导出知识库时候的 formatAnswer 保持一致，一个答案元素的其余字段各占一行
+var importAnswerFields = []struct {
+	name  string
+	field func(ele *domain.AnswerElement) *string
+}{
+	{name: "关键字", field: func(ele *domain.AnswerElement) *string { return &ele.Keywords }},
+	{name: "引导点", field: func(ele *domain.AnswerElement) *string { return &ele.Guidance }},
+	{name: "亮点", field: func(ele *domain.AnswerElement) *string { return &ele.Highlight }},
+	{name: "速记口诀", field: func(ele *domain.AnswerElement) *string { return &ele.Shorthand }},
+}
+
+// parseImportFile 解析失败不会返回 error，而是记录在 ImportItem.Errors 里面
+func parseImportFile(file domain.ImportFile) []domain.ImportItem {
+	content := strings.TrimPrefix(file.Content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	switch strings.ToLower(path.Ext(file.Name)) {
+	case ".csv":
+		return parseImportCSV(file.Name, content)
+	case ".md", ".markdown":
+		return parseImportMarkdowns(file.Name, content)
+	default:
+		return []domain.ImportItem{{
+			Source: file.Name,
+			Errors: []string{"不支持的文件格式，只支持 .csv 和 .md"},
+		}}
+	}
+}
+
+func parseImportCSV(name, content string) []domain.ImportItem {
+	reader := csv.NewReader(strings.NewReader(content))
+	// 列数由下面自己校验，这样能够给出更加友好的提示
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return []domain.ImportItem{{
+			Source: name,
+			Errors: []string{fmt.Sprintf("无法读取表头 %s", err.Error())},
+		}}
+	}
+	cols := make(map[string]int, len(header))
+	var errs []string
+	for i, col := range header {
+		col = strings.TrimSpace(col)
+		switch col {
+		case importColTitle, importColLabels, importColContent, importColAnalysis,
+			importColBasic, importColIntermediate, importColAdvanced:
+			cols[col] = i
+		default:
+			errs = append(errs, fmt.Sprintf("未知的列 %s", col))
+		}
+	}
+	if _, ok := cols[importColTitle]; !ok {
+		errs = append(errs, fmt.Sprintf("缺少 %s 列", importColTitle))
+	}
+	if len(errs) > 0 {
+		return []domain.ImportItem{{Source: name, Errors: errs}}
+	}
+
+	var res []domain.ImportItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// 引号没有闭合之类的错误，后面的内容也没法解析了
+			res = append(res, domain.ImportItem{
+				Source: name,
+				Errors: []string{fmt.Sprintf("无法解析 %s", err.Error())},
+			})
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		item := domain.ImportItem{Source: fmt.Sprintf("%s:%d", name, line)}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(record) != len(header) {
+			item.Errors = []string{fmt.Sprintf("有 %d 列，和表头的 %d 列不一致", len(record), len(header))}
+			res = append(res, item)
+			continue
+		}
+		cell := func(col string) string {
+			idx, ok := cols[col]
+			if !ok {
+				return ""
+			}
+			return record[idx]
+		}
+		item.Question = domain.Question{
+			Title:   strings.TrimSpace(cell(importColTitle)),
+			Labels:  splitImportLabels(cell(importColLabels)),
+			Content: strings.TrimSpace(cell(importColContent)),
+			Answer: domain.Answer{
+				Analysis:     parseImportAnswer(cell(importColAnalysis)),
+				Basic:        parseImportAnswer(cell(importColBasic)),
+				Intermediate: parseImportAnswer(cell(importColIntermediate)),
+				Advanced:     parseImportAnswer(cell(importColAdvanced)),
+			},
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+// parseImportMarkdowns 一个文件里面可以有多道题目，每道题目都以 front matter 开头，例如导出的知识库
+// 只有一道题目的时候 Source 就是文件名，否则带上题目开始的行号
+func parseImportMarkdowns(name, content string) []domain.ImportItem {
+	docs := splitImportMarkdown(strings.TrimLeft(content, "\n"))
+	if len(docs) == 1 {
+		return []domain.ImportItem{parseImportMarkdown(name, docs[0].content)}
+	}
+	res := make([]domain.ImportItem, 0, len(docs))
+	for _, doc := range docs {
+		res = append(res, parseImportMarkdown(fmt.Sprintf("%s:%d", name, doc.line), doc.content))
+	}
+	return res
+}
+
+type importMarkdownDoc struct {
+	// 开始的行号，从 1 开始
+	line    int
+	content string
+}
+
+// splitImportMarkdown 代码块外面的 --- 并且下一行是 title 字段的，就是一道新的题目
+// 正文里面的分割线后面一般不会紧跟着 title:，所以不会被误认为是新的题目
+func splitImportMarkdown(content string) []importMarkdownDoc {
+	lines := strings.Split(content, "\n")
+	var (
+		res    []importMarkdownDoc
+		start  int
+		inCode bool
+		// front matter 里面的 --- 是结束，不是新的题目
+		inFrontMatter bool
+	)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+		}
+		if inCode || trimmed != "---" {
+			continue
+		}
+		if inFrontMatter {
+			inFrontMatter = false
+			continue
+		}
+		if i+1 >= len(lines) {
+			continue
+		}
+		if key, _, ok := cutImportField(lines[i+1]); !ok || key != "title" {
+			continue
+		}
+		if i > start {
+			res = append(res, importMarkdownDoc{line: start + 1, content: strings.Join(lines[start:i], "\n")})
+		}
+		start = i
+		inFrontMatter = true
+	}
+	return append(res, importMarkdownDoc{line: start + 1, content: strings.Join(lines[start:], "\n")})
+}
+
+// parseImportMarkdown 解析的格式如下，二级标题和 CSV 的列名一样，之前的内容是问题描述：
+//
+//	---
+//	title: 什么是 MVCC
+//	labels: MySQL, 事务
+//	---
+//	问题描述
+//	## 问题分析
+//	## 15K 答案
+//	## 25K 答案
+//	## 35K 答案
+func parseImportMarkdown(name, content string) domain.ImportItem {
+	item := domain.ImportItem{Source: name}
+	lines := strings.Split(strings.TrimLeft(content, "\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		item.Errors = []string{"缺少 front matter"}
+		return item
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		item.Errors = []string{"front matter 没有结束"}
+		return item
+	}
+
+	for _, line := range lines[1:end] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, val, ok := cutImportField(line)
+		if !ok {
+			item.Errors = append(item.Errors, fmt.Sprintf("无法解析 front matter %s", line))
+			continue
+		}
+		switch key {
+		case "title":
+			item.Question.Title = val
+		case "labels":
+			item.Question.Labels = splitImportLabels(val)
+		default:
+			item.Errors = append(item.Errors, fmt.Sprintf("未知的字段 %s", key))
+		}
+	}
+
+	sections := make(map[string][]string, 5)
+	current := importColContent
+	inCode := false
+	for _, line := range lines[end+1:] {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+		}
+		if !inCode && strings.HasPrefix(trimmed, "#") {
+			switch heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#")); heading {
+			case importColAnalysis, importColBasic, importColIntermediate, importColAdvanced:
+				if _, ok := sections[heading]; ok {
+					item.Errors = append(item.Errors, fmt.Sprintf("重复的章节 %s", heading))
+				}
+				current = heading
+				sections[current] = []string{}
+				continue
+			}
+		}
+		sections[current] = append(sections[current], line)
+	}
+	section := func(name string) string {
+		return strings.Join(sections[name], "\n")
+	}
+	item.Question.Content = strings.TrimSpace(section(importColContent))
+	item.Question.Answer = domain.Answer{
+		Analysis:     parseImportAnswer(section(importColAnalysis)),
+		Basic:        parseImportAnswer(section(importColBasic)),
+		Intermediate: parseImportAnswer(section(importColIntermediate)),
+		Advanced:     parseImportAnswer(section(importColAdvanced)),
+	}
+	return item
+}
+
+// parseImportAnswer 是导出知识库时候 formatAnswer 的逆过程
+func parseImportAnswer(text string) domain.AnswerElement {
+	var (
+		ele     domain.AnswerElement
+		content []string
+	)
+	for _, line := range strings.Split(text, "\n") {
+		matched := false
+		for _, f := range importAnswerFields {
+			if val, ok := cutImportPrefix(strings.TrimSpace(line), f.name); ok {
+				*f.field(&ele) = val
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			content = append(content, line)
+		}
+	}
+	ele.Content = strings.TrimSpace(strings.Join(content, "\n"))
+	return ele
+}
+
+// cutImportField 解析 front matter 里面的 key: value
+func cutImportField(line string) (string, string, bool) {
+	key, val, ok := strings.Cut(line, ":")
+	if !ok {
+		key, val, ok = strings.Cut(line, "：")
+	}
+	return strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(val), ok
+}
+
+// cutImportPrefix 兼容全角和半角的冒号
+func cutImportPrefix(line, name string) (string, bool) {
+	for _, sep := range []string{"：", ":"} {
+		if val, ok := strings.CutPrefix(line, name+sep); ok {
+			return strings.TrimSpace(val), true
+		}
+	}
+	return "", false
+}
+
+// splitImportLabels 导出的时候用分号分隔，手写的时候也经常用逗号，或者写成 [a, b]
+func splitImportLabels(val string) []string {
+	val = strings.Trim(strings.TrimSpace(val), "[]")
+	fields := strings.FieldsFunc(val, func(r rune) bool {
+		return r == ';' || r == ',' || r == '；' || r == '，'
+	})
+	res := make([]string, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		f = strings.Trim(strings.TrimSpace(f), `"'`)
+		if _, ok := seen[f]; ok || f == "" {
+			continue
+		}
+		seen[f] = struct{}{}
+		res = append(res, f)
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+func isBlankRecord(record []string) bool {
+	for _, val := range record {
+		if strings.TrimSpace(val) != "" {
+			return false
+		}
+	}
+	return true
+}
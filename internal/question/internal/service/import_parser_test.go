@@ -0,0 +1,191 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImportFile(t *testing.T) {
+	testCases := []struct {
+		name      string
+		file      domain.ImportFile
+		wantItems []domain.ImportItem
+	}{
+		{
+			name: "CSV，和导出的格式一样",
+			file: domain.ImportFile{
+				Name: "genknow.csv",
+				Content: "\ufeff问题,标签,问题描述,问题分析,15K 答案,25K 答案,35K 答案\r\n" +
+					"什么是 MVCC,MySQL;事务,问题描述,\"分析\n关键字：关键字\n引导点：引导点\n亮点：亮点\n速记口诀：口诀\",基本回答,中级回答,高级回答\r\n" +
+					",,,,,,\r\n" +
+					"Redis 为什么快,\"Redis, 缓存\",,,,,\r\n",
+			},
+			wantItems: []domain.ImportItem{
+				{
+					Source: "genknow.csv:2",
+					Question: domain.Question{
+						Title:   "什么是 MVCC",
+						Labels:  []string{"MySQL", "事务"},
+						Content: "问题描述",
+						Answer: domain.Answer{
+							Analysis: domain.AnswerElement{
+								Content:   "分析",
+								Keywords:  "关键字",
+								Guidance:  "引导点",
+								Highlight: "亮点",
+								Shorthand: "口诀",
+							},
+							Basic:        domain.AnswerElement{Content: "基本回答"},
+							Intermediate: domain.AnswerElement{Content: "中级回答"},
+							Advanced:     domain.AnswerElement{Content: "高级回答"},
+						},
+					},
+				},
+				{
+					Source: "genknow.csv:8",
+					Question: domain.Question{
+						Title:  "Redis 为什么快",
+						Labels: []string{"Redis", "缓存"},
+					},
+				},
+			},
+		},
+		{
+			name: "CSV，列的顺序不同，可以缺少答案",
+			file: domain.ImportFile{
+				Name:    "a.CSV",
+				Content: "标签,问题\n[Go],channel 的原理\n",
+			},
+			wantItems: []domain.ImportItem{
+				{
+					Source:   "a.CSV:2",
+					Question: domain.Question{Title: "channel 的原理", Labels: []string{"Go"}},
+				},
+			},
+		},
+		{
+			name: "CSV，未知的列",
+			file: domain.ImportFile{
+				Name:    "a.csv",
+				Content: "题目,标签\nabc,def\n",
+			},
+			wantItems: []domain.ImportItem{
+				{Source: "a.csv", Errors: []string{"未知的列 题目", "缺少 问题 列"}},
+			},
+		},
+		{
+			name: "CSV，列数不一致",
+			file: domain.ImportFile{
+				Name:    "a.csv",
+				Content: "问题,标签\nabc\n",
+			},
+			wantItems: []domain.ImportItem{
+				{Source: "a.csv:2", Errors: []string{"有 1 列，和表头的 2 列不一致"}},
+			},
+		},
+		{
+			name: "Markdown",
+			file: domain.ImportFile{
+				Name: "mvcc.md",
+				Content: "---\ntitle: 什么是 MVCC\nlabels: [MySQL, 事务]\n---\n问题描述\n\n" +
+					"```sql\n# 问题分析\nSELECT 1;\n```\n" +
+					"## 问题分析\n分析\n关键字: 关键字\n" +
+					"## 15K 答案\n基本回答\n### 小标题\n" +
+					"## 35K 答案\n高级回答\n",
+			},
+			wantItems: []domain.ImportItem{
+				{
+					Source: "mvcc.md",
+					Question: domain.Question{
+						Title:   "什么是 MVCC",
+						Labels:  []string{"MySQL", "事务"},
+						Content: "问题描述\n\n```sql\n# 问题分析\nSELECT 1;\n```",
+						Answer: domain.Answer{
+							Analysis: domain.AnswerElement{Content: "分析", Keywords: "关键字"},
+							Basic:    domain.AnswerElement{Content: "基本回答\n### 小标题"},
+							Advanced: domain.AnswerElement{Content: "高级回答"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Markdown，一个文件多道题目",
+			file: domain.ImportFile{
+				Name: "genknow.md",
+				Content: "---\ntitle: 题目1\n---\n\n描述\n\n---\n\n分割线之后\n" +
+					"```\n---\ntitle: 代码块\n```\n" +
+					"---\ntitle: 题目2\nlabels: Go\n---\n## 15K 答案\n回答\n",
+			},
+			wantItems: []domain.ImportItem{
+				{
+					Source: "genknow.md:1",
+					Question: domain.Question{
+						Title:   "题目1",
+						Content: "描述\n\n---\n\n分割线之后\n```\n---\ntitle: 代码块\n```",
+					},
+				},
+				{
+					Source: "genknow.md:14",
+					Question: domain.Question{
+						Title:  "题目2",
+						Labels: []string{"Go"},
+						Answer: domain.Answer{Basic: domain.AnswerElement{Content: "回答"}},
+					},
+				},
+			},
+		},
+		{
+			name: "Markdown，缺少 front matter",
+			file: domain.ImportFile{
+				Name:    "a.md",
+				Content: "# 标题\n内容",
+			},
+			wantItems: []domain.ImportItem{
+				{Source: "a.md", Errors: []string{"缺少 front matter"}},
+			},
+		},
+		{
+			name: "Markdown，未知的字段和重复的章节",
+			file: domain.ImportFile{
+				Name:    "a.md",
+				Content: "---\ntitle: 标题\nauthor: 大明\n---\n## 15K 答案\n## 15K 答案\n",
+			},
+			wantItems: []domain.ImportItem{
+				{
+					Source:   "a.md",
+					Question: domain.Question{Title: "标题"},
+					Errors:   []string{"未知的字段 author", "重复的章节 15K 答案"},
+				},
+			},
+		},
+		{
+			name: "不支持的格式",
+			file: domain.ImportFile{Name: "a.xlsx"},
+			wantItems: []domain.ImportItem{
+				{Source: "a.xlsx", Errors: []string{"不支持的文件格式，只支持 .csv 和 .md"}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantItems, parseImportFile(tc.file))
+		})
+	}
+}
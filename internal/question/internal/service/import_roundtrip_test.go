@@ -0,0 +1,84 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/job"
+	"github.com/ecodeclub/webook/internal/question/internal/service"
+	quemocks "github.com/ecodeclub/webook/internal/question/mocks"
+	"github.com/gotomicro/ego/task/ejob"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// TestImportExportedKnowledge 导出的知识库可以原样导入
+func TestImportExportedKnowledge(t *testing.T) {
+	ques := []domain.Question{
+		{
+			Id:      1,
+			Title:   "什么是 MVCC",
+			Labels:  []string{"MySQL", "事务"},
+			Content: "问题描述\n\n---\n\n```sql\n# 问题分析\nSELECT 1;\n```",
+			Answer: domain.Answer{
+				Analysis: domain.AnswerElement{
+					Content:   "分析",
+					Keywords:  "关键字",
+					Guidance:  "引导点",
+					Highlight: "亮点",
+					Shorthand: "口诀",
+				},
+				Basic:        domain.AnswerElement{Content: "基本回答\n### 小标题"},
+				Intermediate: domain.AnswerElement{Content: "中级回答"},
+				Advanced:     domain.AnswerElement{Content: "高级回答", Keywords: "ReadView"},
+			},
+			Utime: time.UnixMilli(1),
+		},
+		{
+			Id:    2,
+			Title: "Redis 为什么快",
+			Utime: time.UnixMilli(2),
+		},
+	}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := quemocks.NewMockService(ctrl)
+	svc.EXPECT().PubListByUtime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(ques, nil)
+	svc.EXPECT().PubDetailByIDs(gomock.Any(), []int64{1, 2}).Return(ques, nil)
+
+	buf := &bytes.Buffer{}
+	starter := job.NewKnowledgeJobStarter(svc, nil, "", job.KnowledgeFormatMarkdown)
+	cnt, _, err := starter.Export(ejob.Context{Ctx: context.Background()},
+		job.KnowledgeFormatMarkdown, time.Time{}, time.Now(), buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cnt)
+
+	items := service.ParseImportFile(domain.ImportFile{Name: "genknow.md", Content: buf.String()})
+	require.Len(t, items, len(ques))
+	for i, item := range items {
+		assert.Empty(t, item.Errors)
+		want := ques[i]
+		want.Id = 0
+		want.Utime = time.Time{}
+		assert.Equal(t, want, item.Question)
+	}
+}
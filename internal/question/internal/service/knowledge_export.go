@@ -0,0 +1,86 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository"
+)
+
+const (
+	maxKnowledgeExportErrMsgLen = 1024
+	// 增量导出往前重叠的时间，重复导出的题目由下游按照 id 去重
+	knowledgeExportOverlap = 5 * time.Minute
+)
+
+// KnowledgeExportService 记录导出知识库的过程，增量导出依赖于这些记录
+type KnowledgeExportService interface {
+	// Start 开始一次导出，水位由导出的题目决定
+	// 增量导出从同一个格式上一次成功导出的水位往前重叠一段时间开始，没有成功导出过的时候等同于全量导出
+	Start(ctx context.Context, format string, incremental bool) (domain.KnowledgeExportRun, error)
+	// Finish 结束一次导出，err 不为 nil 的时候记录为失败，失败的导出不会推进水位
+	Finish(ctx context.Context, run domain.KnowledgeExportRun, err error) (domain.KnowledgeExportRun, error)
+}
+
+var _ KnowledgeExportService = &knowledgeExportService{}
+
+type knowledgeExportService struct {
+	repo repository.KnowledgeExportRepository
+}
+
+func NewKnowledgeExportService(repo repository.KnowledgeExportRepository) KnowledgeExportService {
+	return &knowledgeExportService{repo: repo}
+}
+
+func (s *knowledgeExportService) Start(ctx context.Context, format string, incremental bool) (domain.KnowledgeExportRun, error) {
+	now := time.Now()
+	run := domain.KnowledgeExportRun{
+		Format:      format,
+		Incremental: incremental,
+		Status:      domain.KnowledgeExportStatusRunning,
+		StartTime:   now,
+	}
+	if incremental {
+		last, err := s.repo.LatestSucceeded(ctx, format)
+		if err != nil {
+			return domain.KnowledgeExportRun{}, err
+		}
+		// 没有导出新的题目的时候水位保持不变
+		run.Watermark = last.Watermark
+		if !last.Watermark.IsZero() {
+			run.Since = last.Watermark.Add(-knowledgeExportOverlap)
+		}
+	}
+	id, err := s.repo.Create(ctx, run)
+	run.Id = id
+	return run, err
+}
+
+func (s *knowledgeExportService) Finish(ctx context.Context, run domain.KnowledgeExportRun, err error) (domain.KnowledgeExportRun, error) {
+	run.EndTime = time.Now()
+	run.Status = domain.KnowledgeExportStatusSucceeded
+	if err != nil {
+		run.Status = domain.KnowledgeExportStatusFailed
+		run.ErrMsg = err.Error()
+		// 和数据库的字段长度保持一致
+		if msg := []rune(run.ErrMsg); len(msg) > maxKnowledgeExportErrMsgLen {
+			run.ErrMsg = string(msg[:maxKnowledgeExportErrMsgLen])
+		}
+	}
+	return run, s.repo.Finish(ctx, run)
+}
@@ -18,6 +18,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/question/internal/event"
 	"github.com/gotomicro/ego/core/elog"
 
@@ -39,15 +41,26 @@ type Service interface {
 	// Delete 会直接删除制作库和线上库的数据
 	Delete(ctx context.Context, qid int64) error
 
-	// PubList 只会返回八股文的数据
-	PubList(ctx context.Context, offset int, limit int) ([]domain.Question, error)
+	// PubList 只会返回八股文的数据，filter 为空的时候不按照标签筛选
+	PubList(ctx context.Context, offset int, limit int, filter label.Filter) ([]domain.Question, error)
+	// PubLabelFacets 八股文每个标签下的题目数量，和筛选条件无关
+	PubLabelFacets(ctx context.Context) ([]label.Facet, error)
+	// SyncLabels 将题目的字符串标签关联到 label 模块的标签上，不存在的标签会被创建
+	SyncLabels(ctx context.Context, qid int64, labels []string) error
 	// GetPubByIDs 目前只会获取基础信息，也就是不包括答案在内的信息
 	GetPubByIDs(ctx context.Context, ids []int64) ([]domain.Question, error)
 	PubDetail(ctx context.Context, qid int64) (domain.Question, error)
+
+	// PubListByUtime 八股文按照更新时间升序翻页，返回 (utime, id) 之后，更新时间不晚于 end 的题目
+	// 只有基础信息，不包括答案，用于导出知识库
+	PubListByUtime(ctx context.Context, utime time.Time, id int64, end time.Time, limit int) ([]domain.Question, error)
+	// PubDetailByIDs 批量获取包括答案在内的线上库数据，不会增加阅读计数
+	PubDetailByIDs(ctx context.Context, ids []int64) ([]domain.Question, error)
 }
 
 type service struct {
 	repo         repository.Repository
+	labelSvc     label.Service
 	syncProducer event.SyncDataToSearchEventProducer
 	intrProducer event.InteractiveEventProducer
 
@@ -59,6 +72,14 @@ func (s *service) GetPubByIDs(ctx context.Context, ids []int64) ([]domain.Questi
 	return s.repo.GetPubByIDs(ctx, ids)
 }
 
+func (s *service) PubListByUtime(ctx context.Context, utime time.Time, id int64, end time.Time, limit int) ([]domain.Question, error) {
+	return s.repo.PubListByUtime(ctx, domain.DefaultBiz, utime, id, end, limit)
+}
+
+func (s *service) PubDetailByIDs(ctx context.Context, ids []int64) ([]domain.Question, error) {
+	return s.repo.GetPubDetailByIDs(ctx, ids)
+}
+
 func (s *service) PubDetail(ctx context.Context, qid int64) (domain.Question, error) {
 	que, err := s.repo.GetPubByID(ctx, qid)
 	if err == nil {
@@ -102,8 +123,26 @@ func (s *service) List(ctx context.Context, offset int, limit int) ([]domain.Que
 	return qs, total, eg.Wait()
 }
 
-func (s *service) PubList(ctx context.Context, offset int, limit int) ([]domain.Question, error) {
-	return s.repo.PubList(ctx, offset, limit, domain.DefaultBiz)
+func (s *service) PubList(ctx context.Context, offset int, limit int, filter label.Filter) ([]domain.Question, error) {
+	return s.repo.PubList(ctx, offset, limit, domain.DefaultBiz, filter)
+}
+
+func (s *service) PubLabelFacets(ctx context.Context) ([]label.Facet, error) {
+	facets, err := s.repo.PubLabelCounts(ctx, domain.DefaultBiz)
+	if err != nil {
+		return nil, err
+	}
+	return s.labelSvc.FillFacetNames(ctx, facets)
+}
+
+func (s *service) SyncLabels(ctx context.Context, qid int64, labels []string) error {
+	ls, err := s.labelSvc.GetOrCreateSystemLabels(ctx, labels)
+	if err != nil {
+		return err
+	}
+	return s.repo.SyncLabels(ctx, qid, slice.Map(ls, func(idx int, src label.Label) int64 {
+		return src.Id
+	}))
 }
 
 func (s *service) Save(ctx context.Context, question *domain.Question) (int64, error) {
@@ -128,15 +167,24 @@ func (s *service) Publish(ctx context.Context, question *domain.Question) (int64
 	if err != nil {
 		return 0, err
 	}
+	// 题目已经发布成功了，标签关联失败不影响发布，可以通过 sync-question-labels 任务修复
+	err = s.SyncLabels(ctx, id, question.Labels)
+	if err != nil {
+		s.logger.Error("关联题目标签失败",
+			elog.FieldErr(err),
+			elog.Int64("qid", id))
+	}
 	s.syncQuestion(id)
 	return id, nil
 }
 
 func NewService(repo repository.Repository,
+	labelSvc label.Service,
 	syncEvent event.SyncDataToSearchEventProducer,
 	intrEvent event.InteractiveEventProducer) Service {
 	return &service{
 		repo:         repo,
+		labelSvc:     labelSvc,
 		syncProducer: syncEvent,
 		intrProducer: intrEvent,
 		logger:       elog.DefaultLogger,
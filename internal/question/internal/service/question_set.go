@@ -16,8 +16,11 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/question/internal/event"
 	"github.com/gotomicro/ego/core/elog"
 
@@ -33,14 +36,34 @@ type QuestionSetService interface {
 	Save(ctx context.Context, set domain.QuestionSet) (int64, error)
 	UpdateQuestions(ctx context.Context, set domain.QuestionSet) error
 	List(ctx context.Context, offset, limit int) ([]domain.QuestionSet, int64, error)
-	ListDefault(ctx context.Context, offset, limit int) ([]domain.QuestionSet, error)
+	// ListDefault 只会返回八股文题集，filter 为空的时候不按照标签筛选
+	ListDefault(ctx context.Context, offset, limit int, filter label.Filter) ([]domain.QuestionSet, error)
+	// DefaultLabelFacets 八股文题集每个标签下的题集数量，题集的标签来自于题集内的题目
+	DefaultLabelFacets(ctx context.Context) ([]label.Facet, error)
 	Detail(ctx context.Context, id int64) (domain.QuestionSet, error)
 	GetByIds(ctx context.Context, ids []int64) ([]domain.QuestionSet, error)
 	DetailByBiz(ctx context.Context, biz string, bizId int64) (domain.QuestionSet, error)
+	// PubDetail 只会返回已发表的题集，其余情况返回 ErrQuestionSetNotPublished
+	PubDetail(ctx context.Context, id int64) (domain.QuestionSet, error)
+
+	// Publish 重新发表已下线的题集
+	Publish(ctx context.Context, id int64) error
+	// Unpublish 下线题集，题集和题目的关系会保留
+	Unpublish(ctx context.Context, id int64) error
+	// Delete 下线题集并且放入回收站
+	Delete(ctx context.Context, id int64) error
+	// Restore 从回收站恢复，恢复之后是已下线的状态，需要重新发表
+	Restore(ctx context.Context, id int64) error
+	TrashList(ctx context.Context, offset, limit int) ([]domain.QuestionSet, int64, error)
+	// PurgeTrash 彻底删除在 before 之前放入回收站的题集，最多删除 limit 个，返回删除的数量
+	PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error)
 }
 
+var ErrQuestionSetNotPublished = errors.New("题集没有发表")
+
 type questionSetService struct {
 	repo         repository.QuestionSetRepository
+	labelSvc     label.Service
 	producer     event.SyncDataToSearchEventProducer
 	intrProducer event.InteractiveEventProducer
 	logger       *elog.Component
@@ -51,8 +74,16 @@ func (q *questionSetService) DetailByBiz(ctx context.Context, biz string, bizId
 	return q.repo.GetByBiz(ctx, biz, bizId)
 }
 
-func (q *questionSetService) ListDefault(ctx context.Context, offset, limit int) ([]domain.QuestionSet, error) {
-	return q.repo.ListByBiz(ctx, offset, limit, domain.DefaultBiz)
+func (q *questionSetService) ListDefault(ctx context.Context, offset, limit int, filter label.Filter) ([]domain.QuestionSet, error) {
+	return q.repo.ListByBiz(ctx, offset, limit, domain.DefaultBiz, filter)
+}
+
+func (q *questionSetService) DefaultLabelFacets(ctx context.Context) ([]label.Facet, error) {
+	facets, err := q.repo.LabelCounts(ctx, domain.DefaultBiz)
+	if err != nil {
+		return nil, err
+	}
+	return q.labelSvc.FillFacetNames(ctx, facets)
 }
 
 func (q *questionSetService) GetByIds(ctx context.Context, ids []int64) ([]domain.QuestionSet, error) {
@@ -63,8 +94,11 @@ func (q *questionSetService) Save(ctx context.Context, set domain.QuestionSet) (
 	var id = set.Id
 	var err error
 	if set.Id > 0 {
+		// 保存不会修改题集的状态
+		set.Status = domain.QuestionSetStatusUnknown
 		err = q.repo.UpdateNonZero(ctx, set)
 	} else {
+		set.Status = domain.QuestionSetStatusPublished
 		id, err = q.repo.Create(ctx, set)
 	}
 	if err != nil {
@@ -99,6 +133,89 @@ func (q *questionSetService) Detail(ctx context.Context, id int64) (domain.Quest
 	return qs, err
 }
 
+func (q *questionSetService) PubDetail(ctx context.Context, id int64) (domain.QuestionSet, error) {
+	qs, err := q.Detail(ctx, id)
+	if err != nil {
+		return domain.QuestionSet{}, err
+	}
+	if qs.Status != domain.QuestionSetStatusPublished {
+		return domain.QuestionSet{}, fmt.Errorf("%w, qsid %d", ErrQuestionSetNotPublished, id)
+	}
+	return qs, nil
+}
+
+func (q *questionSetService) Publish(ctx context.Context, id int64) error {
+	err := q.repo.UpdateStatus(ctx, id, domain.QuestionSetStatusPublished)
+	if err != nil {
+		return err
+	}
+	q.syncQuestionSet(id)
+	return nil
+}
+
+func (q *questionSetService) Unpublish(ctx context.Context, id int64) error {
+	err := q.repo.UpdateStatus(ctx, id, domain.QuestionSetStatusUnPublished)
+	if err != nil {
+		return err
+	}
+	q.removeFromSearch(ctx, id)
+	return nil
+}
+
+func (q *questionSetService) Delete(ctx context.Context, id int64) error {
+	err := q.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	q.removeFromSearch(ctx, id)
+	return nil
+}
+
+func (q *questionSetService) Restore(ctx context.Context, id int64) error {
+	return q.repo.Restore(ctx, id)
+}
+
+func (q *questionSetService) TrashList(ctx context.Context, offset, limit int) ([]domain.QuestionSet, int64, error) {
+	var (
+		eg    errgroup.Group
+		qs    []domain.QuestionSet
+		total int64
+	)
+	eg.Go(func() error {
+		var err error
+		qs, err = q.repo.TrashList(ctx, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		total, err = q.repo.TrashTotal(ctx)
+		return err
+	})
+	return qs, total, eg.Wait()
+}
+
+func (q *questionSetService) PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error) {
+	qs, err := q.repo.ListDeletedBefore(ctx, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, set := range qs {
+		err = q.repo.Purge(ctx, set.Id)
+		if err != nil {
+			return 0, fmt.Errorf("彻底删除题集 %d 失败 %w", set.Id, err)
+		}
+		// 数据已经删除了，通知失败只影响其它模块的残留数据
+		err1 := q.intrProducer.Produce(ctx, event.NewInteractiveDeleteEvent(set.Id, domain.QuestionSetBiz))
+		if err1 != nil {
+			q.logger.Error("发送题集删除消息到互动失败",
+				elog.FieldErr(err1),
+				elog.Int64("qsid", set.Id))
+		}
+		q.removeFromSearch(ctx, set.Id)
+	}
+	return len(qs), nil
+}
+
 func (q *questionSetService) List(ctx context.Context, offset, limit int) ([]domain.QuestionSet, int64, error) {
 	var (
 		eg    errgroup.Group
@@ -129,6 +246,10 @@ func (q *questionSetService) syncQuestionSet(id int64) {
 		)
 		return
 	}
+	// 下线和回收站里面的题集不需要出现在搜索里面
+	if qSet.Status != domain.QuestionSetStatusPublished {
+		return
+	}
 	evt := event.NewQuestionSetEvent(qSet)
 	err = q.producer.Produce(ctx, evt)
 	if err != nil {
@@ -139,11 +260,26 @@ func (q *questionSetService) syncQuestionSet(id int64) {
 	}
 }
 
+func (q *questionSetService) removeFromSearch(ctx context.Context, id int64) {
+	ctx, cancel := context.WithTimeout(ctx, q.syncTimeout)
+	defer cancel()
+	evt := event.NewSyncDeleteEvent(domain.QuestionSetBiz, id)
+	err := q.producer.Produce(ctx, evt)
+	if err != nil {
+		q.logger.Error("发送题集下线消息到搜索失败",
+			elog.FieldErr(err),
+			elog.Any("event", evt),
+		)
+	}
+}
+
 func NewQuestionSetService(repo repository.QuestionSetRepository,
+	labelSvc label.Service,
 	intrProducer event.InteractiveEventProducer,
 	producer event.SyncDataToSearchEventProducer) QuestionSetService {
 	return &questionSetService{
 		repo:         repo,
+		labelSvc:     labelSvc,
 		producer:     producer,
 		intrProducer: intrProducer,
 		logger:       elog.DefaultLogger,
@@ -0,0 +1,103 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository"
+)
+
+// ReviewService 根据测试结果安排复习
+type ReviewService interface {
+	// Schedule 每一次测试之后，根据结果安排下一次复习
+	Schedule(ctx context.Context, uid, qid int64, result domain.Result) error
+	// Today 今天需要复习的题目，最早到期的在前面
+	// qsid 不为 0 的时候只返回这个题集里面的题目
+	Today(ctx context.Context, uid, qsid int64) ([]domain.Review, error)
+}
+
+var _ ReviewService = &reviewService{}
+
+type reviewService struct {
+	repo    repository.ReviewRepository
+	setRepo repository.QuestionSetRepository
+	// 每天最多复习多少道题目，今天已经复习了的也算在里面
+	dailyLimit int
+}
+
+func NewReviewService(repo repository.ReviewRepository,
+	setRepo repository.QuestionSetRepository,
+	dailyLimit int) ReviewService {
+	return &reviewService{
+		repo:       repo,
+		setRepo:    setRepo,
+		dailyLimit: dailyLimit,
+	}
+}
+
+func (s *reviewService) Schedule(ctx context.Context, uid, qid int64, result domain.Result) error {
+	review, err := s.repo.GetReview(ctx, uid, qid)
+	if err != nil {
+		return err
+	}
+	return s.repo.SaveReview(ctx, review.Next(result, time.Now()))
+}
+
+func (s *reviewService) Today(ctx context.Context, uid, qsid int64) ([]domain.Review, error) {
+	err := s.seed(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	reviewed, err := s.repo.CountReviewed(ctx, uid, domain.StartOfDay(now).UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	limit := s.dailyLimit - int(reviewed)
+	if limit <= 0 {
+		return []domain.Review{}, nil
+	}
+	var qids []int64
+	if qsid > 0 {
+		set, err := s.setRepo.GetByID(ctx, qsid)
+		if err != nil {
+			return nil, err
+		}
+		if len(set.Questions) == 0 {
+			return []domain.Review{}, nil
+		}
+		qids = slice.Map(set.Questions, func(idx int, src domain.Question) int64 {
+			return src.Id
+		})
+	}
+	return s.repo.ListDue(ctx, uid, domain.EndOfDay(now).UnixMilli(), qids, limit)
+}
+
+// seed 在有复习计划之前就已经测试过的题目，根据最后一次的测试结果补上复习计划
+func (s *reviewService) seed(ctx context.Context, uid int64) error {
+	results, err := s.repo.UnscheduledResults(ctx, uid)
+	if err != nil || len(results) == 0 {
+		return err
+	}
+	reviews := slice.Map(results, func(idx int, src domain.ExamineResult) domain.Review {
+		review := domain.Review{Uid: uid, Qid: src.Qid}
+		return review.Next(src.Result, time.UnixMilli(src.Ctime))
+	})
+	return s.repo.BatchCreateReviews(ctx, reviews)
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/repository"
+	"golang.org/x/sync/errgroup"
+)
+
+// RevisionService 题目的历史版本，每一次保存和发布都会产生一个新的版本
+type RevisionService interface {
+	// List 最新的版本在前面
+	List(ctx context.Context, qid int64, offset, limit int) ([]domain.QuestionRevision, int64, error)
+	Detail(ctx context.Context, qid, id int64) (domain.QuestionRevision, error)
+	// Diff 从 from 版本到 to 版本有变化的字段
+	Diff(ctx context.Context, qid, from, to int64) ([]domain.FieldDiff, error)
+	// Restore 用历史版本覆盖当前的草稿，publish 为 true 的时候同时发布
+	// 恢复本身也是一次保存或者发布，所以也会产生一个新的版本
+	Restore(ctx context.Context, uid, qid, id int64, publish bool) (int64, error)
+}
+
+// ErrRevisionNotFound 历史版本不存在，或者不是这道题目的
+var ErrRevisionNotFound = repository.ErrRecordNotFound
+
+const (
+	defaultRevisionLimit = 10
+	maxRevisionLimit     = 100
+)
+
+var _ RevisionService = &revisionService{}
+
+type revisionService struct {
+	svc  Service
+	repo repository.RevisionRepository
+}
+
+func NewRevisionService(svc Service, repo repository.RevisionRepository) RevisionService {
+	return &revisionService{
+		svc:  svc,
+		repo: repo,
+	}
+}
+
+func (s *revisionService) List(ctx context.Context, qid int64, offset, limit int) ([]domain.QuestionRevision, int64, error) {
+	var (
+		eg    errgroup.Group
+		revs  []domain.QuestionRevision
+		total int64
+	)
+	if limit <= 0 {
+		limit = defaultRevisionLimit
+	}
+	limit = min(limit, maxRevisionLimit)
+	eg.Go(func() error {
+		var err error
+		revs, err = s.repo.ListRevisions(ctx, qid, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		total, err = s.repo.CountRevisions(ctx, qid)
+		return err
+	})
+	return revs, total, eg.Wait()
+}
+
+func (s *revisionService) Detail(ctx context.Context, qid, id int64) (domain.QuestionRevision, error) {
+	return s.repo.GetRevision(ctx, qid, id)
+}
+
+func (s *revisionService) Diff(ctx context.Context, qid, from, to int64) ([]domain.FieldDiff, error) {
+	var (
+		eg             errgroup.Group
+		fromRev, toRev domain.QuestionRevision
+	)
+	eg.Go(func() error {
+		var err error
+		fromRev, err = s.repo.GetRevision(ctx, qid, from)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		toRev, err = s.repo.GetRevision(ctx, qid, to)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return fromRev.Diff(toRev), nil
+}
+
+func (s *revisionService) Restore(ctx context.Context, uid, qid, id int64, publish bool) (int64, error) {
+	rev, err := s.repo.GetRevision(ctx, qid, id)
+	if err != nil {
+		return 0, err
+	}
+	que := rev.Question
+	que.Uid = uid
+	if publish {
+		return s.svc.Publish(ctx, &que)
+	}
+	return s.svc.Save(ctx, &que)
+}
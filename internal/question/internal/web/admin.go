@@ -15,23 +15,35 @@
 package web
 
 import (
+	"errors"
+
 	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/ginx"
 	"github.com/ecodeclub/ginx/session"
 	"github.com/ecodeclub/webook/internal/interactive"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/errs"
 	"github.com/ecodeclub/webook/internal/question/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
 // AdminHandler 制作库
 type AdminHandler struct {
-	svc service.Service
+	svc         service.Service
+	draftSvc    service.AnswerDraftService
+	importSvc   service.ImportService
+	revisionSvc service.RevisionService
 }
 
-func NewAdminHandler(svc service.Service) *AdminHandler {
+func NewAdminHandler(svc service.Service,
+	draftSvc service.AnswerDraftService,
+	importSvc service.ImportService,
+	revisionSvc service.RevisionService) *AdminHandler {
 	return &AdminHandler{
-		svc: svc,
+		svc:         svc,
+		draftSvc:    draftSvc,
+		importSvc:   importSvc,
+		revisionSvc: revisionSvc,
 	}
 }
 
@@ -41,6 +53,16 @@ func (h *AdminHandler) PrivateRoutes(server *gin.Engine) {
 	server.POST("/question/detail", ginx.B[Qid](h.Detail))
 	server.POST("/question/delete", ginx.B[Qid](h.Delete))
 	server.POST("/question/publish", ginx.BS[SaveReq](h.Publish))
+	// 使用 AI 生成答案草稿，不会保存
+	server.POST("/question/draft", ginx.BS[SaveReq](h.GenerateDraft))
+	// 批量导入 CSV 和 Markdown，建议先 dryRun 看一下校验报告
+	server.POST("/question/import", ginx.BS[ImportReq](h.Import))
+
+	// 历史版本，每一次保存和发布都会产生一个版本
+	server.POST("/question/revision/list", ginx.B[RevisionListReq](h.RevisionList))
+	server.POST("/question/revision/detail", ginx.B[RevisionReq](h.RevisionDetail))
+	server.POST("/question/revision/diff", ginx.B[RevisionDiffReq](h.RevisionDiff))
+	server.POST("/question/revision/restore", ginx.BS[RevisionRestoreReq](h.RevisionRestore))
 }
 
 func (h *AdminHandler) Delete(ctx *ginx.Context, qid Qid) (ginx.Result, error) {
@@ -77,6 +99,95 @@ func (h *AdminHandler) Publish(ctx *ginx.Context, req SaveReq, sess session.Sess
 	}, nil
 }
 
+func (h *AdminHandler) GenerateDraft(ctx *ginx.Context, req SaveReq, sess session.Session) (ginx.Result, error) {
+	que, err := h.draftSvc.Generate(ctx, sess.Claims().Uid, req.Question.toDomain())
+	switch {
+	case errors.Is(err, service.ErrBudgetExceeded):
+		return ginx.Result{
+			Code: errs.AIBudgetExceeded.Code,
+			Msg:  errs.AIBudgetExceeded.Msg,
+		}, nil
+	case err != nil:
+		return systemErrorResult, err
+	}
+	// 只替换答案，其余的字段保持前端传过来的样子
+	res := req.Question
+	res.Analysis = newAnswerElement(que.Answer.Analysis)
+	res.Basic = newAnswerElement(que.Answer.Basic)
+	res.Intermediate = newAnswerElement(que.Answer.Intermediate)
+	res.Advanced = newAnswerElement(que.Answer.Advanced)
+	return ginx.Result{
+		Data: SaveReq{Question: res},
+	}, nil
+}
+
+func (h *AdminHandler) Import(ctx *ginx.Context, req ImportReq, sess session.Session) (ginx.Result, error) {
+	report, err := h.importSvc.Import(ctx, sess.Claims().Uid, req.files(), req.DryRun, req.Publish, req.Qsid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	if !report.Valid() {
+		return ginx.Result{
+			Code: errs.ImportInvalid.Code,
+			Msg:  errs.ImportInvalid.Msg,
+			Data: newImportReport(report),
+		}, nil
+	}
+	return ginx.Result{
+		Data: newImportReport(report),
+	}, nil
+}
+
+func (h *AdminHandler) RevisionList(ctx *ginx.Context, req RevisionListReq) (ginx.Result, error) {
+	revs, total, err := h.revisionSvc.List(ctx, req.Qid, req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: newRevisionList(revs, total),
+	}, nil
+}
+
+func (h *AdminHandler) RevisionDetail(ctx *ginx.Context, req RevisionReq) (ginx.Result, error) {
+	rev, err := h.revisionSvc.Detail(ctx, req.Qid, req.Id)
+	if err != nil {
+		return revisionErrResult(err)
+	}
+	return ginx.Result{
+		Data: newRevisionDetail(rev),
+	}, nil
+}
+
+func (h *AdminHandler) RevisionDiff(ctx *ginx.Context, req RevisionDiffReq) (ginx.Result, error) {
+	diffs, err := h.revisionSvc.Diff(ctx, req.Qid, req.From, req.To)
+	if err != nil {
+		return revisionErrResult(err)
+	}
+	return ginx.Result{
+		Data: newRevisionDiff(diffs),
+	}, nil
+}
+
+func (h *AdminHandler) RevisionRestore(ctx *ginx.Context, req RevisionRestoreReq, sess session.Session) (ginx.Result, error) {
+	id, err := h.revisionSvc.Restore(ctx, sess.Claims().Uid, req.Qid, req.Id, req.Publish)
+	if err != nil {
+		return revisionErrResult(err)
+	}
+	return ginx.Result{
+		Data: id,
+	}, nil
+}
+
+func revisionErrResult(err error) (ginx.Result, error) {
+	if errors.Is(err, service.ErrRevisionNotFound) {
+		return ginx.Result{
+			Code: errs.RevisionNotFound.Code,
+			Msg:  errs.RevisionNotFound.Msg,
+		}, nil
+	}
+	return systemErrorResult, err
+}
+
 func (h *AdminHandler) List(ctx *ginx.Context, req Page) (ginx.Result, error) {
 	// 制作库不需要统计总数
 	data, cnt, err := h.svc.List(ctx, req.Offset, req.Limit)
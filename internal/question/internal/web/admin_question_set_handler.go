@@ -40,6 +40,11 @@ func (h *AdminQuestionSetHandler) PrivateRoutes(server *gin.Engine) {
 	g.POST("/questions/save", ginx.BS[UpdateQuestions](h.UpdateQuestions))
 	g.POST("/list", ginx.B[Page](h.ListQuestionSets))
 	g.POST("/detail", ginx.B(h.RetrieveQuestionSetDetail))
+	g.POST("/publish", ginx.B[QuestionSetID](h.Publish))
+	g.POST("/unpublish", ginx.B[QuestionSetID](h.Unpublish))
+	g.POST("/delete", ginx.B[QuestionSetID](h.Delete))
+	g.POST("/restore", ginx.B[QuestionSetID](h.Restore))
+	g.POST("/trash/list", ginx.B[Page](h.TrashList))
 }
 
 // UpdateQuestions 整体更新题集中的所有问题 覆盖式的 前端传递过来的问题集合就是题集中最终的问题集合
@@ -87,15 +92,65 @@ func (h *AdminQuestionSetHandler) ListQuestionSets(ctx *ginx.Context, req Page)
 	}
 	return ginx.Result{
 		Data: QuestionSetList{
-			Total: total,
-			QuestionSets: slice.Map(data, func(idx int, src domain.QuestionSet) QuestionSet {
-				qs := newQuestionSet(src)
-				return qs
-			}),
+			Total:        total,
+			QuestionSets: slice.Map(data, newAdminQuestionSet),
 		},
 	}, nil
 }
 
+// Publish 重新发表已下线的题集
+func (h *AdminQuestionSetHandler) Publish(ctx *ginx.Context, req QuestionSetID) (ginx.Result, error) {
+	err := h.svc.Publish(ctx, req.QSID)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *AdminQuestionSetHandler) Unpublish(ctx *ginx.Context, req QuestionSetID) (ginx.Result, error) {
+	err := h.svc.Unpublish(ctx, req.QSID)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+// Delete 放入回收站，超过保留期限之后会被彻底删除
+func (h *AdminQuestionSetHandler) Delete(ctx *ginx.Context, req QuestionSetID) (ginx.Result, error) {
+	err := h.svc.Delete(ctx, req.QSID)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *AdminQuestionSetHandler) Restore(ctx *ginx.Context, req QuestionSetID) (ginx.Result, error) {
+	err := h.svc.Restore(ctx, req.QSID)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *AdminQuestionSetHandler) TrashList(ctx *ginx.Context, req Page) (ginx.Result, error) {
+	data, total, err := h.svc.TrashList(ctx, req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: QuestionSetList{
+			Total:        total,
+			QuestionSets: slice.Map(data, newAdminQuestionSet),
+		},
+	}, nil
+}
+
+func newAdminQuestionSet(idx int, src domain.QuestionSet) QuestionSet {
+	qs := newQuestionSet(src)
+	qs.Status = src.Status.ToUint8()
+	return qs
+}
+
 func (h *AdminQuestionSetHandler) RetrieveQuestionSetDetail(
 	ctx *ginx.Context,
 	req QuestionSetID) (ginx.Result, error) {
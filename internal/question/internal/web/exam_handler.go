@@ -0,0 +1,127 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ginx"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/errs"
+	"github.com/ecodeclub/webook/internal/question/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ExamHandler 基于题集的模拟考试
+type ExamHandler struct {
+	svc service.ExamService
+}
+
+func NewExamHandler(svc service.ExamService) *ExamHandler {
+	return &ExamHandler{
+		svc: svc,
+	}
+}
+
+func (h *ExamHandler) MemberRoutes(server *gin.Engine) {
+	g := server.Group("/question/exam")
+	g.POST("/start", ginx.BS(h.Start))
+	g.POST("/submit", ginx.BS(h.Submit))
+	g.POST("/finish", ginx.BS(h.Finish))
+	g.POST("/detail", ginx.BS(h.Detail))
+	g.POST("/list", ginx.BS(h.List))
+}
+
+func (h *ExamHandler) Start(ctx *ginx.Context, req ExamStartReq, sess session.Session) (ginx.Result, error) {
+	exam, err := h.svc.Start(ctx, sess.Claims().Uid, req.Qsid,
+		req.Count, req.Random, time.Duration(req.Duration)*time.Minute)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{
+		Data: newExam(exam),
+	}, nil
+}
+
+// Submit 回答一道题目，评价的结果交卷之后才能看到
+func (h *ExamHandler) Submit(ctx *ginx.Context, req ExamSubmitReq, sess session.Session) (ginx.Result, error) {
+	err := h.svc.Submit(ctx, sess.Claims().Uid, req.Id, req.Qid, req.Input)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{}, nil
+}
+
+// Finish 交卷，返回考试报告
+func (h *ExamHandler) Finish(ctx *ginx.Context, req ExamIdReq, sess session.Session) (ginx.Result, error) {
+	exam, err := h.svc.Finish(ctx, sess.Claims().Uid, req.Id)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: newExam(exam),
+	}, nil
+}
+
+// Detail 考试详情，结束了的考试就是考试报告
+func (h *ExamHandler) Detail(ctx *ginx.Context, req ExamIdReq, sess session.Session) (ginx.Result, error) {
+	exam, err := h.svc.Detail(ctx, sess.Claims().Uid, req.Id)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: newExam(exam),
+	}, nil
+}
+
+func (h *ExamHandler) List(ctx *ginx.Context, req Page, sess session.Session) (ginx.Result, error) {
+	exams, total, err := h.svc.List(ctx, sess.Claims().Uid, req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: ExamList{
+			Total: total,
+			Exams: slice.Map(exams, func(idx int, src domain.Exam) Exam {
+				return newExam(src)
+			}),
+		},
+	}, nil
+}
+
+func (h *ExamHandler) errResult(err error) (ginx.Result, error) {
+	switch {
+	case errors.Is(err, service.ErrExamFinished):
+		return ginx.Result{
+			Code: errs.ExamFinished.Code,
+			Msg:  errs.ExamFinished.Msg,
+		}, nil
+	case errors.Is(err, service.ErrExamAnswered):
+		return ginx.Result{
+			Code: errs.ExamAnswered.Code,
+			Msg:  errs.ExamAnswered.Msg,
+		}, nil
+	case errors.Is(err, service.ErrQuestionSetNotPublished):
+		// 下线或者删除了的题集，对 C 端来说就是不存在
+		return ginx.Result{
+			Code: errs.QuestionSetNotFound.Code,
+			Msg:  errs.QuestionSetNotFound.Msg,
+		}, nil
+	}
+	return examineErrResult(err)
+}
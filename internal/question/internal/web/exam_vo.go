@@ -0,0 +1,97 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+)
+
+// ExamStartReq Count 为 0 的时候使用题集里面的所有题目
+// Duration 是考试时长，单位是分钟，为 0 的时候每道题目 5 分钟
+type ExamStartReq struct {
+	Qsid     int64 `json:"qsid"`
+	Count    int   `json:"count,omitempty"`
+	Random   bool  `json:"random,omitempty"`
+	Duration int   `json:"duration,omitempty"`
+}
+
+type ExamSubmitReq struct {
+	Id    int64  `json:"id"`
+	Qid   int64  `json:"qid"`
+	Input string `json:"input"`
+}
+
+type ExamIdReq struct {
+	Id int64 `json:"id"`
+}
+
+type Exam struct {
+	Id   int64 `json:"id"`
+	Qsid int64 `json:"qsid"`
+	// 1 进行中，2 已交卷，3 超时自动交卷
+	Status     uint8          `json:"status"`
+	Deadline   int64          `json:"deadline"`
+	FinishTime int64          `json:"finishTime,omitempty"`
+	Level      uint8          `json:"level"`
+	WeakLabels []string       `json:"weakLabels,omitempty"`
+	Questions  []ExamQuestion `json:"questions,omitempty"`
+	Ctime      int64          `json:"ctime"`
+}
+
+type ExamQuestion struct {
+	Qid      int64    `json:"qid"`
+	Title    string   `json:"title"`
+	Labels   []string `json:"labels,omitempty"`
+	Input    string   `json:"input,omitempty"`
+	Answered bool     `json:"answered"`
+	// 交卷之后才有
+	Result uint8  `json:"result"`
+	Tid    string `json:"tid,omitempty"`
+}
+
+type ExamList struct {
+	Total int64  `json:"total"`
+	Exams []Exam `json:"exams"`
+}
+
+// newExam 交卷之前不返回每道题目的评价
+func newExam(e domain.Exam) Exam {
+	finished := e.Status != domain.ExamStatusInProgress
+	return Exam{
+		Id:         e.Id,
+		Qsid:       e.Qsid,
+		Status:     e.Status.ToUint8(),
+		Deadline:   e.Deadline,
+		FinishTime: e.FinishTime,
+		Level:      e.Level.ToUint8(),
+		WeakLabels: e.WeakLabels,
+		Questions: slice.Map(e.Questions, func(idx int, src domain.ExamQuestion) ExamQuestion {
+			q := ExamQuestion{
+				Qid:      src.Qid,
+				Title:    src.Title,
+				Labels:   src.Labels,
+				Input:    src.Input,
+				Answered: src.Answered,
+			}
+			if finished {
+				q.Result = src.Result.ToUint8()
+				q.Tid = src.Tid
+			}
+			return q
+		}),
+		Ctime: e.Ctime,
+	}
+}
@@ -16,14 +16,23 @@ package web
 
 import (
 	"errors"
+	"io"
+	"net/http"
+	"time"
 
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/ginx"
 	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
 	"github.com/ecodeclub/webook/internal/question/internal/errs"
 	"github.com/ecodeclub/webook/internal/question/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/gotomicro/ego/core/elog"
 )
 
+// 订阅异步测试结果的时候，查询的间隔
+const asyncResultPollInterval = time.Second
+
 type ExamineHandler struct {
 	svc service.ExamineService
 }
@@ -37,22 +46,220 @@ func NewExamineHandler(svc service.ExamineService) *ExamineHandler {
 func (h *ExamineHandler) MemberRoutes(server *gin.Engine) {
 	g := server.Group("/question/examine")
 	g.POST("", ginx.BS(h.Examine))
+	// 使用 SSE 推送 AI 的部分回答，最后推送完整的测试结果
+	g.POST("/stream", h.StreamExamine)
+	g.POST("/quota", ginx.S(h.Quota))
+
+	// 异步测试，提交之后使用 Tid 轮询或者订阅结果
+	g.POST("/async", ginx.BS(h.ExamineAsync))
+	g.POST("/async/result", ginx.BS(h.AsyncResult))
+	// 使用 SSE 推送异步测试的结果
+	g.POST("/async/subscribe", h.SubscribeAsync)
+	g.POST("/async/retry", ginx.BS(h.RetryAsync))
+
+	// 历史记录和进度
+	g.POST("/history", ginx.BS(h.History))
+	g.POST("/progress", ginx.BS(h.Progress))
 }
 
 func (h *ExamineHandler) Examine(ctx *ginx.Context, req ExamineReq, sess session.Session) (ginx.Result, error) {
 	res, err := h.svc.Examine(ctx, sess.Claims().Uid, req.Qid, req.Input)
+	if err != nil {
+		return examineErrResult(err)
+	}
+	return ginx.Result{
+		Data: newExamineResult(res),
+	}, nil
+}
+
+// StreamExamine 流式测试
+// 事件 message 是 AI 的部分回答，result 是最终的测试结果，error 是错误
+func (h *ExamineHandler) StreamExamine(ctx *gin.Context) {
+	var req ExamineReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	sess, err := session.Get(&ginx.Context{Context: ctx})
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	ch, err := h.svc.StreamExamine(ctx, sess.Claims().Uid, req.Qid, req.Input)
+	if err != nil {
+		res, err := examineErrResult(err)
+		if err != nil {
+			elog.Error("流式测试失败", elog.FieldErr(err))
+		}
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+	ctx.Stream(func(w io.Writer) bool {
+		evt, ok := <-ch
+		if !ok {
+			return false
+		}
+		switch {
+		case evt.Err != nil:
+			res, err := examineErrResult(evt.Err)
+			if err != nil {
+				elog.Error("流式测试失败", elog.FieldErr(err))
+			}
+			ctx.SSEvent("error", res)
+		case evt.Done:
+			ctx.SSEvent("result", newExamineResult(evt.Result))
+		default:
+			ctx.SSEvent("message", ExamineStreamContent{Content: evt.Content})
+		}
+		return true
+	})
+	// 客户端可能提前断开了，要把剩下的读完，保证扣费和记录能够完成
+	for range ch {
+	}
+}
+
+func (h *ExamineHandler) ExamineAsync(ctx *ginx.Context, req ExamineReq, sess session.Session) (ginx.Result, error) {
+	res, err := h.svc.ExamineAsync(ctx, sess.Claims().Uid, req.Qid, req.Input)
+	if err != nil {
+		return examineErrResult(err)
+	}
+	return ginx.Result{
+		Data: newExamineResult(res),
+	}, nil
+}
+
+// AsyncResult 查询异步测试的结果，状态是等待中的时候前端过一会再来查
+func (h *ExamineHandler) AsyncResult(ctx *ginx.Context, req ExamineTidReq, sess session.Session) (ginx.Result, error) {
+	res, err := h.svc.AsyncResult(ctx, sess.Claims().Uid, req.Tid)
+	if err != nil {
+		return asyncErrResult(err)
+	}
+	return ginx.Result{
+		Data: newExamineResult(res),
+	}, nil
+}
+
+// RetryAsync 重新提交失败了的，或者等待太久的异步测试
+func (h *ExamineHandler) RetryAsync(ctx *ginx.Context, req ExamineTidReq, sess session.Session) (ginx.Result, error) {
+	res, err := h.svc.RetryAsync(ctx, sess.Claims().Uid, req.Tid)
+	if err != nil {
+		return asyncErrResult(err)
+	}
+	return ginx.Result{
+		Data: newExamineResult(res),
+	}, nil
+}
+
+// SubscribeAsync 订阅异步测试的结果，结果出来之前定时查询
+// 事件 result 是最终的测试结果，error 是错误
+func (h *ExamineHandler) SubscribeAsync(ctx *gin.Context) {
+	var req ExamineTidReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	sess, err := session.Get(&ginx.Context{Context: ctx})
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	uid := sess.Claims().Uid
+	ticker := time.NewTicker(asyncResultPollInterval)
+	defer ticker.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		res, err := h.svc.AsyncResult(ctx, uid, req.Tid)
+		if err != nil {
+			result, err := asyncErrResult(err)
+			if err != nil {
+				elog.Error("查询异步测试结果失败", elog.FieldErr(err))
+			}
+			ctx.SSEvent("error", result)
+			return false
+		}
+		if !res.Status.InProgress() {
+			ctx.SSEvent("result", newExamineResult(res))
+			return false
+		}
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-ticker.C:
+			return true
+		}
+	})
+}
+
+// History 某道题目的所有测试记录
+func (h *ExamineHandler) History(ctx *ginx.Context, req ExamineHistoryReq, sess session.Session) (ginx.Result, error) {
+	records, total, err := h.svc.History(ctx, sess.Claims().Uid, req.Qid, req.Offset, req.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: ExamineHistory{
+			Total: total,
+			Records: slice.Map(records, func(idx int, src domain.ExamineResult) ExamineResult {
+				return newExamineResult(src)
+			}),
+		},
+	}, nil
+}
+
+// Progress 所有题目的测试进度
+func (h *ExamineHandler) Progress(ctx *ginx.Context, req ExamineProgressReq, sess session.Session) (ginx.Result, error) {
+	res, err := h.svc.Progress(ctx, sess.Claims().Uid, req.Days)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: newExamineProgress(res),
+	}, nil
+}
+
+// Quota 今天剩余的测试次数
+func (h *ExamineHandler) Quota(ctx *ginx.Context, sess session.Session) (ginx.Result, error) {
+	res, err := h.svc.Quota(ctx, sess.Claims().Uid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: newExamineQuota(res),
+	}, nil
+}
+
+// examineErrResult 调用 AI 评价的时候，积分不足之类的错误需要告诉用户
+func examineErrResult(err error) (ginx.Result, error) {
 	switch {
 	case errors.Is(err, service.ErrInsufficientCredit):
 		return ginx.Result{
 			Code: errs.InsufficientCredit.Code,
 			Msg:  errs.InsufficientCredit.Msg,
 		}, nil
+	case errors.Is(err, service.ErrQuotaExceeded):
+		return ginx.Result{
+			Code: errs.QuotaExceeded.Code,
+			Msg:  errs.QuotaExceeded.Msg,
+		}, nil
+	case errors.Is(err, service.ErrInputRejected):
+		return ginx.Result{
+			Code: errs.InputRejected.Code,
+			Msg:  errs.InputRejected.Msg,
+		}, nil
+	}
+	return systemErrorResult, err
+}
 
-	case err == nil:
+// asyncErrResult 异步测试的 Tid 是前端传过来的，不存在或者不能重试都是客户端的问题
+func asyncErrResult(err error) (ginx.Result, error) {
+	switch {
+	case errors.Is(err, service.ErrExamineNotFound):
 		return ginx.Result{
-			Data: newExamineResult(res),
+			Code: errs.ExamineNotFound.Code,
+			Msg:  errs.ExamineNotFound.Msg,
+		}, nil
+	case errors.Is(err, service.ErrExamineNotRetryable):
+		return ginx.Result{
+			Code: errs.ExamineNotRetryable.Code,
+			Msg:  errs.ExamineNotRetryable.Msg,
 		}, nil
-	default:
-		return systemErrorResult, err
 	}
+	return systemErrorResult, err
 }
@@ -14,16 +14,37 @@
 
 package web
 
-import "github.com/ecodeclub/webook/internal/question/internal/domain"
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+)
 
 type ExamineReq struct {
 	Qid   int64  `json:"qid"`
 	Input string `json:"input"`
 }
 
+// ExamineHistoryReq 分页查询某道题目的测试记录
+type ExamineHistoryReq struct {
+	Qid    int64 `json:"qid"`
+	Offset int   `json:"offset,omitempty"`
+	Limit  int   `json:"limit,omitempty"`
+}
+
+// ExamineProgressReq Days 是统计每天测试次数的天数，默认 30 天，最多 90 天
+type ExamineProgressReq struct {
+	Days int `json:"days,omitempty"`
+}
+
+// ExamineTidReq 异步测试使用 Tid 查询结果和重试
+type ExamineTidReq struct {
+	Tid string `json:"tid"`
+}
+
 type ExamineResult struct {
 	Qid    int64
-	Result uint8 `json:"result"`
+	Tid    string `json:"tid"`
+	Result uint8  `json:"result"`
 	// 原始回答，源自 AI
 	RawResult string `json:"rawResult"`
 
@@ -31,13 +52,148 @@ type ExamineResult struct {
 	Tokens int64 `json:"tokens"`
 	// 花费的金额
 	Amount int64 `json:"amount"`
+
+	// 各个维度的得分，AI 没有返回结构化结果的时候都是 0
+	Scores      ExamineScores       `json:"scores"`
+	Suggestions []ExamineSuggestion `json:"suggestions"`
+
+	// 异步测试的状态，1 等待中，2 成功，3 失败
+	Status     uint8  `json:"status"`
+	FailReason string `json:"failReason"`
+
+	// 用户的回答
+	Input string `json:"input,omitempty"`
+	Ctime int64  `json:"ctime,omitempty"`
+}
+
+// ExamineScores 各个维度的得分，范围是 0-100
+type ExamineScores struct {
+	Correctness int `json:"correctness"`
+	Depth       int `json:"depth"`
+	Highlights  int `json:"highlights"`
+	Keywords    int `json:"keywords"`
+}
+
+// ExamineSuggestion 改进建议，Keyword 是题目答案里面的关键字
+type ExamineSuggestion struct {
+	Keyword string `json:"keyword"`
+	Content string `json:"content"`
 }
 
 func newExamineResult(r domain.ExamineResult) ExamineResult {
+	status := r.Status
+	if status.InProgress() {
+		// 前端不需要区分有没有开始调用 AI，都是等待中
+		status = domain.ExamineStatusPending
+	}
 	return ExamineResult{
-		Qid:       r.Qid,
-		Result:    r.Result.ToUint8(),
-		RawResult: r.RawResult,
-		Amount:    r.Amount,
+		Qid:         r.Qid,
+		Tid:         r.Tid,
+		Result:      r.Result.ToUint8(),
+		RawResult:   r.RawResult,
+		Tokens:      r.Tokens,
+		Amount:      r.Amount,
+		Scores:      newExamineScores(r.Scores),
+		Suggestions: newExamineSuggestions(r.Suggestions),
+		Status:      status.ToUint8(),
+		FailReason:  r.FailReason,
+		Input:       r.Input,
+		Ctime:       r.Ctime,
+	}
+}
+
+func newExamineScores(s domain.ExamineScores) ExamineScores {
+	return ExamineScores{
+		Correctness: s.Correctness,
+		Depth:       s.Depth,
+		Highlights:  s.Highlights,
+		Keywords:    s.Keywords,
+	}
+}
+
+func newExamineSuggestions(sugs []domain.ExamineSuggestion) []ExamineSuggestion {
+	return slice.Map(sugs, func(idx int, src domain.ExamineSuggestion) ExamineSuggestion {
+		return ExamineSuggestion{
+			Keyword: src.Keyword,
+			Content: src.Content,
+		}
+	})
+}
+
+type ExamineHistory struct {
+	Total   int64           `json:"total"`
+	Records []ExamineResult `json:"records"`
+}
+
+type ExamineProgress struct {
+	Days         []ExamineDailyAttempts `json:"days"`
+	Distribution []ExamineResultCount   `json:"distribution"`
+	Best         []ExamineBestResult    `json:"best"`
+}
+
+type ExamineDailyAttempts struct {
+	Date     string `json:"date"`
+	Attempts int64  `json:"attempts"`
+}
+
+type ExamineResultCount struct {
+	Result uint8 `json:"result"`
+	Count  int64 `json:"count"`
+}
+
+// ExamineBestResult 某道题目的最好成绩，LastTime 是最后一次测试的时间
+type ExamineBestResult struct {
+	Qid      int64 `json:"qid"`
+	Result   uint8 `json:"result"`
+	Attempts int64 `json:"attempts"`
+	LastTime int64 `json:"lastTime"`
+}
+
+func newExamineProgress(p domain.ExamineProgress) ExamineProgress {
+	return ExamineProgress{
+		Days: slice.Map(p.Days, func(idx int, src domain.ExamineDailyAttempts) ExamineDailyAttempts {
+			return ExamineDailyAttempts{
+				Date:     src.Date,
+				Attempts: src.Attempts,
+			}
+		}),
+		Distribution: slice.Map(p.Distribution, func(idx int, src domain.ExamineResultCount) ExamineResultCount {
+			return ExamineResultCount{
+				Result: src.Result.ToUint8(),
+				Count:  src.Count,
+			}
+		}),
+		Best: slice.Map(p.Best, func(idx int, src domain.ExamineBestResult) ExamineBestResult {
+			return ExamineBestResult{
+				Qid:      src.Qid,
+				Result:   src.Result.ToUint8(),
+				Attempts: src.Attempts,
+				LastTime: src.LastTime,
+			}
+		}),
+	}
+}
+
+// ExamineStreamContent 流式测试中的增量内容
+type ExamineStreamContent struct {
+	Content string `json:"content"`
+}
+
+// ExamineQuota 测试配额，上限为 0 表示不限制，CallsLeft 为 -1 表示不限制
+type ExamineQuota struct {
+	CallsPerDay  int64 `json:"callsPerDay"`
+	CallsToday   int64 `json:"callsToday"`
+	CallsLeft    int64 `json:"callsLeft"`
+	TokensPerDay int64 `json:"tokensPerDay"`
+	TokensToday  int64 `json:"tokensToday"`
+}
+
+func newExamineQuota(q domain.ExamineQuota) ExamineQuota {
+	return ExamineQuota{
+		CallsPerDay:  q.CallsPerDay,
+		CallsToday:   q.CallsToday,
+		CallsLeft:    q.CallsLeft,
+		TokensPerDay: q.TokensPerDay,
+		TokensToday:  q.TokensToday,
 	}
 }
@@ -48,8 +48,9 @@ func NewHandler(intrSvc interactive.Service,
 
 func (h *Handler) PublicRoutes(server *gin.Engine) {
 	// 下次发版要删除这个 pub
-	server.POST("/question/pub/list", ginx.B[Page](h.PubList))
-	server.POST("/question/list", ginx.B[Page](h.PubList))
+	server.POST("/question/pub/list", ginx.B[PubListReq](h.PubList))
+	server.POST("/question/list", ginx.B[PubListReq](h.PubList))
+	server.POST("/question/facets", ginx.W(h.PubLabelFacets))
 }
 
 func (h *Handler) MemberRoutes(server *gin.Engine) {
@@ -64,7 +65,7 @@ func (h *Handler) PubDetail(ctx *ginx.Context,
 		eg      errgroup.Group
 		detail  domain.Question
 		intr    interactive.Interactive
-		examine domain.Result
+		examine domain.ExamineResult
 	)
 	uid := sess.Claims().Uid
 	eg.Go(func() error {
@@ -109,14 +110,14 @@ func (h *Handler) PubDetail(ctx *ginx.Context,
 	}
 
 	que := newQuestion(detail, intr)
-	que.ExamineResult = examine.ToUint8()
+	que.setExamineResult(examine)
 	return ginx.Result{
 		Data: que,
 	}, err
 }
 
-func (h *Handler) PubList(ctx *ginx.Context, req Page) (ginx.Result, error) {
-	data, err := h.svc.PubList(ctx, req.Offset, req.Limit)
+func (h *Handler) PubList(ctx *ginx.Context, req PubListReq) (ginx.Result, error) {
+	data, err := h.svc.PubList(ctx, req.Offset, req.Limit, req.labelFilter())
 	if err != nil {
 		return systemErrorResult, err
 	}
@@ -144,3 +145,14 @@ func (h *Handler) PubList(ctx *ginx.Context, req Page) (ginx.Result, error) {
 		}),
 	}, nil
 }
+
+// PubLabelFacets 八股文每个标签下的题目数量，前端用来展示标签筛选
+func (h *Handler) PubLabelFacets(ctx *ginx.Context) (ginx.Result, error) {
+	facets, err := h.svc.PubLabelFacets(ctx)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: slice.Map(facets, newLabelFacet),
+	}, nil
+}
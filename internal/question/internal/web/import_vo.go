@@ -0,0 +1,80 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+)
+
+// ImportReq 根据文件名的后缀区分格式，.csv 和导出的知识库格式一样，.md 一个文件一道题目
+// DryRun 为 true 的时候只校验，Publish 为 true 的时候直接发布，否则保存为草稿
+// Qsid 大于 0 的时候会把导入的题目加入到这个题集
+type ImportReq struct {
+	Files   []ImportFile `json:"files"`
+	DryRun  bool         `json:"dryRun,omitempty"`
+	Publish bool         `json:"publish,omitempty"`
+	Qsid    int64        `json:"qsid,omitempty"`
+}
+
+type ImportFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+func (req ImportReq) files() []domain.ImportFile {
+	return slice.Map(req.Files, func(idx int, src ImportFile) domain.ImportFile {
+		return domain.ImportFile{
+			Name:    src.Name,
+			Content: src.Content,
+		}
+	})
+}
+
+type ImportReport struct {
+	DryRun   bool         `json:"dryRun"`
+	Imported bool         `json:"imported"`
+	Items    []ImportItem `json:"items"`
+	Errors   []string     `json:"errors,omitempty"`
+}
+
+type ImportItem struct {
+	Source string   `json:"source"`
+	Title  string   `json:"title"`
+	Labels []string `json:"labels,omitempty"`
+	// 新建的题目在真正导入之后才有 Qid
+	Qid int64 `json:"qid,omitempty"`
+	// 1 新建，2 覆盖同名的题目
+	Action uint8    `json:"action"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+func newImportReport(report domain.ImportReport) ImportReport {
+	return ImportReport{
+		DryRun:   report.DryRun,
+		Imported: report.Imported,
+		Errors:   report.Errors,
+		Items: slice.Map(report.Items, func(idx int, src domain.ImportItem) ImportItem {
+			return ImportItem{
+				Source: src.Source,
+				Title:  src.Question.Title,
+				Labels: src.Question.Labels,
+				Qid:    src.Question.Id,
+				Action: src.Action.ToUint8(),
+				Errors: src.Errors,
+			}
+		}),
+	}
+}
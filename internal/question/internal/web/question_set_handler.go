@@ -16,6 +16,7 @@ package web
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ecodeclub/webook/internal/interactive"
 	"golang.org/x/sync/errgroup"
@@ -24,6 +25,7 @@ import (
 	"github.com/ecodeclub/ginx"
 	"github.com/ecodeclub/ginx/session"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/errs"
 	"github.com/ecodeclub/webook/internal/question/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/gotomicro/ego/core/elog"
@@ -54,14 +56,15 @@ func (h *QuestionSetHandler) PublicRoutes(server *gin.Engine) {}
 
 func (h *QuestionSetHandler) PrivateRoutes(server *gin.Engine) {
 	g := server.Group("/question-sets")
-	g.POST("/list", ginx.B[Page](h.ListQuestionSets))
+	g.POST("/list", ginx.B[PubListReq](h.ListQuestionSets))
+	g.POST("/facets", ginx.W(h.LabelFacets))
 	g.POST("/detail", ginx.BS(h.RetrieveQuestionSetDetail))
 	g.POST("/detail/biz", ginx.BS(h.GetDetailByBiz))
 }
 
 // ListQuestionSets 展示个人题集
-func (h *QuestionSetHandler) ListQuestionSets(ctx *ginx.Context, req Page) (ginx.Result, error) {
-	data, err := h.svc.ListDefault(ctx, req.Offset, req.Limit)
+func (h *QuestionSetHandler) ListQuestionSets(ctx *ginx.Context, req PubListReq) (ginx.Result, error) {
+	data, err := h.svc.ListDefault(ctx, req.Offset, req.Limit, req.labelFilter())
 	if err != nil {
 		return systemErrorResult, err
 	}
@@ -91,6 +94,17 @@ func (h *QuestionSetHandler) ListQuestionSets(ctx *ginx.Context, req Page) (ginx
 	}, nil
 }
 
+// LabelFacets 八股文题集每个标签下的题集数量，前端用来展示标签筛选
+func (h *QuestionSetHandler) LabelFacets(ctx *ginx.Context) (ginx.Result, error) {
+	facets, err := h.svc.DefaultLabelFacets(ctx)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: slice.Map(facets, newLabelFacet),
+	}, nil
+}
+
 func (h *QuestionSetHandler) GetDetailByBiz(
 	ctx *ginx.Context,
 	req BizReq, sess session.Session) (ginx.Result, error) {
@@ -106,7 +120,14 @@ func (h *QuestionSetHandler) RetrieveQuestionSetDetail(
 	ctx *ginx.Context,
 	req QuestionSetID, sess session.Session) (ginx.Result, error) {
 
-	data, err := h.svc.Detail(ctx.Request.Context(), req.QSID)
+	data, err := h.svc.PubDetail(ctx.Request.Context(), req.QSID)
+	// 下线或者删除了的题集，对 C 端来说就是不存在
+	if errors.Is(err, service.ErrQuestionSetNotPublished) {
+		return ginx.Result{
+			Code: errs.QuestionSetNotFound.Code,
+			Msg:  errs.QuestionSetNotFound.Msg,
+		}, nil
+	}
 	if err != nil {
 		return systemErrorResult, err
 	}
@@ -158,8 +179,7 @@ func (h *QuestionSetHandler) toQuestionSetVO(
 func (h *QuestionSetHandler) toQuestionVO(questions []domain.Question, results map[int64]domain.ExamineResult) []Question {
 	return slice.Map(questions, func(idx int, src domain.Question) Question {
 		que := newQuestion(src, interactive.Interactive{})
-		res := results[que.Id]
-		que.ExamineResult = res.Result.ToUint8()
+		que.setExamineResult(results[que.Id])
 		return que
 	})
 }
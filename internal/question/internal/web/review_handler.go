@@ -0,0 +1,70 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ginx"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewHandler 根据测试结果安排的复习
+type ReviewHandler struct {
+	svc    service.ReviewService
+	queSvc service.Service
+}
+
+func NewReviewHandler(svc service.ReviewService, queSvc service.Service) *ReviewHandler {
+	return &ReviewHandler{
+		svc:    svc,
+		queSvc: queSvc,
+	}
+}
+
+func (h *ReviewHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/question/review")
+	g.POST("/today", ginx.BS(h.Today))
+}
+
+// Today 今天需要复习的题目
+func (h *ReviewHandler) Today(ctx *ginx.Context, req ReviewTodayReq, sess session.Session) (ginx.Result, error) {
+	reviews, err := h.svc.Today(ctx, sess.Claims().Uid, req.Qsid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	qids := slice.Map(reviews, func(idx int, src domain.Review) int64 {
+		return src.Qid
+	})
+	titles := make(map[int64]string, len(qids))
+	if len(qids) > 0 {
+		ques, err := h.queSvc.GetPubByIDs(ctx, qids)
+		if err != nil {
+			return systemErrorResult, err
+		}
+		for _, que := range ques {
+			titles[que.Id] = que.Title
+		}
+	}
+	return ginx.Result{
+		Data: ReviewList{
+			Reviews: slice.Map(reviews, func(idx int, src domain.Review) Review {
+				return newReview(src, titles[src.Qid])
+			}),
+		},
+	}, nil
+}
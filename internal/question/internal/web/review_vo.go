@@ -0,0 +1,51 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import "github.com/ecodeclub/webook/internal/question/internal/domain"
+
+// ReviewTodayReq Qsid 不为 0 的时候只返回这个题集里面的题目
+type ReviewTodayReq struct {
+	Qsid int64 `json:"qsid,omitempty"`
+}
+
+type ReviewList struct {
+	Reviews []Review `json:"reviews"`
+}
+
+type Review struct {
+	Qid   int64  `json:"qid"`
+	Title string `json:"title"`
+	// 最后一次测试的结果
+	LastResult uint8 `json:"lastResult"`
+	// 连续通过的次数
+	Repetitions int `json:"repetitions"`
+	// 复习的间隔，单位是天
+	Interval   int   `json:"interval"`
+	LastReview int64 `json:"lastReview"`
+	NextReview int64 `json:"nextReview"`
+}
+
+func newReview(r domain.Review, title string) Review {
+	return Review{
+		Qid:         r.Qid,
+		Title:       title,
+		LastResult:  r.LastResult.ToUint8(),
+		Repetitions: r.Repetitions,
+		Interval:    r.Interval,
+		LastReview:  r.LastReview,
+		NextReview:  r.NextReview,
+	}
+}
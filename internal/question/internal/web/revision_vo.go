@@ -0,0 +1,111 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/question/internal/domain"
+)
+
+type RevisionListReq struct {
+	Qid    int64 `json:"qid"`
+	Offset int   `json:"offset,omitempty"`
+	Limit  int   `json:"limit,omitempty"`
+}
+
+type RevisionReq struct {
+	Qid int64 `json:"qid"`
+	Id  int64 `json:"id"`
+}
+
+// RevisionDiffReq 从 From 版本到 To 版本的变化
+type RevisionDiffReq struct {
+	Qid  int64 `json:"qid"`
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// RevisionRestoreReq Publish 为 true 的时候恢复之后直接发布，否则只覆盖草稿
+type RevisionRestoreReq struct {
+	Qid     int64 `json:"qid"`
+	Id      int64 `json:"id"`
+	Publish bool  `json:"publish,omitempty"`
+}
+
+type RevisionList struct {
+	Total     int64      `json:"total"`
+	Revisions []Revision `json:"revisions,omitempty"`
+}
+
+type Revision struct {
+	Id int64 `json:"id"`
+	// 这一次修改的人
+	Uid int64 `json:"uid"`
+	// 1 保存草稿，2 发布
+	Status uint8  `json:"status"`
+	Title  string `json:"title"`
+	Ctime  int64  `json:"ctime"`
+	// 只有详情接口才有
+	Question *Question `json:"question,omitempty"`
+}
+
+func newRevision(rev domain.QuestionRevision) Revision {
+	return Revision{
+		Id:     rev.Id,
+		Uid:    rev.Question.Uid,
+		Status: rev.Question.Status.ToUint8(),
+		Title:  rev.Question.Title,
+		Ctime:  rev.Ctime.UnixMilli(),
+	}
+}
+
+type FieldDiff struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type RevisionDiff struct {
+	Diffs []FieldDiff `json:"diffs,omitempty"`
+}
+
+func newRevisionList(revs []domain.QuestionRevision, total int64) RevisionList {
+	return RevisionList{
+		Total: total,
+		Revisions: slice.Map(revs, func(idx int, src domain.QuestionRevision) Revision {
+			return newRevision(src)
+		}),
+	}
+}
+
+func newRevisionDetail(rev domain.QuestionRevision) Revision {
+	res := newRevision(rev)
+	que := newQuestion(rev.Question, interactive.Interactive{})
+	res.Question = &que
+	return res
+}
+
+func newRevisionDiff(diffs []domain.FieldDiff) RevisionDiff {
+	return RevisionDiff{
+		Diffs: slice.Map(diffs, func(idx int, src domain.FieldDiff) FieldDiff {
+			return FieldDiff{
+				Field: src.Field,
+				Old:   src.Old,
+				New:   src.New,
+			}
+		}),
+	}
+}
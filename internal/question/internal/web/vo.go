@@ -16,6 +16,7 @@ package web
 
 import (
 	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
 )
 
@@ -46,6 +47,15 @@ type Question struct {
 	Interactive Interactive   `json:"interactive"`
 
 	ExamineResult uint8 `json:"examineResult"`
+	// 最近一次测试的得分和改进建议，AI 没有返回结构化结果的时候都是零值
+	ExamineScores      ExamineScores       `json:"examineScores"`
+	ExamineSuggestions []ExamineSuggestion `json:"examineSuggestions,omitempty"`
+}
+
+func (que *Question) setExamineResult(r domain.ExamineResult) {
+	que.ExamineResult = r.Result.ToUint8()
+	que.ExamineScores = newExamineScores(r.Scores)
+	que.ExamineSuggestions = newExamineSuggestions(r.Suggestions)
 }
 
 func (que Question) toDomain() domain.Question {
@@ -126,6 +136,37 @@ type Page struct {
 	Limit  int `json:"limit,omitempty"`
 }
 
+// PubListReq C 端的列表，可以按照标签筛选
+type PubListReq struct {
+	Offset int `json:"offset,omitempty"`
+	Limit  int `json:"limit,omitempty"`
+	// label 模块里面的标签 ID
+	LabelIds []int64 `json:"labelIds,omitempty"`
+	// 0-命中任意一个标签 1-同时命中全部标签
+	LabelMode uint8 `json:"labelMode,omitempty"`
+}
+
+func (r PubListReq) labelFilter() label.Filter {
+	return label.Filter{
+		LabelIds: r.LabelIds,
+		Mode:     label.MatchMode(r.LabelMode),
+	}
+}
+
+type LabelFacet struct {
+	LabelId int64  `json:"labelId"`
+	Name    string `json:"name"`
+	Cnt     int64  `json:"cnt"`
+}
+
+func newLabelFacet(idx int, src label.Facet) LabelFacet {
+	return LabelFacet{
+		LabelId: src.LabelId,
+		Name:    src.Name,
+		Cnt:     src.Cnt,
+	}
+}
+
 type Qid struct {
 	Qid int64 `json:"qid"`
 }
@@ -145,12 +186,14 @@ type QuestionSetID struct {
 }
 
 type QuestionSet struct {
-	Id          int64       `json:"id,omitempty"`
-	Title       string      `json:"title,omitempty"`
-	Description string      `json:"description,omitempty"`
-	Questions   []Question  `json:"questions,omitempty"`
-	Biz         string      `json:"biz"`
-	BizId       int64       `json:"bizId"`
+	Id          int64      `json:"id,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Questions   []Question `json:"questions,omitempty"`
+	Biz         string     `json:"biz"`
+	BizId       int64      `json:"bizId"`
+	// Status 只在管理端的列表里面返回
+	Status      uint8       `json:"status,omitempty"`
 	Utime       int64       `json:"utime,omitempty"`
 	Interactive Interactive `json:"interactive,omitempty"`
 }
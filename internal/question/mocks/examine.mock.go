@@ -0,0 +1,469 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./examine.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./examine.go -destination=../../mocks/examine.mock.go -package=quemocks -typed=true ExamineService
+//
+// Package quemocks is a generated GoMock package.
+package quemocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/ecodeclub/webook/internal/question/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockExamineService is a mock of ExamineService interface.
+type MockExamineService struct {
+	ctrl     *gomock.Controller
+	recorder *MockExamineServiceMockRecorder
+}
+
+// MockExamineServiceMockRecorder is the mock recorder for MockExamineService.
+type MockExamineServiceMockRecorder struct {
+	mock *MockExamineService
+}
+
+// NewMockExamineService creates a new mock instance.
+func NewMockExamineService(ctrl *gomock.Controller) *MockExamineService {
+	mock := &MockExamineService{ctrl: ctrl}
+	mock.recorder = &MockExamineServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExamineService) EXPECT() *MockExamineServiceMockRecorder {
+	return m.recorder
+}
+
+// AsyncResult mocks base method.
+func (m *MockExamineService) AsyncResult(ctx context.Context, uid int64, tid string) (domain.ExamineResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AsyncResult", ctx, uid, tid)
+	ret0, _ := ret[0].(domain.ExamineResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AsyncResult indicates an expected call of AsyncResult.
+func (mr *MockExamineServiceMockRecorder) AsyncResult(ctx, uid, tid any) *ExamineServiceAsyncResultCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AsyncResult", reflect.TypeOf((*MockExamineService)(nil).AsyncResult), ctx, uid, tid)
+	return &ExamineServiceAsyncResultCall{Call: call}
+}
+
+// ExamineServiceAsyncResultCall wrap *gomock.Call
+type ExamineServiceAsyncResultCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceAsyncResultCall) Return(arg0 domain.ExamineResult, arg1 error) *ExamineServiceAsyncResultCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceAsyncResultCall) Do(f func(context.Context, int64, string) (domain.ExamineResult, error)) *ExamineServiceAsyncResultCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceAsyncResultCall) DoAndReturn(f func(context.Context, int64, string) (domain.ExamineResult, error)) *ExamineServiceAsyncResultCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Examine mocks base method.
+func (m *MockExamineService) Examine(ctx context.Context, uid, qid int64, input string) (domain.ExamineResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Examine", ctx, uid, qid, input)
+	ret0, _ := ret[0].(domain.ExamineResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Examine indicates an expected call of Examine.
+func (mr *MockExamineServiceMockRecorder) Examine(ctx, uid, qid, input any) *ExamineServiceExamineCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Examine", reflect.TypeOf((*MockExamineService)(nil).Examine), ctx, uid, qid, input)
+	return &ExamineServiceExamineCall{Call: call}
+}
+
+// ExamineServiceExamineCall wrap *gomock.Call
+type ExamineServiceExamineCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceExamineCall) Return(arg0 domain.ExamineResult, arg1 error) *ExamineServiceExamineCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceExamineCall) Do(f func(context.Context, int64, int64, string) (domain.ExamineResult, error)) *ExamineServiceExamineCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceExamineCall) DoAndReturn(f func(context.Context, int64, int64, string) (domain.ExamineResult, error)) *ExamineServiceExamineCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ExamineAsync mocks base method.
+func (m *MockExamineService) ExamineAsync(ctx context.Context, uid, qid int64, input string) (domain.ExamineResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExamineAsync", ctx, uid, qid, input)
+	ret0, _ := ret[0].(domain.ExamineResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExamineAsync indicates an expected call of ExamineAsync.
+func (mr *MockExamineServiceMockRecorder) ExamineAsync(ctx, uid, qid, input any) *ExamineServiceExamineAsyncCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExamineAsync", reflect.TypeOf((*MockExamineService)(nil).ExamineAsync), ctx, uid, qid, input)
+	return &ExamineServiceExamineAsyncCall{Call: call}
+}
+
+// ExamineServiceExamineAsyncCall wrap *gomock.Call
+type ExamineServiceExamineAsyncCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceExamineAsyncCall) Return(arg0 domain.ExamineResult, arg1 error) *ExamineServiceExamineAsyncCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceExamineAsyncCall) Do(f func(context.Context, int64, int64, string) (domain.ExamineResult, error)) *ExamineServiceExamineAsyncCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceExamineAsyncCall) DoAndReturn(f func(context.Context, int64, int64, string) (domain.ExamineResult, error)) *ExamineServiceExamineAsyncCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetResults mocks base method.
+func (m *MockExamineService) GetResults(ctx context.Context, uid int64, ids []int64) (map[int64]domain.ExamineResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResults", ctx, uid, ids)
+	ret0, _ := ret[0].(map[int64]domain.ExamineResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResults indicates an expected call of GetResults.
+func (mr *MockExamineServiceMockRecorder) GetResults(ctx, uid, ids any) *ExamineServiceGetResultsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResults", reflect.TypeOf((*MockExamineService)(nil).GetResults), ctx, uid, ids)
+	return &ExamineServiceGetResultsCall{Call: call}
+}
+
+// ExamineServiceGetResultsCall wrap *gomock.Call
+type ExamineServiceGetResultsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceGetResultsCall) Return(arg0 map[int64]domain.ExamineResult, arg1 error) *ExamineServiceGetResultsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceGetResultsCall) Do(f func(context.Context, int64, []int64) (map[int64]domain.ExamineResult, error)) *ExamineServiceGetResultsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceGetResultsCall) DoAndReturn(f func(context.Context, int64, []int64) (map[int64]domain.ExamineResult, error)) *ExamineServiceGetResultsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// HandleAsync mocks base method.
+func (m *MockExamineService) HandleAsync(ctx context.Context, uid int64, tid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleAsync", ctx, uid, tid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleAsync indicates an expected call of HandleAsync.
+func (mr *MockExamineServiceMockRecorder) HandleAsync(ctx, uid, tid any) *ExamineServiceHandleAsyncCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleAsync", reflect.TypeOf((*MockExamineService)(nil).HandleAsync), ctx, uid, tid)
+	return &ExamineServiceHandleAsyncCall{Call: call}
+}
+
+// ExamineServiceHandleAsyncCall wrap *gomock.Call
+type ExamineServiceHandleAsyncCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceHandleAsyncCall) Return(arg0 error) *ExamineServiceHandleAsyncCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceHandleAsyncCall) Do(f func(context.Context, int64, string) error) *ExamineServiceHandleAsyncCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceHandleAsyncCall) DoAndReturn(f func(context.Context, int64, string) error) *ExamineServiceHandleAsyncCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// History mocks base method.
+func (m *MockExamineService) History(ctx context.Context, uid, qid int64, offset, limit int) ([]domain.ExamineResult, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, uid, qid, offset, limit)
+	ret0, _ := ret[0].([]domain.ExamineResult)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// History indicates an expected call of History.
+func (mr *MockExamineServiceMockRecorder) History(ctx, uid, qid, offset, limit any) *ExamineServiceHistoryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockExamineService)(nil).History), ctx, uid, qid, offset, limit)
+	return &ExamineServiceHistoryCall{Call: call}
+}
+
+// ExamineServiceHistoryCall wrap *gomock.Call
+type ExamineServiceHistoryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceHistoryCall) Return(arg0 []domain.ExamineResult, arg1 int64, arg2 error) *ExamineServiceHistoryCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceHistoryCall) Do(f func(context.Context, int64, int64, int, int) ([]domain.ExamineResult, int64, error)) *ExamineServiceHistoryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceHistoryCall) DoAndReturn(f func(context.Context, int64, int64, int, int) ([]domain.ExamineResult, int64, error)) *ExamineServiceHistoryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Progress mocks base method.
+func (m *MockExamineService) Progress(ctx context.Context, uid int64, days int) (domain.ExamineProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Progress", ctx, uid, days)
+	ret0, _ := ret[0].(domain.ExamineProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Progress indicates an expected call of Progress.
+func (mr *MockExamineServiceMockRecorder) Progress(ctx, uid, days any) *ExamineServiceProgressCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Progress", reflect.TypeOf((*MockExamineService)(nil).Progress), ctx, uid, days)
+	return &ExamineServiceProgressCall{Call: call}
+}
+
+// ExamineServiceProgressCall wrap *gomock.Call
+type ExamineServiceProgressCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceProgressCall) Return(arg0 domain.ExamineProgress, arg1 error) *ExamineServiceProgressCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceProgressCall) Do(f func(context.Context, int64, int) (domain.ExamineProgress, error)) *ExamineServiceProgressCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceProgressCall) DoAndReturn(f func(context.Context, int64, int) (domain.ExamineProgress, error)) *ExamineServiceProgressCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// QuestionResult mocks base method.
+func (m *MockExamineService) QuestionResult(ctx context.Context, uid, qid int64) (domain.ExamineResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuestionResult", ctx, uid, qid)
+	ret0, _ := ret[0].(domain.ExamineResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuestionResult indicates an expected call of QuestionResult.
+func (mr *MockExamineServiceMockRecorder) QuestionResult(ctx, uid, qid any) *ExamineServiceQuestionResultCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuestionResult", reflect.TypeOf((*MockExamineService)(nil).QuestionResult), ctx, uid, qid)
+	return &ExamineServiceQuestionResultCall{Call: call}
+}
+
+// ExamineServiceQuestionResultCall wrap *gomock.Call
+type ExamineServiceQuestionResultCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceQuestionResultCall) Return(arg0 domain.ExamineResult, arg1 error) *ExamineServiceQuestionResultCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceQuestionResultCall) Do(f func(context.Context, int64, int64) (domain.ExamineResult, error)) *ExamineServiceQuestionResultCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceQuestionResultCall) DoAndReturn(f func(context.Context, int64, int64) (domain.ExamineResult, error)) *ExamineServiceQuestionResultCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Quota mocks base method.
+func (m *MockExamineService) Quota(ctx context.Context, uid int64) (domain.ExamineQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quota", ctx, uid)
+	ret0, _ := ret[0].(domain.ExamineQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quota indicates an expected call of Quota.
+func (mr *MockExamineServiceMockRecorder) Quota(ctx, uid any) *ExamineServiceQuotaCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quota", reflect.TypeOf((*MockExamineService)(nil).Quota), ctx, uid)
+	return &ExamineServiceQuotaCall{Call: call}
+}
+
+// ExamineServiceQuotaCall wrap *gomock.Call
+type ExamineServiceQuotaCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceQuotaCall) Return(arg0 domain.ExamineQuota, arg1 error) *ExamineServiceQuotaCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceQuotaCall) Do(f func(context.Context, int64) (domain.ExamineQuota, error)) *ExamineServiceQuotaCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceQuotaCall) DoAndReturn(f func(context.Context, int64) (domain.ExamineQuota, error)) *ExamineServiceQuotaCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RetryAsync mocks base method.
+func (m *MockExamineService) RetryAsync(ctx context.Context, uid int64, tid string) (domain.ExamineResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryAsync", ctx, uid, tid)
+	ret0, _ := ret[0].(domain.ExamineResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryAsync indicates an expected call of RetryAsync.
+func (mr *MockExamineServiceMockRecorder) RetryAsync(ctx, uid, tid any) *ExamineServiceRetryAsyncCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryAsync", reflect.TypeOf((*MockExamineService)(nil).RetryAsync), ctx, uid, tid)
+	return &ExamineServiceRetryAsyncCall{Call: call}
+}
+
+// ExamineServiceRetryAsyncCall wrap *gomock.Call
+type ExamineServiceRetryAsyncCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceRetryAsyncCall) Return(arg0 domain.ExamineResult, arg1 error) *ExamineServiceRetryAsyncCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceRetryAsyncCall) Do(f func(context.Context, int64, string) (domain.ExamineResult, error)) *ExamineServiceRetryAsyncCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceRetryAsyncCall) DoAndReturn(f func(context.Context, int64, string) (domain.ExamineResult, error)) *ExamineServiceRetryAsyncCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// StreamExamine mocks base method.
+func (m *MockExamineService) StreamExamine(ctx context.Context, uid, qid int64, input string) (<-chan domain.ExamineEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamExamine", ctx, uid, qid, input)
+	ret0, _ := ret[0].(<-chan domain.ExamineEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamExamine indicates an expected call of StreamExamine.
+func (mr *MockExamineServiceMockRecorder) StreamExamine(ctx, uid, qid, input any) *ExamineServiceStreamExamineCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamExamine", reflect.TypeOf((*MockExamineService)(nil).StreamExamine), ctx, uid, qid, input)
+	return &ExamineServiceStreamExamineCall{Call: call}
+}
+
+// ExamineServiceStreamExamineCall wrap *gomock.Call
+type ExamineServiceStreamExamineCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ExamineServiceStreamExamineCall) Return(arg0 <-chan domain.ExamineEvent, arg1 error) *ExamineServiceStreamExamineCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ExamineServiceStreamExamineCall) Do(f func(context.Context, int64, int64, string) (<-chan domain.ExamineEvent, error)) *ExamineServiceStreamExamineCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ExamineServiceStreamExamineCall) DoAndReturn(f func(context.Context, int64, int64, string) (<-chan domain.ExamineEvent, error)) *ExamineServiceStreamExamineCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
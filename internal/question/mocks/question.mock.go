@@ -11,7 +11,9 @@ package quemocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	label "github.com/ecodeclub/webook/internal/label"
 	domain "github.com/ecodeclub/webook/internal/question/internal/domain"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -234,19 +236,97 @@ func (c *ServicePubDetailCall) DoAndReturn(f func(context.Context, int64) (domai
 	return c
 }
 
+// PubDetailByIDs mocks base method.
+func (m *MockService) PubDetailByIDs(ctx context.Context, ids []int64) ([]domain.Question, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubDetailByIDs", ctx, ids)
+	ret0, _ := ret[0].([]domain.Question)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PubDetailByIDs indicates an expected call of PubDetailByIDs.
+func (mr *MockServiceMockRecorder) PubDetailByIDs(ctx, ids any) *ServicePubDetailByIDsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubDetailByIDs", reflect.TypeOf((*MockService)(nil).PubDetailByIDs), ctx, ids)
+	return &ServicePubDetailByIDsCall{Call: call}
+}
+
+// ServicePubDetailByIDsCall wrap *gomock.Call
+type ServicePubDetailByIDsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServicePubDetailByIDsCall) Return(arg0 []domain.Question, arg1 error) *ServicePubDetailByIDsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServicePubDetailByIDsCall) Do(f func(context.Context, []int64) ([]domain.Question, error)) *ServicePubDetailByIDsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServicePubDetailByIDsCall) DoAndReturn(f func(context.Context, []int64) ([]domain.Question, error)) *ServicePubDetailByIDsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PubLabelFacets mocks base method.
+func (m *MockService) PubLabelFacets(ctx context.Context) ([]label.Facet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubLabelFacets", ctx)
+	ret0, _ := ret[0].([]label.Facet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PubLabelFacets indicates an expected call of PubLabelFacets.
+func (mr *MockServiceMockRecorder) PubLabelFacets(ctx any) *ServicePubLabelFacetsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubLabelFacets", reflect.TypeOf((*MockService)(nil).PubLabelFacets), ctx)
+	return &ServicePubLabelFacetsCall{Call: call}
+}
+
+// ServicePubLabelFacetsCall wrap *gomock.Call
+type ServicePubLabelFacetsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServicePubLabelFacetsCall) Return(arg0 []label.Facet, arg1 error) *ServicePubLabelFacetsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServicePubLabelFacetsCall) Do(f func(context.Context) ([]label.Facet, error)) *ServicePubLabelFacetsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServicePubLabelFacetsCall) DoAndReturn(f func(context.Context) ([]label.Facet, error)) *ServicePubLabelFacetsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // PubList mocks base method.
-func (m *MockService) PubList(ctx context.Context, offset, limit int) ([]domain.Question, error) {
+func (m *MockService) PubList(ctx context.Context, offset, limit int, filter label.Filter) ([]domain.Question, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PubList", ctx, offset, limit)
+	ret := m.ctrl.Call(m, "PubList", ctx, offset, limit, filter)
 	ret0, _ := ret[0].([]domain.Question)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PubList indicates an expected call of PubList.
-func (mr *MockServiceMockRecorder) PubList(ctx, offset, limit any) *ServicePubListCall {
+func (mr *MockServiceMockRecorder) PubList(ctx, offset, limit, filter any) *ServicePubListCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubList", reflect.TypeOf((*MockService)(nil).PubList), ctx, offset, limit)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubList", reflect.TypeOf((*MockService)(nil).PubList), ctx, offset, limit, filter)
 	return &ServicePubListCall{Call: call}
 }
 
@@ -262,13 +342,52 @@ func (c *ServicePubListCall) Return(arg0 []domain.Question, arg1 error) *Service
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *ServicePubListCall) Do(f func(context.Context, int, int) ([]domain.Question, error)) *ServicePubListCall {
+func (c *ServicePubListCall) Do(f func(context.Context, int, int, label.Filter) ([]domain.Question, error)) *ServicePubListCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *ServicePubListCall) DoAndReturn(f func(context.Context, int, int) ([]domain.Question, error)) *ServicePubListCall {
+func (c *ServicePubListCall) DoAndReturn(f func(context.Context, int, int, label.Filter) ([]domain.Question, error)) *ServicePubListCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PubListByUtime mocks base method.
+func (m *MockService) PubListByUtime(ctx context.Context, utime time.Time, id int64, end time.Time, limit int) ([]domain.Question, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubListByUtime", ctx, utime, id, end, limit)
+	ret0, _ := ret[0].([]domain.Question)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PubListByUtime indicates an expected call of PubListByUtime.
+func (mr *MockServiceMockRecorder) PubListByUtime(ctx, utime, id, end, limit any) *ServicePubListByUtimeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubListByUtime", reflect.TypeOf((*MockService)(nil).PubListByUtime), ctx, utime, id, end, limit)
+	return &ServicePubListByUtimeCall{Call: call}
+}
+
+// ServicePubListByUtimeCall wrap *gomock.Call
+type ServicePubListByUtimeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServicePubListByUtimeCall) Return(arg0 []domain.Question, arg1 error) *ServicePubListByUtimeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServicePubListByUtimeCall) Do(f func(context.Context, time.Time, int64, time.Time, int) ([]domain.Question, error)) *ServicePubListByUtimeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServicePubListByUtimeCall) DoAndReturn(f func(context.Context, time.Time, int64, time.Time, int) ([]domain.Question, error)) *ServicePubListByUtimeCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -350,3 +469,41 @@ func (c *ServiceSaveCall) DoAndReturn(f func(context.Context, *domain.Question)
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// SyncLabels mocks base method.
+func (m *MockService) SyncLabels(ctx context.Context, qid int64, labels []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncLabels", ctx, qid, labels)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncLabels indicates an expected call of SyncLabels.
+func (mr *MockServiceMockRecorder) SyncLabels(ctx, qid, labels any) *ServiceSyncLabelsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncLabels", reflect.TypeOf((*MockService)(nil).SyncLabels), ctx, qid, labels)
+	return &ServiceSyncLabelsCall{Call: call}
+}
+
+// ServiceSyncLabelsCall wrap *gomock.Call
+type ServiceSyncLabelsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ServiceSyncLabelsCall) Return(arg0 error) *ServiceSyncLabelsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ServiceSyncLabelsCall) Do(f func(context.Context, int64, []string) error) *ServiceSyncLabelsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ServiceSyncLabelsCall) DoAndReturn(f func(context.Context, int64, []string) error) *ServiceSyncLabelsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
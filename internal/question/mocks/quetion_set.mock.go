@@ -11,7 +11,9 @@ package quemocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	label "github.com/ecodeclub/webook/internal/label"
 	domain "github.com/ecodeclub/webook/internal/question/internal/domain"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -39,6 +41,83 @@ func (m *MockQuestionSetService) EXPECT() *MockQuestionSetServiceMockRecorder {
 	return m.recorder
 }
 
+// DefaultLabelFacets mocks base method.
+func (m *MockQuestionSetService) DefaultLabelFacets(ctx context.Context) ([]label.Facet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DefaultLabelFacets", ctx)
+	ret0, _ := ret[0].([]label.Facet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DefaultLabelFacets indicates an expected call of DefaultLabelFacets.
+func (mr *MockQuestionSetServiceMockRecorder) DefaultLabelFacets(ctx any) *QuestionSetServiceDefaultLabelFacetsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DefaultLabelFacets", reflect.TypeOf((*MockQuestionSetService)(nil).DefaultLabelFacets), ctx)
+	return &QuestionSetServiceDefaultLabelFacetsCall{Call: call}
+}
+
+// QuestionSetServiceDefaultLabelFacetsCall wrap *gomock.Call
+type QuestionSetServiceDefaultLabelFacetsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QuestionSetServiceDefaultLabelFacetsCall) Return(arg0 []label.Facet, arg1 error) *QuestionSetServiceDefaultLabelFacetsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QuestionSetServiceDefaultLabelFacetsCall) Do(f func(context.Context) ([]label.Facet, error)) *QuestionSetServiceDefaultLabelFacetsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QuestionSetServiceDefaultLabelFacetsCall) DoAndReturn(f func(context.Context) ([]label.Facet, error)) *QuestionSetServiceDefaultLabelFacetsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Delete mocks base method.
+func (m *MockQuestionSetService) Delete(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockQuestionSetServiceMockRecorder) Delete(ctx, id any) *QuestionSetServiceDeleteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockQuestionSetService)(nil).Delete), ctx, id)
+	return &QuestionSetServiceDeleteCall{Call: call}
+}
+
+// QuestionSetServiceDeleteCall wrap *gomock.Call
+type QuestionSetServiceDeleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QuestionSetServiceDeleteCall) Return(arg0 error) *QuestionSetServiceDeleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QuestionSetServiceDeleteCall) Do(f func(context.Context, int64) error) *QuestionSetServiceDeleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QuestionSetServiceDeleteCall) DoAndReturn(f func(context.Context, int64) error) *QuestionSetServiceDeleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Detail mocks base method.
 func (m *MockQuestionSetService) Detail(ctx context.Context, id int64) (domain.QuestionSet, error) {
 	m.ctrl.T.Helper()
@@ -197,18 +276,18 @@ func (c *QuestionSetServiceListCall) DoAndReturn(f func(context.Context, int, in
 }
 
 // ListDefault mocks base method.
-func (m *MockQuestionSetService) ListDefault(ctx context.Context, offset, limit int) ([]domain.QuestionSet, error) {
+func (m *MockQuestionSetService) ListDefault(ctx context.Context, offset, limit int, filter label.Filter) ([]domain.QuestionSet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListDefault", ctx, offset, limit)
+	ret := m.ctrl.Call(m, "ListDefault", ctx, offset, limit, filter)
 	ret0, _ := ret[0].([]domain.QuestionSet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListDefault indicates an expected call of ListDefault.
-func (mr *MockQuestionSetServiceMockRecorder) ListDefault(ctx, offset, limit any) *QuestionSetServiceListDefaultCall {
+func (mr *MockQuestionSetServiceMockRecorder) ListDefault(ctx, offset, limit, filter any) *QuestionSetServiceListDefaultCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDefault", reflect.TypeOf((*MockQuestionSetService)(nil).ListDefault), ctx, offset, limit)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDefault", reflect.TypeOf((*MockQuestionSetService)(nil).ListDefault), ctx, offset, limit, filter)
 	return &QuestionSetServiceListDefaultCall{Call: call}
 }
 
@@ -224,13 +303,167 @@ func (c *QuestionSetServiceListDefaultCall) Return(arg0 []domain.QuestionSet, ar
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *QuestionSetServiceListDefaultCall) Do(f func(context.Context, int, int) ([]domain.QuestionSet, error)) *QuestionSetServiceListDefaultCall {
+func (c *QuestionSetServiceListDefaultCall) Do(f func(context.Context, int, int, label.Filter) ([]domain.QuestionSet, error)) *QuestionSetServiceListDefaultCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QuestionSetServiceListDefaultCall) DoAndReturn(f func(context.Context, int, int, label.Filter) ([]domain.QuestionSet, error)) *QuestionSetServiceListDefaultCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PubDetail mocks base method.
+func (m *MockQuestionSetService) PubDetail(ctx context.Context, id int64) (domain.QuestionSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubDetail", ctx, id)
+	ret0, _ := ret[0].(domain.QuestionSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PubDetail indicates an expected call of PubDetail.
+func (mr *MockQuestionSetServiceMockRecorder) PubDetail(ctx, id any) *QuestionSetServicePubDetailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubDetail", reflect.TypeOf((*MockQuestionSetService)(nil).PubDetail), ctx, id)
+	return &QuestionSetServicePubDetailCall{Call: call}
+}
+
+// QuestionSetServicePubDetailCall wrap *gomock.Call
+type QuestionSetServicePubDetailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QuestionSetServicePubDetailCall) Return(arg0 domain.QuestionSet, arg1 error) *QuestionSetServicePubDetailCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QuestionSetServicePubDetailCall) Do(f func(context.Context, int64) (domain.QuestionSet, error)) *QuestionSetServicePubDetailCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *QuestionSetServiceListDefaultCall) DoAndReturn(f func(context.Context, int, int) ([]domain.QuestionSet, error)) *QuestionSetServiceListDefaultCall {
+func (c *QuestionSetServicePubDetailCall) DoAndReturn(f func(context.Context, int64) (domain.QuestionSet, error)) *QuestionSetServicePubDetailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Publish mocks base method.
+func (m *MockQuestionSetService) Publish(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockQuestionSetServiceMockRecorder) Publish(ctx, id any) *QuestionSetServicePublishCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockQuestionSetService)(nil).Publish), ctx, id)
+	return &QuestionSetServicePublishCall{Call: call}
+}
+
+// QuestionSetServicePublishCall wrap *gomock.Call
+type QuestionSetServicePublishCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QuestionSetServicePublishCall) Return(arg0 error) *QuestionSetServicePublishCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QuestionSetServicePublishCall) Do(f func(context.Context, int64) error) *QuestionSetServicePublishCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QuestionSetServicePublishCall) DoAndReturn(f func(context.Context, int64) error) *QuestionSetServicePublishCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PurgeTrash mocks base method.
+func (m *MockQuestionSetService) PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, before, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockQuestionSetServiceMockRecorder) PurgeTrash(ctx, before, limit any) *QuestionSetServicePurgeTrashCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockQuestionSetService)(nil).PurgeTrash), ctx, before, limit)
+	return &QuestionSetServicePurgeTrashCall{Call: call}
+}
+
+// QuestionSetServicePurgeTrashCall wrap *gomock.Call
+type QuestionSetServicePurgeTrashCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QuestionSetServicePurgeTrashCall) Return(arg0 int, arg1 error) *QuestionSetServicePurgeTrashCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QuestionSetServicePurgeTrashCall) Do(f func(context.Context, time.Time, int) (int, error)) *QuestionSetServicePurgeTrashCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QuestionSetServicePurgeTrashCall) DoAndReturn(f func(context.Context, time.Time, int) (int, error)) *QuestionSetServicePurgeTrashCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Restore mocks base method.
+func (m *MockQuestionSetService) Restore(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockQuestionSetServiceMockRecorder) Restore(ctx, id any) *QuestionSetServiceRestoreCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockQuestionSetService)(nil).Restore), ctx, id)
+	return &QuestionSetServiceRestoreCall{Call: call}
+}
+
+// QuestionSetServiceRestoreCall wrap *gomock.Call
+type QuestionSetServiceRestoreCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QuestionSetServiceRestoreCall) Return(arg0 error) *QuestionSetServiceRestoreCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QuestionSetServiceRestoreCall) Do(f func(context.Context, int64) error) *QuestionSetServiceRestoreCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QuestionSetServiceRestoreCall) DoAndReturn(f func(context.Context, int64) error) *QuestionSetServiceRestoreCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -274,6 +507,84 @@ func (c *QuestionSetServiceSaveCall) DoAndReturn(f func(context.Context, domain.
 	return c
 }
 
+// TrashList mocks base method.
+func (m *MockQuestionSetService) TrashList(ctx context.Context, offset, limit int) ([]domain.QuestionSet, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrashList", ctx, offset, limit)
+	ret0, _ := ret[0].([]domain.QuestionSet)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TrashList indicates an expected call of TrashList.
+func (mr *MockQuestionSetServiceMockRecorder) TrashList(ctx, offset, limit any) *QuestionSetServiceTrashListCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrashList", reflect.TypeOf((*MockQuestionSetService)(nil).TrashList), ctx, offset, limit)
+	return &QuestionSetServiceTrashListCall{Call: call}
+}
+
+// QuestionSetServiceTrashListCall wrap *gomock.Call
+type QuestionSetServiceTrashListCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QuestionSetServiceTrashListCall) Return(arg0 []domain.QuestionSet, arg1 int64, arg2 error) *QuestionSetServiceTrashListCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QuestionSetServiceTrashListCall) Do(f func(context.Context, int, int) ([]domain.QuestionSet, int64, error)) *QuestionSetServiceTrashListCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QuestionSetServiceTrashListCall) DoAndReturn(f func(context.Context, int, int) ([]domain.QuestionSet, int64, error)) *QuestionSetServiceTrashListCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Unpublish mocks base method.
+func (m *MockQuestionSetService) Unpublish(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unpublish", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unpublish indicates an expected call of Unpublish.
+func (mr *MockQuestionSetServiceMockRecorder) Unpublish(ctx, id any) *QuestionSetServiceUnpublishCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unpublish", reflect.TypeOf((*MockQuestionSetService)(nil).Unpublish), ctx, id)
+	return &QuestionSetServiceUnpublishCall{Call: call}
+}
+
+// QuestionSetServiceUnpublishCall wrap *gomock.Call
+type QuestionSetServiceUnpublishCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QuestionSetServiceUnpublishCall) Return(arg0 error) *QuestionSetServiceUnpublishCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QuestionSetServiceUnpublishCall) Do(f func(context.Context, int64) error) *QuestionSetServiceUnpublishCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QuestionSetServiceUnpublishCall) DoAndReturn(f func(context.Context, int64) error) *QuestionSetServiceUnpublishCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdateQuestions mocks base method.
 func (m *MockQuestionSetService) UpdateQuestions(ctx context.Context, set domain.QuestionSet) error {
 	m.ctrl.T.Helper()
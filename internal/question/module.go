@@ -22,6 +22,12 @@ type Module struct {
 	Hdl         *Handler
 	QsHdl       *QuestionSetHandler
 	ExamineHdl  *ExamineHandler
+	ExamineSvc  ExamineService
+	ReviewHdl   *ReviewHandler
+	ExamHdl     *ExamHandler
 
 	KnowledgeJobStarter *KnowledgeJobStarter
+	SyncLabelsJob       *SyncLabelsJob
+	PurgeSetTrashJob    *PurgeSetTrashJob
+	ExamineConsumer     *ExamineConsumer
 }
@@ -16,6 +16,7 @@ package baguwen
 
 import (
 	"github.com/ecodeclub/webook/internal/question/internal/domain"
+	"github.com/ecodeclub/webook/internal/question/internal/event/consumer"
 	"github.com/ecodeclub/webook/internal/question/internal/job"
 	"github.com/ecodeclub/webook/internal/question/internal/service"
 	"github.com/ecodeclub/webook/internal/question/internal/web"
@@ -26,10 +27,17 @@ type AdminQuestionSetHandler = web.AdminQuestionSetHandler
 type Handler = web.Handler
 type QuestionSetHandler = web.QuestionSetHandler
 type ExamineHandler = web.ExamineHandler
+type ReviewHandler = web.ReviewHandler
+type ExamHandler = web.ExamHandler
 
 type Service = service.Service
 type QuestionSetService = service.QuestionSetService
+type ExamineService = service.ExamineService
 type Question = domain.Question
 type QuestionSet = domain.QuestionSet
+type ExamineResult = domain.ExamineResult
 
 type KnowledgeJobStarter = job.KnowledgeJobStarter
+type SyncLabelsJob = job.SyncLabelsJob
+type PurgeSetTrashJob = job.PurgeSetTrashJob
+type ExamineConsumer = consumer.ExamineConsumer
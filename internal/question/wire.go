@@ -17,9 +17,13 @@
 package baguwen
 
 import (
+	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/ecodeclub/webook/internal/ai"
+	"github.com/ecodeclub/webook/internal/label"
 
 	"github.com/gotomicro/ego/core/econf"
 
@@ -30,8 +34,10 @@ import (
 	"github.com/ecodeclub/webook/internal/interactive"
 
 	"github.com/ecodeclub/webook/internal/question/internal/event"
+	"github.com/ecodeclub/webook/internal/question/internal/event/consumer"
 
 	"github.com/ecodeclub/ecache"
+	"github.com/ecodeclub/ecache/memory/lru"
 	"github.com/ecodeclub/mq-api"
 
 	"github.com/ecodeclub/webook/internal/question/internal/repository"
@@ -41,42 +47,79 @@ import (
 	"github.com/ecodeclub/webook/internal/question/internal/web"
 	"github.com/ego-component/egorm"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
 var ExamineHandlerSet = wire.NewSet(
 	web.NewExamineHandler,
 	service.NewLLMExamineService,
-	repository.NewCachedExamineRepository,
+	event.NewExamineEventProducer,
+	event.NewExamineResultEventProducer,
+	repository.NewExamineRepository,
 	dao.NewGORMExamineDAO)
 
+var ReviewHandlerSet = wire.NewSet(
+	web.NewReviewHandler,
+	InitReviewService,
+	repository.NewReviewRepository,
+	dao.NewGORMReviewDAO)
+
+var ExamHandlerSet = wire.NewSet(
+	web.NewExamHandler,
+	service.NewExamService,
+	repository.NewExamRepository,
+	dao.NewGORMExamDAO)
+
+var RevisionServiceSet = wire.NewSet(
+	service.NewRevisionService,
+	repository.NewRevisionRepository,
+	dao.NewGORMRevisionDAO)
+
+var KnowledgeExportSet = wire.NewSet(
+	service.NewKnowledgeExportService,
+	repository.NewKnowledgeExportRepository,
+	dao.NewGORMKnowledgeExportDAO)
+
 func InitModule(db *egorm.Component,
 	intrModule *interactive.Module,
 	ec ecache.Cache,
+	cmd redis.Cmdable,
 	perm *permission.Module,
 	aiModule *ai.Module,
+	labelModule *label.Module,
 	q mq.MQ) (*Module, error) {
 	wire.Build(InitQuestionDAO,
-		cache.NewQuestionECache,
+		InitQuestionCache,
 		repository.NewCacheRepository,
 		event.NewSyncEventProducer,
 		event.NewInteractiveEventProducer,
 		service.NewService,
 		web.NewHandler,
 		web.NewAdminHandler,
+		service.NewLLMAnswerDraftService,
+		service.NewImportService,
+		RevisionServiceSet,
 		web.NewAdminQuestionSetHandler,
 
 		ExamineHandlerSet,
+		ReviewHandlerSet,
+		ExamHandlerSet,
+		KnowledgeExportSet,
 
 		InitQuestionSetDAO,
 		repository.NewQuestionSetRepository,
 		service.NewQuestionSetService,
 		web.NewQuestionSetHandler,
 		initKnowledgeStarter,
+		job.NewSyncLabelsJob,
+		InitPurgeSetTrashJob,
+		initExamineConsumer,
 
 		wire.FieldsOf(new(*interactive.Module), "Svc"),
 		wire.FieldsOf(new(*permission.Module), "Svc"),
-		wire.FieldsOf(new(*ai.Module), "Svc"),
+		wire.FieldsOf(new(*ai.Module), "Svc", "QuotaSvc"),
+		wire.FieldsOf(new(*label.Module), "Svc"),
 
 		wire.Struct(new(Module), "*"),
 	)
@@ -85,9 +128,57 @@ func InitModule(db *egorm.Component,
 
 var daoOnce = sync.Once{}
 
-func initKnowledgeStarter(svc service.Service) *job.KnowledgeJobStarter {
+const defaultReviewDailyLimit = 20
+
+// initKnowledgeStarter 读取 job.genKnowledge，format 默认是 csv
+func initKnowledgeStarter(svc service.Service, runSvc service.KnowledgeExportService) *job.KnowledgeJobStarter {
 	baseDir := econf.GetString("job.genKnowledge.baseDir")
-	return job.NewKnowledgeJobStarter(svc, baseDir)
+	format := econf.GetString("job.genKnowledge.format")
+	if format == "" {
+		format = job.KnowledgeFormatCSV
+	}
+	return job.NewKnowledgeJobStarter(svc, runSvc, baseDir, format)
+}
+
+// InitPurgeSetTrashJob 回收站里面的题集保留 30 天
+func InitPurgeSetTrashJob(svc service.QuestionSetService) *PurgeSetTrashJob {
+	return job.NewPurgeSetTrashJob(svc, 30*24*time.Hour, 100)
+}
+
+func initExamineConsumer(svc service.ExamineService, q mq.MQ) *consumer.ExamineConsumer {
+	c, err := consumer.NewExamineConsumer(svc, q)
+	if err != nil {
+		panic(err)
+	}
+	c.Start(context.Background())
+	return c
+}
+
+// InitReviewService 读取 question.review.dailyLimit，每天最多复习多少道题目，默认 20 道
+func InitReviewService(repo repository.ReviewRepository,
+	setRepo repository.QuestionSetRepository) service.ReviewService {
+	dailyLimit := econf.GetInt("question.review.dailyLimit")
+	if dailyLimit <= 0 {
+		dailyLimit = defaultReviewDailyLimit
+	}
+	return service.NewReviewService(repo, setRepo, dailyLimit)
+}
+
+// InitQuestionCache 读取 question.cache.local，capacity 为 0 的时候不使用本地缓存
+func InitQuestionCache(ec ecache.Cache, cmd redis.Cmdable) cache.QuestionCache {
+	type Config struct {
+		Capacity   int           `yaml:"capacity"`
+		Expiration time.Duration `yaml:"expiration"`
+	}
+	var cfg Config
+	err := econf.UnmarshalKey("question.cache.local", &cfg)
+	if err != nil && !errors.Is(err, econf.ErrInvalidKey) {
+		panic(err)
+	}
+	if cfg.Capacity <= 0 {
+		return cache.NewQuestionECache(ec, cmd)
+	}
+	return cache.NewQuestionECacheWithLocal(ec, cmd, lru.NewCache(cfg.Capacity), cfg.Expiration)
 }
 
 func InitTableOnce(db *gorm.DB) {
@@ -7,14 +7,20 @@
 package baguwen
 
 import (
+	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/ecodeclub/ecache"
+	"github.com/ecodeclub/ecache/memory/lru"
 	"github.com/ecodeclub/mq-api"
 	"github.com/ecodeclub/webook/internal/ai"
 	"github.com/ecodeclub/webook/internal/interactive"
+	"github.com/ecodeclub/webook/internal/label"
 	"github.com/ecodeclub/webook/internal/permission"
 	"github.com/ecodeclub/webook/internal/question/internal/event"
+	"github.com/ecodeclub/webook/internal/question/internal/event/consumer"
 	"github.com/ecodeclub/webook/internal/question/internal/job"
 	"github.com/ecodeclub/webook/internal/question/internal/repository"
 	"github.com/ecodeclub/webook/internal/question/internal/repository/cache"
@@ -24,14 +30,15 @@ import (
 	"github.com/ego-component/egorm"
 	"github.com/google/wire"
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
 // Injectors from wire.go:
 
-func InitModule(db *gorm.DB, intrModule *interactive.Module, ec ecache.Cache, perm *permission.Module, aiModule *ai.Module, q mq.MQ) (*Module, error) {
+func InitModule(db *gorm.DB, intrModule *interactive.Module, ec ecache.Cache, cmd redis.Cmdable, perm *permission.Module, aiModule *ai.Module, labelModule *label.Module, q mq.MQ) (*Module, error) {
 	questionDAO := InitQuestionDAO(db)
-	questionCache := cache.NewQuestionECache(ec)
+	questionCache := InitQuestionCache(ec, cmd)
 	repositoryRepository := repository.NewCacheRepository(questionDAO, questionCache)
 	syncDataToSearchEventProducer, err := event.NewSyncEventProducer(q)
 	if err != nil {
@@ -41,22 +48,51 @@ func InitModule(db *gorm.DB, intrModule *interactive.Module, ec ecache.Cache, pe
 	if err != nil {
 		return nil, err
 	}
-	serviceService := service.NewService(repositoryRepository, syncDataToSearchEventProducer, interactiveEventProducer)
+	labelService := labelModule.Svc
+	serviceService := service.NewService(repositoryRepository, labelService, syncDataToSearchEventProducer, interactiveEventProducer)
 	questionSetDAO := InitQuestionSetDAO(db)
-	questionSetRepository := repository.NewQuestionSetRepository(questionSetDAO)
-	questionSetService := service.NewQuestionSetService(questionSetRepository, interactiveEventProducer, syncDataToSearchEventProducer)
-	adminHandler := web.NewAdminHandler(serviceService)
+	questionSetRepository := repository.NewQuestionSetRepository(questionSetDAO, questionCache)
+	questionSetService := service.NewQuestionSetService(questionSetRepository, labelService, interactiveEventProducer, syncDataToSearchEventProducer)
+	llmService := aiModule.Svc
+	answerDraftService := service.NewLLMAnswerDraftService(llmService)
+	importService := service.NewImportService(serviceService, repositoryRepository, questionSetRepository, questionSetService)
+	revisionDAO := dao.NewGORMRevisionDAO(db)
+	revisionRepository := repository.NewRevisionRepository(revisionDAO)
+	revisionService := service.NewRevisionService(serviceService, revisionRepository)
+	adminHandler := web.NewAdminHandler(serviceService, answerDraftService, importService, revisionService)
 	adminQuestionSetHandler := web.NewAdminQuestionSetHandler(questionSetService)
 	service2 := intrModule.Svc
 	examineDAO := dao.NewGORMExamineDAO(db)
-	examineRepository := repository.NewCachedExamineRepository(examineDAO)
-	llmService := aiModule.Svc
-	examineService := service.NewLLMExamineService(repositoryRepository, examineRepository, llmService)
+	examineRepository := repository.NewExamineRepository(examineDAO)
+	quotaService := aiModule.QuotaSvc
+	examineEventProducer, err := event.NewExamineEventProducer(q)
+	if err != nil {
+		return nil, err
+	}
+	examineResultEventProducer, err := event.NewExamineResultEventProducer(q)
+	if err != nil {
+		return nil, err
+	}
+	reviewDAO := dao.NewGORMReviewDAO(db)
+	reviewRepository := repository.NewReviewRepository(reviewDAO)
+	reviewService := InitReviewService(reviewRepository, questionSetRepository)
+	examineService := service.NewLLMExamineService(repositoryRepository, examineRepository, llmService, quotaService, examineEventProducer, examineResultEventProducer, reviewService)
 	service3 := perm.Svc
 	handler := web.NewHandler(service2, examineService, service3, serviceService)
 	questionSetHandler := web.NewQuestionSetHandler(questionSetService, examineService, service2)
 	examineHandler := web.NewExamineHandler(examineService)
-	knowledgeJobStarter := initKnowledgeStarter(serviceService)
+	reviewHandler := web.NewReviewHandler(reviewService, serviceService)
+	examDAO := dao.NewGORMExamDAO(db)
+	examRepository := repository.NewExamRepository(examDAO)
+	examService := service.NewExamService(examRepository, questionSetRepository, repositoryRepository, examineService)
+	examHandler := web.NewExamHandler(examService)
+	knowledgeExportDAO := dao.NewGORMKnowledgeExportDAO(db)
+	knowledgeExportRepository := repository.NewKnowledgeExportRepository(knowledgeExportDAO)
+	knowledgeExportService := service.NewKnowledgeExportService(knowledgeExportRepository)
+	knowledgeJobStarter := initKnowledgeStarter(serviceService, knowledgeExportService)
+	syncLabelsJob := job.NewSyncLabelsJob(serviceService)
+	purgeSetTrashJob := InitPurgeSetTrashJob(questionSetService)
+	examineConsumer := initExamineConsumer(examineService, q)
 	module := &Module{
 		Svc:                 serviceService,
 		SetSvc:              questionSetService,
@@ -65,20 +101,82 @@ func InitModule(db *gorm.DB, intrModule *interactive.Module, ec ecache.Cache, pe
 		Hdl:                 handler,
 		QsHdl:               questionSetHandler,
 		ExamineHdl:          examineHandler,
+		ExamineSvc:          examineService,
+		ReviewHdl:           reviewHandler,
+		ExamHdl:             examHandler,
 		KnowledgeJobStarter: knowledgeJobStarter,
+		SyncLabelsJob:       syncLabelsJob,
+		PurgeSetTrashJob:    purgeSetTrashJob,
+		ExamineConsumer:     examineConsumer,
 	}
 	return module, nil
 }
 
 // wire.go:
 
-var ExamineHandlerSet = wire.NewSet(web.NewExamineHandler, service.NewLLMExamineService, repository.NewCachedExamineRepository, dao.NewGORMExamineDAO)
+var ExamineHandlerSet = wire.NewSet(web.NewExamineHandler, service.NewLLMExamineService, event.NewExamineEventProducer, event.NewExamineResultEventProducer, repository.NewExamineRepository, dao.NewGORMExamineDAO)
+
+var ReviewHandlerSet = wire.NewSet(web.NewReviewHandler, InitReviewService, repository.NewReviewRepository, dao.NewGORMReviewDAO)
+
+var ExamHandlerSet = wire.NewSet(web.NewExamHandler, service.NewExamService, repository.NewExamRepository, dao.NewGORMExamDAO)
+
+var RevisionServiceSet = wire.NewSet(service.NewRevisionService, repository.NewRevisionRepository, dao.NewGORMRevisionDAO)
+
+var KnowledgeExportSet = wire.NewSet(service.NewKnowledgeExportService, repository.NewKnowledgeExportRepository, dao.NewGORMKnowledgeExportDAO)
 
 var daoOnce = sync.Once{}
 
-func initKnowledgeStarter(svc service.Service) *job.KnowledgeJobStarter {
+const defaultReviewDailyLimit = 20
+
+// initKnowledgeStarter 读取 job.genKnowledge，format 默认是 csv
+func initKnowledgeStarter(svc service.Service, runSvc service.KnowledgeExportService) *job.KnowledgeJobStarter {
 	baseDir := econf.GetString("job.genKnowledge.baseDir")
-	return job.NewKnowledgeJobStarter(svc, baseDir)
+	format := econf.GetString("job.genKnowledge.format")
+	if format == "" {
+		format = job.KnowledgeFormatCSV
+	}
+	return job.NewKnowledgeJobStarter(svc, runSvc, baseDir, format)
+}
+
+// InitPurgeSetTrashJob 回收站里面的题集保留 30 天
+func InitPurgeSetTrashJob(svc service.QuestionSetService) *PurgeSetTrashJob {
+	return job.NewPurgeSetTrashJob(svc, 30*24*time.Hour, 100)
+}
+
+func initExamineConsumer(svc service.ExamineService, q mq.MQ) *consumer.ExamineConsumer {
+	c, err := consumer.NewExamineConsumer(svc, q)
+	if err != nil {
+		panic(err)
+	}
+	c.Start(context.Background())
+	return c
+}
+
+// InitReviewService 读取 question.review.dailyLimit，每天最多复习多少道题目，默认 20 道
+func InitReviewService(repo repository.ReviewRepository,
+	setRepo repository.QuestionSetRepository) service.ReviewService {
+	dailyLimit := econf.GetInt("question.review.dailyLimit")
+	if dailyLimit <= 0 {
+		dailyLimit = defaultReviewDailyLimit
+	}
+	return service.NewReviewService(repo, setRepo, dailyLimit)
+}
+
+// InitQuestionCache 读取 question.cache.local，capacity 为 0 的时候不使用本地缓存
+func InitQuestionCache(ec ecache.Cache, cmd redis.Cmdable) cache.QuestionCache {
+	type Config struct {
+		Capacity   int           `yaml:"capacity"`
+		Expiration time.Duration `yaml:"expiration"`
+	}
+	var cfg Config
+	err := econf.UnmarshalKey("question.cache.local", &cfg)
+	if err != nil && !errors.Is(err, econf.ErrInvalidKey) {
+		panic(err)
+	}
+	if cfg.Capacity <= 0 {
+		return cache.NewQuestionECache(ec, cmd)
+	}
+	return cache.NewQuestionECacheWithLocal(ec, cmd, lru.NewCache(cfg.Capacity), cfg.Expiration)
 }
 
 func InitTableOnce(db *gorm.DB) {
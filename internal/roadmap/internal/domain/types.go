@@ -58,4 +58,5 @@ type Biz struct {
 const (
 	BizQuestion    = "question"
 	BizQuestionSet = "questionSet"
+	BizCaseSet     = "caseSet"
 )
@@ -27,6 +27,8 @@ import (
 
 	"github.com/ecodeclub/ekit/iox"
 	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/cases"
+	casemocks "github.com/ecodeclub/webook/internal/cases/mocks"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	quemocks "github.com/ecodeclub/webook/internal/question/mocks"
 	"github.com/ecodeclub/webook/internal/roadmap/internal/domain"
@@ -81,9 +83,22 @@ func (s *AdminHandlerTestSuite) SetupSuite() {
 			}), nil
 		}).AnyTimes()
 
+	mockCaseSetSvc := casemocks.NewMockCaseSetService(ctrl)
+	mockCaseSetSvc.EXPECT().GetByIds(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, ids []int64) ([]cases.CaseSet, error) {
+			return slice.Map(ids, func(idx int, src int64) cases.CaseSet {
+				return cases.CaseSet{
+					Id:    src,
+					Title: fmt.Sprintf("案例集%d", src),
+				}
+			}), nil
+		}).AnyTimes()
+
 	m := startup.InitModule(&baguwen.Module{
 		Svc:    mockQueSvc,
 		SetSvc: mockQueSetSvc,
+	}, &cases.Module{
+		SetSvc: mockCaseSetSvc,
 	})
 	s.hdl = m.AdminHdl
 
@@ -280,6 +295,46 @@ func (s *AdminHandlerTestSuite) TestList() {
 				},
 			},
 		},
+		{
+			name: "案例集",
+			before: func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				err := s.db.WithContext(ctx).Exec("TRUNCATE TABLE roadmaps").Error
+				require.NoError(t, err)
+				err = s.db.WithContext(ctx).Create(dao.Roadmap{
+					Id:    4,
+					Title: "标题4",
+					Biz:   sqlx.NewNullString(domain.BizCaseSet),
+					BizId: sqlx.NewNullInt64(4),
+					Utime: 123,
+				}).Error
+				require.NoError(t, err)
+			},
+			after: func(t *testing.T) {
+
+			},
+			req: web.Page{
+				Offset: 0,
+				Limit:  3,
+			},
+			wantCode: 200,
+			wantResp: test.Result[web.RoadmapListResp]{
+				Data: web.RoadmapListResp{
+					Total: 1,
+					Maps: []web.Roadmap{
+						{
+							Id:       4,
+							Title:    "标题4",
+							Biz:      domain.BizCaseSet,
+							BizId:    4,
+							BizTitle: "案例集4",
+							Utime:    123,
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -24,6 +24,8 @@ import (
 	"github.com/ecodeclub/ekit/iox"
 	"github.com/ecodeclub/ekit/slice"
 	"github.com/ecodeclub/ekit/sqlx"
+	"github.com/ecodeclub/webook/internal/cases"
+	casemocks "github.com/ecodeclub/webook/internal/cases/mocks"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	quemocks "github.com/ecodeclub/webook/internal/question/mocks"
 	"github.com/ecodeclub/webook/internal/roadmap/internal/domain"
@@ -75,9 +77,22 @@ func (s *HandlerTestSuite) SetupSuite() {
 			}), nil
 		}).AnyTimes()
 
+	mockCaseSetSvc := casemocks.NewMockCaseSetService(ctrl)
+	mockCaseSetSvc.EXPECT().GetByIds(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, ids []int64) ([]cases.CaseSet, error) {
+			return slice.Map(ids, func(idx int, src int64) cases.CaseSet {
+				return cases.CaseSet{
+					Id:    src,
+					Title: fmt.Sprintf("案例集%d", src),
+				}
+			}), nil
+		}).AnyTimes()
+
 	m := startup.InitModule(&baguwen.Module{
 		Svc:    mockQueSvc,
 		SetSvc: mockQueSetSvc,
+	}, &cases.Module{
+		SetSvc: mockCaseSetSvc,
 	})
 	s.hdl = m.Hdl
 
@@ -17,13 +17,14 @@
 package startup
 
 import (
+	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/roadmap"
 	testioc "github.com/ecodeclub/webook/internal/test/ioc"
 	"github.com/google/wire"
 )
 
-func InitModule(queModule *baguwen.Module) *roadmap.Module {
+func InitModule(queModule *baguwen.Module, caseModule *cases.Module) *roadmap.Module {
 	wire.Build(
 		testioc.BaseSet,
 		roadmap.InitModule,
@@ -7,6 +7,7 @@
 package startup
 
 import (
+	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/roadmap"
 	testioc "github.com/ecodeclub/webook/internal/test/ioc"
@@ -14,8 +15,8 @@ import (
 
 // Injectors from wire.go:
 
-func InitModule(queModule *baguwen.Module) *roadmap.Module {
+func InitModule(queModule *baguwen.Module, caseModule *cases.Module) *roadmap.Module {
 	db := testioc.InitDB()
-	module := roadmap.InitModule(db, queModule)
+	module := roadmap.InitModule(db, queModule, caseModule)
 	return module
 }
@@ -20,6 +20,7 @@ import (
 	"sync"
 
 	"github.com/ecodeclub/ekit/mapx"
+	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/roadmap/internal/domain"
 	"golang.org/x/sync/errgroup"
@@ -36,8 +37,9 @@ var _ BizService = &ConcurrentBizService{}
 
 // ConcurrentBizService 强调并发
 type ConcurrentBizService struct {
-	queSvc    baguwen.Service
-	queSetSvc baguwen.QuestionSetService
+	queSvc     baguwen.Service
+	queSetSvc  baguwen.QuestionSetService
+	caseSetSvc cases.CaseSetService
 }
 
 func (svc *ConcurrentBizService) GetBizs(ctx context.Context, bizs []string, ids []int64) (map[string]map[int64]domain.Biz, error) {
@@ -84,6 +86,8 @@ func (svc *ConcurrentBizService) GetBizsByIds(ctx context.Context, biz string, i
 		return svc.getQuestions(ctx, ids)
 	case domain.BizQuestionSet:
 		return svc.getQuestionSet(ctx, ids)
+	case domain.BizCaseSet:
+		return svc.getCaseSet(ctx, ids)
 	default:
 		return nil, fmt.Errorf("不支持的 Biz: %s", biz)
 	}
@@ -121,6 +125,24 @@ func (svc *ConcurrentBizService) getQuestionSet(ctx context.Context, ids []int64
 	return res, nil
 }
 
-func NewConcurrentBizService(queSvc baguwen.Service, queSetSvc baguwen.QuestionSetService) BizService {
-	return &ConcurrentBizService{queSvc: queSvc, queSetSvc: queSetSvc}
+func (svc *ConcurrentBizService) getCaseSet(ctx context.Context, ids []int64) (map[int64]domain.Biz, error) {
+	cs, err := svc.caseSetSvc.GetByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.Biz, len(cs))
+	for _, c := range cs {
+		res[c.Id] = domain.Biz{
+			Biz:   domain.BizCaseSet,
+			BizId: c.Id,
+			Title: c.Title,
+		}
+	}
+	return res, nil
+}
+
+func NewConcurrentBizService(queSvc baguwen.Service,
+	queSetSvc baguwen.QuestionSetService,
+	caseSetSvc cases.CaseSetService) BizService {
+	return &ConcurrentBizService{queSvc: queSvc, queSetSvc: queSetSvc, caseSetSvc: caseSetSvc}
 }
@@ -19,6 +19,7 @@ package roadmap
 import (
 	"sync"
 
+	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/roadmap/internal/repository"
 	"github.com/ecodeclub/webook/internal/roadmap/internal/repository/dao"
@@ -28,7 +29,7 @@ import (
 	"github.com/google/wire"
 )
 
-func InitModule(db *egorm.Component, queModule *baguwen.Module) *Module {
+func InitModule(db *egorm.Component, queModule *baguwen.Module, caseModule *cases.Module) *Module {
 	wire.Build(
 		web.NewAdminHandler,
 		service.NewAdminService,
@@ -43,6 +44,7 @@ func InitModule(db *egorm.Component, queModule *baguwen.Module) *Module {
 
 		wire.Struct(new(Module), "*"),
 		wire.FieldsOf(new(*baguwen.Module), "Svc", "SetSvc"),
+		wire.FieldsOf(new(*cases.Module), "SetSvc"),
 	)
 	return new(Module)
 }
@@ -9,6 +9,7 @@ package roadmap
 import (
 	"sync"
 
+	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/roadmap/internal/repository"
 	"github.com/ecodeclub/webook/internal/roadmap/internal/repository/dao"
@@ -20,13 +21,14 @@ import (
 
 // Injectors from wire.go:
 
-func InitModule(db *gorm.DB, queModule *baguwen.Module) *Module {
+func InitModule(db *gorm.DB, queModule *baguwen.Module, caseModule *cases.Module) *Module {
 	daoAdminDAO := initAdminDAO(db)
 	adminRepository := repository.NewCachedAdminRepository(daoAdminDAO)
 	adminService := service.NewAdminService(adminRepository)
 	serviceService := queModule.Svc
 	questionSetService := queModule.SetSvc
-	bizService := service.NewConcurrentBizService(serviceService, questionSetService)
+	caseSetService := caseModule.SetSvc
+	bizService := service.NewConcurrentBizService(serviceService, questionSetService, caseSetService)
 	adminHandler := web.NewAdminHandler(adminService, bizService)
 	roadmapDAO := dao.NewGORMRoadmapDAO(db)
 	repositoryRepository := repository.NewCachedRepository(roadmapDAO)
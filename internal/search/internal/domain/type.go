@@ -107,12 +107,27 @@ type QuestionSet struct {
 	Utime     time.Time
 }
 
+type CaseSet struct {
+	Id  int64
+	Uid int64
+	// 标题
+	Title string
+	// 描述
+	Description string
+	Biz         string
+	BizId       int64
+	// 案例集中引用的案例
+	Cases []int64
+	Utime time.Time
+}
+
 type SearchResult struct {
 	mu          sync.RWMutex
 	Cases       []Case
 	Questions   []Question
 	Skills      []Skill
 	QuestionSet []QuestionSet
+	CaseSets    []CaseSet
 }
 
 func (s *SearchResult) SetCases(cases []Case) {
@@ -138,3 +153,9 @@ func (s *SearchResult) SetQuestionSet(qs []QuestionSet) {
 	defer s.mu.Unlock()
 	s.QuestionSet = qs
 }
+
+func (s *SearchResult) SetCaseSets(cs []CaseSet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CaseSets = cs
+}
@@ -58,7 +58,11 @@ func (s *SyncConsumer) Consume(ctx context.Context) error {
 	}
 	indexName := getIndexName(evt.Biz)
 	docId := strconv.Itoa(evt.BizID)
-	err = s.svc.Input(ctx, indexName, docId, evt.Data)
+	if evt.Op == SyncOpDelete {
+		err = s.svc.Delete(ctx, indexName, docId)
+	} else {
+		err = s.svc.Input(ctx, indexName, docId, evt.Data)
+	}
 	if err != nil {
 		s.logger.Error("同步消息失败", elog.Any("SyncEvent", evt))
 	}
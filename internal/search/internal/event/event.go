@@ -16,6 +16,8 @@ package event
 
 const (
 	SyncTopic = "sync_data_to_search"
+	// SyncOpDelete 从索引里面删除文档，Op 为空的时候写入或者更新文档
+	SyncOpDelete = "delete"
 )
 
 type SyncEvent struct {
@@ -23,4 +25,5 @@ type SyncEvent struct {
 	BizID int    `json:"bizID"`
 	// 具体内容
 	Data string `json:"data"`
+	Op   string `json:"op,omitempty"`
 }
@@ -87,6 +87,8 @@ func (s *HandlerTestSuite) TearDownSuite() {
 	require.NoError(s.T(), err)
 	_, err = s.es.DeleteIndex(dao.QuestionSetIndexName).Do(context.Background())
 	require.NoError(s.T(), err)
+	_, err = s.es.DeleteIndex(dao.CaseSetIndexName).Do(context.Background())
+	require.NoError(s.T(), err)
 }
 
 func (s *HandlerTestSuite) TearDownTest() {
@@ -100,6 +102,8 @@ func (s *HandlerTestSuite) TearDownTest() {
 	require.NoError(s.T(), err)
 	_, err = s.es.DeleteByQuery(dao.QuestionSetIndexName).Query(query).Do(context.Background())
 	require.NoError(s.T(), err)
+	_, err = s.es.DeleteByQuery(dao.CaseSetIndexName).Query(query).Do(context.Background())
+	require.NoError(s.T(), err)
 }
 
 func (s *HandlerTestSuite) TestBizSearch() {
@@ -667,6 +671,44 @@ func (s *HandlerTestSuite) TestBizSearch() {
 				Limit:    20,
 			},
 		},
+		{
+			name: "搜索caseSets",
+			before: func(t *testing.T) {
+				s.initCaseSets()
+			},
+			after: func(t *testing.T, wantRes web.SearchResult, actual web.SearchResult) {
+				for idx := range actual.CaseSets {
+					require.True(t, actual.CaseSets[idx].Utime != "")
+					actual.CaseSets[idx].Utime = ""
+				}
+				assert.Equal(t, wantRes, actual)
+			},
+			wantAns: web.SearchResult{
+				CaseSets: []web.CaseSet{
+					{
+						Id:          2,
+						Uid:         123,
+						Title:       "test_title",
+						Description: "This is a test case set",
+						Biz:         "skill",
+						BizId:       3,
+						Cases:       []int64{2, 1},
+					},
+					{
+						Id:          1,
+						Uid:         123,
+						Title:       "jjjkjk",
+						Description: "test_desc",
+						Cases:       []int64{1},
+					},
+				},
+			},
+			req: web.SearchReq{
+				Keywords: "biz:caseSet:test_title test_desc",
+				Offset:   0,
+				Limit:    20,
+			},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -910,6 +952,24 @@ func (s *HandlerTestSuite) TestSync() {
 				assert.Equal(t, skill, ans)
 			},
 		},
+		{
+			name: "删除文档",
+			msg: event.SyncEvent{
+				Biz:   "skill",
+				BizID: 98,
+				Op:    event.SyncOpDelete,
+			},
+			before: func(t *testing.T) {
+				s.insertSkills([]dao.Skill{{ID: 98, Name: "deleted Skill"}})
+			},
+			after: func(t *testing.T) {
+				_, err := s.es.Get().
+					Index(dao.SkillIndexName).
+					Id("98").
+					Do(context.Background())
+				assert.True(t, elastic.IsNotFound(err))
+			},
+		},
 	}
 	for _, tc := range testcases {
 		s.T().Run(tc.name, func(t *testing.T) {
@@ -1804,6 +1864,38 @@ func (s *HandlerTestSuite) initQuestionSets() {
 	s.insertQuestionSet(questionSets)
 }
 
+func (s *HandlerTestSuite) initCaseSets() {
+	caseSets := []dao.CaseSet{
+		{
+			Id:          2,
+			Uid:         123,
+			Title:       "test_title",
+			Description: "This is a test case set",
+			Biz:         "skill",
+			BizId:       3,
+			Cases:       []int64{2, 1},
+			Utime:       1713856231,
+		},
+		{
+			Id:          1,
+			Uid:         123,
+			Title:       "jjjkjk",
+			Description: "test_desc",
+			Cases:       []int64{1},
+			Utime:       1713856231,
+		},
+	}
+	for _, cs := range caseSets {
+		by, err := json.Marshal(cs)
+		require.NoError(s.T(), err)
+		_, err = s.es.Index().
+			Index(dao.CaseSetIndexName).
+			Id(strconv.FormatInt(cs.Id, 10)).
+			BodyJson(string(by)).Do(context.Background())
+		require.NoError(s.T(), err)
+	}
+}
+
 func (s *HandlerTestSuite) insertQuestion(ques []dao.Question) {
 	for _, que := range ques {
 		by, err := json.Marshal(que)
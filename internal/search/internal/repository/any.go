@@ -32,3 +32,7 @@ func NewAnyRepo(anyDao dao.AnyDAO) AnyRepo {
 func (a *anyRepo) Input(ctx context.Context, index string, docID string, data string) error {
 	return a.anyDao.Input(ctx, index, docID, data)
 }
+
+func (a *anyRepo) Delete(ctx context.Context, index string, docID string) error {
+	return a.anyDao.Delete(ctx, index, docID)
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/webook/internal/search/internal/domain"
+	"github.com/ecodeclub/webook/internal/search/internal/repository/dao"
+)
+
+type caseSetRepo struct {
+	csDao dao.CaseSetDAO
+}
+
+func NewCaseSetRepo(caseSetDao dao.CaseSetDAO) CaseSetRepo {
+	return &caseSetRepo{
+		csDao: caseSetDao,
+	}
+}
+
+func (c *caseSetRepo) SearchCaseSet(ctx context.Context, offset, limit int, keywords string) ([]domain.CaseSet, error) {
+	sets, err := c.csDao.SearchCaseSet(ctx, offset, limit, keywords)
+	if err != nil {
+		return nil, err
+	}
+	ans := make([]domain.CaseSet, 0, len(sets))
+	for _, set := range sets {
+		ans = append(ans, c.toDomain(set))
+	}
+	return ans, nil
+}
+
+func (*caseSetRepo) toDomain(cs dao.CaseSet) domain.CaseSet {
+	return domain.CaseSet{
+		Id:          cs.Id,
+		Uid:         cs.Uid,
+		Title:       cs.Title,
+		Description: cs.Description,
+		Biz:         cs.Biz,
+		BizId:       cs.BizId,
+		Cases:       cs.Cases,
+		Utime:       time.UnixMilli(cs.Utime),
+	}
+}
@@ -37,3 +37,13 @@ func (a *anyESDAO) Input(ctx context.Context, index string, docID string, data s
 		BodyJson(data).Do(ctx)
 	return err
 }
+
+func (a *anyESDAO) Delete(ctx context.Context, index string, docID string) error {
+	_, err := a.client.Delete().
+		Index(index).
+		Id(docID).Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
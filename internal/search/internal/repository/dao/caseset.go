@@ -0,0 +1,75 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package dao
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	// CaseSetIndexName 与同步消费者按 biz 推导出的索引名保持一致
+	CaseSetIndexName        = "caseset_index"
+	caseSetTitleBoost       = 10
+	caseSetDescriptionBoost = 2
+)
+
+type CaseSet struct {
+	Id  int64 `json:"id"`
+	Uid int64 `json:"uid"`
+	// 标题
+	Title string `json:"title"`
+	// 描述
+	Description string `json:"description"`
+	Biz         string `json:"biz"`
+	BizId       int64  `json:"bizId"`
+	// 案例集中引用的案例
+	Cases []int64 `json:"cases"`
+	Utime int64   `json:"utime"`
+}
+
+type caseSetElasticDAO struct {
+	client *elastic.Client
+}
+
+func NewCaseSetDAO(client *elastic.Client) CaseSetDAO {
+	return &caseSetElasticDAO{
+		client: client,
+	}
+}
+
+func (c *caseSetElasticDAO) SearchCaseSet(ctx context.Context, offset, limit int, keywords string) ([]CaseSet, error) {
+	query := elastic.NewBoolQuery().Should(
+		elastic.NewMatchQuery("title", keywords).Boost(caseSetTitleBoost),
+		elastic.NewMatchQuery("description", keywords).Boost(caseSetDescriptionBoost),
+	)
+	resp, err := c.client.Search(CaseSetIndexName).
+		From(offset).
+		Size(limit).Query(query).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]CaseSet, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		var ele CaseSet
+		err = json.Unmarshal(hit.Source, &ele)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ele)
+	}
+	return res, nil
+}
@@ -32,6 +32,8 @@ var (
 	skillIndex string
 	//go:embed questionset_index.json
 	questionSetIndex string
+	//go:embed caseset_index.json
+	caseSetIndex string
 )
 
 // InitES 创建索引
@@ -52,6 +54,9 @@ func InitES(client *elastic.Client) error {
 	eg.Go(func() error {
 		return tryCreateIndex(ctx, client, QuestionSetIndexName, questionSetIndex)
 	})
+	eg.Go(func() error {
+		return tryCreateIndex(ctx, client, CaseSetIndexName, caseSetIndex)
+	})
 	return eg.Wait()
 }
 
@@ -36,6 +36,12 @@ type QuestionSetDAO interface {
 	SearchQuestionSet(ctx context.Context, offset, limit int, keywords string) ([]QuestionSet, error)
 }
 
+type CaseSetDAO interface {
+	SearchCaseSet(ctx context.Context, offset, limit int, keywords string) ([]CaseSet, error)
+}
+
 type AnyDAO interface {
 	Input(ctx context.Context, index string, docID string, data string) error
+	// Delete 文档不存在的时候不会返回错误
+	Delete(ctx context.Context, index string, docID string) error
 }
@@ -31,10 +31,15 @@ type QuestionSetRepo interface {
 	SearchQuestionSet(ctx context.Context, offset, limit int, keywords string) ([]domain.QuestionSet, error)
 }
 
+type CaseSetRepo interface {
+	SearchCaseSet(ctx context.Context, offset, limit int, keywords string) ([]domain.CaseSet, error)
+}
+
 type SkillRepo interface {
 	SearchSkill(ctx context.Context, offset, limit int, keywords string) ([]domain.Skill, error)
 }
 
 type AnyRepo interface {
 	Input(ctx context.Context, index string, docID string, data string) error
+	Delete(ctx context.Context, index string, docID string) error
 }
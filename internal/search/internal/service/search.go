@@ -82,12 +82,14 @@ func NewSearchSvc(
 	questionSetRepo repository.QuestionSetRepo,
 	skillRepo repository.SkillRepo,
 	caseRepo repository.CaseRepo,
+	caseSetRepo repository.CaseSetRepo,
 ) SearchService {
 	searchHandlers := map[string]SearchHandler{
 		"skill":       NewSkillHandler(skillRepo),
 		"case":        NewCaseHandler(caseRepo),
 		"questionSet": NewQuestionSetHandler(questionSetRepo),
 		"question":    NewQuestionHandler(questionRepo),
+		"caseSet":     NewCaseSetHandler(caseSetRepo),
 	}
 	return &searchSvc{
 		searchHandlers: searchHandlers,
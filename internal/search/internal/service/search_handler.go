@@ -83,6 +83,25 @@ func NewQuestionSetHandler(questionSetRepo repository.QuestionSetRepo) SearchHan
 	}
 }
 
+type caseSetHandler struct {
+	caseSetRepo repository.CaseSetRepo
+}
+
+func (c *caseSetHandler) search(ctx context.Context, keywords string, offset, limit int, res *domain.SearchResult) error {
+	caseSets, err := c.caseSetRepo.SearchCaseSet(ctx, offset, limit, keywords)
+	if err != nil {
+		return err
+	}
+	res.SetCaseSets(caseSets)
+	return nil
+}
+
+func NewCaseSetHandler(caseSetRepo repository.CaseSetRepo) SearchHandler {
+	return &caseSetHandler{
+		caseSetRepo: caseSetRepo,
+	}
+}
+
 type skillHandler struct {
 	skillRepo repository.SkillRepo
 }
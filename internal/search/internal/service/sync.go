@@ -22,6 +22,8 @@ import (
 
 type SyncService interface {
 	Input(ctx context.Context, index string, docID string, data string) error
+	// Delete 内容下线或者删除之后，从索引里面移除
+	Delete(ctx context.Context, index string, docID string) error
 }
 type syncService struct {
 	anyRepo repository.AnyRepo
@@ -31,6 +33,10 @@ func (s *syncService) Input(ctx context.Context, index string, docID string, dat
 	return s.anyRepo.Input(ctx, index, docID, data)
 }
 
+func (s *syncService) Delete(ctx context.Context, index string, docID string) error {
+	return s.anyRepo.Delete(ctx, index, docID)
+}
+
 func NewSyncSvc(anyRepo repository.AnyRepo) SyncService {
 	return &syncService{
 		anyRepo: anyRepo,
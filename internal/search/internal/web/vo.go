@@ -99,11 +99,23 @@ type QuestionSet struct {
 	Utime       string  `json:"utime,omitempty"`
 }
 
+type CaseSet struct {
+	Id          int64   `json:"id,omitempty"`
+	Uid         int64   `json:"uid,omitempty"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Biz         string  `json:"biz,omitempty"`
+	BizId       int64   `json:"bizId,omitempty"`
+	Cases       []int64 `json:"cases,omitempty"`
+	Utime       string  `json:"utime,omitempty"`
+}
+
 type SearchResult struct {
 	Cases       []Case        `json:"cases,omitempty"`
 	Questions   []Question    `json:"questions,omitempty"`
 	Skills      []Skill       `json:"skills,omitempty"`
 	QuestionSet []QuestionSet `json:"questionSet,omitempty"`
+	CaseSets    []CaseSet     `json:"caseSets,omitempty"`
 }
 
 func NewSearchResult(res *domain.SearchResult) SearchResult {
@@ -170,6 +182,18 @@ func NewSearchResult(res *domain.SearchResult) SearchResult {
 		}
 		newResult.QuestionSet = append(newResult.QuestionSet, newQuestionSet)
 	}
+	for _, cs := range res.CaseSets {
+		newResult.CaseSets = append(newResult.CaseSets, CaseSet{
+			Id:          cs.Id,
+			Uid:         cs.Uid,
+			Title:       cs.Title,
+			Description: cs.Description,
+			Biz:         cs.Biz,
+			BizId:       cs.BizId,
+			Cases:       cs.Cases,
+			Utime:       cs.Utime.Format(time.DateTime),
+		})
+	}
 
 	return newResult
 }
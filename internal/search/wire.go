@@ -53,17 +53,19 @@ func InitIndexOnce(es *elastic.Client) {
 	})
 }
 
-func InitRepo(es *elastic.Client) (repository.CaseRepo, repository.QuestionRepo, repository.QuestionSetRepo, repository.SkillRepo) {
+func InitRepo(es *elastic.Client) (repository.CaseRepo, repository.QuestionRepo, repository.QuestionSetRepo, repository.SkillRepo, repository.CaseSetRepo) {
 	InitIndexOnce(es)
 	questionDao := dao.NewQuestionDAO(es)
 	caseDao := dao.NewCaseElasticDAO(es)
 	questionSetDao := dao.NewQuestionSetDAO(es)
+	caseSetDao := dao.NewCaseSetDAO(es)
 	skillDao := dao.NewSkillElasticDAO(es)
 	questionRepo := repository.NewQuestionRepo(questionDao)
 	caseRepo := repository.NewCaseRepo(caseDao)
 	questionSetRepo := repository.NewQuestionSetRepo(questionSetDao)
+	caseSetRepo := repository.NewCaseSetRepo(caseSetDao)
 	skillRepo := repository.NewSKillRepo(skillDao)
-	return caseRepo, questionRepo, questionSetRepo, skillRepo
+	return caseRepo, questionRepo, questionSetRepo, skillRepo, caseSetRepo
 }
 func InitAnyRepo(es *elastic.Client) repository.AnyRepo {
 	InitIndexOnce(es)
@@ -73,8 +75,8 @@ func InitAnyRepo(es *elastic.Client) repository.AnyRepo {
 }
 
 func InitSearchSvc(es *elastic.Client) service.SearchService {
-	caseRepo, questionRepo, questionSetRepo, skillRepo := InitRepo(es)
-	return service.NewSearchSvc(questionRepo, questionSetRepo, skillRepo, caseRepo)
+	caseRepo, questionRepo, questionSetRepo, skillRepo, caseSetRepo := InitRepo(es)
+	return service.NewSearchSvc(questionRepo, questionSetRepo, skillRepo, caseRepo, caseSetRepo)
 }
 func InitSyncSvc(es *elastic.Client) service.SyncService {
 	anyRepo := InitAnyRepo(es)
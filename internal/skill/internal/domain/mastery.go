@@ -0,0 +1,72 @@
+package domain
+
+// 和八股文测试结果的取值保持一致，取值越大掌握程度越高
+const (
+	resultBasic        uint8 = 1
+	resultIntermediate uint8 = 2
+	resultAdvanced     uint8 = 3
+)
+
+// LevelMastery 用户在某个技能等级上的掌握情况
+type LevelMastery struct {
+	// Questions 这个等级关联的题目
+	Questions []int64
+	// Mastered 测试结果达到了这个等级的题目
+	Mastered []int64
+	// Missing 没有测试过，或者测试结果还没有达到这个等级的题目
+	Missing []int64
+}
+
+// Rate 达到这个等级的题目的比例，没有关联题目的时候是 0
+func (l LevelMastery) Rate() float64 {
+	if len(l.Questions) == 0 {
+		return 0
+	}
+	return float64(len(l.Mastered)) / float64(len(l.Questions))
+}
+
+func newLevelMastery(questions []int64, results map[int64]uint8, required uint8) LevelMastery {
+	res := LevelMastery{
+		Questions: questions,
+		Mastered:  make([]int64, 0, len(questions)),
+		Missing:   make([]int64, 0, len(questions)),
+	}
+	for _, qid := range questions {
+		if results[qid] >= required {
+			res.Mastered = append(res.Mastered, qid)
+		} else {
+			res.Missing = append(res.Missing, qid)
+		}
+	}
+	return res
+}
+
+// SkillMastery 用户对某个技能的掌握情况
+type SkillMastery struct {
+	Sid          int64
+	Name         string
+	Basic        LevelMastery
+	Intermediate LevelMastery
+	Advanced     LevelMastery
+}
+
+// NewSkillMastery results 是每道题目的测试结果，没有测试过的题目可以不在里面
+func NewSkillMastery(skill Skill, results map[int64]uint8) SkillMastery {
+	return SkillMastery{
+		Sid:          skill.ID,
+		Name:         skill.Name,
+		Basic:        newLevelMastery(skill.Basic.Questions, results, resultBasic),
+		Intermediate: newLevelMastery(skill.Intermediate.Questions, results, resultIntermediate),
+		Advanced:     newLevelMastery(skill.Advanced.Questions, results, resultAdvanced),
+	}
+}
+
+// Score 雷达图上的得分，也就是三个等级合起来达标的题目比例
+func (s SkillMastery) Score() float64 {
+	total := len(s.Basic.Questions) + len(s.Intermediate.Questions) + len(s.Advanced.Questions)
+	if total == 0 {
+		return 0
+	}
+	mastered := len(s.Basic.Mastered) + len(s.Intermediate.Mastered) + len(s.Advanced.Mastered)
+	return float64(mastered) / float64(total)
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSkillMastery(t *testing.T) {
+	skill := Skill{
+		ID:           1,
+		Name:         "mysql",
+		Basic:        SkillLevel{Questions: []int64{1, 2}},
+		Intermediate: SkillLevel{Questions: []int64{3, 4}},
+		Advanced:     SkillLevel{Questions: []int64{5}},
+	}
+	testCases := []struct {
+		name    string
+		skill   Skill
+		results map[int64]uint8
+
+		wantMastery SkillMastery
+		wantRates   []float64
+		wantScore   float64
+	}{
+		{
+			name:    "没有测试过",
+			skill:   skill,
+			results: map[int64]uint8{},
+			wantMastery: SkillMastery{
+				Sid:          1,
+				Name:         "mysql",
+				Basic:        LevelMastery{Questions: []int64{1, 2}, Mastered: []int64{}, Missing: []int64{1, 2}},
+				Intermediate: LevelMastery{Questions: []int64{3, 4}, Mastered: []int64{}, Missing: []int64{3, 4}},
+				Advanced:     LevelMastery{Questions: []int64{5}, Mastered: []int64{}, Missing: []int64{5}},
+			},
+			wantRates: []float64{0, 0, 0},
+			wantScore: 0,
+		},
+		{
+			name:  "更高的测试结果也算达到了这个等级",
+			skill: skill,
+			results: map[int64]uint8{
+				1: resultAdvanced,
+				2: resultBasic,
+				3: resultBasic,
+				4: resultIntermediate,
+				5: 0,
+			},
+			wantMastery: SkillMastery{
+				Sid:          1,
+				Name:         "mysql",
+				Basic:        LevelMastery{Questions: []int64{1, 2}, Mastered: []int64{1, 2}, Missing: []int64{}},
+				Intermediate: LevelMastery{Questions: []int64{3, 4}, Mastered: []int64{4}, Missing: []int64{3}},
+				Advanced:     LevelMastery{Questions: []int64{5}, Mastered: []int64{}, Missing: []int64{5}},
+			},
+			wantRates: []float64{1, 0.5, 0},
+			wantScore: 0.6,
+		},
+		{
+			name:  "没有关联题目",
+			skill: Skill{ID: 2, Name: "redis"},
+			wantMastery: SkillMastery{
+				Sid:          2,
+				Name:         "redis",
+				Basic:        LevelMastery{Mastered: []int64{}, Missing: []int64{}},
+				Intermediate: LevelMastery{Mastered: []int64{}, Missing: []int64{}},
+				Advanced:     LevelMastery{Mastered: []int64{}, Missing: []int64{}},
+			},
+			wantRates: []float64{0, 0, 0},
+			wantScore: 0,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewSkillMastery(tc.skill, tc.results)
+			assert.Equal(t, tc.wantMastery, m)
+			assert.Equal(t, tc.wantRates, []float64{
+				m.Basic.Rate(), m.Intermediate.Rate(), m.Advanced.Rate(),
+			})
+			assert.InDelta(t, tc.wantScore, m.Score(), 0.0001)
+		})
+	}
+}
@@ -10,6 +10,7 @@ type Skill struct {
 	Basic        SkillLevel
 	Intermediate SkillLevel
 	Advanced     SkillLevel
+	Status       SkillStatus
 	Ctime        time.Time
 	Utime        time.Time
 }
@@ -58,3 +59,20 @@ type SkillLevel struct {
 //	RTypeQuestion = "question"
 //	RTypeCase = "case"
 //)
+
+type SkillStatus uint8
+
+func (s SkillStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	// SkillStatusUnknown 未知，更新的时候不会修改状态
+	SkillStatusUnknown SkillStatus = iota
+	// SkillStatusUnPublished 已下线
+	SkillStatusUnPublished
+	// SkillStatusPublished 已发表
+	SkillStatusPublished
+	// SkillStatusDeleted 已删除，放在回收站里面
+	SkillStatusDeleted
+)
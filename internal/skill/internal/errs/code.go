@@ -2,6 +2,11 @@ package errs
 
 var (
 	SystemError = ErrorCode{Code: 507001, Msg: "系统错误"}
+
+	// SkillNotFound 技能不存在，或者还没有发表
+	SkillNotFound = ErrorCode{Code: 407001, Msg: "技能不存在"}
+	// SkillNameInTrash 回收站里面有同名的技能，需要先恢复
+	SkillNameInTrash = ErrorCode{Code: 407002, Msg: "回收站里面有同名的技能"}
 )
 
 type ErrorCode struct {
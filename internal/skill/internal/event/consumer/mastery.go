@@ -0,0 +1,64 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ecodeclub/mq-api"
+	"github.com/ecodeclub/webook/internal/skill/internal/event"
+	"github.com/ecodeclub/webook/internal/skill/internal/service"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// MasteryConsumer 消费八股文的测试结果，让用户的技能掌握情况失效
+type MasteryConsumer struct {
+	svc      service.MasteryService
+	consumer mq.Consumer
+	logger   *elog.Component
+}
+
+func NewMasteryConsumer(svc service.MasteryService, q mq.MQ) (*MasteryConsumer, error) {
+	const groupID = "skill_mastery"
+	consumer, err := q.Consumer(event.ExamineResultTopic, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return &MasteryConsumer{
+		svc:      svc,
+		consumer: consumer,
+		logger:   elog.DefaultLogger,
+	}, nil
+}
+
+func (c *MasteryConsumer) Start(ctx context.Context) {
+	go func() {
+		for {
+			err := c.Consume(ctx)
+			if err != nil {
+				c.logger.Error("消费测试结果事件失败", elog.FieldErr(err))
+			}
+		}
+	}()
+}
+
+func (c *MasteryConsumer) Consume(ctx context.Context) error {
+	msg, err := c.consumer.Consume(ctx)
+	if err != nil {
+		return fmt.Errorf("获取消息失败: %w", err)
+	}
+	var evt event.ExamineResultEvent
+	err = json.Unmarshal(msg.Value, &evt)
+	if err != nil {
+		return fmt.Errorf("解析消息失败: %w", err)
+	}
+	err = c.svc.OnExamineResult(ctx, evt.Uid)
+	if err != nil {
+		return fmt.Errorf("清除技能掌握情况失败 uid %d: %w", evt.Uid, err)
+	}
+	return nil
+}
+
+func (c *MasteryConsumer) Stop(_ context.Context) error {
+	return c.consumer.Close()
+}
@@ -10,8 +10,15 @@ type SkillEvent struct {
 	Biz   string `json:"biz"`
 	BizID int    `json:"bizID"`
 	Data  string `json:"data"`
+	// Op 为空的时候是同步数据
+	Op string `json:"op,omitempty"`
 }
 
+const (
+	skillBiz     = "skill"
+	syncOpDelete = "delete"
+)
+
 type SkillLevel struct {
 	ID        int64   `json:"id"`
 	Desc      string  `json:"desc"`
@@ -59,8 +66,27 @@ func newSkill(s domain.Skill) Skill {
 func NewSkillEvent(s domain.Skill) SkillEvent {
 	qByte, _ := json.Marshal(newSkill(s))
 	return SkillEvent{
-		Biz:   "skill",
+		Biz:   skillBiz,
 		BizID: int(s.ID),
 		Data:  string(qByte),
 	}
 }
+
+// NewSyncDeleteEvent 从搜索里面删除技能
+func NewSyncDeleteEvent(id int64) SkillEvent {
+	return SkillEvent{
+		Biz:   skillBiz,
+		BizID: int(id),
+		Op:    syncOpDelete,
+	}
+}
+
+// ExamineResultTopic 八股文的测试结果，用户有新的测试结果之后技能的掌握情况需要重新计算
+const ExamineResultTopic = "question_examine_results"
+
+// ExamineResultEvent 和 question 模块里面发送的消息保持一致
+type ExamineResultEvent struct {
+	Uid    int64 `json:"uid"`
+	Qid    int64 `json:"qid"`
+	Result uint8 `json:"result"`
+}
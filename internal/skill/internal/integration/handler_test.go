@@ -19,12 +19,16 @@ import (
 	casemocks "github.com/ecodeclub/webook/internal/cases/mocks"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	quemocks "github.com/ecodeclub/webook/internal/question/mocks"
+	"github.com/ecodeclub/webook/internal/skill"
 	evemocks "github.com/ecodeclub/webook/internal/skill/internal/event/mocks"
 	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
 
 	"github.com/ecodeclub/ekit/iox"
 	"github.com/ecodeclub/ekit/sqlx"
 	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/webook/internal/skill/internal/domain"
+	"github.com/ecodeclub/webook/internal/skill/internal/errs"
 	"github.com/ecodeclub/webook/internal/skill/internal/integration/startup"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository/dao"
 	"github.com/ecodeclub/webook/internal/skill/internal/web"
@@ -43,11 +47,14 @@ const uid = 2061
 
 type HandlerTestSuite struct {
 	suite.Suite
-	server   *egin.Component
-	db       *egorm.Component
-	dao      dao.SkillDAO
-	ctrl     *gomock.Controller
-	producer *evemocks.MockSyncEventProducer
+	server *egin.Component
+	// userServer 登录了，但是不是创作者
+	userServer *egin.Component
+	db         *egorm.Component
+	dao        dao.SkillDAO
+	ctrl       *gomock.Controller
+	producer   *evemocks.MockSyncEventProducer
+	purgeJob   *skill.PurgeTrashJob
 }
 
 func (s *HandlerTestSuite) TearDownTest() {
@@ -57,6 +64,12 @@ func (s *HandlerTestSuite) TearDownTest() {
 	require.NoError(s.T(), err)
 	err = s.db.Exec("TRUNCATE  TABLE `skill_refs`").Error
 	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `publish_skill`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `publish_skill_level`").Error
+	require.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `publish_skill_refs`").Error
+	require.NoError(s.T(), err)
 }
 
 func (s *HandlerTestSuite) SetupSuite() {
@@ -84,7 +97,7 @@ func (s *HandlerTestSuite) SetupSuite() {
 		}).AnyTimes()
 	s.ctrl = ctrl
 	s.producer = evemocks.NewMockSyncEventProducer(s.ctrl)
-	handler, err := startup.InitHandler(
+	module, err := startup.InitModule(
 		&baguwen.Module{Svc: queSvc},
 		&cases.Module{Svc: caseSvc},
 		s.producer,
@@ -98,8 +111,18 @@ func (s *HandlerTestSuite) SetupSuite() {
 			Data: map[string]string{"creator": "true"},
 		}))
 	})
-	handler.PrivateRoutes(server.Engine)
+	module.Hdl.PublicRoutes(server.Engine)
+	module.Hdl.PrivateRoutes(server.Engine)
 	s.server = server
+	userServer := egin.Load("server").Build()
+	userServer.Use(func(ctx *gin.Context) {
+		ctx.Set("_session", session.NewMemorySession(session.Claims{
+			Uid: uid,
+		}))
+	})
+	module.Hdl.PrivateRoutes(userServer.Engine)
+	s.userServer = userServer
+	s.purgeJob = module.PurgeTrashJob
 	s.db = testioc.InitDB()
 	err = dao.InitTables(s.db)
 	require.NoError(s.T(), err)
@@ -118,7 +141,6 @@ func (s *HandlerTestSuite) TestSave() {
 		{
 			name: "新增",
 			before: func(t *testing.T) {
-				s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			after: func(t *testing.T) {
 				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -132,8 +154,9 @@ func (s *HandlerTestSuite) TestSave() {
 						Val:   []string{"mysql"},
 						Valid: true,
 					},
-					Name: "mysql",
-					Desc: "mysql_desc",
+					Name:   "mysql",
+					Desc:   "mysql_desc",
+					Status: domain.SkillStatusUnPublished.ToUint8(),
 				}, skill)
 				wantLevels := []dao.SkillLevel{
 					{
@@ -188,7 +211,6 @@ func (s *HandlerTestSuite) TestSave() {
 		{
 			name: "更新",
 			before: func(t *testing.T) {
-				s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil)
 				err := s.db.Create(&dao.Skill{
 					Id: 2,
 					Labels: sqlx.JsonColumn[[]string]{
@@ -231,8 +253,9 @@ func (s *HandlerTestSuite) TestSave() {
 						Val:   []string{"mysql"},
 						Valid: true,
 					},
-					Name: "mysql",
-					Desc: "mysql_desc",
+					Name:   "mysql",
+					Desc:   "mysql_desc",
+					Status: domain.SkillStatusUnPublished.ToUint8(),
 				}, skill)
 				wantLevels := []dao.SkillLevel{
 					{
@@ -323,7 +346,6 @@ func (s *HandlerTestSuite) TestSaveRefs() {
 		{
 			name: "新建",
 			before: func(t *testing.T) {
-				s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).Return(nil)
 				err := s.db.Create(&dao.Skill{
 					Id: 1,
 				}).Error
@@ -786,7 +808,8 @@ func (s *HandlerTestSuite) TestList() {
 							Labels: []string{
 								"mysql100",
 							},
-							Utime: time.Unix(0, 0).Format(time.DateTime),
+							Utime:  time.Unix(0, 0).Format(time.DateTime),
+							Status: domain.SkillStatusPublished.ToUint8(),
 							Basic: web.SkillLevel{
 								Questions: []web.Question{},
 								Cases:     []web.Case{},
@@ -807,7 +830,8 @@ func (s *HandlerTestSuite) TestList() {
 							Labels: []string{
 								"mysql99",
 							},
-							Utime: time.Unix(0, 0).Format(time.DateTime),
+							Utime:  time.Unix(0, 0).Format(time.DateTime),
+							Status: domain.SkillStatusPublished.ToUint8(),
 							Basic: web.SkillLevel{
 								Questions: []web.Question{},
 								Cases:     []web.Case{},
@@ -843,7 +867,8 @@ func (s *HandlerTestSuite) TestList() {
 							Labels: []string{
 								"mysql1",
 							},
-							Utime: time.Unix(0, 0).Format(time.DateTime),
+							Utime:  time.Unix(0, 0).Format(time.DateTime),
+							Status: domain.SkillStatusPublished.ToUint8(),
 							Basic: web.SkillLevel{
 								Questions: []web.Question{},
 								Cases:     []web.Case{},
@@ -974,7 +999,7 @@ func (s *HandlerTestSuite) TestEvent() {
 		mu.Unlock()
 		return nil
 	}).Times(2)
-	// 保存
+	// 发表
 	saveReq := web.SaveReq{
 		Skill: web.Skill{
 			Labels: []string{"mysql"},
@@ -992,7 +1017,7 @@ func (s *HandlerTestSuite) TestEvent() {
 		},
 	}
 	req, err := http.NewRequest(http.MethodPost,
-		"/skill/save", iox.NewJSONReader(saveReq))
+		"/skill/publish", iox.NewJSONReader(saveReq))
 	req.Header.Set("content-type", "application/json")
 	require.NoError(t, err)
 	recorder := test.NewJSONResponseRecorder[int64]()
@@ -1058,6 +1083,24 @@ func (s *HandlerTestSuite) TestEvent() {
 	recorder = test.NewJSONResponseRecorder[int64]()
 	s.server.ServeHTTP(recorder, req2)
 	require.Equal(t, 200, recorder.Code)
+	// 保存 ref 不会同步，发表的时候带上保存好的 ref
+	pubReq := web.SaveReq{
+		Skill: web.Skill{
+			ID:           2,
+			Name:         "test_name",
+			Desc:         "test_desc",
+			Basic:        web.SkillLevel{Id: 4, Desc: "basic_desc"},
+			Intermediate: web.SkillLevel{Id: 5, Desc: "intermediate_desc"},
+			Advanced:     web.SkillLevel{Id: 6, Desc: "advanced_desc"},
+		},
+	}
+	req3, err := http.NewRequest(http.MethodPost,
+		"/skill/publish", iox.NewJSONReader(pubReq))
+	req3.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder = test.NewJSONResponseRecorder[int64]()
+	s.server.ServeHTTP(recorder, req3)
+	require.Equal(t, 200, recorder.Code)
 	time.Sleep(1 * time.Second)
 	wantAns := []event.Skill{
 		{
@@ -1136,6 +1179,585 @@ func (s *HandlerTestSuite) formatSkillLevel(sk event.SkillLevel) event.SkillLeve
 	return sk
 }
 
+func (s *HandlerTestSuite) TestStatusChange() {
+	pubReq := web.SaveReq{
+		Skill: web.Skill{
+			ID:   1,
+			Name: "mysql",
+			Desc: "mysql_desc",
+		},
+	}
+	testCases := []struct {
+		name   string
+		before func(t *testing.T)
+		path   string
+		req    any
+
+		wantStatus    domain.SkillStatus
+		wantPublished bool
+	}{
+		{
+			name: "下线",
+			before: func(t *testing.T) {
+				s.producer.EXPECT().Produce(gomock.Any(), event.NewSyncDeleteEvent(1)).Return(nil)
+			},
+			path:       "/skill/unpublish",
+			req:        web.Sid{Sid: 1},
+			wantStatus: domain.SkillStatusUnPublished,
+		},
+		{
+			name: "删除",
+			before: func(t *testing.T) {
+				s.producer.EXPECT().Produce(gomock.Any(), event.NewSyncDeleteEvent(1)).Return(nil)
+			},
+			path:       "/skill/delete",
+			req:        web.Sid{Sid: 1},
+			wantStatus: domain.SkillStatusDeleted,
+		},
+		{
+			name: "回收站里面的技能不能直接发表",
+			before: func(t *testing.T) {
+				err := s.dao.Delete(context.Background(), 1)
+				require.NoError(t, err)
+			},
+			path:       "/skill/publish",
+			req:        pubReq,
+			wantStatus: domain.SkillStatusDeleted,
+		},
+		{
+			name: "恢复之后是下线状态",
+			before: func(t *testing.T) {
+				err := s.dao.Delete(context.Background(), 1)
+				require.NoError(t, err)
+			},
+			path:       "/skill/restore",
+			req:        web.Sid{Sid: 1},
+			wantStatus: domain.SkillStatusUnPublished,
+		},
+		{
+			name: "重新发表",
+			before: func(t *testing.T) {
+				err := s.dao.Unpublish(context.Background(), 1)
+				require.NoError(t, err)
+				s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, evt event.SkillEvent) error {
+						assert.Equal(t, "skill", evt.Biz)
+						assert.Equal(t, 1, evt.BizID)
+						assert.Empty(t, evt.Op)
+						return nil
+					})
+			},
+			path:          "/skill/publish",
+			req:           pubReq,
+			wantStatus:    domain.SkillStatusPublished,
+			wantPublished: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		s.T().Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			defer cancel()
+			_, err := s.dao.Sync(ctx, dao.Skill{
+				Name:   "mysql",
+				Desc:   "mysql_desc",
+				Status: domain.SkillStatusPublished.ToUint8(),
+			}, []dao.SkillLevel{{Level: dao.LevelBasic, Desc: "mysql_basic"}})
+			require.NoError(t, err)
+			tc.before(t)
+
+			req, err := http.NewRequest(http.MethodPost,
+				tc.path, iox.NewJSONReader(tc.req))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[any]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+
+			sk, err := s.dao.Info(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantStatus.ToUint8(), sk.Status)
+			// 只有已发表的技能会出现在线上库
+			_, err = s.dao.PubInfo(ctx, 1)
+			if tc.wantPublished {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			}
+			levels, err := s.dao.PubSkillLevelInfo(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPublished, len(levels) == 1)
+			s.TearDownTest()
+		})
+	}
+}
+
+func (s *HandlerTestSuite) TestPublish() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	// 已经发表过的技能，修改之后还没有发表
+	_, err := s.dao.Sync(ctx, dao.Skill{
+		Name:   "old_mysql",
+		Desc:   "old_mysql_desc",
+		Status: domain.SkillStatusPublished.ToUint8(),
+	}, []dao.SkillLevel{
+		{Level: dao.LevelBasic, Desc: "old_basic"},
+		{Level: dao.LevelIntermediate, Desc: "old_intermediate"},
+		{Level: dao.LevelAdvanced, Desc: "old_advanced"},
+	})
+	require.NoError(t, err)
+	err = s.dao.SaveRefs(ctx, []dao.SkillRef{
+		{Sid: 1, Slid: 1, Rid: 1, Rtype: dao.RTypeQuestion},
+		{Sid: 1, Slid: 3, Rid: 2, Rtype: dao.RTypeCase},
+	})
+	require.NoError(t, err)
+	saveReq := web.SaveReq{
+		Skill: web.Skill{
+			ID:           1,
+			Labels:       []string{"mysql"},
+			Name:         "mysql",
+			Desc:         "mysql_desc",
+			Basic:        web.SkillLevel{Id: 1, Desc: "mysql_basic"},
+			Intermediate: web.SkillLevel{Id: 2, Desc: "mysql_intermediate"},
+			Advanced:     web.SkillLevel{Id: 3, Desc: "mysql_advanced"},
+		},
+	}
+	req, err := http.NewRequest(http.MethodPost,
+		"/skill/save", iox.NewJSONReader(saveReq))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[int64]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+
+	// 保存之后 C 端看到的还是旧的数据
+	pub, err := s.dao.PubInfo(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "old_mysql", pub.Name)
+	pubRefs, err := s.dao.PubRefs(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, pubRefs)
+	sk, err := s.dao.Info(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SkillStatusUnPublished.ToUint8(), sk.Status)
+
+	s.producer.EXPECT().Produce(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, evt event.SkillEvent) error {
+			var eve event.Skill
+			err := json.Unmarshal([]byte(evt.Data), &eve)
+			require.NoError(t, err)
+			assert.Equal(t, "mysql", eve.Name)
+			assert.Equal(t, []int64{1}, eve.Basic.Questions)
+			assert.Equal(t, []int64{2}, eve.Advanced.Cases)
+			return nil
+		})
+	req, err = http.NewRequest(http.MethodPost,
+		"/skill/publish", iox.NewJSONReader(saveReq))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder = test.NewJSONResponseRecorder[int64]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	assert.Equal(t, int64(1), recorder.MustScan().Data)
+
+	pub, err = s.dao.PubInfo(ctx, 1)
+	require.NoError(t, err)
+	sk, err = s.dao.Info(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SkillStatusPublished.ToUint8(), sk.Status)
+	assert.Equal(t, dao.PublishSkill(sk), pub)
+	levels, err := s.dao.SkillLevelInfo(ctx, 1)
+	require.NoError(t, err)
+	pubLevels, err := s.dao.PubSkillLevelInfo(ctx, 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, slice.Map(levels, func(idx int, src dao.SkillLevel) dao.PublishSkillLevel {
+		return dao.PublishSkillLevel(src)
+	}), pubLevels)
+	refs, err := s.dao.Refs(ctx, 1)
+	require.NoError(t, err)
+	pubRefs, err = s.dao.PubRefs(ctx, 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, slice.Map(refs, func(idx int, src dao.SkillRef) dao.PublishSkillRef {
+		return dao.PublishSkillRef(src)
+	}), pubRefs)
+}
+
+func (s *HandlerTestSuite) TestPubListAndDetail() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	_, err := s.dao.Create(ctx, dao.Skill{
+		Name:   "mysql",
+		Desc:   "mysql_desc",
+		Status: domain.SkillStatusUnPublished.ToUint8(),
+	}, []dao.SkillLevel{{Level: dao.LevelBasic, Desc: "mysql_basic"}})
+	require.NoError(t, err)
+	err = s.dao.SaveRefs(ctx, []dao.SkillRef{
+		{Sid: 1, Slid: 1, Rid: 1, Rtype: dao.RTypeQuestion},
+		{Sid: 1, Slid: 1, Rid: 2, Rtype: dao.RTypeCase},
+	})
+	require.NoError(t, err)
+	_, err = s.dao.Sync(ctx, dao.Skill{
+		Id:     1,
+		Name:   "mysql",
+		Desc:   "mysql_desc",
+		Status: domain.SkillStatusPublished.ToUint8(),
+	}, []dao.SkillLevel{{Id: 1, Level: dao.LevelBasic, Desc: "mysql_basic"}})
+	require.NoError(t, err)
+	// 只保存了还没有发表的技能
+	_, err = s.dao.Create(ctx, dao.Skill{
+		Name:   "redis",
+		Desc:   "redis_desc",
+		Status: domain.SkillStatusUnPublished.ToUint8(),
+	}, []dao.SkillLevel{{Level: dao.LevelBasic, Desc: "redis_basic"}})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost,
+		"/skill/pub/list", iox.NewJSONReader(web.Page{Limit: 10}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.SkillList]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	list := recorder.MustScan().Data
+	assert.Equal(t, int64(1), list.Total)
+	require.Len(t, list.Skills, 1)
+	assert.Equal(t, "mysql", list.Skills[0].Name)
+	assert.Equal(t, "mysql_basic", list.Skills[0].Basic.Desc)
+
+	testCases := []struct {
+		name     string
+		sid      int64
+		wantResp test.Result[web.Skill]
+	}{
+		{
+			name: "线上库里面的技能带上了关联的标题",
+			sid:  1,
+			wantResp: test.Result[web.Skill]{
+				Data: web.Skill{
+					ID:   1,
+					Name: "mysql",
+					Desc: "mysql_desc",
+					Basic: web.SkillLevel{
+						Id:        1,
+						Desc:      "mysql_basic",
+						Questions: []web.Question{{Id: 1, Title: "这是问题1"}},
+						Cases:     []web.Case{{Id: 2, Title: "这是案例2"}},
+					},
+					Intermediate: web.SkillLevel{
+						Questions: []web.Question{},
+						Cases:     []web.Case{},
+					},
+					Advanced: web.SkillLevel{
+						Questions: []web.Question{},
+						Cases:     []web.Case{},
+					},
+				},
+			},
+		},
+		{
+			name: "没有发表的技能看不到",
+			sid:  2,
+			wantResp: test.Result[web.Skill]{
+				Code: errs.SkillNotFound.Code,
+				Msg:  errs.SkillNotFound.Msg,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				"/skill/pub/detail", iox.NewJSONReader(web.Sid{Sid: tc.sid}))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.Skill]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			resp := recorder.MustScan()
+			resp.Data.Utime = ""
+			assert.Equal(t, tc.wantResp, resp)
+		})
+	}
+}
+
+func (s *HandlerTestSuite) TestUserListAndDetail() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	_, err := s.dao.Sync(ctx, dao.Skill{
+		Name:   "mysql",
+		Desc:   "mysql_desc",
+		Status: domain.SkillStatusPublished.ToUint8(),
+	}, []dao.SkillLevel{{Level: dao.LevelBasic, Desc: "mysql_basic"}})
+	require.NoError(t, err)
+	// 还没有发表的技能
+	_, err = s.dao.Create(ctx, dao.Skill{
+		Name:   "redis",
+		Desc:   "redis_desc",
+		Status: domain.SkillStatusUnPublished.ToUint8(),
+	}, []dao.SkillLevel{{Level: dao.LevelBasic, Desc: "redis_basic"}})
+	require.NoError(t, err)
+	// 放入回收站的技能
+	kafka, err := s.dao.Sync(ctx, dao.Skill{
+		Name:   "kafka",
+		Desc:   "kafka_desc",
+		Status: domain.SkillStatusPublished.ToUint8(),
+	}, []dao.SkillLevel{{Level: dao.LevelBasic, Desc: "kafka_basic"}})
+	require.NoError(t, err)
+	err = s.dao.Delete(ctx, kafka)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost,
+		"/skill/list", iox.NewJSONReader(web.Page{Limit: 10}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.SkillList]()
+	s.userServer.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	list := recorder.MustScan().Data
+	assert.Equal(t, int64(1), list.Total)
+	require.Len(t, list.Skills, 1)
+	assert.Equal(t, "mysql", list.Skills[0].Name)
+
+	testCases := []struct {
+		name     string
+		sid      int64
+		wantCode int
+		wantName string
+	}{
+		{
+			name:     "已经发表的技能",
+			sid:      1,
+			wantName: "mysql",
+		},
+		{
+			name:     "没有发表的技能看不到",
+			sid:      2,
+			wantCode: errs.SkillNotFound.Code,
+		},
+		{
+			name:     "回收站里面的技能看不到",
+			sid:      kafka,
+			wantCode: errs.SkillNotFound.Code,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				"/skill/detail", iox.NewJSONReader(web.Sid{Sid: tc.sid}))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.Skill]()
+			s.userServer.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			resp := recorder.MustScan()
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantName, resp.Data.Name)
+		})
+	}
+}
+
+func (s *HandlerTestSuite) TestBackfillPublishSkills() {
+	t := s.T()
+	now := time.Now().UnixMilli()
+	// 有线上库之前的数据，只有制作库
+	err := s.db.Create(&[]dao.Skill{
+		{Id: 1, Name: "mysql", Desc: "mysql_desc", Status: domain.SkillStatusPublished.ToUint8(), Ctime: now, Utime: now},
+		{Id: 2, Name: "redis", Desc: "redis_desc", Status: domain.SkillStatusUnPublished.ToUint8(), Ctime: now, Utime: now},
+		{Id: 3, Name: "kafka", Desc: "kafka_desc", Status: domain.SkillStatusDeleted.ToUint8(), Ctime: now, Utime: now},
+	}).Error
+	require.NoError(t, err)
+	err = s.db.Create(&[]dao.SkillLevel{
+		{Id: 1, Sid: 1, Level: dao.LevelBasic, Desc: "mysql_basic", Ctime: now, Utime: now},
+		{Id: 2, Sid: 2, Level: dao.LevelBasic, Desc: "redis_basic", Ctime: now, Utime: now},
+	}).Error
+	require.NoError(t, err)
+	err = s.db.Create(&[]dao.SkillRef{
+		{Id: 1, Sid: 1, Slid: 1, Rid: 1, Rtype: dao.RTypeQuestion, Ctime: now, Utime: now},
+		{Id: 2, Sid: 2, Slid: 2, Rid: 2, Rtype: dao.RTypeQuestion, Ctime: now, Utime: now},
+	}).Error
+	require.NoError(t, err)
+	// 已经在线上库里面的不会被覆盖
+	err = s.db.Create(&dao.PublishSkill{Id: 4, Name: "es", Desc: "线上库的描述",
+		Status: domain.SkillStatusPublished.ToUint8(), Ctime: now, Utime: now}).Error
+	require.NoError(t, err)
+	err = s.db.Create(&dao.Skill{Id: 4, Name: "es", Desc: "制作库的描述",
+		Status: domain.SkillStatusPublished.ToUint8(), Ctime: now, Utime: now}).Error
+	require.NoError(t, err)
+
+	// 执行两次，结果是一样的
+	for i := 0; i < 2; i++ {
+		err = dao.InitTables(s.db)
+		require.NoError(t, err)
+	}
+
+	var skills []dao.PublishSkill
+	err = s.db.Order("id ASC").Find(&skills).Error
+	require.NoError(t, err)
+	require.Len(t, skills, 2)
+	assert.Equal(t, "mysql", skills[0].Name)
+	assert.Equal(t, "线上库的描述", skills[1].Desc)
+	var levels []dao.PublishSkillLevel
+	err = s.db.Find(&levels).Error
+	require.NoError(t, err)
+	assert.Equal(t, []dao.PublishSkillLevel{
+		{Id: 1, Sid: 1, Level: dao.LevelBasic, Desc: "mysql_basic", Ctime: now, Utime: now},
+	}, levels)
+	var refs []dao.PublishSkillRef
+	err = s.db.Find(&refs).Error
+	require.NoError(t, err)
+	assert.Equal(t, []dao.PublishSkillRef{
+		{Id: 1, Sid: 1, Slid: 1, Rid: 1, Rtype: dao.RTypeQuestion, Ctime: now, Utime: now},
+	}, refs)
+}
+
+func (s *HandlerTestSuite) TestSaveWithNameInTrash() {
+	t := s.T()
+	err := s.db.Create(&dao.Skill{
+		Id:     1,
+		Name:   "mysql",
+		Desc:   "回收站里面的技能",
+		Status: domain.SkillStatusDeleted.ToUint8(),
+		Utime:  123,
+	}).Error
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost,
+		"/skill/save", iox.NewJSONReader(web.SaveReq{
+			Skill: web.Skill{Name: "mysql", Desc: "新的技能"},
+		}))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[int64]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	assert.Equal(t, test.Result[int64]{
+		Code: errs.SkillNameInTrash.Code,
+		Msg:  errs.SkillNameInTrash.Msg,
+	}, recorder.MustScan())
+	// 回收站里面的技能没有变化
+	var sk dao.Skill
+	err = s.db.Where("id = ?", 1).First(&sk).Error
+	require.NoError(t, err)
+	assert.Equal(t, domain.SkillStatusDeleted.ToUint8(), sk.Status)
+	assert.Equal(t, "回收站里面的技能", sk.Desc)
+}
+
+func (s *HandlerTestSuite) TestTrashList() {
+	t := s.T()
+	err := s.db.Create(&[]dao.Skill{
+		{Id: 1, Name: "mysql", Status: domain.SkillStatusUnPublished.ToUint8(), Utime: time.Unix(1, 0).UnixMilli()},
+		{Id: 2, Name: "redis", Status: domain.SkillStatusDeleted.ToUint8(), Utime: time.Unix(2, 0).UnixMilli()},
+		{Id: 3, Name: "kafka", Status: domain.SkillStatusDeleted.ToUint8(), Utime: time.Unix(3, 0).UnixMilli()},
+	}).Error
+	require.NoError(t, err)
+	newSkill := func(id int64, name string, status domain.SkillStatus, utime int64) web.Skill {
+		return web.Skill{
+			ID:     id,
+			Name:   name,
+			Status: status.ToUint8(),
+			Utime:  time.Unix(utime, 0).Format(time.DateTime),
+			Basic: web.SkillLevel{
+				Questions: []web.Question{},
+				Cases:     []web.Case{},
+			},
+			Intermediate: web.SkillLevel{
+				Questions: []web.Question{},
+				Cases:     []web.Case{},
+			},
+			Advanced: web.SkillLevel{
+				Questions: []web.Question{},
+				Cases:     []web.Case{},
+			},
+		}
+	}
+	testCases := []struct {
+		name     string
+		path     string
+		wantResp web.SkillList
+	}{
+		{
+			name: "回收站按照删除时间倒序",
+			path: "/skill/trash/list",
+			wantResp: web.SkillList{
+				Total: 2,
+				Skills: []web.Skill{
+					newSkill(3, "kafka", domain.SkillStatusDeleted, 3),
+					newSkill(2, "redis", domain.SkillStatusDeleted, 2),
+				},
+			},
+		},
+		{
+			name: "列表不包含回收站",
+			path: "/skill/list",
+			wantResp: web.SkillList{
+				Total: 1,
+				Skills: []web.Skill{
+					newSkill(1, "mysql", domain.SkillStatusUnPublished, 1),
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				tc.path, iox.NewJSONReader(web.Page{Limit: 10}))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.SkillList]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, 200, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan().Data)
+		})
+	}
+}
+
+func (s *HandlerTestSuite) TestPurgeTrashJob() {
+	t := s.T()
+	expired := time.Now().Add(-31 * 24 * time.Hour).UnixMilli()
+	err := s.db.Create(&[]dao.Skill{
+		{Id: 1, Name: "过期的技能", Status: domain.SkillStatusDeleted.ToUint8(), Utime: expired},
+		{Id: 2, Name: "刚删除的技能", Status: domain.SkillStatusDeleted.ToUint8(), Utime: time.Now().UnixMilli()},
+		{Id: 3, Name: "很久没有更新的技能", Status: domain.SkillStatusPublished.ToUint8(), Utime: expired},
+	}).Error
+	require.NoError(t, err)
+	err = s.db.Create(&[]dao.SkillLevel{
+		{Id: 1, Sid: 1, Level: dao.LevelBasic},
+		{Id: 2, Sid: 3, Level: dao.LevelBasic},
+	}).Error
+	require.NoError(t, err)
+	err = s.db.Create(&[]dao.SkillRef{
+		{Id: 1, Sid: 1, Slid: 1, Rid: 1, Rtype: dao.RTypeQuestion},
+		{Id: 2, Sid: 3, Slid: 2, Rid: 1, Rtype: dao.RTypeQuestion},
+	}).Error
+	require.NoError(t, err)
+	s.producer.EXPECT().Produce(gomock.Any(), event.NewSyncDeleteEvent(1)).Return(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	err = s.purgeJob.Run(ctx)
+	require.NoError(t, err)
+
+	_, err = s.dao.Info(ctx, 1)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	levels, err := s.dao.SkillLevelInfo(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, levels)
+	refs, err := s.dao.Refs(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+	for _, id := range []int64{2, 3} {
+		_, err = s.dao.Info(ctx, id)
+		assert.NoError(t, err)
+	}
+	refs, err = s.dao.Refs(ctx, 3)
+	require.NoError(t, err)
+	assert.Len(t, refs, 1)
+}
+
 func (s *HandlerTestSuite) assertSkill(wantSKill dao.Skill, actualSkill dao.Skill) {
 	t := s.T()
 	require.True(t, actualSkill.Id > 0)
@@ -0,0 +1,323 @@
+//go:build e2e
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ecache"
+	"github.com/ecodeclub/ekit/iox"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ginx/session"
+	"github.com/ecodeclub/mq-api"
+	"github.com/ecodeclub/webook/internal/cases"
+	casemocks "github.com/ecodeclub/webook/internal/cases/mocks"
+	baguwen "github.com/ecodeclub/webook/internal/question"
+	quemocks "github.com/ecodeclub/webook/internal/question/mocks"
+	"github.com/ecodeclub/webook/internal/skill"
+	"github.com/ecodeclub/webook/internal/skill/internal/domain"
+	"github.com/ecodeclub/webook/internal/skill/internal/errs"
+	"github.com/ecodeclub/webook/internal/skill/internal/event"
+	"github.com/ecodeclub/webook/internal/skill/internal/event/consumer"
+	evemocks "github.com/ecodeclub/webook/internal/skill/internal/event/mocks"
+	"github.com/ecodeclub/webook/internal/skill/internal/integration/startup"
+	"github.com/ecodeclub/webook/internal/skill/internal/repository/dao"
+	"github.com/ecodeclub/webook/internal/skill/internal/web"
+	"github.com/ecodeclub/webook/internal/test"
+	testioc "github.com/ecodeclub/webook/internal/test/ioc"
+	"github.com/ecodeclub/webook/internal/test/mocks"
+	"github.com/ego-component/egorm"
+	"github.com/gin-gonic/gin"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/server/egin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type MasteryHandlerTestSuite struct {
+	suite.Suite
+	server     *egin.Component
+	db         *egorm.Component
+	dao        dao.SkillDAO
+	ec         ecache.Cache
+	ctrl       *gomock.Controller
+	examineSvc *quemocks.MockExamineService
+	module     *skill.Module
+}
+
+func (s *MasteryHandlerTestSuite) SetupSuite() {
+	ctrl := gomock.NewController(s.T())
+	queSvc := quemocks.NewMockService(ctrl)
+	queSvc.EXPECT().GetPubByIDs(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, ids []int64) ([]baguwen.Question, error) {
+			return slice.Map(ids, func(idx int, src int64) baguwen.Question {
+				return baguwen.Question{
+					Id:    src,
+					Title: "这是问题" + strconv.FormatInt(src, 10),
+				}
+			}), nil
+		}).AnyTimes()
+	s.ctrl = ctrl
+	s.examineSvc = quemocks.NewMockExamineService(ctrl)
+	module, err := startup.InitModule(
+		&baguwen.Module{Svc: queSvc, ExamineSvc: s.examineSvc},
+		&cases.Module{Svc: casemocks.NewMockService(ctrl)},
+		evemocks.NewMockSyncEventProducer(ctrl),
+	)
+	require.NoError(s.T(), err)
+	s.module = module
+	econf.Set("server", map[string]any{"contextTimeout": "1s"})
+	server := egin.Load("server").Build()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set("_session", session.NewMemorySession(session.Claims{
+			Uid: uid,
+		}))
+	})
+	module.Hdl.PrivateRoutes(server.Engine)
+	s.server = server
+	s.db = testioc.InitDB()
+	err = dao.InitTables(s.db)
+	require.NoError(s.T(), err)
+	s.dao = dao.NewSkillDAO(s.db)
+	s.ec = testioc.InitCache()
+}
+
+func (s *MasteryHandlerTestSuite) SetupTest() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	// mysql 的三个等级分别关联了 1,2 / 3 / 4 号题目
+	s.publish(t, ctx, 1, "mysql", map[string][]int64{
+		dao.LevelBasic:        {1, 2},
+		dao.LevelIntermediate: {3},
+		dao.LevelAdvanced:     {4},
+	})
+	// redis 只有基础等级关联了 5 号题目
+	s.publish(t, ctx, 2, "redis", map[string][]int64{
+		dao.LevelBasic: {5},
+	})
+	// 没有发表的技能不参与计算
+	_, err := s.dao.Create(ctx, dao.Skill{
+		Name:   "kafka",
+		Status: domain.SkillStatusUnPublished.ToUint8(),
+	}, []dao.SkillLevel{{Level: dao.LevelBasic}})
+	require.NoError(t, err)
+}
+
+func (s *MasteryHandlerTestSuite) TearDownTest() {
+	for _, table := range []string{"skill", "skill_level", "skill_refs",
+		"publish_skill", "publish_skill_level", "publish_skill_refs"} {
+		err := s.db.Exec(fmt.Sprintf("TRUNCATE TABLE `%s`", table)).Error
+		require.NoError(s.T(), err)
+	}
+	_, err := s.ec.Delete(context.Background(), s.masteryKey(), s.masteryVersionKey())
+	require.NoError(s.T(), err)
+}
+
+// publish 发表一个技能，levels 是每个等级关联的题目
+func (s *MasteryHandlerTestSuite) publish(t *testing.T, ctx context.Context,
+	sid int64, name string, levels map[string][]int64) {
+	sls := make([]dao.SkillLevel, 0, len(levels))
+	for _, level := range []string{dao.LevelBasic, dao.LevelIntermediate, dao.LevelAdvanced} {
+		if _, ok := levels[level]; ok {
+			sls = append(sls, dao.SkillLevel{Level: level, Desc: name + "_" + level})
+		}
+	}
+	_, err := s.dao.Create(ctx, dao.Skill{
+		Id:     sid,
+		Name:   name,
+		Status: domain.SkillStatusUnPublished.ToUint8(),
+	}, sls)
+	require.NoError(t, err)
+	sls, err = s.dao.SkillLevelInfo(ctx, sid)
+	require.NoError(t, err)
+	refs := make([]dao.SkillRef, 0, 8)
+	for _, sl := range sls {
+		for _, qid := range levels[sl.Level] {
+			refs = append(refs, dao.SkillRef{Sid: sid, Slid: sl.Id, Rid: qid, Rtype: dao.RTypeQuestion})
+		}
+	}
+	err = s.dao.SaveRefs(ctx, refs)
+	require.NoError(t, err)
+	_, err = s.dao.Sync(ctx, dao.Skill{
+		Id:     sid,
+		Name:   name,
+		Status: domain.SkillStatusPublished.ToUint8(),
+	}, sls)
+	require.NoError(t, err)
+}
+
+// mockResults 1 号题目基础，2 号没有测试过，3 号高级，4 号完全没通过，5 号中级
+func (s *MasteryHandlerTestSuite) mockResults(times int) {
+	s.examineSvc.EXPECT().GetResults(gomock.Any(), int64(uid), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, uid int64, ids []int64) (map[int64]baguwen.ExamineResult, error) {
+			assert.ElementsMatch(s.T(), []int64{1, 2, 3, 4, 5}, ids)
+			return map[int64]baguwen.ExamineResult{
+				1: {Qid: 1, Result: 1},
+				3: {Qid: 3, Result: 3},
+				4: {Qid: 4, Result: 0},
+				5: {Qid: 5, Result: 2},
+			}, nil
+		}).Times(times)
+}
+
+func (s *MasteryHandlerTestSuite) TestMastery() {
+	testCases := []struct {
+		name     string
+		sid      int64
+		wantCode int
+		wantResp test.Result[web.SkillMastery]
+	}{
+		{
+			name:     "每个等级的达标比例和缺少的题目",
+			sid:      1,
+			wantCode: 200,
+			wantResp: test.Result[web.SkillMastery]{
+				Data: web.SkillMastery{
+					Sid:  1,
+					Name: "mysql",
+					Basic: web.LevelMastery{
+						Total:    2,
+						Mastered: 1,
+						Rate:     0.5,
+						Missing:  []web.Question{{Id: 2, Title: "这是问题2"}},
+					},
+					Intermediate: web.LevelMastery{
+						Total:    1,
+						Mastered: 1,
+						Rate:     1,
+						Missing:  []web.Question{},
+					},
+					Advanced: web.LevelMastery{
+						Total:    1,
+						Mastered: 0,
+						Rate:     0,
+						Missing:  []web.Question{{Id: 4, Title: "这是问题4"}},
+					},
+				},
+			},
+		},
+		{
+			name:     "没有发表的技能",
+			sid:      3,
+			wantCode: 200,
+			wantResp: test.Result[web.SkillMastery]{
+				Code: errs.SkillNotFound.Code,
+				Msg:  errs.SkillNotFound.Msg,
+			},
+		},
+	}
+	// 第二次直接读取缓存
+	s.mockResults(1)
+	for _, tc := range testCases {
+		s.T().Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost,
+				"/skill/mastery", iox.NewJSONReader(web.Sid{Sid: tc.sid}))
+			req.Header.Set("content-type", "application/json")
+			require.NoError(t, err)
+			recorder := test.NewJSONResponseRecorder[web.SkillMastery]()
+			s.server.ServeHTTP(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.MustScan())
+		})
+	}
+}
+
+func (s *MasteryHandlerTestSuite) TestRadar() {
+	t := s.T()
+	s.mockResults(1)
+	assert.Equal(t, web.Radar{
+		Items: []web.RadarItem{
+			{Sid: 2, Name: "redis", Score: 1},
+			{Sid: 1, Name: "mysql", Score: 0.5},
+		},
+	}, s.radar(t))
+	// 命中缓存，不会再查询测试结果
+	assert.Len(t, s.radar(t).Items, 2)
+	ms, err := s.ec.Get(context.Background(), s.masteryKey()).AsBytes()
+	require.NoError(t, err)
+	var cached []domain.SkillMastery
+	require.NoError(t, json.Unmarshal(ms, &cached))
+	assert.Len(t, cached, 2)
+}
+
+func (s *MasteryHandlerTestSuite) TestRadarInvalidatedWhileComputing() {
+	t := s.T()
+	// 查询测试结果之后，回写缓存之前，用户又有了新的测试结果
+	s.examineSvc.EXPECT().GetResults(gomock.Any(), int64(uid), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, uid int64, ids []int64) (map[int64]baguwen.ExamineResult, error) {
+			err := s.module.MasterySvc.OnExamineResult(ctx, uid)
+			require.NoError(t, err)
+			return map[int64]baguwen.ExamineResult{}, nil
+		})
+	assert.Len(t, s.radar(t).Items, 2)
+	// 算出来的旧数据不会被读到
+	_, err := s.ec.Get(context.Background(), s.masteryKey()).AsBytes()
+	assert.Error(t, err)
+	s.mockResults(1)
+	assert.Equal(t, web.Radar{
+		Items: []web.RadarItem{
+			{Sid: 2, Name: "redis", Score: 1},
+			{Sid: 1, Name: "mysql", Score: 0.5},
+		},
+	}, s.radar(t))
+}
+
+func (s *MasteryHandlerTestSuite) TestConsumer() {
+	t := s.T()
+	s.mockResults(2)
+	s.radar(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	val, err := json.Marshal(event.ExamineResultEvent{Uid: uid, Qid: 2, Result: 1})
+	require.NoError(t, err)
+	mockConsumer := mocks.NewMockConsumer(ctrl)
+	mockConsumer.EXPECT().Consume(gomock.Any()).Return(&mq.Message{Value: val}, nil)
+	mockMQ := mocks.NewMockMQ(ctrl)
+	mockMQ.EXPECT().Consumer(event.ExamineResultTopic, gomock.Any()).Return(mockConsumer, nil)
+	c, err := consumer.NewMasteryConsumer(s.module.MasterySvc, mockMQ)
+	require.NoError(t, err)
+	err = c.Consume(context.Background())
+	require.NoError(t, err)
+
+	_, err = s.ec.Get(context.Background(), s.masteryKey()).AsBytes()
+	assert.Error(t, err)
+	// 缓存失效之后重新计算
+	assert.Len(t, s.radar(t).Items, 2)
+}
+
+func (s *MasteryHandlerTestSuite) radar(t *testing.T) web.Radar {
+	req, err := http.NewRequest(http.MethodPost, "/skill/radar", iox.NewJSONReader(nil))
+	req.Header.Set("content-type", "application/json")
+	require.NoError(t, err)
+	recorder := test.NewJSONResponseRecorder[web.Radar]()
+	s.server.ServeHTTP(recorder, req)
+	require.Equal(t, 200, recorder.Code)
+	return recorder.MustScan().Data
+}
+
+// masteryKey 当前版本的缓存
+func (s *MasteryHandlerTestSuite) masteryKey() string {
+	ver, err := s.ec.Get(context.Background(), s.masteryVersionKey()).AsInt64()
+	if err != nil {
+		ver = 0
+	}
+	return fmt.Sprintf("skillmastery:%d:%d", uid, ver)
+}
+
+func (s *MasteryHandlerTestSuite) masteryVersionKey() string {
+	return fmt.Sprintf("skillmastery_version:%d", uid)
+}
+
+func TestMasteryHandler(t *testing.T) {
+	suite.Run(t, new(MasteryHandlerTestSuite))
+}
@@ -6,9 +6,12 @@ import (
 	"sync"
 
 	"github.com/ecodeclub/ecache"
+	"github.com/ecodeclub/mq-api"
 	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
+	"github.com/ecodeclub/webook/internal/skill"
 	"github.com/ecodeclub/webook/internal/skill/internal/event"
+	"github.com/ecodeclub/webook/internal/skill/internal/event/consumer"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository/cache"
 	dao2 "github.com/ecodeclub/webook/internal/skill/internal/repository/dao"
@@ -20,27 +23,37 @@ import (
 	"gorm.io/gorm"
 )
 
-func InitHandler(bm *baguwen.Module, cm *cases.Module, p event.SyncEventProducer) (*web.Handler, error) {
-	wire.Build(testioc.BaseSet, initHandler)
-	return new(web.Handler), nil
+func InitModule(bm *baguwen.Module, cm *cases.Module, p event.SyncEventProducer) (*skill.Module, error) {
+	wire.Build(testioc.BaseSet, initModule)
+	return new(skill.Module), nil
 }
 
-func initHandler(
+func initModule(
 	db *egorm.Component,
 	ec ecache.Cache,
 	queModule *baguwen.Module,
 	caseModule *cases.Module,
-	p event.SyncEventProducer) (*web.Handler, error) {
+	q mq.MQ,
+	p event.SyncEventProducer) (*skill.Module, error) {
 	wire.Build(
 		InitSkillDAO,
-		wire.FieldsOf(new(*baguwen.Module), "Svc"),
+		wire.FieldsOf(new(*baguwen.Module), "Svc", "ExamineSvc"),
 		wire.FieldsOf(new(*cases.Module), "Svc"),
 		cache.NewSkillCache,
 		repository.NewSkillRepo,
 		service.NewSkillService,
+		service.NewMasteryService,
 		web.NewHandler,
+		skill.InitPurgeTrashJob,
+		initMasteryConsumer,
+		wire.Struct(new(skill.Module), "*"),
 	)
-	return new(web.Handler), nil
+	return new(skill.Module), nil
+}
+
+// initMasteryConsumer 测试里面不启动，需要的时候手动构造消费者调用 Consume
+func initMasteryConsumer(svc service.MasteryService, q mq.MQ) (*consumer.MasteryConsumer, error) {
+	return consumer.NewMasteryConsumer(svc, q)
 }
 
 var daoOnce = sync.Once{}
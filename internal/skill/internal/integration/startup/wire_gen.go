@@ -10,9 +10,12 @@ import (
 	"sync"
 
 	"github.com/ecodeclub/ecache"
+	"github.com/ecodeclub/mq-api"
 	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
+	"github.com/ecodeclub/webook/internal/skill"
 	"github.com/ecodeclub/webook/internal/skill/internal/event"
+	"github.com/ecodeclub/webook/internal/skill/internal/event/consumer"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository/cache"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository/dao"
@@ -25,29 +28,48 @@ import (
 
 // Injectors from wire.go:
 
-func InitHandler(bm *baguwen.Module, cm *cases.Module, p event.SyncEventProducer) (*web.Handler, error) {
+func InitModule(bm *baguwen.Module, cm *cases.Module, p event.SyncEventProducer) (*skill.Module, error) {
 	db := testioc.InitDB()
 	cache := testioc.InitCache()
-	handler, err := initHandler(db, cache, bm, cm, p)
+	mq := testioc.InitMQ()
+	module, err := initModule(db, cache, bm, cm, mq, p)
 	if err != nil {
 		return nil, err
 	}
-	return handler, nil
+	return module, nil
 }
 
-func initHandler(db *gorm.DB, ec ecache.Cache, queModule *baguwen.Module, caseModule *cases.Module, p event.SyncEventProducer) (*web.Handler, error) {
+func initModule(db *gorm.DB, ec ecache.Cache, queModule *baguwen.Module, caseModule *cases.Module, q mq.MQ, p event.SyncEventProducer) (*skill.Module, error) {
 	skillDAO := InitSkillDAO(db)
 	skillCache := cache.NewSkillCache(ec)
 	skillRepo := repository.NewSkillRepo(skillDAO, skillCache)
 	skillService := service.NewSkillService(skillRepo, p)
+	examineService := queModule.ExamineSvc
+	masteryService := service.NewMasteryService(skillRepo, examineService)
 	serviceService := queModule.Svc
 	service2 := caseModule.Svc
-	handler := web.NewHandler(skillService, serviceService, service2)
-	return handler, nil
+	handler := web.NewHandler(skillService, masteryService, serviceService, service2)
+	purgeTrashJob := skill.InitPurgeTrashJob(skillService)
+	masteryConsumer, err := initMasteryConsumer(masteryService, q)
+	if err != nil {
+		return nil, err
+	}
+	module := &skill.Module{
+		Hdl:             handler,
+		PurgeTrashJob:   purgeTrashJob,
+		MasterySvc:      masteryService,
+		MasteryConsumer: masteryConsumer,
+	}
+	return module, nil
 }
 
 // wire.go:
 
+// initMasteryConsumer 测试里面不启动，需要的时候手动构造消费者调用 Consume
+func initMasteryConsumer(svc service.MasteryService, q mq.MQ) (*consumer.MasteryConsumer, error) {
+	return consumer.NewMasteryConsumer(svc, q)
+}
+
 var daoOnce = sync.Once{}
 
 func InitTableOnce(db *gorm.DB) {
@@ -2,17 +2,33 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/ecodeclub/ecache"
+	"github.com/ecodeclub/webook/internal/skill/internal/domain"
 )
 
 type SkillCache interface {
 	// 缓存总数
 	GetTotal(ctx context.Context) (int64, error)
 	SetTotal(ctx context.Context, total int64) error
+
+	// MasteryVersion 用户的掌握情况的版本号，有新的测试结果的时候会加一
+	MasteryVersion(ctx context.Context, uid int64) (int64, error)
+	// GetMastery 用户在所有技能上的掌握情况，只有 version 是最新的版本号才有意义
+	GetMastery(ctx context.Context, uid, version int64) ([]domain.SkillMastery, error)
+	// SetMastery version 是开始计算之前读到的版本号，
+	// 计算的过程中有新的测试结果的话，写进去的就是旧版本，不会再被读到
+	SetMastery(ctx context.Context, uid, version int64, ms []domain.SkillMastery) error
+	// DelMastery 增加版本号，旧版本的缓存等过期就可以了
+	DelMastery(ctx context.Context, uid int64) error
 }
 
+// masteryExpiration 技能发表之后最多过这么久，用户就能看到新的掌握情况
+const masteryExpiration = time.Minute * 30
+
 type skillCache struct {
 	ec ecache.Cache
 }
@@ -37,3 +53,43 @@ func (s *skillCache) SetTotal(ctx context.Context, total int64) error {
 func (s *skillCache) totalKey() string {
 	return "total"
 }
+
+func (s *skillCache) MasteryVersion(ctx context.Context, uid int64) (int64, error) {
+	val := s.ec.Get(ctx, s.masteryVersionKey(uid))
+	if val.KeyNotFound() {
+		return 0, nil
+	}
+	return val.AsInt64()
+}
+
+func (s *skillCache) GetMastery(ctx context.Context, uid, version int64) ([]domain.SkillMastery, error) {
+	data, err := s.ec.Get(ctx, s.masteryKey(uid, version)).AsBytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.SkillMastery
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+func (s *skillCache) SetMastery(ctx context.Context, uid, version int64, ms []domain.SkillMastery) error {
+	data, err := json.Marshal(ms)
+	if err != nil {
+		return err
+	}
+	return s.ec.Set(ctx, s.masteryKey(uid, version), data, masteryExpiration)
+}
+
+func (s *skillCache) DelMastery(ctx context.Context, uid int64) error {
+	_, err := s.ec.IncrBy(ctx, s.masteryVersionKey(uid), 1)
+	return err
+}
+
+func (s *skillCache) masteryKey(uid, version int64) string {
+	return fmt.Sprintf("mastery:%d:%d", uid, version)
+}
+
+// masteryVersionKey 版本号不设置过期时间，每个用户只有一个数字
+func (s *skillCache) masteryVersionKey(uid int64) string {
+	return fmt.Sprintf("mastery_version:%d", uid)
+}
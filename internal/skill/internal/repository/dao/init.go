@@ -1,11 +1,46 @@
 package dao
 
-import "github.com/ego-component/egorm"
+import (
+	"github.com/ecodeclub/webook/internal/skill/internal/domain"
+	"github.com/ego-component/egorm"
+	"gorm.io/gorm"
+)
 
 func InitTables(db *egorm.Component) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&Skill{},
 		&SkillLevel{},
 		&SkillRef{},
+		&PublishSkill{},
+		&PublishSkillLevel{},
+		&PublishSkillRef{},
 	)
+	if err != nil {
+		return err
+	}
+	return backfillPublishSkills(db)
+}
+
+// backfillPublishSkills 在有线上库之前，C 端直接读取制作库，
+// 所以已发表但是线上库里面还没有的技能，要连同等级和关联关系一起复制过去。
+// 线上库里面已经有的不会被覆盖，重复执行也没关系
+func backfillPublishSkills(db *egorm.Component) error {
+	var sids []int64
+	err := db.Model(&Skill{}).
+		Where("status = ? AND id NOT IN (?)", domain.SkillStatusPublished.ToUint8(),
+			db.Model(&PublishSkill{}).Select("id")).
+		Pluck("id", &sids).Error
+	if err != nil {
+		return err
+	}
+	d := &skillDAO{db: db}
+	for _, sid := range sids {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			return d.publish(tx, sid)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -2,18 +2,29 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/webook/internal/skill/internal/domain"
 	"github.com/ego-component/egorm"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrRecordNotFound = gorm.ErrRecordNotFound
+	// ErrNameInTrash 回收站里面有同名的技能，需要先恢复或者彻底删除
+	ErrNameInTrash = errors.New("同名的技能在回收站里面")
+)
+
 type SkillDAO interface {
 	// Create 管理端接口
 	Create(ctx context.Context, skill Skill, skillLevels []SkillLevel) (int64, error)
 	// Update 管理端接口
 	Update(ctx context.Context, skill Skill, skillLevels []SkillLevel) error
+	// Sync 保存到制作库，并且把技能、等级和关联关系整个同步到线上库
+	Sync(ctx context.Context, skill Skill, skillLevels []SkillLevel) (int64, error)
 	SaveRefs(ctx context.Context, reqs []SkillRef) error
 	// List 列表
 	List(ctx context.Context, offset, limit int) ([]Skill, error)
@@ -26,8 +37,34 @@ type SkillDAO interface {
 	// RefsByLevelIDs ids 为 SkillLevel 的 ID
 	RefsByLevelIDs(ctx context.Context, ids []int64) ([]SkillRef, error)
 	Count(ctx context.Context) (int64, error)
+
+	// Unpublish 从线上库里面删除，制作库里面的数据保留
+	Unpublish(ctx context.Context, id int64) error
+	// Delete 从线上库里面删除，并且放入回收站
+	Delete(ctx context.Context, id int64) error
+	// Restore 将回收站里面的技能恢复成已下线
+	Restore(ctx context.Context, id int64) error
+	TrashList(ctx context.Context, offset, limit int) ([]Skill, error)
+	TrashCount(ctx context.Context) (int64, error)
+	// ListDeletedBefore 回收站里面在 utime 之前删除的技能
+	ListDeletedBefore(ctx context.Context, utime int64, limit int) ([]Skill, error)
+	// Purge 彻底删除回收站里面的技能，连同等级和关联关系
+	Purge(ctx context.Context, id int64) error
+
+	// 下面是线上库的查询接口
+	PubList(ctx context.Context, offset, limit int) ([]PublishSkill, error)
+	PubCount(ctx context.Context) (int64, error)
+	PubInfo(ctx context.Context, id int64) (PublishSkill, error)
+	PubSkillLevelInfo(ctx context.Context, sid int64) ([]PublishSkillLevel, error)
+	PubSkillLevelInfoByIDs(ctx context.Context, sids []int64) ([]PublishSkillLevel, error)
+	PubRefs(ctx context.Context, sid int64) ([]PublishSkillRef, error)
+	// PubAll 线上库的全部技能，技能的数量不会太多
+	PubAll(ctx context.Context) ([]PublishSkill, error)
+	PubRefsBySids(ctx context.Context, sids []int64) ([]PublishSkillRef, error)
 }
 
+var deletedStatus = domain.SkillStatusDeleted.ToUint8()
+
 type skillDAO struct {
 	db *egorm.Component
 }
@@ -50,11 +87,21 @@ func (s *skillDAO) Create(ctx context.Context, skill Skill, skillLevels []SkillL
 }
 
 func (s *skillDAO) create(tx *gorm.DB, skill Skill, skillLevels []SkillLevel) (Skill, []SkillLevel, error) {
+	// 同名的时候会覆盖已有的技能，但是不能把回收站里面的技能悄悄恢复了
+	var cnt int64
+	err := tx.Model(&Skill{}).Where("name = ? AND status = ?", skill.Name, deletedStatus).
+		Count(&cnt).Error
+	if err != nil {
+		return skill, nil, err
+	}
+	if cnt > 0 {
+		return skill, nil, ErrNameInTrash
+	}
 	skill.Utime = time.Now().UnixMilli()
 	skill.Ctime = time.Now().UnixMilli()
-	err := tx.Clauses(clause.OnConflict{
+	err = tx.Clauses(clause.OnConflict{
 		DoUpdates: clause.AssignmentColumns([]string{
-			"labels", "desc", "utime",
+			"labels", "desc", "status", "utime",
 		}),
 		Columns: []clause.Column{{Name: "name"}},
 	}).Create(&skill).Error
@@ -86,15 +133,24 @@ func (s *skillDAO) Update(ctx context.Context, skill Skill, skillLevels []SkillL
 }
 
 func (s *skillDAO) update(tx *gorm.DB, skill Skill, skillLevels []SkillLevel) (Skill, []SkillLevel, error) {
-	err := tx.Model(&skill).Where("id = ?", skill.Id).Updates(map[string]any{
+	// 回收站里面的技能只能通过 Restore 恢复，不会被修改
+	fields := map[string]any{
 		"labels": skill.Labels,
 		"name":   skill.Name,
 		"desc":   skill.Desc,
 		"utime":  time.Now().UnixMilli(),
-	}).Error
-	if err != nil {
-		return skill, skillLevels, err
 	}
+	if skill.Status != domain.SkillStatusUnknown.ToUint8() {
+		fields["status"] = skill.Status
+	}
+	res := tx.Model(&skill).Where("id = ? AND status <> ?", skill.Id, deletedStatus).Updates(fields)
+	if res.Error != nil {
+		return skill, skillLevels, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return skill, skillLevels, gorm.ErrRecordNotFound
+	}
+	var err error
 	for i := range skillLevels {
 		skillLevels[i].Sid = skill.Id
 		skillLevels[i].Utime = time.Now().UnixMilli()
@@ -108,6 +164,71 @@ func (s *skillDAO) update(tx *gorm.DB, skill Skill, skillLevels []SkillLevel) (S
 	return skill, skillLevels, err
 }
 
+func (s *skillDAO) Sync(ctx context.Context, skill Skill, skillLevels []SkillLevel) (int64, error) {
+	var id int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if skill.Id == 0 {
+			skill, _, err = s.create(tx, skill, skillLevels)
+		} else {
+			skill, _, err = s.update(tx, skill, skillLevels)
+		}
+		if err != nil {
+			return err
+		}
+		id = skill.Id
+		return s.publish(tx, id)
+	})
+	return id, err
+}
+
+// publish 关联关系是单独保存的，所以从制作库里面重新读出来整个覆盖线上库
+func (s *skillDAO) publish(tx *gorm.DB, sid int64) error {
+	var skill Skill
+	err := tx.Where("id = ?", sid).First(&skill).Error
+	if err != nil {
+		return err
+	}
+	pubSkill := PublishSkill(skill)
+	err = tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&pubSkill).Error
+	if err != nil {
+		return err
+	}
+
+	var levels []SkillLevel
+	err = tx.Where("sid = ?", sid).Find(&levels).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("sid = ?", sid).Delete(&PublishSkillLevel{}).Error
+	if err != nil {
+		return err
+	}
+	if len(levels) > 0 {
+		pubLevels := slice.Map(levels, func(idx int, src SkillLevel) PublishSkillLevel {
+			return PublishSkillLevel(src)
+		})
+		err = tx.Create(&pubLevels).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var refs []SkillRef
+	err = tx.Where("sid = ?", sid).Find(&refs).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("sid = ?", sid).Delete(&PublishSkillRef{}).Error
+	if err != nil || len(refs) == 0 {
+		return err
+	}
+	pubRefs := slice.Map(refs, func(idx int, src SkillRef) PublishSkillRef {
+		return PublishSkillRef(src)
+	})
+	return tx.Create(&pubRefs).Error
+}
+
 func (s *skillDAO) SaveRefs(ctx context.Context, refs []SkillRef) error {
 	if len(refs) == 0 {
 		return nil
@@ -125,6 +246,7 @@ func (s *skillDAO) SaveRefs(ctx context.Context, refs []SkillRef) error {
 func (s *skillDAO) List(ctx context.Context, offset, limit int) ([]Skill, error) {
 	var skills []Skill
 	err := s.db.WithContext(ctx).Model(&Skill{}).
+		Where("status <> ?", deletedStatus).
 		Order("id desc").
 		Offset(offset).Limit(limit).Find(&skills).Error
 	return skills, err
@@ -157,10 +279,137 @@ func (s *skillDAO) Refs(ctx context.Context, id int64) ([]SkillRef, error) {
 
 func (s *skillDAO) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := s.db.WithContext(ctx).Model(&Skill{}).Count(&count).Error
+	err := s.db.WithContext(ctx).Model(&Skill{}).
+		Where("status <> ?", deletedStatus).Count(&count).Error
+	return count, err
+}
+
+func (s *skillDAO) Unpublish(ctx context.Context, id int64) error {
+	return s.takeDown(ctx, id, domain.SkillStatusUnPublished.ToUint8())
+}
+
+func (s *skillDAO) Delete(ctx context.Context, id int64) error {
+	return s.takeDown(ctx, id, deletedStatus)
+}
+
+func (s *skillDAO) takeDown(ctx context.Context, id int64, status uint8) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ?", id).Delete(&PublishSkill{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("sid = ?", id).Delete(&PublishSkillLevel{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("sid = ?", id).Delete(&PublishSkillRef{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&Skill{}).
+			Where("id = ? AND status <> ?", id, deletedStatus).
+			Updates(map[string]any{
+				"status": status,
+				"utime":  time.Now().UnixMilli(),
+			}).Error
+	})
+}
+
+func (s *skillDAO) Restore(ctx context.Context, id int64) error {
+	return s.db.WithContext(ctx).Model(&Skill{}).
+		Where("id = ? AND status = ?", id, deletedStatus).
+		Updates(map[string]any{
+			"status": domain.SkillStatusUnPublished.ToUint8(),
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (s *skillDAO) TrashList(ctx context.Context, offset, limit int) ([]Skill, error) {
+	var res []Skill
+	err := s.db.WithContext(ctx).Where("status = ?", deletedStatus).
+		Offset(offset).Limit(limit).Order("utime DESC").Find(&res).Error
+	return res, err
+}
+
+func (s *skillDAO) TrashCount(ctx context.Context) (int64, error) {
+	var res int64
+	err := s.db.WithContext(ctx).Model(&Skill{}).
+		Where("status = ?", deletedStatus).Count(&res).Error
+	return res, err
+}
+
+func (s *skillDAO) ListDeletedBefore(ctx context.Context, utime int64, limit int) ([]Skill, error) {
+	var res []Skill
+	err := s.db.WithContext(ctx).
+		Where("status = ? AND utime < ?", deletedStatus, utime).
+		Order("utime ASC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (s *skillDAO) Purge(ctx context.Context, id int64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND status = ?", id, deletedStatus).Delete(&Skill{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		err := tx.Where("sid = ?", id).Delete(&SkillLevel{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("sid = ?", id).Delete(&SkillRef{}).Error
+	})
+}
+
+func (s *skillDAO) PubList(ctx context.Context, offset, limit int) ([]PublishSkill, error) {
+	var skills []PublishSkill
+	err := s.db.WithContext(ctx).Model(&PublishSkill{}).
+		Order("id desc").
+		Offset(offset).Limit(limit).Find(&skills).Error
+	return skills, err
+}
+
+func (s *skillDAO) PubCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&PublishSkill{}).Count(&count).Error
 	return count, err
 }
 
+func (s *skillDAO) PubInfo(ctx context.Context, id int64) (PublishSkill, error) {
+	var skill PublishSkill
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&skill).Error
+	return skill, err
+}
+
+func (s *skillDAO) PubSkillLevelInfo(ctx context.Context, sid int64) ([]PublishSkillLevel, error) {
+	var levels []PublishSkillLevel
+	err := s.db.WithContext(ctx).Where("sid = ?", sid).Find(&levels).Error
+	return levels, err
+}
+
+func (s *skillDAO) PubSkillLevelInfoByIDs(ctx context.Context, sids []int64) ([]PublishSkillLevel, error) {
+	var levels []PublishSkillLevel
+	err := s.db.WithContext(ctx).Where("sid IN ?", sids).Find(&levels).Error
+	return levels, err
+}
+
+func (s *skillDAO) PubRefs(ctx context.Context, sid int64) ([]PublishSkillRef, error) {
+	var refs []PublishSkillRef
+	err := s.db.WithContext(ctx).Where("sid = ?", sid).Find(&refs).Error
+	return refs, err
+}
+
+func (s *skillDAO) PubAll(ctx context.Context) ([]PublishSkill, error) {
+	var skills []PublishSkill
+	err := s.db.WithContext(ctx).Order("id desc").Find(&skills).Error
+	return skills, err
+}
+
+func (s *skillDAO) PubRefsBySids(ctx context.Context, sids []int64) ([]PublishSkillRef, error) {
+	var refs []PublishSkillRef
+	err := s.db.WithContext(ctx).Where("sid IN ?", sids).Find(&refs).Error
+	return refs, err
+}
+
 func NewSkillDAO(db *egorm.Component) SkillDAO {
 	return &skillDAO{
 		db: db,
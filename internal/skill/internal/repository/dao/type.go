@@ -8,15 +8,24 @@ type Skill struct {
 	// Name 描述的是什么技能
 	Name string `gorm:"unique"`
 	// 技能本身的描述
-	Desc  string
-	Ctime int64
-	Utime int64 `gorm:"index"`
+	Desc string
+	// 1 代表有修改还没有发表，或者已经下线了
+	Status uint8 `gorm:"type:tinyint(3);not null;default:2;comment:1-未发表 2-已发表 3-已删除"`
+	Ctime  int64
+	Utime  int64 `gorm:"index"`
 }
 
 func (Skill) TableName() string {
 	return "skill"
 }
 
+// PublishSkill 线上库，C 端只能看到这里的数据
+type PublishSkill Skill
+
+func (PublishSkill) TableName() string {
+	return "publish_skill"
+}
+
 type SkillLevel struct {
 	Id  int64
 	Sid int64 `gorm:"uniqueIndex:sid_level"`
@@ -33,6 +42,12 @@ func (SkillLevel) TableName() string {
 	return "skill_level"
 }
 
+type PublishSkillLevel SkillLevel
+
+func (PublishSkillLevel) TableName() string {
+	return "publish_skill_level"
+}
+
 // SkillRef 是一个面试者需要准备好面试题，面试案例之后，才可以写到简历上的
 // - save: 会把所有的 rid 和 rtype 传过来，删除原本的，而后插入新的
 // 也就是说，Skill 里面的 Save 只是保存基本的信息，这里是新的保存关联关系的接口
@@ -62,3 +77,9 @@ const (
 func (SkillRef) TableName() string {
 	return "skill_refs"
 }
+
+type PublishSkillRef SkillRef
+
+func (PublishSkillRef) TableName() string {
+	return "publish_skill_refs"
+}
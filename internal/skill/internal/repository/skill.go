@@ -16,10 +16,17 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+var (
+	ErrRecordNotFound = dao.ErrRecordNotFound
+	ErrNameInTrash    = dao.ErrNameInTrash
+)
+
 type SkillRepo interface {
 	// Save 管理端接口
 	// 和 Update 返回值为  skill 的 id
 	Save(ctx context.Context, skill domain.Skill) (int64, error)
+	// Sync 保存并且同步到线上库
+	Sync(ctx context.Context, skill domain.Skill) (int64, error)
 	SaveRefs(ctx context.Context, skill domain.Skill) error
 	// List 列表
 	List(ctx context.Context, offset, limit int) ([]domain.Skill, error)
@@ -27,12 +34,32 @@ type SkillRepo interface {
 	Info(ctx context.Context, id int64) (domain.Skill, error)
 	Count(ctx context.Context) (int64, error)
 	RefsByLevelIDs(ctx context.Context, ids []int64) ([]domain.SkillLevel, error)
+
+	Unpublish(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	TrashList(ctx context.Context, offset, limit int) ([]domain.Skill, error)
+	TrashTotal(ctx context.Context) (int64, error)
+	ListDeletedBefore(ctx context.Context, t time.Time, limit int) ([]domain.Skill, error)
+	Purge(ctx context.Context, id int64) error
+
+	// PubList 线上库的列表，和 List 一样不包含关联关系
+	PubList(ctx context.Context, offset, limit int) ([]domain.Skill, error)
+	PubCount(ctx context.Context) (int64, error)
+	PubInfo(ctx context.Context, id int64) (domain.Skill, error)
+	// PubAll 线上库的全部技能，包含关联关系
+	PubAll(ctx context.Context) ([]domain.Skill, error)
+
+	// 用户的技能掌握情况，只走缓存，version 的含义参考 cache.SkillCache
+	MasteryVersion(ctx context.Context, uid int64) (int64, error)
+	GetMastery(ctx context.Context, uid, version int64) ([]domain.SkillMastery, error)
+	SetMastery(ctx context.Context, uid, version int64, ms []domain.SkillMastery) error
+	DelMastery(ctx context.Context, uid int64) error
 }
 type skillRepo struct {
 	skillDao dao.SkillDAO
-	// 暂时没用上
-	cache  cache.SkillCache
-	logger *elog.Component
+	cache    cache.SkillCache
+	logger   *elog.Component
 }
 
 func (s *skillRepo) RefsByLevelIDs(ctx context.Context, ids []int64) ([]domain.SkillLevel, error) {
@@ -60,11 +87,7 @@ func (s *skillRepo) Save(ctx context.Context, skill domain.Skill) (int64, error)
 	var id int64
 	var err error
 	skillDao := s.skillToEntity(skill)
-	levels := []dao.SkillLevel{
-		s.skillLevelToEntity(skill.Basic, dao.LevelBasic),
-		s.skillLevelToEntity(skill.Intermediate, dao.LevelIntermediate),
-		s.skillLevelToEntity(skill.Advanced, dao.LevelAdvanced),
-	}
+	levels := s.skillLevelsToEntity(skill)
 	if skill.ID == 0 {
 		id, err = s.skillDao.Create(ctx, skillDao, levels)
 	} else {
@@ -75,6 +98,10 @@ func (s *skillRepo) Save(ctx context.Context, skill domain.Skill) (int64, error)
 
 }
 
+func (s *skillRepo) Sync(ctx context.Context, skill domain.Skill) (int64, error) {
+	return s.skillDao.Sync(ctx, s.skillToEntity(skill), s.skillLevelsToEntity(skill))
+}
+
 func (s *skillRepo) SaveRefs(ctx context.Context, skill domain.Skill) error {
 	refs := make([]dao.SkillRef, 0, 32)
 	refs = append(refs, s.toRef(skill.ID, skill.Basic)...)
@@ -124,7 +151,89 @@ func (s *skillRepo) List(ctx context.Context, offset, limit int) ([]domain.Skill
 	if err != nil {
 		return nil, err
 	}
-	slm := mapx.NewMultiBuiltinMap[int64, dao.SkillLevel](len(ids))
+	return s.skillsToListDomain(skillList, sls), nil
+}
+
+func (s *skillRepo) PubList(ctx context.Context, offset, limit int) ([]domain.Skill, error) {
+	skillList, err := s.skillDao.PubList(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := slice.Map(skillList, func(idx int, src dao.PublishSkill) int64 {
+		return src.Id
+	})
+	sls, err := s.skillDao.PubSkillLevelInfoByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return s.skillsToListDomain(
+		slice.Map(skillList, func(idx int, src dao.PublishSkill) dao.Skill {
+			return dao.Skill(src)
+		}),
+		slice.Map(sls, func(idx int, src dao.PublishSkillLevel) dao.SkillLevel {
+			return dao.SkillLevel(src)
+		})), nil
+}
+
+func (s *skillRepo) PubAll(ctx context.Context) ([]domain.Skill, error) {
+	skillList, err := s.skillDao.PubAll(ctx)
+	if err != nil || len(skillList) == 0 {
+		return nil, err
+	}
+	ids := slice.Map(skillList, func(idx int, src dao.PublishSkill) int64 {
+		return src.Id
+	})
+	var eg errgroup.Group
+	var sls []dao.PublishSkillLevel
+	var refs []dao.PublishSkillRef
+	eg.Go(func() error {
+		var err error
+		sls, err = s.skillDao.PubSkillLevelInfoByIDs(ctx, ids)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		refs, err = s.skillDao.PubRefsBySids(ctx, ids)
+		return err
+	})
+	if err = eg.Wait(); err != nil {
+		return nil, err
+	}
+	slm := mapx.NewMultiBuiltinMap[int64, dao.SkillLevel](len(skillList))
+	for _, sl := range sls {
+		_ = slm.Put(sl.Sid, dao.SkillLevel(sl))
+	}
+	refm := mapx.NewMultiBuiltinMap[int64, dao.SkillRef](len(skillList))
+	for _, ref := range refs {
+		_ = refm.Put(ref.Sid, dao.SkillRef(ref))
+	}
+	res := make([]domain.Skill, 0, len(skillList))
+	for _, sk := range skillList {
+		skSL, _ := slm.Get(sk.Id)
+		skRefs, _ := refm.Get(sk.Id)
+		res = append(res, s.skillToInfoDomain(dao.Skill(sk), skSL, skRefs))
+	}
+	return res, nil
+}
+
+func (s *skillRepo) MasteryVersion(ctx context.Context, uid int64) (int64, error) {
+	return s.cache.MasteryVersion(ctx, uid)
+}
+
+func (s *skillRepo) GetMastery(ctx context.Context, uid, version int64) ([]domain.SkillMastery, error) {
+	return s.cache.GetMastery(ctx, uid, version)
+}
+
+func (s *skillRepo) SetMastery(ctx context.Context, uid, version int64, ms []domain.SkillMastery) error {
+	return s.cache.SetMastery(ctx, uid, version, ms)
+}
+
+func (s *skillRepo) DelMastery(ctx context.Context, uid int64) error {
+	return s.cache.DelMastery(ctx, uid)
+}
+
+func (s *skillRepo) skillsToListDomain(skillList []dao.Skill, sls []dao.SkillLevel) []domain.Skill {
+	slm := mapx.NewMultiBuiltinMap[int64, dao.SkillLevel](len(skillList))
 	for _, sl := range sls {
 		_ = slm.Put(sl.Sid, sl)
 	}
@@ -133,7 +242,7 @@ func (s *skillRepo) List(ctx context.Context, offset, limit int) ([]domain.Skill
 		skSL, _ := slm.Get(sk.Id)
 		res = append(res, s.skillToInfoDomain(sk, skSL, nil))
 	}
-	return res, nil
+	return res
 }
 
 func (s *skillRepo) Info(ctx context.Context, id int64) (domain.Skill, error) {
@@ -162,16 +271,87 @@ func (s *skillRepo) Info(ctx context.Context, id int64) (domain.Skill, error) {
 	return s.skillToInfoDomain(skill, skillLevels, refs), nil
 }
 
+func (s *skillRepo) PubInfo(ctx context.Context, id int64) (domain.Skill, error) {
+	var eg errgroup.Group
+	var skill dao.PublishSkill
+	var skillLevels []dao.PublishSkillLevel
+	var refs []dao.PublishSkillRef
+	eg.Go(func() error {
+		var err error
+		skill, err = s.skillDao.PubInfo(ctx, id)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		skillLevels, err = s.skillDao.PubSkillLevelInfo(ctx, id)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		refs, err = s.skillDao.PubRefs(ctx, id)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		return domain.Skill{}, err
+	}
+	return s.skillToInfoDomain(dao.Skill(skill),
+		slice.Map(skillLevels, func(idx int, src dao.PublishSkillLevel) dao.SkillLevel {
+			return dao.SkillLevel(src)
+		}),
+		slice.Map(refs, func(idx int, src dao.PublishSkillRef) dao.SkillRef {
+			return dao.SkillRef(src)
+		})), nil
+}
+
 func (s *skillRepo) Count(ctx context.Context) (int64, error) {
 	return s.skillDao.Count(ctx)
 }
 
+func (s *skillRepo) PubCount(ctx context.Context) (int64, error) {
+	return s.skillDao.PubCount(ctx)
+}
+
+func (s *skillRepo) Unpublish(ctx context.Context, id int64) error {
+	return s.skillDao.Unpublish(ctx, id)
+}
+
+func (s *skillRepo) Delete(ctx context.Context, id int64) error {
+	return s.skillDao.Delete(ctx, id)
+}
+
+func (s *skillRepo) Restore(ctx context.Context, id int64) error {
+	return s.skillDao.Restore(ctx, id)
+}
+
+func (s *skillRepo) TrashList(ctx context.Context, offset, limit int) ([]domain.Skill, error) {
+	skills, err := s.skillDao.TrashList(ctx, offset, limit)
+	return slice.Map(skills, func(idx int, src dao.Skill) domain.Skill {
+		return s.skillToListDomain(src)
+	}), err
+}
+
+func (s *skillRepo) TrashTotal(ctx context.Context) (int64, error) {
+	return s.skillDao.TrashCount(ctx)
+}
+
+func (s *skillRepo) ListDeletedBefore(ctx context.Context, t time.Time, limit int) ([]domain.Skill, error) {
+	skills, err := s.skillDao.ListDeletedBefore(ctx, t.UnixMilli(), limit)
+	return slice.Map(skills, func(idx int, src dao.Skill) domain.Skill {
+		return s.skillToListDomain(src)
+	}), err
+}
+
+func (s *skillRepo) Purge(ctx context.Context, id int64) error {
+	return s.skillDao.Purge(ctx, id)
+}
+
 func (s *skillRepo) skillToListDomain(skill dao.Skill) domain.Skill {
 	return domain.Skill{
 		ID:     skill.Id,
 		Labels: skill.Labels.Val,
 		Name:   skill.Name,
 		Desc:   skill.Desc,
+		Status: domain.SkillStatus(skill.Status),
 		Ctime:  time.UnixMilli(skill.Ctime),
 		Utime:  time.UnixMilli(skill.Utime),
 	}
@@ -220,6 +400,15 @@ func (s *skillRepo) skillToEntity(skill domain.Skill) dao.Skill {
 		Labels: sqlx.JsonColumn[[]string]{Val: skill.Labels, Valid: len(skill.Labels) != 0},
 		Name:   skill.Name,
 		Desc:   skill.Desc,
+		Status: skill.Status.ToUint8(),
+	}
+}
+
+func (s *skillRepo) skillLevelsToEntity(skill domain.Skill) []dao.SkillLevel {
+	return []dao.SkillLevel{
+		s.skillLevelToEntity(skill.Basic, dao.LevelBasic),
+		s.skillLevelToEntity(skill.Intermediate, dao.LevelIntermediate),
+		s.skillLevelToEntity(skill.Advanced, dao.LevelAdvanced),
 	}
 }
 
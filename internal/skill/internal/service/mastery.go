@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	baguwen "github.com/ecodeclub/webook/internal/question"
+	"github.com/ecodeclub/webook/internal/skill/internal/domain"
+	"github.com/ecodeclub/webook/internal/skill/internal/repository"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// MasteryService 根据用户的八股文测试结果计算技能的掌握情况
+type MasteryService interface {
+	// Mastery 用户对某个技能的掌握情况，技能必须是已经发表的
+	Mastery(ctx context.Context, uid, sid int64) (domain.SkillMastery, error)
+	// Radar 用户在所有已经发表的技能上的掌握情况
+	Radar(ctx context.Context, uid int64) ([]domain.SkillMastery, error)
+	// OnExamineResult 用户有了新的测试结果，之前算出来的掌握情况就失效了
+	OnExamineResult(ctx context.Context, uid int64) error
+}
+
+type masteryService struct {
+	repo       repository.SkillRepo
+	examineSvc baguwen.ExamineService
+	logger     *elog.Component
+}
+
+func NewMasteryService(repo repository.SkillRepo, examineSvc baguwen.ExamineService) MasteryService {
+	return &masteryService{
+		repo:       repo,
+		examineSvc: examineSvc,
+		logger:     elog.DefaultLogger,
+	}
+}
+
+func (m *masteryService) Mastery(ctx context.Context, uid, sid int64) (domain.SkillMastery, error) {
+	ms, err := m.Radar(ctx, uid)
+	if err != nil {
+		return domain.SkillMastery{}, err
+	}
+	for _, sm := range ms {
+		if sm.Sid == sid {
+			return sm, nil
+		}
+	}
+	return domain.SkillMastery{}, fmt.Errorf("%w, 技能不存在或者没有发表 sid %d", ErrSkillNotFound, sid)
+}
+
+func (m *masteryService) Radar(ctx context.Context, uid int64) ([]domain.SkillMastery, error) {
+	// 先读版本号，计算的过程中有新的测试结果的话，算出来的结果会写到旧版本上
+	version, err := m.repo.MasteryVersion(ctx, uid)
+	if err != nil {
+		// 不知道版本号的时候，就不使用缓存了
+		m.logger.Error("查询技能掌握情况的版本号失败", elog.FieldErr(err), elog.Int64("uid", uid))
+		return m.compute(ctx, uid)
+	}
+	ms, err := m.repo.GetMastery(ctx, uid, version)
+	if err == nil {
+		return ms, nil
+	}
+	ms, err = m.compute(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	err = m.repo.SetMastery(ctx, uid, version, ms)
+	if err != nil {
+		m.logger.Error("回写技能掌握情况缓存失败", elog.FieldErr(err), elog.Int64("uid", uid))
+	}
+	return ms, nil
+}
+
+func (m *masteryService) compute(ctx context.Context, uid int64) ([]domain.SkillMastery, error) {
+	// 一次性算出所有技能，只查询一次测试结果
+	skills, err := m.repo.PubAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	qids := make([]int64, 0, len(skills)*16)
+	for _, sk := range skills {
+		qids = append(qids, sk.Questions()...)
+	}
+	results := make(map[int64]uint8, len(qids))
+	if len(qids) > 0 {
+		rs, err := m.examineSvc.GetResults(ctx, uid, qids)
+		if err != nil {
+			return nil, err
+		}
+		for qid, r := range rs {
+			results[qid] = r.Result.ToUint8()
+		}
+	}
+	ms := make([]domain.SkillMastery, 0, len(skills))
+	for _, sk := range skills {
+		ms = append(ms, domain.NewSkillMastery(sk, results))
+	}
+	return ms, nil
+}
+
+func (m *masteryService) OnExamineResult(ctx context.Context, uid int64) error {
+	return m.repo.DelMastery(ctx, uid)
+}
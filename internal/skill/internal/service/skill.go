@@ -7,19 +7,43 @@ import (
 
 	"github.com/ecodeclub/webook/internal/skill/internal/event"
 	"github.com/gotomicro/ego/core/elog"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/ecodeclub/webook/internal/skill/internal/domain"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository"
 )
 
+var (
+	// ErrSkillNotFound 技能不存在，C 端来说还没有发表的也是不存在
+	ErrSkillNotFound = repository.ErrRecordNotFound
+	// ErrSkillNameInTrash 回收站里面有同名的技能
+	ErrSkillNameInTrash = repository.ErrNameInTrash
+)
+
 type SkillService interface {
-	// Save 保存基本信息
+	// Save 保存基本信息到制作库，C 端看不到
 	Save(ctx context.Context, skill domain.Skill) (int64, error)
-	// SaveRefs 保存关联信息
+	// SaveRefs 保存关联信息到制作库，发表的时候一起同步到线上库
 	SaveRefs(ctx context.Context, skill domain.Skill) error
+	// Publish 保存基本信息，并且连同关联信息一起发表到线上库
+	Publish(ctx context.Context, skill domain.Skill) (int64, error)
 	List(ctx context.Context, offset, limit int) ([]domain.Skill, int64, error)
 	Info(ctx context.Context, id int64) (domain.Skill, error)
 	RefsByLevelIDs(ctx context.Context, ids []int64) ([]domain.SkillLevel, error)
+
+	// Unpublish 下线，同时从搜索里面删除
+	Unpublish(ctx context.Context, id int64) error
+	// Delete 放入回收站，同时从搜索里面删除
+	Delete(ctx context.Context, id int64) error
+	// Restore 从回收站里面恢复，恢复之后是已下线的状态，需要重新发表
+	Restore(ctx context.Context, id int64) error
+	TrashList(ctx context.Context, offset, limit int) ([]domain.Skill, int64, error)
+	// PurgeTrash 彻底删除在 before 之前放入回收站的技能，最多删除 limit 个，返回删除的数量
+	PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error)
+
+	// PubList 和 PubInfo 只读取线上库
+	PubList(ctx context.Context, offset, limit int) ([]domain.Skill, int64, error)
+	PubInfo(ctx context.Context, id int64) (domain.Skill, error)
 }
 
 type skillService struct {
@@ -34,16 +58,17 @@ func (s *skillService) RefsByLevelIDs(ctx context.Context, ids []int64) ([]domai
 }
 
 func (s *skillService) SaveRefs(ctx context.Context, skill domain.Skill) error {
-	err := s.repo.SaveRefs(ctx, skill)
-	if err != nil {
-		return err
-	}
-	s.syncSkill(skill.ID)
-	return nil
+	return s.repo.SaveRefs(ctx, skill)
 }
 
 func (s *skillService) Save(ctx context.Context, skill domain.Skill) (int64, error) {
-	id, err := s.repo.Save(ctx, skill)
+	skill.Status = domain.SkillStatusUnPublished
+	return s.repo.Save(ctx, skill)
+}
+
+func (s *skillService) Publish(ctx context.Context, skill domain.Skill) (int64, error) {
+	skill.Status = domain.SkillStatusPublished
+	id, err := s.repo.Sync(ctx, skill)
 	if err != nil {
 		return 0, err
 	}
@@ -67,6 +92,86 @@ func (s *skillService) Info(ctx context.Context, id int64) (domain.Skill, error)
 	return s.repo.Info(ctx, id)
 }
 
+func (s *skillService) PubList(ctx context.Context, offset, limit int) ([]domain.Skill, int64, error) {
+	var (
+		eg     errgroup.Group
+		skills []domain.Skill
+		total  int64
+	)
+	eg.Go(func() error {
+		var err error
+		skills, err = s.repo.PubList(ctx, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		total, err = s.repo.PubCount(ctx)
+		return err
+	})
+	return skills, total, eg.Wait()
+}
+
+func (s *skillService) PubInfo(ctx context.Context, id int64) (domain.Skill, error) {
+	return s.repo.PubInfo(ctx, id)
+}
+
+func (s *skillService) Unpublish(ctx context.Context, id int64) error {
+	err := s.repo.Unpublish(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.removeFromSearch(ctx, id)
+	return nil
+}
+
+func (s *skillService) Delete(ctx context.Context, id int64) error {
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.removeFromSearch(ctx, id)
+	return nil
+}
+
+func (s *skillService) Restore(ctx context.Context, id int64) error {
+	return s.repo.Restore(ctx, id)
+}
+
+func (s *skillService) TrashList(ctx context.Context, offset, limit int) ([]domain.Skill, int64, error) {
+	var (
+		eg     errgroup.Group
+		skills []domain.Skill
+		total  int64
+	)
+	eg.Go(func() error {
+		var err error
+		skills, err = s.repo.TrashList(ctx, offset, limit)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		total, err = s.repo.TrashTotal(ctx)
+		return err
+	})
+	return skills, total, eg.Wait()
+}
+
+// PurgeTrash 技能没有点赞收藏之类的互动数据，所以只需要通知搜索
+func (s *skillService) PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error) {
+	skills, err := s.repo.ListDeletedBefore(ctx, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, sk := range skills {
+		err = s.repo.Purge(ctx, sk.ID)
+		if err != nil {
+			return 0, fmt.Errorf("彻底删除技能 %d 失败 %w", sk.ID, err)
+		}
+		s.removeFromSearch(ctx, sk.ID)
+	}
+	return len(skills), nil
+}
+
 func NewSkillService(repo repository.SkillRepo, p event.SyncEventProducer) SkillService {
 	return &skillService{
 		repo:        repo,
@@ -79,7 +184,8 @@ func NewSkillService(repo repository.SkillRepo, p event.SyncEventProducer) Skill
 func (s *skillService) syncSkill(id int64) {
 	ctx, cancel := context.WithTimeout(context.Background(), s.syncTimeout)
 	defer cancel()
-	sk, err := s.repo.Info(ctx, id)
+	// 搜索里面只放已经发表的数据
+	sk, err := s.repo.PubInfo(ctx, id)
 	fmt.Printf("开始发送 %d\n", id)
 	if err != nil {
 		s.logger.Error("发送同步搜索信息",
@@ -97,3 +203,16 @@ func (s *skillService) syncSkill(id int64) {
 		)
 	}
 }
+
+func (s *skillService) removeFromSearch(ctx context.Context, id int64) {
+	ctx, cancel := context.WithTimeout(ctx, s.syncTimeout)
+	defer cancel()
+	evt := event.NewSyncDeleteEvent(id)
+	err := s.producer.Produce(ctx, evt)
+	if err != nil {
+		s.logger.Error("发送技能下线消息到搜索失败",
+			elog.FieldErr(err),
+			elog.Any("event", evt),
+		)
+	}
+}
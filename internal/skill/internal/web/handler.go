@@ -18,46 +18,73 @@ import (
 )
 
 type Handler struct {
-	svc     service.SkillService
-	queSvc  baguwen.Service
-	caseSvc cases.Service
-	logger  *elog.Component
+	svc        service.SkillService
+	masterySvc service.MasteryService
+	queSvc     baguwen.Service
+	caseSvc    cases.Service
+	logger     *elog.Component
 }
 
-func NewHandler(svc service.SkillService, queSvc baguwen.Service, caseSvc cases.Service) *Handler {
+func NewHandler(svc service.SkillService, masterySvc service.MasteryService,
+	queSvc baguwen.Service, caseSvc cases.Service) *Handler {
 	return &Handler{
-		svc:     svc,
-		logger:  elog.DefaultLogger,
-		queSvc:  queSvc,
-		caseSvc: caseSvc,
+		svc:        svc,
+		masterySvc: masterySvc,
+		logger:     elog.DefaultLogger,
+		queSvc:     queSvc,
+		caseSvc:    caseSvc,
 	}
 }
 
 func (h *Handler) PrivateRoutes(server *gin.Engine) {
 	server.POST("/skill/save", ginx.S(h.Permission), ginx.B[SaveReq](h.Save))
-	server.POST("/skill/list", ginx.B[Page](h.List))
-	server.POST("/skill/detail", ginx.B[Sid](h.Detail))
+	server.POST("/skill/publish", ginx.S(h.Permission), ginx.B[SaveReq](h.Publish))
+	// 创作者看到的是制作库，其余登录用户只能看到已经发表的技能
+	server.POST("/skill/list", ginx.BS[Page](h.List))
+	server.POST("/skill/detail", ginx.BS[Sid](h.Detail))
 	server.POST("/skill/detail-refs", ginx.S(h.Permission), ginx.B[Sid](h.DetailRefs))
 	server.POST("/skill/save-refs", ginx.S(h.Permission), ginx.B(h.SaveRefs))
 	server.POST("/skill/level-refs", ginx.S(h.Permission), ginx.B(h.RefsByLevelIDs))
+	server.POST("/skill/unpublish", ginx.S(h.Permission), ginx.B[Sid](h.Unpublish))
+	server.POST("/skill/delete", ginx.S(h.Permission), ginx.B[Sid](h.Delete))
+	server.POST("/skill/restore", ginx.S(h.Permission), ginx.B[Sid](h.Restore))
+	server.POST("/skill/trash/list", ginx.S(h.Permission), ginx.B[Page](h.TrashList))
+	server.POST("/skill/mastery", ginx.BS[Sid](h.Mastery))
+	server.POST("/skill/radar", ginx.S(h.Radar))
 }
 
 func (h *Handler) PublicRoutes(server *gin.Engine) {
+	server.POST("/skill/pub/list", ginx.B[Page](h.PubList))
+	server.POST("/skill/pub/detail", ginx.B[Sid](h.PubDetail))
 }
 
 func (h *Handler) Permission(ctx *ginx.Context, sess session.Session) (ginx.Result, error) {
-	if sess.Claims().Get("creator").StringOrDefault("") != "true" {
+	if !h.isCreator(sess) {
 		ctx.AbortWithStatus(http.StatusInternalServerError)
 		return ginx.Result{}, fmt.Errorf("非法访问创作中心 uid: %d", sess.Claims().Uid)
 	}
 	return ginx.Result{}, ginx.ErrNoResponse
 }
 
+func (h *Handler) isCreator(sess session.Session) bool {
+	return sess.Claims().Get("creator").StringOrDefault("") == "true"
+}
+
 func (h *Handler) Save(ctx *ginx.Context, req SaveReq) (ginx.Result, error) {
 	skill := req.Skill.toDomain()
 	id, err := h.svc.Save(ctx, skill)
 	if err != nil {
-		return systemErrorResult, err
+		return skillErrResult(err)
+	}
+	return ginx.Result{
+		Data: id,
+	}, nil
+}
+
+func (h *Handler) Publish(ctx *ginx.Context, req SaveReq) (ginx.Result, error) {
+	id, err := h.svc.Publish(ctx, req.Skill.toDomain())
+	if err != nil {
+		return skillErrResult(err)
 	}
 	return ginx.Result{
 		Data: id,
@@ -74,10 +101,14 @@ func (h *Handler) SaveRefs(ctx *ginx.Context, req SaveReq) (ginx.Result, error)
 	}, nil
 }
 
-func (h *Handler) Detail(ctx *ginx.Context, req Sid) (ginx.Result, error) {
-	skill, err := h.svc.Info(ctx, req.Sid)
+func (h *Handler) Detail(ctx *ginx.Context, req Sid, sess session.Session) (ginx.Result, error) {
+	info := h.svc.Info
+	if !h.isCreator(sess) {
+		info = h.svc.PubInfo
+	}
+	skill, err := info(ctx, req.Sid)
 	if err != nil {
-		return systemErrorResult, err
+		return skillErrResult(err)
 	}
 	skillView := newSkill(skill)
 	return ginx.Result{
@@ -85,7 +116,10 @@ func (h *Handler) Detail(ctx *ginx.Context, req Sid) (ginx.Result, error) {
 	}, nil
 }
 
-func (h *Handler) List(ctx *ginx.Context, page Page) (ginx.Result, error) {
+func (h *Handler) List(ctx *ginx.Context, page Page, sess session.Session) (ginx.Result, error) {
+	if !h.isCreator(sess) {
+		return h.PubList(ctx, page)
+	}
 	skills, count, err := h.svc.List(ctx, page.Offset, page.Limit)
 	if err != nil {
 		return systemErrorResult, err
@@ -101,16 +135,86 @@ func (h *Handler) toSkillList(data []domain.Skill, cnt int64) SkillList {
 	return SkillList{
 		Total: cnt,
 		Skills: slice.Map(data, func(idx int, src domain.Skill) Skill {
-			return newSkill(src)
+			sk := newSkill(src)
+			sk.Status = src.Status.ToUint8()
+			return sk
 		}),
 	}
 }
 
+func (h *Handler) Unpublish(ctx *ginx.Context, req Sid) (ginx.Result, error) {
+	err := h.svc.Unpublish(ctx, req.Sid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+// Delete 放入回收站，超过保留期限之后会被彻底删除
+func (h *Handler) Delete(ctx *ginx.Context, req Sid) (ginx.Result, error) {
+	err := h.svc.Delete(ctx, req.Sid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *Handler) Restore(ctx *ginx.Context, req Sid) (ginx.Result, error) {
+	err := h.svc.Restore(ctx, req.Sid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{}, nil
+}
+
+func (h *Handler) TrashList(ctx *ginx.Context, page Page) (ginx.Result, error) {
+	skills, count, err := h.svc.TrashList(ctx, page.Offset, page.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: h.toSkillList(skills, count),
+	}, nil
+}
+
+// PubList 线上库里面的技能，不包含关联的题目和案例
+func (h *Handler) PubList(ctx *ginx.Context, page Page) (ginx.Result, error) {
+	skills, count, err := h.svc.PubList(ctx, page.Offset, page.Limit)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: SkillList{
+			Total:  count,
+			Skills: slice.Map(skills, func(idx int, src domain.Skill) Skill { return newSkill(src) }),
+		},
+	}, nil
+}
+
+// PubDetail 线上库里面的技能，带上关联的题目和案例的标题
+func (h *Handler) PubDetail(ctx *ginx.Context, req Sid) (ginx.Result, error) {
+	skill, err := h.svc.PubInfo(ctx, req.Sid)
+	if err != nil {
+		return skillErrResult(err)
+	}
+	res, err := h.withRefs(ctx, skill)
+	return ginx.Result{
+		Data: res,
+	}, err
+}
+
 func (h *Handler) DetailRefs(ctx *ginx.Context, req Sid) (ginx.Result, error) {
 	skill, err := h.svc.Info(ctx, req.Sid)
 	if err != nil {
 		return systemErrorResult, err
 	}
+	res, err := h.withRefs(ctx, skill)
+	return ginx.Result{
+		Data: res,
+	}, err
+}
+
+func (h *Handler) withRefs(ctx *ginx.Context, skill domain.Skill) (Skill, error) {
 	res := newSkill(skill)
 	var eg errgroup.Group
 	eg.Go(func() error {
@@ -138,9 +242,7 @@ func (h *Handler) DetailRefs(ctx *ginx.Context, req Sid) (ginx.Result, error) {
 		res.setCases(cms)
 		return nil
 	})
-	return ginx.Result{
-		Data: res,
-	}, eg.Wait()
+	return res, eg.Wait()
 }
 
 func (h *Handler) RefsByLevelIDs(ctx *ginx.Context, req IDs) (ginx.Result, error) {
@@ -192,3 +294,47 @@ func (h *Handler) RefsByLevelIDs(ctx *ginx.Context, req IDs) (ginx.Result, error
 		}),
 	}, nil
 }
+
+// Mastery 用户对某个技能的掌握情况，带上还没有达标的题目的标题
+func (h *Handler) Mastery(ctx *ginx.Context, req Sid, sess session.Session) (ginx.Result, error) {
+	sm, err := h.masterySvc.Mastery(ctx, sess.Claims().Uid, req.Sid)
+	if err != nil {
+		return skillErrResult(err)
+	}
+	res := newSkillMastery(sm)
+	qids := make([]int64, 0, len(sm.Basic.Missing)+len(sm.Intermediate.Missing)+len(sm.Advanced.Missing))
+	qids = append(qids, sm.Basic.Missing...)
+	qids = append(qids, sm.Intermediate.Missing...)
+	qids = append(qids, sm.Advanced.Missing...)
+	if len(qids) > 0 {
+		qs, err := h.queSvc.GetPubByIDs(ctx, qids)
+		if err != nil {
+			return systemErrorResult, err
+		}
+		res.setQuestions(slice.ToMap(qs, func(ele baguwen.Question) int64 {
+			return ele.Id
+		}))
+	}
+	return ginx.Result{
+		Data: res,
+	}, nil
+}
+
+// Radar 用户在所有技能上的掌握情况，用来画雷达图
+func (h *Handler) Radar(ctx *ginx.Context, sess session.Session) (ginx.Result, error) {
+	ms, err := h.masterySvc.Radar(ctx, sess.Claims().Uid)
+	if err != nil {
+		return systemErrorResult, err
+	}
+	return ginx.Result{
+		Data: Radar{
+			Items: slice.Map(ms, func(idx int, src domain.SkillMastery) RadarItem {
+				return RadarItem{
+					Sid:   src.Sid,
+					Name:  src.Name,
+					Score: src.Score(),
+				}
+			}),
+		},
+	}, nil
+}
@@ -15,8 +15,11 @@
 package web
 
 import (
+	"errors"
+
 	"github.com/ecodeclub/ginx"
 	"github.com/ecodeclub/webook/internal/skill/internal/errs"
+	"github.com/ecodeclub/webook/internal/skill/internal/service"
 )
 
 var (
@@ -25,3 +28,21 @@ var (
 		Msg:  errs.SystemError.Msg,
 	}
 )
+
+// skillErrResult 技能不存在或者同名冲突是客户端的错误，其余的都是系统错误
+func skillErrResult(err error) (ginx.Result, error) {
+	switch {
+	case errors.Is(err, service.ErrSkillNotFound):
+		return ginx.Result{
+			Code: errs.SkillNotFound.Code,
+			Msg:  errs.SkillNotFound.Msg,
+		}, nil
+	case errors.Is(err, service.ErrSkillNameInTrash):
+		return ginx.Result{
+			Code: errs.SkillNameInTrash.Code,
+			Msg:  errs.SkillNameInTrash.Msg,
+		}, nil
+	default:
+		return systemErrorResult, err
+	}
+}
@@ -23,6 +23,8 @@ type Skill struct {
 	Intermediate SkillLevel `json:"intermediate,omitempty"`
 	Advanced     SkillLevel `json:"advanced,omitempty"`
 	Utime        string     `json:"utime,omitempty"`
+	// Status 只在列表里面返回，1-已下线 2-已发表 3-已删除
+	Status uint8 `json:"status,omitempty"`
 }
 
 type SkillLevel struct {
@@ -140,3 +142,67 @@ type Case struct {
 type IDs struct {
 	IDs []int64 `json:"ids,omitempty"`
 }
+
+// LevelMastery 某个等级的掌握情况，Rate 是测试结果达到这个等级的题目比例，取值 0-1
+type LevelMastery struct {
+	Total    int     `json:"total"`
+	Mastered int     `json:"mastered"`
+	Rate     float64 `json:"rate"`
+	// Missing 还没有测试过，或者测试结果没有达到这个等级的题目
+	Missing []Question `json:"missing"`
+}
+
+func newLevelMastery(l domain.LevelMastery) LevelMastery {
+	return LevelMastery{
+		Total:    len(l.Questions),
+		Mastered: len(l.Mastered),
+		Rate:     l.Rate(),
+		Missing: slice.Map(l.Missing, func(idx int, src int64) Question {
+			return Question{
+				Id: src,
+			}
+		}),
+	}
+}
+
+func (l *LevelMastery) setQuestions(qm map[int64]baguwen.Question) {
+	l.Missing = slice.Map(l.Missing, func(idx int, src Question) Question {
+		src.Title = qm[src.Id].Title
+		return src
+	})
+}
+
+type SkillMastery struct {
+	Sid          int64        `json:"sid"`
+	Name         string       `json:"name"`
+	Basic        LevelMastery `json:"basic"`
+	Intermediate LevelMastery `json:"intermediate"`
+	Advanced     LevelMastery `json:"advanced"`
+}
+
+func newSkillMastery(s domain.SkillMastery) SkillMastery {
+	return SkillMastery{
+		Sid:          s.Sid,
+		Name:         s.Name,
+		Basic:        newLevelMastery(s.Basic),
+		Intermediate: newLevelMastery(s.Intermediate),
+		Advanced:     newLevelMastery(s.Advanced),
+	}
+}
+
+func (s *SkillMastery) setQuestions(qm map[int64]baguwen.Question) {
+	s.Basic.setQuestions(qm)
+	s.Intermediate.setQuestions(qm)
+	s.Advanced.setQuestions(qm)
+}
+
+type Radar struct {
+	Items []RadarItem `json:"items"`
+}
+
+// RadarItem Score 是三个等级合起来达标的题目比例，取值 0-1
+type RadarItem struct {
+	Sid   int64   `json:"sid"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
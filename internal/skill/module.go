@@ -0,0 +1,24 @@
+// Copyright 2023 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package skill
+
+type Module struct {
+	Hdl           *Handler
+	PurgeTrashJob *PurgeTrashJob
+	// MasterySvc 用户对技能的掌握情况
+	MasterySvc MasteryService
+	// MasteryConsumer 有新的测试结果的时候让技能掌握情况的缓存失效
+	MasteryConsumer *MasteryConsumer
+}
@@ -17,10 +17,14 @@
 package skill
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/ecodeclub/mq-api"
 	"github.com/ecodeclub/webook/internal/skill/internal/event"
+	"github.com/ecodeclub/webook/internal/skill/internal/event/consumer"
+	"github.com/ecodeclub/webook/internal/skill/internal/job"
 
 	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
@@ -38,23 +42,27 @@ import (
 	"gorm.io/gorm"
 )
 
-func InitHandler(
+func InitModule(
 	db *egorm.Component,
 	ec ecache.Cache,
 	queModule *baguwen.Module,
 	caseModule *cases.Module,
-	q mq.MQ) (*Handler, error) {
+	q mq.MQ) (*Module, error) {
 	wire.Build(
 		InitSkillDAO,
-		wire.FieldsOf(new(*baguwen.Module), "Svc"),
+		wire.FieldsOf(new(*baguwen.Module), "Svc", "ExamineSvc"),
 		wire.FieldsOf(new(*cases.Module), "Svc"),
 		cache.NewSkillCache,
 		repository.NewSkillRepo,
 		event.NewSyncEventProducer,
 		service.NewSkillService,
+		service.NewMasteryService,
 		web.NewHandler,
+		InitPurgeTrashJob,
+		initMasteryConsumer,
+		wire.Struct(new(Module), "*"),
 	)
-	return new(Handler), nil
+	return new(Module), nil
 }
 
 var daoOnce = sync.Once{}
@@ -73,4 +81,21 @@ func InitSkillDAO(db *egorm.Component) dao2.SkillDAO {
 	return dao2.NewSkillDAO(db)
 }
 
+// InitPurgeTrashJob 回收站里面的技能保留 30 天
+func InitPurgeTrashJob(svc service.SkillService) *PurgeTrashJob {
+	return job.NewPurgeTrashJob(svc, 30*24*time.Hour, 100)
+}
+
+func initMasteryConsumer(svc service.MasteryService, q mq.MQ) *consumer.MasteryConsumer {
+	c, err := consumer.NewMasteryConsumer(svc, q)
+	if err != nil {
+		panic(err)
+	}
+	c.Start(context.Background())
+	return c
+}
+
 type Handler = web.Handler
+type PurgeTrashJob = job.PurgeTrashJob
+type MasteryConsumer = consumer.MasteryConsumer
+type MasteryService = service.MasteryService
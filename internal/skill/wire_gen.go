@@ -7,13 +7,17 @@
 package skill
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/ecodeclub/ecache"
 	"github.com/ecodeclub/mq-api"
 	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/ecodeclub/webook/internal/skill/internal/event"
+	"github.com/ecodeclub/webook/internal/skill/internal/event/consumer"
+	"github.com/ecodeclub/webook/internal/skill/internal/job"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository/cache"
 	"github.com/ecodeclub/webook/internal/skill/internal/repository/dao"
@@ -25,7 +29,7 @@ import (
 
 // Injectors from wire.go:
 
-func InitHandler(db *gorm.DB, ec ecache.Cache, queModule *baguwen.Module, caseModule *cases.Module, q mq.MQ) (*web.Handler, error) {
+func InitModule(db *gorm.DB, ec ecache.Cache, queModule *baguwen.Module, caseModule *cases.Module, q mq.MQ) (*Module, error) {
 	skillDAO := InitSkillDAO(db)
 	skillCache := cache.NewSkillCache(ec)
 	skillRepo := repository.NewSkillRepo(skillDAO, skillCache)
@@ -34,10 +38,20 @@ func InitHandler(db *gorm.DB, ec ecache.Cache, queModule *baguwen.Module, caseMo
 		return nil, err
 	}
 	skillService := service.NewSkillService(skillRepo, syncEventProducer)
+	examineService := queModule.ExamineSvc
+	masteryService := service.NewMasteryService(skillRepo, examineService)
 	serviceService := queModule.Svc
 	service2 := caseModule.Svc
-	handler := web.NewHandler(skillService, serviceService, service2)
-	return handler, nil
+	handler := web.NewHandler(skillService, masteryService, serviceService, service2)
+	purgeTrashJob := InitPurgeTrashJob(skillService)
+	masteryConsumer := initMasteryConsumer(masteryService, q)
+	module := &Module{
+		Hdl:             handler,
+		PurgeTrashJob:   purgeTrashJob,
+		MasterySvc:      masteryService,
+		MasteryConsumer: masteryConsumer,
+	}
+	return module, nil
 }
 
 // wire.go:
@@ -58,4 +72,24 @@ func InitSkillDAO(db *egorm.Component) dao.SkillDAO {
 	return dao.NewSkillDAO(db)
 }
 
+// InitPurgeTrashJob 回收站里面的技能保留 30 天
+func InitPurgeTrashJob(svc service.SkillService) *PurgeTrashJob {
+	return job.NewPurgeTrashJob(svc, 30*24*time.Hour, 100)
+}
+
+func initMasteryConsumer(svc service.MasteryService, q mq.MQ) *consumer.MasteryConsumer {
+	c, err := consumer.NewMasteryConsumer(svc, q)
+	if err != nil {
+		panic(err)
+	}
+	c.Start(context.Background())
+	return c
+}
+
 type Handler = web.Handler
+
+type PurgeTrashJob = job.PurgeTrashJob
+
+type MasteryConsumer = consumer.MasteryConsumer
+
+type MasteryService = service.MasteryService
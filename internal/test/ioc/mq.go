@@ -91,6 +91,14 @@ func initMQ() (mq.MQ, error) {
 			Name:       "create_product",
 			Partitions: 1,
 		},
+		{
+			Name:       "question_examine_events",
+			Partitions: 1,
+		},
+		{
+			Name:       "question_examine_results",
+			Partitions: 1,
+		},
 	}
 	// 替换用内存实现，方便测试
 	qq := memory.NewMQ()
@@ -18,6 +18,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/ecodeclub/webook/internal/ai"
+	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 
 	"github.com/ecodeclub/webook/internal/roadmap"
@@ -39,7 +41,9 @@ func InitAdminServer(prj *project.AdminHandler,
 	rm *roadmap.AdminHandler,
 	que *baguwen.AdminHandler,
 	queSet *baguwen.AdminQuestionSetHandler,
-	mark *marketing.AdminHandler) AdminServer {
+	caseSet *cases.AdminCaseSetHandler,
+	mark *marketing.AdminHandler,
+	aiHdl *ai.AdminHandler) AdminServer {
 	res := egin.Load("admin").Build()
 	res.Use(cors.New(cors.Config{
 		ExposeHeaders:    []string{"X-Refresh-Token", "X-Access-Token"},
@@ -64,9 +68,11 @@ func InitAdminServer(prj *project.AdminHandler,
 	res.Use(AdminPermission())
 	prj.PrivateRoutes(res.Engine)
 	queSet.PrivateRoutes(res.Engine)
+	caseSet.PrivateRoutes(res.Engine)
 	mark.PrivateRoutes(res.Engine)
 	rm.PrivateRoutes(res.Engine)
 	que.PrivateRoutes(res.Engine)
+	aiHdl.PrivateRoutes(res.Engine)
 	return res
 }
 
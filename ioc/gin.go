@@ -63,10 +63,14 @@ func initGinxServer(sp session.Provider,
 	qh *baguwen.Handler,
 	examineHdl *baguwen.ExamineHandler,
 	qsh *baguwen.QuestionSetHandler,
+	reviewHdl *baguwen.ReviewHandler,
+	examHdl *baguwen.ExamHandler,
 	lhdl *label.Handler,
 	user *user.Handler,
 	cosHdl *cos.Handler,
 	caseHdl *cases.Handler,
+	caseSetHdl *cases.CaseSetHandler,
+	caseExamineHdl *cases.ExamineHandler,
 	skillHdl *skill.Handler,
 	fbHdl *feedback.Handler,
 	pHdl *product.Handler,
@@ -118,8 +122,10 @@ func initGinxServer(sp session.Provider,
 	user.PrivateRoutes(res.Engine)
 	lhdl.PrivateRoutes(res.Engine)
 	qsh.PrivateRoutes(res.Engine)
+	reviewHdl.PrivateRoutes(res.Engine)
 	cosHdl.PrivateRoutes(res.Engine)
 	caseHdl.PrivateRoutes(res.Engine)
+	caseSetHdl.PrivateRoutes(res.Engine)
 	skillHdl.PrivateRoutes(res.Engine)
 	pHdl.PrivateRoutes(res.Engine)
 	orderHdl.PrivateRoutes(res.Engine)
@@ -137,7 +143,9 @@ func initGinxServer(sp session.Provider,
 	res.Use(checkMembershipMiddleware.Build())
 	qh.MemberRoutes(res.Engine)
 	examineHdl.MemberRoutes(res.Engine)
+	examHdl.MemberRoutes(res.Engine)
 	caseHdl.MemberRoutes(res.Engine)
+	caseExamineHdl.MemberRoutes(res.Engine)
 	fbHdl.MemberRoutes(res.Engine)
 	return res
 }
@@ -18,6 +18,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/ecodeclub/webook/internal/cases"
 	baguwen "github.com/ecodeclub/webook/internal/question"
 	"github.com/gotomicro/ego/task/ejob"
 
@@ -25,14 +26,22 @@ import (
 	"github.com/ecodeclub/webook/internal/order"
 	"github.com/ecodeclub/webook/internal/payment"
 	"github.com/ecodeclub/webook/internal/recon"
+	"github.com/ecodeclub/webook/internal/skill"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/gotomicro/ego/task/ecron"
 )
 
 // 手动运行，或者通过 http 来触发
-func initJobs(knowledgeStarter *baguwen.KnowledgeJobStarter) []ejob.Ejob {
+func initJobs(knowledgeStarter *baguwen.KnowledgeJobStarter,
+	queLabelsJob *baguwen.SyncLabelsJob,
+	caseLabelsJob *cases.SyncLabelsJob) []ejob.Ejob {
 	return []ejob.Ejob{
 		ejob.Job("gen-knowledge", knowledgeStarter.Start),
+		// 只导出上一次成功导出之后更新过的题目
+		ejob.Job("gen-knowledge-incremental", knowledgeStarter.StartIncremental),
+		// 把历史数据里面的字符串标签关联到 label 模块的标签上
+		ejob.Job("sync-question-labels", queLabelsJob.Run),
+		ejob.Job("sync-case-labels", caseLabelsJob.Run),
 	}
 }
 
@@ -42,12 +51,18 @@ func initCronJobs(
 	cJob *credit.CloseTimeoutLockedCreditsJob,
 	pJob *payment.SyncWechatOrderJob,
 	rJob *recon.SyncPaymentAndOrderJob,
+	caseTrashJob *cases.PurgeTrashJob,
+	setTrashJob *baguwen.PurgeSetTrashJob,
+	skillTrashJob *skill.PurgeTrashJob,
 ) []ecron.Ecron {
 	return []ecron.Ecron{
 		ecron.Load("cron.closeTimeoutOrder").Build(ecron.WithJob(funcJobWrapper(oJob))),
 		ecron.Load("cron.unlockTimeoutCredit").Build(ecron.WithJob(funcJobWrapper(cJob))),
 		ecron.Load("cron.syncWechatOrder").Build(ecron.WithJob(funcJobWrapper(pJob))),
 		ecron.Load("cron.syncPaymentAndOrder").Build(ecron.WithJob(funcJobWrapper(rJob))),
+		ecron.Load("cron.purgeCaseTrash").Build(ecron.WithJob(funcJobWrapper(caseTrashJob))),
+		ecron.Load("cron.purgeQuestionSetTrash").Build(ecron.WithJob(funcJobWrapper(setTrashJob))),
+		ecron.Load("cron.purgeSkillTrash").Build(ecron.WithJob(funcJobWrapper(skillTrashJob))),
 	}
 }
 
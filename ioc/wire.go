@@ -50,13 +50,15 @@ func InitApp() (*App, error) {
 		baguwen.InitModule,
 		initJobs,
 		wire.FieldsOf(new(*baguwen.Module),
-			"AdminHdl", "AdminSetHdl", "KnowledgeJobStarter",
-			"ExamineHdl", "Hdl", "QsHdl"),
+			"AdminHdl", "AdminSetHdl", "KnowledgeJobStarter", "SyncLabelsJob",
+			"ExamineHdl", "Hdl", "QsHdl", "ReviewHdl", "ExamHdl", "PurgeSetTrashJob"),
 		InitUserHandler,
-		label.InitHandler,
+		label.InitModule,
+		wire.FieldsOf(new(*label.Module), "Hdl"),
 		cases.InitModule,
-		wire.FieldsOf(new(*cases.Module), "Hdl"),
-		skill.InitHandler,
+		wire.FieldsOf(new(*cases.Module), "Hdl", "SetHdl", "AdminSetHdl", "ExamineHdl", "SyncLabelsJob", "PurgeTrashJob"),
+		skill.InitModule,
+		wire.FieldsOf(new(*skill.Module), "Hdl", "PurgeTrashJob"),
 		feedback.InitHandler,
 		member.InitModule,
 		wire.FieldsOf(new(*member.Module), "Svc"),
@@ -85,6 +87,7 @@ func InitApp() (*App, error) {
 		roadmap.InitModule,
 		wire.FieldsOf(new(*roadmap.Module), "Hdl", "AdminHdl"),
 		ai.InitModule,
+		wire.FieldsOf(new(*ai.Module), "AdminHdl"),
 		initLocalActiveLimiterBuilder,
 		initCronJobs,
 		// 这两个顺序不要换
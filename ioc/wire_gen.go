@@ -59,30 +59,36 @@ func InitApp() (*App, error) {
 	if err != nil {
 		return nil, err
 	}
-	aiModule, err := ai.InitModule(db, creditModule)
+	aiModule, err := ai.InitModule(db, cmdable, cache, creditModule, module)
 	if err != nil {
 		return nil, err
 	}
-	baguwenModule, err := baguwen.InitModule(db, interactiveModule, cache, permissionModule, aiModule, mq)
+	labelModule := label.InitModule(db)
+	baguwenModule, err := baguwen.InitModule(db, interactiveModule, cache, cmdable, permissionModule, aiModule, labelModule, mq)
 	if err != nil {
 		return nil, err
 	}
 	handler := baguwenModule.Hdl
 	examineHandler := baguwenModule.ExamineHdl
 	questionSetHandler := baguwenModule.QsHdl
-	webHandler := label.InitHandler(db)
+	reviewHandler := baguwenModule.ReviewHdl
+	examHandler := baguwenModule.ExamHdl
+	webHandler := labelModule.Hdl
 	handler2 := InitUserHandler(db, cache, mq, module, permissionModule)
 	config := InitCosConfig()
 	handler3 := cos.InitHandler(config)
-	casesModule, err := cases.InitModule(db, interactiveModule, mq)
+	casesModule, err := cases.InitModule(db, interactiveModule, labelModule, aiModule, mq)
 	if err != nil {
 		return nil, err
 	}
 	handler4 := casesModule.Hdl
-	handler5, err := skill.InitHandler(db, cache, baguwenModule, casesModule, mq)
+	caseSetHandler := casesModule.SetHdl
+	casesExamineHandler := casesModule.ExamineHdl
+	skillModule, err := skill.InitModule(db, cache, baguwenModule, casesModule, mq)
 	if err != nil {
 		return nil, err
 	}
+	handler5 := skillModule.Hdl
 	handler6, err := feedback.InitHandler(db, mq)
 	if err != nil {
 		return nil, err
@@ -120,15 +126,17 @@ func InitApp() (*App, error) {
 		return nil, err
 	}
 	handler14 := searchModule.Hdl
-	roadmapModule := roadmap.InitModule(db, baguwenModule)
+	roadmapModule := roadmap.InitModule(db, baguwenModule, casesModule)
 	handler15 := roadmapModule.Hdl
-	component := initGinxServer(provider, checkMembershipMiddlewareBuilder, localActiveLimit, checkPermissionMiddlewareBuilder, handler, examineHandler, questionSetHandler, webHandler, handler2, handler3, handler4, handler5, handler6, handler7, handler8, handler9, handler10, handler11, handler12, handler13, handler14, handler15)
+	component := initGinxServer(provider, checkMembershipMiddlewareBuilder, localActiveLimit, checkPermissionMiddlewareBuilder, handler, examineHandler, questionSetHandler, reviewHandler, examHandler, webHandler, handler2, handler3, handler4, caseSetHandler, casesExamineHandler, handler5, handler6, handler7, handler8, handler9, handler10, handler11, handler12, handler13, handler14, handler15)
 	adminHandler := projectModule.AdminHdl
 	webAdminHandler := roadmapModule.AdminHdl
 	adminHandler2 := baguwenModule.AdminHdl
 	adminQuestionSetHandler := baguwenModule.AdminSetHdl
+	adminCaseSetHandler := casesModule.AdminSetHdl
 	adminHandler3 := marketingModule.AdminHdl
-	adminServer := InitAdminServer(adminHandler, webAdminHandler, adminHandler2, adminQuestionSetHandler, adminHandler3)
+	adminHandler4 := aiModule.AdminHdl
+	adminServer := InitAdminServer(adminHandler, webAdminHandler, adminHandler2, adminQuestionSetHandler, adminCaseSetHandler, adminHandler3, adminHandler4)
 	closeTimeoutOrdersJob := orderModule.CloseTimeoutOrdersJob
 	closeTimeoutLockedCreditsJob := creditModule.CloseTimeoutLockedCreditsJob
 	syncWechatOrderJob := paymentModule.SyncWechatOrderJob
@@ -137,9 +145,14 @@ func InitApp() (*App, error) {
 		return nil, err
 	}
 	syncPaymentAndOrderJob := reconModule.SyncPaymentAndOrderJob
-	v := initCronJobs(closeTimeoutOrdersJob, closeTimeoutLockedCreditsJob, syncWechatOrderJob, syncPaymentAndOrderJob)
+	purgeTrashJob := casesModule.PurgeTrashJob
+	purgeSetTrashJob := baguwenModule.PurgeSetTrashJob
+	skillPurgeTrashJob := skillModule.PurgeTrashJob
+	v := initCronJobs(closeTimeoutOrdersJob, closeTimeoutLockedCreditsJob, syncWechatOrderJob, syncPaymentAndOrderJob, purgeTrashJob, purgeSetTrashJob, skillPurgeTrashJob)
 	knowledgeJobStarter := baguwenModule.KnowledgeJobStarter
-	v2 := initJobs(knowledgeJobStarter)
+	syncLabelsJob := baguwenModule.SyncLabelsJob
+	casesSyncLabelsJob := casesModule.SyncLabelsJob
+	v2 := initJobs(knowledgeJobStarter, syncLabelsJob, casesSyncLabelsJob)
 	app := &App{
 		Web:   component,
 		Admin: adminServer,